@@ -8,3 +8,8 @@ const MIN_WINDOW_WIDTH = 960
 const MIN_WINDOW_HEIGHT = 640
 const DEFAULT_LOAD_SIZE = 10000
 const DEFAULT_SCAN_SIZE = 3000
+const DEFAULT_LARGE_VALUE_SIZE = 1024 // in KB
+const DEFAULT_CLIENT_NAME = "tinyrdm:{hostname}:{session}"
+const DEFAULT_KEY_FILTER = "*"
+const DEFAULT_KEY_SEPARATOR = ":"
+const MAX_RECENT_KEYS = 20
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strings"
+	"sync"
+	"tinyrdm/backend/types"
+	redis2 "tinyrdm/backend/utils/redis"
+	strutil "tinyrdm/backend/utils/string"
+)
+
+type aclService struct {
+	ctx context.Context
+}
+
+var acl *aclService
+var onceAcl sync.Once
+
+func ACL() *aclService {
+	if acl == nil {
+		onceAcl.Do(func() {
+			acl = &aclService{}
+		})
+	}
+	return acl
+}
+
+func (a *aclService) Start(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// execute on every node in cluster mode, because acl rules are not propagated between nodes
+func (a *aclService) forEachNode(ctx context.Context, client redis.UniversalClient, fn func(ctx context.Context, cli redis.UniversalClient) error) error {
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		return cluster.ForEachShard(ctx, func(ctx context.Context, cli *redis.Client) error {
+			return fn(ctx, cli)
+		})
+	}
+	return fn(ctx, client)
+}
+
+// ListACLUsers list all acl users
+func (a *aclService) ListACLUsers(server string) (resp types.JSResp) {
+	item, err := Browser().getRedisClient(server, -1)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	lines, err := client.ACLList(ctx).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	// line format: user <name> on|off [rules...]
+	users := make([]types.ACLUser, 0, len(lines))
+	for _, line := range lines {
+		parts := strings.Fields(line)
+		if len(parts) < 2 || parts[0] != "user" {
+			continue
+		}
+		user := types.ACLUser{
+			Name:  parts[1],
+			Rules: strings.Join(parts[2:], " "),
+		}
+		for _, part := range parts[2:] {
+			switch part {
+			case "on":
+				user.Enabled = true
+			case "nopass":
+				user.NoPass = true
+			}
+		}
+		users = append(users, user)
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"users": users,
+	}
+	return
+}
+
+// GetACLUser get rules of acl user
+func (a *aclService) GetACLUser(server, name string) (resp types.JSResp) {
+	item, err := Browser().getRedisClient(server, -1)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	res, err := client.Do(ctx, "ACL", "GETUSER", name).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			resp.Msg = fmt.Sprintf("no acl user named \"%s\"", name)
+		} else {
+			resp.Msg = err.Error()
+		}
+		return
+	}
+
+	// reply is a map in RESP3, or a flat array of key-value in RESP2
+	info := map[string]any{}
+	switch val := res.(type) {
+	case map[any]any:
+		for k, v := range val {
+			info[strutil.AnyToString(k, "", 0)] = v
+		}
+	case []any:
+		for i := 0; i+1 < len(val); i += 2 {
+			info[strutil.AnyToString(val[i], "", 0)] = val[i+1]
+		}
+	}
+
+	// values may be an array(redis 6) or a space separated string(redis 7+)
+	toStrings := func(v any) []string {
+		switch val := v.(type) {
+		case string:
+			return strings.Fields(val)
+		case []any:
+			ret := make([]string, 0, len(val))
+			for _, s := range val {
+				ret = append(ret, strutil.AnyToString(s, "", 0))
+			}
+			return ret
+		}
+		return nil
+	}
+	user := types.ACLUser{
+		Name:     name,
+		Hashes:   toStrings(info["passwords"]),
+		Commands: toStrings(info["commands"]),
+		Keys:     toStrings(info["keys"]),
+		Channels: toStrings(info["channels"]),
+	}
+	for _, flag := range toStrings(info["flags"]) {
+		switch flag {
+		case "on":
+			user.Enabled = true
+		case "nopass":
+			user.NoPass = true
+		}
+	}
+	// keys and channels are in short form in redis 6, like "*" for "~*"
+	for i, key := range user.Keys {
+		if !strings.HasPrefix(key, "~") && !strings.HasPrefix(key, "%") && key != "allkeys" {
+			user.Keys[i] = "~" + key
+		}
+	}
+	for i, channel := range user.Channels {
+		if !strings.HasPrefix(channel, "&") && channel != "allchannels" {
+			user.Channels[i] = "&" + channel
+		}
+	}
+
+	resp.Success = true
+	resp.Data = user
+	return
+}
+
+// GetACLCategories get all command categories
+func (a *aclService) GetACLCategories(server string) (resp types.JSResp) {
+	item, err := Browser().getRedisClient(server, -1)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	categories, err := client.ACLCat(ctx).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"categories": categories,
+	}
+	return
+}
+
+// SaveACLUser create or replace acl user, the rules will be validated before applying
+func (a *aclService) SaveACLUser(server string, user types.ACLUser) (resp types.JSResp) {
+	item, err := Browser().getRedisClient(server, -1)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	categories, err := client.ACLCat(ctx).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	rules, err := redis2.BuildACLRules(user, categories)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	err = a.forEachNode(ctx, client, func(ctx context.Context, cli redis.UniversalClient) error {
+		return cli.ACLSetUser(ctx, user.Name, rules...).Err()
+	})
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// DeleteACLUser delete acl user by name
+func (a *aclService) DeleteACLUser(server, name string) (resp types.JSResp) {
+	item, err := Browser().getRedisClient(server, -1)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	err = a.forEachNode(ctx, client, func(ctx context.Context, cli redis.UniversalClient) error {
+		return cli.ACLDelUser(ctx, name).Err()
+	})
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"log"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	. "tinyrdm/backend/storage"
+	"tinyrdm/backend/types"
+)
+
+type auditService struct {
+	ctx    context.Context
+	logger *AuditLogStorage
+}
+
+var audit *auditService
+var onceAudit sync.Once
+
+func Audit() *auditService {
+	if audit == nil {
+		onceAudit.Do(func() {
+			audit = &auditService{
+				logger: NewAuditLog(),
+			}
+		})
+	}
+	return audit
+}
+
+func (a *auditService) Start(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// record write command executed on connection
+func (a *auditService) record(server string, db int, args []string, err error) {
+	entry := types.AuditEntry{
+		Timestamp: time.Now().UnixMilli(),
+		Server:    server,
+		DB:        db,
+		Args:      args,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err = a.logger.Append(entry); err != nil {
+		log.Println("write audit log fail:", err)
+	}
+}
+
+// QueryAuditLog query recorded write commands, the latest comes first
+func (a *auditService) QueryAuditLog(query types.AuditQuery) (resp types.JSResp) {
+	entries, err := a.logger.Query(query)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"entries": entries,
+	}
+	return
+}
+
+// ExportAuditLog export matched entries to local file in chronological order
+// the format is csv if file extension is ".csv", otherwise JSON lines
+func (a *auditService) ExportAuditLog(query types.AuditQuery) (resp types.JSResp) {
+	defaultFileName := "audit_" + time.Now().Format("20060102150405") + ".csv"
+	filepath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		ShowHiddenFiles: true,
+		DefaultFilename: defaultFileName,
+		Filters: []runtime.FileFilter{
+			{
+				Pattern: "*.csv;*.jsonl",
+			},
+		},
+	})
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if len(filepath) <= 0 {
+		// canceled
+		return
+	}
+
+	entries, err := a.logger.Query(query)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	slices.Reverse(entries)
+
+	file, err := os.Create(filepath)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	defer file.Close()
+
+	if strings.ToLower(path.Ext(filepath)) == ".csv" {
+		writer := csv.NewWriter(file)
+		_ = writer.Write([]string{"time", "server", "db", "command", "error"})
+		for _, entry := range entries {
+			_ = writer.Write([]string{
+				time.UnixMilli(entry.Timestamp).Format(time.RFC3339Nano),
+				entry.Server,
+				strconv.Itoa(entry.DB),
+				strings.Join(entry.Args, " "),
+				entry.Error,
+			})
+		}
+		writer.Flush()
+		err = writer.Error()
+	} else {
+		encoder := json.NewEncoder(file)
+		for _, entry := range entries {
+			if err = encoder.Encode(entry); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"path":  filepath,
+		"count": len(entries),
+	}
+	return
+}
+
+// ClearAuditLog remove all recorded entries
+func (a *auditService) ClearAuditLog() (resp types.JSResp) {
+	if err := a.logger.Clear(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
@@ -1,7 +1,11 @@
 package services
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/csv"
 	"encoding/hex"
 	"encoding/json"
@@ -9,9 +13,13 @@ import (
 	"fmt"
 	"github.com/redis/go-redis/v9"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"io"
 	"math"
+	"net"
 	"net/url"
 	"os"
+	"path"
+	"regexp"
 	"slices"
 	"sort"
 	"strconv"
@@ -27,6 +35,7 @@ import (
 	redis2 "tinyrdm/backend/utils/redis"
 	sliceutil "tinyrdm/backend/utils/slice"
 	strutil "tinyrdm/backend/utils/string"
+	"unicode/utf8"
 )
 
 type slowLogItem struct {
@@ -51,16 +60,48 @@ type connectionItem struct {
 	ctx         context.Context
 	cancelFunc  context.CancelFunc
 	cursor      map[int]uint64      // current cursor of databases
+	nodeCursor  map[string]uint64   // current cursor of each master node in cluster mode
 	entryCursor map[int]entryCursor // current entry cursor of databases
 	stepSize    int64
-	db          int // current database index
+	clientName  string         // name set by CLIENT SETNAME
+	keyFilter   string         // default scan pattern if no pattern specified
+	scanFilter  *keyScanFilter // extra filter applied to scanned keys
+	separator   string         // separator of key namespaces
+	separators  []string       // all separators of key namespaces, including separator above
+	db          int            // current database index
+}
+
+// client-side filter of scanned keys on top of the match pattern of SCAN
+type keyScanFilter struct {
+	regex    *regexp.Regexp
+	excludes []string // glob-style patterns of keys to exclude
+}
+
+func (f *keyScanFilter) match(key string) bool {
+	if f == nil {
+		return true
+	}
+	if f.regex != nil && !f.regex.MatchString(key) {
+		return false
+	}
+	for _, exclude := range f.excludes {
+		if strutil.MatchGlob(exclude, key) {
+			return false
+		}
+	}
+	return true
 }
 
 type browserService struct {
-	ctx        context.Context
-	connMap    map[string]*connectionItem
-	cmdHistory []cmdHistoryItem
-	mutex      sync.Mutex
+	ctx         context.Context
+	connMap     map[string]*connectionItem
+	cmdHistory  []cmdHistoryItem
+	mutex       sync.Mutex
+	sessionSeq  atomic.Int64
+	flushTokens map[string]flushToken // pending confirmations of flushing database
+
+	replicaMutex sync.Mutex
+	replicaCache map[*redis.ClusterClient]replicaCacheItem // replicas of each cluster for reading, refreshed periodically
 }
 
 var browser *browserService
@@ -70,7 +111,9 @@ func Browser() *browserService {
 	if browser == nil {
 		onceBrowser.Do(func() {
 			browser = &browserService{
-				connMap: map[string]*connectionItem{},
+				connMap:      map[string]*connectionItem{},
+				flushTokens:  map[string]flushToken{},
+				replicaCache: map[*redis.ClusterClient]replicaCacheItem{},
 			}
 		})
 	}
@@ -97,6 +140,10 @@ func (b *browserService) Stop() {
 func (b *browserService) OpenConnection(name string) (resp types.JSResp) {
 	// get connection config
 	selConn := Connection().getConnection(name)
+	if selConn == nil {
+		resp.Msg = fmt.Sprintf("no match connection \"%s\"", name)
+		return
+	}
 	// correct last database index
 	lastDB := selConn.LastDB
 	if selConn.DBFilterType == "show" && !slices.Contains(selConn.DBFilterList, lastDB) {
@@ -105,7 +152,7 @@ func (b *browserService) OpenConnection(name string) (resp types.JSResp) {
 		lastDB = selConn.DBFilterList[0]
 	}
 	if lastDB != selConn.LastDB {
-		Connection().SaveLastDB(name, lastDB)
+		Connection().SaveLastDB(selConn.Name, lastDB)
 	}
 
 	item, err := b.getRedisClient(name, lastDB)
@@ -113,6 +160,7 @@ func (b *browserService) OpenConnection(name string) (resp types.JSResp) {
 		resp.Msg = err.Error()
 		return
 	}
+	Connection().saveLastConnected(name)
 
 	client, ctx := item.client, item.ctx
 	var totaldb int
@@ -149,6 +197,7 @@ func (b *browserService) OpenConnection(name string) (resp types.JSResp) {
 		dbs = []types.ConnectionDB{
 			{
 				Name:    "db0",
+				Alias:   selConn.Alias[0],
 				Index:   0,
 				MaxKeys: int(clusterKeyCount),
 			},
@@ -234,10 +283,71 @@ func (b *browserService) OpenConnection(name string) (resp types.JSResp) {
 
 	resp.Success = true
 	resp.Data = map[string]any{
-		"db":      dbs,
-		"view":    selConn.KeyView,
-		"lastDB":  selConn.LastDB,
-		"version": version,
+		"db":         dbs,
+		"view":       selConn.KeyView,
+		"lastDB":     selConn.LastDB,
+		"version":    version,
+		"filter":     item.keyFilter,
+		"separator":  item.separator,
+		"separators": item.separators,
+		"loadSize":   item.stepSize,
+	}
+	return
+}
+
+// NewSession create a new session name of connection, which can be used as server name
+// in all browser and cli api to open an independent client instance of the same connection
+func (b *browserService) NewSession(name string) (resp types.JSResp) {
+	if Connection().getConnection(name) == nil {
+		resp.Msg = fmt.Sprintf("no match connection \"%s\"", name)
+		return
+	}
+
+	name, _ = splitSession(name)
+	resp.Success = true
+	resp.Data = map[string]any{
+		"session": sessionName(name, b.sessionSeq.Add(1)),
+	}
+	return
+}
+
+// QuickConnect open a temporary connection by "host:port" or connection url without saving it,
+// it can be saved by PromoteConnection later
+func (b *browserService) QuickConnect(address string) (resp types.JSResp) {
+	name, err := Connection().addTempConnection(address)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp = b.OpenConnection(name)
+	if !resp.Success {
+		Connection().removeTempConnection(name)
+		return
+	}
+	if data, ok := resp.Data.(map[string]any); ok {
+		data["name"] = name
+	}
+	return
+}
+
+// GetSessionInfo get info of opened session, include client name which can be found in CLIENT LIST
+func (b *browserService) GetSessionInfo(server string) (resp types.JSResp) {
+	b.mutex.Lock()
+	item, ok := b.connMap[server]
+	b.mutex.Unlock()
+	if !ok || item.client == nil {
+		resp.Msg = fmt.Sprintf("session \"%s\" is not opened", server)
+		return
+	}
+
+	connName, session := splitSession(server)
+	resp.Success = true
+	resp.Data = map[string]any{
+		"connection": connName,
+		"session":    session,
+		"clientName": item.clientName,
+		"db":         item.db,
 	}
 	return
 }
@@ -253,6 +363,10 @@ func (b *browserService) CloseConnection(name string) (resp types.JSResp) {
 			item.client.Close()
 		}
 	}
+	if _, session := splitSession(name); len(session) <= 0 {
+		// temporary connection is discarded after closed
+		Connection().removeTempConnection(name)
+	}
 	resp.Success = true
 	return
 }
@@ -278,7 +392,6 @@ func (b *browserService) createRedisClient(ctx context.Context, selConn types.Co
 		return
 	}
 
-	_ = client.Do(ctx, "CLIENT", "SETNAME", url.QueryEscape(selConn.Name)).Err()
 	// add hook to each node in cluster mode
 	if cluster, ok := client.(*redis.ClusterClient); ok {
 		err = cluster.ForEachShard(ctx, func(ctx context.Context, cli *redis.Client) error {
@@ -308,11 +421,14 @@ func (b *browserService) getRedisClient(server string, db int) (item *connection
 
 	var ok bool
 	var client redis.UniversalClient
+	var scanFilter *keyScanFilter
 	if item, ok = b.connMap[server]; ok {
 		if item.db == db || db < 0 {
 			// return without switch database directly
 			return
 		}
+		// keep scan filter after switch database
+		scanFilter = item.scanFilter
 
 		// close previous connection if database is not the same
 		if item.cancelFunc != nil {
@@ -337,6 +453,7 @@ func (b *browserService) getRedisClient(server string, db int) (item *connection
 	}
 	var connConfig = selConn.ConnectionConfig
 	connConfig.LastDB = db
+	connConfig.ClientName = Connection().clientName(server)
 	client, err = b.createRedisClient(ctx, connConfig)
 	if err != nil {
 		delete(b.connMap, server)
@@ -347,17 +464,110 @@ func (b *browserService) getRedisClient(server string, db int) (item *connection
 		ctx:         ctx,
 		cancelFunc:  cancelFunc,
 		cursor:      map[int]uint64{},
+		nodeCursor:  map[string]uint64{},
 		entryCursor: map[int]entryCursor{},
 		stepSize:    int64(selConn.LoadSize),
+		clientName:  connConfig.ClientName,
+		keyFilter:   selConn.DefaultFilter,
+		scanFilter:  scanFilter,
+		separator:   selConn.KeySeparator,
 		db:          db,
 	}
 	if item.stepSize <= 0 {
 		item.stepSize = consts.DEFAULT_LOAD_SIZE
 	}
+	if len(item.keyFilter) <= 0 {
+		item.keyFilter = consts.DEFAULT_KEY_FILTER
+	}
+	if len(item.separator) <= 0 {
+		item.separator = consts.DEFAULT_KEY_SEPARATOR
+	}
+	item.separators = keySeparators(item.separator, selConn.KeySeparators)
 	b.connMap[server] = item
+	go b.watchConnection(server, item)
 	return
 }
 
+// merge separators of key namespaces, duplicated and empty ones are removed
+func keySeparators(separator string, extra []string) []string {
+	separators := []string{separator}
+	for _, sep := range extra {
+		if len(sep) > 0 && !slices.Contains(separators, sep) {
+			separators = append(separators, sep)
+		}
+	}
+	return separators
+}
+
+// update separators of opened connection and its sessions, no need to reconnect
+func (b *browserService) updateKeySeparators(name string, separator string, extra []string) {
+	if len(separator) <= 0 {
+		separator = consts.DEFAULT_KEY_SEPARATOR
+	}
+	separators := keySeparators(separator, extra)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for server, item := range b.connMap {
+		if connName, _ := splitSession(server); connName == name {
+			item.separator, item.separators = separator, separators
+		}
+	}
+}
+
+// watchConnection check connection health periodically, retry with exponential backoff after connection dropped
+// and emit connection state event "conn:state:<server>" with state connected/reconnecting/failed
+func (b *browserService) watchConnection(server string, item *connectionItem) {
+	const checkInterval = 5 * time.Second
+	const maxBackoff = 30 * time.Second
+	const maxRetries = 10
+
+	eventName := "conn:state:" + server
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-item.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := item.client.Ping(item.ctx).Err(); err == nil || item.ctx.Err() != nil {
+			continue
+		}
+
+		// connection dropped, retry until recovered or reach max retries
+		recovered := false
+		backoff := time.Second
+		for attempt := 1; attempt <= maxRetries && !recovered; attempt++ {
+			runtime.EventsEmit(b.ctx, eventName, map[string]any{
+				"state":   "reconnecting",
+				"attempt": attempt,
+				"delay":   backoff.Milliseconds(),
+			})
+			select {
+			case <-item.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			recovered = item.client.Ping(item.ctx).Err() == nil
+			backoff = min(backoff*2, maxBackoff)
+		}
+
+		if !recovered {
+			// running subscriptions and monitors on this server are interrupted
+			runtime.EventsEmit(b.ctx, eventName, map[string]any{
+				"state":       "failed",
+				"interrupted": true,
+			})
+			return
+		}
+		// scan cursors are kept in connection item, so that loading keys can resume after reconnected
+		runtime.EventsEmit(b.ctx, eventName, map[string]any{
+			"state": "connected",
+		})
+	}
+}
+
 // load current database size
 func (b *browserService) loadDBSize(ctx context.Context, client redis.UniversalClient) int64 {
 	keyCount, _ := client.DBSize(ctx).Result()
@@ -369,6 +579,8 @@ func (b *browserService) setClientCursor(server string, db int, cursor uint64) {
 	if _, ok := b.connMap[server]; ok {
 		if cursor == 0 {
 			delete(b.connMap[server].cursor, db)
+			// reset cursors of all cluster nodes too
+			b.connMap[server].nodeCursor = map[string]uint64{}
 		} else {
 			b.connMap[server].cursor[db] = cursor
 		}
@@ -433,1131 +645,4662 @@ func (b *browserService) ServerInfo(name string) (resp types.JSResp) {
 	return
 }
 
-// OpenDatabase open select database, and list all keys
-// @param path contain connection name and db name
-func (b *browserService) OpenDatabase(server string, db int) (resp types.JSResp) {
-	b.setClientCursor(server, db, 0)
-
-	item, err := b.getRedisClient(server, db)
+// GetPoolStats get connection pool stats of server, sum of all nodes in cluster mode
+func (b *browserService) GetPoolStats(server string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, -1)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
-	client, ctx := item.client, item.ctx
-	maxKeys := b.loadDBSize(ctx, client)
 
+	stats := item.client.PoolStats()
 	resp.Success = true
-	resp.Data = map[string]any{
-		"maxKeys": maxKeys,
+	resp.Data = types.ConnectionPoolStats{
+		Hits:         stats.Hits,
+		Misses:       stats.Misses,
+		Timeouts:     stats.Timeouts,
+		WaitCount:    stats.WaitCount,
+		WaitDuration: time.Duration(stats.WaitDurationNs).Milliseconds(),
+		TotalConns:   stats.TotalConns,
+		IdleConns:    stats.IdleConns,
+		StaleConns:   stats.StaleConns,
 	}
 	return
 }
 
-// scan keys
-// @return loaded keys
-// @return next cursor
-// @return scan error
-func (b *browserService) scanKeys(ctx context.Context, client redis.UniversalClient, match, keyType string, cursor uint64, count int64) ([]any, uint64, error) {
-	var err error
-	filterType := len(keyType) > 0
-	scanSize := int64(Preferences().GetScanSize())
-	// define sub scan function
-	scan := func(ctx context.Context, cli redis.UniversalClient, count int64, appendFunc func(k []any)) error {
-		var loadedKey []string
-		var scanCount int64
-		for {
-			if filterType {
-				loadedKey, cursor, err = cli.ScanType(ctx, cursor, match, scanSize, keyType).Result()
-			} else {
-				loadedKey, cursor, err = cli.Scan(ctx, cursor, match, scanSize).Result()
-			}
-			if err != nil {
-				return err
-			} else {
-				ks := sliceutil.Map(loadedKey, func(i int) any {
-					return strutil.EncodeRedisKey(loadedKey[i])
-				})
-				scanCount += int64(len(ks))
-				appendFunc(ks)
-			}
-
-			if (count > 0 && scanCount > count) || cursor == 0 {
-				break
-			}
-		}
-		return nil
-	}
-
-	keys := make([]any, 0)
-	if cluster, ok := client.(*redis.ClusterClient); ok {
-		// cluster mode
-		var mutex sync.Mutex
-		var totalMaster int64
-		cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
-			totalMaster += 1
-			return nil
-		})
-		partCount := count / max(totalMaster, 1)
-		err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
-			// FIXME: BUG? can not fully load in cluster mode? maybe remove the shared "cursor"
-			return scan(ctx, cli, partCount, func(k []any) {
-				mutex.Lock()
-				keys = append(keys, k...)
-				mutex.Unlock()
-			})
-		})
-	} else {
-		err = scan(ctx, client, count, func(k []any) {
-			keys = append(keys, k...)
-		})
-	}
-	if err != nil {
-		return keys, cursor, err
-	}
-	return keys, cursor, nil
-}
-
-// check if key exists
-func (b *browserService) existsKey(ctx context.Context, client redis.UniversalClient, key, keyType string) bool {
-	var keyExists atomic.Bool
-	if cluster, ok := client.(*redis.ClusterClient); ok {
-		// cluster mode
-		cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
-			if n := cli.Exists(ctx, key).Val(); n > 0 {
-				if len(keyType) <= 0 || strings.ToLower(keyType) == cli.Type(ctx, key).Val() {
-					keyExists.Store(true)
-				}
-			}
-			return nil
-		})
-	} else {
-		if n := client.Exists(ctx, key).Val(); n > 0 {
-			if len(keyType) <= 0 || strings.ToLower(keyType) == client.Type(ctx, key).Val() {
-				keyExists.Store(true)
-			}
-		}
-	}
-	return keyExists.Load()
-}
-
-// LoadNextKeys load next key from saved cursor
-func (b *browserService) LoadNextKeys(server string, db int, match, keyType string, exactMatch bool) (resp types.JSResp) {
-	item, err := b.getRedisClient(server, db)
+// GetReplicaTopology get master-replica topology of server
+func (b *browserService) GetReplicaTopology(server string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, -1)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
-	if match == "*" {
-		exactMatch = false
-	}
 
-	client, ctx, count := item.client, item.ctx, item.stepSize
-	var matchKeys []any
-	var maxKeys int64
-	cursor := item.cursor[db]
-	fullScan := match == "*" || match == ""
-	if exactMatch && !fullScan {
-		if b.existsKey(ctx, client, match, keyType) {
-			matchKeys = []any{match}
-			maxKeys = 1
+	client, ctx := item.client, item.ctx
+	var topology types.ReplicationTopology
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		var res string
+		if res, err = cluster.ClusterNodes(ctx).Result(); err != nil {
+			resp.Msg = err.Error()
+			return
 		}
-		b.setClientCursor(server, db, 0)
+		topology.Mode = "cluster"
+		topology.Shards = b.parseClusterNodes(res)
 	} else {
-		matchKeys, cursor, err = b.scanKeys(ctx, client, match, keyType, cursor, count)
-		if err != nil {
+		var res string
+		if res, err = client.Info(ctx, "replication").Result(); err != nil {
 			resp.Msg = err.Error()
 			return
 		}
-		b.setClientCursor(server, db, cursor)
-		if fullScan {
-			maxKeys = b.loadDBSize(ctx, client)
-		} else {
-			maxKeys = int64(len(matchKeys))
-		}
+		topology.Mode = "standalone"
+		topology.Shards = []types.ReplicationShard{b.parseReplicationInfo(res, client)}
 	}
 
 	resp.Success = true
-	resp.Data = map[string]any{
-		"keys":    matchKeys,
-		"end":     cursor == 0,
-		"maxKeys": maxKeys,
-	}
+	resp.Data = topology
 	return
 }
 
-// LoadNextAllKeys load next all keys
-func (b *browserService) LoadNextAllKeys(server string, db int, match, keyType string, exactMatch bool) (resp types.JSResp) {
-	item, err := b.getRedisClient(server, db)
+// TriggerBackup start background saving by "BGSAVE", or rewriting append only file by "BGREWRITEAOF" if aof is true
+// the completion can be polled by GetBackupState
+func (b *browserService) TriggerBackup(server string, aof bool) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, -1)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
 	client, ctx := item.client, item.ctx
-	var matchKeys []any
-	var maxKeys int64
-	fullScan := match == "*" || match == ""
-	if exactMatch && !fullScan {
-		if b.existsKey(ctx, client, match, keyType) {
-			matchKeys = []any{match}
-			maxKeys = 1
-		}
+	if aof {
+		err = client.BgRewriteAOF(ctx).Err()
 	} else {
-		cursor := item.cursor[db]
-		matchKeys, _, err = b.scanKeys(ctx, client, match, keyType, cursor, 0)
-		if err != nil {
-			resp.Msg = err.Error()
-			return
-		}
-		b.setClientCursor(server, db, 0)
-		if fullScan {
-			maxKeys = b.loadDBSize(ctx, client)
-		} else {
-			maxKeys = int64(len(matchKeys))
-		}
+		err = client.BgSave(ctx).Err()
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
 	}
 
 	resp.Success = true
-	resp.Data = map[string]any{
-		"keys":    matchKeys,
-		"maxKeys": maxKeys,
-	}
 	return
 }
 
-// LoadAllKeys load all keys
-func (b *browserService) LoadAllKeys(server string, db int, match, keyType string, exactMatch bool) (resp types.JSResp) {
-	item, err := b.getRedisClient(server, db)
+// GetBackupState get persistence state from "INFO persistence"
+func (b *browserService) GetBackupState(server string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, -1)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
 	client, ctx := item.client, item.ctx
-	var matchKeys []any
-	fullScan := match == "*" || match == ""
-	if exactMatch && !fullScan {
-		if b.existsKey(ctx, client, match, keyType) {
-			matchKeys = []any{match}
-		}
-	} else {
-		matchKeys, _, err = b.scanKeys(ctx, client, match, keyType, 0, 0)
-		if err != nil {
-			resp.Msg = err.Error()
-			return
-		}
+	res, err := client.Info(ctx, "persistence").Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
 	}
 
+	info := b.parseInfo(res)["Persistence"]
+	rdbLastSave, _ := strconv.ParseInt(info["rdb_last_save_time"], 10, 64)
+	rdbChanges, _ := strconv.ParseInt(info["rdb_changes_since_last_save"], 10, 64)
 	resp.Success = true
-	resp.Data = map[string]any{
-		"keys": matchKeys,
+	resp.Data = types.BackupState{
+		Loading:             info["loading"] == "1",
+		RDBInProgress:       info["rdb_bgsave_in_progress"] == "1",
+		RDBLastSaveTime:     rdbLastSave,
+		RDBLastStatus:       info["rdb_last_bgsave_status"],
+		RDBChanges:          rdbChanges,
+		AOFEnabled:          info["aof_enabled"] == "1",
+		AOFRewriteProgress:  info["aof_rewrite_in_progress"] == "1",
+		AOFRewriteScheduled: info["aof_rewrite_scheduled"] == "1",
+		AOFLastStatus:       info["aof_last_bgrewrite_status"],
 	}
 	return
 }
 
-func (b *browserService) GetKeyType(param types.KeySummaryParam) (resp types.JSResp) {
-	item, err := b.getRedisClient(param.Server, param.DB)
+// DownloadBackup download rdb file of server to local path through ssh, only for connections over ssh
+// location of rdb file is read by "CONFIG GET dir" and "CONFIG GET dbfilename",
+// and the file is read on ssh server, so redis should be running on the same host
+func (b *browserService) DownloadBackup(server, localPath string) (resp types.JSResp) {
+	conf := Connection().getConnection(server)
+	if conf == nil {
+		resp.Msg = fmt.Sprintf("no match connection \"%s\"", server)
+		return
+	}
+	if !conf.SSH.Enable {
+		resp.Msg = "only available for connections over ssh"
+		return
+	}
+	item, err := b.getRedisClient(server, -1)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
 	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(param.Key)
-	var keyType string
-	keyType, err = client.Type(ctx, key).Result()
+	cfg, err := client.ConfigGet(ctx, "dir").Result()
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
-
-	if keyType == "none" {
-		resp.Msg = "key not exists"
+	dbCfg, err := client.ConfigGet(ctx, "dbfilename").Result()
+	if err != nil {
+		resp.Msg = err.Error()
 		return
 	}
-
-	var data types.KeySummary
-	switch keyType {
-	case "ReJSON-RL":
-		data.Type = "JSON"
-	default:
-		data.Type = strings.ToLower(keyType)
+	if len(cfg["dir"]) <= 0 || len(dbCfg["dbfilename"]) <= 0 {
+		resp.Msg = "location of rdb file is unavailable"
+		return
 	}
+	remotePath := path.Join(cfg["dir"], dbCfg["dbfilename"])
 
-	resp.Success = true
-	resp.Data = data
-	return
-}
-
-// GetKeySummary get key summary info
-func (b *browserService) GetKeySummary(param types.KeySummaryParam) (resp types.JSResp) {
-	item, err := b.getRedisClient(param.Server, param.DB)
+	connConfig, err := Connection().resolveEnv(conf.ConnectionConfig)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	forward, err := Connection().buildProxyDialer(connConfig.Proxy)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	tunnel, err := Connection().buildSSHTunnel(connConfig, forward)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	defer tunnel.Close()
+	session, err := tunnel.Session()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	defer session.Close()
 
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(param.Key)
-
-	pipe := client.Pipeline()
-	typeVal := pipe.Type(ctx, key)
-	ttlVal := pipe.TTL(ctx, key)
-	_, err = pipe.Exec(ctx)
+	file, err := os.Create(localPath)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	defer file.Close()
 
-	if typeVal.Err() != nil {
-		resp.Msg = typeVal.Err().Error()
+	var stderr strings.Builder
+	session.Stdout = file
+	session.Stderr = &stderr
+	// quote path for remote shell
+	if err = session.Run("cat '" + strings.ReplaceAll(remotePath, "'", "'\\''") + "'"); err != nil {
+		file.Close()
+		os.Remove(localPath)
+		if stderr.Len() > 0 {
+			err = errors.New(strings.TrimSpace(stderr.String()))
+		}
+		resp.Msg = err.Error()
 		return
 	}
-	size, _ := client.MemoryUsage(ctx, key, 0).Result()
-	data := types.KeySummary{
-		Type: typeVal.Val(),
-		Size: size,
+	stat, _ := file.Stat()
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"remotePath": remotePath,
+		"path":       localPath,
+		"size":       stat.Size(),
 	}
-	if data.Type == "none" {
-		resp.Msg = "key not exists"
-		return
+	return
+}
+
+// parse "info replication", the current node could be master or replica
+func (b *browserService) parseReplicationInfo(info string, client redis.UniversalClient) types.ReplicationShard {
+	replication := b.parseInfo(info)["Replication"]
+	var selfAddr string
+	if cli, ok := client.(*redis.Client); ok {
+		selfAddr = cli.Options().Addr
 	}
 
-	if ttlVal.Err() != nil {
-		data.TTL = -1
+	var shard types.ReplicationShard
+	shard.Replicas = []types.ReplicaNode{}
+	if replication["role"] == "master" {
+		shard.Master = types.ReplicaNode{
+			Addr: selfAddr,
+			Role: "master",
+		}
+		shard.Master.Offset, _ = strconv.ParseInt(replication["master_repl_offset"], 10, 64)
+		// slave0:ip=127.0.0.1,port=6380,state=online,offset=1234,lag=0
+		count, _ := strconv.Atoi(replication["connected_slaves"])
+		for i := 0; i < count; i++ {
+			fields := map[string]string{}
+			for _, kv := range strings.Split(replication["slave"+strconv.Itoa(i)], ",") {
+				if k, v, found := strings.Cut(kv, "="); found {
+					fields[k] = v
+				}
+			}
+			node := types.ReplicaNode{
+				Addr:  net.JoinHostPort(fields["ip"], fields["port"]),
+				Role:  "replica",
+				State: fields["state"],
+			}
+			node.Offset, _ = strconv.ParseInt(fields["offset"], 10, 64)
+			node.Lag, _ = strconv.ParseInt(fields["lag"], 10, 64)
+			shard.Replicas = append(shard.Replicas, node)
+		}
 	} else {
-		if ttlVal.Val() < 0 {
-			data.TTL = -1
+		shard.Master = types.ReplicaNode{
+			Addr:  net.JoinHostPort(replication["master_host"], replication["master_port"]),
+			Role:  "master",
+			State: replication["master_link_status"],
+		}
+		self := types.ReplicaNode{
+			Addr:  selfAddr,
+			Role:  "replica",
+			State: replication["master_link_status"],
+		}
+		self.Offset, _ = strconv.ParseInt(replication["slave_repl_offset"], 10, 64)
+		shard.Replicas = append(shard.Replicas, self)
+	}
+	return shard
+}
+
+// group cluster nodes into shards by master
+func (b *browserService) parseClusterNodes(content string) []types.ReplicationShard {
+	var shards []types.ReplicationShard
+	shardIndex := map[string]int{}
+	getShard := func(masterID string) *types.ReplicationShard {
+		idx, ok := shardIndex[masterID]
+		if !ok {
+			shards = append(shards, types.ReplicationShard{
+				Replicas: []types.ReplicaNode{},
+			})
+			idx = len(shards) - 1
+			shardIndex[masterID] = idx
+		}
+		return &shards[idx]
+	}
+
+	for _, node := range redis2.ParseClusterNodes(content) {
+		replicaNode := types.ReplicaNode{
+			ID:    node.ID,
+			Addr:  node.Addr,
+			Role:  node.Role,
+			State: node.Flags,
+		}
+		if node.Role == "master" {
+			getShard(node.ID).Master = replicaNode
 		} else {
-			data.TTL = int64(ttlVal.Val().Seconds())
+			shard := getShard(node.MasterID)
+			shard.Replicas = append(shard.Replicas, replicaNode)
 		}
 	}
+	return shards
+}
 
-	switch data.Type {
-	case "string":
-		data.Length, err = client.StrLen(ctx, key).Result()
-	case "list":
-		data.Length, err = client.LLen(ctx, key).Result()
-	case "hash":
-		data.Length, err = client.HLen(ctx, key).Result()
-	case "set":
-		data.Length, err = client.SCard(ctx, key).Result()
-	case "zset":
-		data.Length, err = client.ZCard(ctx, key).Result()
-	case "stream":
-		data.Length, err = client.XLen(ctx, key).Result()
-	case "ReJSON-RL":
-		data.Type = "JSON"
-		data.Length = 0
-	default:
-		err = errors.New("unknown key type")
+// OpenReplicaSession create a session connect to replica node directly
+// @param readonly send READONLY to cluster replica node, so that read commands can be served
+func (b *browserService) OpenReplicaSession(server, addr string, readonly bool) (resp types.JSResp) {
+	if Connection().getConnection(server) == nil {
+		resp.Msg = fmt.Sprintf("no match connection \"%s\"", server)
+		return
 	}
 
-	if err != nil {
+	name, _ := splitSession(server)
+	session := sessionName(name, b.sessionSeq.Add(1))
+	if err := Connection().setReplicaTarget(session, addr, readonly); err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
 	resp.Success = true
-	resp.Data = data
+	resp.Data = map[string]any{
+		"session": session,
+	}
 	return
 }
 
-// GetKeyDetail get key detail
-func (b *browserService) GetKeyDetail(param types.KeyDetailParam) (resp types.JSResp) {
-	item, err := b.getRedisClient(param.Server, param.DB)
-	if err != nil {
-		resp.Msg = err.Error()
-		return
-	}
+// OpenDatabase open select database, and list all keys
+// @param path contain connection name and db name
+func (b *browserService) OpenDatabase(server string, db int) (resp types.JSResp) {
+	b.setClientCursor(server, db, 0)
 
-	client, ctx, entryCors := item.client, item.ctx, item.entryCursor
-	key := strutil.DecodeRedisKey(param.Key)
-	var keyType string
-	keyType, err = client.Type(ctx, key).Result()
+	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	client, ctx := item.client, item.ctx
+	maxKeys := b.loadDBSize(ctx, client)
 
-	if keyType == "none" {
-		resp.Msg = "key not exists"
-		return
-	}
-	var doConvert bool
-	if (len(param.Decode) > 0 && param.Decode != types.DECODE_NONE) ||
-		(len(param.Format) > 0 && param.Format != types.FORMAT_RAW) {
-		doConvert = true
-	}
-
-	var data types.KeyDetail
-	data.KeyType = strings.ToLower(keyType)
-	//var cursor uint64
-	matchPattern := param.MatchPattern
-	if len(matchPattern) <= 0 {
-		matchPattern = "*"
+	resp.Success = true
+	resp.Data = map[string]any{
+		"maxKeys": maxKeys,
 	}
+	return
+}
 
-	// define get entry cursor function
-	getEntryCursor := func() (uint64, string, bool) {
-		if entry, ok := entryCors[param.DB]; !ok || entry.Key != key || entry.Pattern != matchPattern {
-			// not the same key or match pattern, reset cursor
-			entry = entryCursor{
-				DB:      param.DB,
-				Key:     key,
-				Pattern: matchPattern,
-				Cursor:  0,
+// scan keys of a single node from cursor
+// @return next cursor
+// @return scan error
+func (b *browserService) scanNodeKeys(ctx context.Context, cli redis.UniversalClient, match, keyType string, filter *keyScanFilter, cursor uint64, count int64, appendFunc func(k []any)) (uint64, error) {
+	var loadedKey []string
+	var scanCount int64
+	var err error
+	filterType := len(keyType) > 0
+	filterHLL := isHyperLogLogType(keyType)
+	keyType = scanTypeName(keyType)
+	// TYPE option of SCAN is supported since redis 6.0, fallback to check type of each key if not supported
+	typeByScan := true
+	scanSize := int64(Preferences().GetScanSize())
+	for {
+		if filterType && typeByScan {
+			var nextCursor uint64
+			loadedKey, nextCursor, err = cli.ScanType(ctx, cursor, match, scanSize, keyType).Result()
+			if err != nil && strings.Contains(strings.ToLower(err.Error()), "syntax error") {
+				typeByScan = false
+				continue
 			}
-			entryCors[param.DB] = entry
-			return 0, "", true
+			cursor = nextCursor
 		} else {
-			return entry.Cursor, entry.XLast, false
+			loadedKey, cursor, err = cli.Scan(ctx, cursor, match, scanSize).Result()
+			if err == nil && filterType {
+				loadedKey, err = b.filterKeysByType(ctx, cli, loadedKey, keyType)
+			}
 		}
-	}
-	// define set entry cursor function
-	setEntryCursor := func(cursor uint64) {
-		entryCors[param.DB] = entryCursor{
-			DB:      param.DB,
-			Type:    "",
-			Key:     key,
-			Pattern: matchPattern,
-			Cursor:  cursor,
+		if err == nil && filterHLL {
+			loadedKey, err = b.filterHyperLogLogKeys(ctx, cli, loadedKey)
 		}
-	}
-	// define set last stream pos function
-	setEntryXLast := func(last string) {
-		entryCors[param.DB] = entryCursor{
-			DB:      param.DB,
-			Type:    "",
-			Key:     key,
-			Pattern: matchPattern,
-			XLast:   last,
+		if err != nil {
+			return cursor, err
+		} else {
+			ks := sliceutil.FilterMap(loadedKey, func(i int) (any, bool) {
+				return strutil.EncodeRedisKey(loadedKey[i]), filter.match(loadedKey[i])
+			})
+			scanCount += int64(len(ks))
+			appendFunc(ks)
+		}
+
+		if (count > 0 && scanCount > count) || cursor == 0 {
+			break
 		}
 	}
+	return cursor, nil
+}
 
-	decoder := Preferences().GetDecoder()
+// scan keys
+// @return loaded keys
+// @return next cursor
+// @return scan error
+func (b *browserService) scanKeys(ctx context.Context, client redis.UniversalClient, match, keyType string, filter *keyScanFilter, cursor uint64, count int64) ([]any, uint64, error) {
+	var err error
+	keys := make([]any, 0)
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		// cluster mode, scan all master nodes from beginning
+		nodeCursor := map[string]uint64{}
+		keys, _, err = b.scanClusterKeys(ctx, cluster, match, keyType, filter, nodeCursor, count)
+		return keys, 0, err
+	}
 
-	switch data.KeyType {
-	case "string":
-		var str string
-		str, err = client.Get(ctx, key).Result()
-		data.Value = strutil.EncodeRedisKey(str)
-		//data.Value, data.Decode, data.Format = convutil.ConvertTo(str, param.Decode, param.Format, decoder)
+	cursor, err = b.scanNodeKeys(ctx, client, match, keyType, filter, cursor, count, func(k []any) {
+		keys = append(keys, k...)
+	})
+	if err != nil {
+		return keys, cursor, err
+	}
+	return keys, cursor, nil
+}
 
-	case "list":
-		loadListHandle := func() ([]types.ListEntryItem, bool, bool, error) {
-			var loadVal []string
-			var cursor uint64
-			var reset bool
-			var subErr error
-			doFilter := matchPattern != "*"
-			if param.Full || doFilter {
-				// load all
-				cursor, reset = 0, true
-				loadVal, subErr = client.LRange(ctx, key, 0, -1).Result()
-			} else {
-				if param.Reset {
-					cursor, reset = 0, true
-				} else {
-					cursor, _, reset = getEntryCursor()
-				}
-				scanSize := int64(Preferences().GetScanSize())
-				loadVal, subErr = client.LRange(ctx, key, int64(cursor), int64(cursor)+scanSize-1).Result()
-				cursor = cursor + uint64(scanSize)
-				if len(loadVal) < int(scanSize) {
-					cursor = 0
-				}
+// scan keys matching pattern and type over all nodes and process them in batches,
+// keyType is the type name filtered in frontend, see also scanTypeName
+// fn is called concurrently for different master nodes in cluster mode
+func (b *browserService) scanKeysInBatch(ctx context.Context, client redis.UniversalClient, pattern, keyType string, batchSize int,
+	fn func(ctx context.Context, cli redis.UniversalClient, keys []string) error) error {
+	if isHyperLogLogType(keyType) {
+		process := fn
+		fn = func(ctx context.Context, cli redis.UniversalClient, keys []string) error {
+			hllKeys, err := b.filterHyperLogLogKeys(ctx, cli, keys)
+			if err != nil || len(hllKeys) <= 0 {
+				return err
 			}
-			setEntryCursor(cursor)
-
-			items := make([]types.ListEntryItem, 0, len(loadVal))
-			for _, val := range loadVal {
-				if doFilter && !strings.Contains(val, param.MatchPattern) {
-					continue
-				}
-				items = append(items, types.ListEntryItem{
-					Index: len(items),
-					Value: strutil.EncodeRedisKey(val),
-				})
-				if doConvert {
-					if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
-						items[len(items)-1].DisplayValue = dv
-					}
+			return process(ctx, cli, hllKeys)
+		}
+	}
+	keyType = scanTypeName(keyType)
+	scan := func(ctx context.Context, cli redis.UniversalClient) error {
+		scanSize := int64(Preferences().GetScanSize())
+		var iter *redis.ScanIterator
+		if len(keyType) > 0 {
+			iter = cli.ScanType(ctx, 0, pattern, scanSize, keyType).Iterator()
+		} else {
+			iter = cli.Scan(ctx, 0, pattern, scanSize).Iterator()
+		}
+		keys := make([]string, 0, batchSize)
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+			if len(keys) >= batchSize {
+				if err := fn(ctx, cli, keys); err != nil {
+					return err
 				}
+				keys = keys[:0:cap(keys)]
 			}
-			if subErr != nil {
-				return items, reset, false, subErr
-			}
-			return items, reset, cursor == 0, nil
 		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			return fn(ctx, cli, keys)
+		}
+		return nil
+	}
 
-		data.Value, data.Reset, data.End, err = loadListHandle()
-		data.Match, data.Decode, data.Format = param.MatchPattern, param.Decode, param.Format
-		if err != nil {
-			resp.Msg = err.Error()
-			return
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		// cluster mode
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
+			return scan(ctx, cli)
+		})
+	}
+	return scan(ctx, client)
+}
+
+// scan keys from all master nodes in cluster mode
+// each node keeps its own cursor in nodeCursor, which is keyed by node address:
+// absent means not scanned yet, zero means fully scanned
+// @return loaded keys
+// @return all nodes fully scanned
+// @return scan error
+func (b *browserService) scanClusterKeys(ctx context.Context, cluster *redis.ClusterClient, match, keyType string, filter *keyScanFilter, nodeCursor map[string]uint64, count int64) ([]any, bool, error) {
+	var mutex sync.Mutex
+	var totalMaster int64
+	cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
+		mutex.Lock()
+		totalMaster += 1
+		mutex.Unlock()
+		return nil
+	})
+	partCount := count / max(totalMaster, 1)
+	var replicas map[string]*redis.Client
+	if cluster.Options().ReadOnly {
+		replicas = b.clusterReplicas(ctx, cluster)
+	}
+
+	keys := make([]any, 0)
+	var end atomic.Bool
+	end.Store(true)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
+		addr := cli.Options().Addr
+		mutex.Lock()
+		cursor, scanned := nodeCursor[addr]
+		mutex.Unlock()
+		if scanned && cursor == 0 {
+			// this node has been fully scanned
+			return nil
 		}
 
-	case "hash":
-		if !strings.HasPrefix(matchPattern, "*") {
-			matchPattern = "*" + matchPattern
+		// scan on replica if reading from replicas is enabled, the cursor is still keyed by master address
+		scanCli := cli
+		if replica, ok := replicas[addr]; ok {
+			scanCli = replica
 		}
-		if !strings.HasSuffix(matchPattern, "*") {
-			matchPattern = matchPattern + "*"
+		cursor, err := b.scanNodeKeys(ctx, scanCli, match, keyType, filter, cursor, partCount, func(k []any) {
+			mutex.Lock()
+			keys = append(keys, k...)
+			mutex.Unlock()
+		})
+		mutex.Lock()
+		nodeCursor[addr] = cursor
+		mutex.Unlock()
+		if cursor != 0 {
+			end.Store(false)
 		}
-		loadHashHandle := func() ([]types.HashEntryItem, bool, bool, error) {
-			var items []types.HashEntryItem
-			var loadedVal []string
-			var cursor uint64
-			var reset bool
-			var subErr error
-			scanSize := int64(Preferences().GetScanSize())
-			if param.Full || matchPattern != "*" {
-				// load all
-				cursor, reset = 0, true
-				items = []types.HashEntryItem{}
-				for {
-					loadedVal, cursor, subErr = client.HScan(ctx, key, cursor, matchPattern, scanSize).Result()
-					if subErr != nil {
-						return nil, reset, false, subErr
-					}
-					for i := 0; i < len(loadedVal); i += 2 {
-						items = append(items, types.HashEntryItem{
-							Key:   loadedVal[i],
-							Value: strutil.EncodeRedisKey(loadedVal[i+1]),
-						})
-						if doConvert {
-							if dv, _, _ := convutil.ConvertTo(loadedVal[i+1], param.Decode, param.Format, decoder); dv != loadedVal[i+1] {
-								items[len(items)-1].DisplayValue = dv
-							}
-						}
-					}
-					if cursor == 0 {
-						break
-					}
-				}
-			} else {
-				if param.Reset {
-					cursor, reset = 0, true
-				} else {
-					cursor, _, reset = getEntryCursor()
-				}
-				loadedVal, cursor, subErr = client.HScan(ctx, key, cursor, matchPattern, scanSize).Result()
-				if subErr != nil {
-					return nil, reset, false, subErr
-				}
-				loadedLen := len(loadedVal)
-				items = make([]types.HashEntryItem, loadedLen/2)
-				for i := 0; i < loadedLen; i += 2 {
-					items[i/2].Key = loadedVal[i]
-					items[i/2].Value = strutil.EncodeRedisKey(loadedVal[i+1])
-					if doConvert {
-						if dv, _, _ := convutil.ConvertTo(loadedVal[i+1], param.Decode, param.Format, decoder); dv != loadedVal[i+1] {
-							items[i/2].DisplayValue = dv
-						}
-					}
-				}
+		return err
+	})
+	return keys, end.Load(), err
+}
+
+// cached replicas of cluster, keyed by master address
+type replicaCacheItem struct {
+	replicas map[string]*redis.Client
+	expire   time.Time
+}
+
+// get one replica client of each master in cluster mode, keyed by master address
+// the replica with the smallest address is chosen, so that scan cursor can be continued on the same node.
+// topology is cached for a while, instead of querying slots for every batch of scanning
+func (b *browserService) clusterReplicas(ctx context.Context, cluster *redis.ClusterClient) map[string]*redis.Client {
+	const cacheDuration = 30 * time.Second
+	now := time.Now()
+	b.replicaMutex.Lock()
+	defer b.replicaMutex.Unlock()
+	if cached, ok := b.replicaCache[cluster]; ok && now.Before(cached.expire) {
+		return cached.replicas
+	}
+	// drop expired caches, including those of closed clients
+	for cli, cached := range b.replicaCache {
+		if !now.Before(cached.expire) {
+			delete(b.replicaCache, cli)
+		}
+	}
+
+	slots, err := cluster.ClusterSlots(ctx).Result()
+	if err != nil {
+		return nil
+	}
+	masterOf := map[string]string{}
+	for _, slot := range slots {
+		for i := 1; i < len(slot.Nodes); i++ {
+			masterOf[slot.Nodes[i].Addr] = slot.Nodes[0].Addr
+		}
+	}
+
+	var mutex sync.Mutex
+	replicas := map[string]*redis.Client{}
+	_ = cluster.ForEachSlave(ctx, func(ctx context.Context, cli *redis.Client) error {
+		addr := cli.Options().Addr
+		mutex.Lock()
+		defer mutex.Unlock()
+		if master, ok := masterOf[addr]; ok {
+			if exist, ok := replicas[master]; !ok || exist.Options().Addr > addr {
+				replicas[master] = cli
 			}
-			setEntryCursor(cursor)
-			return items, reset, cursor == 0, nil
 		}
+		return nil
+	})
+	b.replicaCache[cluster] = replicaCacheItem{
+		replicas: replicas,
+		expire:   now.Add(cacheDuration),
+	}
+	return replicas
+}
 
-		data.Value, data.Reset, data.End, err = loadHashHandle()
-		data.Match, data.Decode, data.Format = param.MatchPattern, param.Decode, param.Format
-		if err != nil {
-			resp.Msg = err.Error()
-			return
+// scan next keys from saved cursor, and save the new cursor after scanning
+// @return loaded keys
+// @return scan finished
+// @return scan error
+func (b *browserService) scanNextKeys(item *connectionItem, server string, db int, match, keyType string, count int64) ([]any, bool, error) {
+	client, ctx := item.client, item.ctx
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		// cluster mode, continue with cursor of each master node
+		return b.scanClusterKeys(ctx, cluster, match, keyType, item.scanFilter, item.nodeCursor, count)
+	}
+
+	keys, cursor, err := b.scanKeys(ctx, client, match, keyType, item.scanFilter, item.cursor[db], count)
+	if err != nil {
+		return keys, false, err
+	}
+	b.setClientCursor(server, db, cursor)
+	return keys, cursor == 0, nil
+}
+
+// convert key type filtered in frontend to the type name used by SCAN and TYPE command
+func scanTypeName(keyType string) string {
+	switch strings.ToLower(keyType) {
+	case "json":
+		return "ReJSON-RL"
+	case "timeseries":
+		return "TSDB-TYPE"
+	case "hyperloglog":
+		// hyperloglog is stored as string, filtered by header after scanning
+		return "string"
+	}
+	for name, probType := range probabilisticTypes {
+		if strings.EqualFold(probType, keyType) {
+			return name
 		}
+	}
+	return strings.ToLower(keyType)
+}
 
-	case "set":
-		if !strings.HasPrefix(matchPattern, "*") {
-			matchPattern = "*" + matchPattern
+// filter keys of specified type by checking type of each key in pipeline
+func (b *browserService) filterKeysByType(ctx context.Context, cli redis.UniversalClient, keys []string, keyType string) ([]string, error) {
+	if len(keys) <= 0 {
+		return keys, nil
+	}
+	pipe := cli.Pipeline()
+	for _, key := range keys {
+		pipe.Type(ctx, key)
+	}
+	cmders, err := pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	return sliceutil.FilterMap(keys, func(i int) (string, bool) {
+		return keys[i], strings.EqualFold(cmders[i].(*redis.StatusCmd).Val(), keyType)
+	}), nil
+}
+
+// filter HyperLogLog keys from string keys by checking header of each value in pipeline
+func (b *browserService) filterHyperLogLogKeys(ctx context.Context, cli redis.UniversalClient, keys []string) ([]string, error) {
+	if len(keys) <= 0 {
+		return keys, nil
+	}
+	pipe := cli.Pipeline()
+	for _, key := range keys {
+		pipe.GetRange(ctx, key, 0, 15)
+	}
+	cmders, err := pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	return sliceutil.FilterMap(keys, func(i int) (string, bool) {
+		return keys[i], isHyperLogLog(cmders[i].(*redis.StringCmd).Val())
+	}), nil
+}
+
+// check if type filtered in frontend is hyperloglog, which can not be filtered by SCAN or TYPE
+func isHyperLogLogType(keyType string) bool {
+	return strings.EqualFold(keyType, "hyperloglog")
+}
+
+// check if key exists
+func (b *browserService) existsKey(ctx context.Context, client redis.UniversalClient, key, keyType string) bool {
+	// cluster client will route the command to the node which holds the key
+	if n := client.Exists(ctx, key).Val(); n > 0 {
+		if len(keyType) <= 0 || strings.EqualFold(scanTypeName(keyType), client.Type(ctx, key).Val()) {
+			if isHyperLogLogType(keyType) {
+				return isHyperLogLog(client.GetRange(ctx, key, 0, 15).Val())
+			}
+			return true
 		}
-		if !strings.HasSuffix(matchPattern, "*") {
-			matchPattern = matchPattern + "*"
+	}
+	return false
+}
+
+// SetKeyScanFilter set client-side filter of scanned keys, applied on top of the match pattern of SCAN
+// @param regex regular expression which keys should match, empty for no limit
+// @param excludes glob-style patterns of keys to exclude, like "session:*"
+func (b *browserService) SetKeyScanFilter(server string, regex string, excludes []string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, -1)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	var filter *keyScanFilter
+	excludes = sliceutil.FilterMap(excludes, func(i int) (string, bool) {
+		return excludes[i], len(excludes[i]) > 0
+	})
+	if len(regex) > 0 || len(excludes) > 0 {
+		filter = &keyScanFilter{
+			excludes: excludes,
 		}
-		loadSetHandle := func() ([]types.SetEntryItem, bool, bool, error) {
-			var items []types.SetEntryItem
-			var cursor uint64
-			var reset bool
-			var subErr error
-			var loadedKey []string
-			scanSize := int64(Preferences().GetScanSize())
-			if param.Full || matchPattern != "*" {
-				// load all
-				cursor, reset = 0, true
-				items = []types.SetEntryItem{}
-				for {
-					loadedKey, cursor, subErr = client.SScan(ctx, key, cursor, matchPattern, scanSize).Result()
-					if subErr != nil {
-						return items, reset, false, subErr
-					}
-					for _, val := range loadedKey {
-						items = append(items, types.SetEntryItem{
-							Value: strutil.EncodeRedisKey(val),
-						})
-						if doConvert {
-							if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
-								items[len(items)-1].DisplayValue = dv
-							}
-						}
-					}
-					if cursor == 0 {
-						break
-					}
-				}
-			} else {
-				if param.Reset {
-					cursor, reset = 0, true
-				} else {
-					cursor, _, reset = getEntryCursor()
-				}
-				loadedKey, cursor, subErr = client.SScan(ctx, key, cursor, matchPattern, scanSize).Result()
-				items = make([]types.SetEntryItem, len(loadedKey))
-				for i, val := range loadedKey {
-					items[i].Value = strutil.EncodeRedisKey(val)
-					if doConvert {
-						if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
-							items[i].DisplayValue = dv
-						}
-					}
-				}
+		if len(regex) > 0 {
+			if filter.regex, err = regexp.Compile(regex); err != nil {
+				resp.Msg = err.Error()
+				return
 			}
-			setEntryCursor(cursor)
-			return items, reset, cursor == 0, nil
 		}
+	}
+	b.mutex.Lock()
+	item.scanFilter = filter
+	b.mutex.Unlock()
+	resp.Success = true
+	return
+}
 
-		data.Value, data.Reset, data.End, err = loadSetHandle()
-		data.Match, data.Decode, data.Format = param.MatchPattern, param.Decode, param.Format
+// LoadNextKeys load next key from saved cursor
+func (b *browserService) LoadNextKeys(server string, db int, match, keyType string, exactMatch bool) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if len(match) <= 0 {
+		// use default filter of connection
+		match = item.keyFilter
+	}
+	if match == "*" {
+		exactMatch = false
+	}
+
+	client, ctx, count := item.client, item.ctx, item.stepSize
+	var matchKeys []any
+	var maxKeys int64
+	end := true
+	fullScan := match == "*" || match == ""
+	if exactMatch && !fullScan {
+		if b.existsKey(ctx, client, match, keyType) {
+			matchKeys = []any{match}
+			maxKeys = 1
+		}
+		b.setClientCursor(server, db, 0)
+	} else {
+		matchKeys, end, err = b.scanNextKeys(item, server, db, match, keyType, count)
 		if err != nil {
 			resp.Msg = err.Error()
 			return
 		}
+		if fullScan && item.scanFilter == nil {
+			maxKeys = b.loadDBSize(ctx, client)
+		} else {
+			maxKeys = int64(len(matchKeys))
+		}
+	}
 
-	case "zset":
-		if !strings.HasPrefix(matchPattern, "*") {
-			matchPattern = "*" + matchPattern
+	resp.Success = true
+	resp.Data = map[string]any{
+		"keys":    matchKeys,
+		"end":     end,
+		"maxKeys": maxKeys,
+	}
+	return
+}
+
+// LoadNextAllKeys load next all keys
+func (b *browserService) LoadNextAllKeys(server string, db int, match, keyType string, exactMatch bool) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if len(match) <= 0 {
+		// use default filter of connection
+		match = item.keyFilter
+	}
+
+	client, ctx := item.client, item.ctx
+	var matchKeys []any
+	var maxKeys int64
+	fullScan := match == "*" || match == ""
+	if exactMatch && !fullScan {
+		if b.existsKey(ctx, client, match, keyType) {
+			matchKeys = []any{match}
+			maxKeys = 1
 		}
-		if !strings.HasSuffix(matchPattern, "*") {
-			matchPattern = matchPattern + "*"
+	} else {
+		matchKeys, _, err = b.scanNextKeys(item, server, db, match, keyType, 0)
+		if err != nil {
+			resp.Msg = err.Error()
+			return
 		}
-		loadZSetHandle := func() ([]types.ZSetEntryItem, bool, bool, error) {
-			var items []types.ZSetEntryItem
-			var reset bool
-			var cursor uint64
-			scanSize := int64(Preferences().GetScanSize())
-			doFilter := matchPattern != "*"
-			if param.Full || doFilter {
-				// load all
-				var loadedVal []string
-				cursor, reset = 0, true
-				items = []types.ZSetEntryItem{}
-				for {
-					loadedVal, cursor, err = client.ZScan(ctx, key, cursor, matchPattern, scanSize).Result()
-					if err != nil {
-						return items, reset, false, err
-					}
-					var score float64
-					for i := 0; i < len(loadedVal); i += 2 {
-						if score, err = strconv.ParseFloat(loadedVal[i+1], 64); err == nil {
-							items = append(items, types.ZSetEntryItem{
-								Value: strutil.EncodeRedisKey(loadedVal[i]),
-								Score: score,
-							})
-							if doConvert {
-								if dv, _, _ := convutil.ConvertTo(loadedVal[i], param.Decode, param.Format, decoder); dv != loadedVal[i] {
-									items[len(items)-1].DisplayValue = dv
-								}
-							}
-						}
-					}
-					if cursor == 0 {
-						break
-					}
-				}
-			} else {
-				if param.Reset {
-					cursor, reset = 0, true
-				} else {
-					cursor, _, reset = getEntryCursor()
-				}
-				var loadedVal []redis.Z
-				loadedVal, err = client.ZRangeWithScores(ctx, key, int64(cursor), int64(cursor)+scanSize-1).Result()
-				cursor = cursor + uint64(scanSize)
-				if len(loadedVal) < int(scanSize) {
-					cursor = 0
+		b.setClientCursor(server, db, 0)
+		if fullScan && item.scanFilter == nil {
+			maxKeys = b.loadDBSize(ctx, client)
+		} else {
+			maxKeys = int64(len(matchKeys))
+		}
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"keys":    matchKeys,
+		"maxKeys": maxKeys,
+	}
+	return
+}
+
+// LoadAllKeys load all keys
+func (b *browserService) LoadAllKeys(server string, db int, match, keyType string, exactMatch bool) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if len(match) <= 0 {
+		// use default filter of connection
+		match = item.keyFilter
+	}
+
+	client, ctx := item.client, item.ctx
+	var matchKeys []any
+	fullScan := match == "*" || match == ""
+	if exactMatch && !fullScan {
+		if b.existsKey(ctx, client, match, keyType) {
+			matchKeys = []any{match}
+		}
+	} else {
+		matchKeys, _, err = b.scanKeys(ctx, client, match, keyType, item.scanFilter, 0, 0)
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"keys": matchKeys,
+	}
+	return
+}
+
+func (b *browserService) GetKeyType(param types.KeySummaryParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	var keyType string
+	keyType, err = client.Type(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	if keyType == "none" {
+		resp.Msg = "key not exists"
+		return
+	}
+
+	resp.Success = true
+	resp.Data = types.KeySummary{
+		Type: displayKeyType(keyType),
+	}
+	return
+}
+
+// convert type name of redis to the one used by frontend
+func displayKeyType(keyType string) string {
+	switch keyType {
+	case "ReJSON-RL":
+		return "JSON"
+	case "TSDB-TYPE":
+		return "timeseries"
+	default:
+		if probType, ok := probabilisticTypes[keyType]; ok {
+			return probType
+		}
+		return strings.ToLower(keyType)
+	}
+}
+
+// SampleRandomKeys pick random keys by "RANDOMKEY" with their type, ttl and memory usage
+func (b *browserService) SampleRandomKeys(server string, db int, count int) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if count <= 0 {
+		count = 20
+	}
+	count = min(count, 1000)
+
+	client, ctx := item.client, item.ctx
+	// random keys may be duplicated, try a few more times to fill up the count
+	keys := make([]string, 0, count)
+	keySet := coll.NewSet[string]()
+	for attempt := 0; attempt < 3 && len(keys) < count; attempt++ {
+		pipe := client.Pipeline()
+		cmds := make([]*redis.StringCmd, count-len(keys))
+		for i := range cmds {
+			cmds[i] = pipe.RandomKey(ctx)
+		}
+		if _, err = pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			resp.Msg = err.Error()
+			return
+		}
+		for _, cmd := range cmds {
+			if key, cmdErr := cmd.Result(); cmdErr == nil && !keySet.Contains(key) {
+				keySet.Add(key)
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) <= 0 {
+			// empty database
+			break
+		}
+	}
+
+	pipe := client.Pipeline()
+	typeCmds := make([]*redis.StatusCmd, len(keys))
+	ttlCmds := make([]*redis.DurationCmd, len(keys))
+	memCmds := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		typeCmds[i] = pipe.Type(ctx, key)
+		ttlCmds[i] = pipe.TTL(ctx, key)
+		memCmds[i] = pipe.MemoryUsage(ctx, key, 0)
+	}
+	// memory usage may be unavailable on some cloud services, ignore errors of single command
+	if _, err = pipe.Exec(ctx); errors.Is(err, context.Canceled) {
+		resp.Msg = err.Error()
+		return
+	}
+
+	samples := make([]map[string]any, 0, len(keys))
+	for i, key := range keys {
+		keyType := typeCmds[i].Val()
+		if keyType == "none" || len(keyType) <= 0 {
+			// removed after picked
+			continue
+		}
+		ttl := int64(-1)
+		if ttlCmds[i].Err() == nil && ttlCmds[i].Val() >= 0 {
+			ttl = int64(ttlCmds[i].Val().Seconds())
+		}
+		samples = append(samples, map[string]any{
+			"key":  strutil.EncodeRedisKey(key),
+			"type": displayKeyType(keyType),
+			"ttl":  ttl,
+			"size": memCmds[i].Val(),
+		})
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"keys": samples,
+	}
+	return
+}
+
+// GetKeySummary get key summary info
+func (b *browserService) GetKeySummary(param types.KeySummaryParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+
+	pipe := client.Pipeline()
+	typeVal := pipe.Type(ctx, key)
+	ttlVal := pipe.TTL(ctx, key)
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	if typeVal.Err() != nil {
+		resp.Msg = typeVal.Err().Error()
+		return
+	}
+	size, _ := client.MemoryUsage(ctx, key, 0).Result()
+	data := types.KeySummary{
+		Type: typeVal.Val(),
+		Size: size,
+	}
+	if data.Type == "none" {
+		resp.Msg = "key not exists"
+		return
+	}
+
+	if ttlVal.Err() != nil {
+		data.TTL = -1
+	} else {
+		if ttlVal.Val() < 0 {
+			data.TTL = -1
+		} else {
+			data.TTL = int64(ttlVal.Val().Seconds())
+			data.ExpireAt = time.Now().Add(ttlVal.Val()).Format(time.RFC3339)
+		}
+	}
+
+	switch data.Type {
+	case "string":
+		data.Length, err = client.StrLen(ctx, key).Result()
+		if err == nil && data.Length >= 16 {
+			if magic, _ := client.GetRange(ctx, key, 0, 15).Result(); isHyperLogLog(magic) {
+				// keep string type so that it can be viewed as string
+				if count, pfErr := client.PFCount(ctx, key).Result(); pfErr == nil {
+					data.Cardinality = &count
 				}
+			}
+		}
+	case "list":
+		data.Length, err = client.LLen(ctx, key).Result()
+	case "hash":
+		data.Length, err = client.HLen(ctx, key).Result()
+	case "set":
+		data.Length, err = client.SCard(ctx, key).Result()
+	case "zset":
+		data.Length, err = client.ZCard(ctx, key).Result()
+	case "stream":
+		data.Length, err = client.XLen(ctx, key).Result()
+	case "ReJSON-RL":
+		data.Type = "JSON"
+		data.Length = 0
+	case "TSDB-TYPE":
+		data.Type = "timeseries"
+		var res any
+		if res, err = client.Do(ctx, "TS.INFO", key).Result(); err == nil {
+			data.Length = redis2.ParseTimeSeriesInfo(res).TotalSamples
+		}
+	case "MBbloom--", "MBbloomCF":
+		data.Type = probabilisticTypes[data.Type]
+		var info map[string]any
+		if info, err = b.probabilisticInfo(ctx, client, data.Type, key); err == nil {
+			inserted, _ := strutil.AnyToInt(info["Number of items inserted"])
+			data.Length = int64(inserted)
+		}
+	case "CMSk-TYPE", "TopK-TYPE", "TDIS-TYPE":
+		data.Type = probabilisticTypes[data.Type]
+	default:
+		err = errors.New("unknown key type")
+	}
 
-				items = make([]types.ZSetEntryItem, 0, len(loadedVal))
-				for _, z := range loadedVal {
-					val := strutil.AnyToString(z.Member, "", 0)
-					if doFilter && !strings.Contains(val, param.MatchPattern) {
-						continue
-					}
-					entry := types.ZSetEntryItem{
-						Value: strutil.EncodeRedisKey(val),
-					}
-					if math.IsInf(z.Score, 1) {
-						entry.ScoreStr = "+inf"
-					} else if math.IsInf(z.Score, -1) {
-						entry.ScoreStr = "-inf"
-					} else {
-						entry.Score = z.Score
-					}
-					items = append(items, entry)
-					if doConvert {
-						if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
-							items[len(items)-1].DisplayValue = dv
-						}
-					}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	if param.Object {
+		pipe = client.Pipeline()
+		encodingVal := pipe.ObjectEncoding(ctx, key)
+		idleVal := pipe.ObjectIdleTime(ctx, key)
+		freqVal := pipe.ObjectFreq(ctx, key)
+		// idle time and frequency are mutually exclusive depends on maxmemory policy, ignore the failed one
+		_, _ = pipe.Exec(ctx)
+		data.Encoding = encodingVal.Val()
+		if idleVal.Err() == nil {
+			idle := int64(idleVal.Val().Seconds())
+			data.Idle = &idle
+		}
+		if freqVal.Err() == nil {
+			freq := freqVal.Val()
+			data.Freq = &freq
+		}
+	}
+
+	resp.Success = true
+	resp.Data = data
+	return
+}
+
+// GetKeyDetail get key detail
+func (b *browserService) GetKeyDetail(param types.KeyDetailParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx, entryCors := item.client, item.ctx, item.entryCursor
+	key := strutil.DecodeRedisKey(param.Key)
+	var keyType string
+	keyType, err = client.Type(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	if keyType == "none" {
+		resp.Msg = "key not exists"
+		return
+	}
+	if len(param.Decode) <= 0 || param.Decode == types.DECODE_PROTOBUF || len(param.Format) <= 0 {
+		// decode and format by rule of key in preferences if not specified,
+		// and protobuf decoding takes message type from the rule
+		decode, format := Preferences().keyRuleFor(key)
+		if len(decode) > 0 && (len(param.Decode) <= 0 || strings.HasPrefix(decode, param.Decode+":")) {
+			param.Decode = decode
+		}
+		if len(param.Format) <= 0 {
+			param.Format = format
+		}
+	}
+	var doConvert bool
+	if (len(param.Decode) > 0 && param.Decode != types.DECODE_NONE) ||
+		(len(param.Format) > 0 && param.Format != types.FORMAT_RAW) {
+		doConvert = true
+	}
+
+	var data types.KeyDetail
+	data.KeyType = strings.ToLower(keyType)
+	//var cursor uint64
+	matchPattern := param.MatchPattern
+	if len(matchPattern) <= 0 {
+		matchPattern = "*"
+	}
+
+	// define get entry cursor function
+	getEntryCursor := func() (uint64, string, bool) {
+		if param.Cursor != nil && data.KeyType != "stream" {
+			// cursor specified by frontend, could be paging back and forth
+			return *param.Cursor, "", *param.Cursor == 0
+		}
+		if entry, ok := entryCors[param.DB]; !ok || entry.Key != key || entry.Pattern != matchPattern {
+			// not the same key or match pattern, reset cursor
+			entry = entryCursor{
+				DB:      param.DB,
+				Key:     key,
+				Pattern: matchPattern,
+				Cursor:  0,
+			}
+			entryCors[param.DB] = entry
+			return 0, "", true
+		} else {
+			return entry.Cursor, entry.XLast, false
+		}
+	}
+	// define set entry cursor function
+	setEntryCursor := func(cursor uint64) {
+		data.Cursor = cursor
+		entryCors[param.DB] = entryCursor{
+			DB:      param.DB,
+			Type:    "",
+			Key:     key,
+			Pattern: matchPattern,
+			Cursor:  cursor,
+		}
+	}
+	// define set last stream pos function
+	setEntryXLast := func(last string) {
+		entryCors[param.DB] = entryCursor{
+			DB:      param.DB,
+			Type:    "",
+			Key:     key,
+			Pattern: matchPattern,
+			XLast:   last,
+		}
+	}
+
+	decoder := Preferences().GetDecoder()
+
+	switch data.KeyType {
+	case "string":
+		var str string
+		var strLen int64
+		largeSize := Preferences().GetLargeValueSize()
+		if param.Partial && !param.Full {
+			strLen, _ = client.StrLen(ctx, key).Result()
+		}
+		if strLen > largeSize {
+			// only load leading part, the rest can be loaded by GetStringRange
+			str, err = client.GetRange(ctx, key, 0, largeSize-1).Result()
+			str = cutPartialRune(str)
+			data.Length, data.Cursor, data.Partial = strLen, uint64(len(str)), true
+		} else {
+			str, err = client.Get(ctx, key).Result()
+		}
+		if err == nil && !data.Partial && isHyperLogLog(str) {
+			// show summary instead of the garbled dense/sparse representation
+			var count int64
+			if count, err = client.PFCount(ctx, key).Result(); err == nil {
+				data.Cardinality = &count
+				data.Value = hyperLogLogSummary(str, count)
+			}
+			break
+		}
+		data.Value = strutil.EncodeRedisKey(str)
+		//data.Value, data.Decode, data.Format = convutil.ConvertTo(str, param.Decode, param.Format, decoder)
+
+	case "list":
+		loadListHandle := func() ([]types.ListEntryItem, bool, bool, error) {
+			var loadVal []string
+			var cursor uint64
+			var reset bool
+			var subErr error
+			doFilter := matchPattern != "*"
+			if param.Full || doFilter {
+				// load all
+				cursor, reset = 0, true
+				loadVal, subErr = client.LRange(ctx, key, 0, -1).Result()
+			} else {
+				if param.Reset {
+					cursor, reset = 0, true
+				} else {
+					cursor, _, reset = getEntryCursor()
+				}
+				scanSize := int64(Preferences().GetScanSize())
+				loadVal, subErr = client.LRange(ctx, key, int64(cursor), int64(cursor)+scanSize-1).Result()
+				cursor = cursor + uint64(scanSize)
+				if len(loadVal) < int(scanSize) {
+					cursor = 0
+				}
+			}
+			setEntryCursor(cursor)
+
+			items := make([]types.ListEntryItem, 0, len(loadVal))
+			for _, val := range loadVal {
+				if doFilter && !strings.Contains(val, param.MatchPattern) {
+					continue
+				}
+				items = append(items, types.ListEntryItem{
+					Index: len(items),
+					Value: strutil.EncodeRedisKey(val),
+				})
+				if doConvert {
+					if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
+						items[len(items)-1].DisplayValue = dv
+					}
+				}
+			}
+			if subErr != nil {
+				return items, reset, false, subErr
+			}
+			return items, reset, cursor == 0, nil
+		}
+
+		data.Value, data.Reset, data.End, err = loadListHandle()
+		data.Match, data.Decode, data.Format = param.MatchPattern, param.Decode, param.Format
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+
+	case "hash":
+		if !strings.HasPrefix(matchPattern, "*") {
+			matchPattern = "*" + matchPattern
+		}
+		if !strings.HasSuffix(matchPattern, "*") {
+			matchPattern = matchPattern + "*"
+		}
+		loadHashHandle := func() ([]types.HashEntryItem, bool, bool, error) {
+			var items []types.HashEntryItem
+			var loadedVal []string
+			var cursor uint64
+			var reset bool
+			var subErr error
+			scanSize := int64(Preferences().GetScanSize())
+			if param.Full || matchPattern != "*" {
+				// load all
+				cursor, reset = 0, true
+				items = []types.HashEntryItem{}
+				for {
+					loadedVal, cursor, subErr = client.HScan(ctx, key, cursor, matchPattern, scanSize).Result()
+					if subErr != nil {
+						return nil, reset, false, subErr
+					}
+					for i := 0; i < len(loadedVal); i += 2 {
+						items = append(items, types.HashEntryItem{
+							Key:   loadedVal[i],
+							Value: strutil.EncodeRedisKey(loadedVal[i+1]),
+						})
+						if doConvert {
+							if dv, _, _ := convutil.ConvertTo(loadedVal[i+1], param.Decode, param.Format, decoder); dv != loadedVal[i+1] {
+								items[len(items)-1].DisplayValue = dv
+							}
+						}
+					}
+					if cursor == 0 {
+						break
+					}
+				}
+			} else {
+				if param.Reset {
+					cursor, reset = 0, true
+				} else {
+					cursor, _, reset = getEntryCursor()
+				}
+				loadedVal, cursor, subErr = client.HScan(ctx, key, cursor, matchPattern, scanSize).Result()
+				if subErr != nil {
+					return nil, reset, false, subErr
+				}
+				loadedLen := len(loadedVal)
+				items = make([]types.HashEntryItem, loadedLen/2)
+				for i := 0; i < loadedLen; i += 2 {
+					items[i/2].Key = loadedVal[i]
+					items[i/2].Value = strutil.EncodeRedisKey(loadedVal[i+1])
+					if doConvert {
+						if dv, _, _ := convutil.ConvertTo(loadedVal[i+1], param.Decode, param.Format, decoder); dv != loadedVal[i+1] {
+							items[i/2].DisplayValue = dv
+						}
+					}
+				}
+			}
+			setEntryCursor(cursor)
+			return items, reset, cursor == 0, nil
+		}
+
+		var hashItems []types.HashEntryItem
+		hashItems, data.Reset, data.End, err = loadHashHandle()
+		if err == nil {
+			b.fillHashFieldTTL(ctx, client, key, hashItems)
+		}
+		data.Value = hashItems
+		data.Match, data.Decode, data.Format = param.MatchPattern, param.Decode, param.Format
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+
+	case "set":
+		if !strings.HasPrefix(matchPattern, "*") {
+			matchPattern = "*" + matchPattern
+		}
+		if !strings.HasSuffix(matchPattern, "*") {
+			matchPattern = matchPattern + "*"
+		}
+		loadSetHandle := func() ([]types.SetEntryItem, bool, bool, error) {
+			var items []types.SetEntryItem
+			var cursor uint64
+			var reset bool
+			var subErr error
+			var loadedKey []string
+			scanSize := int64(Preferences().GetScanSize())
+			if param.Full || matchPattern != "*" {
+				// load all
+				cursor, reset = 0, true
+				items = []types.SetEntryItem{}
+				for {
+					loadedKey, cursor, subErr = client.SScan(ctx, key, cursor, matchPattern, scanSize).Result()
+					if subErr != nil {
+						return items, reset, false, subErr
+					}
+					for _, val := range loadedKey {
+						items = append(items, types.SetEntryItem{
+							Value: strutil.EncodeRedisKey(val),
+						})
+						if doConvert {
+							if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
+								items[len(items)-1].DisplayValue = dv
+							}
+						}
+					}
+					if cursor == 0 {
+						break
+					}
+				}
+			} else {
+				if param.Reset {
+					cursor, reset = 0, true
+				} else {
+					cursor, _, reset = getEntryCursor()
+				}
+				loadedKey, cursor, subErr = client.SScan(ctx, key, cursor, matchPattern, scanSize).Result()
+				items = make([]types.SetEntryItem, len(loadedKey))
+				for i, val := range loadedKey {
+					items[i].Value = strutil.EncodeRedisKey(val)
+					if doConvert {
+						if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
+							items[i].DisplayValue = dv
+						}
+					}
+				}
+			}
+			setEntryCursor(cursor)
+			return items, reset, cursor == 0, nil
+		}
+
+		data.Value, data.Reset, data.End, err = loadSetHandle()
+		data.Match, data.Decode, data.Format = param.MatchPattern, param.Decode, param.Format
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+
+	case "zset":
+		if !strings.HasPrefix(matchPattern, "*") {
+			matchPattern = "*" + matchPattern
+		}
+		if !strings.HasSuffix(matchPattern, "*") {
+			matchPattern = matchPattern + "*"
+		}
+		loadZSetHandle := func() ([]types.ZSetEntryItem, bool, bool, error) {
+			var items []types.ZSetEntryItem
+			var reset bool
+			var cursor uint64
+			scanSize := int64(Preferences().GetScanSize())
+			doFilter := matchPattern != "*"
+			if param.Full || doFilter {
+				// load all
+				var loadedVal []string
+				cursor, reset = 0, true
+				items = []types.ZSetEntryItem{}
+				for {
+					loadedVal, cursor, err = client.ZScan(ctx, key, cursor, matchPattern, scanSize).Result()
+					if err != nil {
+						return items, reset, false, err
+					}
+					var score float64
+					for i := 0; i < len(loadedVal); i += 2 {
+						if score, err = strconv.ParseFloat(loadedVal[i+1], 64); err == nil {
+							items = append(items, types.ZSetEntryItem{
+								Value: strutil.EncodeRedisKey(loadedVal[i]),
+								Score: score,
+							})
+							if doConvert {
+								if dv, _, _ := convutil.ConvertTo(loadedVal[i], param.Decode, param.Format, decoder); dv != loadedVal[i] {
+									items[len(items)-1].DisplayValue = dv
+								}
+							}
+						}
+					}
+					if cursor == 0 {
+						break
+					}
+				}
+			} else {
+				if param.Reset {
+					cursor, reset = 0, true
+				} else {
+					cursor, _, reset = getEntryCursor()
+				}
+				var loadedVal []redis.Z
+				loadedVal, err = client.ZRangeWithScores(ctx, key, int64(cursor), int64(cursor)+scanSize-1).Result()
+				cursor = cursor + uint64(scanSize)
+				if len(loadedVal) < int(scanSize) {
+					cursor = 0
+				}
+
+				items = make([]types.ZSetEntryItem, 0, len(loadedVal))
+				for _, z := range loadedVal {
+					val := strutil.AnyToString(z.Member, "", 0)
+					if doFilter && !strings.Contains(val, param.MatchPattern) {
+						continue
+					}
+					entry := types.ZSetEntryItem{
+						Value: strutil.EncodeRedisKey(val),
+					}
+					if math.IsInf(z.Score, 1) {
+						entry.ScoreStr = "+inf"
+					} else if math.IsInf(z.Score, -1) {
+						entry.ScoreStr = "-inf"
+					} else {
+						entry.Score = z.Score
+					}
+					items = append(items, entry)
+					if doConvert {
+						if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
+							items[len(items)-1].DisplayValue = dv
+						}
+					}
+				}
+			}
+			setEntryCursor(cursor)
+			return items, reset, cursor == 0, nil
+		}
+
+		data.Value, data.Reset, data.End, err = loadZSetHandle()
+		data.Match, data.Decode, data.Format = param.MatchPattern, param.Decode, param.Format
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+
+	case "stream":
+		loadStreamHandle := func() ([]types.StreamEntryItem, bool, bool, error) {
+			var msgs []redis.XMessage
+			var last string
+			var reset bool
+			doFilter := matchPattern != "*"
+			if param.Full || doFilter {
+				// load all
+				last, reset = "", true
+				msgs, err = client.XRevRange(ctx, key, "+", "-").Result()
+			} else {
+				scanSize := int64(Preferences().GetScanSize())
+				if param.Reset {
+					last = ""
+				} else {
+					_, last, reset = getEntryCursor()
+				}
+				if len(last) <= 0 {
+					last = "+"
+				}
+				if last != "+" {
+					// add 1 more item when continue scan
+					msgs, err = client.XRevRangeN(ctx, key, last, "-", scanSize+1).Result()
+					msgs = msgs[1:]
+				} else {
+					msgs, err = client.XRevRangeN(ctx, key, last, "-", scanSize).Result()
+				}
+				scanCount := len(msgs)
+				if scanCount <= 0 || scanCount < int(scanSize) {
+					last = ""
+				} else if scanCount > 0 {
+					last = msgs[scanCount-1].ID
+				}
+			}
+			setEntryXLast(last)
+			items := make([]types.StreamEntryItem, 0, len(msgs))
+			for _, msg := range msgs {
+				it := types.StreamEntryItem{
+					ID:    msg.ID,
+					Value: msg.Values,
+				}
+				if vb, merr := json.Marshal(msg.Values); merr != nil {
+					it.DisplayValue = "{}"
+				} else {
+					it.DisplayValue, _, _ = convutil.ConvertTo(string(vb), types.DECODE_NONE, types.FORMAT_JSON, decoder)
+				}
+				if doFilter && !strings.Contains(it.DisplayValue, param.MatchPattern) {
+					continue
+				}
+				items = append(items, it)
+			}
+			if err != nil {
+				return items, reset, false, err
+			}
+			return items, reset, last == "", nil
+		}
+
+		data.Value, data.Reset, data.End, err = loadStreamHandle()
+		data.Match, data.Decode, data.Format = param.MatchPattern, param.Decode, param.Format
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+
+	case "rejson-rl":
+		var jsonStr string
+		data.KeyType = "JSON"
+		jsonStr, err = client.JSONGet(ctx, key).Result()
+		if param.Format == types.FORMAT_RAW {
+			// compact view
+			data.Value, data.Decode, data.Format = strutil.JSONMinify(jsonStr), types.DECODE_NONE, types.FORMAT_RAW
+		} else {
+			data.Value, data.Decode, data.Format = convutil.ConvertTo(jsonStr, types.DECODE_NONE, types.FORMAT_JSON, nil)
+		}
+
+	case "tsdb-type":
+		// show metadata only, samples should be queried by QueryTimeSeriesRange
+		var res any
+		data.KeyType = "timeseries"
+		if res, err = client.Do(ctx, "TS.INFO", key).Result(); err == nil {
+			info := redis2.ParseTimeSeriesInfo(res)
+			data.Value, data.Length = info, info.TotalSamples
+		}
+
+	case "mbbloom--", "mbbloomcf", "cmsk-type", "topk-type", "tdis-type":
+		// show info only
+		data.KeyType = probabilisticTypes[keyType]
+		data.Value, err = b.probabilisticInfo(ctx, client, data.KeyType, key)
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	data.Notes = Connection().matchKeyNotes(param.Server, param.DB, key)
+	if strings.HasPrefix(param.Decode, types.DECODE_PROTOBUF+":") {
+		data.Decode = param.Decode
+	}
+	resp.Success = true
+	resp.Data = data
+	return
+}
+
+// ConvertValue convert value with decode method and format
+// blank decode indicate auto decode
+// blank format indicate auto format
+func (b *browserService) ConvertValue(value any, decode, format string) (resp types.JSResp) {
+	str := strutil.DecodeRedisKey(value)
+	value, decode, format = convutil.ConvertTo(str, decode, format, Preferences().GetDecoder())
+	resp.Success = true
+	resp.Data = map[string]any{
+		"value":  value,
+		"decode": decode,
+		"format": format,
+		// ranked alternatives of original content for "also viewable as"
+		"candidates": convutil.DetectCandidates(str),
+	}
+	return
+}
+
+// SetKeyValue set value by key
+// @param ttl <= 0 means keep current ttl
+func (b *browserService) SetKeyValue(param types.SetKeyParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	var expiration time.Duration
+	if param.TTL < 0 {
+		if expiration, err = client.PTTL(ctx, key).Result(); err != nil {
+			expiration = redis.KeepTTL
+		}
+	} else {
+		expiration = time.Duration(param.TTL) * time.Second
+	}
+	// use default decode type and format
+	if len(param.Decode) <= 0 {
+		param.Decode = types.DECODE_NONE
+	}
+	if len(param.Format) <= 0 {
+		param.Format = types.FORMAT_RAW
+	}
+	keyType := strings.ToLower(param.KeyType)
+	if param.Expected != nil && keyType != "string" && keyType != "json" {
+		// members of other types are edited by their own api, which always write directly
+		resp.Msg = fmt.Sprintf("checking expected value is not supported for type \"%s\"", keyType)
+		return
+	}
+	var savedValue any
+	switch keyType {
+	case "string":
+		if str, ok := param.Value.(string); !ok {
+			resp.Msg = "invalid string value"
+			return
+		} else {
+			if param.Partial {
+				strLen, _ := client.StrLen(ctx, key).Result()
+				resp.Msg = fmt.Sprintf("value of %d bytes is loaded partially, load full value before saving", strLen)
+				return
+			}
+			if savedValue, err = convutil.SaveAs(str, param.Format, param.Decode, Preferences().GetDecoder()); err != nil {
+				resp.Msg = fmt.Sprintf(`save to type "%s" fail: %s`, param.Format, err.Error())
+				return
+			}
+			// hyperloglog is shown as summary, which should never overwrite the original one
+			if magic, _ := client.GetRange(ctx, key, 0, 15).Result(); isHyperLogLog(magic) && !isHyperLogLog(fmt.Sprint(savedValue)) {
+				resp.Msg = "hyperloglog can not be edited as string, add items by PFADD instead"
+				return
+			}
+			if param.Expected != nil {
+				err = b.writeIfUnchanged(ctx, client, key, strutil.DecodeRedisKey(param.Expected), func(tx *redis.Tx) (string, error) {
+					return tx.Get(ctx, key).Result()
+				}, func(pipe redis.Pipeliner) error {
+					pipe.Set(ctx, key, savedValue, 0)
+					if expiration > 0 {
+						pipe.Expire(ctx, key, expiration)
+					}
+					return nil
+				})
+				break
+			}
+			_, err = client.Set(ctx, key, savedValue, 0).Result()
+			// set expiration lonely, not "keepttl"
+			if err == nil && expiration > 0 {
+				client.Expire(ctx, key, expiration)
+			}
+		}
+	case "list":
+		if strs, ok := param.Value.([]any); !ok {
+			resp.Msg = "invalid list value"
+			return
+		} else {
+			err = client.LPush(ctx, key, strs...).Err()
+			if err == nil && expiration > 0 {
+				client.Expire(ctx, key, expiration)
+			}
+		}
+	case "hash":
+		if strs, ok := param.Value.([]any); !ok {
+			resp.Msg = "invalid hash value"
+			return
+		} else {
+			total := len(strs)
+			if total > 1 {
+				_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+					for i := 0; i < total; i += 2 {
+						pipe.HSet(ctx, key, strs[i], strs[i+1])
+					}
+					if expiration > 0 {
+						pipe.Expire(ctx, key, expiration)
+					}
+					return nil
+				})
+			}
+		}
+	case "set":
+		if strs, ok := param.Value.([]any); !ok || len(strs) <= 0 {
+			resp.Msg = "invalid set value"
+			return
+		} else {
+			if len(strs) > 0 {
+				err = client.SAdd(ctx, key, strs...).Err()
+				if err == nil && expiration > 0 {
+					client.Expire(ctx, key, expiration)
+				}
+			}
+		}
+	case "zset":
+		if strs, ok := param.Value.([]any); !ok || len(strs) <= 0 {
+			resp.Msg = "invalid zset value"
+			return
+		} else {
+			if len(strs) > 1 {
+				var members []redis.Z
+				for i := 0; i < len(strs); i += 2 {
+					score, _ := strconv.ParseFloat(strs[i+1].(string), 64)
+					members = append(members, redis.Z{
+						Score:  score,
+						Member: strs[i],
+					})
+				}
+				err = client.ZAdd(ctx, key, members...).Err()
+				if err == nil && expiration > 0 {
+					client.Expire(ctx, key, expiration)
+				}
+			}
+		}
+	case "stream":
+		if strs, ok := param.Value.([]any); !ok {
+			resp.Msg = "invalid stream value"
+			return
+		} else {
+			if len(strs) > 2 {
+				err = client.XAdd(ctx, &redis.XAddArgs{
+					Stream: key,
+					ID:     strs[0].(string),
+					Values: strs[1:],
+				}).Err()
+				if err == nil && expiration > 0 {
+					client.Expire(ctx, key, expiration)
+				}
+			}
+		}
+	case "json":
+		if param.Expected != nil {
+			// the loaded value may be reformatted by editor, compare in compact form as returned by "JSON.GET"
+			expected := strutil.DecodeRedisKey(param.Expected)
+			var buf bytes.Buffer
+			if json.Compact(&buf, []byte(expected)) == nil {
+				expected = buf.String()
+			}
+			err = b.writeIfUnchanged(ctx, client, key, expected, func(tx *redis.Tx) (string, error) {
+				return tx.JSONGet(ctx, key).Result()
+			}, func(pipe redis.Pipeliner) error {
+				pipe.JSONSet(ctx, key, ".", param.Value)
+				if expiration > 0 {
+					pipe.Expire(ctx, key, expiration)
+				}
+				return nil
+			})
+		} else {
+			err = client.JSONSet(ctx, key, ".", param.Value).Err()
+			if err == nil && expiration > 0 {
+				client.Expire(ctx, key, expiration)
+			}
+		}
+		var ok bool
+		if savedValue, ok = param.Value.(string); !ok {
+			savedValue = ""
+		}
+	}
+
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	respData := map[string]any{}
+	if val, ok := savedValue.(string); ok {
+		respData["value"] = strutil.EncodeRedisKey(val)
+	}
+	resp.Data = respData
+	return
+}
+
+var errValueConflict = errors.New("value has been modified by others since loaded, reload it before saving")
+
+// run write commands in transaction by "WATCH" and "MULTI" only if current value still equals to expected,
+// fails with errValueConflict if value changed before or during the transaction
+func (b *browserService) writeIfUnchanged(ctx context.Context, client redis.UniversalClient, key, expected string,
+	getFunc func(tx *redis.Tx) (string, error), writeFunc func(pipe redis.Pipeliner) error) error {
+	err := client.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := getFunc(tx)
+		if errors.Is(err, redis.Nil) {
+			// removed since loaded
+			return errValueConflict
+		} else if err != nil {
+			return err
+		}
+		if current != expected {
+			return errValueConflict
+		}
+		_, err = tx.TxPipelined(ctx, writeFunc)
+		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		return errValueConflict
+	}
+	return err
+}
+
+// SearchKeyEntries search entries of hash, set, zset or list by glob-style pattern in pages
+// searching stops when enough entries matched or time limit reached, then it can be continued with returned cursor
+func (b *browserService) SearchKeyEntries(param types.SearchEntriesParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	keyType, err := client.Type(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	pattern := param.Pattern
+	if len(pattern) <= 0 {
+		pattern = "*"
+	}
+	count := param.Count
+	if count <= 0 {
+		count = 100
+	}
+	scanSize := int64(Preferences().GetScanSize())
+	deadline := time.Now().Add(time.Second)
+	cursor := param.Cursor
+	var items any
+	var matched int
+	switch keyType {
+	case "hash":
+		entries := []types.HashEntryItem{}
+		for {
+			var vals []string
+			if vals, cursor, err = client.HScan(ctx, key, cursor, pattern, scanSize).Result(); err != nil {
+				break
+			}
+			for i := 0; i+1 < len(vals); i += 2 {
+				entries = append(entries, types.HashEntryItem{
+					Key:   vals[i],
+					Value: strutil.EncodeRedisKey(vals[i+1]),
+				})
+			}
+			if cursor == 0 || len(entries) >= count || time.Now().After(deadline) {
+				break
+			}
+		}
+		items, matched = entries, len(entries)
+
+	case "set":
+		entries := []types.SetEntryItem{}
+		for {
+			var vals []string
+			if vals, cursor, err = client.SScan(ctx, key, cursor, pattern, scanSize).Result(); err != nil {
+				break
+			}
+			for _, val := range vals {
+				entries = append(entries, types.SetEntryItem{
+					Value: strutil.EncodeRedisKey(val),
+				})
+			}
+			if cursor == 0 || len(entries) >= count || time.Now().After(deadline) {
+				break
+			}
+		}
+		items, matched = entries, len(entries)
+
+	case "zset":
+		entries := []types.ZSetEntryItem{}
+		for {
+			var vals []string
+			if vals, cursor, err = client.ZScan(ctx, key, cursor, pattern, scanSize).Result(); err != nil {
+				break
+			}
+			for i := 0; i+1 < len(vals); i += 2 {
+				score, _ := strconv.ParseFloat(vals[i+1], 64)
+				entries = append(entries, types.ZSetEntryItem{
+					Score:    score,
+					ScoreStr: vals[i+1],
+					Value:    strutil.EncodeRedisKey(vals[i]),
+				})
+			}
+			if cursor == 0 || len(entries) >= count || time.Now().After(deadline) {
+				break
+			}
+		}
+		items, matched = entries, len(entries)
+
+	case "list":
+		// no scan command for list, match elements in range one by one
+		entries := []types.ListEntryItem{}
+		for {
+			var vals []string
+			start := int64(cursor)
+			if vals, err = client.LRange(ctx, key, start, start+scanSize-1).Result(); err != nil {
+				break
+			}
+			for i, val := range vals {
+				if strutil.MatchGlob(pattern, val) {
+					entries = append(entries, types.ListEntryItem{
+						Index: int(start) + i,
+						Value: strutil.EncodeRedisKey(val),
+					})
+				}
+			}
+			cursor += uint64(len(vals))
+			if int64(len(vals)) < scanSize {
+				cursor = 0
+			}
+			if cursor == 0 || len(entries) >= count || time.Now().After(deadline) {
+				break
+			}
+		}
+		items, matched = entries, len(entries)
+
+	default:
+		resp.Msg = "unsupported key type: " + keyType
+		return
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"entries": items,
+		"matched": matched,
+		"cursor":  cursor,
+		"end":     cursor == 0,
+	}
+	return
+}
+
+// GetJSONValue get value and type at path of JSON key
+func (b *browserService) GetJSONValue(param types.GetJSONParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	jsonPath := param.Path
+	if len(jsonPath) <= 0 {
+		jsonPath = "$"
+	}
+	val, err := client.JSONGet(ctx, key, jsonPath).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		resp.Msg = err.Error()
+		return
+	}
+	if len(val) <= 0 {
+		resp.Msg = "key or path not exists"
+		return
+	}
+	jsonType, _ := client.Do(ctx, "JSON.TYPE", key, jsonPath).Result()
+
+	format := types.FORMAT_JSON
+	if param.Format == types.FORMAT_RAW {
+		format = types.FORMAT_RAW
+		val = strutil.JSONMinify(val)
+	} else {
+		val = strutil.JSONBeautify(val, "  ")
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"value":  val,
+		"type":   jsonType,
+		"format": format,
+	}
+	return
+}
+
+// SetJSONValue update value at path of JSON key, the key will be created if path is root
+func (b *browserService) SetJSONValue(param types.SetJSONParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	if !json.Valid([]byte(param.Value)) {
+		resp.Msg = "invalid JSON value"
+		return
+	}
+	mode := strings.ToUpper(param.Mode)
+	if mode != "" && mode != "NX" && mode != "XX" {
+		resp.Msg = "invalid set mode: " + param.Mode
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	jsonPath := param.Path
+	if len(jsonPath) <= 0 {
+		jsonPath = "$"
+	}
+	err = client.JSONSetMode(ctx, key, jsonPath, param.Value, mode).Err()
+	if errors.Is(err, redis.Nil) {
+		// condition of mode not met
+		resp.Msg = "path condition not met"
+		return
+	} else if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// DeleteJSONPath delete value at path of JSON key, the whole key will be deleted if path is root
+func (b *browserService) DeleteJSONPath(server string, db int, k any, jsonPath string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	if len(jsonPath) <= 0 {
+		jsonPath = "$"
+	}
+	deleted, err := client.JSONDel(ctx, key, jsonPath).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"deleted": deleted,
+	}
+	return
+}
+
+// GetTimeSeriesInfo get metadata of time series key by "TS.INFO"
+func (b *browserService) GetTimeSeriesInfo(server string, db int, k any) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	res, err := client.Do(ctx, "TS.INFO", strutil.DecodeRedisKey(k)).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = redis2.ParseTimeSeriesInfo(res)
+	return
+}
+
+// build time window and aggregation arguments of TS.RANGE and TS.MRANGE
+func (b *browserService) timeSeriesRangeArgs(from, to, count int64, param types.TimeSeriesRangeParam) ([]any, error) {
+	var args []any
+	if from > 0 {
+		args = append(args, from)
+	} else {
+		args = append(args, "-")
+	}
+	if to > 0 {
+		args = append(args, to)
+	} else {
+		args = append(args, "+")
+	}
+	if count > 0 {
+		args = append(args, "COUNT", count)
+	}
+	if len(param.Aggregation) > 0 {
+		if param.BucketDuration <= 0 {
+			return nil, errors.New("bucket duration is required for aggregation")
+		}
+		args = append(args, "AGGREGATION", param.Aggregation, param.BucketDuration)
+	}
+	return args, nil
+}
+
+// QueryTimeSeriesRange query samples of time series key by "TS.RANGE" or "TS.REVRANGE"
+// if serialNo is specified, samples are emitted in batches by event "tsrange:<serialNo>"
+// instead of returning in response, and can be canceled by event "tsrange:stop:<serialNo>"
+func (b *browserService) QueryTimeSeriesRange(param types.TimeSeriesRangeParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client := item.client
+	key := strutil.DecodeRedisKey(param.Key)
+	cmd := "TS.RANGE"
+	if param.Reverse {
+		cmd = "TS.REVRANGE"
+	}
+	if len(param.SerialNo) <= 0 {
+		var args []any
+		if args, err = b.timeSeriesRangeArgs(param.From, param.To, param.Count, param); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		var res any
+		if res, err = client.Do(item.ctx, append([]any{cmd, key}, args...)...).Result(); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+
+		resp.Success = true
+		resp.Data = map[string]any{
+			"samples": redis2.ParseTimeSeriesSamples(res),
+		}
+		return
+	}
+
+	ctx, cancelFunc := context.WithCancel(item.ctx)
+	defer cancelFunc()
+	cancelStopEvent := runtime.EventsOnce(ctx, "tsrange:stop:"+param.SerialNo, func(data ...any) {
+		cancelFunc()
+	})
+	defer cancelStopEvent()
+
+	const batchSize = 2000
+	from, to := param.From, param.To
+	var total int64
+	for param.Count <= 0 || total < param.Count {
+		count := int64(batchSize)
+		if param.Count > 0 {
+			count = min(count, param.Count-total)
+		}
+		var args []any
+		if args, err = b.timeSeriesRangeArgs(from, to, count, param); err != nil {
+			break
+		}
+		var res any
+		if res, err = client.Do(ctx, append([]any{cmd, key}, args...)...).Result(); err != nil {
+			break
+		}
+		samples := redis2.ParseTimeSeriesSamples(res)
+		if len(samples) > 0 {
+			runtime.EventsEmit(b.ctx, "tsrange:"+param.SerialNo, samples)
+			total += int64(len(samples))
+		}
+		if int64(len(samples)) < count {
+			break
+		}
+
+		// continue from next timestamp or bucket
+		last := samples[len(samples)-1].Timestamp
+		if param.Reverse {
+			to = last - 1
+		} else if len(param.Aggregation) > 0 {
+			from = last + param.BucketDuration
+		} else {
+			from = last + 1
+		}
+	}
+	canceled := errors.Is(err, context.Canceled)
+	if err != nil && !canceled {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"total":    total,
+		"canceled": canceled,
+	}
+	return
+}
+
+// QueryTimeSeriesMRange query samples of multiple time series matched filters by "TS.MRANGE" or "TS.MREVRANGE"
+func (b *browserService) QueryTimeSeriesMRange(param types.TimeSeriesRangeParam) (resp types.JSResp) {
+	if len(param.Filters) <= 0 {
+		resp.Msg = "at least one filter is required"
+		return
+	}
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	cmd := "TS.MRANGE"
+	if param.Reverse {
+		cmd = "TS.MREVRANGE"
+	}
+	args, err := b.timeSeriesRangeArgs(param.From, param.To, param.Count, param)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	args = append([]any{cmd}, args...)
+	// WITHLABELS must be placed before FILTER
+	args = append(args, "WITHLABELS", "FILTER")
+	for _, filter := range param.Filters {
+		args = append(args, filter)
+	}
+	if len(param.GroupBy) > 0 {
+		if len(param.Reduce) <= 0 {
+			resp.Msg = "reducer is required for grouping"
+			return
+		}
+		args = append(args, "GROUPBY", param.GroupBy, "REDUCE", param.Reduce)
+	}
+
+	client, ctx := item.client, item.ctx
+	res, err := client.Do(ctx, args...).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	series := redis2.ParseTimeSeriesMRange(res)
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Key < series[j].Key
+	})
+	resp.Success = true
+	resp.Data = map[string]any{
+		"series": series,
+	}
+	return
+}
+
+// key types of RedisBloom module returned by "TYPE"
+var probabilisticTypes = map[string]string{
+	"MBbloom--": types.PROB_BLOOM,
+	"MBbloomCF": types.PROB_CUCKOO,
+	"CMSk-TYPE": types.PROB_CMS,
+	"TopK-TYPE": types.PROB_TOPK,
+	"TDIS-TYPE": types.PROB_TDIGEST,
+}
+
+// get probabilistic type of key, return error if key is not a probabilistic type
+func (b *browserService) probabilisticType(ctx context.Context, client redis.UniversalClient, key string) (string, error) {
+	keyType, err := client.Type(ctx, key).Result()
+	if err != nil {
+		return "", err
+	}
+	if keyType == "none" {
+		return "", errors.New("key not exists")
+	}
+	probType, ok := probabilisticTypes[keyType]
+	if !ok {
+		return "", fmt.Errorf("key type \"%s\" is not a probabilistic type", keyType)
+	}
+	return probType, nil
+}
+
+func (b *browserService) probabilisticInfo(ctx context.Context, client redis.UniversalClient, probType, key string) (map[string]any, error) {
+	var cmd string
+	switch probType {
+	case types.PROB_BLOOM:
+		cmd = "BF.INFO"
+	case types.PROB_CUCKOO:
+		cmd = "CF.INFO"
+	case types.PROB_CMS:
+		cmd = "CMS.INFO"
+	case types.PROB_TOPK:
+		cmd = "TOPK.INFO"
+	case types.PROB_TDIGEST:
+		cmd = "TDIGEST.INFO"
+	default:
+		return nil, errors.New("unknown probabilistic type")
+	}
+	res, err := client.Do(ctx, cmd, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	return redis2.ReplyToMap(res), nil
+}
+
+// GetProbabilisticInfo get info of bloom filter, cuckoo filter, count-min sketch, top-k or t-digest key
+func (b *browserService) GetProbabilisticInfo(server string, db int, k any) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	probType, err := b.probabilisticType(ctx, client, key)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	info, err := b.probabilisticInfo(ctx, client, probType, key)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"type": probType,
+		"info": info,
+	}
+	return
+}
+
+// TestProbabilisticItems test items in probabilistic key
+// bloom and cuckoo: whether items may exist
+// count-min sketch: estimated count of items
+// top-k: whether items are in top-k list
+// t-digest: estimated rank of values
+func (b *browserService) TestProbabilisticItems(server string, db int, k any, items []string) (resp types.JSResp) {
+	if len(items) <= 0 {
+		resp.Msg = "no item to test"
+		return
+	}
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	probType, err := b.probabilisticType(ctx, client, key)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	var cmd string
+	switch probType {
+	case types.PROB_BLOOM:
+		cmd = "BF.MEXISTS"
+	case types.PROB_CUCKOO:
+		cmd = "CF.MEXISTS"
+	case types.PROB_CMS:
+		cmd = "CMS.QUERY"
+	case types.PROB_TOPK:
+		cmd = "TOPK.QUERY"
+	case types.PROB_TDIGEST:
+		cmd = "TDIGEST.RANK"
+	}
+	args := []any{cmd, key}
+	for _, it := range items {
+		args = append(args, it)
+	}
+	res, err := client.Do(ctx, args...).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	vals, _ := res.([]any)
+	results := make([]map[string]any, 0, len(items))
+	for i, it := range items {
+		var val any
+		if i < len(vals) {
+			val = vals[i]
+			if probType == types.PROB_BLOOM || probType == types.PROB_CUCKOO || probType == types.PROB_TOPK {
+				// reply 1/0 as boolean
+				n, _ := strutil.AnyToInt(val)
+				val = n == 1
+			}
+		}
+		results = append(results, map[string]any{
+			"item":   it,
+			"result": val,
+		})
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"type":    probType,
+		"results": results,
+	}
+	return
+}
+
+// AddProbabilisticItems add items to probabilistic key
+// count-min sketch increases count of each item by 1, t-digest accepts numeric values only
+func (b *browserService) AddProbabilisticItems(server string, db int, k any, items []string) (resp types.JSResp) {
+	if len(items) <= 0 {
+		resp.Msg = "no item to add"
+		return
+	}
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	probType, err := b.probabilisticType(ctx, client, key)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	var args []any
+	switch probType {
+	case types.PROB_BLOOM:
+		args = []any{"BF.MADD", key}
+	case types.PROB_CUCKOO:
+		args = []any{"CF.INSERT", key, "NOCREATE", "ITEMS"}
+	case types.PROB_CMS:
+		args = []any{"CMS.INCRBY", key}
+	case types.PROB_TOPK:
+		args = []any{"TOPK.ADD", key}
+	case types.PROB_TDIGEST:
+		args = []any{"TDIGEST.ADD", key}
+	}
+	for _, it := range items {
+		if probType == types.PROB_TDIGEST {
+			if _, convErr := strconv.ParseFloat(it, 64); convErr != nil {
+				resp.Msg = fmt.Sprintf("invalid numeric value \"%s\"", it)
+				return
+			}
+		}
+		args = append(args, it)
+		if probType == types.PROB_CMS {
+			args = append(args, 1)
+		}
+	}
+	if err = client.Do(ctx, args...).Err(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// ReserveProbabilistic create an empty probabilistic key with specified parameters
+func (b *browserService) ReserveProbabilistic(param types.ProbabilisticReserveParam) (resp types.JSResp) {
+	key := strutil.DecodeRedisKey(param.Key)
+	var args []any
+	switch param.Type {
+	case types.PROB_BLOOM:
+		if param.ErrorRate <= 0 || param.ErrorRate >= 1 || param.Capacity <= 0 {
+			resp.Msg = "error rate between 0 and 1 and capacity are required"
+			return
+		}
+		args = []any{"BF.RESERVE", key, param.ErrorRate, param.Capacity}
+	case types.PROB_CUCKOO:
+		if param.Capacity <= 0 {
+			resp.Msg = "capacity is required"
+			return
+		}
+		args = []any{"CF.RESERVE", key, param.Capacity}
+	case types.PROB_CMS:
+		if param.Width <= 0 || param.Depth <= 0 {
+			resp.Msg = "width and depth are required"
+			return
+		}
+		args = []any{"CMS.INITBYDIM", key, param.Width, param.Depth}
+	case types.PROB_TOPK:
+		if param.TopK <= 0 {
+			resp.Msg = "k is required"
+			return
+		}
+		args = []any{"TOPK.RESERVE", key, param.TopK}
+		if param.Width > 0 && param.Depth > 0 {
+			decay := param.Decay
+			if decay <= 0 {
+				decay = 0.9
+			}
+			args = append(args, param.Width, param.Depth, decay)
+		}
+	case types.PROB_TDIGEST:
+		args = []any{"TDIGEST.CREATE", key}
+		if param.Compression > 0 {
+			args = append(args, "COMPRESSION", param.Compression)
+		}
+	default:
+		resp.Msg = "unknown probabilistic type: " + param.Type
+		return
+	}
+
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	client, ctx := item.client, item.ctx
+	if err = client.Do(ctx, args...).Err(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// check if value of string key is HyperLogLog, which starts with magic "HYLL"
+func isHyperLogLog(str string) bool {
+	return len(str) >= 16 && strings.HasPrefix(str, "HYLL")
+}
+
+// readable summary of HyperLogLog in json, the 5th byte of header is encoding
+func hyperLogLogSummary(str string, count int64) string {
+	encoding := "dense"
+	if str[4] == 1 {
+		encoding = "sparse"
+	}
+	b, _ := json.Marshal(struct {
+		Cardinality int64  `json:"cardinality"`
+		Encoding    string `json:"encoding"`
+		Bytes       int    `json:"bytes"`
+	}{count, encoding, len(str)})
+	return string(b)
+}
+
+// AddHyperLogLogItems add members to HyperLogLog by "PFADD"
+func (b *browserService) AddHyperLogLogItems(server string, db int, k any, items []string) (resp types.JSResp) {
+	if len(items) <= 0 {
+		resp.Msg = "no item to add"
+		return
+	}
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	updated, err := client.PFAdd(ctx, key, sliceutil.Map(items, func(i int) any {
+		return items[i]
+	})...).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	count, err := client.PFCount(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"updated": updated > 0, // whether the estimated cardinality changed
+		"count":   count,
+	}
+	return
+}
+
+// MergeHyperLogLog merge HyperLogLogs into destination key by "PFMERGE"
+// all keys should be in the same slot for cluster mode
+func (b *browserService) MergeHyperLogLog(server string, db int, destKey any, srcKeys []any) (resp types.JSResp) {
+	if len(srcKeys) <= 0 {
+		resp.Msg = "no source key to merge"
+		return
+	}
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	dest := strutil.DecodeRedisKey(destKey)
+	keys := sliceutil.Map(srcKeys, func(i int) string {
+		return strutil.DecodeRedisKey(srcKeys[i])
+	})
+	if err = client.PFMerge(ctx, dest, keys...).Err(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	count, err := client.PFCount(ctx, dest).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"count": count,
+	}
+	return
+}
+
+// GetBitmapBits get offsets of set bits of string key in pages
+func (b *browserService) GetBitmapBits(param types.BitmapParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	strLen, err := client.StrLen(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	count := param.Count
+	if count <= 0 {
+		count = 8192
+	} else if count > 1<<20 {
+		// at most 128KB of string per page
+		count = 1 << 20
+	}
+	// align page to byte boundary
+	start := max(param.Start, 0) / 8 * 8
+	count = (count + 7) / 8 * 8
+
+	page := types.BitmapPage{
+		Start:     start,
+		Count:     count,
+		BitLength: strLen * 8,
+		Bits:      []int64{},
+	}
+	if start < page.BitLength {
+		var str string
+		str, err = client.GetRange(ctx, key, start/8, (start+count)/8-1).Result()
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		for i := 0; i < len(str); i++ {
+			for j := 0; j < 8; j++ {
+				// the most significant bit comes first
+				if str[i]&(0x80>>j) != 0 {
+					page.Bits = append(page.Bits, start+int64(i*8+j))
+				}
+			}
+		}
+		page.BitCount = int64(len(page.Bits))
+	}
+
+	resp.Success = true
+	resp.Data = page
+	return
+}
+
+// CountBitmapBits count set bits in range by "BITCOUNT"
+// @param byBit range is indexed by bit instead of byte, requires redis 7.0+
+func (b *browserService) CountBitmapBits(server string, db int, k any, start, end int64, byBit bool) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	// unit argument is not supported before redis 7.0, send it only if indexed by bit
+	var unit string
+	if byBit {
+		unit = redis.BitCountIndexBit
+	}
+	count, err := client.BitCount(ctx, key, &redis.BitCount{
+		Start: start,
+		End:   end,
+		Unit:  unit,
+	}).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"count": count,
+	}
+	return
+}
+
+// GetBitmapBit get bit value at offset by "GETBIT"
+func (b *browserService) GetBitmapBit(server string, db int, k any, offset int64) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	bit, err := client.GetBit(ctx, key, offset).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"bit": bit,
+	}
+	return
+}
+
+// SetBitmapBit set or clear bit at offset by "SETBIT", the string grows if offset out of range
+func (b *browserService) SetBitmapBit(server string, db int, k any, offset int64, value int) (resp types.JSResp) {
+	if value != 0 && value != 1 {
+		resp.Msg = "bit value should be 0 or 1"
+		return
+	}
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	old, err := client.SetBit(ctx, key, offset, value).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"old": old,
+	}
+	return
+}
+
+// FindBitmapBit find position of first bit with specified value in range by "BITPOS"
+// @param end -1 for the end of string
+// @param byBit range is indexed by bit instead of byte, requires redis 7.0+
+func (b *browserService) FindBitmapBit(server string, db int, k any, bit int8, start, end int64, byBit bool) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	var pos int64
+	if byBit {
+		pos, err = client.BitPosSpan(ctx, key, bit, start, end, redis.BitCountIndexBit).Result()
+	} else {
+		// unit argument is not supported before redis 7.0
+		pos, err = client.BitPos(ctx, key, int64(bit), start, end).Result()
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"pos": pos, // -1 if not found
+	}
+	return
+}
+
+// cut incomplete utf-8 sequence at the end of chunk, which will be loaded with the next chunk
+func cutPartialRune(str string) string {
+	for i := 1; i < utf8.UTFMax && i <= len(str); i++ {
+		c := str[len(str)-i]
+		if c < utf8.RuneSelf {
+			break
+		}
+		if utf8.RuneStart(c) {
+			if !utf8.FullRuneInString(str[len(str)-i:]) {
+				return str[:len(str)-i]
+			}
+			break
+		}
+	}
+	return str
+}
+
+// GetStringRange load next part of large string value from offset, see also KeyDetail.Partial
+func (b *browserService) GetStringRange(param types.BinaryRangeParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	size, err := client.StrLen(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	// load at most the partial loading size each time
+	limit := Preferences().GetLargeValueSize()
+	length := param.Length
+	if length <= 0 || length > limit {
+		length = limit
+	}
+	offset := max(param.Offset, 0)
+
+	var str string
+	if offset < size {
+		if str, err = client.GetRange(ctx, key, offset, offset+length-1).Result(); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		if offset+int64(len(str)) < size {
+			str = cutPartialRune(str)
+		}
+	}
+
+	next := offset + int64(len(str))
+	resp.Success = true
+	resp.Data = map[string]any{
+		"value":  strutil.EncodeRedisKey(str),
+		"offset": offset,
+		"length": len(str),
+		"size":   size,
+		"end":    next >= size,
+	}
+	return
+}
+
+// GetBinaryRange read bytes of string in range by "GETRANGE" as hex, for editing large binary value in chunks
+func (b *browserService) GetBinaryRange(param types.BinaryRangeParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	size, err := client.StrLen(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	length := param.Length
+	if length <= 0 {
+		length = 4096
+	} else if length > 65536 {
+		length = 65536
+	}
+	offset := max(param.Offset, 0)
+
+	chunk := types.BinaryChunk{
+		Offset: offset,
+		Size:   size,
+	}
+	if offset < size {
+		var str string
+		str, err = client.GetRange(ctx, key, offset, offset+length-1).Result()
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		chunk.Length = int64(len(str))
+		chunk.Hex = hex.EncodeToString([]byte(str))
+	}
+
+	resp.Success = true
+	resp.Data = chunk
+	return
+}
+
+// SetBinaryRange overwrite bytes of string from offset by "SETRANGE", bytes are passed in hex
+// string is padded with zero bytes if offset is larger than its length
+func (b *browserService) SetBinaryRange(param types.BinaryRangeParam) (resp types.JSResp) {
+	data, err := convutil.DecodeHexBytes(param.Hex)
+	if err != nil {
+		resp.Msg = "invalid hex string: " + err.Error()
+		return
+	}
+	if param.Offset < 0 {
+		resp.Msg = "offset should not be negative"
+		return
+	}
+
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	size, err := client.SetRange(ctx, key, param.Offset, string(data)).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"size": size,
+	}
+	return
+}
+
+// GetKeyPreview detect content type of string value by magic bytes, and return the whole value in base64 for previewing
+// @param maxSize max bytes of value to return, payload is omitted if value is larger
+func (b *browserService) GetKeyPreview(server string, db int, k any, maxSize int64) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	size, err := client.StrLen(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if maxSize <= 0 {
+		maxSize = 5 * 1024 * 1024
+	}
+
+	var contentType, payload string
+	if size > 0 {
+		var head string
+		if head, err = client.GetRange(ctx, key, 0, 15).Result(); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		contentType = convutil.DetectContentType([]byte(head))
+	}
+	if len(contentType) > 0 && size <= maxSize {
+		var val string
+		if val, err = client.Get(ctx, key).Result(); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		payload = base64.StdEncoding.EncodeToString([]byte(val))
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"contentType": contentType, // empty if not previewable
+		"size":        size,
+		"payload":     payload, // empty if too large
+	}
+	return
+}
+
+// validate type and offset of bitfield
+func checkBitfield(field types.BitfieldField) error {
+	invalid := true
+	if len(field.Type) >= 2 {
+		if bits, err := strconv.Atoi(field.Type[1:]); err == nil {
+			switch field.Type[0] {
+			case 'i', 'I':
+				invalid = bits < 1 || bits > 64
+			case 'u', 'U':
+				invalid = bits < 1 || bits > 63
+			}
+		}
+	}
+	if invalid {
+		return fmt.Errorf("invalid bitfield type \"%s\"", field.Type)
+	}
+	if _, err := strconv.ParseUint(strings.TrimPrefix(field.Offset, "#"), 10, 64); err != nil {
+		return fmt.Errorf("invalid bitfield offset \"%s\"", field.Offset)
+	}
+	return nil
+}
+
+// GetBitfieldValues read packed integers of string key by "BITFIELD_RO" with user-defined layout
+func (b *browserService) GetBitfieldValues(param types.BitfieldParam) (resp types.JSResp) {
+	if len(param.Fields) <= 0 {
+		resp.Msg = "no field in layout"
+		return
+	}
+	args := make([]any, 0, len(param.Fields)*3)
+	for _, field := range param.Fields {
+		if err := checkBitfield(field); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		args = append(args, "GET", field.Type, field.Offset)
+	}
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	values, err := client.BitFieldRO(ctx, key, args...).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	fields := make([]types.BitfieldField, len(param.Fields))
+	for i, field := range param.Fields {
+		fields[i] = field
+		if i < len(values) {
+			fields[i].Value = values[i]
+		}
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"fields": fields,
+	}
+	return
+}
+
+// SetBitfieldValues write packed integers of string key by "BITFIELD" in one command
+// @return old values of fields, nil for field failed by "FAIL" overflow
+func (b *browserService) SetBitfieldValues(param types.BitfieldParam) (resp types.JSResp) {
+	if len(param.Fields) <= 0 {
+		resp.Msg = "no field to set"
+		return
+	}
+	args := make([]any, 0, len(param.Fields)*4+2)
+	if len(param.Overflow) > 0 {
+		overflow := strings.ToUpper(param.Overflow)
+		if overflow != "WRAP" && overflow != "SAT" && overflow != "FAIL" {
+			resp.Msg = "invalid overflow behavior: " + param.Overflow
+			return
+		}
+		args = append(args, "OVERFLOW", overflow)
+	}
+	for _, field := range param.Fields {
+		if err := checkBitfield(field); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		args = append(args, "SET", field.Type, field.Offset, field.Value)
+	}
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	// reply contains nil for failed field, so parse raw reply instead of int slice
+	res, err := client.Do(ctx, append([]any{"BITFIELD", key}, args...)...).Slice()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"old": res,
+	}
+	return
+}
+
+// GetHashValue get hash field
+func (b *browserService) GetHashValue(param types.GetHashParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	val, err := client.HGet(ctx, key, param.Field).Result()
+	if errors.Is(err, redis.Nil) {
+		resp.Msg = "field in key not found"
+		return
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	var displayVal string
+	if (len(param.Decode) > 0 && param.Decode != types.DECODE_NONE) ||
+		(len(param.Format) > 0 && param.Format != types.FORMAT_RAW) {
+		decoder := Preferences().GetDecoder()
+		displayVal, _, _ = convutil.ConvertTo(val, param.Decode, param.Format, decoder)
+		if displayVal == val {
+			displayVal = ""
+		}
+	}
+
+	resp.Data = types.HashEntryItem{
+		Key:          param.Field,
+		Value:        val,
+		DisplayValue: displayVal,
+	}
+	resp.Success = true
+	return
+}
+
+// check if error is caused by hash field expiration commands unsupported before Redis 7.4
+func isHashFieldTTLUnsupported(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "unknown command")
+}
+
+// fill remaining ttl of hash fields by "HTTL", ignored if not supported by server
+func (b *browserService) fillHashFieldTTL(ctx context.Context, client redis.UniversalClient, key string, items []types.HashEntryItem) {
+	if len(items) <= 0 {
+		return
+	}
+	fields := sliceutil.Map(items, func(i int) string {
+		return items[i].Key
+	})
+	ttls, err := client.HTTL(ctx, key, fields...).Result()
+	if err != nil || len(ttls) != len(items) {
+		return
+	}
+	for i, ttl := range ttls {
+		// -1 for no expiration, -2 for field not exists
+		if ttl > 0 {
+			items[i].TTL = ttl
+		}
+	}
+}
+
+// GetHashFieldTTL get remaining ttl of hash fields by "HTTL"
+func (b *browserService) GetHashFieldTTL(server string, db int, k any, fields []string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if len(fields) <= 0 {
+		resp.Msg = "no field specified"
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	ttls, err := client.HTTL(ctx, key, fields...).Result()
+	if isHashFieldTTLUnsupported(err) {
+		resp.Success = true
+		resp.Data = map[string]any{
+			"supported": false,
+		}
+		return
+	} else if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	ttlMap := make(map[string]int64, len(fields))
+	for i, ttl := range ttls {
+		if i < len(fields) {
+			// -1 for no expiration, -2 for field not exists
+			ttlMap[fields[i]] = ttl
+		}
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"supported": true,
+		"ttl":       ttlMap,
+	}
+	return
+}
+
+// SetHashFieldTTL set expiration of hash fields by "HEXPIRE", or remove it by "HPERSIST"
+// @param ttl < 0 means persist fields
+func (b *browserService) SetHashFieldTTL(server string, db int, k any, fields []string, ttl int64) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if len(fields) <= 0 {
+		resp.Msg = "no field specified"
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	var codes []int64
+	if ttl < 0 {
+		codes, err = client.HPersist(ctx, key, fields...).Result()
+	} else {
+		codes, err = client.HExpire(ctx, key, time.Duration(ttl)*time.Second, fields...).Result()
+	}
+	if isHashFieldTTLUnsupported(err) {
+		resp.Msg = "expiration of hash field requires Redis 7.4 or later"
+		return
+	} else if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	// -2 means field not exists
+	var updated int
+	for _, code := range codes {
+		if code != -2 {
+			updated += 1
+		}
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"updated": updated,
+	}
+	return
+}
+
+// SetHashValue update hash field
+func (b *browserService) SetHashValue(param types.SetHashParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	str := strutil.DecodeRedisKey(param.Value)
+	var saveStr, displayStr string
+	decoder := Preferences().GetDecoder()
+	if saveStr, err = convutil.SaveAs(str, param.Format, param.Decode, decoder); err != nil {
+		resp.Msg = fmt.Sprintf(`save to type "%s" fail: %s`, param.Format, err.Error())
+		return
+	}
+	if len(param.RetDecode) > 0 && len(param.RetFormat) > 0 {
+		displayStr, _, _ = convutil.ConvertTo(saveStr, param.RetDecode, param.RetFormat, decoder)
+	}
+	var updated, added, removed []types.HashEntryItem
+	var replaced []types.HashReplaceItem
+	var affect int64
+	if len(param.NewField) <= 0 {
+		// new field is empty, delete old field
+		_, err = client.HDel(ctx, key, param.Field).Result()
+		removed = append(removed, types.HashEntryItem{
+			Key: param.Field,
+		})
+	} else if len(param.Field) <= 0 || param.Field == param.NewField {
+		affect, err = client.HSet(ctx, key, param.NewField, saveStr).Result()
+		if affect <= 0 {
+			// update field value
+			updated = append(updated, types.HashEntryItem{
+				Key:          param.NewField,
+				Value:        saveStr,
+				DisplayValue: displayStr,
+			})
+		} else {
+			// add new field
+			added = append(added, types.HashEntryItem{
+				Key:          param.NewField,
+				Value:        saveStr,
+				DisplayValue: displayStr,
+			})
+		}
+	} else {
+		// remove old field and add new field
+		if _, err = client.HDel(ctx, key, param.Field).Result(); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+
+		affect, err = client.HSet(ctx, key, param.NewField, saveStr).Result()
+		if affect <= 0 {
+			// no new filed added, just update exists item
+			removed = append(removed, types.HashEntryItem{
+				Key: param.Field,
+			})
+			updated = append(updated, types.HashEntryItem{
+				Key:          param.NewField,
+				Value:        saveStr,
+				DisplayValue: displayStr,
+			})
+		} else {
+			// add new field
+			replaced = append(replaced, types.HashReplaceItem{
+				Key:          param.Field,
+				NewKey:       param.NewField,
+				Value:        saveStr,
+				DisplayValue: displayStr,
+			})
+		}
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = struct {
+		Added    []types.HashEntryItem   `json:"added,omitempty"`
+		Removed  []types.HashEntryItem   `json:"removed,omitempty"`
+		Updated  []types.HashEntryItem   `json:"updated,omitempty"`
+		Replaced []types.HashReplaceItem `json:"replaced,omitempty"`
+	}{
+		Added:    added,
+		Removed:  removed,
+		Updated:  updated,
+		Replaced: replaced,
+	}
+	return
+}
+
+// AddHashField add or update hash field
+func (b *browserService) AddHashField(server string, db int, k any, action int, fieldItems []any) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	var updated []types.HashEntryItem
+	var added []types.HashEntryItem
+	switch action {
+	case 1:
+		// ignore duplicated fields
+		for i := 0; i < len(fieldItems); i += 2 {
+			field, value := strutil.DecodeRedisKey(fieldItems[i]), strutil.DecodeRedisKey(fieldItems[i+1])
+			if succ, _ := client.HSetNX(ctx, key, field, value).Result(); succ {
+				added = append(added, types.HashEntryItem{
+					Key:          field,
+					Value:        value,
+					DisplayValue: "", // TODO: convert to display value
+				})
+			}
+		}
+	default:
+		// overwrite duplicated fields
+		total := len(fieldItems)
+		if total > 1 {
+			for i := 0; i < total; i += 2 {
+				field, value := strutil.DecodeRedisKey(fieldItems[i]), strutil.DecodeRedisKey(fieldItems[i+1])
+				if affect, _ := client.HSet(ctx, key, field, value).Result(); affect > 0 {
+					added = append(added, types.HashEntryItem{
+						Key:          field,
+						Value:        value,
+						DisplayValue: "", // TODO: convert to display value
+					})
+				} else {
+					updated = append(updated, types.HashEntryItem{
+						Key:          field,
+						Value:        value,
+						DisplayValue: "", // TODO: convert to display value
+					})
+				}
+			}
+		}
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = struct {
+		Added   []types.HashEntryItem `json:"added,omitempty"`
+		Updated []types.HashEntryItem `json:"updated,omitempty"`
+	}{
+		Added:   added,
+		Updated: updated,
+	}
+	return
+}
+
+// GetListRange load page of list around specified index by "LRANGE"
+func (b *browserService) GetListRange(param types.ListRangeParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	length, err := client.LLen(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	count := param.Count
+	if count <= 0 {
+		count = int64(Preferences().GetScanSize())
+	}
+	index := param.Index
+	if index < 0 {
+		index += length
+	}
+	index = min(max(index, 0), max(length-1, 0))
+	// place target index in the middle of page
+	start := max(min(index-count/2, length-count), 0)
+
+	vals, err := client.LRange(ctx, key, start, start+count-1).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	doConvert := (len(param.Decode) > 0 && param.Decode != types.DECODE_NONE) ||
+		(len(param.Format) > 0 && param.Format != types.FORMAT_RAW)
+	decoder := Preferences().GetDecoder()
+	entries := make([]types.ListEntryItem, 0, len(vals))
+	for i, val := range vals {
+		entry := types.ListEntryItem{
+			Index: int(start) + i,
+			Value: strutil.EncodeRedisKey(val),
+		}
+		if doConvert {
+			if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
+				entry.DisplayValue = dv
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"entries": entries,
+		"start":   start,
+		"index":   index,
+		"length":  length,
+	}
+	return
+}
+
+// FindListElement find positions of element in list by "LPOS", requires redis 6.0.6+
+func (b *browserService) FindListElement(param types.ListPositionParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	count := param.Count
+	if count <= 0 {
+		count = 100
+	}
+	positions, err := client.LPosCount(ctx, key, strutil.DecodeRedisKey(param.Element), count, redis.LPosArgs{
+		Rank:   param.Rank,
+		MaxLen: param.MaxLen,
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		resp.Msg = err.Error()
+		return
+	}
+	if positions == nil {
+		positions = []int64{}
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"positions": positions,
+	}
+	return
+}
+
+// AddListItem add item to list or remove from it
+func (b *browserService) AddListItem(server string, db int, k any, action int, items []any) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	var leftPush, rightPush []types.ListEntryItem
+	switch action {
+	case 0:
+		// push to head
+		slices.Reverse(items)
+		_, err = client.LPush(ctx, key, items...).Result()
+		for i := len(items) - 1; i >= 0; i-- {
+			leftPush = append(leftPush, types.ListEntryItem{
+				Value:        items[i],
+				DisplayValue: "", // TODO: convert to display value
+			})
+		}
+	default:
+		// append to tail
+		_, err = client.RPush(ctx, key, items...).Result()
+		for _, it := range items {
+			rightPush = append(rightPush, types.ListEntryItem{
+				Value:        it,
+				DisplayValue: "", // TODO: convert to display value
+			})
+		}
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = struct {
+		Left  []types.ListEntryItem `json:"left,omitempty"`
+		Right []types.ListEntryItem `json:"right,omitempty"`
+	}{
+		Left:  leftPush,
+		Right: rightPush,
+	}
+	return
+}
+
+// SetListItem update or remove list item by index
+func (b *browserService) SetListItem(param types.SetListParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	str := strutil.DecodeRedisKey(param.Value)
+	index := int64(param.Index)
+	var replaced, removed []types.ListReplaceItem
+	if len(str) <= 0 {
+		// remove from list
+		err = client.LSet(ctx, key, index, "---VALUE_REMOVED_BY_TINY_RDM---").Err()
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+
+		err = client.LRem(ctx, key, 1, "---VALUE_REMOVED_BY_TINY_RDM---").Err()
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		removed = append(removed, types.ListReplaceItem{
+			Index: param.Index,
+		})
+	} else {
+		// replace index value
+		var saveStr string
+		decoder := Preferences().GetDecoder()
+		if saveStr, err = convutil.SaveAs(str, param.Format, param.Decode, decoder); err != nil {
+			resp.Msg = fmt.Sprintf(`save to type "%s" fail: %s`, param.Format, err.Error())
+			return
+		}
+		err = client.LSet(ctx, key, index, saveStr).Err()
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		var displayStr string
+		if len(param.RetDecode) > 0 && len(param.RetFormat) > 0 {
+			displayStr, _, _ = convutil.ConvertTo(saveStr, param.RetDecode, param.RetFormat, decoder)
+		}
+		replaced = append(replaced, types.ListReplaceItem{
+			Index:        param.Index,
+			Value:        saveStr,
+			DisplayValue: displayStr,
+		})
+	}
+
+	resp.Success = true
+	resp.Data = struct {
+		Removed  []types.ListReplaceItem `json:"removed,omitempty"`
+		Replaced []types.ListReplaceItem `json:"replaced,omitempty"`
+	}{
+		Removed:  removed,
+		Replaced: replaced,
+	}
+	return
+}
+
+// SetSetItem add members to set or remove from set
+func (b *browserService) SetSetItem(server string, db int, k any, remove bool, members []any) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	var added, removed []types.SetEntryItem
+	var affected int64
+	if remove {
+		for _, member := range members {
+			if affected, _ = client.SRem(ctx, key, member).Result(); affected > 0 {
+				removed = append(removed, types.SetEntryItem{
+					Value: member,
+				})
+			}
+		}
+	} else {
+		for _, member := range members {
+			if affected, _ = client.SAdd(ctx, key, member).Result(); affected > 0 {
+				added = append(added, types.SetEntryItem{
+					Value:        member,
+					DisplayValue: "", // TODO: convert to display value
+				})
+			}
+		}
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = struct {
+		Added    []types.SetEntryItem `json:"added,omitempty"`
+		Removed  []types.SetEntryItem `json:"removed,omitempty"`
+		Affected int64                `json:"affected"`
+	}{
+		Added:    added,
+		Removed:  removed,
+		Affected: affected,
+	}
+	return
+}
+
+// UpdateSetItem replace member of set
+func (b *browserService) UpdateSetItem(param types.SetSetParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	var added, removed []types.SetEntryItem
+	var affect int64
+	// remove old value
+	str := strutil.DecodeRedisKey(param.Value)
+	if affect, _ = client.SRem(ctx, key, str).Result(); affect > 0 {
+		removed = append(removed, types.SetEntryItem{
+			Value: str,
+		})
+	}
+
+	// insert new value
+	str = strutil.DecodeRedisKey(param.NewValue)
+	decoder := Preferences().GetDecoder()
+	var saveStr string
+	if saveStr, err = convutil.SaveAs(str, param.Format, param.Decode, decoder); err != nil {
+		resp.Msg = fmt.Sprintf(`save to type "%s" fail: %s`, param.Format, err.Error())
+		return
+	}
+	if affect, _ = client.SAdd(ctx, key, saveStr).Result(); affect > 0 {
+		// add new item
+		var displayStr string
+		if len(param.RetDecode) > 0 && len(param.RetFormat) > 0 {
+			displayStr, _, _ = convutil.ConvertTo(saveStr, param.RetDecode, param.RetFormat, decoder)
+		}
+		added = append(added, types.SetEntryItem{
+			Value:        saveStr,
+			DisplayValue: displayStr,
+		})
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = struct {
+		Added   []types.SetEntryItem `json:"added,omitempty"`
+		Removed []types.SetEntryItem `json:"removed,omitempty"`
+	}{
+		Added:   added,
+		Removed: removed,
+	}
+	return
+}
+
+// QueryZSetRange query members of zset in range of score by "ZRANGEBYSCORE", or in lexicographical range by "ZRANGEBYLEX"
+func (b *browserService) QueryZSetRange(param types.ZSetRangeParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	byLex := strings.EqualFold(param.By, "lex")
+	minVal, maxVal := param.Min, param.Max
+	if len(minVal) <= 0 {
+		minVal = "-inf"
+		if byLex {
+			minVal = "-"
+		}
+	}
+	if len(maxVal) <= 0 {
+		maxVal = "+inf"
+		if byLex {
+			maxVal = "+"
+		}
+	}
+	count := param.Count
+	if count <= 0 {
+		count = int64(Preferences().GetScanSize())
+	}
+	offset := max(param.Offset, 0)
+	rangeBy := &redis.ZRangeBy{
+		Min:    minVal,
+		Max:    maxVal,
+		Offset: offset,
+		Count:  count,
+	}
+
+	var members []redis.Z
+	var total int64
+	if byLex {
+		var vals []string
+		if param.Reverse {
+			vals, err = client.ZRevRangeByLex(ctx, key, rangeBy).Result()
+		} else {
+			vals, err = client.ZRangeByLex(ctx, key, rangeBy).Result()
+		}
+		if err == nil && len(vals) > 0 {
+			// scores are not replied by lex range
+			pipe := client.Pipeline()
+			for _, val := range vals {
+				pipe.ZScore(ctx, key, val)
+			}
+			var cmders []redis.Cmder
+			if cmders, err = pipe.Exec(ctx); err == nil {
+				members = make([]redis.Z, len(vals))
+				for i, cmder := range cmders {
+					members[i] = redis.Z{Member: vals[i], Score: cmder.(*redis.FloatCmd).Val()}
 				}
 			}
-			setEntryCursor(cursor)
-			return items, reset, cursor == 0, nil
 		}
+		if err == nil {
+			total, err = client.ZLexCount(ctx, key, minVal, maxVal).Result()
+		}
+	} else {
+		if param.Reverse {
+			members, err = client.ZRevRangeByScoreWithScores(ctx, key, rangeBy).Result()
+		} else {
+			members, err = client.ZRangeByScoreWithScores(ctx, key, rangeBy).Result()
+		}
+		if err == nil {
+			total, err = client.ZCount(ctx, key, minVal, maxVal).Result()
+		}
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
 
-		data.Value, data.Reset, data.End, err = loadZSetHandle()
-		data.Match, data.Decode, data.Format = param.MatchPattern, param.Decode, param.Format
-		if err != nil {
-			resp.Msg = err.Error()
+	doConvert := (len(param.Decode) > 0 && param.Decode != types.DECODE_NONE) ||
+		(len(param.Format) > 0 && param.Format != types.FORMAT_RAW)
+	decoder := Preferences().GetDecoder()
+	entries := make([]types.ZSetEntryItem, 0, len(members))
+	for _, z := range members {
+		val := strutil.AnyToString(z.Member, "", 0)
+		entry := types.ZSetEntryItem{
+			Value: strutil.EncodeRedisKey(val),
+		}
+		if math.IsInf(z.Score, 1) {
+			entry.ScoreStr = "+inf"
+		} else if math.IsInf(z.Score, -1) {
+			entry.ScoreStr = "-inf"
+		} else {
+			entry.Score = z.Score
+		}
+		if doConvert {
+			if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
+				entry.DisplayValue = dv
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"entries": entries,
+		"offset":  offset,
+		"total":   total, // members in range
+		"end":     offset+int64(len(entries)) >= total,
+	}
+	return
+}
+
+// UpdateZSetValue update value of sorted set member
+func (b *browserService) UpdateZSetValue(param types.SetZSetParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	val, newVal := strutil.DecodeRedisKey(param.Value), strutil.DecodeRedisKey(param.NewValue)
+	var added, updated, removed []types.ZSetEntryItem
+	var replaced []types.ZSetReplaceItem
+	var affect int64
+	decoder := Preferences().GetDecoder()
+	if len(newVal) <= 0 {
+		// no new value, delete value
+		if affect, err = client.ZRem(ctx, key, val).Result(); affect > 0 {
+			//removed = append(removed, val)
+			removed = append(removed, types.ZSetEntryItem{
+				Value: val,
+			})
+		}
+	} else {
+		var saveVal string
+		if saveVal, err = convutil.SaveAs(newVal, param.Format, param.Decode, decoder); err != nil {
+			resp.Msg = fmt.Sprintf(`save to type "%s" fail: %s`, param.Format, err.Error())
 			return
 		}
 
-	case "stream":
-		loadStreamHandle := func() ([]types.StreamEntryItem, bool, bool, error) {
-			var msgs []redis.XMessage
-			var last string
-			var reset bool
-			doFilter := matchPattern != "*"
-			if param.Full || doFilter {
-				// load all
-				last, reset = "", true
-				msgs, err = client.XRevRange(ctx, key, "+", "-").Result()
+		if saveVal == val {
+			affect, err = client.ZAdd(ctx, key, redis.Z{
+				Score:  param.Score,
+				Member: saveVal,
+			}).Result()
+			displayValue, _, _ := convutil.ConvertTo(val, param.RetDecode, param.RetFormat, decoder)
+			if affect > 0 {
+				// add new item
+				added = append(added, types.ZSetEntryItem{
+					Score:        param.Score,
+					Value:        val,
+					DisplayValue: displayValue,
+				})
 			} else {
-				scanSize := int64(Preferences().GetScanSize())
-				if param.Reset {
-					last = ""
-				} else {
-					_, last, reset = getEntryCursor()
-				}
-				if len(last) <= 0 {
-					last = "+"
-				}
-				if last != "+" {
-					// add 1 more item when continue scan
-					msgs, err = client.XRevRangeN(ctx, key, last, "-", scanSize+1).Result()
-					msgs = msgs[1:]
-				} else {
-					msgs, err = client.XRevRangeN(ctx, key, last, "-", scanSize).Result()
-				}
-				scanCount := len(msgs)
-				if scanCount <= 0 || scanCount < int(scanSize) {
-					last = ""
-				} else if scanCount > 0 {
-					last = msgs[scanCount-1].ID
-				}
-			}
-			setEntryXLast(last)
-			items := make([]types.StreamEntryItem, 0, len(msgs))
-			for _, msg := range msgs {
-				it := types.StreamEntryItem{
-					ID:    msg.ID,
-					Value: msg.Values,
-				}
-				if vb, merr := json.Marshal(msg.Values); merr != nil {
-					it.DisplayValue = "{}"
-				} else {
-					it.DisplayValue, _, _ = convutil.ConvertTo(string(vb), types.DECODE_NONE, types.FORMAT_JSON, decoder)
-				}
-				if doFilter && !strings.Contains(it.DisplayValue, param.MatchPattern) {
-					continue
-				}
-				items = append(items, it)
+				// update score only
+				updated = append(updated, types.ZSetEntryItem{
+					Score:        param.Score,
+					Value:        val,
+					DisplayValue: displayValue,
+				})
 			}
+		} else {
+			// remove old value and add new one
+			_, err = client.ZRem(ctx, key, val).Result()
 			if err != nil {
-				return items, reset, false, err
+				resp.Msg = err.Error()
+				return
+			}
+
+			affect, err = client.ZAdd(ctx, key, redis.Z{
+				Score:  param.Score,
+				Member: saveVal,
+			}).Result()
+			displayValue, _, _ := convutil.ConvertTo(saveVal, param.RetDecode, param.RetFormat, decoder)
+			if affect <= 0 {
+				// no new value added, just update exists item
+				removed = append(removed, types.ZSetEntryItem{
+					Value: val,
+				})
+				updated = append(updated, types.ZSetEntryItem{
+					Score:        param.Score,
+					Value:        saveVal,
+					DisplayValue: displayValue,
+				})
+			} else {
+				// add new field
+				replaced = append(replaced, types.ZSetReplaceItem{
+					Score:        param.Score,
+					Value:        val,
+					NewValue:     saveVal,
+					DisplayValue: displayValue,
+				})
 			}
-			return items, reset, last == "", nil
 		}
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
 
-		data.Value, data.Reset, data.End, err = loadStreamHandle()
-		data.Match, data.Decode, data.Format = param.MatchPattern, param.Decode, param.Format
+	resp.Success = true
+	resp.Data = struct {
+		Added    []types.ZSetEntryItem   `json:"added,omitempty"`
+		Updated  []types.ZSetEntryItem   `json:"updated,omitempty"`
+		Replaced []types.ZSetReplaceItem `json:"replaced,omitempty"`
+		Removed  []types.ZSetEntryItem   `json:"removed,omitempty"`
+	}{
+		Added:    added,
+		Updated:  updated,
+		Replaced: replaced,
+		Removed:  removed,
+	}
+	return
+}
+
+// AddZSetValue add item to sorted set
+func (b *browserService) AddZSetValue(server string, db int, k any, action int, valueScore map[string]float64) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+
+	var added, updated []types.ZSetEntryItem
+	switch action {
+	case 1:
+		// ignore duplicated fields
+		for m, s := range valueScore {
+			if affect, _ := client.ZAddNX(ctx, key, redis.Z{Score: s, Member: m}).Result(); affect > 0 {
+				added = append(added, types.ZSetEntryItem{
+					Score:        s,
+					Value:        m,
+					DisplayValue: "", // TODO: convert to display value
+				})
+			}
+		}
+	default:
+		// overwrite duplicated fields
+		for m, s := range valueScore {
+			if affect, _ := client.ZAdd(ctx, key, redis.Z{Score: s, Member: m}).Result(); affect > 0 {
+				added = append(added, types.ZSetEntryItem{
+					Score:        s,
+					Value:        m,
+					DisplayValue: "", // TODO: convert to display value
+				})
+			} else {
+				updated = append(updated, types.ZSetEntryItem{
+					Score:        s,
+					Value:        m,
+					DisplayValue: "", // TODO: convert to display value
+				})
+			}
+		}
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = struct {
+		Added   []types.ZSetEntryItem `json:"added,omitempty"`
+		Updated []types.ZSetEntryItem `json:"updated,omitempty"`
+	}{
+		Added:   added,
+		Updated: updated,
+	}
+	return
+}
+
+// OperateSets compute intersection, union or difference of sets or sorted sets,
+// result is stored into target key by "*STORE" commands, or returned for previewing
+func (b *browserService) OperateSets(param types.SetOperationParam) (resp types.JSResp) {
+	op := strings.ToLower(param.Op)
+	if op != "inter" && op != "union" && op != "diff" {
+		resp.Msg = "unknown operation: " + param.Op
+		return
+	}
+	if len(param.Keys) <= 0 {
+		resp.Msg = "no key specified"
+		return
+	}
+	if len(param.Weights) > 0 && len(param.Weights) != len(param.Keys) {
+		resp.Msg = "count of weights should be the same as keys"
+		return
+	}
+	if (len(param.Weights) > 0 || len(param.Aggregate) > 0) && (!param.ZSet || op == "diff") {
+		resp.Msg = "weights and aggregate are only supported by inter and union of sorted sets"
+		return
+	}
+
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	keys := sliceutil.Map(param.Keys, func(i int) string {
+		return strutil.DecodeRedisKey(param.Keys[i])
+	})
+	store := &redis.ZStore{
+		Keys:      keys,
+		Weights:   param.Weights,
+		Aggregate: strings.ToUpper(param.Aggregate),
+	}
+	if target := strutil.DecodeRedisKey(param.Target); len(target) > 0 {
+		var size int64
+		switch {
+		case param.ZSet && op == "inter":
+			size, err = client.ZInterStore(ctx, target, store).Result()
+		case param.ZSet && op == "union":
+			size, err = client.ZUnionStore(ctx, target, store).Result()
+		case param.ZSet:
+			size, err = client.ZDiffStore(ctx, target, keys...).Result()
+		case op == "inter":
+			size, err = client.SInterStore(ctx, target, keys...).Result()
+		case op == "union":
+			size, err = client.SUnionStore(ctx, target, keys...).Result()
+		default:
+			size, err = client.SDiffStore(ctx, target, keys...).Result()
+		}
 		if err != nil {
 			resp.Msg = err.Error()
 			return
 		}
+		resp.Success = true
+		resp.Data = map[string]any{
+			"size": size,
+		}
+		return
+	}
 
-	case "rejson-rl":
-		var jsonStr string
-		data.KeyType = "JSON"
-		jsonStr, err = client.JSONGet(ctx, key).Result()
-		data.Value, data.Decode, data.Format = convutil.ConvertTo(jsonStr, types.DECODE_NONE, types.FORMAT_JSON, nil)
+	limit := param.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+	var total int
+	var members []any
+	if param.ZSet {
+		// requires redis 6.2+
+		var zs []redis.Z
+		switch op {
+		case "inter":
+			zs, err = client.ZInterWithScores(ctx, store).Result()
+		case "union":
+			zs, err = client.ZUnionWithScores(ctx, *store).Result()
+		default:
+			zs, err = client.ZDiffWithScores(ctx, keys...).Result()
+		}
+		total = len(zs)
+		zs = zs[:min(len(zs), limit)]
+		items := make([]types.ZSetEntryItem, 0, len(zs))
+		for _, z := range zs {
+			entry := types.ZSetEntryItem{
+				Value: strutil.EncodeRedisKey(strutil.AnyToString(z.Member, "", 0)),
+			}
+			if math.IsInf(z.Score, 1) {
+				entry.ScoreStr = "+inf"
+			} else if math.IsInf(z.Score, -1) {
+				entry.ScoreStr = "-inf"
+			} else {
+				entry.Score = z.Score
+			}
+			items = append(items, entry)
+		}
+		members = sliceutil.Map(items, func(i int) any {
+			return items[i]
+		})
+	} else {
+		var vals []string
+		switch op {
+		case "inter":
+			vals, err = client.SInter(ctx, keys...).Result()
+		case "union":
+			vals, err = client.SUnion(ctx, keys...).Result()
+		default:
+			vals, err = client.SDiff(ctx, keys...).Result()
+		}
+		total = len(vals)
+		vals = vals[:min(len(vals), limit)]
+		members = sliceutil.Map(vals, func(i int) any {
+			return types.SetEntryItem{
+				Value: strutil.EncodeRedisKey(vals[i]),
+			}
+		})
 	}
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+
 	resp.Success = true
-	resp.Data = data
+	resp.Data = map[string]any{
+		"members": members,
+		"total":   total,
+	}
 	return
 }
 
-// ConvertValue convert value with decode method and format
-// blank decode indicate auto decode
-// blank format indicate auto format
-func (b *browserService) ConvertValue(value any, decode, format string) (resp types.JSResp) {
-	str := strutil.DecodeRedisKey(value)
-	value, decode, format = convutil.ConvertTo(str, decode, format, Preferences().GetDecoder())
+// AddStreamValue add stream field
+func (b *browserService) AddStreamValue(server string, db int, k any, ID string, fieldItems []any) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	var updateID string
+	updateID, err = client.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		ID:     ID,
+		Values: fieldItems,
+	}).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	updateValues := make(map[string]any, len(fieldItems)/2)
+	for i := 0; i < len(fieldItems)/2; i += 2 {
+		updateValues[fieldItems[i].(string)] = fieldItems[i+1]
+	}
+	vb, _ := json.Marshal(updateValues)
+	displayValue, _, _ := convutil.ConvertTo(string(vb), types.DECODE_NONE, types.FORMAT_JSON, Preferences().GetDecoder())
+
 	resp.Success = true
-	resp.Data = map[string]any{
-		"value":  value,
-		"decode": decode,
-		"format": format,
+	resp.Data = struct {
+		Added []types.StreamEntryItem `json:"added,omitempty"`
+	}{
+		Added: []types.StreamEntryItem{
+			{
+				ID:           updateID,
+				Value:        updateValues,
+				DisplayValue: displayValue, // TODO: convert to display value
+			},
+		},
 	}
 	return
 }
 
-// SetKeyValue set value by key
-// @param ttl <= 0 means keep current ttl
-func (b *browserService) SetKeyValue(param types.SetKeyParam) (resp types.JSResp) {
-	item, err := b.getRedisClient(param.Server, param.DB)
+// RemoveStreamValues remove stream values by id
+func (b *browserService) RemoveStreamValues(server string, db int, k any, IDs []string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
 	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(param.Key)
-	var expiration time.Duration
-	if param.TTL < 0 {
-		if expiration, err = client.PTTL(ctx, key).Result(); err != nil {
-			expiration = redis.KeepTTL
-		}
-	} else {
-		expiration = time.Duration(param.TTL) * time.Second
+	key := strutil.DecodeRedisKey(k)
+
+	var affected int64
+	affected, err = client.XDel(ctx, key, IDs...).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
 	}
-	// use default decode type and format
-	if len(param.Decode) <= 0 {
-		param.Decode = types.DECODE_NONE
+
+	resp.Success = true
+	resp.Data = struct {
+		Affected int64 `json:"affected"`
+	}{
+		Affected: affected,
 	}
-	if len(param.Format) <= 0 {
-		param.Format = types.FORMAT_RAW
+	return
+}
+
+// GetStreamGroups get consumer groups of stream by "XINFO GROUPS"
+func (b *browserService) GetStreamGroups(server string, db int, k any) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
 	}
-	var savedValue any
-	switch strings.ToLower(param.KeyType) {
-	case "string":
-		if str, ok := param.Value.(string); !ok {
-			resp.Msg = "invalid string value"
-			return
-		} else {
-			if savedValue, err = convutil.SaveAs(str, param.Format, param.Decode, Preferences().GetDecoder()); err != nil {
-				resp.Msg = fmt.Sprintf(`save to type "%s" fail: %s`, param.Format, err.Error())
-				return
-			}
-			_, err = client.Set(ctx, key, savedValue, 0).Result()
-			// set expiration lonely, not "keepttl"
-			if err == nil && expiration > 0 {
-				client.Expire(ctx, key, expiration)
-			}
-		}
-	case "list":
-		if strs, ok := param.Value.([]any); !ok {
-			resp.Msg = "invalid list value"
-			return
-		} else {
-			err = client.LPush(ctx, key, strs...).Err()
-			if err == nil && expiration > 0 {
-				client.Expire(ctx, key, expiration)
-			}
-		}
-	case "hash":
-		if strs, ok := param.Value.([]any); !ok {
-			resp.Msg = "invalid hash value"
-			return
-		} else {
-			total := len(strs)
-			if total > 1 {
-				_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
-					for i := 0; i < total; i += 2 {
-						pipe.HSet(ctx, key, strs[i], strs[i+1])
-					}
-					if expiration > 0 {
-						pipe.Expire(ctx, key, expiration)
-					}
-					return nil
-				})
-			}
-		}
-	case "set":
-		if strs, ok := param.Value.([]any); !ok || len(strs) <= 0 {
-			resp.Msg = "invalid set value"
-			return
-		} else {
-			if len(strs) > 0 {
-				err = client.SAdd(ctx, key, strs...).Err()
-				if err == nil && expiration > 0 {
-					client.Expire(ctx, key, expiration)
-				}
-			}
-		}
-	case "zset":
-		if strs, ok := param.Value.([]any); !ok || len(strs) <= 0 {
-			resp.Msg = "invalid zset value"
-			return
-		} else {
-			if len(strs) > 1 {
-				var members []redis.Z
-				for i := 0; i < len(strs); i += 2 {
-					score, _ := strconv.ParseFloat(strs[i+1].(string), 64)
-					members = append(members, redis.Z{
-						Score:  score,
-						Member: strs[i],
-					})
-				}
-				err = client.ZAdd(ctx, key, members...).Err()
-				if err == nil && expiration > 0 {
-					client.Expire(ctx, key, expiration)
-				}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	groups, err := client.XInfoGroups(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"groups": sliceutil.Map(groups, func(i int) types.StreamGroup {
+			return types.StreamGroup{
+				Name:            groups[i].Name,
+				Consumers:       groups[i].Consumers,
+				Pending:         groups[i].Pending,
+				LastDeliveredID: groups[i].LastDeliveredID,
+				EntriesRead:     groups[i].EntriesRead,
+				Lag:             groups[i].Lag,
 			}
-		}
-	case "stream":
-		if strs, ok := param.Value.([]any); !ok {
-			resp.Msg = "invalid stream value"
-			return
-		} else {
-			if len(strs) > 2 {
-				err = client.XAdd(ctx, &redis.XAddArgs{
-					Stream: key,
-					ID:     strs[0].(string),
-					Values: strs[1:],
-				}).Err()
-				if err == nil && expiration > 0 {
-					client.Expire(ctx, key, expiration)
-				}
+		}),
+	}
+	return
+}
+
+// GetStreamConsumers get consumers of group by "XINFO CONSUMERS"
+func (b *browserService) GetStreamConsumers(server string, db int, k any, group string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	consumers, err := client.XInfoConsumers(ctx, key, group).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"consumers": sliceutil.Map(consumers, func(i int) types.StreamConsumer {
+			return types.StreamConsumer{
+				Name:     consumers[i].Name,
+				Pending:  consumers[i].Pending,
+				Idle:     consumers[i].Idle.Milliseconds(),
+				Inactive: consumers[i].Inactive.Milliseconds(),
 			}
-		}
-	case "json":
-		err = client.JSONSet(ctx, key, ".", param.Value).Err()
-		if err == nil && expiration > 0 {
-			client.Expire(ctx, key, expiration)
-		}
-		var ok bool
-		if savedValue, ok = param.Value.(string); !ok {
-			savedValue = ""
-		}
+		}),
+	}
+	return
+}
+
+// GetStreamPending get pending entries summary of group, and details of the oldest pending entries
+// @param consumer only list entries of the consumer if not empty
+// @param count max count of entries details
+func (b *browserService) GetStreamPending(server string, db int, k any, group, consumer string, count int64) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	summary, err := client.XPending(ctx, key, group).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if count <= 0 {
+		count = 100
+	}
+	entries, err := client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream:   key,
+		Group:    group,
+		Start:    "-",
+		End:      "+",
+		Count:    count,
+		Consumer: consumer,
+	}).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = types.StreamPendingSummary{
+		Count:     summary.Count,
+		Lower:     summary.Lower,
+		Higher:    summary.Higher,
+		Consumers: summary.Consumers,
+		Entries: sliceutil.Map(entries, func(i int) types.StreamPendingEntry {
+			return types.StreamPendingEntry{
+				ID:         entries[i].ID,
+				Consumer:   entries[i].Consumer,
+				Idle:       entries[i].Idle.Milliseconds(),
+				RetryCount: entries[i].RetryCount,
+			}
+		}),
+	}
+	return
+}
+
+// AckStreamEntries acknowledge pending entries of group by "XACK"
+func (b *browserService) AckStreamEntries(server string, db int, k any, group string, IDs []string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
 	}
 
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	acked, err := client.XAck(ctx, key, group, IDs...).Result()
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+
 	resp.Success = true
-	respData := map[string]any{}
-	if val, ok := savedValue.(string); ok {
-		respData["value"] = strutil.EncodeRedisKey(val)
+	resp.Data = map[string]any{
+		"acked": acked,
 	}
-	resp.Data = respData
 	return
 }
 
-// GetHashValue get hash field
-func (b *browserService) GetHashValue(param types.GetHashParam) (resp types.JSResp) {
+// ClaimStreamEntries transfer ownership of pending entries to another consumer
+// specified entries are claimed by "XCLAIM", otherwise idle entries are claimed by "XAUTOCLAIM"
+func (b *browserService) ClaimStreamEntries(param types.StreamClaimParam) (resp types.JSResp) {
 	item, err := b.getRedisClient(param.Server, param.DB)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	if len(param.Consumer) <= 0 {
+		resp.Msg = "consumer is required"
+		return
+	}
 
 	client, ctx := item.client, item.ctx
 	key := strutil.DecodeRedisKey(param.Key)
-	val, err := client.HGet(ctx, key, param.Field).Result()
-	if errors.Is(err, redis.Nil) {
-		resp.Msg = "field in key not found"
-		return
+	minIdle := time.Duration(param.MinIdle) * time.Millisecond
+	var msgs []redis.XMessage
+	var next string
+	if len(param.IDs) > 0 {
+		msgs, err = client.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   key,
+			Group:    param.Group,
+			Consumer: param.Consumer,
+			MinIdle:  minIdle,
+			Messages: param.IDs,
+		}).Result()
+	} else {
+		start := param.Start
+		if len(start) <= 0 {
+			start = "0-0"
+		}
+		msgs, next, err = client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   key,
+			Group:    param.Group,
+			Consumer: param.Consumer,
+			MinIdle:  minIdle,
+			Start:    start,
+			Count:    param.Count,
+		}).Result()
 	}
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
-	var displayVal string
-	if (len(param.Decode) > 0 && param.Decode != types.DECODE_NONE) ||
-		(len(param.Format) > 0 && param.Format != types.FORMAT_RAW) {
-		decoder := Preferences().GetDecoder()
-		displayVal, _, _ = convutil.ConvertTo(val, param.Decode, param.Format, decoder)
-		if displayVal == val {
-			displayVal = ""
+	decoder := Preferences().GetDecoder()
+	entries := make([]types.StreamEntryItem, 0, len(msgs))
+	for _, msg := range msgs {
+		it := types.StreamEntryItem{
+			ID:    msg.ID,
+			Value: msg.Values,
 		}
+		if vb, merr := json.Marshal(msg.Values); merr != nil {
+			it.DisplayValue = "{}"
+		} else {
+			it.DisplayValue, _, _ = convutil.ConvertTo(string(vb), types.DECODE_NONE, types.FORMAT_JSON, decoder)
+		}
+		entries = append(entries, it)
 	}
 
-	resp.Data = types.HashEntryItem{
-		Key:          param.Field,
-		Value:        val,
-		DisplayValue: displayVal,
+	resp.Success = true
+	resp.Data = map[string]any{
+		"entries": entries,
+		"next":    next, // start id of next XAUTOCLAIM, "0-0" means all scanned
+	}
+	return
+}
+
+// CreateStreamGroup create consumer group by "XGROUP CREATE"
+// @param start id of last delivered entry, "$" for only new entries and "0" for all entries
+// @param mkStream create stream if not exists
+func (b *browserService) CreateStreamGroup(server string, db int, k any, group, start string, mkStream bool) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	if len(start) <= 0 {
+		start = "$"
+	}
+	if mkStream {
+		err = client.XGroupCreateMkStream(ctx, key, group, start).Err()
+	} else {
+		err = client.XGroupCreate(ctx, key, group, start).Err()
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
 	}
+
 	resp.Success = true
 	return
 }
 
-// SetHashValue update hash field
-func (b *browserService) SetHashValue(param types.SetHashParam) (resp types.JSResp) {
-	item, err := b.getRedisClient(param.Server, param.DB)
+// DestroyStreamGroup destroy consumer group by "XGROUP DESTROY", all pending entries of group are lost
+func (b *browserService) DestroyStreamGroup(server string, db int, k any, group string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
 	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(param.Key)
-	str := strutil.DecodeRedisKey(param.Value)
-	var saveStr, displayStr string
-	decoder := Preferences().GetDecoder()
-	if saveStr, err = convutil.SaveAs(str, param.Format, param.Decode, decoder); err != nil {
-		resp.Msg = fmt.Sprintf(`save to type "%s" fail: %s`, param.Format, err.Error())
+	key := strutil.DecodeRedisKey(k)
+	if err = client.XGroupDestroy(ctx, key, group).Err(); err != nil {
+		resp.Msg = err.Error()
 		return
 	}
-	if len(param.RetDecode) > 0 && len(param.RetFormat) > 0 {
-		displayStr, _, _ = convutil.ConvertTo(saveStr, param.RetDecode, param.RetFormat, decoder)
+
+	resp.Success = true
+	return
+}
+
+// DeleteStreamConsumer remove consumer from group by "XGROUP DELCONSUMER"
+// @return count of pending entries owned by the consumer before deleted
+func (b *browserService) DeleteStreamConsumer(server string, db int, k any, group, consumer string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
 	}
-	var updated, added, removed []types.HashEntryItem
-	var replaced []types.HashReplaceItem
-	var affect int64
-	if len(param.NewField) <= 0 {
-		// new field is empty, delete old field
-		_, err = client.HDel(ctx, key, param.Field).Result()
-		removed = append(removed, types.HashEntryItem{
-			Key: param.Field,
-		})
-	} else if len(param.Field) <= 0 || param.Field == param.NewField {
-		affect, err = client.HSet(ctx, key, param.NewField, saveStr).Result()
-		if affect <= 0 {
-			// update field value
-			updated = append(updated, types.HashEntryItem{
-				Key:          param.NewField,
-				Value:        saveStr,
-				DisplayValue: displayStr,
-			})
-		} else {
-			// add new field
-			added = append(added, types.HashEntryItem{
-				Key:          param.NewField,
-				Value:        saveStr,
-				DisplayValue: displayStr,
-			})
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	pending, err := client.XGroupDelConsumer(ctx, key, group, consumer).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"pending": pending,
+	}
+	return
+}
+
+// SetKeyTTL set ttl of key
+func (b *browserService) SetKeyTTL(server string, db int, k any, ttl int64) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	if ttl < 0 {
+		if err = client.Persist(ctx, key).Err(); err != nil {
+			resp.Msg = err.Error()
+			return
 		}
 	} else {
-		// remove old field and add new field
-		if _, err = client.HDel(ctx, key, param.Field).Result(); err != nil {
+		expiration := time.Duration(ttl) * time.Second
+		if err = client.Expire(ctx, key, expiration).Err(); err != nil {
 			resp.Msg = err.Error()
 			return
 		}
+	}
 
-		affect, err = client.HSet(ctx, key, param.NewField, saveStr).Result()
-		if affect <= 0 {
-			// no new filed added, just update exists item
-			removed = append(removed, types.HashEntryItem{
-				Key: param.Field,
-			})
-			updated = append(updated, types.HashEntryItem{
-				Key:          param.NewField,
-				Value:        saveStr,
-				DisplayValue: displayStr,
-			})
-		} else {
-			// add new field
-			replaced = append(replaced, types.HashReplaceItem{
-				Key:          param.Field,
-				NewKey:       param.NewField,
-				Value:        saveStr,
-				DisplayValue: displayStr,
-			})
+	resp.Success = true
+	return
+}
+
+// layouts of absolute expiry, local timezone is used if not specified
+var expireAtLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func parseExpireAt(datetime string) (time.Time, error) {
+	datetime = strings.TrimSpace(datetime)
+	for _, layout := range expireAtLayouts {
+		if t, err := time.ParseInLocation(layout, datetime, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid datetime \"%s\", ISO 8601 format is required", datetime)
+}
+
+// SetKeyExpireAt set absolute expiry of key by "EXPIREAT", or "PEXPIREAT" if milliseconds specified
+// @param datetime ISO 8601 datetime like "2024-01-02T15:04:05+08:00", local timezone is used if omitted
+func (b *browserService) SetKeyExpireAt(server string, db int, k any, datetime string) (resp types.JSResp) {
+	expireAt, err := parseExpireAt(datetime)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if !expireAt.After(time.Now()) {
+		// key will be deleted immediately by expiration in the past
+		resp.Msg = "expiry should be later than now"
+		return
+	}
+
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	var ok bool
+	if expireAt.Nanosecond() >= int(time.Millisecond) {
+		ok, err = client.PExpireAt(ctx, key, expireAt).Result()
+	} else {
+		ok, err = client.ExpireAt(ctx, key, expireAt).Result()
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if !ok {
+		resp.Msg = "key not exists"
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"ttl":      int64(time.Until(expireAt).Seconds()),
+		"expireAt": expireAt.Local().Format(time.RFC3339),
+	}
+	return
+}
+
+// BatchSetTTL batch set ttl
+func (b *browserService) BatchSetTTL(server string, db int, ks []any, ttl int64, serialNo string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	client := item.client
+	ctx, cancelFunc := context.WithCancel(b.ctx)
+	defer cancelFunc()
+
+	//cancelEvent := "ttling:stop:" + serialNo
+	//runtime.EventsOnce(ctx, cancelEvent, func(data ...any) {
+	//	cancelFunc()
+	//})
+	//processEvent := "ttling:" + serialNo
+	total := len(ks)
+	var failed, updated atomic.Int64
+	var canceled bool
+
+	expiration := time.Now().Add(time.Duration(ttl) * time.Second)
+	del := func(ctx context.Context, cli redis.UniversalClient) error {
+		startTime := time.Now().Add(-10 * time.Second)
+		for i, k := range ks {
+			// emit progress per second
+			//param := map[string]any{
+			//	"total":      total,
+			//	"progress":   i + 1,
+			//	"processing": k,
+			//}
+			if i >= total-1 || time.Now().Sub(startTime).Milliseconds() > 100 {
+				startTime = time.Now()
+				//runtime.EventsEmit(ctx, processEvent, param)
+				// do some sleep to prevent blocking the Redis server
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			key := strutil.DecodeRedisKey(k)
+			var expErr error
+			if ttl < 0 {
+				expErr = cli.Persist(ctx, key).Err()
+			} else {
+				expErr = cli.ExpireAt(ctx, key, expiration).Err()
+			}
+			if err != nil {
+				failed.Add(1)
+			} else {
+				// save deleted key
+				updated.Add(1)
+			}
+			if errors.Is(expErr, context.Canceled) || canceled {
+				canceled = true
+				break
+			}
 		}
+		return nil
 	}
-	if err != nil {
-		resp.Msg = err.Error()
-		return
+
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		// cluster mode
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
+			return del(ctx, cli)
+		})
+	} else {
+		err = del(ctx, client)
 	}
 
+	//runtime.EventsOff(ctx, cancelEvent)
 	resp.Success = true
 	resp.Data = struct {
-		Added    []types.HashEntryItem   `json:"added,omitempty"`
-		Removed  []types.HashEntryItem   `json:"removed,omitempty"`
-		Updated  []types.HashEntryItem   `json:"updated,omitempty"`
-		Replaced []types.HashReplaceItem `json:"replaced,omitempty"`
+		Canceled bool  `json:"canceled"`
+		Updated  int64 `json:"updated"`
+		Failed   int64 `json:"failed"`
 	}{
-		Added:    added,
-		Removed:  removed,
-		Updated:  updated,
-		Replaced: replaced,
+		Canceled: canceled,
+		Updated:  updated.Load(),
+		Failed:   failed.Load(),
 	}
 	return
 }
 
-// AddHashField add or update hash field
-func (b *browserService) AddHashField(server string, db int, k any, action int, fieldItems []any) (resp types.JSResp) {
+// BatchSetTTLByPattern set ttl of keys matching pattern in batches while scanning, persist keys if ttl < 0
+// progress is pushed by event "ttling:<serialNo>" and can be canceled by "ttling:stop:<serialNo>"
+// keys removed during scanning, or already persistent for persist, are reported as skipped
+func (b *browserService) BatchSetTTLByPattern(server string, db int, pattern string, ttl int64, serialNo string) (resp types.JSResp) {
 	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	client := item.client
+	ctx, cancelFunc := context.WithCancel(b.ctx)
+	defer cancelFunc()
 
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(k)
-	var updated []types.HashEntryItem
-	var added []types.HashEntryItem
-	switch action {
-	case 1:
-		// ignore duplicated fields
-		for i := 0; i < len(fieldItems); i += 2 {
-			field, value := strutil.DecodeRedisKey(fieldItems[i]), strutil.DecodeRedisKey(fieldItems[i+1])
-			if succ, _ := client.HSetNX(ctx, key, field, value).Result(); succ {
-				added = append(added, types.HashEntryItem{
-					Key:          field,
-					Value:        value,
-					DisplayValue: "", // TODO: convert to display value
-				})
+	cancelStopEvent := runtime.EventsOnce(ctx, "ttling:stop:"+serialNo, func(data ...any) {
+		cancelFunc()
+	})
+	processEvent := "ttling:" + serialNo
+	job := Jobs().newJob("ttl", "set ttl of keys matching "+pattern, server, db, cancelFunc)
+	const maxSkippedKeys = 100
+	var updated, failed atomic.Int64
+	var mutex sync.Mutex
+	skippedKeys := make([]any, 0)
+	var skipped int64
+	expiration := time.Duration(ttl) * time.Second
+	startTime := time.Now().Add(-10 * time.Second)
+	err = b.scanKeysInBatch(ctx, client, pattern, "", 500, func(ctx context.Context, cli redis.UniversalClient, keys []string) error {
+		pipe := cli.Pipeline()
+		for _, key := range keys {
+			if ttl < 0 {
+				pipe.Persist(ctx, key)
+			} else {
+				pipe.Expire(ctx, key, expiration)
 			}
 		}
-	default:
-		// overwrite duplicated fields
-		total := len(fieldItems)
-		if total > 1 {
-			for i := 0; i < total; i += 2 {
-				field, value := strutil.DecodeRedisKey(fieldItems[i]), strutil.DecodeRedisKey(fieldItems[i+1])
-				if affect, _ := client.HSet(ctx, key, field, value).Result(); affect > 0 {
-					added = append(added, types.HashEntryItem{
-						Key:          field,
-						Value:        value,
-						DisplayValue: "", // TODO: convert to display value
-					})
-				} else {
-					updated = append(updated, types.HashEntryItem{
-						Key:          field,
-						Value:        value,
-						DisplayValue: "", // TODO: convert to display value
-					})
+		cmders, expErr := pipe.Exec(ctx)
+		if errors.Is(expErr, context.Canceled) {
+			return expErr
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		for i, cmder := range cmders {
+			if cmder.Err() != nil {
+				failed.Add(1)
+			} else if cmder.(*redis.BoolCmd).Val() {
+				updated.Add(1)
+			} else {
+				skipped += 1
+				if len(skippedKeys) < maxSkippedKeys {
+					skippedKeys = append(skippedKeys, strutil.EncodeRedisKey(keys[i]))
 				}
 			}
 		}
-	}
-	if err != nil {
+		job.update(updated.Load()+skipped+failed.Load(), 0)
+		if time.Now().Sub(startTime).Milliseconds() > 100 {
+			startTime = time.Now()
+			runtime.EventsEmit(ctx, processEvent, map[string]any{
+				"updated":    updated.Load(),
+				"skipped":    skipped,
+				"failed":     failed.Load(),
+				"processing": keys[len(keys)-1],
+			})
+		}
+		return nil
+	})
+	cancelStopEvent()
+	job.finish(false, err)
+	canceled := errors.Is(err, context.Canceled)
+	if err != nil && !canceled {
 		resp.Msg = err.Error()
 		return
 	}
 
 	resp.Success = true
-	resp.Data = struct {
-		Added   []types.HashEntryItem `json:"added,omitempty"`
-		Updated []types.HashEntryItem `json:"updated,omitempty"`
-	}{
-		Added:   added,
-		Updated: updated,
+	resp.Data = map[string]any{
+		"canceled":    canceled,
+		"updated":     updated.Load(),
+		"failed":      failed.Load(),
+		"skipped":     skipped,
+		"skippedKeys": skippedKeys, // only the first 100 skipped keys
 	}
 	return
 }
 
-// AddListItem add item to list or remove from it
-func (b *browserService) AddListItem(server string, db int, k any, action int, items []any) (resp types.JSResp) {
+// DeleteKey remove redis key
+func (b *browserService) DeleteKey(server string, db int, k any, async bool) (resp types.JSResp) {
 	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
@@ -1566,473 +5309,887 @@ func (b *browserService) AddListItem(server string, db int, k any, action int, i
 
 	client, ctx := item.client, item.ctx
 	key := strutil.DecodeRedisKey(k)
-	var leftPush, rightPush []types.ListEntryItem
-	switch action {
-	case 0:
-		// push to head
-		slices.Reverse(items)
-		_, err = client.LPush(ctx, key, items...).Result()
-		for i := len(items) - 1; i >= 0; i-- {
-			leftPush = append(leftPush, types.ListEntryItem{
-				Value:        items[i],
-				DisplayValue: "", // TODO: convert to display value
-			})
+	var deletedKeys []string
+	if strings.HasSuffix(key, "*") {
+		// delete by prefix
+		var mutex sync.Mutex
+		supportUnlink := true
+		del := func(ctx context.Context, cli redis.UniversalClient) error {
+			handleDel := func(ks []string) error {
+				var delErr error
+				if async && supportUnlink {
+					if delErr = cli.Unlink(ctx, ks...).Err(); delErr != nil {
+						supportUnlink = false
+						// not support unlink? try del command
+						delErr = cli.Del(ctx, ks...).Err()
+					}
+				} else {
+					delErr = cli.Del(ctx, ks...).Err()
+				}
+
+				mutex.Lock()
+				deletedKeys = append(deletedKeys, ks...)
+				mutex.Unlock()
+
+				return delErr
+			}
+
+			scanSize := int64(Preferences().GetScanSize())
+			iter := cli.Scan(ctx, 0, key, scanSize).Iterator()
+			resultKeys := make([]string, 0, 100)
+			for iter.Next(ctx) {
+				resultKeys = append(resultKeys, iter.Val())
+				if len(resultKeys) >= 20 {
+					handleDel(resultKeys)
+					resultKeys = resultKeys[:0:cap(resultKeys)]
+				}
+			}
+
+			if len(resultKeys) > 0 {
+				handleDel(resultKeys)
+			}
+			return nil
 		}
-	default:
-		// append to tail
-		_, err = client.RPush(ctx, key, items...).Result()
-		for _, it := range items {
-			rightPush = append(rightPush, types.ListEntryItem{
-				Value:        it,
-				DisplayValue: "", // TODO: convert to display value
+
+		if cluster, ok := client.(*redis.ClusterClient); ok {
+			// cluster mode
+			err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
+				return del(ctx, cli)
 			})
+		} else {
+			err = del(ctx, client)
+		}
+
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+	} else {
+		// delete key only
+		if async {
+			if err = client.Unlink(ctx, key).Err(); err != nil {
+				if err = client.Del(ctx, key).Err(); err != nil {
+					resp.Msg = err.Error()
+					return
+				}
+			}
+		} else {
+			if err = client.Del(ctx, key).Err(); err != nil {
+				resp.Msg = err.Error()
+				return
+			}
 		}
+		deletedKeys = append(deletedKeys, key)
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"deleted":     deletedKeys,
+		"deleteCount": len(deletedKeys),
 	}
+	return
+}
+
+// DeleteOneKey delete one key
+func (b *browserService) DeleteOneKey(server string, db int, k any) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
-	resp.Success = true
-	resp.Data = struct {
-		Left  []types.ListEntryItem `json:"left,omitempty"`
-		Right []types.ListEntryItem `json:"right,omitempty"`
-	}{
-		Left:  leftPush,
-		Right: rightPush,
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	// cluster client will route the command to the node which holds the key
+	if err = client.Del(ctx, key).Err(); err != nil {
+		resp.Msg = err.Error()
+		return
 	}
+
+	resp.Success = true
 	return
 }
 
-// SetListItem update or remove list item by index
-func (b *browserService) SetListItem(param types.SetListParam) (resp types.JSResp) {
-	item, err := b.getRedisClient(param.Server, param.DB)
+// DeleteKeys delete keys sync with notification
+func (b *browserService) DeleteKeys(server string, db int, ks []any, serialNo string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	client := item.client
+	ctx, cancelFunc := context.WithCancel(b.ctx)
+	defer cancelFunc()
 
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(param.Key)
-	str := strutil.DecodeRedisKey(param.Value)
-	index := int64(param.Index)
-	var replaced, removed []types.ListReplaceItem
-	if len(str) <= 0 {
-		// remove from list
-		err = client.LSet(ctx, key, index, "---VALUE_REMOVED_BY_TINY_RDM---").Err()
-		if err != nil {
-			resp.Msg = err.Error()
-			return
+	cancelEvent := "delete:stop:" + serialNo
+	cancelStopEvent := runtime.EventsOnce(ctx, cancelEvent, func(data ...any) {
+		cancelFunc()
+	})
+	total := len(ks)
+	job := Jobs().newJob("delete", fmt.Sprintf("delete %d keys", total), server, db, cancelFunc)
+	var canceled bool
+	var processed atomic.Int64
+	var deletedKeys = make([]any, 0, total)
+	var mutex sync.Mutex
+	del := func(ctx context.Context, cli redis.UniversalClient) error {
+		const batchSize = 1000
+		for i := 0; i < total; i += batchSize {
+			pipe := cli.Pipeline()
+			for j := 0; j < batchSize; j++ {
+				if i+j < total {
+					pipe.Del(ctx, strutil.DecodeRedisKey(ks[i+j]))
+				}
+			}
+			cmders, delErr := pipe.Exec(ctx)
+			for j, cmder := range cmders {
+				if cmder.(*redis.IntCmd).Val() == 1 {
+					// save deleted key
+					mutex.Lock()
+					deletedKeys = append(deletedKeys, ks[i+j])
+					mutex.Unlock()
+				}
+			}
+			job.update(processed.Add(int64(len(cmders))), int64(total))
+			if errors.Is(delErr, context.Canceled) || canceled {
+				canceled = true
+				break
+			}
 		}
+		return nil
+	}
 
-		err = client.LRem(ctx, key, 1, "---VALUE_REMOVED_BY_TINY_RDM---").Err()
-		if err != nil {
-			resp.Msg = err.Error()
-			return
-		}
-		removed = append(removed, types.ListReplaceItem{
-			Index: param.Index,
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		// cluster mode
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
+			return del(ctx, cli)
 		})
 	} else {
-		// replace index value
-		var saveStr string
-		decoder := Preferences().GetDecoder()
-		if saveStr, err = convutil.SaveAs(str, param.Format, param.Decode, decoder); err != nil {
-			resp.Msg = fmt.Sprintf(`save to type "%s" fail: %s`, param.Format, err.Error())
-			return
-		}
-		err = client.LSet(ctx, key, index, saveStr).Err()
-		if err != nil {
-			resp.Msg = err.Error()
-			return
-		}
-		var displayStr string
-		if len(param.RetDecode) > 0 && len(param.RetFormat) > 0 {
-			displayStr, _, _ = convutil.ConvertTo(saveStr, param.RetDecode, param.RetFormat, decoder)
-		}
-		replaced = append(replaced, types.ListReplaceItem{
-			Index:        param.Index,
-			Value:        saveStr,
-			DisplayValue: displayStr,
-		})
+		err = del(ctx, client)
 	}
 
+	cancelStopEvent()
+	job.finish(canceled, err)
 	resp.Success = true
 	resp.Data = struct {
-		Removed  []types.ListReplaceItem `json:"removed,omitempty"`
-		Replaced []types.ListReplaceItem `json:"replaced,omitempty"`
+		Canceled bool `json:"canceled"`
+		Deleted  any  `json:"deleted"`
+		Failed   int  `json:"failed"`
 	}{
-		Removed:  removed,
-		Replaced: replaced,
+		Canceled: canceled,
+		Deleted:  deletedKeys,
+		Failed:   len(ks) - len(deletedKeys),
 	}
 	return
 }
 
-// SetSetItem add members to set or remove from set
-func (b *browserService) SetSetItem(server string, db int, k any, remove bool, members []any) (resp types.JSResp) {
+// DeleteKeysByPattern delete keys by pattern
+func (b *browserService) DeleteKeysByPattern(server string, db int, pattern string) (resp types.JSResp) {
 	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	client := item.client
+	ctx, cancelFunc := context.WithCancel(b.ctx)
+	defer cancelFunc()
 
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(k)
-	var added, removed []types.SetEntryItem
-	var affected int64
-	if remove {
-		for _, member := range members {
-			if affected, _ = client.SRem(ctx, key, member).Result(); affected > 0 {
-				removed = append(removed, types.SetEntryItem{
-					Value: member,
-				})
+	var ks []any
+	ks, _, err = b.scanKeys(ctx, client, pattern, "", nil, 0, 0)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	total := len(ks)
+	job := Jobs().newJob("delete", fmt.Sprintf("delete %d keys", total), server, db, cancelFunc)
+	var canceled bool
+	var processed atomic.Int64
+	var deletedKeys = make([]any, 0, total)
+	var mutex sync.Mutex
+	del := func(ctx context.Context, cli redis.UniversalClient) error {
+		const batchSize = 1000
+		for i := 0; i < total; i += batchSize {
+			pipe := cli.Pipeline()
+			for j := 0; j < batchSize; j++ {
+				if i+j < total {
+					pipe.Del(ctx, strutil.DecodeRedisKey(ks[i+j]))
+				}
 			}
-		}
-	} else {
-		for _, member := range members {
-			if affected, _ = client.SAdd(ctx, key, member).Result(); affected > 0 {
-				added = append(added, types.SetEntryItem{
-					Value:        member,
-					DisplayValue: "", // TODO: convert to display value
-				})
+			cmders, delErr := pipe.Exec(ctx)
+			for j, cmder := range cmders {
+				if cmder.(*redis.IntCmd).Val() == 1 {
+					// save deleted key
+					mutex.Lock()
+					deletedKeys = append(deletedKeys, ks[i+j])
+					mutex.Unlock()
+				}
+			}
+			job.update(processed.Add(int64(len(cmders))), int64(total))
+			if errors.Is(delErr, context.Canceled) || canceled {
+				canceled = true
+				break
 			}
 		}
+		return nil
 	}
-	if err != nil {
-		resp.Msg = err.Error()
-		return
+
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		// cluster mode
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
+			return del(ctx, cli)
+		})
+	} else {
+		err = del(ctx, client)
 	}
+	job.finish(canceled, err)
 
 	resp.Success = true
 	resp.Data = struct {
-		Added    []types.SetEntryItem `json:"added,omitempty"`
-		Removed  []types.SetEntryItem `json:"removed,omitempty"`
-		Affected int64                `json:"affected"`
+		Canceled bool `json:"canceled"`
+		Deleted  any  `json:"deleted"`
+		Failed   int  `json:"failed"`
 	}{
-		Added:    added,
-		Removed:  removed,
-		Affected: affected,
+		Canceled: canceled,
+		Deleted:  deletedKeys,
+		Failed:   len(ks) - len(deletedKeys),
 	}
 	return
 }
 
-// UpdateSetItem replace member of set
-func (b *browserService) UpdateSetItem(param types.SetSetParam) (resp types.JSResp) {
-	item, err := b.getRedisClient(param.Server, param.DB)
+// CountKeysByPattern count keys matching pattern without loading them, as dry run of bulk operations
+func (b *browserService) CountKeysByPattern(server string, db int, pattern string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
 	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(param.Key)
-	var added, removed []types.SetEntryItem
-	var affect int64
-	// remove old value
-	str := strutil.DecodeRedisKey(param.Value)
-	if affect, _ = client.SRem(ctx, key, str).Result(); affect > 0 {
-		removed = append(removed, types.SetEntryItem{
-			Value: str,
-		})
-	}
-
-	// insert new value
-	str = strutil.DecodeRedisKey(param.NewValue)
-	decoder := Preferences().GetDecoder()
-	var saveStr string
-	if saveStr, err = convutil.SaveAs(str, param.Format, param.Decode, decoder); err != nil {
-		resp.Msg = fmt.Sprintf(`save to type "%s" fail: %s`, param.Format, err.Error())
-		return
-	}
-	if affect, _ = client.SAdd(ctx, key, saveStr).Result(); affect > 0 {
-		// add new item
-		var displayStr string
-		if len(param.RetDecode) > 0 && len(param.RetFormat) > 0 {
-			displayStr, _, _ = convutil.ConvertTo(saveStr, param.RetDecode, param.RetFormat, decoder)
-		}
-		added = append(added, types.SetEntryItem{
-			Value:        saveStr,
-			DisplayValue: displayStr,
-		})
-	}
+	var count atomic.Int64
+	err = b.scanKeysInBatch(ctx, client, pattern, "", 1000, func(ctx context.Context, cli redis.UniversalClient, keys []string) error {
+		count.Add(int64(len(keys)))
+		return nil
+	})
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
 	resp.Success = true
-	resp.Data = struct {
-		Added   []types.SetEntryItem `json:"added,omitempty"`
-		Removed []types.SetEntryItem `json:"removed,omitempty"`
-	}{
-		Added:   added,
-		Removed: removed,
+	resp.Data = map[string]any{
+		"count": count.Load(),
 	}
 	return
 }
 
-// UpdateZSetValue update value of sorted set member
-func (b *browserService) UpdateZSetValue(param types.SetZSetParam) (resp types.JSResp) {
-	item, err := b.getRedisClient(param.Server, param.DB)
+// GetTTLHistogram sample ttl of keys matching pattern and count them in ranges of types.NamespaceTTLRanges
+// @param limit max keys to sample, scanning stops once reached
+func (b *browserService) GetTTLHistogram(server string, db int, pattern string, limit int64) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
-
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(param.Key)
-	val, newVal := strutil.DecodeRedisKey(param.Value), strutil.DecodeRedisKey(param.NewValue)
-	var added, updated, removed []types.ZSetEntryItem
-	var replaced []types.ZSetReplaceItem
-	var affect int64
-	decoder := Preferences().GetDecoder()
-	if len(newVal) <= 0 {
-		// no new value, delete value
-		if affect, err = client.ZRem(ctx, key, val).Result(); affect > 0 {
-			//removed = append(removed, val)
-			removed = append(removed, types.ZSetEntryItem{
-				Value: val,
-			})
-		}
-	} else {
-		var saveVal string
-		if saveVal, err = convutil.SaveAs(newVal, param.Format, param.Decode, decoder); err != nil {
-			resp.Msg = fmt.Sprintf(`save to type "%s" fail: %s`, param.Format, err.Error())
-			return
-		}
-
-		if saveVal == val {
-			affect, err = client.ZAdd(ctx, key, redis.Z{
-				Score:  param.Score,
-				Member: saveVal,
-			}).Result()
-			displayValue, _, _ := convutil.ConvertTo(val, param.RetDecode, param.RetFormat, decoder)
-			if affect > 0 {
-				// add new item
-				added = append(added, types.ZSetEntryItem{
-					Score:        param.Score,
-					Value:        val,
-					DisplayValue: displayValue,
-				})
-			} else {
-				// update score only
-				updated = append(updated, types.ZSetEntryItem{
-					Score:        param.Score,
-					Value:        val,
-					DisplayValue: displayValue,
-				})
-			}
-		} else {
-			// remove old value and add new one
-			_, err = client.ZRem(ctx, key, val).Result()
-			if err != nil {
-				resp.Msg = err.Error()
-				return
-			}
-
-			affect, err = client.ZAdd(ctx, key, redis.Z{
-				Score:  param.Score,
-				Member: saveVal,
-			}).Result()
-			displayValue, _, _ := convutil.ConvertTo(saveVal, param.RetDecode, param.RetFormat, decoder)
-			if affect <= 0 {
-				// no new value added, just update exists item
-				removed = append(removed, types.ZSetEntryItem{
-					Value: val,
-				})
-				updated = append(updated, types.ZSetEntryItem{
-					Score:        param.Score,
-					Value:        saveVal,
-					DisplayValue: displayValue,
-				})
-			} else {
-				// add new field
-				replaced = append(replaced, types.ZSetReplaceItem{
-					Score:        param.Score,
-					Value:        val,
-					NewValue:     saveVal,
-					DisplayValue: displayValue,
-				})
+	if len(pattern) <= 0 {
+		pattern = "*"
+	}
+	if limit <= 0 {
+		limit = 10000
+	}
+
+	ctx, cancelFunc := context.WithCancel(item.ctx)
+	defer cancelFunc()
+	counts := make([]int64, len(types.NamespaceTTLRanges))
+	persistKeys := []any{}
+	var sampled int64
+	var truncated bool
+	var mutex sync.Mutex
+	err = b.scanKeysInBatch(ctx, item.client, pattern, "", 500, func(ctx context.Context, cli redis.UniversalClient, keys []string) error {
+		pipe := cli.Pipeline()
+		cmds := make([]*redis.DurationCmd, len(keys))
+		for i, key := range keys {
+			cmds[i] = pipe.PTTL(ctx, key)
+		}
+		if _, pipeErr := pipe.Exec(ctx); errors.Is(pipeErr, context.Canceled) {
+			return pipeErr
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		for i, cmd := range cmds {
+			ttl, ttlErr := cmd.Result()
+			if ttlErr != nil || ttl == -2 {
+				// removed during scanning
+				continue
+			}
+			idx := ttlRangeIndex(ttl)
+			if idx == 0 && len(persistKeys) < 20 {
+				persistKeys = append(persistKeys, strutil.EncodeRedisKey(keys[i]))
+			}
+			counts[idx] += 1
+			sampled += 1
+			if sampled >= limit {
+				truncated = true
+				cancelFunc()
+				return context.Canceled
 			}
 		}
-	}
-	if err != nil {
+		return nil
+	})
+	if err != nil && !(truncated && errors.Is(err, context.Canceled)) {
 		resp.Msg = err.Error()
 		return
 	}
 
+	buckets := make([]map[string]any, len(types.NamespaceTTLRanges))
+	for i, r := range types.NamespaceTTLRanges {
+		buckets[i] = map[string]any{
+			"range": r,
+			"count": counts[i],
+		}
+	}
 	resp.Success = true
-	resp.Data = struct {
-		Added    []types.ZSetEntryItem   `json:"added,omitempty"`
-		Updated  []types.ZSetEntryItem   `json:"updated,omitempty"`
-		Replaced []types.ZSetReplaceItem `json:"replaced,omitempty"`
-		Removed  []types.ZSetEntryItem   `json:"removed,omitempty"`
-	}{
-		Added:    added,
-		Updated:  updated,
-		Replaced: replaced,
-		Removed:  removed,
+	resp.Data = map[string]any{
+		"buckets":     buckets,
+		"sampled":     sampled,
+		"truncated":   truncated,   // stopped for reaching the limit
+		"persistKeys": persistKeys, // some keys without expiration for example
 	}
 	return
 }
 
-// AddZSetValue add item to sorted set
-func (b *browserService) AddZSetValue(server string, db int, k any, action int, valueScore map[string]float64) (resp types.JSResp) {
+// BulkDeleteByPattern delete keys matching pattern by "UNLINK" in batches while scanning,
+// progress is pushed by event "bulkdel:<serialNo>" and can be canceled by "bulkdel:stop:<serialNo>"
+func (b *browserService) BulkDeleteByPattern(server string, db int, pattern, serialNo string) (resp types.JSResp) {
 	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	client := item.client
+	ctx, cancelFunc := context.WithCancel(b.ctx)
+	defer cancelFunc()
 
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(k)
-
-	var added, updated []types.ZSetEntryItem
-	switch action {
-	case 1:
-		// ignore duplicated fields
-		for m, s := range valueScore {
-			if affect, _ := client.ZAddNX(ctx, key, redis.Z{Score: s, Member: m}).Result(); affect > 0 {
-				added = append(added, types.ZSetEntryItem{
-					Score:        s,
-					Value:        m,
-					DisplayValue: "", // TODO: convert to display value
-				})
-			}
+	cancelStopEvent := runtime.EventsOnce(ctx, "bulkdel:stop:"+serialNo, func(data ...any) {
+		cancelFunc()
+	})
+	processEvent := "bulkdel:" + serialNo
+	job := Jobs().newJob("delete", "delete keys matching "+pattern, server, db, cancelFunc)
+	var deleted, failed atomic.Int64
+	var mutex sync.Mutex
+	startTime := time.Now().Add(-10 * time.Second)
+	err = b.scanKeysInBatch(ctx, client, pattern, "", 500, func(ctx context.Context, cli redis.UniversalClient, keys []string) error {
+		// delete one by one in pipeline, keys may be in different slots
+		pipe := cli.Pipeline()
+		for _, key := range keys {
+			pipe.Unlink(ctx, key)
 		}
-	default:
-		// overwrite duplicated fields
-		for m, s := range valueScore {
-			if affect, _ := client.ZAdd(ctx, key, redis.Z{Score: s, Member: m}).Result(); affect > 0 {
-				added = append(added, types.ZSetEntryItem{
-					Score:        s,
-					Value:        m,
-					DisplayValue: "", // TODO: convert to display value
-				})
+		cmders, delErr := pipe.Exec(ctx)
+		if errors.Is(delErr, context.Canceled) {
+			return delErr
+		}
+		for _, cmder := range cmders {
+			if cmder.(*redis.IntCmd).Val() == 1 {
+				deleted.Add(1)
 			} else {
-				updated = append(updated, types.ZSetEntryItem{
-					Score:        s,
-					Value:        m,
-					DisplayValue: "", // TODO: convert to display value
-				})
+				failed.Add(1)
 			}
 		}
-	}
-	if err != nil {
+
+		job.update(deleted.Load()+failed.Load(), 0)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if time.Now().Sub(startTime).Milliseconds() > 100 {
+			startTime = time.Now()
+			runtime.EventsEmit(ctx, processEvent, map[string]any{
+				"deleted":    deleted.Load(),
+				"failed":     failed.Load(),
+				"processing": keys[len(keys)-1],
+			})
+		}
+		return nil
+	})
+	cancelStopEvent()
+	job.finish(false, err)
+	canceled := errors.Is(err, context.Canceled)
+	if err != nil && !canceled {
 		resp.Msg = err.Error()
 		return
 	}
 
 	resp.Success = true
-	resp.Data = struct {
-		Added   []types.ZSetEntryItem `json:"added,omitempty"`
-		Updated []types.ZSetEntryItem `json:"updated,omitempty"`
-	}{
-		Added:   added,
-		Updated: updated,
+	resp.Data = map[string]any{
+		"canceled": canceled,
+		"deleted":  deleted.Load(),
+		"failed":   failed.Load(),
 	}
 	return
 }
 
-// AddStreamValue add stream field
-func (b *browserService) AddStreamValue(server string, db int, k any, ID string, fieldItems []any) (resp types.JSResp) {
-	item, err := b.getRedisClient(server, db)
-	if err != nil {
-		resp.Msg = err.Error()
+// search value of key by match function, onMatch is called with field (empty for string),
+// and return false to stop searching in current key
+func (b *browserService) searchKeyValue(ctx context.Context, cli redis.UniversalClient, key, keyType string,
+	match func(string) bool, onMatch func(field, value string) bool) error {
+	scanSize := int64(Preferences().GetScanSize())
+	switch keyType {
+	case "string":
+		val, err := cli.Get(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if match(val) {
+			onMatch("", val)
+		}
+
+	case "hash":
+		iter := cli.HScan(ctx, key, 0, "*", scanSize).Iterator()
+		for iter.Next(ctx) {
+			field := iter.Val()
+			if !iter.Next(ctx) {
+				break
+			}
+			if val := iter.Val(); match(field) || match(val) {
+				if !onMatch(field, val) {
+					return nil
+				}
+			}
+		}
+		return iter.Err()
+
+	case "set":
+		iter := cli.SScan(ctx, key, 0, "*", scanSize).Iterator()
+		for iter.Next(ctx) {
+			if val := iter.Val(); match(val) {
+				if !onMatch("", val) {
+					return nil
+				}
+			}
+		}
+		return iter.Err()
+
+	case "zset":
+		iter := cli.ZScan(ctx, key, 0, "*", scanSize).Iterator()
+		for iter.Next(ctx) {
+			member := iter.Val()
+			if !iter.Next(ctx) {
+				break
+			}
+			if match(member) {
+				// field is the score of member
+				if !onMatch(iter.Val(), member) {
+					return nil
+				}
+			}
+		}
+		return iter.Err()
+
+	case "list":
+		for start := int64(0); ; start += scanSize {
+			vals, err := cli.LRange(ctx, key, start, start+scanSize-1).Result()
+			if err != nil {
+				return err
+			}
+			for i, val := range vals {
+				if match(val) {
+					if !onMatch(strconv.FormatInt(start+int64(i), 10), val) {
+						return nil
+					}
+				}
+			}
+			if int64(len(vals)) < scanSize {
+				break
+			}
+		}
+
+	case "stream":
+		start := "-"
+		for {
+			msgs, err := cli.XRangeN(ctx, key, start, "+", scanSize).Result()
+			if err != nil {
+				return err
+			}
+			for _, msg := range msgs {
+				for field, val := range msg.Values {
+					if str := fmt.Sprint(val); match(field) || match(str) {
+						if !onMatch(msg.ID+" "+field, str) {
+							return nil
+						}
+					}
+				}
+			}
+			if int64(len(msgs)) < scanSize {
+				break
+			}
+			// exclusive range start from next entry
+			start = "(" + msgs[len(msgs)-1].ID
+		}
+
+	case "ReJSON-RL":
+		val, err := cli.JSONGet(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if match(val) {
+			onMatch("", val)
+		}
+	}
+	// module types else are not searchable
+	return nil
+}
+
+// SearchValues scan keys matching pattern and search keyword in their values,
+// matched entries are emitted by event "valsearch:<serialNo>" during searching
+func (b *browserService) SearchValues(param types.ValueSearchParam) (resp types.JSResp) {
+	if len(param.Keyword) <= 0 {
+		resp.Msg = "keyword is required"
 		return
 	}
+	var match func(string) bool
+	if param.Regex {
+		expr := param.Keyword
+		if param.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		reg, err := regexp.Compile(expr)
+		if err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		match = reg.MatchString
+	} else if param.IgnoreCase {
+		keyword := strings.ToLower(param.Keyword)
+		match = func(s string) bool {
+			return strings.Contains(strings.ToLower(s), keyword)
+		}
+	} else {
+		match = func(s string) bool {
+			return strings.Contains(s, param.Keyword)
+		}
+	}
+	pattern := param.Pattern
+	if len(pattern) <= 0 {
+		pattern = "*"
+	}
+	var keyType string
+	if len(param.Type) > 0 {
+		keyType = scanTypeName(param.Type)
+	}
 
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(k)
-	var updateID string
-	updateID, err = client.XAdd(ctx, &redis.XAddArgs{
-		Stream: key,
-		ID:     ID,
-		Values: fieldItems,
-	}).Result()
+	item, err := b.getRedisClient(param.Server, param.DB)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	client := item.client
+	ctx, cancelFunc := context.WithCancel(b.ctx)
+	defer cancelFunc()
 
-	updateValues := make(map[string]any, len(fieldItems)/2)
-	for i := 0; i < len(fieldItems)/2; i += 2 {
-		updateValues[fieldItems[i].(string)] = fieldItems[i+1]
+	cancelStopEvent := runtime.EventsOnce(ctx, "valsearch:stop:"+param.SerialNo, func(data ...any) {
+		cancelFunc()
+	})
+	processEvent := "valsearch:" + param.SerialNo
+	job := Jobs().newJob("search", "search values of "+pattern, param.Server, param.DB, cancelFunc)
+	const maxMatches = 1000
+	const maxPerKey = 10
+	const maxValueLen = 200
+	var scanned, matched atomic.Int64
+	var mutex sync.Mutex
+	var pending []map[string]any
+	var truncated atomic.Bool
+	startTime := time.Now().Add(-10 * time.Second)
+	err = b.scanKeysInBatch(ctx, client, pattern, param.Type, 100, func(ctx context.Context, cli redis.UniversalClient, keys []string) error {
+		keyTypes := make([]string, len(keys))
+		if len(keyType) > 0 {
+			for i := range keys {
+				keyTypes[i] = keyType
+			}
+		} else {
+			pipe := cli.Pipeline()
+			for _, key := range keys {
+				pipe.Type(ctx, key)
+			}
+			cmders, typeErr := pipe.Exec(ctx)
+			if errors.Is(typeErr, context.Canceled) {
+				return typeErr
+			}
+			for i, cmder := range cmders {
+				keyTypes[i] = cmder.(*redis.StatusCmd).Val()
+			}
+		}
+
+		for i, key := range keys {
+			var keyMatched int
+			searchErr := b.searchKeyValue(ctx, cli, key, keyTypes[i], match, func(field, value string) bool {
+				if len(value) > maxValueLen {
+					value = value[:maxValueLen]
+				}
+				mutex.Lock()
+				pending = append(pending, map[string]any{
+					"key":   strutil.EncodeRedisKey(key),
+					"type":  strings.ToLower(keyTypes[i]),
+					"field": field,
+					"value": strutil.EncodeRedisKey(value),
+				})
+				mutex.Unlock()
+				keyMatched += 1
+				return matched.Add(1) < maxMatches && keyMatched < maxPerKey
+			})
+			if errors.Is(searchErr, context.Canceled) {
+				return searchErr
+			}
+			// ignore keys removed or changed during searching
+			scanned.Add(1)
+			if matched.Load() >= maxMatches {
+				truncated.Store(true)
+				cancelFunc()
+				return context.Canceled
+			}
+		}
+
+		job.update(scanned.Load(), 0)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if time.Now().Sub(startTime).Milliseconds() > 100 {
+			startTime = time.Now()
+			runtime.EventsEmit(ctx, processEvent, map[string]any{
+				"matches": pending,
+				"scanned": scanned.Load(),
+			})
+			pending = nil
+		}
+		return nil
+	})
+	cancelStopEvent()
+	canceled := errors.Is(err, context.Canceled) && !truncated.Load()
+	if truncated.Load() {
+		job.finish(false, nil)
+	} else {
+		job.finish(false, err)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		resp.Msg = err.Error()
+		return
+	}
+	if len(pending) > 0 {
+		// emit the rest matches with app context, search context may be canceled
+		runtime.EventsEmit(b.ctx, processEvent, map[string]any{
+			"matches": pending,
+			"scanned": scanned.Load(),
+		})
 	}
-	vb, _ := json.Marshal(updateValues)
-	displayValue, _, _ := convutil.ConvertTo(string(vb), types.DECODE_NONE, types.FORMAT_JSON, Preferences().GetDecoder())
 
 	resp.Success = true
-	resp.Data = struct {
-		Added []types.StreamEntryItem `json:"added,omitempty"`
-	}{
-		Added: []types.StreamEntryItem{
-			{
-				ID:           updateID,
-				Value:        updateValues,
-				DisplayValue: displayValue, // TODO: convert to display value
-			},
-		},
+	resp.Data = map[string]any{
+		"canceled":  canceled,
+		"truncated": truncated.Load(), // stopped for too many matches
+		"scanned":   scanned.Load(),
+		"matched":   matched.Load(),
 	}
 	return
 }
 
-// RemoveStreamValues remove stream values by id
-func (b *browserService) RemoveStreamValues(server string, db int, k any, IDs []string) (resp types.JSResp) {
-	item, err := b.getRedisClient(server, db)
+// get index of ttl range in types.NamespaceTTLRanges
+func ttlRangeIndex(ttl time.Duration) int {
+	switch {
+	case ttl < 0:
+		return 0
+	case ttl < time.Minute:
+		return 1
+	case ttl < time.Hour:
+		return 2
+	case ttl < 24*time.Hour:
+		return 3
+	case ttl < 7*24*time.Hour:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// AnalyzeNamespaces scan keys and aggregate key count, type, sampled memory usage and ttl distribution of each namespace,
+// progress is emitted by event "nsstat:<serialNo>", and can be canceled by event "nsstat:stop:<serialNo>"
+func (b *browserService) AnalyzeNamespaces(param types.NamespaceStatParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	pattern := param.Pattern
+	if len(pattern) <= 0 {
+		pattern = "*"
+	}
+	depth := max(param.Depth, 1)
+	sampleRate := int64(max(param.SampleRate, 1))
+	separators := item.separators
+	client := item.client
+	ctx, cancelFunc := context.WithCancel(b.ctx)
+	defer cancelFunc()
+
+	cancelStopEvent := runtime.EventsOnce(ctx, "nsstat:stop:"+param.SerialNo, func(data ...any) {
+		cancelFunc()
+	})
+	processEvent := "nsstat:" + param.SerialNo
+	job := Jobs().newJob("analyze", "analyze namespaces of "+pattern, param.Server, param.DB, cancelFunc)
+	stats := map[string]*types.NamespaceStat{}
+	var scanned int64
+	var mutex sync.Mutex
+	startTime := time.Now().Add(-10 * time.Second)
+	err = b.scanKeysInBatch(ctx, client, pattern, "", 500, func(ctx context.Context, cli redis.UniversalClient, keys []string) error {
+		mutex.Lock()
+		base := scanned
+		scanned += int64(len(keys))
+		mutex.Unlock()
+
+		pipe := cli.Pipeline()
+		typeCmds := make([]*redis.StatusCmd, len(keys))
+		ttlCmds := make([]*redis.DurationCmd, len(keys))
+		memCmds := make([]*redis.IntCmd, len(keys))
+		for i, key := range keys {
+			typeCmds[i] = pipe.Type(ctx, key)
+			ttlCmds[i] = pipe.PTTL(ctx, key)
+			if (base+int64(i))%sampleRate == 0 {
+				memCmds[i] = pipe.MemoryUsage(ctx, key, 0)
+			}
+		}
+		if _, pipeErr := pipe.Exec(ctx); errors.Is(pipeErr, context.Canceled) {
+			return pipeErr
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		for i, key := range keys {
+			keyType := typeCmds[i].Val()
+			if keyType == "none" || len(keyType) <= 0 {
+				// removed during analyzing
+				continue
+			}
+			var namespace string
+			if parts := strutil.SplitKey(key, separators); len(parts) > 1 {
+				namespace = strings.Join(parts[:min(depth, len(parts)-1)], separators[0])
+			}
+			stat, ok := stats[namespace]
+			if !ok {
+				stat = &types.NamespaceStat{
+					Namespace: namespace,
+					Types:     map[string]int64{},
+					TTL:       map[string]int64{},
+				}
+				stats[namespace] = stat
+			}
+			stat.Keys += 1
+			stat.Types[strings.ToLower(keyType)] += 1
+			stat.TTL[types.NamespaceTTLRanges[ttlRangeIndex(ttlCmds[i].Val())]] += 1
+			if memCmds[i] != nil && memCmds[i].Err() == nil {
+				stat.SampledKeys += 1
+				stat.SampledMemory += memCmds[i].Val()
+			}
+		}
+		job.update(scanned, 0)
+
+		if time.Now().Sub(startTime).Milliseconds() > 100 {
+			startTime = time.Now()
+			runtime.EventsEmit(ctx, processEvent, map[string]any{
+				"scanned":    scanned,
+				"namespaces": len(stats),
+				"processing": keys[len(keys)-1],
+			})
+		}
+		return nil
+	})
+	cancelStopEvent()
+	job.finish(false, err)
+	canceled := errors.Is(err, context.Canceled)
+	if err != nil && !canceled {
+		resp.Msg = err.Error()
+		return
+	}
 
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(k)
-
-	var affected int64
-	affected, err = client.XDel(ctx, key, IDs...).Result()
-	if err != nil {
-		resp.Msg = err.Error()
-		return
+	report := make([]types.NamespaceStat, 0, len(stats))
+	for _, stat := range stats {
+		if stat.SampledKeys > 0 {
+			// estimate total memory by average of sampled keys
+			stat.Memory = stat.SampledMemory * stat.Keys / stat.SampledKeys
+		}
+		report = append(report, *stat)
 	}
+	slices.SortFunc(report, func(a, b types.NamespaceStat) int {
+		if a.Keys != b.Keys {
+			return int(b.Keys - a.Keys)
+		}
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
 
 	resp.Success = true
-	resp.Data = struct {
-		Affected int64 `json:"affected"`
-	}{
-		Affected: affected,
+	resp.Data = map[string]any{
+		"canceled":  canceled, // report is partial if canceled
+		"scanned":   scanned,
+		"separator": separators[0],
+		"stats":     report,
 	}
 	return
 }
 
-// SetKeyTTL set ttl of key
-func (b *browserService) SetKeyTTL(server string, db int, k any, ttl int64) (resp types.JSResp) {
-	item, err := b.getRedisClient(server, db)
+// ExportNamespaceStats export report of AnalyzeNamespaces to csv file
+func (b *browserService) ExportNamespaceStats(stats []types.NamespaceStat, path string) (resp types.JSResp) {
+	file, err := os.Create(path)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	defer file.Close()
 
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(k)
-	if ttl < 0 {
-		if err = client.Persist(ctx, key).Err(); err != nil {
-			resp.Msg = err.Error()
-			return
+	typeSet := coll.NewSet[string]()
+	for _, stat := range stats {
+		for keyType := range stat.Types {
+			typeSet.Add(keyType)
 		}
-	} else {
-		expiration := time.Duration(ttl) * time.Second
-		if err = client.Expire(ctx, key, expiration).Err(); err != nil {
+	}
+	keyTypes := typeSet.ToSlice()
+	slices.Sort(keyTypes)
+
+	writer := csv.NewWriter(file)
+	header := []string{"namespace", "keys", "memory", "sampled_keys", "sampled_memory"}
+	header = append(header, keyTypes...)
+	for _, r := range types.NamespaceTTLRanges {
+		header = append(header, "ttl "+r)
+	}
+	if err = writer.Write(header); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	for _, stat := range stats {
+		row := []string{
+			stat.Namespace,
+			strconv.FormatInt(stat.Keys, 10),
+			strconv.FormatInt(stat.Memory, 10),
+			strconv.FormatInt(stat.SampledKeys, 10),
+			strconv.FormatInt(stat.SampledMemory, 10),
+		}
+		for _, keyType := range keyTypes {
+			row = append(row, strconv.FormatInt(stat.Types[keyType], 10))
+		}
+		for _, r := range types.NamespaceTTLRanges {
+			row = append(row, strconv.FormatInt(stat.TTL[r], 10))
+		}
+		if err = writer.Write(row); err != nil {
 			resp.Msg = err.Error()
 			return
 		}
 	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
 
 	resp.Success = true
 	return
 }
 
-// BatchSetTTL batch set ttl
-func (b *browserService) BatchSetTTL(server string, db int, ks []any, ttl int64, serialNo string) (resp types.JSResp) {
+// ExportKey export keys
+func (b *browserService) ExportKey(server string, db int, ks []any, path string, includeExpire bool) (resp types.JSResp) {
 	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
@@ -2042,335 +6199,269 @@ func (b *browserService) BatchSetTTL(server string, db int, ks []any, ttl int64,
 	ctx, cancelFunc := context.WithCancel(b.ctx)
 	defer cancelFunc()
 
-	//cancelEvent := "ttling:stop:" + serialNo
-	//runtime.EventsOnce(ctx, cancelEvent, func(data ...any) {
-	//	cancelFunc()
-	//})
-	//processEvent := "ttling:" + serialNo
-	total := len(ks)
-	var failed, updated atomic.Int64
-	var canceled bool
-
-	expiration := time.Now().Add(time.Duration(ttl) * time.Second)
-	del := func(ctx context.Context, cli redis.UniversalClient) error {
-		startTime := time.Now().Add(-10 * time.Second)
-		for i, k := range ks {
-			// emit progress per second
-			//param := map[string]any{
-			//	"total":      total,
-			//	"progress":   i + 1,
-			//	"processing": k,
-			//}
-			if i >= total-1 || time.Now().Sub(startTime).Milliseconds() > 100 {
-				startTime = time.Now()
-				//runtime.EventsEmit(ctx, processEvent, param)
-				// do some sleep to prevent blocking the Redis server
-				time.Sleep(10 * time.Millisecond)
-			}
-
-			key := strutil.DecodeRedisKey(k)
-			var expErr error
-			if ttl < 0 {
-				expErr = cli.Persist(ctx, key).Err()
-			} else {
-				expErr = cli.ExpireAt(ctx, key, expiration).Err()
-			}
-			if err != nil {
-				failed.Add(1)
-			} else {
-				// save deleted key
-				updated.Add(1)
-			}
-			if errors.Is(expErr, context.Canceled) || canceled {
-				canceled = true
-				break
-			}
-		}
-		return nil
-	}
-
-	if cluster, ok := client.(*redis.ClusterClient); ok {
-		// cluster mode
-		err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
-			return del(ctx, cli)
-		})
-	} else {
-		err = del(ctx, client)
-	}
-
-	//runtime.EventsOff(ctx, cancelEvent)
-	resp.Success = true
-	resp.Data = struct {
-		Canceled bool  `json:"canceled"`
-		Updated  int64 `json:"updated"`
-		Failed   int64 `json:"failed"`
-	}{
-		Canceled: canceled,
-		Updated:  updated.Load(),
-		Failed:   failed.Load(),
-	}
-	return
-}
-
-// DeleteKey remove redis key
-func (b *browserService) DeleteKey(server string, db int, k any, async bool) (resp types.JSResp) {
-	item, err := b.getRedisClient(server, db)
+	file, err := os.Create(path)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	defer file.Close()
 
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(k)
-	var deletedKeys []string
-	if strings.HasSuffix(key, "*") {
-		// delete by prefix
-		var mutex sync.Mutex
-		supportUnlink := true
-		del := func(ctx context.Context, cli redis.UniversalClient) error {
-			handleDel := func(ks []string) error {
-				var delErr error
-				if async && supportUnlink {
-					if delErr = cli.Unlink(ctx, ks...).Err(); delErr != nil {
-						supportUnlink = false
-						// not support unlink? try del command
-						delErr = cli.Del(ctx, ks...).Err()
-					}
-				} else {
-					delErr = cli.Del(ctx, ks...).Err()
-				}
-
-				mutex.Lock()
-				deletedKeys = append(deletedKeys, ks...)
-				mutex.Unlock()
-
-				return delErr
-			}
-
-			scanSize := int64(Preferences().GetScanSize())
-			iter := cli.Scan(ctx, 0, key, scanSize).Iterator()
-			resultKeys := make([]string, 0, 100)
-			for iter.Next(ctx) {
-				resultKeys = append(resultKeys, iter.Val())
-				if len(resultKeys) >= 20 {
-					handleDel(resultKeys)
-					resultKeys = resultKeys[:0:cap(resultKeys)]
-				}
-			}
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
 
-			if len(resultKeys) > 0 {
-				handleDel(resultKeys)
+	cancelStopEvent := runtime.EventsOnce(ctx, "export:stop:"+path, func(data ...any) {
+		cancelFunc()
+	})
+	processEvent := "exporting:" + path
+	total := len(ks)
+	job := Jobs().newJob("export", "export keys to "+path, server, db, cancelFunc)
+	var exported, failed int64
+	var canceled bool
+	var lastErr error
+	startTime := time.Now().Add(-10 * time.Second)
+	for i, k := range ks {
+		job.update(int64(i), int64(total))
+		if i >= total-1 || time.Now().Sub(startTime).Milliseconds() > 100 {
+			startTime = time.Now()
+			param := map[string]any{
+				"total":      total,
+				"progress":   i + 1,
+				"processing": k,
 			}
-			return nil
+			runtime.EventsEmit(ctx, processEvent, param)
 		}
 
-		if cluster, ok := client.(*redis.ClusterClient); ok {
-			// cluster mode
-			err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
-				return del(ctx, cli)
-			})
-		} else {
-			err = del(ctx, client)
+		key := strutil.DecodeRedisKey(k)
+		content, dumpErr := client.Dump(ctx, key).Bytes()
+		if errors.Is(dumpErr, context.Canceled) || canceled {
+			canceled = true
+			break
 		}
-
-		if err != nil {
-			resp.Msg = err.Error()
-			return
+		if dumpErr != nil {
+			failed += 1
+			lastErr = dumpErr
+			job.log("dump key \"%s\" fail: %s", key, dumpErr.Error())
+			continue
 		}
-	} else {
-		// delete key only
-		if async {
-			if err = client.Unlink(ctx, key).Err(); err != nil {
-				if err = client.Del(ctx, key).Err(); err != nil {
-					resp.Msg = err.Error()
-					return
-				}
+		record := []string{hex.EncodeToString([]byte(key)), hex.EncodeToString(content)}
+		if includeExpire {
+			if dur, ttlErr := client.PTTL(ctx, key).Result(); ttlErr == nil && dur > 0 {
+				record = append(record, strconv.FormatInt(time.Now().Add(dur).UnixMilli(), 10))
+			} else {
+				record = append(record, "-1")
 			}
+		}
+		if err = writer.Write(record); err != nil {
+			failed += 1
+			lastErr = err
 		} else {
-			if err = client.Del(ctx, key).Err(); err != nil {
-				resp.Msg = err.Error()
-				return
-			}
+			exported += 1
 		}
-		deletedKeys = append(deletedKeys, key)
-	}
-
-	resp.Success = true
-	resp.Data = map[string]any{
-		"deleted":     deletedKeys,
-		"deleteCount": len(deletedKeys),
 	}
-	return
-}
 
-// DeleteOneKey delete one key
-func (b *browserService) DeleteOneKey(server string, db int, k any) (resp types.JSResp) {
-	item, err := b.getRedisClient(server, db)
-	if err != nil {
+	cancelStopEvent()
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		job.finish(canceled, err)
 		resp.Msg = err.Error()
 		return
 	}
-
-	client, ctx := item.client, item.ctx
-	key := strutil.DecodeRedisKey(k)
-	if cluster, ok := client.(*redis.ClusterClient); ok {
-		// cluster mode
-		err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
-			return cli.Del(ctx, key).Err()
-		})
-	} else {
-		err = client.Del(ctx, key).Err()
+	job.finish(canceled, jobFailure(failed, lastErr))
+	resp.Success = true
+	resp.Data = struct {
+		Canceled bool  `json:"canceled"`
+		Exported int64 `json:"exported"`
+		Failed   int64 `json:"failed"`
+	}{
+		Canceled: canceled,
+		Exported: exported,
+		Failed:   failed,
 	}
+	return
+}
 
+// ExportKeyList export names of keys matched by pattern, type and the scan filter of connection,
+// with type, ttl and memory usage of each key, to csv file or clipboard
+func (b *browserService) ExportKeyList(param types.KeyListExportParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	pattern := param.Pattern
+	if len(pattern) <= 0 {
+		pattern = "*"
+	}
 
-	resp.Success = true
-	return
-}
-
-// DeleteKeys delete keys sync with notification
-func (b *browserService) DeleteKeys(server string, db int, ks []any, serialNo string) (resp types.JSResp) {
-	item, err := b.getRedisClient(server, db)
-	if err != nil {
+	var output io.Writer
+	var buf bytes.Buffer
+	if len(param.Path) > 0 {
+		file, createErr := os.Create(param.Path)
+		if createErr != nil {
+			resp.Msg = createErr.Error()
+			return
+		}
+		defer file.Close()
+		output = file
+	} else {
+		output = &buf
+	}
+	writer := csv.NewWriter(output)
+	if err = writer.Write([]string{"key", "type", "ttl", "size"}); err != nil {
 		resp.Msg = err.Error()
 		return
 	}
-	client := item.client
+
+	client, filter := item.client, item.scanFilter
 	ctx, cancelFunc := context.WithCancel(b.ctx)
 	defer cancelFunc()
-
-	cancelEvent := "delete:stop:" + serialNo
-	cancelStopEvent := runtime.EventsOnce(ctx, cancelEvent, func(data ...any) {
-		cancelFunc()
-	})
-	total := len(ks)
-	var canceled bool
-	var deletedKeys = make([]any, 0, total)
+	job := Jobs().newJob("export", "export key list of "+pattern, param.Server, param.DB, cancelFunc)
+	var exported int64
 	var mutex sync.Mutex
-	del := func(ctx context.Context, cli redis.UniversalClient) error {
-		const batchSize = 1000
-		for i := 0; i < total; i += batchSize {
-			pipe := cli.Pipeline()
-			for j := 0; j < batchSize; j++ {
-				if i+j < total {
-					pipe.Del(ctx, strutil.DecodeRedisKey(ks[i+j]))
-				}
+	err = b.scanKeysInBatch(ctx, client, pattern, param.Type, 500, func(ctx context.Context, cli redis.UniversalClient, keys []string) error {
+		keys = sliceutil.FilterMap(keys, func(i int) (string, bool) {
+			return keys[i], filter.match(keys[i])
+		})
+		pipe := cli.Pipeline()
+		typeCmds := make([]*redis.StatusCmd, len(keys))
+		ttlCmds := make([]*redis.DurationCmd, len(keys))
+		memCmds := make([]*redis.IntCmd, len(keys))
+		for i, key := range keys {
+			typeCmds[i] = pipe.Type(ctx, key)
+			ttlCmds[i] = pipe.TTL(ctx, key)
+			memCmds[i] = pipe.MemoryUsage(ctx, key, 0)
+		}
+		if _, pipeErr := pipe.Exec(ctx); errors.Is(pipeErr, context.Canceled) {
+			return pipeErr
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		for i, key := range keys {
+			if typeCmds[i].Val() == "none" {
+				// removed during exporting
+				continue
 			}
-			cmders, delErr := pipe.Exec(ctx)
-			for j, cmder := range cmders {
-				if cmder.(*redis.IntCmd).Val() == 1 {
-					// save deleted key
-					mutex.Lock()
-					deletedKeys = append(deletedKeys, ks[i+j])
-					mutex.Unlock()
-				}
+			ttl := int64(-1)
+			if ttlCmds[i].Val() >= 0 {
+				ttl = int64(ttlCmds[i].Val().Seconds())
 			}
-			if errors.Is(delErr, context.Canceled) || canceled {
-				canceled = true
-				break
+			record := []string{
+				key,
+				displayKeyType(typeCmds[i].Val()),
+				strconv.FormatInt(ttl, 10),
+				strconv.FormatInt(memCmds[i].Val(), 10),
+			}
+			if writeErr := writer.Write(record); writeErr != nil {
+				return writeErr
 			}
+			exported += 1
 		}
+		job.update(exported, 0)
 		return nil
+	})
+	job.finish(false, err)
+	canceled := errors.Is(err, context.Canceled)
+	if err != nil && !canceled {
+		resp.Msg = err.Error()
+		return
 	}
-
-	if cluster, ok := client.(*redis.ClusterClient); ok {
-		// cluster mode
-		err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
-			return del(ctx, cli)
-		})
-	} else {
-		err = del(ctx, client)
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if len(param.Path) <= 0 {
+		if err = runtime.ClipboardSetText(b.ctx, buf.String()); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
 	}
 
-	cancelStopEvent()
 	resp.Success = true
-	resp.Data = struct {
-		Canceled bool `json:"canceled"`
-		Deleted  any  `json:"deleted"`
-		Failed   int  `json:"failed"`
-	}{
-		Canceled: canceled,
-		Deleted:  deletedKeys,
-		Failed:   len(ks) - len(deletedKeys),
+	resp.Data = map[string]any{
+		"canceled": canceled,
+		"exported": exported,
 	}
 	return
 }
 
-// DeleteKeysByPattern delete keys by pattern
-func (b *browserService) DeleteKeysByPattern(server string, db int, pattern string) (resp types.JSResp) {
-	item, err := b.getRedisClient(server, db)
+// generate type-specific commands to rebuild key, large collections are split into multiple commands
+// returns nil commands for unsupported types like module types
+func (b *browserService) keyToCommands(ctx context.Context, client redis.UniversalClient, key string) ([][]string, error) {
+	const chunkSize = 100
+	keyType, err := client.Type(ctx, key).Result()
 	if err != nil {
-		resp.Msg = err.Error()
-		return
+		return nil, err
 	}
-	client := item.client
-	ctx, cancelFunc := context.WithCancel(b.ctx)
-	defer cancelFunc()
 
-	var ks []any
-	ks, _, err = b.scanKeys(ctx, client, pattern, "", 0, 0)
-	if err != nil {
-		resp.Msg = err.Error()
-		return
+	var cmds [][]string
+	appendChunks := func(cmd string, vals []string, step int) {
+		for i := 0; i < len(vals); i += chunkSize * step {
+			args := []string{cmd, key}
+			args = append(args, vals[i:min(i+chunkSize*step, len(vals))]...)
+			cmds = append(cmds, args)
+		}
 	}
-
-	total := len(ks)
-	var canceled bool
-	var deletedKeys = make([]any, 0, total)
-	var mutex sync.Mutex
-	del := func(ctx context.Context, cli redis.UniversalClient) error {
-		const batchSize = 1000
-		for i := 0; i < total; i += batchSize {
-			pipe := cli.Pipeline()
-			for j := 0; j < batchSize; j++ {
-				if i+j < total {
-					pipe.Del(ctx, strutil.DecodeRedisKey(ks[i+j]))
+	switch keyType {
+	case "string":
+		var val string
+		if val, err = client.Get(ctx, key).Result(); err == nil {
+			cmds = append(cmds, []string{"SET", key, val})
+		}
+	case "list":
+		var vals []string
+		if vals, err = client.LRange(ctx, key, 0, -1).Result(); err == nil {
+			appendChunks("RPUSH", vals, 1)
+		}
+	case "hash", "set", "zset":
+		// scan instead of loading the whole collection at once
+		var iter *redis.ScanIterator
+		switch keyType {
+		case "hash":
+			iter = client.HScan(ctx, key, 0, "*", chunkSize).Iterator()
+		case "set":
+			iter = client.SScan(ctx, key, 0, "*", chunkSize).Iterator()
+		default:
+			iter = client.ZScan(ctx, key, 0, "*", chunkSize).Iterator()
+		}
+		var vals []string
+		for iter.Next(ctx) {
+			vals = append(vals, iter.Val())
+		}
+		if err = iter.Err(); err == nil {
+			switch keyType {
+			case "hash":
+				appendChunks("HSET", vals, 2)
+			case "set":
+				appendChunks("SADD", vals, 1)
+			default:
+				// swap member and score
+				for i := 0; i+1 < len(vals); i += 2 {
+					vals[i], vals[i+1] = vals[i+1], vals[i]
 				}
+				appendChunks("ZADD", vals, 2)
 			}
-			cmders, delErr := pipe.Exec(ctx)
-			for j, cmder := range cmders {
-				if cmder.(*redis.IntCmd).Val() == 1 {
-					// save deleted key
-					mutex.Lock()
-					deletedKeys = append(deletedKeys, ks[i+j])
-					mutex.Unlock()
+		}
+	case "stream":
+		var msgs []redis.XMessage
+		if msgs, err = client.XRange(ctx, key, "-", "+").Result(); err == nil {
+			for _, msg := range msgs {
+				args := []string{"XADD", key, msg.ID}
+				for field, val := range msg.Values {
+					args = append(args, field, fmt.Sprint(val))
 				}
-			}
-			if errors.Is(delErr, context.Canceled) || canceled {
-				canceled = true
-				break
+				cmds = append(cmds, args)
 			}
 		}
-		return nil
 	}
-
-	if cluster, ok := client.(*redis.ClusterClient); ok {
-		// cluster mode
-		err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
-			return del(ctx, cli)
-		})
-	} else {
-		err = del(ctx, client)
-	}
-
-	resp.Success = true
-	resp.Data = struct {
-		Canceled bool `json:"canceled"`
-		Deleted  any  `json:"deleted"`
-		Failed   int  `json:"failed"`
-	}{
-		Canceled: canceled,
-		Deleted:  deletedKeys,
-		Failed:   len(ks) - len(deletedKeys),
-	}
-	return
+	return cmds, err
 }
 
-// ExportKey export keys
-func (b *browserService) ExportKey(server string, db int, ks []any, path string, includeExpire bool) (resp types.JSResp) {
+// ExportKeyRESP export keys as raw RESP commands, which can be replayed by "redis-cli --pipe"
+// @param useRestore export binary payload by "RESTORE", or type-specific commands if false.
+// keys of module types are always exported by "RESTORE"
+func (b *browserService) ExportKeyRESP(server string, db int, ks []any, path string, includeExpire, useRestore bool) (resp types.JSResp) {
 	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
@@ -2387,7 +6478,7 @@ func (b *browserService) ExportKey(server string, db int, ks []any, path string,
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
+	writer := bufio.NewWriter(file)
 	defer writer.Flush()
 
 	cancelStopEvent := runtime.EventsOnce(ctx, "export:stop:"+path, func(data ...any) {
@@ -2395,10 +6486,13 @@ func (b *browserService) ExportKey(server string, db int, ks []any, path string,
 	})
 	processEvent := "exporting:" + path
 	total := len(ks)
+	job := Jobs().newJob("export", "export keys to "+path, server, db, cancelFunc)
 	var exported, failed int64
 	var canceled bool
+	var lastErr error
 	startTime := time.Now().Add(-10 * time.Second)
 	for i, k := range ks {
+		job.update(int64(i), int64(total))
 		if i >= total-1 || time.Now().Sub(startTime).Milliseconds() > 100 {
 			startTime = time.Now()
 			param := map[string]any{
@@ -2410,40 +6504,188 @@ func (b *browserService) ExportKey(server string, db int, ks []any, path string,
 		}
 
 		key := strutil.DecodeRedisKey(k)
-		content, dumpErr := client.Dump(ctx, key).Bytes()
-		if errors.Is(dumpErr, context.Canceled) || canceled {
+		var cmds [][]string
+		var exportErr error
+		if !useRestore {
+			cmds, exportErr = b.keyToCommands(ctx, client, key)
+			if len(cmds) > 0 {
+				// remove existing key before rebuilding
+				cmds = append([][]string{{"DEL", key}}, cmds...)
+			}
+		}
+		var ttl time.Duration
+		if exportErr == nil && includeExpire {
+			ttl, exportErr = client.PTTL(ctx, key).Result()
+		}
+		if exportErr == nil && len(cmds) <= 0 {
+			var content string
+			if content, exportErr = client.Dump(ctx, key).Result(); exportErr == nil {
+				args := []string{"RESTORE", key, "0", content, "REPLACE"}
+				if ttl > 0 {
+					args[2] = strconv.FormatInt(time.Now().Add(ttl).UnixMilli(), 10)
+					args = append(args, "ABSTTL")
+				}
+				cmds = [][]string{args}
+			}
+		} else if exportErr == nil && ttl > 0 {
+			cmds = append(cmds, []string{"PEXPIREAT", key, strconv.FormatInt(time.Now().Add(ttl).UnixMilli(), 10)})
+		}
+		if errors.Is(exportErr, context.Canceled) || ctx.Err() != nil {
 			canceled = true
 			break
 		}
-		record := []string{hex.EncodeToString([]byte(key)), hex.EncodeToString(content)}
-		if includeExpire {
-			if dur, ttlErr := client.PTTL(ctx, key).Result(); ttlErr == nil && dur > 0 {
-				record = append(record, strconv.FormatInt(time.Now().Add(dur).UnixMilli(), 10))
-			} else {
-				record = append(record, "-1")
+		if exportErr != nil {
+			failed += 1
+			lastErr = exportErr
+			job.log("export key \"%s\" fail: %s", key, exportErr.Error())
+			continue
+		}
+
+		for _, cmd := range cmds {
+			if _, err = writer.Write(redis2.EncodeRESP(cmd...)); err != nil {
+				break
 			}
 		}
-		if err = writer.Write(record); err != nil {
-			failed += 1
-		} else {
-			exported += 1
+		if err != nil {
+			cancelStopEvent()
+			job.finish(false, err)
+			resp.Msg = err.Error()
+			return
+		}
+		exported += 1
+	}
+
+	cancelStopEvent()
+	if err = writer.Flush(); err != nil {
+		job.finish(canceled, err)
+	} else {
+		job.finish(canceled, jobFailure(failed, lastErr))
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	resp.Data = struct {
+		Canceled bool  `json:"canceled"`
+		Exported int64 `json:"exported"`
+		Failed   int64 `json:"failed"`
+	}{
+		Canceled: canceled,
+		Exported: exported,
+		Failed:   failed,
+	}
+	return
+}
+
+// ExportKeyDump export payload of "DUMP" and ttl of one key to file in json format
+func (b *browserService) ExportKeyDump(server string, db int, k any, path string) (resp types.JSResp) {
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	keyType, err := client.Type(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if keyType == "none" {
+		resp.Msg = "key not exists"
+		return
+	}
+	payload, err := client.Dump(ctx, key).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	ttl := int64(-1)
+	if dur, ttlErr := client.PTTL(ctx, key).Result(); ttlErr == nil && dur > 0 {
+		ttl = dur.Milliseconds()
+	}
+
+	content, err := json.MarshalIndent(types.KeyDump{
+		Version:   1,
+		Key:       base64.StdEncoding.EncodeToString([]byte(key)),
+		Type:      keyType,
+		TTL:       ttl,
+		Payload:   base64.StdEncoding.EncodeToString([]byte(payload)),
+		CreatedAt: time.Now().UnixMilli(),
+	}, "", "  ")
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if err = os.WriteFile(path, content, 0644); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// RestoreKeyDump restore key from file exported by ExportKeyDump with "RESTORE"
+// @param newKey restore as another key if not empty
+// @param replace replace existing key, or fail if exists
+func (b *browserService) RestoreKeyDump(server string, db int, path string, newKey any, replace bool) (resp types.JSResp) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	var dump types.KeyDump
+	if err = json.Unmarshal(content, &dump); err != nil || len(dump.Payload) <= 0 {
+		resp.Msg = "invalid key dump file"
+		return
+	}
+	keyBytes, err := base64.StdEncoding.DecodeString(dump.Key)
+	if err != nil {
+		resp.Msg = "invalid key dump file"
+		return
+	}
+	payload, err := base64.StdEncoding.DecodeString(dump.Payload)
+	if err != nil {
+		resp.Msg = "invalid key dump file"
+		return
+	}
+	key := string(keyBytes)
+	if newKey != nil {
+		if k := strutil.DecodeRedisKey(newKey); len(k) > 0 {
+			key = k
 		}
 	}
 
-	cancelStopEvent()
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	client, ctx := item.client, item.ctx
+	ttl := time.Duration(max(dump.TTL, 0)) * time.Millisecond
+	if replace {
+		err = client.RestoreReplace(ctx, key, ttl, string(payload)).Err()
+	} else {
+		err = client.Restore(ctx, key, ttl, string(payload)).Err()
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
 	resp.Success = true
-	resp.Data = struct {
-		Canceled bool  `json:"canceled"`
-		Exported int64 `json:"exported"`
-		Failed   int64 `json:"failed"`
-	}{
-		Canceled: canceled,
-		Exported: exported,
-		Failed:   failed,
+	resp.Data = map[string]any{
+		"key":  strutil.EncodeRedisKey(key),
+		"type": dump.Type,
 	}
 	return
 }
 
+var errKeyExisted = errors.New("key already existed")
+
 // ImportCSV import data from csv file
 func (b *browserService) ImportCSV(server string, db int, path string, conflict int, ttl int64) (resp types.JSResp) {
 	item, err := b.getRedisClient(server, db)
@@ -2463,18 +6705,24 @@ func (b *browserService) ImportCSV(server string, db int, path string, conflict
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	var fileSize int64
+	if info, statErr := file.Stat(); statErr == nil {
+		fileSize = info.Size()
+	}
 
 	cancelEvent := "import:stop:" + path
 	cancelStopEvent := runtime.EventsOnce(ctx, cancelEvent, func(data ...any) {
 		cancelFunc()
 	})
 	processEvent := "importing:" + path
+	job := Jobs().newJob("import", "import keys from "+path, server, db, cancelFunc)
 	var line []string
 	var readErr error
 	var key, value []byte
 	var ttlValue time.Duration
-	var imported, ignored int64
+	var imported, ignored, failed int64
 	var canceled bool
+	var lastErr error
 	startTime := time.Now().Add(-10 * time.Second)
 	for {
 		readErr = nil
@@ -2513,15 +6761,22 @@ func (b *browserService) ImportCSV(server string, db int, path string, conflict
 			if n, _ := client.Exists(ctx, keyStr).Result(); n <= 0 {
 				readErr = client.Restore(ctx, keyStr, ttlValue, string(value)).Err()
 			} else {
-				readErr = errors.New("key already existed")
+				readErr = errKeyExisted
 			}
 		}
 		if readErr != nil {
 			// restore fail
 			ignored += 1
+			if !errors.Is(readErr, errKeyExisted) {
+				failed += 1
+				lastErr = readErr
+			}
+			job.log("restore key \"%s\" fail: %s", key, readErr.Error())
 		} else {
 			imported += 1
 		}
+		// progress by bytes read
+		job.update(reader.InputOffset(), fileSize)
 		if errors.Is(readErr, context.Canceled) || canceled {
 			canceled = true
 			break
@@ -2541,6 +6796,13 @@ func (b *browserService) ImportCSV(server string, db int, path string, conflict
 	}
 
 	cancelStopEvent()
+	if readErr != nil && !errors.Is(readErr, io.EOF) && !canceled {
+		// stopped by malformed content
+		job.finish(false, readErr)
+		resp.Msg = readErr.Error()
+		return
+	}
+	job.finish(canceled, jobFailure(failed, lastErr))
 	resp.Success = true
 	resp.Data = struct {
 		Canceled bool  `json:"canceled"`
@@ -2554,15 +6816,125 @@ func (b *browserService) ImportCSV(server string, db int, path string, conflict
 	return
 }
 
-// FlushDB flush database
-func (b *browserService) FlushDB(server string, db int, async bool) (resp types.JSResp) {
+// confirmation of flushing database, expired after a while
+type flushToken struct {
+	server  string
+	db      int
+	all     bool
+	confirm string
+	expire  time.Time
+}
+
+// PrepareFlush report size and sample keys of database before flushing, and return a one-time token,
+// the confirm text should be typed by user to flush by FlushDB
+// @param all prepare to flush all databases
+func (b *browserService) PrepareFlush(server string, db int, all bool) (resp types.JSResp) {
+	if conf := Connection().getConnection(server); conf != nil && conf.DisableFlush {
+		resp.Msg = "flushing database is disabled for this connection"
+		return
+	}
 	item, err := b.getRedisClient(server, db)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
+	client, ctx := item.client, item.ctx
+	var dbSize int64
+	if _, isCluster := client.(*redis.ClusterClient); all && !isCluster {
+		// sum keys of all databases
+		if res, err := client.Info(ctx, "keyspace").Result(); err == nil {
+			for _, dbInfo := range b.parseInfo(res)["Keyspace"] {
+				dbSize += int64(b.parseDBItemInfo(dbInfo)["keys"])
+			}
+		}
+	} else {
+		dbSize = b.loadDBSize(ctx, client)
+	}
+	// sample some keys of current database to show what will be deleted
+	samples, _, _ := b.scanKeys(ctx, client, "*", "", nil, 0, 20)
+	if len(samples) > 20 {
+		samples = samples[:20]
+	}
+
+	connName, _ := splitSession(server)
+	confirm := fmt.Sprintf("db%d", db)
+	if all {
+		confirm = connName
+	}
+	tokenBytes := make([]byte, 16)
+	if _, err = rand.Read(tokenBytes); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	token := hex.EncodeToString(tokenBytes)
+	b.mutex.Lock()
+	now := time.Now()
+	for t, ft := range b.flushTokens {
+		if now.After(ft.expire) {
+			delete(b.flushTokens, t)
+		}
+	}
+	b.flushTokens[token] = flushToken{
+		server:  server,
+		db:      db,
+		all:     all,
+		confirm: confirm,
+		expire:  now.Add(5 * time.Minute),
+	}
+	b.mutex.Unlock()
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"dbSize":  dbSize,
+		"samples": samples,
+		"confirm": confirm, // text should be typed by user
+		"token":   token,
+	}
+	return
+}
+
+// FlushDB flush database by "FLUSHDB ASYNC", or all databases by "FLUSHALL ASYNC",
+// token and confirm text returned by PrepareFlush are required
+func (b *browserService) FlushDB(param types.FlushParam) (resp types.JSResp) {
+	if conf := Connection().getConnection(param.Server); conf != nil {
+		if conf.ReadOnly {
+			resp.Msg = "flushing database is not allowed in read-only mode"
+			return
+		}
+		if conf.DisableFlush {
+			resp.Msg = "flushing database is disabled for this connection"
+			return
+		}
+	}
+	b.mutex.Lock()
+	ft, ok := b.flushTokens[param.Token]
+	// token can only be used once
+	delete(b.flushTokens, param.Token)
+	b.mutex.Unlock()
+	if !ok || time.Now().After(ft.expire) || ft.server != param.Server || ft.db != param.DB || ft.all != param.All {
+		resp.Msg = "flush confirmation is expired, please try again"
+		return
+	}
+	if param.Confirm != ft.confirm {
+		resp.Msg = fmt.Sprintf("confirm text mismatched, please type \"%s\"", ft.confirm)
+		return
+	}
+
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	db := param.DB
 	flush := func(ctx context.Context, cli redis.UniversalClient, async bool) error {
+		if param.All {
+			if async {
+				return cli.FlushAllAsync(ctx).Err()
+			}
+			return cli.FlushAll(ctx).Err()
+		}
 		_, e := cli.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
 			pipe.Select(ctx, db)
 			if async {
@@ -2579,17 +6951,17 @@ func (b *browserService) FlushDB(server string, db int, async bool) (resp types.
 	if cluster, ok := client.(*redis.ClusterClient); ok {
 		// cluster mode
 		err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
-			return flush(ctx, cli, async)
+			return flush(ctx, cli, true)
 		})
 		// try sync mode if error cause
-		if err != nil && async {
+		if err != nil {
 			err = cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
 				return flush(ctx, cli, false)
 			})
 		}
 	} else {
-		if err = flush(ctx, client, async); err != nil && async {
-			// try sync mode if error cause
+		if err = flush(ctx, client, true); err != nil {
+			// try sync mode if error cause, ASYNC is not supported before redis 4.0
 			err = flush(ctx, client, false)
 		}
 	}
@@ -2602,6 +6974,212 @@ func (b *browserService) FlushDB(server string, db int, async bool) (resp types.
 	return
 }
 
+// make temporary key name in the same slot of key, so that it can be renamed to key in cluster mode
+func sameSlotTempKey(key, suffix string) string {
+	if _, ok := redis2.KeyHashTag(key); ok {
+		// keep the original hash tag
+		return key + suffix
+	} else if !strings.ContainsRune(key, '}') {
+		return "{" + key + "}" + suffix
+	}
+	// whole key is hashed but can not be wrapped as tag, find another tag in the same slot
+	slot := redis2.KeySlot(key)
+	for i := 0; ; i++ {
+		if tag := "tinyrdm-" + strconv.Itoa(i); redis2.KeySlot(tag) == slot {
+			return "{" + tag + "}" + suffix
+		}
+	}
+}
+
+// ConvertKeyType convert key to another type, supports list/set/zset to each other, hash to json string and back,
+// converted value is written to a temporary key then renamed to target in a transaction, ttl is kept as well
+func (b *browserService) ConvertKeyType(param types.ConvertTypeParam) (resp types.JSResp) {
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(param.Key)
+	target := key
+	if param.Target != nil {
+		if t := strutil.DecodeRedisKey(param.Target); len(t) > 0 {
+			target = t
+		}
+	}
+	to := strings.ToLower(param.To)
+	tmpKey := sameSlotTempKey(key, ":tinyrdm-convert")
+	watchKeys := []string{key}
+	if target != key {
+		if _, ok := client.(*redis.ClusterClient); ok && redis2.KeySlot(target) != redis2.KeySlot(key) {
+			resp.Msg = "target key should be in the same slot of key in cluster mode, use hash tag like \"{tag}\" for them"
+			return
+		}
+		watchKeys = append(watchKeys, target)
+	}
+
+	const batchSize = 1000
+	var count int
+	err = client.Watch(ctx, func(tx *redis.Tx) error {
+		fromType, err := tx.Type(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if fromType == "none" {
+			return errors.New("key not exists")
+		}
+		if target != key {
+			if n, _ := tx.Exists(ctx, target).Result(); n > 0 {
+				return errors.New("target key already exists")
+			}
+		}
+		ttl, _ := tx.PTTL(ctx, key).Result()
+
+		// load all members or fields of original key
+		var members []string
+		switch fromType {
+		case "list":
+			members, err = tx.LRange(ctx, key, 0, -1).Result()
+		case "set":
+			members, err = tx.SMembers(ctx, key).Result()
+		case "zset":
+			members, err = tx.ZRange(ctx, key, 0, -1).Result()
+		case "hash":
+			var fields map[string]string
+			if fields, err = tx.HGetAll(ctx, key).Result(); err == nil && to == "json" {
+				for field, val := range fields {
+					// json.Marshal would replace invalid bytes with U+FFFD silently
+					if !utf8.ValidString(field) || !utf8.ValidString(val) {
+						return fmt.Errorf("field %q is not valid utf-8, can not convert to json", field)
+					}
+				}
+				var content []byte
+				if content, err = json.Marshal(fields); err == nil {
+					members = []string{string(content)}
+				}
+			}
+		case "string":
+			var str string
+			var obj map[string]any
+			if str, err = tx.Get(ctx, key).Result(); err == nil && to == "hash" {
+				if json.Unmarshal([]byte(str), &obj) != nil {
+					return errors.New("value is not a json object")
+				}
+				for field, val := range obj {
+					if v, ok := val.(string); ok {
+						members = append(members, field, v)
+					} else {
+						content, _ := json.Marshal(val)
+						members = append(members, field, string(content))
+					}
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		var write func(pipe redis.Pipeliner, batch []string)
+		step := batchSize
+		switch {
+		case to == "set" && (fromType == "list" || fromType == "zset"):
+			write = func(pipe redis.Pipeliner, batch []string) {
+				pipe.SAdd(ctx, tmpKey, sliceutil.Map(batch, func(i int) any { return batch[i] })...)
+			}
+		case to == "zset" && (fromType == "list" || fromType == "set"):
+			write = func(pipe redis.Pipeliner, batch []string) {
+				pipe.ZAdd(ctx, tmpKey, sliceutil.Map(batch, func(i int) redis.Z {
+					return redis.Z{Score: param.Score, Member: batch[i]}
+				})...)
+			}
+		case to == "list" && (fromType == "set" || fromType == "zset"):
+			write = func(pipe redis.Pipeliner, batch []string) {
+				pipe.RPush(ctx, tmpKey, sliceutil.Map(batch, func(i int) any { return batch[i] })...)
+			}
+		case to == "json" && fromType == "hash":
+			write = func(pipe redis.Pipeliner, batch []string) {
+				pipe.Set(ctx, tmpKey, batch[0], 0)
+			}
+		case to == "hash" && fromType == "string":
+			if len(members) <= 0 {
+				return errors.New("no field to convert")
+			}
+			step = batchSize * 2
+			write = func(pipe redis.Pipeliner, batch []string) {
+				pipe.HSet(ctx, tmpKey, sliceutil.Map(batch, func(i int) any { return batch[i] })...)
+			}
+		default:
+			return fmt.Errorf("convert from %s to %s is not supported", fromType, param.To)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, tmpKey)
+			for i := 0; i < len(members); i += step {
+				write(pipe, members[i:min(i+step, len(members))])
+			}
+			pipe.Rename(ctx, tmpKey, target)
+			if ttl > 0 {
+				pipe.PExpire(ctx, target, ttl)
+			}
+			return nil
+		})
+		if err == nil {
+			count = len(members)
+			if to == "hash" {
+				count /= 2
+			}
+		}
+		return err
+	}, watchKeys...)
+	if errors.Is(err, redis.TxFailedErr) {
+		err = errors.New("key has been modified by others during converting, please retry")
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"key":   strutil.EncodeRedisKey(target),
+		"count": count, // number of converted members or fields, before deduplicated
+	}
+	return
+}
+
+// RunKeyAction run user-defined key action in preferences by "EVAL", name of key is bound to KEYS[1]
+// @param args bound to ARGV
+func (b *browserService) RunKeyAction(server string, db int, k any, name string, args []string) (resp types.JSResp) {
+	action, ok := Preferences().getKeyAction(name)
+	if !ok {
+		resp.Msg = "no key action named \"" + name + "\""
+		return
+	}
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	key := strutil.DecodeRedisKey(k)
+	result, err := client.Eval(ctx, action.Script, []string{key}, sliceutil.Map(args, func(i int) any {
+		return args[i]
+	})...).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"result": strutil.AnyToString(result, "", 0), // formatted like output of cli
+		"raw":    redis2.NormalizeReply(result),
+	}
+	return
+}
+
 // RenameKey rename key
 func (b *browserService) RenameKey(server string, db int, key, newKey string) (resp types.JSResp) {
 	item, err := b.getRedisClient(server, db)
@@ -2625,6 +7203,259 @@ func (b *browserService) RenameKey(server string, db int, key, newKey string) (r
 	return
 }
 
+// BatchRenameByPrefix rename all keys under a prefix to another prefix by "RENAMENX" in batches,
+// or duplicate keys by "COPY" without replacing if copyMode is true
+// progress is pushed by event "renaming:<serialNo>" and can be canceled by "renaming:stop:<serialNo>"
+// keys whose target name already exists are reported as collisions
+func (b *browserService) BatchRenameByPrefix(server string, db int, prefix, newPrefix string, copyMode bool, serialNo string) (resp types.JSResp) {
+	if len(prefix) <= 0 || prefix == newPrefix {
+		resp.Msg = "invalid prefix"
+		return
+	}
+	if strings.HasPrefix(newPrefix, prefix) {
+		// renamed keys would be scanned again
+		resp.Msg = "new prefix should not start with the old prefix"
+		return
+	}
+	item, err := b.getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	client := item.client
+	if _, ok := client.(*redis.ClusterClient); ok {
+		resp.Msg = "RENAME not support in cluster mode yet"
+		return
+	}
+	ctx, cancelFunc := context.WithCancel(b.ctx)
+	defer cancelFunc()
+
+	cancelStopEvent := runtime.EventsOnce(ctx, "renaming:stop:"+serialNo, func(data ...any) {
+		cancelFunc()
+	})
+	processEvent := "renaming:" + serialNo
+	job := Jobs().newJob("rename", "rename keys with prefix "+prefix, server, db, cancelFunc)
+	const maxCollisions = 100
+	var renamed, failed, collided int64
+	collisions := make([]any, 0)
+	startTime := time.Now().Add(-10 * time.Second)
+	err = b.scanKeysInBatch(ctx, client, strutil.EscapeGlob(prefix)+"*", "", 500, func(ctx context.Context, cli redis.UniversalClient, keys []string) error {
+		pipe := cli.Pipeline()
+		for _, key := range keys {
+			newKey := newPrefix + strings.TrimPrefix(key, prefix)
+			if copyMode {
+				pipe.Copy(ctx, key, newKey, db, false)
+			} else {
+				pipe.RenameNX(ctx, key, newKey)
+			}
+		}
+		cmders, renameErr := pipe.Exec(ctx)
+		if errors.Is(renameErr, context.Canceled) {
+			return renameErr
+		}
+		for i, cmder := range cmders {
+			var ok bool
+			switch c := cmder.(type) {
+			case *redis.BoolCmd:
+				ok = c.Val()
+			case *redis.IntCmd:
+				ok = c.Val() == 1
+			}
+			if cmder.Err() != nil {
+				failed += 1
+			} else if ok {
+				renamed += 1
+			} else {
+				collided += 1
+				if len(collisions) < maxCollisions {
+					collisions = append(collisions, strutil.EncodeRedisKey(keys[i]))
+				}
+			}
+		}
+		job.update(renamed+collided+failed, 0)
+		if time.Now().Sub(startTime).Milliseconds() > 100 {
+			startTime = time.Now()
+			runtime.EventsEmit(ctx, processEvent, map[string]any{
+				"renamed":    renamed,
+				"collided":   collided,
+				"failed":     failed,
+				"processing": keys[len(keys)-1],
+			})
+		}
+		return nil
+	})
+	cancelStopEvent()
+	job.finish(false, err)
+	canceled := errors.Is(err, context.Canceled)
+	if err != nil && !canceled {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"canceled":   canceled,
+		"renamed":    renamed,
+		"failed":     failed,
+		"collided":   collided,
+		"collisions": collisions, // only the first 100 collided keys
+	}
+	return
+}
+
+// TransferKeys copy or move keys to another database or connection with ttl preserved
+// keys are copied by "COPY" within the same instance, otherwise by "DUMP" and "RESTORE"
+// progress is pushed by event "transfer:<serialNo>" and can be canceled by "transfer:stop:<serialNo>"
+func (b *browserService) TransferKeys(param types.TransferKeysParam) (resp types.JSResp) {
+	targetServer := param.TargetServer
+	if len(targetServer) <= 0 {
+		targetServer = param.Server
+	}
+	sameServer := targetServer == param.Server
+	if sameServer && param.TargetDB == param.DB {
+		resp.Msg = "source and target database are the same"
+		return
+	}
+	item, err := b.getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	client := item.client
+	if _, ok := client.(*redis.ClusterClient); ok && sameServer {
+		resp.Msg = "only database 0 is available in cluster mode"
+		return
+	}
+
+	// use a dedicated client for target, so that the opened browser of target is not affected
+	var target redis.UniversalClient
+	if !sameServer {
+		conf := Connection().getConnection(targetServer)
+		if conf == nil {
+			resp.Msg = fmt.Sprintf("no match connection \"%s\"", targetServer)
+			return
+		}
+		connConfig := conf.ConnectionConfig
+		connConfig.LastDB = param.TargetDB
+		if target, err = Connection().createRedisClient(connConfig); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		defer target.Close()
+	}
+
+	ctx, cancelFunc := context.WithCancel(b.ctx)
+	defer cancelFunc()
+	cancelStopEvent := runtime.EventsOnce(ctx, "transfer:stop:"+param.SerialNo, func(data ...any) {
+		cancelFunc()
+	})
+	processEvent := "transfer:" + param.SerialNo
+	const batchSize = 100
+	total := len(param.Keys)
+	job := Jobs().newJob("transfer", fmt.Sprintf("transfer %d keys to %s db%d", total, targetServer, param.TargetDB), param.Server, param.DB, cancelFunc)
+	var transferred, skipped, failed int64
+	var canceled bool
+	var lastErr error
+	for i := 0; i < total; i += batchSize {
+		job.update(int64(i), int64(total))
+		runtime.EventsEmit(ctx, processEvent, map[string]any{
+			"total":      total,
+			"progress":   i,
+			"processing": param.Keys[i],
+		})
+
+		keys := sliceutil.Map(param.Keys[i:min(i+batchSize, total)], func(j int) string {
+			return strutil.DecodeRedisKey(param.Keys[i+j])
+		})
+		// result of each key, nil for skipped
+		results := make([]error, len(keys))
+		var batchErr error
+		if sameServer {
+			pipe := client.Pipeline()
+			for _, key := range keys {
+				pipe.Copy(ctx, key, key, param.TargetDB, param.Replace)
+			}
+			var cmders []redis.Cmder
+			cmders, batchErr = pipe.Exec(ctx)
+			for j, cmder := range cmders {
+				if cmder.Err() != nil {
+					results[j] = cmder.Err()
+				} else if cmder.(*redis.IntCmd).Val() != 1 {
+					results[j] = redis.Nil
+				}
+			}
+		} else {
+			pipe := client.Pipeline()
+			dumpCmds := make([]*redis.StringCmd, len(keys))
+			ttlCmds := make([]*redis.DurationCmd, len(keys))
+			for j, key := range keys {
+				dumpCmds[j] = pipe.Dump(ctx, key)
+				ttlCmds[j] = pipe.PTTL(ctx, key)
+			}
+			_, batchErr = pipe.Exec(ctx)
+			if !errors.Is(batchErr, context.Canceled) {
+				targetPipe := target.Pipeline()
+				restoreCmds := make([]*redis.StatusCmd, len(keys))
+				for j, key := range keys {
+					if results[j] = dumpCmds[j].Err(); results[j] != nil {
+						continue
+					}
+					ttl := max(ttlCmds[j].Val(), 0)
+					if param.Replace {
+						restoreCmds[j] = targetPipe.RestoreReplace(ctx, key, ttl, dumpCmds[j].Val())
+					} else {
+						restoreCmds[j] = targetPipe.Restore(ctx, key, ttl, dumpCmds[j].Val())
+					}
+				}
+				_, batchErr = targetPipe.Exec(ctx)
+				for j, cmd := range restoreCmds {
+					if cmd == nil {
+						continue
+					}
+					if results[j] = cmd.Err(); results[j] != nil && strings.HasPrefix(results[j].Error(), "BUSYKEY") {
+						// target key exists
+						results[j] = redis.Nil
+					}
+				}
+			}
+		}
+		if errors.Is(batchErr, context.Canceled) || ctx.Err() != nil {
+			canceled = true
+			break
+		}
+
+		delPipe := client.Pipeline()
+		for j, result := range results {
+			if errors.Is(result, redis.Nil) {
+				skipped += 1
+			} else if result != nil {
+				failed += 1
+				lastErr = result
+				job.log("transfer key \"%s\" fail: %s", keys[j], result.Error())
+			} else {
+				transferred += 1
+				if param.Move {
+					delPipe.Del(ctx, keys[j])
+				}
+			}
+		}
+		if param.Move && delPipe.Len() > 0 {
+			_, _ = delPipe.Exec(ctx)
+		}
+	}
+	cancelStopEvent()
+	job.finish(canceled, jobFailure(failed, lastErr))
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"canceled":    canceled,
+		"transferred": transferred,
+		"skipped":     skipped,
+		"failed":      failed,
+	}
+	return
+}
+
 // GetCmdHistory get redis command history
 func (b *browserService) GetCmdHistory(pageNo, pageSize int) (resp types.JSResp) {
 	resp.Success = true
@@ -2670,7 +7501,7 @@ func (b *browserService) GetSlowLogs(server string, num int64) (resp types.JSRes
 		// cluster mode
 		var mu sync.Mutex
 		err = cluster.ForEachShard(ctx, func(ctx context.Context, cli *redis.Client) error {
-			if subLogs, _ := client.SlowLogGet(ctx, num).Result(); len(subLogs) > 0 {
+			if subLogs, _ := cli.SlowLogGet(ctx, num).Result(); len(subLogs) > 0 {
 				mu.Lock()
 				logs = append(logs, subLogs...)
 				mu.Unlock()
@@ -0,0 +1,502 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"tinyrdm/backend/types"
+	redis2 "tinyrdm/backend/utils/redis"
+)
+
+type clusterService struct {
+	ctx      context.Context
+	confirms map[string]clusterConfirm // pending confirmation of dangerous operations, key is token
+	mutex    sync.Mutex
+}
+
+type clusterConfirm struct {
+	operation string // signature of operation and its arguments
+	expireAt  time.Time
+}
+
+// named clusterMgr to avoid shadowing by the common local name "cluster"
+var clusterMgr *clusterService
+var onceClusterMgr sync.Once
+
+func Cluster() *clusterService {
+	if clusterMgr == nil {
+		onceClusterMgr.Do(func() {
+			clusterMgr = &clusterService{
+				confirms: map[string]clusterConfirm{},
+			}
+		})
+	}
+	return clusterMgr
+}
+
+func (c *clusterService) Start(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// get cluster client of server
+func (c *clusterService) getClusterClient(server string) (*redis.ClusterClient, context.Context, error) {
+	item, err := Browser().getRedisClient(server, -1)
+	if err != nil {
+		return nil, nil, err
+	}
+	clusterClient, ok := item.client.(*redis.ClusterClient)
+	if !ok {
+		return nil, nil, errors.New("not a cluster connection")
+	}
+	return clusterClient, item.ctx, nil
+}
+
+// get all nodes of cluster
+func (c *clusterService) getNodes(ctx context.Context, client *redis.ClusterClient) ([]types.ClusterNode, error) {
+	res, err := client.ClusterNodes(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+	return redis2.ParseClusterNodes(res), nil
+}
+
+// get client of single node by address
+func (c *clusterService) getNodeClient(ctx context.Context, client *redis.ClusterClient, addr string) (*redis.Client, error) {
+	var nodeClient *redis.Client
+	var mutex sync.Mutex
+	err := client.ForEachShard(ctx, func(ctx context.Context, cli *redis.Client) error {
+		if cli.Options().Addr == addr {
+			mutex.Lock()
+			nodeClient = cli
+			mutex.Unlock()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if nodeClient == nil {
+		return nil, fmt.Errorf("node \"%s\" not found", addr)
+	}
+	return nodeClient, nil
+}
+
+func findNode(nodes []types.ClusterNode, id string) (types.ClusterNode, bool) {
+	for _, node := range nodes {
+		if node.ID == id {
+			return node, true
+		}
+	}
+	return types.ClusterNode{}, false
+}
+
+// GetClusterNodes get all nodes and slot distribution of cluster
+func (c *clusterService) GetClusterNodes(server string) (resp types.JSResp) {
+	client, ctx, err := c.getClusterClient(server)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	nodes, err := c.getNodes(ctx, client)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	var assigned int
+	for _, node := range nodes {
+		assigned += node.SlotCount
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"nodes":      nodes,
+		"assigned":   assigned,
+		"unassigned": redis2.ClusterSlotCount - assigned,
+	}
+	return
+}
+
+// AddSlots assign unassigned slots to master node
+func (c *clusterService) AddSlots(server, nodeID string, ranges []types.ClusterSlotRange) (resp types.JSResp) {
+	client, ctx, err := c.getClusterClient(server)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	nodes, err := c.getNodes(ctx, client)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	node, ok := findNode(nodes, nodeID)
+	if !ok || node.Role != "master" {
+		resp.Msg = "master node not found"
+		return
+	}
+	nodeClient, err := c.getNodeClient(ctx, client, node.Addr)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	for _, r := range ranges {
+		if r.Start < 0 || r.End >= redis2.ClusterSlotCount || r.Start > r.End {
+			resp.Msg = fmt.Sprintf("invalid slot range %d-%d", r.Start, r.End)
+			return
+		}
+		if err = nodeClient.ClusterAddSlotsRange(ctx, r.Start, r.End).Err(); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+	}
+	resp.Success = true
+	return
+}
+
+// MigrateSlots move slots with all keys from source node to target node
+// progress is emitted by event "reshard:<serialNo>", and can be stopped by event "reshard:stop:<serialNo>"
+func (c *clusterService) MigrateSlots(server string, move types.ClusterSlotMove, serialNo string) (resp types.JSResp) {
+	return c.runSlotMoves(server, []types.ClusterSlotMove{move}, serialNo)
+}
+
+// RebalanceSlots distribute slots evenly among all master nodes
+// progress is emitted by event "reshard:<serialNo>", and can be stopped by event "reshard:stop:<serialNo>"
+func (c *clusterService) RebalanceSlots(server string, serialNo string) (resp types.JSResp) {
+	client, ctx, err := c.getClusterClient(server)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	nodes, err := c.getNodes(ctx, client)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	moves := redis2.PlanRebalance(nodes)
+	if len(moves) <= 0 {
+		resp.Success = true
+		resp.Data = map[string]any{
+			"moved": 0,
+		}
+		return
+	}
+	return c.runSlotMoves(server, moves, serialNo)
+}
+
+func (c *clusterService) runSlotMoves(server string, moves []types.ClusterSlotMove, serialNo string) (resp types.JSResp) {
+	client, itemCtx, err := c.getClusterClient(server)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	ctx, cancelFunc := context.WithCancel(itemCtx)
+	defer cancelFunc()
+
+	cancelEvent := "reshard:stop:" + serialNo
+	cancelStopEvent := runtime.EventsOnce(ctx, cancelEvent, func(data ...any) {
+		cancelFunc()
+	})
+	defer cancelStopEvent()
+	processEvent := "reshard:" + serialNo
+
+	nodes, err := c.getNodes(ctx, client)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	var total, moved int
+	for _, move := range moves {
+		total += len(move.Slots)
+	}
+	var canceled bool
+	for _, move := range moves {
+		source, ok := findNode(nodes, move.SourceID)
+		if !ok {
+			err = fmt.Errorf("source node \"%s\" not found", move.SourceID)
+			break
+		}
+		target, ok := findNode(nodes, move.TargetID)
+		if !ok {
+			err = fmt.Errorf("target node \"%s\" not found", move.TargetID)
+			break
+		}
+		for _, slot := range move.Slots {
+			if err = c.migrateSlot(ctx, client, nodes, source, target, slot); err != nil {
+				break
+			}
+			moved += 1
+			runtime.EventsEmit(c.ctx, processEvent, map[string]any{
+				"slot":      slot,
+				"source":    source.ID,
+				"target":    target.ID,
+				"processed": moved,
+				"total":     total,
+			})
+		}
+		if err != nil {
+			break
+		}
+	}
+	if errors.Is(err, context.Canceled) {
+		canceled, err = true, nil
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"moved":    moved,
+		"total":    total,
+		"canceled": canceled,
+	}
+	return
+}
+
+// migrateSlot migrate one slot by the steps like "redis-cli --cluster reshard"
+func (c *clusterService) migrateSlot(ctx context.Context, client *redis.ClusterClient, nodes []types.ClusterNode,
+	source, target types.ClusterNode, slot int) error {
+	const batchSize = 100
+	const migrateTimeout = 10 * time.Second
+
+	sourceClient, err := c.getNodeClient(ctx, client, source.Addr)
+	if err != nil {
+		return err
+	}
+	targetClient, err := c.getNodeClient(ctx, client, target.Addr)
+	if err != nil {
+		return err
+	}
+
+	// 1. mark slot importing in target and migrating in source
+	if err = targetClient.Do(ctx, "CLUSTER", "SETSLOT", slot, "IMPORTING", source.ID).Err(); err != nil {
+		return err
+	}
+	if err = sourceClient.Do(ctx, "CLUSTER", "SETSLOT", slot, "MIGRATING", target.ID).Err(); err != nil {
+		return err
+	}
+
+	// 2. move keys in batch
+	host, port, err := net.SplitHostPort(target.Addr)
+	if err != nil {
+		return err
+	}
+	opt := sourceClient.Options()
+	for {
+		keys, err := sourceClient.ClusterGetKeysInSlot(ctx, slot, batchSize).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) <= 0 {
+			break
+		}
+		args := []any{"MIGRATE", host, port, "", 0, migrateTimeout.Milliseconds(), "REPLACE"}
+		if len(opt.Password) > 0 {
+			if len(opt.Username) > 0 {
+				args = append(args, "AUTH2", opt.Username, opt.Password)
+			} else {
+				args = append(args, "AUTH", opt.Password)
+			}
+		}
+		args = append(args, "KEYS")
+		for _, key := range keys {
+			args = append(args, key)
+		}
+		if err = sourceClient.Do(ctx, args...).Err(); err != nil && !strings.Contains(err.Error(), "NOKEY") {
+			return fmt.Errorf("migrate keys of slot %d fail: %s", slot, err.Error())
+		}
+	}
+
+	// 3. assign slot to target in all masters
+	if err = targetClient.Do(ctx, "CLUSTER", "SETSLOT", slot, "NODE", target.ID).Err(); err != nil {
+		return err
+	}
+	if err = sourceClient.Do(ctx, "CLUSTER", "SETSLOT", slot, "NODE", target.ID).Err(); err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if node.Role != "master" || node.ID == source.ID || node.ID == target.ID {
+			continue
+		}
+		if nodeClient, err := c.getNodeClient(ctx, client, node.Addr); err == nil {
+			_ = nodeClient.Do(ctx, "CLUSTER", "SETSLOT", slot, "NODE", target.ID).Err()
+		}
+	}
+	return nil
+}
+
+// confirm check the confirmation token of operation
+// a new token will be generated if token is empty or mismatched, and the operation should be called again with it
+func (c *clusterService) confirm(operation, token string) (newToken string, confirmed bool) {
+	const confirmExpire = 60 * time.Second
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	for t, item := range c.confirms {
+		if now.After(item.expireAt) {
+			delete(c.confirms, t)
+		}
+	}
+	if item, ok := c.confirms[token]; ok && item.operation == operation {
+		// token can be used only once
+		delete(c.confirms, token)
+		return "", true
+	}
+
+	newToken = uuid.NewString()
+	c.confirms[newToken] = clusterConfirm{
+		operation: operation,
+		expireAt:  now.Add(confirmExpire),
+	}
+	return newToken, false
+}
+
+// response of unconfirmed operation
+func (c *clusterService) confirmResp(token, summary string) (resp types.JSResp) {
+	resp.Success = true
+	resp.Data = map[string]any{
+		"confirmed":    false,
+		"confirmToken": token,
+		"summary":      summary,
+	}
+	return
+}
+
+// MeetNode add a new node into cluster
+// @param token confirmation token, return a new token to confirm if empty
+func (c *clusterService) MeetNode(server, addr, token string) (resp types.JSResp) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if newToken, ok := c.confirm(strings.Join([]string{"meet", server, addr}, "|"), token); !ok {
+		return c.confirmResp(newToken, fmt.Sprintf("node %s will join the cluster", addr))
+	}
+
+	client, ctx, err := c.getClusterClient(server)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if err = client.ClusterMeet(ctx, host, port).Err(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"confirmed": true,
+	}
+	return
+}
+
+// ForgetNode remove a node from cluster, the node should not hold any slot
+// @param token confirmation token, return a new token to confirm if empty
+func (c *clusterService) ForgetNode(server, nodeID, token string) (resp types.JSResp) {
+	client, ctx, err := c.getClusterClient(server)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	nodes, err := c.getNodes(ctx, client)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	node, ok := findNode(nodes, nodeID)
+	if !ok {
+		resp.Msg = "node not found"
+		return
+	}
+	if node.SlotCount > 0 {
+		resp.Msg = "node still holds slots, migrate them before removing"
+		return
+	}
+	if newToken, ok := c.confirm(strings.Join([]string{"forget", server, nodeID}, "|"), token); !ok {
+		return c.confirmResp(newToken, fmt.Sprintf("node %s (%s) will be removed from the cluster", node.Addr, node.ID))
+	}
+
+	// all other nodes should forget it, otherwise it will be added back by gossip
+	skipped := []string{}
+	for _, n := range nodes {
+		if n.ID == nodeID {
+			continue
+		}
+		var nodeClient *redis.Client
+		if nodeClient, err = c.getNodeClient(ctx, client, n.Addr); err != nil {
+			// node not reachable by client, need to forget manually
+			skipped = append(skipped, n.Addr)
+			continue
+		}
+		if err = nodeClient.ClusterForget(ctx, nodeID).Err(); err != nil {
+			resp.Msg = fmt.Sprintf("forget node in %s fail: %s", n.Addr, err.Error())
+			return
+		}
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"confirmed": true,
+		"skipped":   skipped,
+	}
+	return
+}
+
+// FailoverNode promote the replica node to master by manual failover
+// @param mode empty for normal failover, "force" or "takeover"
+// @param token confirmation token, return a new token to confirm if empty
+func (c *clusterService) FailoverNode(server, nodeID, mode, token string) (resp types.JSResp) {
+	mode = strings.ToUpper(mode)
+	if len(mode) > 0 && mode != "FORCE" && mode != "TAKEOVER" {
+		resp.Msg = "invalid failover mode"
+		return
+	}
+	client, ctx, err := c.getClusterClient(server)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	nodes, err := c.getNodes(ctx, client)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	node, ok := findNode(nodes, nodeID)
+	if !ok || node.Role != "replica" {
+		resp.Msg = "replica node not found"
+		return
+	}
+	if newToken, ok := c.confirm(strings.Join([]string{"failover", server, nodeID, mode}, "|"), token); !ok {
+		return c.confirmResp(newToken, fmt.Sprintf("replica %s (%s) will be promoted to master", node.Addr, node.ID))
+	}
+
+	nodeClient, err := c.getNodeClient(ctx, client, node.Addr)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	args := []any{"CLUSTER", "FAILOVER"}
+	if len(mode) > 0 {
+		args = append(args, mode)
+	}
+	if err = nodeClient.Do(ctx, args...).Err(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"confirmed": true,
+	}
+	return
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"fmt"
 	"github.com/klauspost/compress/zip"
 	"github.com/redis/go-redis/v9"
 	"github.com/vrischmann/userdir"
@@ -16,13 +20,21 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"tinyrdm/backend/consts"
 	. "tinyrdm/backend/storage"
 	"tinyrdm/backend/types"
+	cryptoutil "tinyrdm/backend/utils/crypto"
 	_ "tinyrdm/backend/utils/proxy"
+	redis2 "tinyrdm/backend/utils/redis"
+	sliceutil "tinyrdm/backend/utils/slice"
+	sshutil "tinyrdm/backend/utils/ssh"
+	strutil "tinyrdm/backend/utils/string"
+	"unicode"
 )
 
 type cmdHistoryItem struct {
@@ -33,8 +45,19 @@ type cmdHistoryItem struct {
 }
 
 type connectionService struct {
-	ctx   context.Context
-	conns *ConnectionsStorage
+	ctx        context.Context
+	conns      *ConnectionsStorage
+	states     *ConnectionStatesStorage
+	replicas   map[string]replicaTarget // target node of replica sessions
+	replicaMux sync.Mutex
+	temps      map[string]types.ConnectionConfig // unsaved connections opened by quick connect
+	tempMux    sync.Mutex
+}
+
+type replicaTarget struct {
+	addr     string
+	port     int
+	readonly bool
 }
 
 var connection *connectionService
@@ -44,7 +67,10 @@ func Connection() *connectionService {
 	if connection == nil {
 		onceConnection.Do(func() {
 			connection = &connectionService{
-				conns: NewConnections(),
+				conns:    NewConnections(),
+				states:   NewConnectionStates(),
+				replicas: map[string]replicaTarget{},
+				temps:    map[string]types.ConnectionConfig{},
 			}
 		})
 	}
@@ -55,62 +81,45 @@ func (c *connectionService) Start(ctx context.Context) {
 	c.ctx = ctx
 }
 
-func (c *connectionService) buildOption(config types.ConnectionConfig) (*redis.Options, error) {
-	var dialer proxy.Dialer
-	var dialerErr error
-	if config.Proxy.Type == 1 {
-		// use system proxy
-		dialer = proxy.FromEnvironment()
-	} else if config.Proxy.Type == 2 {
-		// use custom proxy
-		proxyUrl := url.URL{
-			Host: net.JoinHostPort(config.Proxy.Addr, strconv.Itoa(config.Proxy.Port)),
-		}
-		if len(config.Proxy.Username) > 0 {
-			proxyUrl.User = url.UserPassword(config.Proxy.Username, config.Proxy.Password)
-		}
-		switch config.Proxy.Schema {
-		case "socks5", "socks5h", "http", "https":
-			proxyUrl.Scheme = config.Proxy.Schema
-		default:
-			proxyUrl.Scheme = "http"
-		}
-		if dialer, dialerErr = proxy.FromURL(&proxyUrl, proxy.Direct); dialerErr != nil {
-			return nil, dialerErr
-		}
+// resolveEnv replace ${ENV_VAR} placeholders in address, credential and tls path fields
+func (c *connectionService) resolveEnv(config types.ConnectionConfig) (types.ConnectionConfig, error) {
+	fields := []*string{
+		&config.Addr, &config.Sock, &config.Username, &config.Password,
+		&config.SSL.CertFile, &config.SSL.KeyFile, &config.SSL.CAFile, &config.SSL.KeyPassphrase, &config.SSL.SNI,
+		&config.Sentinel.Username, &config.Sentinel.Password,
+		&config.Proxy.Addr, &config.Proxy.Username, &config.Proxy.Password,
+	}
+	config.Sentinel.Addrs = slices.Clone(config.Sentinel.Addrs)
+	for i := range config.Sentinel.Addrs {
+		fields = append(fields, &config.Sentinel.Addrs[i])
+	}
+	config.InitCommands = slices.Clone(config.InitCommands)
+	for i := range config.InitCommands {
+		fields = append(fields, &config.InitCommands[i])
+	}
+	config.SSH.JumpHosts = slices.Clone(config.SSH.JumpHosts)
+	hosts := []*types.ConnectionSSHHost{&config.SSH.ConnectionSSHHost}
+	for i := range config.SSH.JumpHosts {
+		hosts = append(hosts, &config.SSH.JumpHosts[i])
+	}
+	for _, host := range hosts {
+		fields = append(fields, &host.Addr, &host.Username, &host.Password, &host.PKFile, &host.Passphrase)
 	}
 
-	var sshConfig *ssh.ClientConfig
-	var sshAddr string
-	if config.SSH.Enable {
-		sshConfig = &ssh.ClientConfig{
-			User:            config.SSH.Username,
-			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-			Timeout:         time.Duration(config.ConnTimeout) * time.Second,
-		}
-		switch config.SSH.LoginType {
-		case "pwd":
-			sshConfig.Auth = []ssh.AuthMethod{ssh.Password(config.SSH.Password)}
-		case "pkfile":
-			key, err := os.ReadFile(config.SSH.PKFile)
-			if err != nil {
-				return nil, err
-			}
-			var signer ssh.Signer
-			if len(config.SSH.Passphrase) > 0 {
-				signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(config.SSH.Passphrase))
-			} else {
-				signer, err = ssh.ParsePrivateKey(key)
-			}
-			if err != nil {
-				return nil, err
-			}
-			sshConfig.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
-		default:
-			return nil, errors.New("invalid login type")
+	for _, field := range fields {
+		val, err := strutil.ExpandEnv(*field)
+		if err != nil {
+			return config, err
 		}
+		*field = val
+	}
+	return config, nil
+}
 
-		sshAddr = net.JoinHostPort(config.SSH.Addr, strconv.Itoa(config.SSH.Port))
+func (c *connectionService) buildOption(config types.ConnectionConfig) (*redis.Options, error) {
+	dialer, err := c.buildProxyDialer(config.Proxy)
+	if err != nil {
+		return nil, err
 	}
 
 	var tlsConfig *tls.Config
@@ -118,7 +127,7 @@ func (c *connectionService) buildOption(config types.ConnectionConfig) (*redis.O
 		// setup tls config
 		var certs []tls.Certificate
 		if len(config.SSL.CertFile) > 0 && len(config.SSL.KeyFile) > 0 {
-			if cert, err := tls.LoadX509KeyPair(config.SSL.CertFile, config.SSL.KeyFile); err != nil {
+			if cert, err := c.loadX509KeyPair(config.SSL.CertFile, config.SSL.KeyFile, config.SSL.KeyPassphrase); err != nil {
 				return nil, err
 			} else {
 				certs = []tls.Certificate{cert}
@@ -132,7 +141,9 @@ func (c *connectionService) buildOption(config types.ConnectionConfig) (*redis.O
 				return nil, err
 			}
 			caCertPool = x509.NewCertPool()
-			caCertPool.AppendCertsFromPEM(ca)
+			if !caCertPool.AppendCertsFromPEM(ca) {
+				return nil, errors.New("no valid certificate found in CA file")
+			}
 		}
 
 		tlsConfig = &tls.Config{
@@ -143,16 +154,63 @@ func (c *connectionService) buildOption(config types.ConnectionConfig) (*redis.O
 		}
 	}
 
+	readTimeout, writeTimeout := config.ExecTimeout, config.ExecTimeout
+	if config.ReadTimeout > 0 {
+		readTimeout = config.ReadTimeout
+	}
+	if config.WriteTimeout > 0 {
+		writeTimeout = config.WriteTimeout
+	}
 	option := &redis.Options{
 		Username:        config.Username,
 		Password:        config.Password,
 		DialTimeout:     time.Duration(config.ConnTimeout) * time.Second,
-		ReadTimeout:     time.Duration(config.ExecTimeout) * time.Second,
-		WriteTimeout:    time.Duration(config.ExecTimeout) * time.Second,
+		ReadTimeout:     time.Duration(readTimeout) * time.Second,
+		WriteTimeout:    time.Duration(writeTimeout) * time.Second,
 		ConnMaxIdleTime: 0,
 		TLSConfig:       tlsConfig,
 		DisableIdentity: true,
 		IdentitySuffix:  "tinyrdm_",
+		PoolSize:        config.PoolSize,
+		MinIdleConns:    config.MinIdleConns,
+		MaxIdleConns:    config.MaxIdleConns,
+		ConnMaxLifetime: time.Duration(config.ConnMaxLifetime) * time.Second,
+	}
+	if config.Protocol == 2 || config.Protocol == 3 {
+		option.Protocol = config.Protocol
+	}
+	var initCmds [][]any
+	for _, line := range config.InitCommands {
+		if cmd := strutil.SplitCmd(strings.TrimSpace(line)); len(cmd) > 0 && len(cmd[0]) > 0 {
+			if strings.EqualFold(cmd[0], "select") {
+				// database is managed by browser, switching it on connect would mismatch the selected one
+				return nil, errors.New("\"SELECT\" is not allowed in startup commands, open the database in browser instead")
+			}
+			initCmds = append(initCmds, sliceutil.Map(cmd, func(i int) any {
+				return cmd[i]
+			}))
+		}
+	}
+	if config.ReplicaReadOnly || len(initCmds) > 0 || len(config.ClientName) > 0 {
+		option.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+			if len(config.ClientName) > 0 {
+				// best effort, may be denied by acl or not supported by proxy
+				_ = cn.Do(ctx, "CLIENT", "SETNAME", config.ClientName).Err()
+			}
+			if config.ReplicaReadOnly {
+				// allow read commands on replica node of cluster
+				if err := cn.ReadOnly(ctx).Err(); err != nil {
+					return err
+				}
+			}
+			// run startup commands on every new connection
+			for _, args := range initCmds {
+				if err := cn.Do(ctx, args...).Err(); err != nil && !errors.Is(err, redis.Nil) {
+					return fmt.Errorf("startup command \"%s\" failed: %s", args[0], err.Error())
+				}
+			}
+			return nil
+		}
 	}
 	if config.Network == "unix" {
 		option.Network = "unix"
@@ -174,29 +232,23 @@ func (c *connectionService) buildOption(config types.ConnectionConfig) (*redis.O
 		}
 	}
 
-	if len(sshAddr) > 0 {
-		if dialer != nil {
-			// ssh with proxy
-			conn, err := dialer.Dial("tcp", sshAddr)
-			if err != nil {
-				return nil, err
-			}
-			sc, chans, reqs, err := ssh.NewClientConn(conn, sshAddr, sshConfig)
-			if err != nil {
-				return nil, err
-			}
-			dialer = ssh.NewClient(sc, chans, reqs)
-		} else {
-			// ssh without proxy
-			sshClient, err := ssh.Dial("tcp", sshAddr, sshConfig)
-			if err != nil {
-				return nil, err
-			}
-			dialer = sshClient
+	if dialer == nil && config.KeepAlive != 0 {
+		// direct connection with custom keepalive period
+		dialer = &net.Dialer{
+			Timeout:   option.DialTimeout,
+			KeepAlive: time.Duration(config.KeepAlive) * time.Second,
+		}
+	}
+	if config.SSH.Enable {
+		if dialer, err = c.buildSSHTunnel(config, dialer); err != nil {
+			return nil, err
 		}
 	}
 	if dialer != nil {
 		dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if ctxDialer, ok := dialer.(proxy.ContextDialer); ok {
+				return ctxDialer.DialContext(ctx, network, addr)
+			}
 			return dialer.Dial(network, addr)
 		}
 
@@ -205,11 +257,19 @@ func (c *connectionService) buildOption(config types.ConnectionConfig) (*redis.O
 			option.Dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
 				rawConn, err := dial(ctx, network, addr)
 				if err != nil {
-					rawConn.Close()
 					return nil, err
 				}
-				tlsConn := tls.Client(rawConn, tlsConfig)
-				if err = tlsConn.Handshake(); err != nil {
+				// verify certificate by dialing host if no SNI specified, same as the default dialer of go-redis
+				connConfig := tlsConfig.Clone()
+				if len(connConfig.ServerName) <= 0 {
+					if host, _, splitErr := net.SplitHostPort(addr); splitErr == nil {
+						connConfig.ServerName = host
+					} else {
+						connConfig.ServerName = addr
+					}
+				}
+				tlsConn := tls.Client(rawConn, connConfig)
+				if err = tlsConn.HandshakeContext(ctx); err != nil {
 					rawConn.Close()
 					return nil, err
 				}
@@ -218,45 +278,239 @@ func (c *connectionService) buildOption(config types.ConnectionConfig) (*redis.O
 		} else {
 			option.Dialer = dial
 		}
+	}
+	if config.SSH.Enable {
+		// deadline is not supported by ssh channel
 		option.ReadTimeout = -2
 		option.WriteTimeout = -2
 	}
 	return option, nil
 }
 
-func (c *connectionService) createRedisClient(config types.ConnectionConfig) (redis.UniversalClient, error) {
-	option, err := c.buildOption(config)
+// build ssh tunnel which dials through each jump host in order, then the ssh server
+// the forward dialer is used to reach the first host if provided
+func (c *connectionService) buildSSHTunnel(config types.ConnectionConfig, forward proxy.Dialer) (*sshutil.Tunnel, error) {
+	hosts, err := c.resolveSSHHosts(config.SSH)
 	if err != nil {
 		return nil, err
 	}
+	var tunnel *sshutil.Tunnel
+	for _, host := range hosts {
+		sshAddr, sshConfig, err := c.buildSSHConfig(host, time.Duration(config.ConnTimeout)*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		tunnel = sshutil.NewTunnel(sshAddr, sshConfig, forward)
+		forward = tunnel
+	}
+	if tunnel == nil {
+		return nil, errors.New("no ssh host")
+	}
+	return tunnel, nil
+}
 
-	if config.Sentinel.Enable {
-		// get master address via sentinel node
-		sentinel := redis.NewSentinelClient(option)
-		defer sentinel.Close()
+// load client certificate and private key from PEM files, the private key could be encrypted with passphrase
+func (c *connectionService) loadX509KeyPair(certFile, keyFile, passphrase string) (tls.Certificate, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	if len(passphrase) > 0 {
+		block, _ := pem.Decode(keyPEM)
+		if block == nil {
+			return tls.Certificate{}, errors.New("invalid private key file")
+		}
+		// legacy encrypted PEM, which is still generated by "openssl genrsa -aes256"
+		if x509.IsEncryptedPEMBlock(block) {
+			der, err := x509.DecryptPEMBlock(block, []byte(passphrase))
+			if err != nil {
+				return tls.Certificate{}, err
+			}
+			keyPEM = pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der})
+		}
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// build proxy dialer for connection
+// @return nil if connect directly
+func (c *connectionService) buildProxyDialer(proxyConfig types.ConnectionProxy) (proxy.Dialer, error) {
+	if proxyConfig.Type == types.PROXY_DEFAULT {
+		proxyConfig = Preferences().GetDefaultProxy()
+	}
+
+	switch proxyConfig.Type {
+	case types.PROXY_SYSTEM:
+		// use system proxy
+		return proxy.FromEnvironment(), nil
+	case types.PROXY_CUSTOM:
+		// use custom proxy
+		proxyUrl := url.URL{
+			Host: net.JoinHostPort(proxyConfig.Addr, strconv.Itoa(proxyConfig.Port)),
+		}
+		if len(proxyConfig.Username) > 0 {
+			proxyUrl.User = url.UserPassword(proxyConfig.Username, proxyConfig.Password)
+		}
+		switch proxyConfig.Schema {
+		case "socks5", "socks5h", "http", "https":
+			proxyUrl.Scheme = proxyConfig.Schema
+		default:
+			proxyUrl.Scheme = "http"
+		}
+		return proxy.FromURL(&proxyUrl, proxy.Direct)
+	default:
+		return nil, nil
+	}
+}
 
-		var addr []string
-		addr, err = sentinel.GetMasterAddrByName(c.ctx, config.Sentinel.Master).Result()
+// get ssh hosts to dial in order, the last one is the ssh server.
+// if "UseConfig" is enabled, missing options are read from ~/.ssh/config,
+// and jump hosts are taken from "ProxyJump" when not specified
+func (c *connectionService) resolveSSHHosts(sshOpt types.ConnectionSSH) ([]types.ConnectionSSHHost, error) {
+	if !sshOpt.UseConfig {
+		return append(slices.Clone(sshOpt.JumpHosts), sshOpt.ConnectionSSHHost), nil
+	}
+
+	applyConfig := func(host *types.ConnectionSSHHost) ([]string, error) {
+		cfg, _, err := sshutil.LookupHostConfig(host.Addr)
 		if err != nil {
 			return nil, err
 		}
-		if len(addr) < 2 {
-			return nil, errors.New("cannot get master address")
+		host.Addr = cfg.HostName
+		if host.Port <= 0 {
+			host.Port = cfg.Port
+		}
+		if host.Port <= 0 {
+			host.Port = 22
+		}
+		if len(host.Username) <= 0 {
+			host.Username = cfg.User
+		}
+		if len(host.LoginType) <= 0 {
+			// prefer identity file in config, then ssh-agent
+			host.LoginType = "agent"
+			for _, file := range cfg.IdentityFiles {
+				if _, err = os.Stat(file); err == nil {
+					host.LoginType, host.PKFile = "pkfile", file
+					break
+				}
+			}
+		}
+		return cfg.ProxyJump, nil
+	}
+
+	server := sshOpt.ConnectionSSHHost
+	proxyJump, err := applyConfig(&server)
+	if err != nil {
+		return nil, err
+	}
+	jumpHosts := slices.Clone(sshOpt.JumpHosts)
+	if len(jumpHosts) <= 0 {
+		// nested "ProxyJump" of jump hosts is not followed
+		for _, spec := range proxyJump {
+			var host types.ConnectionSSHHost
+			host.Username, host.Addr, host.Port = sshutil.ParseJumpHost(spec)
+			if _, err = applyConfig(&host); err != nil {
+				return nil, err
+			}
+			jumpHosts = append(jumpHosts, host)
+		}
+	}
+	return append(jumpHosts, server), nil
+}
+
+// build ssh client config of a ssh host
+// @return ssh server address
+func (c *connectionService) buildSSHConfig(host types.ConnectionSSHHost, timeout time.Duration) (string, *ssh.ClientConfig, error) {
+	hostKeyCallback, err := sshutil.HostKeyCallback(host.HostKeyPolicy, host.HostKey)
+	if err != nil {
+		return "", nil, err
+	}
+	sshConfig := &ssh.ClientConfig{
+		User:            host.Username,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+	}
+	switch host.LoginType {
+	case "pwd":
+		sshConfig.Auth = []ssh.AuthMethod{ssh.Password(host.Password)}
+	case "pkfile":
+		key, err := os.ReadFile(host.PKFile)
+		if err != nil {
+			return "", nil, err
+		}
+		var signer ssh.Signer
+		if len(host.Passphrase) > 0 {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(host.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(key)
 		}
-		option.Addr = net.JoinHostPort(addr[0], addr[1])
-		option.Username = config.Sentinel.Username
-		option.Password = config.Sentinel.Password
-		if option.Dialer != nil {
-			option.ReadTimeout = -2
-			option.WriteTimeout = -2
+		if err != nil {
+			return "", nil, err
 		}
+		sshConfig.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
+	case "agent":
+		sshConfig.Auth = []ssh.AuthMethod{sshutil.AgentAuth()}
+	default:
+		return "", nil, errors.New("invalid login type")
+	}
+
+	return net.JoinHostPort(host.Addr, strconv.Itoa(host.Port)), sshConfig, nil
+}
+
+func (c *connectionService) createRedisClient(config types.ConnectionConfig) (redis.UniversalClient, error) {
+	config, err := c.resolveEnv(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.ClientName) <= 0 {
+		config.ClientName = c.clientName(config.Name)
+	}
+	option, err := c.buildOption(config)
+	if err != nil {
+		return nil, err
 	}
 
 	if config.LastDB > 0 {
 		option.DB = config.LastDB
 	}
 
-	rdb := redis.NewClient(option)
+	var rdb *redis.Client
+	if config.Sentinel.Enable {
+		// connect to current master via sentinel nodes, the failover client
+		// will follow the new master automatically after a failover
+		failoverOptions := &redis.FailoverOptions{
+			MasterName:       config.Sentinel.Master,
+			SentinelAddrs:    c.sentinelAddrs(config, option.Addr),
+			SentinelUsername: option.Username,
+			SentinelPassword: option.Password,
+			Dialer:           option.Dialer,
+			OnConnect:        option.OnConnect,
+			Protocol:         option.Protocol,
+			Username:         config.Sentinel.Username,
+			Password:         config.Sentinel.Password,
+			DB:               option.DB,
+			DialTimeout:      option.DialTimeout,
+			ReadTimeout:      option.ReadTimeout,
+			WriteTimeout:     option.WriteTimeout,
+			ConnMaxIdleTime:  option.ConnMaxIdleTime,
+			PoolSize:         option.PoolSize,
+			MinIdleConns:     option.MinIdleConns,
+			MaxIdleConns:     option.MaxIdleConns,
+			ConnMaxLifetime:  option.ConnMaxLifetime,
+			TLSConfig:        option.TLSConfig,
+			DisableIdentity:  option.DisableIdentity,
+			IdentitySuffix:   option.IdentitySuffix,
+		}
+		rdb = redis.NewFailoverClient(failoverOptions)
+	} else {
+		rdb = redis.NewClient(option)
+	}
 	if config.Cluster.Enable {
 		defer rdb.Close()
 
@@ -278,6 +532,8 @@ func (c *connectionService) createRedisClient(config types.ConnectionConfig) (re
 				MinRetryBackoff:       option.MinRetryBackoff,
 				MaxRetryBackoff:       option.MaxRetryBackoff,
 				DialTimeout:           option.DialTimeout,
+				ReadTimeout:           option.ReadTimeout,
+				WriteTimeout:          option.WriteTimeout,
 				ContextTimeoutEnabled: option.ContextTimeoutEnabled,
 				PoolFIFO:              option.PoolFIFO,
 				PoolSize:              option.PoolSize,
@@ -288,11 +544,7 @@ func (c *connectionService) createRedisClient(config types.ConnectionConfig) (re
 				ConnMaxLifetime:       option.ConnMaxLifetime,
 				TLSConfig:             option.TLSConfig,
 				DisableIdentity:       option.DisableIdentity,
-			}
-			if option.Dialer != nil {
-				clusterOptions.Dialer = option.Dialer
-				clusterOptions.ReadTimeout = -2
-				clusterOptions.WriteTimeout = -2
+				ReadOnly:              config.ReadFromReplica,
 			}
 			var addrs []string
 			for _, slot := range slots {
@@ -300,7 +552,22 @@ func (c *connectionService) createRedisClient(config types.ConnectionConfig) (re
 					addrs = append(addrs, node.Addr)
 				}
 			}
-			clusterOptions.Addrs = addrs
+			// nodes may serve multiple slot ranges, keep each address only once
+			clusterOptions.Addrs = sliceutil.Unique(addrs)
+			// hooks of cluster client are not applied to node clients used by ForEachMaster/ForEachShard,
+			// install them to each node instead, commands of cluster client are processed by node clients too
+			auditHook := c.newAuditHook(config)
+			clusterOptions.NewClient = func(opt *redis.Options) *redis.Client {
+				node := redis.NewClient(opt)
+				if config.ReadOnly {
+					node.AddHook(redis2.NewReadOnlyHook())
+				}
+				if config.DisableFlush {
+					node.AddHook(redis2.NewNoFlushHook())
+				}
+				node.AddHook(auditHook)
+				return node
+			}
 			clusterClient := redis.NewClusterClient(clusterOptions)
 			return clusterClient, nil
 		} else {
@@ -308,11 +575,63 @@ func (c *connectionService) createRedisClient(config types.ConnectionConfig) (re
 		}
 	}
 
+	if config.ReadOnly {
+		rdb.AddHook(redis2.NewReadOnlyHook())
+	}
+	if config.DisableFlush {
+		rdb.AddHook(redis2.NewNoFlushHook())
+	}
+	rdb.AddHook(c.newAuditHook(config))
 	return rdb, nil
 }
 
+// generate client name by template in preferences
+// @param server connection name or session name, see sessionName
+func (c *connectionService) clientName(server string) string {
+	connName, session := splitSession(server)
+	if len(session) <= 0 {
+		session = "main"
+	}
+	hostname, _ := os.Hostname()
+	name := strings.NewReplacer(
+		"{hostname}", url.QueryEscape(hostname),
+		"{connection}", url.QueryEscape(connName),
+		"{session}", url.QueryEscape(session),
+	).Replace(Preferences().GetClientNameTemplate())
+	// spaces and control characters are not allowed in client name
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// create hook to record write commands into audit log
+func (c *connectionService) newAuditHook(config types.ConnectionConfig) *redis2.AuditHook {
+	return redis2.NewAuditHook(config.LastDB, func(db int, args []string, err error) {
+		Audit().record(config.Name, db, args, err)
+	})
+}
+
+// get all sentinel node addresses, the primary address comes first
+func (c *connectionService) sentinelAddrs(config types.ConnectionConfig, primary string) []string {
+	addrs := []string{primary}
+	for _, addr := range config.Sentinel.Addrs {
+		if addr = strings.TrimSpace(addr); len(addr) > 0 {
+			addrs = append(addrs, addr)
+		}
+	}
+	return sliceutil.Unique(addrs)
+}
+
 // ListSentinelMasters list all master info by sentinel
 func (c *connectionService) ListSentinelMasters(config types.ConnectionConfig) (resp types.JSResp) {
+	config, err := c.resolveEnv(config)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
 	option, err := c.buildOption(config)
 	if err != nil {
 		resp.Msg = err.Error()
@@ -345,6 +664,77 @@ func (c *connectionService) ListSentinelMasters(config types.ConnectionConfig) (
 	return
 }
 
+// GetSentinelTopology get master, replicas and sentinels discovered by sentinel
+func (c *connectionService) GetSentinelTopology(config types.ConnectionConfig) (resp types.JSResp) {
+	config, err := c.resolveEnv(config)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	option, err := c.buildOption(config)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	if option.DialTimeout > 0 {
+		option.DialTimeout = 10 * time.Second
+	}
+	masterName := config.Sentinel.Master
+	toNodes := func(infos []map[string]string) []types.SentinelNode {
+		return sliceutil.Map(infos, func(i int) types.SentinelNode {
+			return types.SentinelNode{
+				Name:  infos[i]["name"],
+				Addr:  net.JoinHostPort(infos[i]["ip"], infos[i]["port"]),
+				Flags: infos[i]["flags"],
+			}
+		})
+	}
+
+	// query the first available sentinel node
+	for _, addr := range c.sentinelAddrs(config, option.Addr) {
+		opt := *option
+		opt.Addr = addr
+		err = func() error {
+			sentinel := redis.NewSentinelClient(&opt)
+			defer sentinel.Close()
+
+			masterInfo, err := sentinel.Master(c.ctx, masterName).Result()
+			if err != nil {
+				return err
+			}
+			replicas, err := sentinel.Replicas(c.ctx, masterName).Result()
+			if err != nil {
+				return err
+			}
+			sentinels, err := sentinel.Sentinels(c.ctx, masterName).Result()
+			if err != nil {
+				return err
+			}
+
+			topology := types.SentinelTopology{
+				Master: types.SentinelNode{
+					Name:  masterInfo["name"],
+					Addr:  net.JoinHostPort(masterInfo["ip"], masterInfo["port"]),
+					Flags: masterInfo["flags"],
+				},
+				Replicas: toNodes(replicas),
+				// the queried sentinel is not included in its own "SENTINEL SENTINELS" reply
+				Sentinels: append([]types.SentinelNode{{Addr: addr, Flags: "sentinel"}}, toNodes(sentinels)...),
+			}
+			resp.Data = topology
+			return nil
+		}()
+		if err == nil {
+			resp.Success = true
+			return
+		}
+	}
+
+	resp.Msg = err.Error()
+	return
+}
+
 func (c *connectionService) TestConnection(config types.ConnectionConfig) (resp types.JSResp) {
 	client, err := c.createRedisClient(config)
 	if err != nil {
@@ -353,14 +743,109 @@ func (c *connectionService) TestConnection(config types.ConnectionConfig) (resp
 	}
 	defer client.Close()
 
+	// record the tls handshake result
+	var tlsState *types.ConnectionTLSState
+	var tlsConfig *tls.Config
+	switch cli := client.(type) {
+	case *redis.Client:
+		tlsConfig = cli.Options().TLSConfig
+	case *redis.ClusterClient:
+		tlsConfig = cli.Options().TLSConfig
+	}
+	if tlsConfig != nil {
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if tlsState == nil {
+				tlsState = c.parseTLSState(state)
+			}
+			return nil
+		}
+	}
+
 	if _, err = client.Ping(c.ctx).Result(); err != nil && !errors.Is(err, redis.Nil) {
 		resp.Msg = err.Error()
 	} else {
 		resp.Success = true
+		data := map[string]any{
+			"summary": c.getServerSummary(client, config),
+		}
+		if tlsState != nil {
+			data["tls"] = tlsState
+		}
+		resp.Data = data
 	}
 	return
 }
 
+// getServerSummary collect server version, mode, role, memory usage, database count and ping rtt
+func (c *connectionService) getServerSummary(client redis.UniversalClient, config types.ConnectionConfig) types.ConnectionSummary {
+	var summary types.ConnectionSummary
+	// measure rtt by several pings
+	const pingTimes = 5
+	var total time.Duration
+	var count int
+	for i := 0; i < pingTimes; i++ {
+		start := time.Now()
+		if err := client.Ping(c.ctx).Err(); err != nil {
+			break
+		}
+		cost := time.Since(start)
+		total += cost
+		count += 1
+		ms := float64(cost.Microseconds()) / 1000
+		if summary.RTTMin == 0 || ms < summary.RTTMin {
+			summary.RTTMin = ms
+		}
+		if ms > summary.RTTMax {
+			summary.RTTMax = ms
+		}
+	}
+	if count > 0 {
+		summary.RTT = float64(total.Microseconds()) / float64(count) / 1000
+	}
+
+	if res, err := client.Info(c.ctx, "server", "memory", "replication").Result(); err == nil {
+		info := Browser().parseInfo(res)
+		summary.Version = info["Server"]["redis_version"]
+		summary.Mode = info["Server"]["redis_mode"]
+		summary.Role = info["Replication"]["role"]
+		summary.UsedMemory, _ = strconv.ParseInt(info["Memory"]["used_memory"], 10, 64)
+	}
+	if config.Sentinel.Enable {
+		summary.Mode = "sentinel"
+	} else if _, isCluster := client.(*redis.ClusterClient); isCluster {
+		summary.Mode = "cluster"
+	} else if len(summary.Mode) <= 0 {
+		summary.Mode = "standalone"
+	}
+
+	if summary.Mode == "cluster" {
+		// only one database in cluster mode
+		summary.DBCount = 1
+	} else if res, err := client.ConfigGet(c.ctx, "databases").Result(); err == nil {
+		summary.DBCount, _ = strconv.Atoi(res["databases"])
+	}
+	return summary
+}
+
+func (c *connectionService) parseTLSState(state tls.ConnectionState) *types.ConnectionTLSState {
+	return &types.ConnectionTLSState{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+		PeerCerts: sliceutil.Map(state.PeerCertificates, func(i int) types.ConnectionTLSCert {
+			cert := state.PeerCertificates[i]
+			return types.ConnectionTLSCert{
+				Subject:   cert.Subject.String(),
+				Issuer:    cert.Issuer.String(),
+				Serial:    cert.SerialNumber.String(),
+				DNSNames:  cert.DNSNames,
+				NotBefore: cert.NotBefore.UnixMilli(),
+				NotAfter:  cert.NotAfter.UnixMilli(),
+			}
+		}),
+	}
+}
+
 // ListConnection list all saved connection in local profile
 func (c *connectionService) ListConnection() (resp types.JSResp) {
 	resp.Success = true
@@ -368,46 +853,244 @@ func (c *connectionService) ListConnection() (resp types.JSResp) {
 	return
 }
 
-func (c *connectionService) getConnection(name string) *types.Connection {
-	return c.conns.GetConnection(name)
+// FilterConnections list connections by environment label and tag, empty value means no limit
+func (c *connectionService) FilterConnections(environment, tag string) (resp types.JSResp) {
+	resp.Success = true
+	resp.Data = c.conns.FilterConnections(environment, tag)
+	return
 }
 
-// GetConnection get connection profile by name
-func (c *connectionService) GetConnection(name string) (resp types.JSResp) {
-	conn := c.getConnection(name)
-	resp.Success = conn != nil
-	resp.Data = conn
+// ListConnectionLabels list all environment labels and tags in use
+func (c *connectionService) ListConnectionLabels() (resp types.JSResp) {
+	environments, tags := c.conns.GetConnectionLabels()
+	resp.Success = true
+	resp.Data = map[string]any{
+		"environments": environments,
+		"tags":         tags,
+	}
 	return
 }
 
-// SaveConnection save connection config to local profile
-func (c *connectionService) SaveConnection(name string, param types.ConnectionConfig) (resp types.JSResp) {
-	var err error
-	if strings.ContainsAny(param.Name, "/") {
-		err = errors.New("connection name contains illegal characters")
-	} else {
-		if len(name) > 0 {
-			// update connection
-			err = c.conns.UpdateConnection(name, param)
-		} else {
-			err = c.conns.CreateConnection(param)
+// separator in session name "<connection name><separator><session id>",
+// the unit separator is rejected in connection name, unlike "/" which may exist in imported connections
+const sessionSeparator = "\x1f"
+
+// make session name of connection
+func sessionName(connName string, id int64) string {
+	return connName + sessionSeparator + strconv.FormatInt(id, 10)
+}
+
+// split session name into connection name and session id, session id is empty for connection name
+func splitSession(server string) (connName, session string) {
+	connName, session, _ = strings.Cut(server, sessionSeparator)
+	return
+}
+
+// getConnection get connection by name, the name can also be a session name, see sessionName
+func (c *connectionService) getConnection(name string) *types.Connection {
+	connName, _ := splitSession(name)
+	conn := c.conns.GetConnection(connName)
+	if conn == nil {
+		c.tempMux.Lock()
+		temp, ok := c.temps[connName]
+		c.tempMux.Unlock()
+		if !ok {
+			return nil
 		}
+		conn = &types.Connection{ConnectionConfig: temp}
 	}
-	if err != nil {
-		resp.Msg = err.Error()
-	} else {
-		resp.Success = true
+
+	c.replicaMux.Lock()
+	target, ok := c.replicas[name]
+	c.replicaMux.Unlock()
+	if ok {
+		// connect to the replica node directly
+		conn.Network = "tcp"
+		conn.Addr, conn.Port = target.addr, target.port
+		conn.Cluster.Enable = false
+		conn.Sentinel.Enable = false
+		conn.ReplicaReadOnly = target.readonly
 	}
-	return
+	return conn
 }
 
-// DeleteConnection remove connection by name
-func (c *connectionService) DeleteConnection(name string) (resp types.JSResp) {
-	err := c.conns.DeleteConnection(name)
+// setReplicaTarget bind session to a replica node
+func (c *connectionService) setReplicaTarget(session, addr string, readonly bool) error {
+	host, portStr, err := net.SplitHostPort(addr)
 	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return err
+	}
+
+	c.replicaMux.Lock()
+	defer c.replicaMux.Unlock()
+	c.replicas[session] = replicaTarget{
+		addr:     host,
+		port:     port,
+		readonly: readonly,
+	}
+	return nil
+}
+
+// addTempConnection create an unsaved connection from "host:port" or connection url
+// @return name of the temporary connection
+func (c *connectionService) addTempConnection(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	config := types.ConnectionConfig{
+		Network:     "tcp",
+		ConnTimeout: 10,
+		ExecTimeout: 10,
+		Temporary:   true,
+	}
+	if strings.Contains(address, "://") {
+		urlOpt, err := c.parseURL(address)
+		if err != nil {
+			return "", err
+		}
+		config.Username, config.Password, config.LastDB = urlOpt.Username, urlOpt.Password, urlOpt.DB
+		if urlOpt.Network == "unix" {
+			config.Network, config.Sock = "unix", urlOpt.Addr
+		} else {
+			address = urlOpt.Addr
+		}
+		if urlOpt.TLSConfig != nil {
+			config.SSL.Enable = true
+			config.SSL.SNI = urlOpt.TLSConfig.ServerName
+			config.SSL.AllowInsecure = urlOpt.TLSConfig.InsecureSkipVerify
+		}
+	}
+	if config.Network == "tcp" {
+		host, portStr, err := net.SplitHostPort(address)
+		if err != nil {
+			// port is omitted
+			host, portStr = address, "6379"
+		}
+		if config.Port, err = strconv.Atoi(portStr); err != nil {
+			return "", errors.New("invalid port: " + portStr)
+		}
+		if len(host) <= 0 {
+			host = "127.0.0.1"
+		}
+		config.Addr = host
+	}
+
+	// name by address, and avoid duplicating with saved or other temporary connections
+	baseName := net.JoinHostPort(config.Addr, strconv.Itoa(config.Port))
+	if config.Network == "unix" {
+		baseName = "unix:" + strings.ReplaceAll(config.Sock, "/", "_")
+	}
+	c.tempMux.Lock()
+	defer c.tempMux.Unlock()
+	config.Name = baseName
+	for n := 2; ; n++ {
+		if _, ok := c.temps[config.Name]; !ok && c.conns.GetConnection(config.Name) == nil {
+			break
+		}
+		config.Name = fmt.Sprintf("%s (%d)", baseName, n)
+	}
+	c.temps[config.Name] = config
+	return config.Name, nil
+}
+
+// removeTempConnection discard temporary connection, do nothing for saved connection
+func (c *connectionService) removeTempConnection(name string) {
+	c.tempMux.Lock()
+	defer c.tempMux.Unlock()
+	delete(c.temps, name)
+}
+
+// PromoteConnection save temporary connection to local profile
+// @param newName name of saved connection, keep current name if empty
+func (c *connectionService) PromoteConnection(name, newName, group string) (resp types.JSResp) {
+	c.tempMux.Lock()
+	defer c.tempMux.Unlock()
+	config, ok := c.temps[name]
+	if !ok {
+		resp.Msg = "no temporary connection named \"" + name + "\""
+		return
+	}
+
+	if len(newName) > 0 {
+		config.Name = newName
+	}
+	if strings.ContainsAny(config.Name, "/"+sessionSeparator) {
+		resp.Msg = "connection name contains illegal characters"
+		return
+	}
+	config.Group = group
+	config.Temporary = false
+	if err := c.conns.CreateConnection(config); err != nil {
 		resp.Msg = err.Error()
 		return
 	}
+	delete(c.temps, name)
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"name": config.Name,
+	}
+	return
+}
+
+// GetConnection get connection profile by name
+func (c *connectionService) GetConnection(name string) (resp types.JSResp) {
+	conn := c.getConnection(name)
+	resp.Success = conn != nil
+	resp.Data = conn
+	return
+}
+
+// SaveConnection save connection config to local profile
+func (c *connectionService) SaveConnection(name string, param types.ConnectionConfig) (resp types.JSResp) {
+	var err error
+	if strings.ContainsAny(param.Name, "/"+sessionSeparator) {
+		err = errors.New("connection name contains illegal characters")
+	} else {
+		if len(name) > 0 {
+			// update connection, keep pinned state and last connected time which are not edited by form
+			if conn := c.conns.GetConnection(name); conn != nil {
+				param.Pinned, param.LastConnected = conn.Pinned, conn.LastConnected
+			}
+			if err = c.conns.UpdateConnection(name, param); err == nil && name != param.Name {
+				_ = c.states.RenameState(name, param.Name)
+			}
+		} else {
+			err = c.conns.CreateConnection(param)
+		}
+	}
+	if err != nil {
+		resp.Msg = err.Error()
+	} else {
+		resp.Success = true
+	}
+	return
+}
+
+// CloneConnection duplicate connection with all settings in the same group
+func (c *connectionService) CloneConnection(name string) (resp types.JSResp) {
+	newName, err := c.conns.CloneConnection(name)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"name": newName,
+	}
+	return
+}
+
+// DeleteConnection remove connection by name
+func (c *connectionService) DeleteConnection(name string) (resp types.JSResp) {
+	err := c.conns.DeleteConnection(name)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	_ = c.states.DeleteState(name)
 	resp.Success = true
 	return
 }
@@ -423,9 +1106,31 @@ func (c *connectionService) SaveSortedConnection(sortedConns types.Connections)
 	return
 }
 
-// CreateGroup create a new group
-func (c *connectionService) CreateGroup(name string) (resp types.JSResp) {
-	err := c.conns.CreateGroup(name)
+// MoveConnection move connection into target group at specified position
+func (c *connectionService) MoveConnection(name, parent string, index int) (resp types.JSResp) {
+	err := c.conns.MoveConnection(name, parent, index)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
+
+// MoveGroup move group into target group at specified position
+func (c *connectionService) MoveGroup(name, parent string, index int) (resp types.JSResp) {
+	err := c.conns.MoveGroup(name, parent, index)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
+
+// CreateGroup create a new group under parent group, or top level if parent is empty
+func (c *connectionService) CreateGroup(name, parent string) (resp types.JSResp) {
+	err := c.conns.CreateGroup(name, parent)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
@@ -475,6 +1180,345 @@ func (c *connectionService) SaveLastDB(name string, db int) (resp types.JSResp)
 	return
 }
 
+// saveLastConnected record connected time, ignore temporary connection
+func (c *connectionService) saveLastConnected(name string) {
+	connName, _ := splitSession(name)
+	if c.conns.GetConnection(connName) != nil {
+		_ = c.conns.SaveLastConnected(connName, time.Now().UnixMilli())
+	}
+}
+
+// ListRecentConnections list pinned connections and recently connected ones
+// @param limit max count of recent connections
+func (c *connectionService) ListRecentConnections(limit int) (resp types.JSResp) {
+	pinned, recent := c.conns.GetRecentConnections(limit)
+	resp.Success = true
+	resp.Data = map[string]any{
+		"pinned": pinned,
+		"recent": recent,
+	}
+	return
+}
+
+// PinConnection pin connection to favorites or unpin it
+func (c *connectionService) PinConnection(name string, pinned bool) (resp types.JSResp) {
+	if err := c.conns.SetPinned(name, pinned); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
+
+// GetMasterPasswordState get whether connections file is encrypted by master password and not unlocked yet
+func (c *connectionService) GetMasterPasswordState() (resp types.JSResp) {
+	resp.Success = true
+	resp.Data = map[string]any{
+		"encrypted": c.conns.IsEncrypted(),
+		"locked":    c.conns.IsLocked(),
+	}
+	return
+}
+
+// UnlockConnections unlock encrypted connections file by master password
+func (c *connectionService) UnlockConnections(password string) (resp types.JSResp) {
+	if err := c.conns.Unlock(password); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
+
+// SetMasterPassword set or change master password of connections file, remove encryption if new password is empty
+func (c *connectionService) SetMasterPassword(password, newPassword string) (resp types.JSResp) {
+	if err := c.conns.SetMasterPassword(password, newPassword); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
+
+// MigrateSecretStore move passwords and passphrases of all connections to os keychain or back to profile file
+func (c *connectionService) MigrateSecretStore(useKeychain bool) (resp types.JSResp) {
+	var store string
+	if useKeychain {
+		store = SecretStoreKeychain
+	}
+	count, err := c.conns.SetSecretStore(store)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"count": count,
+	}
+	return
+}
+
+// GetConnectionState get saved state of connection, restore the state when connection reopened
+func (c *connectionService) GetConnectionState(name string) (resp types.JSResp) {
+	conn := c.conns.GetConnection(name)
+	if conn == nil {
+		resp.Msg = "no connection named \"" + name + "\""
+		return
+	}
+
+	state := c.states.GetState(name)
+	state.LastDB = conn.LastDB
+	resp.Success = true
+	resp.Data = state
+	return
+}
+
+// SaveConnectionState save state of connection, include last database, key filter and expanded nodes
+func (c *connectionService) SaveConnectionState(name string, state types.ConnectionState) (resp types.JSResp) {
+	if resp = c.SaveLastDB(name, state.LastDB); !resp.Success {
+		return
+	}
+
+	err := c.states.ModifyState(name, func(s *types.ConnectionState) {
+		// bookmarks, recent keys and notes are maintained separately
+		state.Bookmarks, state.RecentKeys, state.Notes = s.Bookmarks, s.RecentKeys, s.Notes
+		*s = state
+	})
+	if err != nil {
+		resp.Success = false
+		resp.Msg = "save connection state fail:" + err.Error()
+		return
+	}
+	return
+}
+
+// ListKeyBookmarks list bookmarked keys of database
+func (c *connectionService) ListKeyBookmarks(name string, db int) (resp types.JSResp) {
+	keys := c.states.GetState(name).Bookmarks[db]
+	resp.Success = true
+	resp.Data = map[string]any{
+		"keys": sliceutil.Map(keys, func(i int) any {
+			return strutil.EncodeRedisKey(keys[i])
+		}),
+	}
+	return
+}
+
+// AddKeyBookmark bookmark key of database, so it can be reopened without scanning
+func (c *connectionService) AddKeyBookmark(name string, db int, k any) (resp types.JSResp) {
+	if c.conns.GetConnection(name) == nil {
+		resp.Msg = "no connection named \"" + name + "\""
+		return
+	}
+
+	key := strutil.DecodeRedisKey(k)
+	err := c.states.ModifyState(name, func(state *types.ConnectionState) {
+		if slices.Contains(state.Bookmarks[db], key) {
+			return
+		}
+		if state.Bookmarks == nil {
+			state.Bookmarks = map[int][]string{}
+		}
+		state.Bookmarks[db] = append(state.Bookmarks[db], key)
+	})
+	if err != nil {
+		resp.Msg = "save bookmark fail:" + err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// RemoveKeyBookmark remove bookmarked key of database
+func (c *connectionService) RemoveKeyBookmark(name string, db int, k any) (resp types.JSResp) {
+	key := strutil.DecodeRedisKey(k)
+	err := c.states.ModifyState(name, func(state *types.ConnectionState) {
+		if _, ok := state.Bookmarks[db]; !ok {
+			return
+		}
+		state.Bookmarks[db] = slices.DeleteFunc(state.Bookmarks[db], func(s string) bool {
+			return s == key
+		})
+		if len(state.Bookmarks[db]) <= 0 {
+			delete(state.Bookmarks, db)
+		}
+	})
+	if err != nil {
+		resp.Msg = "remove bookmark fail:" + err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// ListRecentKeys list recently viewed keys of connection, the latest comes first
+func (c *connectionService) ListRecentKeys(name string) (resp types.JSResp) {
+	recentKeys := c.states.GetState(name).RecentKeys
+	resp.Success = true
+	resp.Data = map[string]any{
+		"keys": sliceutil.Map(recentKeys, func(i int) map[string]any {
+			return map[string]any{
+				"db":        recentKeys[i].DB,
+				"key":       strutil.EncodeRedisKey(recentKeys[i].Key),
+				"type":      recentKeys[i].Type,
+				"timestamp": recentKeys[i].Timestamp,
+			}
+		}),
+	}
+	return
+}
+
+// RecordRecentKey record key as the latest viewed one, only the last few keys are kept
+func (c *connectionService) RecordRecentKey(name string, db int, k any, keyType string) (resp types.JSResp) {
+	if c.conns.GetConnection(name) == nil {
+		resp.Msg = "no connection named \"" + name + "\""
+		return
+	}
+
+	key := strutil.DecodeRedisKey(k)
+	err := c.states.ModifyState(name, func(state *types.ConnectionState) {
+		recentKeys := slices.DeleteFunc(state.RecentKeys, func(r types.RecentKey) bool {
+			return r.DB == db && r.Key == key
+		})
+		recentKeys = slices.Insert(recentKeys, 0, types.RecentKey{
+			DB:        db,
+			Key:       key,
+			Type:      keyType,
+			Timestamp: time.Now().UnixMilli(),
+		})
+		if len(recentKeys) > consts.MAX_RECENT_KEYS {
+			recentKeys = recentKeys[:consts.MAX_RECENT_KEYS]
+		}
+		state.RecentKeys = recentKeys
+	})
+	if err != nil {
+		resp.Msg = "save recent key fail:" + err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// ClearRecentKeys remove all recently viewed keys of connection
+func (c *connectionService) ClearRecentKeys(name string) (resp types.JSResp) {
+	err := c.states.ModifyState(name, func(state *types.ConnectionState) {
+		state.RecentKeys = nil
+	})
+	if err != nil {
+		resp.Msg = "clear recent keys fail:" + err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// ListKeyNotes list all local notes of keys and namespaces in connection
+func (c *connectionService) ListKeyNotes(name string) (resp types.JSResp) {
+	notes := c.states.GetState(name).Notes
+	if notes == nil {
+		notes = []types.KeyNote{}
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"notes": notes,
+	}
+	return
+}
+
+// SaveKeyNote add or update note of key or namespace pattern, remove it if both note and labels are empty
+func (c *connectionService) SaveKeyNote(name string, note types.KeyNote) (resp types.JSResp) {
+	if c.conns.GetConnection(name) == nil {
+		resp.Msg = "no connection named \"" + name + "\""
+		return
+	}
+	if len(note.Pattern) <= 0 {
+		resp.Msg = "key or pattern is required"
+		return
+	}
+
+	note.Note = strings.TrimSpace(note.Note)
+	note.Labels = slices.DeleteFunc(note.Labels, func(label string) bool {
+		return len(strings.TrimSpace(label)) <= 0
+	})
+	note.UpdatedAt = time.Now().UnixMilli()
+	err := c.states.ModifyState(name, func(state *types.ConnectionState) {
+		state.Notes = slices.DeleteFunc(state.Notes, func(n types.KeyNote) bool {
+			return n.DB == note.DB && n.Pattern == note.Pattern
+		})
+		if len(note.Note) > 0 || len(note.Labels) > 0 {
+			state.Notes = append(state.Notes, note)
+		}
+	})
+	if err != nil {
+		resp.Msg = "save note fail:" + err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// DeleteKeyNote remove note of key or namespace pattern
+func (c *connectionService) DeleteKeyNote(name string, db int, pattern string) (resp types.JSResp) {
+	err := c.states.ModifyState(name, func(state *types.ConnectionState) {
+		state.Notes = slices.DeleteFunc(state.Notes, func(n types.KeyNote) bool {
+			return n.DB == db && n.Pattern == pattern
+		})
+	})
+	if err != nil {
+		resp.Msg = "delete note fail:" + err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// get notes matching key exactly or by glob-style pattern
+func (c *connectionService) matchKeyNotes(name string, db int, key string) []types.KeyNote {
+	notes := c.states.GetState(name).Notes
+	return sliceutil.FilterMap(notes, func(i int) (types.KeyNote, bool) {
+		n := notes[i]
+		return n, n.DB == db && (n.Pattern == key || strutil.MatchGlob(n.Pattern, key))
+	})
+}
+
+// SaveDBAlias save alias name of database index, remove alias if empty
+func (c *connectionService) SaveDBAlias(name string, db int, alias string) (resp types.JSResp) {
+	param := c.conns.GetConnection(name)
+	if param == nil {
+		resp.Msg = "no connection named \"" + name + "\""
+		return
+	}
+	if db < 0 {
+		resp.Msg = "invalid database index"
+		return
+	}
+
+	alias = strings.TrimSpace(alias)
+	if param.Alias[db] != alias {
+		if len(alias) > 0 {
+			if param.Alias == nil {
+				param.Alias = map[int]string{}
+			}
+			param.Alias[db] = alias
+		} else {
+			delete(param.Alias, db)
+		}
+		if err := c.conns.UpdateConnection(name, param.ConnectionConfig); err != nil {
+			resp.Msg = "save connection fail:" + err.Error()
+			return
+		}
+	}
+	resp.Success = true
+	return
+}
+
 // SaveRefreshInterval save auto refresh interval
 func (c *connectionService) SaveRefreshInterval(name string, interval int) (resp types.JSResp) {
 	param := c.conns.GetConnection(name)
@@ -493,6 +1537,35 @@ func (c *connectionService) SaveRefreshInterval(name string, interval int) (resp
 	return
 }
 
+// SaveKeySeparators save separators of key namespaces, the first one is the main separator
+// opened connection applies new separators without reconnecting
+func (c *connectionService) SaveKeySeparators(name string, separators []string) (resp types.JSResp) {
+	param := c.conns.GetConnection(name)
+	if param == nil {
+		resp.Msg = "no connection named \"" + name + "\""
+		return
+	}
+	separators = sliceutil.FilterMap(separators, func(i int) (string, bool) {
+		return separators[i], len(separators[i]) > 0
+	})
+	if len(separators) <= 0 {
+		resp.Msg = "at least one separator is required"
+		return
+	}
+
+	param.KeySeparator, param.KeySeparators = separators[0], separators[1:]
+	if err := c.conns.UpdateConnection(name, param.ConnectionConfig); err != nil {
+		resp.Msg = "save connection fail:" + err.Error()
+		return
+	}
+	Browser().updateKeySeparators(name, param.KeySeparator, param.KeySeparators)
+	resp.Success = true
+	resp.Data = map[string]any{
+		"separators": keySeparators(param.KeySeparator, param.KeySeparators),
+	}
+	return
+}
+
 // ExportConnections export connections to zip file
 func (c *connectionService) ExportConnections() (resp types.JSResp) {
 	defaultFileName := "connections_" + time.Now().Format("20060102150405") + ".zip"
@@ -605,52 +1678,248 @@ func (c *connectionService) ImportConnections() (resp types.JSResp) {
 	return
 }
 
+type encryptedConnections struct {
+	Version int    `json:"version"`
+	Cipher  string `json:"cipher"`
+	Data    string `json:"data"` // base64 of encrypted connections json
+}
+
+// ExportEncryptedConnections export selected connections to json file encrypted with passphrase
+// @param names connection or group names, export all if empty
+func (c *connectionService) ExportEncryptedConnections(names []string, passphrase string) (resp types.JSResp) {
+	if len(passphrase) <= 0 {
+		resp.Msg = "passphrase is required"
+		return
+	}
+	conns := c.conns.GetSelectedConnections(names)
+	if len(conns) <= 0 {
+		resp.Msg = "no connection to export"
+		return
+	}
+
+	defaultFileName := "connections_" + time.Now().Format("20060102150405") + ".json"
+	filepath, err := runtime.SaveFileDialog(c.ctx, runtime.SaveDialogOptions{
+		ShowHiddenFiles: true,
+		DefaultFilename: defaultFileName,
+		Filters: []runtime.FileFilter{
+			{
+				Pattern: "*.json",
+			},
+		},
+	})
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	plain, err := json.Marshal(conns)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	encrypted, err := cryptoutil.EncryptWithPassphrase(plain, passphrase)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	content, err := json.MarshalIndent(encryptedConnections{
+		Version: 1,
+		Cipher:  "scrypt+aes-256-gcm",
+		Data:    base64.StdEncoding.EncodeToString(encrypted),
+	}, "", "  ")
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if err = os.WriteFile(filepath, content, 0600); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = struct {
+		Path string `json:"path"`
+	}{
+		Path: filepath,
+	}
+	return
+}
+
+// ImportEncryptedConnections import connections from encrypted json file
+// @param conflict resolution when connection name is duplicated: 0 skip, 1 overwrite, 2 rename
+func (c *connectionService) ImportEncryptedConnections(passphrase string, conflict int) (resp types.JSResp) {
+	filepath, err := runtime.OpenFileDialog(c.ctx, runtime.OpenDialogOptions{
+		ShowHiddenFiles: true,
+		Filters: []runtime.FileFilter{
+			{
+				Pattern: "*.json",
+			},
+		},
+	})
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	content, err := os.ReadFile(filepath)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	var file encryptedConnections
+	if err = json.Unmarshal(content, &file); err != nil || file.Version != 1 {
+		resp.Msg = "invalid connections file"
+		return
+	}
+	encrypted, err := base64.StdEncoding.DecodeString(file.Data)
+	if err != nil {
+		resp.Msg = "invalid connections file"
+		return
+	}
+	plain, err := cryptoutil.DecryptWithPassphrase(encrypted, passphrase)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	var conns types.Connections
+	if err = json.Unmarshal(plain, &conns); err != nil {
+		resp.Msg = "invalid connections file"
+		return
+	}
+
+	imported, skipped, err := c.conns.ImportConnections(conns, conflict)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = struct {
+		Imported int `json:"imported"`
+		Skipped  int `json:"skipped"`
+	}{
+		Imported: imported,
+		Skipped:  skipped,
+	}
+	return
+}
+
+func (c *connectionService) parseURL(connURL string) (*redis.Options, error) {
+	if strings.HasPrefix(connURL, "redis-socket://") {
+		connURL = "unix://" + strings.TrimPrefix(connURL, "redis-socket://")
+	}
+	return redis.ParseURL(connURL)
+}
+
 // ParseConnectURL parse connection url string
-func (c *connectionService) ParseConnectURL(url string) (resp types.JSResp) {
-	urlOpt, err := redis.ParseURL(url)
+// support redis://, rediss:// and redis-socket:// (or unix://) schemes
+func (c *connectionService) ParseConnectURL(connURL string) (resp types.JSResp) {
+	urlOpt, err := c.parseURL(connURL)
 	if err != nil {
 		resp.Msg = err.Error()
 		return
 	}
 
-	var network, addr string
+	var network, addr, sock string
 	var port int
 	if urlOpt.Network == "unix" {
 		network = urlOpt.Network
 		addr = urlOpt.Addr
+		sock = urlOpt.Addr
 	} else {
 		network = "tcp"
-		addrPart := strings.Split(urlOpt.Addr, ":")
-		addr = addrPart[0]
+		host, portStr, splitErr := net.SplitHostPort(urlOpt.Addr)
+		if splitErr != nil {
+			host = urlOpt.Addr
+		}
+		addr = host
 		port = 6379
-		if len(addrPart) > 1 {
-			port, _ = strconv.Atoi(addrPart[1])
+		if len(portStr) > 0 {
+			port, _ = strconv.Atoi(portStr)
 		}
 	}
-	var sslServerName string
+	var sslServerName *string
+	var allowInsecure bool
 	if urlOpt.TLSConfig != nil {
-		sslServerName = urlOpt.TLSConfig.ServerName
+		sslServerName = &urlOpt.TLSConfig.ServerName
+		allowInsecure = urlOpt.TLSConfig.InsecureSkipVerify
 	}
 	resp.Success = true
 	resp.Data = struct {
-		Network       string `json:"network"`
-		Sock          string `json:"sock"`
-		Addr          string `json:"addr"`
-		Port          int    `json:"port"`
-		Username      string `json:"username"`
-		Password      string `json:"password"`
-		ConnTimeout   int64  `json:"connTimeout"`
-		ExecTimeout   int64  `json:"execTimeout"`
-		SSLServerName string `json:"sslServerName,omitempty"`
+		Network       string  `json:"network"`
+		Sock          string  `json:"sock"`
+		Addr          string  `json:"addr"`
+		Port          int     `json:"port"`
+		Username      string  `json:"username"`
+		Password      string  `json:"password"`
+		DB            int     `json:"db"`
+		ConnTimeout   int64   `json:"connTimeout"`
+		ExecTimeout   int64   `json:"execTimeout"`
+		SSLServerName *string `json:"sslServerName,omitempty"`
+		AllowInsecure bool    `json:"allowInsecure,omitempty"`
 	}{
 		Network:       network,
+		Sock:          sock,
 		Addr:          addr,
 		Port:          port,
 		Username:      urlOpt.Username,
 		Password:      urlOpt.Password,
+		DB:            urlOpt.DB,
 		ConnTimeout:   int64(urlOpt.DialTimeout.Seconds()),
 		ExecTimeout:   int64(urlOpt.ReadTimeout.Seconds()),
 		SSLServerName: sslServerName,
+		AllowInsecure: allowInsecure,
+	}
+	return
+}
+
+// GenerateConnectURL generate connection url string from saved connection, which can be parsed by ParseConnectURL
+func (c *connectionService) GenerateConnectURL(name string) (resp types.JSResp) {
+	conn := c.conns.GetConnection(name)
+	if conn == nil {
+		resp.Msg = "no connection named \"" + name + "\""
+		return
+	}
+
+	u := url.URL{}
+	query := url.Values{}
+	if conn.Network == "unix" {
+		u.Scheme = "redis-socket"
+		u.Path = conn.Sock
+		if conn.LastDB > 0 {
+			query.Set("db", strconv.Itoa(conn.LastDB))
+		}
+	} else {
+		if conn.SSL.Enable {
+			u.Scheme = "rediss"
+			if conn.SSL.AllowInsecure {
+				query.Set("skip_verify", "true")
+			}
+		} else {
+			u.Scheme = "redis"
+		}
+		port := conn.Port
+		if port <= 0 {
+			port = 6379
+		}
+		u.Host = net.JoinHostPort(conn.Addr, strconv.Itoa(port))
+		u.Path = "/" + strconv.Itoa(conn.LastDB)
+	}
+	if len(conn.Username) > 0 || len(conn.Password) > 0 {
+		u.User = url.UserPassword(conn.Username, conn.Password)
+	}
+	if conn.ConnTimeout > 0 {
+		query.Set("dial_timeout", strconv.Itoa(conn.ConnTimeout)+"s")
+	}
+	if conn.ExecTimeout > 0 {
+		query.Set("read_timeout", strconv.Itoa(conn.ExecTimeout)+"s")
+		query.Set("write_timeout", strconv.Itoa(conn.ExecTimeout)+"s")
+	}
+	u.RawQuery = query.Encode()
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"url": u.String(),
 	}
 	return
 }
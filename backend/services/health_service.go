@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"tinyrdm/backend/types"
+)
+
+type healthService struct {
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	mutex     sync.Mutex
+	status    map[string]types.ConnectionHealth
+	stopCh    chan struct{}
+}
+
+var health *healthService
+var onceHealth sync.Once
+
+func Health() *healthService {
+	if health == nil {
+		onceHealth.Do(func() {
+			health = &healthService{
+				status: map[string]types.ConnectionHealth{},
+			}
+		})
+	}
+	return health
+}
+
+func (h *healthService) Start(ctx context.Context) {
+	h.ctx, h.ctxCancel = context.WithCancel(ctx)
+}
+
+// StartHealthCheck start to ping all saved connections periodically in background
+// the aggregated status will be emitted by event "health:status" after each round
+// @param interval check interval in seconds
+func (h *healthService) StartHealthCheck(interval int) (resp types.JSResp) {
+	if interval <= 0 {
+		resp.Msg = "invalid check interval"
+		return
+	}
+
+	h.StopHealthCheck()
+	h.mutex.Lock()
+	stopCh := make(chan struct{})
+	h.stopCh = stopCh
+	h.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			h.checkAll()
+			runtime.EventsEmit(h.ctx, "health:status", h.getStatusList())
+			select {
+			case <-ticker.C:
+			case <-stopCh:
+				return
+			case <-h.ctx.Done():
+				return
+			}
+		}
+	}()
+	resp.Success = true
+	return
+}
+
+// StopHealthCheck stop background health check
+func (h *healthService) StopHealthCheck() (resp types.JSResp) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.stopCh != nil {
+		close(h.stopCh)
+		h.stopCh = nil
+	}
+	resp.Success = true
+	return
+}
+
+// GetHealthStatus get last health status of all saved connections
+func (h *healthService) GetHealthStatus() (resp types.JSResp) {
+	resp.Success = true
+	resp.Data = h.getStatusList()
+	return
+}
+
+// StopAll stop health check
+func (h *healthService) StopAll() {
+	if h.ctxCancel != nil {
+		h.ctxCancel()
+	}
+	h.StopHealthCheck()
+}
+
+func (h *healthService) getStatusList() []types.ConnectionHealth {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// keep the order of saved connections
+	var list []types.ConnectionHealth
+	for _, conn := range Connection().conns.GetConnectionsFlat() {
+		if status, ok := h.status[conn.Name]; ok {
+			list = append(list, status)
+		}
+	}
+	return list
+}
+
+// checkAll ping all connections concurrently
+func (h *healthService) checkAll() {
+	const maxConcurrent = 8
+	conns := Connection().conns.GetConnectionsFlat()
+	status := make(map[string]types.ConnectionHealth, len(conns))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrent)
+	for _, conn := range conns {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(config types.ConnectionConfig) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result := h.check(config)
+			mutex.Lock()
+			status[config.Name] = result
+			mutex.Unlock()
+		}(conn.ConnectionConfig)
+	}
+	wg.Wait()
+
+	h.mutex.Lock()
+	h.status = status
+	h.mutex.Unlock()
+}
+
+// check ping one connection and classify the result
+func (h *healthService) check(config types.ConnectionConfig) types.ConnectionHealth {
+	const maxTimeout = 10
+	result := types.ConnectionHealth{
+		Name:      config.Name,
+		CheckedAt: time.Now().UnixMilli(),
+	}
+	// fail fast in health check
+	if config.ConnTimeout <= 0 || config.ConnTimeout > maxTimeout {
+		config.ConnTimeout = maxTimeout
+	}
+	if config.ExecTimeout <= 0 || config.ExecTimeout > maxTimeout {
+		config.ExecTimeout = maxTimeout
+	}
+
+	client, err := Connection().createRedisClient(config)
+	if err == nil {
+		defer client.Close()
+		start := time.Now()
+		if err = client.Ping(h.ctx).Err(); err == nil {
+			result.Latency = time.Since(start).Milliseconds()
+			result.Status = types.HEALTH_REACHABLE
+			if res, infoErr := client.Info(h.ctx, "server").Result(); infoErr == nil {
+				result.Version = Browser().parseInfo(res)["Server"]["redis_version"]
+			}
+			return result
+		}
+	}
+
+	result.Error = err.Error()
+	var netErr net.Error
+	errMsg := strings.ToUpper(err.Error())
+	switch {
+	case strings.Contains(errMsg, "WRONGPASS"), strings.Contains(errMsg, "NOAUTH"),
+		strings.Contains(errMsg, "INVALID PASSWORD"), strings.Contains(errMsg, "INVALID USERNAME-PASSWORD"):
+		result.Status = types.HEALTH_AUTH_FAILED
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		result.Status = types.HEALTH_TIMEOUT
+	default:
+		result.Status = types.HEALTH_UNREACHABLE
+	}
+	return result
+}
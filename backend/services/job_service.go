@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"tinyrdm/backend/types"
+)
+
+const maxJobLogs = 200
+const maxFinishedJobs = 100
+
+type jobItem struct {
+	mutex      sync.Mutex
+	job        types.Job
+	logs       []types.JobLog
+	cancelFunc context.CancelFunc
+	lastEmit   time.Time
+}
+
+type jobService struct {
+	ctx   context.Context
+	mutex sync.Mutex
+	jobs  map[string]*jobItem
+}
+
+var jobs *jobService
+var onceJobs sync.Once
+
+func Jobs() *jobService {
+	if jobs == nil {
+		onceJobs.Do(func() {
+			jobs = &jobService{
+				jobs: map[string]*jobItem{},
+			}
+		})
+	}
+	return jobs
+}
+
+func (j *jobService) Start(ctx context.Context) {
+	j.ctx = ctx
+}
+
+// register a running job, cancelFunc is called when job canceled by CancelJob
+func (j *jobService) newJob(kind, title, server string, db int, cancelFunc context.CancelFunc) *jobItem {
+	now := time.Now()
+	item := &jobItem{
+		job: types.Job{
+			ID:        kind + ":" + strconv.FormatInt(now.UnixNano(), 10),
+			Kind:      kind,
+			Title:     title,
+			Server:    server,
+			DB:        db,
+			Status:    types.JOB_RUNNING,
+			Progress:  -1,
+			ETA:       -1,
+			StartTime: now.UnixMilli(),
+		},
+		cancelFunc: cancelFunc,
+	}
+
+	j.mutex.Lock()
+	j.jobs[item.job.ID] = item
+	j.purge()
+	j.mutex.Unlock()
+	j.emit(item.job)
+	return item
+}
+
+// remove the oldest finished jobs if too many
+func (j *jobService) purge() {
+	finished := make([]*jobItem, 0, len(j.jobs))
+	for _, item := range j.jobs {
+		if item.snapshot().Status != types.JOB_RUNNING {
+			finished = append(finished, item)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+	slices.SortFunc(finished, func(a, b *jobItem) int {
+		return int(a.snapshot().StartTime - b.snapshot().StartTime)
+	})
+	for _, item := range finished[:len(finished)-maxFinishedJobs] {
+		delete(j.jobs, item.job.ID)
+	}
+}
+
+func (j *jobService) emit(job types.Job) {
+	if j.ctx != nil {
+		runtime.EventsEmit(j.ctx, "jobs:update", job)
+	}
+}
+
+func (i *jobItem) snapshot() types.Job {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.job
+}
+
+// update progress of job, progress and eta are estimated if total is known
+func (i *jobItem) update(processed, total int64) {
+	i.mutex.Lock()
+	i.job.Processed, i.job.Total = processed, total
+	if total > 0 {
+		i.job.Progress = min(float64(processed)*100/float64(total), 100)
+		if elapsed := time.Now().UnixMilli() - i.job.StartTime; processed > 0 {
+			i.job.ETA = elapsed * (total - min(processed, total)) / processed / 1000
+		}
+	}
+	// emit progress at most every 100ms
+	var job *types.Job
+	if time.Now().Sub(i.lastEmit).Milliseconds() > 100 {
+		i.lastEmit = time.Now()
+		snapshot := i.job
+		job = &snapshot
+	}
+	i.mutex.Unlock()
+	if job != nil {
+		Jobs().emit(*job)
+	}
+}
+
+// append log of job, only the latest logs are kept
+func (i *jobItem) log(format string, args ...any) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.logs = append(i.logs, types.JobLog{
+		Timestamp: time.Now().UnixMilli(),
+		Message:   fmt.Sprintf(format, args...),
+	})
+	if len(i.logs) > maxJobLogs {
+		i.logs = slices.Delete(i.logs, 0, len(i.logs)-maxJobLogs)
+	}
+}
+
+// mark job finished, canceled if err is context.Canceled, or failed if err is other error
+func (i *jobItem) finish(canceled bool, err error) {
+	i.mutex.Lock()
+	switch {
+	case canceled || errors.Is(err, context.Canceled):
+		i.job.Status = types.JOB_CANCELED
+	case err != nil:
+		i.job.Status = types.JOB_FAILED
+		i.job.Error = err.Error()
+	default:
+		i.job.Status = types.JOB_DONE
+		if i.job.Total > 0 {
+			i.job.Progress = 100
+		}
+	}
+	i.job.ETA = 0
+	i.job.EndTime = time.Now().UnixMilli()
+	job := i.job
+	i.mutex.Unlock()
+	Jobs().emit(job)
+}
+
+// summarize failed items of job as error, nil if nothing failed
+func jobFailure(failed int64, lastErr error) error {
+	if failed <= 0 || lastErr == nil {
+		return nil
+	}
+	return fmt.Errorf("%d failed, last error: %s", failed, lastErr.Error())
+}
+
+// ListJobs list all running and recently finished jobs, the latest comes first
+func (j *jobService) ListJobs() (resp types.JSResp) {
+	j.mutex.Lock()
+	list := make([]types.Job, 0, len(j.jobs))
+	for _, item := range j.jobs {
+		list = append(list, item.snapshot())
+	}
+	j.mutex.Unlock()
+	slices.SortFunc(list, func(a, b types.Job) int {
+		return int(b.StartTime - a.StartTime)
+	})
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"jobs": list,
+	}
+	return
+}
+
+// GetJobLogs get logs of job
+func (j *jobService) GetJobLogs(id string) (resp types.JSResp) {
+	j.mutex.Lock()
+	item, ok := j.jobs[id]
+	j.mutex.Unlock()
+	if !ok {
+		resp.Msg = "job not found"
+		return
+	}
+
+	item.mutex.Lock()
+	logs := slices.Clone(item.logs)
+	item.mutex.Unlock()
+	if logs == nil {
+		logs = []types.JobLog{}
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"logs": logs,
+	}
+	return
+}
+
+// CancelJob cancel running job
+func (j *jobService) CancelJob(id string) (resp types.JSResp) {
+	j.mutex.Lock()
+	item, ok := j.jobs[id]
+	j.mutex.Unlock()
+	if !ok {
+		resp.Msg = "job not found"
+		return
+	}
+
+	if item.snapshot().Status == types.JOB_RUNNING && item.cancelFunc != nil {
+		item.cancelFunc()
+	}
+	resp.Success = true
+	return
+}
+
+// ClearFinishedJobs remove all finished jobs from list
+func (j *jobService) ClearFinishedJobs() (resp types.JSResp) {
+	j.mutex.Lock()
+	for id, item := range j.jobs {
+		if item.snapshot().Status != types.JOB_RUNNING {
+			delete(j.jobs, id)
+		}
+	}
+	j.mutex.Unlock()
+	resp.Success = true
+	return
+}
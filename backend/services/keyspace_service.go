@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tinyrdm/backend/types"
+	"tinyrdm/backend/utils/coll"
+	strutil "tinyrdm/backend/utils/string"
+)
+
+type keyspaceItem struct {
+	client    redis.UniversalClient
+	pubsub    *redis.PubSub
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	prefix    string // channel prefix "__keyspace@<db>__:"
+	events    coll.Set[string]
+	eventName string
+	flags     string // "notify-keyspace-events" set for watching
+	prevFlags string // "notify-keyspace-events" before watching, restored if watching is not stopped by frontend
+}
+
+type keyspaceService struct {
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	mutex     sync.Mutex
+	items     map[string]*keyspaceItem
+}
+
+var keyspace *keyspaceService
+var onceKeyspace sync.Once
+
+func Keyspace() *keyspaceService {
+	if keyspace == nil {
+		onceKeyspace.Do(func() {
+			keyspace = &keyspaceService{
+				items: map[string]*keyspaceItem{},
+			}
+		})
+	}
+	return keyspace
+}
+
+func (k *keyspaceService) Start(ctx context.Context) {
+	k.ctx, k.ctxCancel = context.WithCancel(ctx)
+}
+
+// merge notification classes into current "notify-keyspace-events" flags, "K" is always required
+func mergeNotifyFlags(current, classes string) string {
+	flags := current
+	for _, c := range "K" + classes {
+		if !strings.ContainsRune(flags, c) {
+			if strings.ContainsRune(flags, 'A') && strings.ContainsRune("g$lshzxetd", c) {
+				// already included by alias "A"
+				continue
+			}
+			flags += string(c)
+		}
+	}
+	return flags
+}
+
+// StartKeyspaceEvents enable keyspace notifications of specified classes and subscribe events of keys matching pattern,
+// events are pushed by event in batches, the previous flags are returned so that it can be restored after watching
+func (k *keyspaceService) StartKeyspaceEvents(param types.KeyspaceEventParam) (resp types.JSResp) {
+	conf := Connection().getConnection(param.Server)
+	if conf == nil {
+		resp.Msg = fmt.Sprintf("no connection profile named: %s", param.Server)
+		return
+	}
+	if conf.Cluster.Enable {
+		// notifications are not broadcast in cluster, each node only publishes events of its own keys
+		resp.Msg = "keyspace notifications are not supported in cluster mode"
+		return
+	}
+	// subscribing occupies the connection, use a dedicated client
+	client, err := Connection().createRedisClient(conf.ConnectionConfig)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	ctx, ctxCancel := context.WithCancel(k.ctx)
+	var prevFlags string
+	if res, err := client.ConfigGet(ctx, "notify-keyspace-events").Result(); err == nil {
+		prevFlags = res["notify-keyspace-events"]
+	}
+	classes := param.Classes
+	if len(classes) <= 0 {
+		classes = "A"
+	}
+	flags := mergeNotifyFlags(prevFlags, classes)
+	if flags != prevFlags {
+		if err = client.ConfigSet(ctx, "notify-keyspace-events", flags).Err(); err != nil {
+			ctxCancel()
+			client.Close()
+			resp.Msg = err.Error()
+			return
+		}
+	}
+
+	pattern := param.Pattern
+	if len(pattern) <= 0 {
+		pattern = "*"
+	}
+	item := &keyspaceItem{
+		client:    client,
+		ctx:       ctx,
+		ctxCancel: ctxCancel,
+		prefix:    fmt.Sprintf("__keyspace@%d__:", param.DB),
+		events:    coll.NewSet(param.Events...),
+		eventName: "keyspace:" + strconv.FormatInt(time.Now().UnixNano(), 10),
+		flags:     flags,
+		prevFlags: prevFlags,
+	}
+	item.pubsub = client.PSubscribe(ctx, item.prefix+pattern)
+	if _, err = item.pubsub.Receive(ctx); err != nil {
+		item.pubsub.Close()
+		ctxCancel()
+		client.Close()
+		resp.Msg = err.Error()
+		return
+	}
+
+	k.mutex.Lock()
+	k.items[item.eventName] = item
+	k.mutex.Unlock()
+
+	go k.processEvents(item)
+	resp.Success = true
+	resp.Data = map[string]any{
+		"eventName": item.eventName,
+		"flags":     flags,
+		"prevFlags": prevFlags,
+	}
+	return
+}
+
+func (k *keyspaceService) processEvents(item *keyspaceItem) {
+	ch := item.pubsub.Channel()
+	cache := make([]types.KeyspaceEvent, 0, 1000)
+	ticker := time.NewTicker(300 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			// payload of keyspace channel is the event name
+			if item.events.Size() > 0 && !item.events.Contains(msg.Payload) {
+				continue
+			}
+			cache = append(cache, types.KeyspaceEvent{
+				Timestamp: time.Now().UnixMilli(),
+				Key:       strutil.EncodeRedisKey(strings.TrimPrefix(msg.Channel, item.prefix)),
+				Event:     msg.Payload,
+			})
+			if len(cache) > 300 {
+				runtime.EventsEmit(k.ctx, item.eventName, cache)
+				cache = cache[:0:cap(cache)]
+			}
+
+		case <-ticker.C:
+			if len(cache) > 0 {
+				runtime.EventsEmit(k.ctx, item.eventName, cache)
+				cache = cache[:0:cap(cache)]
+			}
+
+		case <-item.ctx.Done():
+			// watching stopped
+			return
+		}
+	}
+}
+
+// StopKeyspaceEvents stop watching keyspace events
+// @param restoreFlags restore "notify-keyspace-events" to this value if not empty
+func (k *keyspaceService) StopKeyspaceEvents(eventName string, restoreFlags string) (resp types.JSResp) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	item, ok := k.items[eventName]
+	if !ok {
+		resp.Success = true
+		return
+	}
+
+	item.pubsub.Close()
+	if len(restoreFlags) > 0 {
+		ctx, cancel := context.WithTimeout(k.ctx, 5*time.Second)
+		item.client.ConfigSet(ctx, "notify-keyspace-events", restoreFlags)
+		cancel()
+	}
+	item.ctxCancel()
+	item.client.Close()
+	delete(k.items, eventName)
+	resp.Success = true
+	return
+}
+
+// StopAll stop all watching and restore flags changed at start, even if notifications were disabled before
+func (k *keyspaceService) StopAll() {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	for eventName, item := range k.items {
+		item.pubsub.Close()
+		if item.flags != item.prevFlags {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			item.client.ConfigSet(ctx, "notify-keyspace-events", item.prevFlags)
+			cancel()
+		}
+		item.ctxCancel()
+		item.client.Close()
+		delete(k.items, eventName)
+	}
+
+	if k.ctxCancel != nil {
+		k.ctxCancel()
+	}
+}
@@ -3,25 +3,43 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"log"
 	"net/http"
 	"os"
+	"path"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"tinyrdm/backend/consts"
 	storage2 "tinyrdm/backend/storage"
 	"tinyrdm/backend/types"
+	avroutil "tinyrdm/backend/utils/avro"
 	"tinyrdm/backend/utils/coll"
 	convutil "tinyrdm/backend/utils/convert"
+	protoutil "tinyrdm/backend/utils/proto"
 	sliceutil "tinyrdm/backend/utils/slice"
+	strutil "tinyrdm/backend/utils/string"
 
 	"github.com/adrg/sysfont"
+	"github.com/vrischmann/userdir"
 	runtime2 "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type preferencesService struct {
 	pref          *storage2.PreferencesStorage
 	clientVersion string
+
+	protoMutex     sync.Mutex
+	protoSignature string // paths and modified time of loaded proto files
+	protoErr       error
+
+	avroMutex     sync.Mutex
+	avroSignature string // url and credentials of current schema registry
+
+	pluginMutex     sync.Mutex
+	pluginSignature string // names and modified time of loaded wasm plugins
 }
 
 var preferences *preferencesService
@@ -63,12 +81,16 @@ func (p *preferencesService) UpdatePreferences(value map[string]any) (resp types
 		resp.Msg = err.Error()
 		return
 	}
+	p.updateAvroRegistry()
+	convutil.SetMaxDecodedSize(p.GetLargeValueSize())
 	resp.Success = true
 	return
 }
 
 func (p *preferencesService) RestorePreferences() (resp types.JSResp) {
 	defaultPref := p.pref.RestoreDefault()
+	p.updateAvroRegistry()
+	convutil.SetMaxDecodedSize(p.GetLargeValueSize())
 	resp.Data = map[string]any{
 		"pref": defaultPref,
 	}
@@ -110,8 +132,14 @@ func (p *preferencesService) GetBuildInDecoder() (resp types.JSResp) {
 			buildinDecoder = append(buildinDecoder, name)
 		}
 	}
+	p.loadPlugins()
+	plugins := convutil.WasmPlugins()
+	pluginDecoder := sliceutil.Map(plugins, func(i int) string {
+		return plugins[i].Name
+	})
 	resp.Data = map[string]any{
 		"decoder": buildinDecoder,
+		"plugin":  pluginDecoder,
 	}
 	resp.Success = true
 	return
@@ -206,7 +234,33 @@ func (p *preferencesService) GetScanSize() int {
 	return size
 }
 
+// GetLargeValueSize get threshold in bytes of large string value
+func (p *preferencesService) GetLargeValueSize() int64 {
+	data := p.pref.GetPreferences()
+	size := data.General.LargeValueSize
+	if size <= 0 {
+		size = consts.DEFAULT_LARGE_VALUE_SIZE
+	}
+	return int64(size) * 1024
+}
+
+// GetClientNameTemplate get template of client name for opened connections
+func (p *preferencesService) GetClientNameTemplate() string {
+	data := p.pref.GetPreferences()
+	if tpl := strings.TrimSpace(data.General.ClientName); len(tpl) > 0 {
+		return tpl
+	}
+	return consts.DEFAULT_CLIENT_NAME
+}
+
+// GetDefaultProxy get default proxy for connections
+func (p *preferencesService) GetDefaultProxy() types.ConnectionProxy {
+	data := p.pref.GetPreferences()
+	return data.General.DefaultProxy
+}
+
 func (p *preferencesService) GetDecoder() []convutil.CmdConvert {
+	p.loadPlugins()
 	data := p.pref.GetPreferences()
 	return sliceutil.FilterMap(data.Decoder, func(i int) (convutil.CmdConvert, bool) {
 		//if !data.Decoder[i].Enable {
@@ -219,10 +273,279 @@ func (p *preferencesService) GetDecoder() []convutil.CmdConvert {
 			DecodeArgs: data.Decoder[i].DecodeArgs,
 			EncodePath: data.Decoder[i].EncodePath,
 			EncodeArgs: data.Decoder[i].EncodeArgs,
+			Chain:      data.Decoder[i].Chain,
 		}, true
 	})
 }
 
+// GetKeyActions get user-defined key actions available for key type, or all actions if key type is empty
+func (p *preferencesService) GetKeyActions(keyType string) (resp types.JSResp) {
+	data := p.pref.GetPreferences()
+	actions := sliceutil.FilterMap(data.KeyActions, func(i int) (types.PreferencesKeyAction, bool) {
+		action := data.KeyActions[i]
+		if len(keyType) <= 0 || len(action.Types) <= 0 {
+			return action, true
+		}
+		return action, slices.ContainsFunc(action.Types, func(t string) bool {
+			return strings.EqualFold(t, keyType)
+		})
+	})
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"actions": actions,
+	}
+	return
+}
+
+// SaveKeyAction add key action, or replace the action named origName
+func (p *preferencesService) SaveKeyAction(origName string, action types.PreferencesKeyAction) (resp types.JSResp) {
+	action.Name = strings.TrimSpace(action.Name)
+	if len(action.Name) <= 0 || len(strings.TrimSpace(action.Script)) <= 0 {
+		resp.Msg = "name and script of key action are required"
+		return
+	}
+
+	actions := p.pref.GetPreferences().KeyActions
+	idx := -1
+	for i, a := range actions {
+		if a.Name == action.Name && a.Name != origName {
+			resp.Msg = "duplicated key action name \"" + action.Name + "\""
+			return
+		}
+		if len(origName) > 0 && a.Name == origName {
+			idx = i
+		}
+	}
+	if idx >= 0 {
+		actions[idx] = action
+	} else if len(origName) > 0 {
+		resp.Msg = "no key action named \"" + origName + "\""
+		return
+	} else {
+		actions = append(actions, action)
+	}
+	if err := p.pref.UpdatePreferences(map[string]any{"keyActions": actions}); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
+
+// DeleteKeyAction remove key action by name
+func (p *preferencesService) DeleteKeyAction(name string) (resp types.JSResp) {
+	actions := p.pref.GetPreferences().KeyActions
+	remain := slices.DeleteFunc(slices.Clone(actions), func(a types.PreferencesKeyAction) bool {
+		return a.Name == name
+	})
+	if len(remain) == len(actions) {
+		resp.Msg = "no key action named \"" + name + "\""
+		return
+	}
+	if err := p.pref.UpdatePreferences(map[string]any{"keyActions": remain}); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
+
+func (p *preferencesService) getKeyAction(name string) (types.PreferencesKeyAction, bool) {
+	data := p.pref.GetPreferences()
+	for _, action := range data.KeyActions {
+		if action.Name == name {
+			return action, true
+		}
+	}
+	return types.PreferencesKeyAction{}, false
+}
+
+// load protobuf schema from proto files in preferences, reload only if any file changed
+func (p *preferencesService) loadProtobufSchema() error {
+	data := p.pref.GetPreferences()
+	var sig strings.Builder
+	for _, file := range data.Protobuf.Files {
+		sig.WriteString(file)
+		if info, err := os.Stat(file); err == nil {
+			sig.WriteString(":" + strconv.FormatInt(info.ModTime().UnixNano(), 10))
+		}
+		sig.WriteString(";")
+	}
+
+	p.protoMutex.Lock()
+	defer p.protoMutex.Unlock()
+	if sig.String() == p.protoSignature {
+		return p.protoErr
+	}
+	p.protoSignature = sig.String()
+	schema, err := protoutil.LoadFiles(data.Protobuf.Files)
+	p.protoErr = err
+	convutil.SetProtobufSchema(schema)
+	return err
+}
+
+// GetKeyRules get rules of default decode type and format of keys, in order of matching
+func (p *preferencesService) GetKeyRules() (resp types.JSResp) {
+	resp.Success = true
+	resp.Data = map[string]any{
+		"rules": p.pref.GetPreferences().KeyRules,
+	}
+	return
+}
+
+// SetKeyRules replace rules of default decode type and format of keys, the first matched rule is applied
+func (p *preferencesService) SetKeyRules(rules []types.PreferencesKeyRule) (resp types.JSResp) {
+	for i := range rules {
+		if rules[i].Pattern = strings.TrimSpace(rules[i].Pattern); len(rules[i].Pattern) <= 0 {
+			resp.Msg = "pattern of key rule is required"
+			return
+		}
+	}
+	if rules == nil {
+		rules = []types.PreferencesKeyRule{}
+	}
+	if err := p.pref.UpdatePreferences(map[string]any{"keyRules": rules}); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
+
+// default decode type and format of key by the first matched rule, protobuf mappings are
+// regarded as rules of protobuf decoding, empty if no rule matched
+func (p *preferencesService) keyRuleFor(key string) (decode, format string) {
+	data := p.pref.GetPreferences()
+	for _, rule := range data.KeyRules {
+		if strutil.MatchGlob(rule.Pattern, key) {
+			decode, format = convutil.NormalizeDecode(rule.Decode), rule.Format
+			break
+		}
+	}
+	if len(decode) <= 0 {
+		for _, mapping := range data.Protobuf.Mappings {
+			if len(mapping.Message) > 0 && strutil.MatchGlob(mapping.Pattern, key) {
+				decode = types.DECODE_PROTOBUF + ":" + mapping.Message
+				break
+			}
+		}
+	}
+	if strings.Contains(decode, types.DECODE_PROTOBUF+":") {
+		p.loadProtobufSchema()
+	}
+	return
+}
+
+// GetAvroPreferences get schema registry of avro decoding
+func (p *preferencesService) GetAvroPreferences() (resp types.JSResp) {
+	resp.Success = true
+	resp.Data = p.pref.GetPreferences().Avro
+	return
+}
+
+// SetAvroPreferences replace schema registry of avro decoding, empty url to disable
+func (p *preferencesService) SetAvroPreferences(avro types.PreferencesAvro) (resp types.JSResp) {
+	avro.RegistryURL = strings.TrimSpace(avro.RegistryURL)
+	if err := p.pref.UpdatePreferences(map[string]any{"avro": avro}); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	p.updateAvroRegistry()
+	resp.Success = true
+	return
+}
+
+// update schema registry for avro decoding, the cached schemas are kept if registry not changed
+func (p *preferencesService) updateAvroRegistry() {
+	avro := p.pref.GetPreferences().Avro
+	sig := strings.Join([]string{avro.RegistryURL, avro.Username, avro.Password}, "\n")
+
+	p.avroMutex.Lock()
+	defer p.avroMutex.Unlock()
+	if sig == p.avroSignature {
+		return
+	}
+	p.avroSignature = sig
+	if len(avro.RegistryURL) <= 0 {
+		convutil.SetAvroRegistry(nil)
+		return
+	}
+	cacheDir := path.Join(userdir.GetConfigHome(), "TinyRDM", "schemas")
+	convutil.SetAvroRegistry(avroutil.NewRegistry(avro.RegistryURL, avro.Username, avro.Password, cacheDir))
+}
+
+// load wasm plugin decoders from plugins directory, reload only if any plugin changed
+func (p *preferencesService) loadPlugins() {
+	dir := path.Join(userdir.GetConfigHome(), "TinyRDM", "plugins")
+	var sig strings.Builder
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			if info, err := entry.Info(); err == nil && strings.HasSuffix(entry.Name(), ".wasm") {
+				sig.WriteString(entry.Name() + ":" + strconv.FormatInt(info.ModTime().UnixNano(), 10) + ";")
+			}
+		}
+	}
+
+	p.pluginMutex.Lock()
+	defer p.pluginMutex.Unlock()
+	if sig.String() == p.pluginSignature {
+		return
+	}
+	p.pluginSignature = sig.String()
+	plugins, err := convutil.LoadWasmPlugins(dir)
+	if err != nil {
+		log.Printf("load plugins fail: %s\n", err)
+	}
+	convutil.SetWasmPlugins(plugins)
+}
+
+// GetProtobufPreferences get proto files and key mappings of protobuf decoding
+func (p *preferencesService) GetProtobufPreferences() (resp types.JSResp) {
+	resp.Success = true
+	resp.Data = p.pref.GetPreferences().Protobuf
+	return
+}
+
+// SetProtobufPreferences replace proto files and key mappings of protobuf decoding,
+// saved even if the files can not be parsed, and the error is returned
+func (p *preferencesService) SetProtobufPreferences(pb types.PreferencesProtobuf) (resp types.JSResp) {
+	if pb.Files == nil {
+		pb.Files = []string{}
+	}
+	if pb.Mappings == nil {
+		pb.Mappings = []types.PreferencesProtobufMapping{}
+	}
+	if err := p.pref.UpdatePreferences(map[string]any{"protobuf": pb}); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if err := p.loadProtobufSchema(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
+
+// GetProtobufMessages get all message types in proto files of preferences
+func (p *preferencesService) GetProtobufMessages() (resp types.JSResp) {
+	if err := p.loadProtobufSchema(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	messages := []string{}
+	if schema := convutil.GetProtobufSchema(); schema != nil {
+		messages = schema.Messages()
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"messages": messages,
+	}
+	return
+}
+
 type sponsorItem struct {
 	Name   string   `json:"name"`
 	Link   string   `json:"link"`
@@ -282,4 +605,6 @@ func (p *preferencesService) UpdateEnv() {
 	} else {
 		os.Unsetenv("LANG")
 	}
+	p.updateAvroRegistry()
+	convutil.SetMaxDecodedSize(p.GetLargeValueSize())
 }
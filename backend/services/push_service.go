@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"strconv"
+	"sync"
+	"time"
+	"tinyrdm/backend/types"
+	sliceutil "tinyrdm/backend/utils/slice"
+)
+
+const invalidateChannel = "__redis__:invalidate"
+
+type pushItem struct {
+	client    *redis.Client
+	subClient *redis.Client // client which receive push messages
+	pubsub    *redis.PubSub
+	tracking  *redis.Conn // connection which enable client tracking
+	mutex     sync.Mutex
+	closeCh   chan struct{}
+	eventName string
+}
+
+type pushMessage struct {
+	Timestamp int64    `json:"timestamp"`
+	Kind      string   `json:"kind"` // "invalidate" or "message"
+	Channel   string   `json:"channel"`
+	Keys      []string `json:"keys,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+type pushService struct {
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	mutex     sync.Mutex
+	items     map[string]*pushItem
+}
+
+var push *pushService
+var oncePush sync.Once
+
+func Push() *pushService {
+	if push == nil {
+		oncePush.Do(func() {
+			push = &pushService{
+				items: map[string]*pushItem{},
+			}
+		})
+	}
+	return push
+}
+
+func (p *pushService) getItem(server string) (*pushItem, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	item, ok := p.items[server]
+	if !ok {
+		var err error
+		conf := Connection().getConnection(server)
+		if conf == nil {
+			return nil, fmt.Errorf("no connection profile named: %s", server)
+		}
+		var uniClient redis.UniversalClient
+		if uniClient, err = Connection().createRedisClient(conf.ConnectionConfig); err != nil {
+			return nil, err
+		}
+		var client *redis.Client
+		if client, ok = uniClient.(*redis.Client); !ok {
+			uniClient.Close()
+			return nil, errors.New("push messages is not supported in cluster mode")
+		}
+		item = &pushItem{
+			client: client,
+		}
+		p.items[server] = item
+	}
+	return item, nil
+}
+
+func (p *pushService) Start(ctx context.Context) {
+	p.ctx, p.ctxCancel = context.WithCancel(ctx)
+}
+
+// StartPush start to receive push messages, include key invalidation of client tracking
+// @param prefixes only track keys with these prefixes, track all keys if empty
+func (p *pushService) StartPush(server string, prefixes []string) (resp types.JSResp) {
+	// stop previous push of the server, or its subscription and tracking connection are leaked
+	p.StopPush(server)
+	item, err := p.getItem(server)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	// record the client id of subscribe connection, which is the redirect target of invalidation
+	var subClientID int64
+	subOption := *item.client.Options()
+	onConnect := subOption.OnConnect
+	subOption.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+		if onConnect != nil {
+			if err := onConnect(ctx, cn); err != nil {
+				return err
+			}
+		}
+		id, err := cn.ClientID(ctx).Result()
+		subClientID = id
+		return err
+	}
+	item.subClient = redis.NewClient(&subOption)
+	item.pubsub = item.subClient.Subscribe(p.ctx, invalidateChannel)
+	if _, err = item.pubsub.Receive(p.ctx); err != nil {
+		p.StopPush(server)
+		resp.Msg = err.Error()
+		return
+	}
+
+	// enable client tracking on a dedicated connection in broadcasting mode
+	args := []any{"CLIENT", "TRACKING", "ON", "REDIRECT", subClientID, "BCAST"}
+	for _, prefix := range prefixes {
+		if len(prefix) > 0 {
+			args = append(args, "PREFIX", prefix)
+		}
+	}
+	item.tracking = item.client.Conn()
+	if err = item.tracking.Do(p.ctx, args...).Err(); err != nil {
+		p.StopPush(server)
+		resp.Msg = err.Error()
+		return
+	}
+
+	item.closeCh = make(chan struct{})
+	item.eventName = "push:" + strconv.Itoa(int(time.Now().Unix()))
+	go p.processPush(&item.mutex, item.pubsub.Channel(), item.closeCh, item.eventName)
+	resp.Success = true
+	resp.Data = struct {
+		EventName string `json:"eventName"`
+	}{
+		EventName: item.eventName,
+	}
+	return
+}
+
+func (p *pushService) processPush(mutex *sync.Mutex, ch <-chan *redis.Message, closeCh <-chan struct{}, eventName string) {
+	cache := make([]pushMessage, 0, 1000)
+	ticker := time.NewTicker(300 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case data := <-ch:
+			go func() {
+				msg := pushMessage{
+					Timestamp: time.Now().UnixMilli(),
+					Kind:      "message",
+					Channel:   data.Channel,
+					Message:   data.Payload,
+				}
+				if data.Channel == invalidateChannel {
+					// null payload indicates the whole keyspace is flushed
+					msg.Kind = "invalidate"
+					msg.Keys = sliceutil.FilterMap(data.PayloadSlice, func(i int) (string, bool) {
+						return data.PayloadSlice[i], len(data.PayloadSlice[i]) > 0
+					})
+					if len(msg.Keys) <= 0 && len(data.Payload) > 0 {
+						msg.Keys = []string{data.Payload}
+					}
+					msg.Message = ""
+				}
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				cache = append(cache, msg)
+				if len(cache) > 300 {
+					runtime.EventsEmit(p.ctx, eventName, cache)
+					cache = cache[:0:cap(cache)]
+				}
+			}()
+
+		case <-ticker.C:
+			func() {
+				mutex.Lock()
+				defer mutex.Unlock()
+				if len(cache) > 0 {
+					runtime.EventsEmit(p.ctx, eventName, cache)
+					cache = cache[:0:cap(cache)]
+				}
+			}()
+
+		case <-closeCh:
+			// push stopped
+			return
+		}
+	}
+}
+
+// StopPush stop receiving push messages by server name
+func (p *pushService) StopPush(server string) (resp types.JSResp) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	item, ok := p.items[server]
+	if !ok {
+		resp.Success = true
+		return
+	}
+
+	if item.tracking != nil {
+		item.tracking.Close()
+	}
+	if item.pubsub != nil {
+		item.pubsub.Close()
+	}
+	if item.subClient != nil {
+		item.subClient.Close()
+	}
+	item.client.Close()
+	if item.closeCh != nil {
+		close(item.closeCh)
+	}
+	delete(p.items, server)
+	resp.Success = true
+	return
+}
+
+// StopAll stop all push listeners
+func (p *pushService) StopAll() {
+	if p.ctxCancel != nil {
+		p.ctxCancel()
+	}
+
+	for server := range p.items {
+		p.StopPush(server)
+	}
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tinyrdm/backend/types"
+	convutil "tinyrdm/backend/utils/convert"
+	rdbutil "tinyrdm/backend/utils/rdb"
+	strutil "tinyrdm/backend/utils/string"
+)
+
+type rdbFile struct {
+	aux  map[string]string
+	dbs  map[int]map[string]*rdbutil.Entry
+	keys map[int][]string // sorted keys of each database
+}
+
+type rdbService struct {
+	ctx   context.Context
+	mutex sync.Mutex
+	files map[string]*rdbFile
+}
+
+var rdb *rdbService
+var onceRDB sync.Once
+
+func RDB() *rdbService {
+	if rdb == nil {
+		onceRDB.Do(func() {
+			rdb = &rdbService{
+				files: map[string]*rdbFile{},
+			}
+		})
+	}
+	return rdb
+}
+
+func (r *rdbService) Start(ctx context.Context) {
+	r.ctx = ctx
+}
+
+func (r *rdbService) getFile(filepath string) (*rdbFile, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	file, ok := r.files[filepath]
+	if !ok {
+		return nil, fmt.Errorf("rdb file \"%s\" not opened", filepath)
+	}
+	return file, nil
+}
+
+// OpenRDBFile parse local rdb dump file and keep all keys in memory for browsing offline
+func (r *rdbService) OpenRDBFile(filepath string) (resp types.JSResp) {
+	file := &rdbFile{
+		aux:  map[string]string{},
+		dbs:  map[int]map[string]*rdbutil.Entry{},
+		keys: map[int][]string{},
+	}
+	err := rdbutil.ParseFile(filepath, func(entry *rdbutil.Entry) error {
+		db, ok := file.dbs[entry.DB]
+		if !ok {
+			db = map[string]*rdbutil.Entry{}
+			file.dbs[entry.DB] = db
+		}
+		db[entry.Key] = entry
+		return nil
+	}, func(key, val string) {
+		file.aux[key] = val
+	})
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	dbs := make([]types.ConnectionDB, 0, len(file.dbs))
+	for index, db := range file.dbs {
+		keys := make([]string, 0, len(db))
+		for key := range db {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		file.keys[index] = keys
+		dbs = append(dbs, types.ConnectionDB{
+			Name:    fmt.Sprintf("db%d", index),
+			Index:   index,
+			MaxKeys: len(keys),
+		})
+	}
+	slices.SortFunc(dbs, func(a, b types.ConnectionDB) int {
+		return a.Index - b.Index
+	})
+
+	r.mutex.Lock()
+	r.files[filepath] = file
+	r.mutex.Unlock()
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"name": path.Base(filepath),
+		"aux":  file.aux, // auxiliary fields like "redis-ver" and "ctime"
+		"db":   dbs,
+	}
+	return
+}
+
+// CloseRDBFile release parsed keys of rdb file
+func (r *rdbService) CloseRDBFile(filepath string) (resp types.JSResp) {
+	r.mutex.Lock()
+	delete(r.files, filepath)
+	r.mutex.Unlock()
+	resp.Success = true
+	return
+}
+
+// LoadRDBKeys load keys matching pattern in database of rdb file
+func (r *rdbService) LoadRDBKeys(filepath string, db int, match string) (resp types.JSResp) {
+	file, err := r.getFile(filepath)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if len(match) <= 0 {
+		match = "*"
+	}
+
+	now := time.Now().UnixMilli()
+	keys := make([]any, 0)
+	keyTypes := map[string]string{}
+	expired := 0
+	for _, key := range file.keys[db] {
+		if match != "*" {
+			if ok, _ := path.Match(match, key); !ok {
+				continue
+			}
+		}
+		entry := file.dbs[db][key]
+		if entry.ExpireAt > 0 && entry.ExpireAt <= now {
+			expired += 1
+		}
+		keys = append(keys, strutil.EncodeRedisKey(key))
+		keyTypes[key] = entry.Type
+	}
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"keys":    keys,
+		"types":   keyTypes,
+		"expired": expired, // count of keys already expired by now
+	}
+	return
+}
+
+// GetRDBKeySummary get type, ttl and length of key in rdb file, server of param is the path of rdb file
+func (r *rdbService) GetRDBKeySummary(param types.KeySummaryParam) (resp types.JSResp) {
+	file, err := r.getFile(param.Server)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	entry, ok := file.dbs[param.DB][strutil.DecodeRedisKey(param.Key)]
+	if !ok {
+		resp.Msg = "key not exists"
+		return
+	}
+
+	data := types.KeySummary{
+		Type: entry.Type,
+		TTL:  -1,
+	}
+	if entry.ExpireAt > 0 {
+		// remaining ttl since now, could be negative for keys expired after dumped
+		data.TTL = (entry.ExpireAt - time.Now().UnixMilli()) / 1000
+	}
+	switch val := entry.Value.(type) {
+	case string:
+		data.Length = int64(len(val))
+	case []string:
+		data.Length = int64(len(val))
+		if entry.Type == "hash" {
+			data.Length /= 2
+		}
+	case []rdbutil.ZSetMember:
+		data.Length = int64(len(val))
+	case []rdbutil.StreamEntry:
+		data.Length = int64(len(val))
+	}
+
+	resp.Success = true
+	resp.Data = data
+	return
+}
+
+// GetRDBKeyDetail get value of key in rdb file in the same format as GetKeyDetail,
+// server of param is the path of rdb file, all entries are loaded at once
+func (r *rdbService) GetRDBKeyDetail(param types.KeyDetailParam) (resp types.JSResp) {
+	file, err := r.getFile(param.Server)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	entry, ok := file.dbs[param.DB][strutil.DecodeRedisKey(param.Key)]
+	if !ok {
+		resp.Msg = "key not exists"
+		return
+	}
+
+	doConvert := (len(param.Decode) > 0 && param.Decode != types.DECODE_NONE) ||
+		(len(param.Format) > 0 && param.Format != types.FORMAT_RAW)
+	doFilter := len(param.MatchPattern) > 0 && param.MatchPattern != "*"
+	decoder := Preferences().GetDecoder()
+	convert := func(val string) string {
+		if doConvert {
+			if dv, _, _ := convutil.ConvertTo(val, param.Decode, param.Format, decoder); dv != val {
+				return dv
+			}
+		}
+		return ""
+	}
+
+	data := types.KeyDetail{
+		KeyType: entry.Type,
+		Reset:   true,
+		End:     true,
+		Match:   param.MatchPattern,
+		Decode:  param.Decode,
+		Format:  param.Format,
+	}
+	switch entry.Type {
+	case "string":
+		data.Value = strutil.EncodeRedisKey(entry.Value.(string))
+
+	case "list":
+		vals := entry.Value.([]string)
+		items := make([]types.ListEntryItem, 0, len(vals))
+		for _, val := range vals {
+			if doFilter && !strings.Contains(val, param.MatchPattern) {
+				continue
+			}
+			items = append(items, types.ListEntryItem{
+				Index:        len(items),
+				Value:        strutil.EncodeRedisKey(val),
+				DisplayValue: convert(val),
+			})
+		}
+		data.Value = items
+
+	case "hash":
+		vals := entry.Value.([]string)
+		items := make([]types.HashEntryItem, 0, len(vals)/2)
+		for i := 0; i+1 < len(vals); i += 2 {
+			if doFilter && !strings.Contains(vals[i], param.MatchPattern) {
+				continue
+			}
+			items = append(items, types.HashEntryItem{
+				Key:          vals[i],
+				Value:        strutil.EncodeRedisKey(vals[i+1]),
+				DisplayValue: convert(vals[i+1]),
+			})
+		}
+		data.Value = items
+
+	case "set":
+		vals := entry.Value.([]string)
+		items := make([]types.SetEntryItem, 0, len(vals))
+		for _, val := range vals {
+			if doFilter && !strings.Contains(val, param.MatchPattern) {
+				continue
+			}
+			items = append(items, types.SetEntryItem{
+				Value:        strutil.EncodeRedisKey(val),
+				DisplayValue: convert(val),
+			})
+		}
+		data.Value = items
+
+	case "zset":
+		members := entry.Value.([]rdbutil.ZSetMember)
+		items := make([]types.ZSetEntryItem, 0, len(members))
+		for _, m := range members {
+			if doFilter && !strings.Contains(m.Member, param.MatchPattern) {
+				continue
+			}
+			items = append(items, types.ZSetEntryItem{
+				Score:        m.Score,
+				ScoreStr:     strconv.FormatFloat(m.Score, 'f', -1, 64),
+				Value:        strutil.EncodeRedisKey(m.Member),
+				DisplayValue: convert(m.Member),
+			})
+		}
+		data.Value = items
+
+	case "stream":
+		entries := entry.Value.([]rdbutil.StreamEntry)
+		items := make([]types.StreamEntryItem, 0, len(entries))
+		// the latest entry comes first, same as XREVRANGE
+		for _, e := range slices.Backward(entries) {
+			values := make(map[string]any, len(e.Fields)/2)
+			for i := 0; i+1 < len(e.Fields); i += 2 {
+				values[e.Fields[i]] = e.Fields[i+1]
+			}
+			it := types.StreamEntryItem{
+				ID:    e.ID,
+				Value: values,
+			}
+			if vb, merr := json.Marshal(values); merr != nil {
+				it.DisplayValue = "{}"
+			} else {
+				it.DisplayValue, _, _ = convutil.ConvertTo(string(vb), types.DECODE_NONE, types.FORMAT_JSON, decoder)
+			}
+			if doFilter && !strings.Contains(it.DisplayValue, param.MatchPattern) {
+				continue
+			}
+			items = append(items, it)
+		}
+		data.Value = items
+
+	default:
+		// module types, content is not parsed
+		data.Value = nil
+	}
+
+	resp.Success = true
+	resp.Data = data
+	return
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"tinyrdm/backend/types"
+	redis2 "tinyrdm/backend/utils/redis"
+)
+
+type searchService struct {
+	ctx context.Context
+}
+
+var search *searchService
+var onceSearch sync.Once
+
+func Search() *searchService {
+	if search == nil {
+		onceSearch.Do(func() {
+			search = &searchService{}
+		})
+	}
+	return search
+}
+
+func (s *searchService) Start(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// ListSearchIndexes list all full-text indexes by "FT._LIST"
+func (s *searchService) ListSearchIndexes(server string, db int) (resp types.JSResp) {
+	item, err := Browser().getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	res, err := client.Do(ctx, "FT._LIST").Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	// reply is an array in RESP2, or a set in RESP3
+	indexes := []string{}
+	switch val := res.(type) {
+	case []any:
+		for _, idx := range val {
+			indexes = append(indexes, fmt.Sprint(idx))
+		}
+	case map[any]bool:
+		for idx := range val {
+			indexes = append(indexes, fmt.Sprint(idx))
+		}
+	}
+	slices.Sort(indexes)
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"indexes": indexes,
+	}
+	return
+}
+
+// GetSearchIndexInfo get definition, schema and statistics of index by "FT.INFO"
+func (s *searchService) GetSearchIndexInfo(server string, db int, index string) (resp types.JSResp) {
+	item, err := Browser().getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	client, ctx := item.client, item.ctx
+	res, err := client.Do(ctx, "FT.INFO", index).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	resp.Data = redis2.ReplyToMap(res)
+	return
+}
+
+// SearchIndex query index by "FT.SEARCH" with pagination
+func (s *searchService) SearchIndex(param types.SearchQueryParam) (resp types.JSResp) {
+	item, err := Browser().getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	query := strings.TrimSpace(param.Query)
+	if len(query) <= 0 {
+		query = "*"
+	}
+	limit := param.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	offset := max(param.Offset, 0)
+	args := []any{"FT.SEARCH", param.Index, query, "WITHSCORES"}
+	if len(param.Return) > 0 {
+		args = append(args, "RETURN", len(param.Return))
+		for _, field := range param.Return {
+			args = append(args, field)
+		}
+	}
+	if len(param.SortBy) > 0 {
+		order := "ASC"
+		if param.SortDesc {
+			order = "DESC"
+		}
+		args = append(args, "SORTBY", param.SortBy, order)
+	}
+	args = append(args, "LIMIT", offset, limit)
+
+	client, ctx := item.client, item.ctx
+	res, err := client.Do(ctx, args...).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	total, docs := redis2.ParseSearchReply(res, true)
+	resp.Success = true
+	resp.Data = types.SearchResult{
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+		Docs:   docs,
+	}
+	return
+}
+
+// AggregateIndex run aggregation pipeline on index by "FT.AGGREGATE" with pagination
+func (s *searchService) AggregateIndex(param types.SearchAggregateParam) (resp types.JSResp) {
+	item, err := Browser().getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	query := strings.TrimSpace(param.Query)
+	if len(query) <= 0 {
+		query = "*"
+	}
+	limit := param.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	offset := max(param.Offset, 0)
+	args := []any{"FT.AGGREGATE", param.Index, query}
+	for _, arg := range param.Args {
+		if strings.EqualFold(arg, "WITHCURSOR") {
+			resp.Msg = "cursor is not supported, use offset and limit instead"
+			return
+		}
+		args = append(args, arg)
+	}
+	args = append(args, "LIMIT", offset, limit)
+
+	client, ctx := item.client, item.ctx
+	res, err := client.Do(ctx, args...).Result()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	total, rows := redis2.ParseAggregateReply(res)
+	resp.Success = true
+	resp.Data = types.SearchAggregateResult{
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+		Rows:   rows,
+	}
+	return
+}
+
+// CreateSearchIndex create index by "FT.CREATE"
+func (s *searchService) CreateSearchIndex(param types.SearchIndexParam) (resp types.JSResp) {
+	if len(param.Index) <= 0 {
+		resp.Msg = "index name is required"
+		return
+	}
+	if len(param.Schema) <= 0 {
+		resp.Msg = "at least one field is required in schema"
+		return
+	}
+	on := strings.ToUpper(param.On)
+	if len(on) <= 0 {
+		on = "HASH"
+	} else if on != "HASH" && on != "JSON" {
+		resp.Msg = "invalid index data type: " + param.On
+		return
+	}
+
+	args := []any{"FT.CREATE", param.Index, "ON", on}
+	if len(param.Prefixes) > 0 {
+		args = append(args, "PREFIX", len(param.Prefixes))
+		for _, prefix := range param.Prefixes {
+			args = append(args, prefix)
+		}
+	}
+	args = append(args, "SCHEMA")
+	for _, field := range param.Schema {
+		fieldType := strings.ToUpper(field.Type)
+		switch fieldType {
+		case "TEXT", "NUMERIC", "TAG", "GEO":
+		default:
+			resp.Msg = fmt.Sprintf("unsupported type \"%s\" of field \"%s\"", field.Type, field.Name)
+			return
+		}
+		args = append(args, field.Name)
+		if len(field.Alias) > 0 {
+			args = append(args, "AS", field.Alias)
+		} else if on == "JSON" {
+			resp.Msg = fmt.Sprintf("alias of JSON path \"%s\" is required", field.Name)
+			return
+		}
+		args = append(args, fieldType)
+		if field.Sortable {
+			args = append(args, "SORTABLE")
+		}
+	}
+
+	item, err := Browser().getRedisClient(param.Server, param.DB)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	client, ctx := item.client, item.ctx
+	if err = client.Do(ctx, args...).Err(); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
+
+// DropSearchIndex drop index by "FT.DROPINDEX"
+// @param deleteDocs delete indexed documents as well
+func (s *searchService) DropSearchIndex(server string, db int, index string, deleteDocs bool) (resp types.JSResp) {
+	item, err := Browser().getRedisClient(server, db)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	args := []any{"FT.DROPINDEX", index}
+	if deleteDocs {
+		args = append(args, "DD")
+	}
+	client, ctx := item.client, item.ctx
+	if err = client.Do(ctx, args...).Err(); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "unknown index") {
+			err = errors.New("no index named \"" + index + "\"")
+		}
+		resp.Msg = err.Error()
+		return
+	}
+
+	resp.Success = true
+	return
+}
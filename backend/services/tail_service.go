@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+	"tinyrdm/backend/types"
+	convutil "tinyrdm/backend/utils/convert"
+	strutil "tinyrdm/backend/utils/string"
+)
+
+type tailItem struct {
+	client    redis.UniversalClient
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	key       string
+	lastID    string
+	decode    string
+	format    string
+	paused    bool
+	resumeCh  chan struct{}
+	mutex     sync.Mutex
+	eventName string
+}
+
+type tailService struct {
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	mutex     sync.Mutex
+	items     map[string]*tailItem
+}
+
+var tail *tailService
+var onceTail sync.Once
+
+func Tail() *tailService {
+	if tail == nil {
+		onceTail.Do(func() {
+			tail = &tailService{
+				items: map[string]*tailItem{},
+			}
+		})
+	}
+	return tail
+}
+
+func (t *tailService) Start(ctx context.Context) {
+	t.ctx, t.ctxCancel = context.WithCancel(ctx)
+}
+
+// StartTailStream follow new entries of stream by "XREAD BLOCK", entries are pushed by event
+// each field value is converted by specified decode and format type
+func (t *tailService) StartTailStream(param types.StreamTailParam) (resp types.JSResp) {
+	conf := Connection().getConnection(param.Server)
+	if conf == nil {
+		resp.Msg = fmt.Sprintf("no connection profile named: %s", param.Server)
+		return
+	}
+	// blocking read occupies the connection, use a dedicated client
+	connConfig := conf.ConnectionConfig
+	connConfig.LastDB = param.DB
+	client, err := Connection().createRedisClient(connConfig)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	key := strutil.DecodeRedisKey(param.Key)
+	start := param.Start
+	if len(start) <= 0 || start == "$" {
+		// resolve "$" to id of the last entry, otherwise entries appended while paused are skipped after resume
+		var msgs []redis.XMessage
+		if msgs, err = client.XRevRangeN(t.ctx, key, "+", "-", 1).Result(); err != nil {
+			client.Close()
+			resp.Msg = err.Error()
+			return
+		}
+		if len(msgs) > 0 {
+			start = msgs[0].ID
+		} else {
+			start = "0-0"
+		}
+	}
+	item := &tailItem{
+		client:    client,
+		key:       key,
+		lastID:    start,
+		decode:    param.Decode,
+		format:    param.Format,
+		resumeCh:  make(chan struct{}),
+		eventName: "tail:" + strconv.FormatInt(time.Now().UnixNano(), 10),
+	}
+	item.ctx, item.ctxCancel = context.WithCancel(t.ctx)
+
+	t.mutex.Lock()
+	t.items[item.eventName] = item
+	t.mutex.Unlock()
+
+	go t.processTail(item)
+	resp.Success = true
+	resp.Data = struct {
+		EventName string `json:"eventName"`
+	}{
+		EventName: item.eventName,
+	}
+	return
+}
+
+func (t *tailService) processTail(item *tailItem) {
+	defer func() {
+		// panic in goroutine would crash the whole app
+		if r := recover(); r != nil {
+			log.Printf("tail stream %s panic: %v\n", item.key, r)
+			runtime.EventsEmit(t.ctx, item.eventName, map[string]any{
+				"error": fmt.Sprint(r),
+			})
+		}
+	}()
+	// retry with backoff on persistent error, and give up after failing several times in a row
+	const maxFailures = 10
+	const maxBackoff = 30 * time.Second
+	var failures int
+	decoder := Preferences().GetDecoder()
+	for {
+		item.mutex.Lock()
+		paused, resumeCh := item.paused, item.resumeCh
+		item.mutex.Unlock()
+		if paused {
+			// stop reading until resumed, entries appended meanwhile will be read after resume
+			select {
+			case <-resumeCh:
+			case <-item.ctx.Done():
+				return
+			}
+		}
+
+		streams, err := item.client.XRead(item.ctx, &redis.XReadArgs{
+			Streams: []string{item.key, item.lastID},
+			Count:   500,
+			Block:   time.Second,
+		}).Result()
+		if item.ctx.Err() != nil {
+			// tail stopped
+			return
+		}
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				// no new entry
+				failures = 0
+				continue
+			}
+			failures += 1
+			if failures >= maxFailures {
+				runtime.EventsEmit(t.ctx, item.eventName, map[string]any{
+					"error":   err.Error(),
+					"stopped": true,
+				})
+				t.StopTailStream(item.eventName)
+				return
+			}
+			runtime.EventsEmit(t.ctx, item.eventName, map[string]any{
+				"error": err.Error(),
+			})
+			select {
+			case <-time.After(min(time.Second<<(failures-1), maxBackoff)):
+			case <-item.ctx.Done():
+				return
+			}
+			continue
+		}
+		failures = 0
+
+		for _, stream := range streams {
+			if len(stream.Messages) <= 0 {
+				continue
+			}
+			entries := make([]types.StreamEntryItem, 0, len(stream.Messages))
+			for _, msg := range stream.Messages {
+				entries = append(entries, t.convertEntry(msg, item.decode, item.format, decoder))
+			}
+			item.lastID = stream.Messages[len(stream.Messages)-1].ID
+			runtime.EventsEmit(t.ctx, item.eventName, map[string]any{
+				"entries": entries,
+			})
+		}
+	}
+}
+
+// convert each field value of entry to display value
+func (t *tailService) convertEntry(msg redis.XMessage, decode, format string, decoder []convutil.CmdConvert) types.StreamEntryItem {
+	it := types.StreamEntryItem{
+		ID:    msg.ID,
+		Value: msg.Values,
+	}
+	values := make(map[string]string, len(msg.Values))
+	for field, val := range msg.Values {
+		values[field], _, _ = convutil.ConvertTo(fmt.Sprint(val), decode, format, decoder)
+	}
+	if vb, err := json.Marshal(values); err != nil {
+		it.DisplayValue = "{}"
+	} else {
+		it.DisplayValue = string(vb)
+	}
+	return it
+}
+
+// PauseTailStream stop pushing new entries temporarily
+func (t *tailService) PauseTailStream(eventName string) (resp types.JSResp) {
+	t.mutex.Lock()
+	item, ok := t.items[eventName]
+	t.mutex.Unlock()
+	if !ok {
+		resp.Msg = "tail not found"
+		return
+	}
+
+	item.mutex.Lock()
+	if !item.paused {
+		item.paused = true
+		item.resumeCh = make(chan struct{})
+	}
+	item.mutex.Unlock()
+	resp.Success = true
+	return
+}
+
+// ResumeTailStream continue pushing entries from the last received one
+func (t *tailService) ResumeTailStream(eventName string) (resp types.JSResp) {
+	t.mutex.Lock()
+	item, ok := t.items[eventName]
+	t.mutex.Unlock()
+	if !ok {
+		resp.Msg = "tail not found"
+		return
+	}
+
+	item.mutex.Lock()
+	if item.paused {
+		item.paused = false
+		close(item.resumeCh)
+	}
+	item.mutex.Unlock()
+	resp.Success = true
+	return
+}
+
+// StopTailStream stop following stream
+func (t *tailService) StopTailStream(eventName string) (resp types.JSResp) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	item, ok := t.items[eventName]
+	if !ok {
+		resp.Success = true
+		return
+	}
+
+	item.ctxCancel()
+	item.client.Close()
+	delete(t.items, eventName)
+	resp.Success = true
+	return
+}
+
+// StopAll stop all tails
+func (t *tailService) StopAll() {
+	if t.ctxCancel != nil {
+		t.ctxCancel()
+	}
+
+	for eventName := range t.items {
+		t.StopTailStream(eventName)
+	}
+}
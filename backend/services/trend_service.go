@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	. "tinyrdm/backend/storage"
+	"tinyrdm/backend/types"
+)
+
+type trendService struct {
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	mutex     sync.Mutex
+	storage   *KeyspaceTrendStorage
+	recorded  map[string]map[int]struct{} // databases with keys in last round of each server
+	stopCh    chan struct{}
+}
+
+var trend *trendService
+var onceTrend sync.Once
+
+func Trend() *trendService {
+	if trend == nil {
+		onceTrend.Do(func() {
+			trend = &trendService{
+				storage:  NewKeyspaceTrend(),
+				recorded: map[string]map[int]struct{}{},
+			}
+		})
+	}
+	return trend
+}
+
+func (t *trendService) Start(ctx context.Context) {
+	t.ctx, t.ctxCancel = context.WithCancel(ctx)
+}
+
+// StartKeyspaceTrend start to record size of databases of all opened connections periodically in background,
+// samples of each round will be emitted by event "keyspace_trend:sample"
+// @param interval record interval in seconds
+func (t *trendService) StartKeyspaceTrend(interval int) (resp types.JSResp) {
+	if interval <= 0 {
+		resp.Msg = "invalid record interval"
+		return
+	}
+
+	t.StopKeyspaceTrend()
+	t.mutex.Lock()
+	stopCh := make(chan struct{})
+	t.stopCh = stopCh
+	t.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			if samples := t.recordAll(); len(samples) > 0 {
+				runtime.EventsEmit(t.ctx, "keyspace_trend:sample", samples)
+			}
+			select {
+			case <-ticker.C:
+			case <-stopCh:
+				return
+			case <-t.ctx.Done():
+				return
+			}
+		}
+	}()
+	resp.Success = true
+	return
+}
+
+// StopKeyspaceTrend stop recording in background, recorded samples are kept
+func (t *trendService) StopKeyspaceTrend() (resp types.JSResp) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.stopCh != nil {
+		close(t.stopCh)
+		t.stopCh = nil
+	}
+	resp.Success = true
+	return
+}
+
+// GetKeyspaceTrend get recorded samples in chronological order, grouped by database
+func (t *trendService) GetKeyspaceTrend(query types.KeyspaceTrendQuery) (resp types.JSResp) {
+	samples, err := t.storage.Query(query)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	series := map[int][]types.KeyspaceSample{}
+	for _, sample := range samples {
+		series[sample.DB] = append(series[sample.DB], sample)
+	}
+	if query.MaxPoints > 0 {
+		for db, list := range series {
+			series[db] = downsampleKeyspace(list, query.MaxPoints)
+		}
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"series": series,
+	}
+	return
+}
+
+// ClearKeyspaceTrend remove recorded samples of server, or all samples if server is empty
+func (t *trendService) ClearKeyspaceTrend(server string) (resp types.JSResp) {
+	if err := t.storage.Clear(server); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	return
+}
+
+// StopAll stop recording
+func (t *trendService) StopAll() {
+	if t.ctxCancel != nil {
+		t.ctxCancel()
+	}
+	t.StopKeyspaceTrend()
+}
+
+// keep samples evenly spaced in list, and always keep the latest one
+func downsampleKeyspace(samples []types.KeyspaceSample, maxPoints int) []types.KeyspaceSample {
+	if len(samples) <= maxPoints {
+		return samples
+	}
+	ret := make([]types.KeyspaceSample, 0, maxPoints)
+	step := float64(len(samples)-1) / float64(max(maxPoints-1, 1))
+	for i := 0; i < maxPoints-1; i++ {
+		ret = append(ret, samples[int(float64(i)*step)])
+	}
+	return append(ret, samples[len(samples)-1])
+}
+
+// recordAll record all opened connections, sessions of the same connection are recorded once
+func (t *trendService) recordAll() []types.KeyspaceSample {
+	b := Browser()
+	clients := map[string]redis.UniversalClient{}
+	b.mutex.Lock()
+	for server, item := range b.connMap {
+		name, _ := splitSession(server)
+		if _, exists := clients[name]; !exists && item.client != nil {
+			clients[name] = item.client
+		}
+	}
+	b.mutex.Unlock()
+
+	now := time.Now().UnixMilli()
+	var samples []types.KeyspaceSample
+	for name, client := range clients {
+		ctx, cancel := context.WithTimeout(t.ctx, 10*time.Second)
+		list, err := t.record(ctx, client)
+		cancel()
+		if err != nil {
+			log.Printf("record keyspace of %s fail: %v\n", name, err)
+			continue
+		}
+
+		// databases became empty are absent in keyspace
+		t.mutex.Lock()
+		dbs := map[int]struct{}{}
+		for i := range list {
+			list[i].Timestamp, list[i].Server = now, name
+			dbs[list[i].DB] = struct{}{}
+		}
+		for db := range t.recorded[name] {
+			if _, ok := dbs[db]; !ok {
+				list = append(list, types.KeyspaceSample{Timestamp: now, Server: name, DB: db})
+			}
+		}
+		t.recorded[name] = dbs
+		t.mutex.Unlock()
+		samples = append(samples, list...)
+	}
+
+	if err := t.storage.Append(samples...); err != nil {
+		log.Println("write keyspace trend fail:", err)
+	}
+	return samples
+}
+
+// record size of databases by INFO keyspace, which has the same key count as DBSIZE of each database
+// without switching, the key count of cluster is summed by DBSIZE of all masters
+func (t *trendService) record(ctx context.Context, client redis.UniversalClient) ([]types.KeyspaceSample, error) {
+	b := Browser()
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		var mutex sync.Mutex
+		sample := types.KeyspaceSample{}
+		var ttlSum int64
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
+			size, err := cli.DBSize(ctx).Result()
+			if err != nil {
+				return err
+			}
+			var dbInfo map[string]int
+			if res, err := cli.Info(ctx, "keyspace").Result(); err == nil {
+				dbInfo = b.parseDBItemInfo(b.parseInfo(res)["Keyspace"]["db0"])
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			sample.Keys += size
+			sample.Expires += int64(dbInfo["expires"])
+			ttlSum += int64(dbInfo["avg_ttl"]) * int64(dbInfo["expires"])
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if sample.Expires > 0 {
+			sample.AvgTTL = ttlSum / sample.Expires
+		}
+		return []types.KeyspaceSample{sample}, nil
+	}
+
+	res, err := client.Info(ctx, "keyspace").Result()
+	if err != nil {
+		return nil, err
+	}
+	var samples []types.KeyspaceSample
+	for dbName, dbInfoStr := range b.parseInfo(res)["Keyspace"] {
+		db, err := strconv.Atoi(strings.TrimPrefix(dbName, "db"))
+		if err != nil {
+			continue
+		}
+		dbInfo := b.parseDBItemInfo(dbInfoStr)
+		samples = append(samples, types.KeyspaceSample{
+			DB:      db,
+			Keys:    int64(dbInfo["keys"]),
+			Expires: int64(dbInfo["expires"]),
+			AvgTTL:  int64(dbInfo["avg_ttl"]),
+		})
+	}
+	return samples, nil
+}
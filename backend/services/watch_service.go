@@ -0,0 +1,584 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tinyrdm/backend/types"
+	strutil "tinyrdm/backend/utils/string"
+)
+
+// max entries of key could be watched, the whole value is read on every refresh
+const maxSnapshotEntries = 10000
+
+// value of key at some point, entries of hash, set, zset and stream are kept in map,
+// members of set map to empty string, members of zset map to score
+type keySnapshot struct {
+	Type    string
+	TTL     int64
+	Value   string
+	List    []string
+	Entries map[string]string
+}
+
+// read whole value of key, stream entries are formatted as field value pairs
+func snapshotKey(ctx context.Context, client redis.UniversalClient, key string) (snap keySnapshot, err error) {
+	if snap.Type, err = client.Type(ctx, key).Result(); err != nil {
+		return
+	}
+	if snap.Type == "none" {
+		return
+	}
+	var size int64
+	switch snap.Type {
+	case "list":
+		size, err = client.LLen(ctx, key).Result()
+	case "hash":
+		size, err = client.HLen(ctx, key).Result()
+	case "set":
+		size, err = client.SCard(ctx, key).Result()
+	case "zset":
+		size, err = client.ZCard(ctx, key).Result()
+	case "stream":
+		size, err = client.XLen(ctx, key).Result()
+	}
+	if err != nil {
+		return
+	}
+	if size > maxSnapshotEntries {
+		err = fmt.Errorf("key has more than %d entries", maxSnapshotEntries)
+		return
+	}
+
+	if ttl, terr := client.PTTL(ctx, key).Result(); terr == nil {
+		snap.TTL = ttl.Milliseconds()
+		if snap.TTL < 0 {
+			snap.TTL = -1
+		}
+	}
+	switch snap.Type {
+	case "string":
+		snap.Value, err = client.Get(ctx, key).Result()
+	case "list":
+		snap.List, err = client.LRange(ctx, key, 0, -1).Result()
+	case "hash":
+		snap.Entries, err = client.HGetAll(ctx, key).Result()
+	case "set":
+		var members []string
+		if members, err = client.SMembers(ctx, key).Result(); err == nil {
+			snap.Entries = make(map[string]string, len(members))
+			for _, member := range members {
+				snap.Entries[member] = ""
+			}
+		}
+	case "zset":
+		var members []redis.Z
+		if members, err = client.ZRangeWithScores(ctx, key, 0, -1).Result(); err == nil {
+			snap.Entries = make(map[string]string, len(members))
+			for _, z := range members {
+				snap.Entries[fmt.Sprint(z.Member)] = strconv.FormatFloat(z.Score, 'f', -1, 64)
+			}
+		}
+	case "stream":
+		var msgs []redis.XMessage
+		if msgs, err = client.XRange(ctx, key, "-", "+").Result(); err == nil {
+			snap.Entries = make(map[string]string, len(msgs))
+			for _, msg := range msgs {
+				fields := make([]string, 0, len(msg.Values))
+				for field, val := range msg.Values {
+					fields = append(fields, field+"="+fmt.Sprint(val))
+				}
+				slices.Sort(fields)
+				snap.Entries[msg.ID] = strings.Join(fields, " ")
+			}
+		}
+	case "ReJSON-RL":
+		snap.Value, err = client.JSONGet(ctx, key).Result()
+	default:
+		err = errors.New("unsupported key type: " + snap.Type)
+	}
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+	return
+}
+
+// compare two snapshots of key
+func diffSnapshot(prev, cur keySnapshot) types.KeyDiff {
+	diff := types.KeyDiff{
+		Type: strings.ToLower(cur.Type),
+	}
+	if prev.Type != cur.Type {
+		diff.OldType = strings.ToLower(prev.Type)
+		// type changed, compare with empty value
+		prev = keySnapshot{}
+	}
+
+	switch {
+	case cur.Entries != nil || prev.Entries != nil:
+		for field, val := range cur.Entries {
+			if old, ok := prev.Entries[field]; !ok {
+				diff.Added = append(diff.Added, types.DiffEntry{Field: field, New: strutil.EncodeRedisKey(val)})
+			} else if old != val {
+				diff.Changed = append(diff.Changed, types.DiffEntry{
+					Field: field,
+					Old:   strutil.EncodeRedisKey(old),
+					New:   strutil.EncodeRedisKey(val),
+				})
+			}
+		}
+		for field, old := range prev.Entries {
+			if _, ok := cur.Entries[field]; !ok {
+				diff.Removed = append(diff.Removed, types.DiffEntry{Field: field, Old: strutil.EncodeRedisKey(old)})
+			}
+		}
+		sortDiffEntries(diff.Added)
+		sortDiffEntries(diff.Removed)
+		sortDiffEntries(diff.Changed)
+
+	case cur.List != nil || prev.List != nil:
+		// compare by index
+		for i := 0; i < max(len(prev.List), len(cur.List)); i++ {
+			field := strconv.Itoa(i)
+			switch {
+			case i >= len(prev.List):
+				diff.Added = append(diff.Added, types.DiffEntry{Field: field, New: strutil.EncodeRedisKey(cur.List[i])})
+			case i >= len(cur.List):
+				diff.Removed = append(diff.Removed, types.DiffEntry{Field: field, Old: strutil.EncodeRedisKey(prev.List[i])})
+			case prev.List[i] != cur.List[i]:
+				diff.Changed = append(diff.Changed, types.DiffEntry{
+					Field: field,
+					Old:   strutil.EncodeRedisKey(prev.List[i]),
+					New:   strutil.EncodeRedisKey(cur.List[i]),
+				})
+			}
+		}
+
+	case prev.Value != cur.Value:
+		diff.Changed = []types.DiffEntry{{
+			Old: strutil.EncodeRedisKey(prev.Value),
+			New: strutil.EncodeRedisKey(cur.Value),
+		}}
+	}
+	return diff
+}
+
+// max lines of each side to make line diff, which costs O(m*n)
+const maxDiffLines = 2000
+
+// make line diff by longest common subsequence, return nil if too many lines
+func diffLines(prev, cur string) []types.DiffLine {
+	a, b := strings.Split(prev, "\n"), strings.Split(cur, "\n")
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return nil
+	}
+	// lcs[i][j] is the length of lcs of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]types.DiffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, types.DiffLine{Op: " ", Line: strutil.EncodeRedisKey(a[i])})
+			i, j = i+1, j+1
+		case j < len(b) && (i >= len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			lines = append(lines, types.DiffLine{Op: "+", Line: strutil.EncodeRedisKey(b[j])})
+			j++
+		default:
+			lines = append(lines, types.DiffLine{Op: "-", Line: strutil.EncodeRedisKey(a[i])})
+			i++
+		}
+	}
+	return lines
+}
+
+func sortDiffEntries(entries []types.DiffEntry) {
+	slices.SortFunc(entries, func(a, b types.DiffEntry) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+}
+
+type watchItem struct {
+	client    redis.UniversalClient
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	key       string
+	db        int
+	interval  time.Duration
+	eventName string
+}
+
+type snapshotItem struct {
+	server    string
+	db        int
+	key       string
+	snapshot  keySnapshot
+	timestamp int64
+}
+
+type watchService struct {
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	mutex     sync.Mutex
+	items     map[string]*watchItem
+	snapshots map[string]*snapshotItem // captured snapshots of key by id
+}
+
+// max snapshots kept in memory, the oldest one is dropped if exceeded
+const maxSnapshots = 50
+
+var watch *watchService
+var onceWatch sync.Once
+
+func Watch() *watchService {
+	if watch == nil {
+		onceWatch.Do(func() {
+			watch = &watchService{
+				items:     map[string]*watchItem{},
+				snapshots: map[string]*snapshotItem{},
+			}
+		})
+	}
+	return watch
+}
+
+func (w *watchService) Start(ctx context.Context) {
+	w.ctx, w.ctxCancel = context.WithCancel(ctx)
+}
+
+// StartWatchKey re-read value of key when it is modified and push the diff by event,
+// keyspace notification is used if enabled on server, otherwise poll by interval
+func (w *watchService) StartWatchKey(param types.KeyWatchParam) (resp types.JSResp) {
+	conf := Connection().getConnection(param.Server)
+	if conf == nil {
+		resp.Msg = fmt.Sprintf("no connection profile named: %s", param.Server)
+		return
+	}
+	// subscribing occupies the connection, use a dedicated client
+	connConfig := conf.ConnectionConfig
+	connConfig.LastDB = param.DB
+	client, err := Connection().createRedisClient(connConfig)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	interval := time.Duration(param.Interval) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Second
+	} else if interval < 200*time.Millisecond {
+		interval = 200 * time.Millisecond
+	}
+	item := &watchItem{
+		client:    client,
+		key:       strutil.DecodeRedisKey(param.Key),
+		db:        param.DB,
+		interval:  interval,
+		eventName: "watch:" + strconv.FormatInt(time.Now().UnixNano(), 10),
+	}
+	item.ctx, item.ctxCancel = context.WithCancel(w.ctx)
+
+	snap, err := snapshotKey(item.ctx, client, item.key)
+	if err != nil {
+		item.ctxCancel()
+		client.Close()
+		resp.Msg = err.Error()
+		return
+	}
+
+	// notifications are published by the node holding the key, not available in cluster mode
+	var notify bool
+	if !conf.Cluster.Enable {
+		if res, err := client.ConfigGet(item.ctx, "notify-keyspace-events").Result(); err == nil {
+			// all required classes are enabled if nothing to merge
+			flags := res["notify-keyspace-events"]
+			notify = mergeNotifyFlags(flags, notifyClassesOf(snap.Type)) == flags
+		}
+	}
+
+	w.mutex.Lock()
+	w.items[item.eventName] = item
+	w.mutex.Unlock()
+
+	go w.processWatch(item, snap, notify)
+	resp.Success = true
+	resp.Data = map[string]any{
+		"eventName": item.eventName,
+		"notify":    notify, // refreshed by keyspace notification, or by polling
+	}
+	return
+}
+
+// notification classes required to watch key of type, generic, expired and evicted events are always required
+func notifyClassesOf(keyType string) string {
+	switch strings.ToLower(keyType) {
+	case "string":
+		return "gxe$"
+	case "list":
+		return "gxel"
+	case "set":
+		return "gxes"
+	case "hash":
+		return "gxeh"
+	case "zset":
+		return "gxez"
+	case "stream":
+		return "gxet"
+	default:
+		// key not exists yet, may be created in any type
+		return "g$lshzxet"
+	}
+}
+
+func (w *watchService) processWatch(item *watchItem, snap keySnapshot, notify bool) {
+	var notifyCh <-chan *redis.Message
+	if notify {
+		// only subscribe channel of the key exactly
+		pubsub := item.client.Subscribe(item.ctx, fmt.Sprintf("__keyspace@%d__:%s", item.db, item.key))
+		defer pubsub.Close()
+		notifyCh = pubsub.Channel()
+	}
+	snapTime := time.Now()
+	ticker := time.NewTicker(item.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-item.ctx.Done():
+			// watching stopped
+			return
+		case <-notifyCh:
+			// merge notifications in a short time
+			time.Sleep(100 * time.Millisecond)
+			for len(notifyCh) > 0 {
+				<-notifyCh
+			}
+		case <-ticker.C:
+			if notify {
+				// ttl countdown is not notified, only refresh on notification
+				continue
+			}
+		}
+
+		cur, err := snapshotKey(item.ctx, item.client, item.key)
+		if item.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			runtime.EventsEmit(w.ctx, item.eventName, map[string]any{
+				"error": err.Error(),
+			})
+			continue
+		}
+		diff := diffSnapshot(snap, cur)
+		ttlChanged := (cur.TTL < 0) != (snap.TTL < 0)
+		if !ttlChanged && cur.TTL >= 0 {
+			// ignore ttl counting down, only report expire time reset
+			expect := snap.TTL - time.Since(snapTime).Milliseconds()
+			ttlChanged = cur.TTL-expect > 1000 || expect-cur.TTL > 1000
+		}
+		if diff.Empty() && !ttlChanged {
+			continue
+		}
+		snap, snapTime = cur, time.Now()
+		runtime.EventsEmit(w.ctx, item.eventName, map[string]any{
+			"exists": cur.Type != "none",
+			"ttl":    cur.TTL,
+			"diff":   diff,
+		})
+	}
+}
+
+// StopWatchKey stop watching key
+func (w *watchService) StopWatchKey(eventName string) (resp types.JSResp) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	item, ok := w.items[eventName]
+	if !ok {
+		resp.Success = true
+		return
+	}
+
+	item.ctxCancel()
+	item.client.Close()
+	delete(w.items, eventName)
+	resp.Success = true
+	return
+}
+
+// CaptureKeySnapshot read current value of key and keep it in memory, which can be compared later by DiffKeys
+func (w *watchService) CaptureKeySnapshot(server string, db int, k any) (resp types.JSResp) {
+	key := strutil.DecodeRedisKey(k)
+	snap, err := w.readSnapshot(server, db, key)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if snap.Type == "none" {
+		resp.Msg = "key not exists"
+		return
+	}
+
+	now := time.Now()
+	id := "snapshot:" + strconv.FormatInt(now.UnixNano(), 10)
+	w.mutex.Lock()
+	if len(w.snapshots) >= maxSnapshots {
+		var oldest string
+		for sid, s := range w.snapshots {
+			if len(oldest) <= 0 || s.timestamp < w.snapshots[oldest].timestamp {
+				oldest = sid
+			}
+		}
+		delete(w.snapshots, oldest)
+	}
+	w.snapshots[id] = &snapshotItem{
+		server:    server,
+		db:        db,
+		key:       key,
+		snapshot:  snap,
+		timestamp: now.UnixMilli(),
+	}
+	w.mutex.Unlock()
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"id":        id,
+		"type":      strings.ToLower(snap.Type),
+		"timestamp": now.UnixMilli(),
+	}
+	return
+}
+
+// ListKeySnapshots list captured snapshots of key
+func (w *watchService) ListKeySnapshots(server string, db int, k any) (resp types.JSResp) {
+	key := strutil.DecodeRedisKey(k)
+	w.mutex.Lock()
+	list := []map[string]any{}
+	for id, s := range w.snapshots {
+		if s.server == server && s.db == db && s.key == key {
+			list = append(list, map[string]any{
+				"id":        id,
+				"type":      strings.ToLower(s.snapshot.Type),
+				"timestamp": s.timestamp,
+			})
+		}
+	}
+	w.mutex.Unlock()
+	slices.SortFunc(list, func(a, b map[string]any) int {
+		return int(a["timestamp"].(int64) - b["timestamp"].(int64))
+	})
+
+	resp.Success = true
+	resp.Data = map[string]any{
+		"snapshots": list,
+	}
+	return
+}
+
+// DeleteKeySnapshot delete captured snapshot
+func (w *watchService) DeleteKeySnapshot(id string) (resp types.JSResp) {
+	w.mutex.Lock()
+	delete(w.snapshots, id)
+	w.mutex.Unlock()
+	resp.Success = true
+	return
+}
+
+// read snapshot by browsing client if the database is opened, otherwise by a dedicated client,
+// so that the browsing database is not switched
+func (w *watchService) readSnapshot(server string, db int, key string) (keySnapshot, error) {
+	b := Browser()
+	b.mutex.Lock()
+	item, ok := b.connMap[server]
+	b.mutex.Unlock()
+	if ok && item.db == db && item.client != nil {
+		return snapshotKey(item.ctx, item.client, key)
+	}
+
+	conf := Connection().getConnection(server)
+	if conf == nil {
+		return keySnapshot{}, fmt.Errorf("no connection profile named: %s", server)
+	}
+	connConfig := conf.ConnectionConfig
+	connConfig.LastDB = db
+	client, err := Connection().createRedisClient(connConfig)
+	if err != nil {
+		return keySnapshot{}, err
+	}
+	defer client.Close()
+	return snapshotKey(w.ctx, client, key)
+}
+
+// DiffKeys compare value of two keys, or captured snapshot with current value of key
+func (w *watchService) DiffKeys(param types.KeyDiffParam) (resp types.JSResp) {
+	var prev keySnapshot
+	var curServer, curKey string
+	var curDB int
+	if len(param.Snapshot) > 0 {
+		w.mutex.Lock()
+		s, ok := w.snapshots[param.Snapshot]
+		w.mutex.Unlock()
+		if !ok {
+			resp.Msg = "snapshot not found"
+			return
+		}
+		prev = s.snapshot
+		curServer, curDB, curKey = s.server, s.db, s.key
+	} else {
+		var err error
+		if prev, err = w.readSnapshot(param.Server, param.DB, strutil.DecodeRedisKey(param.Key)); err != nil {
+			resp.Msg = err.Error()
+			return
+		}
+		curServer, curDB, curKey = param.TargetServer, param.TargetDB, strutil.DecodeRedisKey(param.TargetKey)
+		if len(curServer) <= 0 {
+			curServer = param.Server
+		}
+	}
+
+	cur, err := w.readSnapshot(curServer, curDB, curKey)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	diff := diffSnapshot(prev, cur)
+	if len(diff.OldType) <= 0 && cur.Entries == nil && cur.List == nil && prev.Value != cur.Value {
+		diff.Lines = diffLines(prev.Value, cur.Value)
+	}
+	resp.Success = true
+	resp.Data = diff
+	return
+}
+
+// StopAll stop all watching
+func (w *watchService) StopAll() {
+	if w.ctxCancel != nil {
+		w.ctxCancel()
+	}
+
+	for eventName := range w.items {
+		w.StopWatchKey(eventName)
+	}
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/vrischmann/userdir"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"tinyrdm/backend/types"
+)
+
+// rotate audit log file after exceeding the size, only one backup is kept
+const maxAuditLogSize = 20 * 1024 * 1024
+
+type AuditLogStorage struct {
+	logPath string
+	mutex   sync.Mutex
+}
+
+func NewAuditLog() *AuditLogStorage {
+	return &AuditLogStorage{
+		logPath: path.Join(userdir.GetConfigHome(), "TinyRDM", "audit.log"),
+	}
+}
+
+// Append write an entry to the end of audit log file in JSON lines format
+func (a *AuditLogStorage) Append(entry types.AuditEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if err = ensureDirExists(path.Dir(a.logPath)); err != nil {
+		return err
+	}
+	if stat, statErr := os.Stat(a.logPath); statErr == nil && stat.Size() >= maxAuditLogSize {
+		_ = os.Rename(a.logPath, a.logPath+".1")
+	}
+	file, err := os.OpenFile(a.logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(b, '\n'))
+	return err
+}
+
+// read all entries from backup and current log file, in chronological order
+func (a *AuditLogStorage) readAll() ([]types.AuditEntry, error) {
+	var entries []types.AuditEntry
+	for _, logPath := range []string{a.logPath + ".1", a.logPath} {
+		file, err := os.Open(logPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var entry types.AuditEntry
+			if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+				entries = append(entries, entry)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func matchAuditEntry(entry types.AuditEntry, query types.AuditQuery) bool {
+	if len(query.Server) > 0 && entry.Server != query.Server {
+		return false
+	}
+	if query.DB != nil && entry.DB != *query.DB {
+		return false
+	}
+	if query.StartTime > 0 && entry.Timestamp < query.StartTime {
+		return false
+	}
+	if query.EndTime > 0 && entry.Timestamp > query.EndTime {
+		return false
+	}
+	if len(query.Keyword) > 0 {
+		cmd := strings.ToLower(strings.Join(entry.Args, " "))
+		if !strings.Contains(cmd, strings.ToLower(query.Keyword)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Query get matched entries, the latest entry comes first
+func (a *AuditLogStorage) Query(query types.AuditQuery) ([]types.AuditEntry, error) {
+	a.mutex.Lock()
+	entries, err := a.readAll()
+	a.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]types.AuditEntry, 0)
+	for _, entry := range slices.Backward(entries) {
+		if matchAuditEntry(entry, query) {
+			ret = append(ret, entry)
+			if query.Limit > 0 && len(ret) >= query.Limit {
+				break
+			}
+		}
+	}
+	return ret, nil
+}
+
+// Clear remove all audit log files
+func (a *AuditLogStorage) Clear() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for _, logPath := range []string{a.logPath + ".1", a.logPath} {
+		if err := os.Remove(logPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
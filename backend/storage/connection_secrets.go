@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"tinyrdm/backend/types"
+	keyringutil "tinyrdm/backend/utils/keyring"
+)
+
+const keychainService = "TinyRDM"
+
+const SecretStoreKeychain = "keychain"
+
+// all secrets of one connection, saved as one keychain item
+type connectionSecrets struct {
+	Password         string          `json:"password,omitempty"`
+	SentinelPassword string          `json:"sentinelPassword,omitempty"`
+	KeyPassphrase    string          `json:"keyPassphrase,omitempty"`
+	ProxyPassword    string          `json:"proxyPassword,omitempty"`
+	SSH              []sshHostSecret `json:"ssh,omitempty"` // ssh server first, then jump hosts in order
+}
+
+type sshHostSecret struct {
+	Password   string `json:"password,omitempty"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+// secretsCache cache secrets read from or written to keychain, avoid accessing keychain frequently
+type secretsCache struct {
+	items map[string]string
+	mutex sync.Mutex
+}
+
+// takeSecrets move secrets out from connection config
+func takeSecrets(conf *types.ConnectionConfig) connectionSecrets {
+	secrets := connectionSecrets{
+		Password:         conf.Password,
+		SentinelPassword: conf.Sentinel.Password,
+		KeyPassphrase:    conf.SSL.KeyPassphrase,
+		ProxyPassword:    conf.Proxy.Password,
+	}
+	conf.Password, conf.Sentinel.Password, conf.SSL.KeyPassphrase, conf.Proxy.Password = "", "", "", ""
+
+	hosts := []*types.ConnectionSSHHost{&conf.SSH.ConnectionSSHHost}
+	// copy jump hosts before clearing, the slice may be shared with others
+	conf.SSH.JumpHosts = slices.Clone(conf.SSH.JumpHosts)
+	for i := range conf.SSH.JumpHosts {
+		hosts = append(hosts, &conf.SSH.JumpHosts[i])
+	}
+	for _, host := range hosts {
+		secrets.SSH = append(secrets.SSH, sshHostSecret{
+			Password:   host.Password,
+			Passphrase: host.Passphrase,
+		})
+		host.Password, host.Passphrase = "", ""
+	}
+	return secrets
+}
+
+// fillSecrets put secrets back to connection config
+func fillSecrets(conf *types.ConnectionConfig, secrets connectionSecrets) {
+	conf.Password = secrets.Password
+	conf.Sentinel.Password = secrets.SentinelPassword
+	conf.SSL.KeyPassphrase = secrets.KeyPassphrase
+	conf.Proxy.Password = secrets.ProxyPassword
+	for i, secret := range secrets.SSH {
+		var host *types.ConnectionSSHHost
+		if i == 0 {
+			host = &conf.SSH.ConnectionSSHHost
+		} else if i-1 < len(conf.SSH.JumpHosts) {
+			host = &conf.SSH.JumpHosts[i-1]
+		} else {
+			break
+		}
+		host.Password, host.Passphrase = secret.Password, secret.Passphrase
+	}
+}
+
+// load secrets of connection from keychain
+func (s *secretsCache) load(conf *types.ConnectionConfig) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	content, ok := s.items[conf.Name]
+	if !ok {
+		var err error
+		if content, err = keyringutil.Get(keychainService, conf.Name); err != nil {
+			if !errors.Is(err, keyringutil.ErrNotFound) {
+				log.Printf("load secrets of \"%s\" fail: %s\n", conf.Name, err)
+			}
+			return
+		}
+		s.items[conf.Name] = content
+	}
+
+	var secrets connectionSecrets
+	if err := json.Unmarshal([]byte(content), &secrets); err == nil {
+		fillSecrets(conf, secrets)
+	}
+}
+
+// store secrets of connection to keychain, and clear them in config
+func (s *secretsCache) store(conf *types.ConnectionConfig) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	content, _ := json.Marshal(takeSecrets(conf))
+	if cached, ok := s.items[conf.Name]; ok && cached == string(content) {
+		return nil
+	}
+	if err := keyringutil.Set(keychainService, conf.Name, string(content)); err != nil {
+		return err
+	}
+	s.items[conf.Name] = string(content)
+	return nil
+}
+
+// purge remove keychain items which not in use anymore
+func (s *secretsCache) purge(inUse map[string]struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for name := range s.items {
+		if _, ok := inUse[name]; !ok {
+			_ = keyringutil.Delete(keychainService, name)
+			delete(s.items, name)
+		}
+	}
+}
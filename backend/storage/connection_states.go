@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"gopkg.in/yaml.v3"
+	"sync"
+	"tinyrdm/backend/types"
+)
+
+type ConnectionStatesStorage struct {
+	storage *localStorage
+	mutex   sync.Mutex
+}
+
+func NewConnectionStates() *ConnectionStatesStorage {
+	return &ConnectionStatesStorage{
+		storage: NewLocalStore("connection_states.yaml"),
+	}
+}
+
+func (c *ConnectionStatesStorage) getStates() (ret types.ConnectionStates) {
+	ret = types.ConnectionStates{}
+	b, err := c.storage.Load()
+	if err != nil {
+		return
+	}
+
+	if err = yaml.Unmarshal(b, &ret); err != nil || ret == nil {
+		ret = types.ConnectionStates{}
+	}
+	return
+}
+
+func (c *ConnectionStatesStorage) saveStates(states types.ConnectionStates) error {
+	b, err := yaml.Marshal(&states)
+	if err != nil {
+		return err
+	}
+	return c.storage.Store(b)
+}
+
+// GetState get saved state of connection
+func (c *ConnectionStatesStorage) GetState(name string) types.ConnectionState {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.getStates()[name]
+}
+
+// SaveState save state of connection
+func (c *ConnectionStatesStorage) SaveState(name string, state types.ConnectionState) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	states := c.getStates()
+	states[name] = state
+	return c.saveStates(states)
+}
+
+// ModifyState modify saved state of connection in place
+func (c *ConnectionStatesStorage) ModifyState(name string, modify func(state *types.ConnectionState)) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	states := c.getStates()
+	state := states[name]
+	modify(&state)
+	states[name] = state
+	return c.saveStates(states)
+}
+
+// RenameState move state to new connection name
+func (c *ConnectionStatesStorage) RenameState(name, newName string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	states := c.getStates()
+	state, ok := states[name]
+	if !ok {
+		return nil
+	}
+	delete(states, name)
+	states[newName] = state
+	return c.saveStates(states)
+}
+
+// DeleteState remove state of connection
+func (c *ConnectionStatesStorage) DeleteState(name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	states := c.getStates()
+	if _, ok := states[name]; !ok {
+		return nil
+	}
+	delete(states, name)
+	return c.saveStates(states)
+}
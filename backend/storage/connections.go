@@ -1,22 +1,32 @@
 package storage
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
 	"gopkg.in/yaml.v3"
+	"maps"
 	"slices"
+	"strings"
 	"sync"
 	"tinyrdm/backend/consts"
 	"tinyrdm/backend/types"
 )
 
 type ConnectionsStorage struct {
-	storage *localStorage
-	mutex   sync.Mutex
+	storage    *localStorage
+	secrets    *secretsCache
+	masterKey  []byte // key derived from master password, nil if not encrypted
+	masterSalt []byte
+	mutex      sync.Mutex
 }
 
 func NewConnections() *ConnectionsStorage {
 	return &ConnectionsStorage{
 		storage: NewLocalStore("connections.yaml"),
+		secrets: &secretsCache{
+			items: map[string]string{},
+		},
 	}
 }
 
@@ -32,8 +42,8 @@ func (c *ConnectionsStorage) defaultConnectionItem() types.ConnectionConfig {
 		Port:            6379,
 		Username:        "",
 		Password:        "",
-		DefaultFilter:   "*",
-		KeySeparator:    ":",
+		DefaultFilter:   consts.DEFAULT_KEY_FILTER,
+		KeySeparator:    consts.DEFAULT_KEY_SEPARATOR,
 		ConnTimeout:     60,
 		ExecTimeout:     60,
 		DBFilterType:    "none",
@@ -48,7 +58,7 @@ func (c *ConnectionsStorage) defaultConnectionItem() types.ConnectionConfig {
 }
 
 func (c *ConnectionsStorage) getConnections() (ret types.Connections) {
-	b, err := c.storage.Load()
+	b, err := c.loadProfile()
 	ret = c.defaultConnections()
 	if err != nil {
 		return
@@ -61,6 +71,18 @@ func (c *ConnectionsStorage) getConnections() (ret types.Connections) {
 	if len(ret) <= 0 {
 		ret = c.defaultConnections()
 	}
+	// load secrets saved in keychain
+	var loadSecrets func(types.Connections)
+	loadSecrets = func(conns types.Connections) {
+		for i := range conns {
+			if conns[i].Type == "group" {
+				loadSecrets(conns[i].Connections)
+			} else if conns[i].SecretStore == SecretStoreKeychain {
+				c.secrets.load(&conns[i].ConnectionConfig)
+			}
+		}
+	}
+	loadSecrets(ret)
 	//if !sliceutil.AnyMatch(ret, func(i int) bool {
 	//	return ret[i].GroupName == ""
 	//}) {
@@ -76,14 +98,57 @@ func (c *ConnectionsStorage) GetConnections() (ret types.Connections) {
 
 // GetConnectionsFlat get all store connections from local flat(exclude group level)
 func (c *ConnectionsStorage) GetConnectionsFlat() (ret types.Connections) {
-	conns := c.getConnections()
-	for _, conn := range conns {
-		if conn.Type == "group" {
-			ret = append(ret, conn.Connections...)
-		} else {
-			ret = append(ret, conn)
+	var flat func(types.Connections)
+	flat = func(conns types.Connections) {
+		for _, conn := range conns {
+			if conn.Type == "group" {
+				flat(conn.Connections)
+			} else {
+				ret = append(ret, conn)
+			}
+		}
+	}
+	flat(c.getConnections())
+	return
+}
+
+// FilterConnections get connections match environment and tag with group structure kept
+// empty environment or tag means no limit for that condition
+func (c *ConnectionsStorage) FilterConnections(environment, tag string) types.Connections {
+	var filter func(types.Connections) types.Connections
+	filter = func(conns types.Connections) types.Connections {
+		var ret types.Connections
+		for _, conn := range conns {
+			if conn.Type == "group" {
+				if subConns := filter(conn.Connections); len(subConns) > 0 {
+					conn.Connections = subConns
+					ret = append(ret, conn)
+				}
+			} else if (len(environment) <= 0 || strings.EqualFold(conn.Environment, environment)) &&
+				(len(tag) <= 0 || slices.ContainsFunc(conn.Tags, func(t string) bool { return strings.EqualFold(t, tag) })) {
+				ret = append(ret, conn)
+			}
 		}
+		return ret
 	}
+	return filter(c.getConnections())
+}
+
+// GetConnectionLabels get all distinct environments and tags used by connections
+func (c *ConnectionsStorage) GetConnectionLabels() (environments []string, tags []string) {
+	environments, tags = []string{}, []string{}
+	for _, conn := range c.GetConnectionsFlat() {
+		if len(conn.Environment) > 0 && !slices.Contains(environments, conn.Environment) {
+			environments = append(environments, conn.Environment)
+		}
+		for _, t := range conn.Tags {
+			if len(t) > 0 && !slices.Contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+	}
+	slices.Sort(environments)
+	slices.Sort(tags)
 	return
 }
 
@@ -111,24 +176,92 @@ func (c *ConnectionsStorage) GetConnection(name string) *types.Connection {
 	return findConn(name, "", conns)
 }
 
-// GetGroup get one connection group by name
+// GetGroup get one connection group by name, include nested groups
 func (c *ConnectionsStorage) GetGroup(name string) *types.Connection {
-	conns := c.getConnections()
+	return findGroup(c.getConnections(), name)
+}
 
+// findGroup find group by name recursively
+func findGroup(conns types.Connections, name string) *types.Connection {
 	for i, conn := range conns {
-		if conn.Type == "group" && conn.Name == name {
-			return &conns[i]
+		if conn.Type == "group" {
+			if conn.Name == name {
+				return &conns[i]
+			}
+			if ret := findGroup(conn.Connections, name); ret != nil {
+				return ret
+			}
 		}
 	}
 	return nil
 }
 
+// takeItem remove connection or group by name recursively, and return the removed one
+func takeItem(conns *types.Connections, name string, isGroup bool) (types.Connection, bool) {
+	for i, conn := range *conns {
+		if (conn.Type == "group") == isGroup && conn.Name == name {
+			*conns = append((*conns)[:i], (*conns)[i+1:]...)
+			return conn, true
+		}
+		if conn.Type == "group" {
+			if ret, ok := takeItem(&(*conns)[i].Connections, name, isGroup); ok {
+				return ret, true
+			}
+		}
+	}
+	return types.Connection{}, false
+}
+
+// insertItem insert item into connections at index, append to the end if index out of range
+func insertItem(conns types.Connections, item types.Connection, index int) types.Connections {
+	if index < 0 || index > len(conns) {
+		index = len(conns)
+	}
+	return slices.Insert(conns, index, item)
+}
+
 func (c *ConnectionsStorage) saveConnections(conns types.Connections) error {
+	if c.IsLocked() {
+		// never overwrite the encrypted file before unlocked
+		return ErrConnectionsLocked
+	}
+	return c.storeConnections(conns)
+}
+
+func (c *ConnectionsStorage) storeConnections(conns types.Connections) error {
+	// move secrets to keychain, save others to profile
+	inKeychain := map[string]struct{}{}
+	var storeSecrets func(types.Connections) (types.Connections, error)
+	storeSecrets = func(cons types.Connections) (types.Connections, error) {
+		ret := make(types.Connections, 0, len(cons))
+		for _, conn := range cons {
+			if conn.Type == "group" {
+				subConns, err := storeSecrets(conn.Connections)
+				if err != nil {
+					return nil, err
+				}
+				conn.Connections = subConns
+			} else if conn.SecretStore == SecretStoreKeychain {
+				if err := c.secrets.store(&conn.ConnectionConfig); err != nil {
+					return nil, err
+				}
+				inKeychain[conn.Name] = struct{}{}
+			}
+			ret = append(ret, conn)
+		}
+		return ret, nil
+	}
+	conns, err := storeSecrets(conns)
+	if err != nil {
+		return err
+	}
+	c.secrets.purge(inKeychain)
+
 	b, err := yaml.Marshal(&conns)
 	if err != nil {
 		return err
 	}
-	if err = c.storage.Store(b); err != nil {
+	if err = c.storeProfile(b); err != nil {
 		return err
 	}
 	return nil
@@ -147,12 +280,7 @@ func (c *ConnectionsStorage) CreateConnection(param types.ConnectionConfig) erro
 	conns := c.getConnections()
 	var group *types.Connection
 	if len(param.Group) > 0 {
-		for i, conn := range conns {
-			if conn.Type == "group" && conn.Name == param.Group {
-				group = &conns[i]
-				break
-			}
-		}
+		group = findGroup(conns, param.Group)
 	}
 	if group != nil {
 		group.Connections = append(group.Connections, types.Connection{
@@ -162,6 +290,9 @@ func (c *ConnectionsStorage) CreateConnection(param types.ConnectionConfig) erro
 		if len(param.Group) > 0 {
 			// no group matched, create new group
 			conns = append(conns, types.Connection{
+				ConnectionConfig: types.ConnectionConfig{
+					Name: param.Group,
+				},
 				Type: "group",
 				Connections: types.Connections{
 					types.Connection{
@@ -218,37 +349,126 @@ func (c *ConnectionsStorage) UpdateConnection(name string, param types.Connectio
 	return c.saveConnections(conns)
 }
 
-// DeleteConnection remove special connection
-func (c *ConnectionsStorage) DeleteConnection(name string) error {
+// modifyConnection find connection by name and save after modified
+func (c *ConnectionsStorage) modifyConnection(name string, modify func(conn *types.ConnectionConfig)) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	conns := c.getConnections()
-	var updated bool
-	for i, conn := range conns {
-		if conn.Type == "group" {
-			for j, subConn := range conn.Connections {
-				if subConn.Name == name {
-					conns[i].Connections = append(conns[i].Connections[:j], conns[i].Connections[j+1:]...)
-					updated = true
-					break
+	var find func(types.Connections) *types.Connection
+	find = func(conns types.Connections) *types.Connection {
+		for i, conn := range conns {
+			if conn.Type == "group" {
+				if ret := find(conn.Connections); ret != nil {
+					return ret
 				}
+			} else if conn.Name == name {
+				return &conns[i]
 			}
-		} else if conn.Name == name {
-			conns = append(conns[:i], conns[i+1:]...)
-			updated = true
-			break
 		}
-		if updated {
-			break
+		return nil
+	}
+	conn := find(conns)
+	if conn == nil {
+		return errors.New("connection not found")
+	}
+	modify(&conn.ConnectionConfig)
+	return c.saveConnections(conns)
+}
+
+// SaveLastConnected update last connected time of connection
+func (c *ConnectionsStorage) SaveLastConnected(name string, timestamp int64) error {
+	return c.modifyConnection(name, func(conn *types.ConnectionConfig) {
+		conn.LastConnected = timestamp
+	})
+}
+
+// SetPinned pin connection to favorites or unpin it
+func (c *ConnectionsStorage) SetPinned(name string, pinned bool) error {
+	return c.modifyConnection(name, func(conn *types.ConnectionConfig) {
+		conn.Pinned = pinned
+	})
+}
+
+// GetRecentConnections get pinned connections in profile order, and other connections
+// ordered by last connected time, the latest comes first
+// @param limit max count of recent connections, no limit if <= 0
+func (c *ConnectionsStorage) GetRecentConnections(limit int) (pinned, recent types.Connections) {
+	pinned, recent = types.Connections{}, types.Connections{}
+	for _, conn := range c.GetConnectionsFlat() {
+		if conn.Pinned {
+			pinned = append(pinned, conn)
+		} else if conn.LastConnected > 0 {
+			recent = append(recent, conn)
 		}
 	}
-	if !updated {
+	slices.SortStableFunc(recent, func(a, b types.Connection) int {
+		return cmp.Compare(b.LastConnected, a.LastConnected)
+	})
+	if limit > 0 && len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return
+}
+
+// DeleteConnection remove special connection
+func (c *ConnectionsStorage) DeleteConnection(name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	conns := c.getConnections()
+	if _, ok := takeItem(&conns, name, false); !ok {
 		return errors.New("no match connection")
 	}
 	return c.saveConnections(conns)
 }
 
+// CloneConnection duplicate connection with all settings, and place it after the original one in the same group
+// @return name of new connection
+func (c *ConnectionsStorage) CloneConnection(name string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	conns := c.getConnections()
+	var parent *types.Connections
+	var idx int
+	var findConn func(*types.Connections) bool
+	findConn = func(cons *types.Connections) bool {
+		for i := range *cons {
+			if (*cons)[i].Type == "group" {
+				if findConn(&(*cons)[i].Connections) {
+					return true
+				}
+			} else if (*cons)[i].Name == name {
+				parent, idx = cons, i
+				return true
+			}
+		}
+		return false
+	}
+	if !findConn(&conns) {
+		return "", errors.New("connection not found")
+	}
+
+	newName := name + " - copy"
+	for n := 2; c.GetConnection(newName) != nil; n++ {
+		newName = fmt.Sprintf("%s - copy %d", name, n)
+	}
+	conn := (*parent)[idx]
+	conn.Name = newName
+	conn.Alias = maps.Clone(conn.Alias)
+	conn.DBFilterList = slices.Clone(conn.DBFilterList)
+	conn.Tags = slices.Clone(conn.Tags)
+	conn.SSH.JumpHosts = slices.Clone(conn.SSH.JumpHosts)
+	conn.Sentinel.Addrs = slices.Clone(conn.Sentinel.Addrs)
+	*parent = slices.Insert(*parent, idx+1, conn)
+
+	if err := c.saveConnections(conns); err != nil {
+		return "", err
+	}
+	return newName, nil
+}
+
 // SaveSortedConnection save connection after sort
 func (c *ConnectionsStorage) SaveSortedConnection(sortedConns types.Connections) error {
 	c.mutex.Lock()
@@ -290,24 +510,85 @@ func (c *ConnectionsStorage) SaveSortedConnection(sortedConns types.Connections)
 	return c.saveConnections(conns)
 }
 
-// CreateGroup create a new group
-func (c *ConnectionsStorage) CreateGroup(name string) error {
+// MoveConnection move connection into another group at specified position
+// @param parent target group name, move to top level if empty
+// @param index position in target group, append to the end if out of range
+func (c *ConnectionsStorage) MoveConnection(name, parent string, index int) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	conns := c.getConnections()
-	for _, conn := range conns {
-		if conn.Type == "group" && conn.Name == name {
-			return errors.New("duplicated group name")
+	conn, ok := takeItem(&conns, name, false)
+	if !ok {
+		return errors.New("connection not found")
+	}
+
+	if len(parent) > 0 {
+		group := findGroup(conns, parent)
+		if group == nil {
+			return errors.New("group not found")
 		}
+		group.Connections = insertItem(group.Connections, conn, index)
+	} else {
+		conns = insertItem(conns, conn, index)
+	}
+	return c.saveConnections(conns)
+}
+
+// MoveGroup move group with all its children into another group at specified position
+// @param parent target group name, move to top level if empty
+// @param index position in target group, append to the end if out of range
+func (c *ConnectionsStorage) MoveGroup(name, parent string, index int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	conns := c.getConnections()
+	group, ok := takeItem(&conns, name, true)
+	if !ok {
+		return errors.New("group not found")
 	}
 
-	conns = append(conns, types.Connection{
+	if len(parent) > 0 {
+		if parent == name || findGroup(group.Connections, parent) != nil {
+			return errors.New("can not move group into itself")
+		}
+		parentGroup := findGroup(conns, parent)
+		if parentGroup == nil {
+			return errors.New("group not found")
+		}
+		parentGroup.Connections = insertItem(parentGroup.Connections, group, index)
+	} else {
+		conns = insertItem(conns, group, index)
+	}
+	return c.saveConnections(conns)
+}
+
+// CreateGroup create a new group
+// @param parent parent group name, create at top level if empty
+func (c *ConnectionsStorage) CreateGroup(name, parent string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	conns := c.getConnections()
+	if findGroup(conns, name) != nil {
+		return errors.New("duplicated group name")
+	}
+
+	group := types.Connection{
 		ConnectionConfig: types.ConnectionConfig{
 			Name: name,
 		},
 		Type: "group",
-	})
+	}
+	if len(parent) > 0 {
+		parentGroup := findGroup(conns, parent)
+		if parentGroup == nil {
+			return errors.New("group not found")
+		}
+		parentGroup.Connections = append(parentGroup.Connections, group)
+	} else {
+		conns = append(conns, group)
+	}
 	return c.saveConnections(conns)
 }
 
@@ -316,40 +597,186 @@ func (c *ConnectionsStorage) RenameGroup(name, newName string) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	groupIndex := -1
 	conns := c.getConnections()
-	for i, conn := range conns {
-		if conn.Type == "group" {
-			if conn.Name == newName {
-				return errors.New("duplicated group name")
-			} else if conn.Name == name {
-				groupIndex = i
-			}
-		}
+	if findGroup(conns, newName) != nil {
+		return errors.New("duplicated group name")
 	}
-
-	if groupIndex == -1 {
+	group := findGroup(conns, name)
+	if group == nil {
 		return errors.New("group not found")
 	}
 
-	conns[groupIndex].Name = newName
+	group.Name = newName
 	return c.saveConnections(conns)
 }
 
 // DeleteGroup remove specified group, include all connections under it
+// connections and subgroups will be moved to parent instead if includeConnection is true
 func (c *ConnectionsStorage) DeleteGroup(group string, includeConnection bool) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	conns := c.getConnections()
-	for i, conn := range conns {
-		if conn.Type == "group" && conn.Name == group {
-			conns = append(conns[:i], conns[i+1:]...)
-			if includeConnection {
-				conns = append(conns, conn.Connections...)
+	var deleteGroup func(*types.Connections) bool
+	deleteGroup = func(cons *types.Connections) bool {
+		for i, conn := range *cons {
+			if conn.Type != "group" {
+				continue
+			}
+			if conn.Name == group {
+				*cons = append((*cons)[:i], (*cons)[i+1:]...)
+				if includeConnection {
+					*cons = append(*cons, conn.Connections...)
+				}
+				return true
+			}
+			if deleteGroup(&(*cons)[i].Connections) {
+				return true
 			}
-			return c.saveConnections(conns)
+		}
+		return false
+	}
+
+	if !deleteGroup(&conns) {
+		return errors.New("group not found")
+	}
+	return c.saveConnections(conns)
+}
+
+// GetSelectedConnections get connections by names with group structure kept
+// a selected group includes all connections under it, select all if names is empty
+func (c *ConnectionsStorage) GetSelectedConnections(names []string) types.Connections {
+	conns := c.getConnections()
+	if len(names) <= 0 {
+		return conns
+	}
+
+	var selectConns func(types.Connections) types.Connections
+	selectConns = func(cons types.Connections) types.Connections {
+		var ret types.Connections
+		for _, conn := range cons {
+			if slices.Contains(names, conn.Name) {
+				ret = append(ret, conn)
+			} else if conn.Type == "group" {
+				if subConns := selectConns(conn.Connections); len(subConns) > 0 {
+					conn.Connections = subConns
+					ret = append(ret, conn)
+				}
+			}
+		}
+		return ret
+	}
+	return selectConns(conns)
+}
+
+// ImportConnections merge connections into local profile
+// @param conflict resolution when connection name is duplicated: 0 skip, 1 overwrite, 2 rename
+// @return imported connection count
+// @return skipped connection count
+func (c *ConnectionsStorage) ImportConnections(imports types.Connections, conflict int) (int, int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	conns := c.getConnections()
+	// find connection by name, return the parent connections and index
+	var findConn func(*types.Connections, string) (*types.Connections, int)
+	findConn = func(cons *types.Connections, name string) (*types.Connections, int) {
+		for i := range *cons {
+			if (*cons)[i].Type == "group" {
+				if parent, idx := findConn(&(*cons)[i].Connections, name); parent != nil {
+					return parent, idx
+				}
+			} else if (*cons)[i].Name == name {
+				return cons, i
+			}
+		}
+		return nil, -1
+	}
+
+	var imported, skipped int
+	addConn := func(target *types.Connections, conn types.Connection) {
+		if parent, idx := findConn(&conns, conn.Name); parent != nil {
+			switch conflict {
+			case 1:
+				// overwrite existing connection in place
+				(*parent)[idx] = conn
+				imported += 1
+				return
+			case 2:
+				// rename to an unused name
+				baseName := conn.Name
+				for n := 1; ; n++ {
+					conn.Name = fmt.Sprintf("%s (%d)", baseName, n)
+					if p, _ := findConn(&conns, conn.Name); p == nil {
+						break
+					}
+				}
+			default:
+				skipped += 1
+				return
+			}
+		}
+		*target = append(*target, conn)
+		imported += 1
+	}
+
+	var importConns func(*types.Connections, types.Connections)
+	importConns = func(target *types.Connections, items types.Connections) {
+		for _, item := range items {
+			if item.Type == "group" {
+				// merge into group with the same name, or create it under target
+				group := findGroup(conns, item.Name)
+				if group == nil {
+					*target = append(*target, types.Connection{
+						ConnectionConfig: types.ConnectionConfig{
+							Name: item.Name,
+						},
+						Type: "group",
+					})
+					group = &(*target)[len(*target)-1]
+				}
+				importConns(&group.Connections, item.Connections)
+			} else {
+				addConn(target, item)
+			}
+		}
+	}
+	importConns(&conns, imports)
+
+	if imported > 0 {
+		if err := c.saveConnections(conns); err != nil {
+			return 0, 0, err
+		}
+	}
+	return imported, skipped, nil
+}
+
+// SetSecretStore migrate secrets of all connections to specified store
+// @param store "keychain" or empty for profile file
+// @return count of migrated connections
+func (c *ConnectionsStorage) SetSecretStore(store string) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	conns := c.getConnections()
+	var count int
+	var migrate func(types.Connections)
+	migrate = func(cons types.Connections) {
+		for i := range cons {
+			if cons[i].Type == "group" {
+				migrate(cons[i].Connections)
+			} else if cons[i].SecretStore != store {
+				cons[i].SecretStore = store
+				count += 1
+			}
+		}
+	}
+	migrate(conns)
+
+	if count > 0 {
+		if err := c.saveConnections(conns); err != nil {
+			return 0, err
 		}
 	}
-	return errors.New("group not found")
+	return count, nil
 }
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"gopkg.in/yaml.v3"
+	cryptoutil "tinyrdm/backend/utils/crypto"
+)
+
+var ErrConnectionsLocked = errors.New("connections are locked by master password")
+
+// encryptedProfile content of connections file encrypted by master password
+type encryptedProfile struct {
+	Encrypted struct {
+		KDF  string `yaml:"kdf"`
+		Salt string `yaml:"salt"` // base64 encoded
+		Data string `yaml:"data"` // base64 encoded nonce | ciphertext
+	} `yaml:"encrypted"`
+}
+
+// parse encrypted profile, return nil if content is not encrypted
+func parseEncryptedProfile(b []byte) *encryptedProfile {
+	var profile encryptedProfile
+	if err := yaml.Unmarshal(b, &profile); err != nil || len(profile.Encrypted.Data) <= 0 {
+		return nil
+	}
+	return &profile
+}
+
+// load plain content of connections file, decrypt with master key if encrypted
+func (c *ConnectionsStorage) loadProfile() ([]byte, error) {
+	b, err := c.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	profile := parseEncryptedProfile(b)
+	if profile == nil {
+		return b, nil
+	}
+	if c.masterKey == nil {
+		return nil, ErrConnectionsLocked
+	}
+	data, err := base64.StdEncoding.DecodeString(profile.Encrypted.Data)
+	if err != nil {
+		return nil, err
+	}
+	return cryptoutil.DecryptWithKey(data, c.masterKey)
+}
+
+// store content to connections file, encrypt with master key if set
+func (c *ConnectionsStorage) storeProfile(b []byte) error {
+	if c.masterKey == nil {
+		return c.storage.Store(b)
+	}
+
+	data, err := cryptoutil.EncryptWithKey(b, c.masterKey)
+	if err != nil {
+		return err
+	}
+	var profile encryptedProfile
+	profile.Encrypted.KDF = "argon2id"
+	profile.Encrypted.Salt = base64.StdEncoding.EncodeToString(c.masterSalt)
+	profile.Encrypted.Data = base64.StdEncoding.EncodeToString(data)
+	if b, err = yaml.Marshal(&profile); err != nil {
+		return err
+	}
+	return c.storage.Store(b)
+}
+
+// IsEncrypted check if connections file is encrypted by master password
+func (c *ConnectionsStorage) IsEncrypted() bool {
+	b, err := c.storage.Load()
+	return err == nil && parseEncryptedProfile(b) != nil
+}
+
+// IsLocked check if connections file is encrypted and not unlocked yet
+func (c *ConnectionsStorage) IsLocked() bool {
+	return c.masterKey == nil && c.IsEncrypted()
+}
+
+// Unlock decrypt connections file by master password, the key is kept until app exit
+func (c *ConnectionsStorage) Unlock(password string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	b, err := c.storage.Load()
+	if err != nil {
+		return err
+	}
+	profile := parseEncryptedProfile(b)
+	if profile == nil {
+		return nil
+	}
+	salt, err := base64.StdEncoding.DecodeString(profile.Encrypted.Salt)
+	if err != nil {
+		return err
+	}
+	data, err := base64.StdEncoding.DecodeString(profile.Encrypted.Data)
+	if err != nil {
+		return err
+	}
+	key := cryptoutil.DeriveKeyArgon2(password, salt)
+	if _, err = cryptoutil.DecryptWithKey(data, key); err != nil {
+		return errors.New("incorrect master password")
+	}
+	c.masterKey, c.masterSalt = key, salt
+	return nil
+}
+
+// SetMasterPassword change master password and re-encrypt connections file
+// remove encryption if new password is empty
+func (c *ConnectionsStorage) SetMasterPassword(password, newPassword string) error {
+	if c.IsEncrypted() {
+		if err := c.Unlock(password); err != nil {
+			return err
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	conns := c.getConnections()
+	if len(newPassword) > 0 {
+		salt, err := cryptoutil.NewSalt()
+		if err != nil {
+			return err
+		}
+		c.masterKey, c.masterSalt = cryptoutil.DeriveKeyArgon2(newPassword, salt), salt
+	} else {
+		c.masterKey, c.masterSalt = nil, nil
+	}
+	return c.storeConnections(conns)
+}
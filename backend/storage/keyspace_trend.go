@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/vrischmann/userdir"
+	"os"
+	"path"
+	"sync"
+	"tinyrdm/backend/types"
+)
+
+// rotate trend file after exceeding the size, only one backup is kept
+const maxKeyspaceTrendSize = 20 * 1024 * 1024
+
+type KeyspaceTrendStorage struct {
+	filePath string
+	mutex    sync.Mutex
+}
+
+func NewKeyspaceTrend() *KeyspaceTrendStorage {
+	return &KeyspaceTrendStorage{
+		filePath: path.Join(userdir.GetConfigHome(), "TinyRDM", "keyspace_trend.log"),
+	}
+}
+
+// Append write samples to the end of trend file in JSON lines format
+func (k *KeyspaceTrendStorage) Append(samples ...types.KeyspaceSample) error {
+	if len(samples) <= 0 {
+		return nil
+	}
+	var content []byte
+	for _, sample := range samples {
+		b, err := json.Marshal(sample)
+		if err != nil {
+			return err
+		}
+		content = append(append(content, b...), '\n')
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if err := ensureDirExists(path.Dir(k.filePath)); err != nil {
+		return err
+	}
+	if stat, statErr := os.Stat(k.filePath); statErr == nil && stat.Size() >= maxKeyspaceTrendSize {
+		_ = os.Rename(k.filePath, k.filePath+".1")
+	}
+	file, err := os.OpenFile(k.filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(content)
+	return err
+}
+
+// read all samples matched from backup and current file, in chronological order
+func (k *KeyspaceTrendStorage) readAll(match func(types.KeyspaceSample) bool) ([]types.KeyspaceSample, error) {
+	var samples []types.KeyspaceSample
+	for _, filePath := range []string{k.filePath + ".1", k.filePath} {
+		file, err := os.Open(filePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var sample types.KeyspaceSample
+			if json.Unmarshal(scanner.Bytes(), &sample) == nil && match(sample) {
+				samples = append(samples, sample)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return samples, nil
+}
+
+// Query get matched samples in chronological order
+func (k *KeyspaceTrendStorage) Query(query types.KeyspaceTrendQuery) ([]types.KeyspaceSample, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	samples, err := k.readAll(func(sample types.KeyspaceSample) bool {
+		if len(query.Server) > 0 && sample.Server != query.Server {
+			return false
+		}
+		if query.DB != nil && sample.DB != *query.DB {
+			return false
+		}
+		if query.StartTime > 0 && sample.Timestamp < query.StartTime {
+			return false
+		}
+		if query.EndTime > 0 && sample.Timestamp > query.EndTime {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if samples == nil {
+		samples = make([]types.KeyspaceSample, 0)
+	}
+	return samples, nil
+}
+
+// Clear remove samples of server, or all samples if server is empty
+func (k *KeyspaceTrendStorage) Clear(server string) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if len(server) <= 0 {
+		for _, filePath := range []string{k.filePath + ".1", k.filePath} {
+			if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// rewrite with samples of other servers
+	samples, err := k.readAll(func(sample types.KeyspaceSample) bool {
+		return sample.Server != server
+	})
+	if err != nil {
+		return err
+	}
+	var content []byte
+	for _, sample := range samples {
+		if b, err := json.Marshal(sample); err == nil {
+			content = append(append(content, b...), '\n')
+		}
+	}
+	_ = os.Remove(k.filePath + ".1")
+	if len(content) <= 0 {
+		if err = os.Remove(k.filePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(k.filePath, content, 0600)
+}
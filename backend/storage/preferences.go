@@ -12,8 +12,9 @@ import (
 )
 
 type PreferencesStorage struct {
-	storage *localStorage
-	mutex   sync.Mutex
+	storage    *localStorage
+	mutex      sync.Mutex
+	avroSecret *string // cached password of schema registry in keychain
 }
 
 func NewPreferences() *PreferencesStorage {
@@ -39,6 +40,7 @@ func (p *PreferencesStorage) getPreferences() (ret types.Preferences) {
 		ret = p.DefaultPreferences()
 		return
 	}
+	p.loadSecrets(&ret.Avro)
 	return
 }
 
@@ -51,6 +53,9 @@ func (p *PreferencesStorage) GetPreferences() (ret types.Preferences) {
 	if ret.General.ScanSize <= 0 {
 		ret.General.ScanSize = consts.DEFAULT_SCAN_SIZE
 	}
+	if ret.General.LargeValueSize <= 0 {
+		ret.General.LargeValueSize = consts.DEFAULT_LARGE_VALUE_SIZE
+	}
 	ret.Behavior.AsideWidth = max(ret.Behavior.AsideWidth, consts.DEFAULT_ASIDE_WIDTH)
 	ret.Behavior.WindowWidth = max(ret.Behavior.WindowWidth, consts.MIN_WINDOW_WIDTH)
 	ret.Behavior.WindowHeight = max(ret.Behavior.WindowHeight, consts.MIN_WINDOW_HEIGHT)
@@ -84,7 +89,12 @@ func (p *PreferencesStorage) setPreferences(pf *types.Preferences, key string, v
 }
 
 func (p *PreferencesStorage) savePreferences(pf *types.Preferences) error {
-	b, err := yaml.Marshal(pf)
+	// secrets are removed from a copy, pf is returned to caller as it is
+	stored := *pf
+	if err := p.storeSecrets(&stored.Avro); err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(&stored)
 	if err != nil {
 		return err
 	}
@@ -95,11 +105,20 @@ func (p *PreferencesStorage) savePreferences(pf *types.Preferences) error {
 	return nil
 }
 
-// SetPreferences replace preferences
+// keep sections managed by their own api, which are not sent by preferences dialog
+func keepManagedSections(pf *types.Preferences, old types.Preferences) {
+	pf.KeyActions = old.KeyActions
+	pf.Protobuf = old.Protobuf
+	pf.Avro = old.Avro
+	pf.KeyRules = old.KeyRules
+}
+
+// SetPreferences replace preferences, sections managed by their own api are kept
 func (p *PreferencesStorage) SetPreferences(pf *types.Preferences) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	keepManagedSections(pf, p.getPreferences())
 	return p.savePreferences(pf)
 }
 
@@ -124,6 +143,7 @@ func (p *PreferencesStorage) RestoreDefault() types.Preferences {
 	defer p.mutex.Unlock()
 
 	pf := p.DefaultPreferences()
+	keepManagedSections(&pf, p.getPreferences())
 	p.savePreferences(&pf)
 	return pf
 }
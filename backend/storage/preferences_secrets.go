@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"errors"
+	"log"
+	"tinyrdm/backend/types"
+	keyringutil "tinyrdm/backend/utils/keyring"
+)
+
+// keychain service of secrets in preferences, separated from connections which use connection name as account
+const prefKeychainService = keychainService + " Preferences"
+
+const avroSecretAccount = "avro-registry"
+
+// load password of schema registry from keychain
+func (p *PreferencesStorage) loadSecrets(avro *types.PreferencesAvro) {
+	if avro.SecretStore != SecretStoreKeychain {
+		return
+	}
+	if p.avroSecret == nil {
+		secret, err := keyringutil.Get(prefKeychainService, avroSecretAccount)
+		if err != nil {
+			if !errors.Is(err, keyringutil.ErrNotFound) {
+				log.Printf("load secret of schema registry fail: %s\n", err)
+			}
+			return
+		}
+		p.avroSecret = &secret
+	}
+	avro.Password = *p.avroSecret
+}
+
+// move password of schema registry to keychain, keep it in profile only if keychain is not supported
+func (p *PreferencesStorage) storeSecrets(avro *types.PreferencesAvro) error {
+	if len(avro.Password) <= 0 {
+		if p.avroSecret != nil {
+			_ = keyringutil.Delete(prefKeychainService, avroSecretAccount)
+			p.avroSecret = nil
+		}
+		avro.SecretStore = ""
+		return nil
+	}
+
+	if p.avroSecret == nil || *p.avroSecret != avro.Password {
+		if err := keyringutil.Set(prefKeychainService, avroSecretAccount, avro.Password); err != nil {
+			if errors.Is(err, keyringutil.ErrUnsupported) {
+				avro.SecretStore = ""
+				return nil
+			}
+			return err
+		}
+		secret := avro.Password
+		p.avroSecret = &secret
+	}
+	avro.SecretStore = SecretStoreKeychain
+	avro.Password = ""
+	return nil
+}
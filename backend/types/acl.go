@@ -0,0 +1,13 @@
+package types
+
+type ACLUser struct {
+	Name      string   `json:"name"`
+	Enabled   bool     `json:"enabled"`
+	NoPass    bool     `json:"nopass,omitempty"`
+	Passwords []string `json:"passwords,omitempty"` // new plain passwords
+	Hashes    []string `json:"hashes,omitempty"`    // SHA256 hashes of passwords
+	Commands  []string `json:"commands,omitempty"`  // like "+@read", "-flushdb" or "+config|get"
+	Keys      []string `json:"keys,omitempty"`      // like "~cache:*" or "%R~log:*"
+	Channels  []string `json:"channels,omitempty"`  // like "&news.*"
+	Rules     string   `json:"rules,omitempty"`     // full rules return by "ACL LIST", only for display
+}
@@ -0,0 +1,18 @@
+package types
+
+type AuditEntry struct {
+	Timestamp int64    `json:"timestamp"` // unix milliseconds
+	Server    string   `json:"server"`
+	DB        int      `json:"db"`
+	Args      []string `json:"args"`
+	Error     string   `json:"error,omitempty"`
+}
+
+type AuditQuery struct {
+	Server    string `json:"server,omitempty"`
+	DB        *int   `json:"db,omitempty"`      // all databases if not set
+	Keyword   string `json:"keyword,omitempty"` // match command and arguments, case-insensitive
+	StartTime int64  `json:"startTime,omitempty"`
+	EndTime   int64  `json:"endTime,omitempty"`
+	Limit     int    `json:"limit,omitempty"` // return latest entries only, no limit if not set
+}
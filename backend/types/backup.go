@@ -0,0 +1,23 @@
+package types
+
+type BackupState struct {
+	Loading             bool   `json:"loading"`
+	RDBInProgress       bool   `json:"rdbInProgress"`
+	RDBLastSaveTime     int64  `json:"rdbLastSaveTime"` // unix seconds of last successful save
+	RDBLastStatus       string `json:"rdbLastStatus"`
+	RDBChanges          int64  `json:"rdbChanges"` // changes since last save
+	AOFEnabled          bool   `json:"aofEnabled"`
+	AOFRewriteProgress  bool   `json:"aofRewriteInProgress"`
+	AOFRewriteScheduled bool   `json:"aofRewriteScheduled"`
+	AOFLastStatus       string `json:"aofLastStatus"`
+}
+
+// KeyDump portable backup of single key
+type KeyDump struct {
+	Version   int    `json:"version"`
+	Key       string `json:"key"` // base64 encoded key name
+	Type      string `json:"type"`
+	TTL       int64  `json:"ttl"`       // remaining time to live in milliseconds when exported, -1 for persistent
+	Payload   string `json:"payload"`   // base64 encoded payload of "DUMP"
+	CreatedAt int64  `json:"createdAt"` // unix milliseconds
+}
@@ -0,0 +1,48 @@
+package types
+
+type BitmapParam struct {
+	Server string `json:"server"`
+	DB     int    `json:"db"`
+	Key    any    `json:"key"`
+	Start  int64  `json:"start"` // offset of first bit in page, aligned to byte
+	Count  int64  `json:"count"` // bits per page
+}
+
+type BitmapPage struct {
+	Start     int64   `json:"start"`
+	Count     int64   `json:"count"`
+	BitLength int64   `json:"bitLength"` // total bits of string
+	BitCount  int64   `json:"bitCount"`  // set bits in current page
+	Bits      []int64 `json:"bits"`      // offsets of set bits in current page
+}
+
+type BitfieldField struct {
+	Name   string `json:"name,omitempty"` // label of field in layout
+	Type   string `json:"type"`           // signed "i1"~"i64" or unsigned "u1"~"u63"
+	Offset string `json:"offset"`         // bit offset, or field index with "#" prefix like "#2"
+	Value  int64  `json:"value,omitempty"`
+}
+
+type BitfieldParam struct {
+	Server   string          `json:"server"`
+	DB       int             `json:"db"`
+	Key      any             `json:"key"`
+	Fields   []BitfieldField `json:"fields"`
+	Overflow string          `json:"overflow,omitempty"` // overflow behavior of set: "WRAP"(default), "SAT" or "FAIL"
+}
+
+type BinaryRangeParam struct {
+	Server string `json:"server"`
+	DB     int    `json:"db"`
+	Key    any    `json:"key"`
+	Offset int64  `json:"offset"`           // offset of first byte
+	Length int64  `json:"length,omitempty"` // bytes to read
+	Hex    string `json:"hex,omitempty"`    // bytes in hex to write at offset
+}
+
+type BinaryChunk struct {
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"` // bytes in current chunk
+	Size   int64  `json:"size"`   // total bytes of string
+	Hex    string `json:"hex"`
+}
@@ -0,0 +1,23 @@
+package types
+
+type ClusterSlotRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type ClusterNode struct {
+	ID        string             `json:"id"`
+	Addr      string             `json:"addr"`
+	Role      string             `json:"role"` // master or replica
+	MasterID  string             `json:"masterId,omitempty"`
+	Flags     string             `json:"flags"`
+	LinkState string             `json:"linkState"`
+	Slots     []ClusterSlotRange `json:"slots"`
+	SlotCount int                `json:"slotCount"`
+}
+
+type ClusterSlotMove struct {
+	SourceID string `json:"sourceId"`
+	TargetID string `json:"targetId"`
+	Slots    []int  `json:"slots"`
+}
@@ -12,28 +12,50 @@ type ConnectionConfig struct {
 	Port            int                `json:"port,omitempty" yaml:"port,omitempty"`
 	Username        string             `json:"username,omitempty" yaml:"username,omitempty"`
 	Password        string             `json:"password,omitempty" yaml:"password,omitempty"`
+	SecretStore     string             `json:"secretStore,omitempty" yaml:"secret_store,omitempty"` // where to save passwords and passphrases, "keychain" or empty for profile file
 	DefaultFilter   string             `json:"defaultFilter,omitempty" yaml:"default_filter,omitempty"`
 	KeySeparator    string             `json:"keySeparator,omitempty" yaml:"key_separator,omitempty"`
+	KeySeparators   []string           `json:"keySeparators,omitempty" yaml:"key_separators,omitempty"` // additional separators like "/", multi-char separator like "::" is supported
 	ConnTimeout     int                `json:"connTimeout,omitempty" yaml:"conn_timeout,omitempty"`
 	ExecTimeout     int                `json:"execTimeout,omitempty" yaml:"exec_timeout,omitempty"`
+	ReadTimeout     int                `json:"readTimeout,omitempty" yaml:"read_timeout,omitempty"`   // use exec timeout if not set
+	WriteTimeout    int                `json:"writeTimeout,omitempty" yaml:"write_timeout,omitempty"` // use exec timeout if not set
+	KeepAlive       int                `json:"keepAlive,omitempty" yaml:"keep_alive,omitempty"`       // tcp keepalive period in seconds, 0 for system default and -1 to disable
+	PoolSize        int                `json:"poolSize,omitempty" yaml:"pool_size,omitempty"`         // max connections in pool, use default if not set
+	MinIdleConns    int                `json:"minIdleConns,omitempty" yaml:"min_idle_conns,omitempty"`
+	MaxIdleConns    int                `json:"maxIdleConns,omitempty" yaml:"max_idle_conns,omitempty"`
+	ConnMaxLifetime int                `json:"connMaxLifetime,omitempty" yaml:"conn_max_lifetime,omitempty"` // in seconds, never close by age if not set
+	ReadOnly        bool               `json:"readOnly,omitempty" yaml:"read_only,omitempty"`                // reject all write commands
+	DisableFlush    bool               `json:"disableFlush,omitempty" yaml:"disable_flush,omitempty"`        // reject flushing databases
+	ReadFromReplica bool               `json:"readFromReplica,omitempty" yaml:"read_from_replica,omitempty"` // route read-only commands to replicas, only for cluster mode, ignored for sentinel
+	Protocol        int                `json:"protocol,omitempty" yaml:"protocol,omitempty"`                 // RESP protocol version 2 or 3, negotiate automatically if not set
 	DBFilterType    string             `json:"dbFilterType" yaml:"db_filter_type,omitempty"`
 	DBFilterList    []int              `json:"dbFilterList" yaml:"db_filter_list,omitempty"`
 	KeyView         int                `json:"keyView,omitempty" yaml:"key_view,omitempty"`
 	LoadSize        int                `json:"loadSize,omitempty" yaml:"load_size,omitempty"`
 	MarkColor       string             `json:"markColor,omitempty" yaml:"mark_color,omitempty"`
+	DisplayName     string             `json:"displayName,omitempty" yaml:"display_name,omitempty"` // alias shown instead of name
+	Environment     string             `json:"environment,omitempty" yaml:"environment,omitempty"`  // environment label like "prod", "staging"
+	Tags            []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
 	RefreshInterval int                `json:"refreshInterval,omitempty" yaml:"refresh_interval,omitempty"`
+	InitCommands    []string           `json:"initCommands,omitempty" yaml:"init_commands,omitempty"` // commands executed after each connection established
+	Pinned          bool               `json:"pinned,omitempty" yaml:"pinned,omitempty"`
+	LastConnected   int64              `json:"lastConnected,omitempty" yaml:"last_connected,omitempty"` // unix milliseconds of last opened
 	Alias           map[int]string     `json:"alias,omitempty" yaml:"alias,omitempty"`
 	SSL             ConnectionSSL      `json:"ssl,omitempty" yaml:"ssl,omitempty"`
 	SSH             ConnectionSSH      `json:"ssh,omitempty" yaml:"ssh,omitempty"`
 	Sentinel        ConnectionSentinel `json:"sentinel,omitempty" yaml:"sentinel,omitempty"`
 	Cluster         ConnectionCluster  `json:"cluster,omitempty" yaml:"cluster,omitempty"`
 	Proxy           ConnectionProxy    `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	ReplicaReadOnly bool               `json:"-" yaml:"-"`                   // send READONLY after connected, only for replica session of cluster
+	ClientName      string             `json:"-" yaml:"-"`                   // name set by CLIENT SETNAME, generated from template in preferences
+	Temporary       bool               `json:"temporary,omitempty" yaml:"-"` // unsaved connection opened by quick connect
 }
 
 type Connection struct {
 	ConnectionConfig `json:",inline" yaml:",inline"`
-	Type             string       `json:"type,omitempty" yaml:"type,omitempty"`
-	Connections      []Connection `json:"connections,omitempty" yaml:"connections,omitempty"`
+	Type             string      `json:"type,omitempty" yaml:"type,omitempty"`
+	Connections      Connections `json:"connections,omitempty" yaml:"connections,omitempty"`
 }
 
 type Connections []Connection
@@ -50,34 +72,88 @@ type ConnectionDB struct {
 type ConnectionSSL struct {
 	Enable        bool   `json:"enable,omitempty" yaml:"enable,omitempty"`
 	KeyFile       string `json:"keyFile,omitempty" yaml:"keyfile,omitempty"`
+	KeyPassphrase string `json:"keyPassphrase,omitempty" yaml:"key_passphrase,omitempty"` // passphrase of encrypted private key
 	CertFile      string `json:"certFile,omitempty" yaml:"certfile,omitempty"`
 	CAFile        string `json:"caFile,omitempty" yaml:"cafile,omitempty"`
 	AllowInsecure bool   `json:"allowInsecure,omitempty" yaml:"allow_insecure,omitempty"`
 	SNI           string `json:"sni,omitempty" yaml:"sni,omitempty"`
 }
 
+type ConnectionTLSCert struct {
+	Subject   string   `json:"subject"`
+	Issuer    string   `json:"issuer"`
+	Serial    string   `json:"serial"`
+	DNSNames  []string `json:"dnsNames,omitempty"`
+	NotBefore int64    `json:"notBefore"`
+	NotAfter  int64    `json:"notAfter"`
+}
+
+type ConnectionTLSState struct {
+	Version     string              `json:"version"`
+	CipherSuite string              `json:"cipherSuite"`
+	ServerName  string              `json:"serverName,omitempty"`
+	PeerCerts   []ConnectionTLSCert `json:"peerCerts"`
+}
+
+type ConnectionSummary struct {
+	Version    string  `json:"version"`
+	Mode       string  `json:"mode"` // standalone, cluster or sentinel
+	Role       string  `json:"role"`
+	UsedMemory int64   `json:"usedMemory"`
+	DBCount    int     `json:"dbCount"`
+	RTT        float64 `json:"rtt"` // average round trip time of ping in milliseconds
+	RTTMin     float64 `json:"rttMin"`
+	RTTMax     float64 `json:"rttMax"`
+}
+
 type ConnectionSSH struct {
-	Enable     bool   `json:"enable,omitempty" yaml:"enable,omitempty"`
-	Addr       string `json:"addr,omitempty" yaml:"addr,omitempty"`
-	Port       int    `json:"port,omitempty" yaml:"port,omitempty"`
-	LoginType  string `json:"loginType,omitempty" yaml:"login_type"`
-	Username   string `json:"username,omitempty" yaml:"username,omitempty"`
-	Password   string `json:"password,omitempty" yaml:"password,omitempty"`
-	PKFile     string `json:"pkFile,omitempty" yaml:"pk_file,omitempty"`
-	Passphrase string `json:"passphrase,omitempty" yaml:"passphrase,omitempty"`
+	Enable            bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	UseConfig         bool `json:"useConfig,omitempty" yaml:"use_config,omitempty"` // treat addr as host alias in ~/.ssh/config
+	ConnectionSSHHost `json:",inline" yaml:",inline"`
+	JumpHosts         []ConnectionSSHHost `json:"jumpHosts,omitempty" yaml:"jump_hosts,omitempty"` // ordered hops before reaching the ssh server
+}
+
+type ConnectionSSHHost struct {
+	Addr          string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	Port          int    `json:"port,omitempty" yaml:"port,omitempty"`
+	LoginType     string `json:"loginType,omitempty" yaml:"login_type"`
+	Username      string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password      string `json:"password,omitempty" yaml:"password,omitempty"`
+	PKFile        string `json:"pkFile,omitempty" yaml:"pk_file,omitempty"`
+	Passphrase    string `json:"passphrase,omitempty" yaml:"passphrase,omitempty"`
+	HostKeyPolicy string `json:"hostKeyPolicy,omitempty" yaml:"host_key_policy,omitempty"` // "insecure"(default), "known_hosts" or "fingerprint"
+	HostKey       string `json:"hostKey,omitempty" yaml:"host_key,omitempty"`              // expected SHA256 fingerprint for "fingerprint" policy
 }
 
 type ConnectionSentinel struct {
-	Enable   bool   `json:"enable,omitempty" yaml:"enable,omitempty"`
-	Master   string `json:"master,omitempty" yaml:"master,omitempty"`
-	Username string `json:"username,omitempty" yaml:"username,omitempty"`
-	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	Enable   bool     `json:"enable,omitempty" yaml:"enable,omitempty"`
+	Master   string   `json:"master,omitempty" yaml:"master,omitempty"`
+	Username string   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string   `json:"password,omitempty" yaml:"password,omitempty"`
+	Addrs    []string `json:"addrs,omitempty" yaml:"addrs,omitempty"` // extra sentinel nodes in "host:port" format
+}
+
+type SentinelNode struct {
+	Name  string `json:"name,omitempty"`
+	Addr  string `json:"addr"`
+	Flags string `json:"flags,omitempty"`
+}
+
+type SentinelTopology struct {
+	Master    SentinelNode   `json:"master"`
+	Replicas  []SentinelNode `json:"replicas"`
+	Sentinels []SentinelNode `json:"sentinels"`
 }
 
 type ConnectionCluster struct {
 	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
 }
 
+const PROXY_DEFAULT = 0 // follow the default proxy in preferences, no proxy if not set
+const PROXY_SYSTEM = 1
+const PROXY_CUSTOM = 2
+const PROXY_NONE = 3 // always connect directly, ignore the default proxy
+
 type ConnectionProxy struct {
 	Type     int    `json:"type,omitempty" yaml:"type,omitempty"`
 	Schema   string `json:"schema,omitempty" yaml:"schema,omitempty"`
@@ -86,3 +162,14 @@ type ConnectionProxy struct {
 	Username string `json:"username,omitempty" yaml:"username,omitempty"`
 	Password string `json:"password,omitempty" yaml:"password,omitempty"`
 }
+
+type ConnectionPoolStats struct {
+	Hits         uint32 `json:"hits"`
+	Misses       uint32 `json:"misses"`
+	Timeouts     uint32 `json:"timeouts"`
+	WaitCount    uint32 `json:"waitCount"`
+	WaitDuration int64  `json:"waitDuration"` // total waiting time in milliseconds
+	TotalConns   uint32 `json:"totalConns"`
+	IdleConns    uint32 `json:"idleConns"`
+	StaleConns   uint32 `json:"staleConns"`
+}
@@ -0,0 +1,27 @@
+package types
+
+type ConnectionState struct {
+	LastDB       int              `json:"lastDB" yaml:"-"` // saved with connection profile
+	KeyFilter    string           `json:"keyFilter,omitempty" yaml:"key_filter,omitempty"`
+	ExpandedKeys []string         `json:"expandedKeys,omitempty" yaml:"expanded_keys,omitempty"` // expanded nodes in key tree
+	Bookmarks    map[int][]string `json:"-" yaml:"bookmarks,omitempty"`                          // bookmarked keys of each database
+	RecentKeys   []RecentKey      `json:"-" yaml:"recent_keys,omitempty"`                        // recently viewed keys, the latest comes first
+	Notes        []KeyNote        `json:"-" yaml:"notes,omitempty"`                              // notes of keys or namespaces
+}
+
+type RecentKey struct {
+	DB        int    `json:"db" yaml:"db"`
+	Key       string `json:"-" yaml:"key"`
+	Type      string `json:"type,omitempty" yaml:"type,omitempty"`
+	Timestamp int64  `json:"timestamp" yaml:"timestamp"`
+}
+
+type ConnectionStates map[string]ConnectionState
+
+type KeyNote struct {
+	DB        int      `json:"db" yaml:"db"`
+	Pattern   string   `json:"pattern" yaml:"pattern"` // exact key or glob-style pattern like "cfg:v2:*"
+	Note      string   `json:"note,omitempty" yaml:"note,omitempty"`
+	Labels    []string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	UpdatedAt int64    `json:"updatedAt" yaml:"updated_at"`
+}
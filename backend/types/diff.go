@@ -0,0 +1,42 @@
+package types
+
+type DiffEntry struct {
+	Field string `json:"field"` // hash field, set/zset member, list index or stream entry id, empty for string
+	Old   any    `json:"old,omitempty"`
+	New   any    `json:"new,omitempty"`
+}
+
+type KeyDiff struct {
+	Type    string      `json:"type"`
+	OldType string      `json:"oldType,omitempty"` // previous type if type changed, all entries are replaced
+	Added   []DiffEntry `json:"added,omitempty"`
+	Removed []DiffEntry `json:"removed,omitempty"`
+	Changed []DiffEntry `json:"changed,omitempty"`
+	Lines   []DiffLine  `json:"lines,omitempty"` // line diff of string value
+}
+
+func (d KeyDiff) Empty() bool {
+	return len(d.OldType) <= 0 && len(d.Added) <= 0 && len(d.Removed) <= 0 && len(d.Changed) <= 0
+}
+
+type KeyWatchParam struct {
+	Server   string `json:"server"`
+	DB       int    `json:"db"`
+	Key      any    `json:"key"`
+	Interval int64  `json:"interval,omitempty"` // polling interval in milliseconds if keyspace notification is not available
+}
+
+type DiffLine struct {
+	Op   string `json:"op"` // "+" for added, "-" for removed, " " for unchanged
+	Line any    `json:"line"`
+}
+
+type KeyDiffParam struct {
+	Server       string `json:"server"`
+	DB           int    `json:"db"`
+	Key          any    `json:"key"`
+	Snapshot     string `json:"snapshot,omitempty"`     // compare captured snapshot with current value of key if not empty
+	TargetServer string `json:"targetServer,omitempty"` // same as server if empty
+	TargetDB     int    `json:"targetDB"`
+	TargetKey    any    `json:"targetKey,omitempty"`
+}
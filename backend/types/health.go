@@ -0,0 +1,17 @@
+package types
+
+const (
+	HEALTH_REACHABLE   = "reachable"
+	HEALTH_AUTH_FAILED = "auth_failed"
+	HEALTH_TIMEOUT     = "timeout"
+	HEALTH_UNREACHABLE = "unreachable"
+)
+
+type ConnectionHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Version   string `json:"version,omitempty"`
+	Latency   int64  `json:"latency"` // ping cost in milliseconds
+	Error     string `json:"error,omitempty"`
+	CheckedAt int64  `json:"checkedAt"`
+}
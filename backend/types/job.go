@@ -0,0 +1,29 @@
+package types
+
+const (
+	JOB_RUNNING  = "running"
+	JOB_DONE     = "done"
+	JOB_CANCELED = "canceled"
+	JOB_FAILED   = "failed"
+)
+
+type Job struct {
+	ID        string  `json:"id"`
+	Kind      string  `json:"kind"` // kind of operation like "delete", "export", "import" and "analyze"
+	Title     string  `json:"title"`
+	Server    string  `json:"server"`
+	DB        int     `json:"db"`
+	Status    string  `json:"status"`
+	Processed int64   `json:"processed"`
+	Total     int64   `json:"total"`    // 0 if unknown
+	Progress  float64 `json:"progress"` // percentage of processed, -1 if total unknown
+	ETA       int64   `json:"eta"`      // estimated remaining seconds, -1 if unknown
+	StartTime int64   `json:"startTime"`
+	EndTime   int64   `json:"endTime,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type JobLog struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
@@ -10,35 +10,49 @@ type KeySummaryParam struct {
 	Server string `json:"server"`
 	DB     int    `json:"db"`
 	Key    any    `json:"key"`
+	Object bool   `json:"object,omitempty"` // also load encoding, idle time and access frequency
 }
 
 type KeySummary struct {
-	Type   string `json:"type"`
-	TTL    int64  `json:"ttl,omitempty"`
-	Size   int64  `json:"size,omitempty"`
-	Length int64  `json:"length,omitempty"`
+	Type     string `json:"type"`
+	TTL      int64  `json:"ttl,omitempty"`
+	ExpireAt string `json:"expireAt,omitempty"` // absolute expiry in local time, empty if no ttl
+	Size     int64  `json:"size,omitempty"`
+	Length   int64  `json:"length,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Idle     *int64 `json:"idle,omitempty"` // idle seconds, only available if maxmemory policy is not LFU
+	Freq     *int64 `json:"freq,omitempty"` // access frequency, only available if maxmemory policy is LFU
+	// estimated cardinality by PFCOUNT, only available if string is a hyperloglog
+	Cardinality *int64 `json:"cardinality,omitempty"`
 }
 
 type KeyDetailParam struct {
-	Server       string `json:"server"`
-	DB           int    `json:"db"`
-	Key          any    `json:"key"`
-	Format       string `json:"format,omitempty"`
-	Decode       string `json:"decode,omitempty"`
-	MatchPattern string `json:"matchPattern,omitempty"`
-	Reset        bool   `json:"reset"`
-	Full         bool   `json:"full"`
+	Server       string  `json:"server"`
+	DB           int     `json:"db"`
+	Key          any     `json:"key"`
+	Format       string  `json:"format,omitempty"`
+	Decode       string  `json:"decode,omitempty"`
+	MatchPattern string  `json:"matchPattern,omitempty"`
+	Reset        bool    `json:"reset"`
+	Full         bool    `json:"full"`
+	Partial      bool    `json:"partial,omitempty"` // only load leading part of large string, the rest can be loaded by GetStringRange
+	Cursor       *uint64 `json:"cursor,omitempty"`  // continue scanning from cursor returned last time, instead of the cached one
 }
 
 type KeyDetail struct {
-	Value   any    `json:"value"`
-	KeyType string `json:"key_type"`
-	Length  int64  `json:"length,omitempty"`
-	Format  string `json:"format,omitempty"`
-	Decode  string `json:"decode,omitempty"`
-	Match   string `json:"match,omitempty"`
-	Reset   bool   `json:"reset"`
-	End     bool   `json:"end"`
+	Value   any       `json:"value"`
+	KeyType string    `json:"key_type"`
+	Length  int64     `json:"length,omitempty"`
+	Format  string    `json:"format,omitempty"`
+	Decode  string    `json:"decode,omitempty"`
+	Match   string    `json:"match,omitempty"`
+	Reset   bool      `json:"reset"`
+	End     bool      `json:"end"`
+	Cursor  uint64    `json:"cursor"`            // cursor for loading next page, 0 if end
+	Partial bool      `json:"partial,omitempty"` // only leading part of large string is loaded, length is the total bytes
+	Notes   []KeyNote `json:"notes,omitempty"`   // local notes matching the key
+	// estimated cardinality by PFCOUNT, only available if string is a hyperloglog
+	Cardinality *int64 `json:"cardinality,omitempty"`
 }
 
 type SetKeyParam struct {
@@ -50,6 +64,11 @@ type SetKeyParam struct {
 	TTL     int64  `json:"ttl"`
 	Format  string `json:"format,omitempty"`
 	Decode  string `json:"decode,omitempty"`
+	// value when loaded, saving is aborted if the current value differs, only for string and json,
+	// members of hash, list, set and zset are saved by their own api without checking
+	Expected any `json:"expected,omitempty"`
+	// string value is loaded partially, saving is rejected as the rest would be lost
+	Partial bool `json:"partial,omitempty"`
 }
 
 type SetListParam struct {
@@ -102,6 +121,23 @@ type SetZSetParam struct {
 	RetDecode string  `json:"retDecode,omitempty"`
 }
 
+type GetJSONParam struct {
+	Server string `json:"server"`
+	DB     int    `json:"db"`
+	Key    any    `json:"key"`
+	Path   string `json:"path,omitempty"`   // root path "$" if empty
+	Format string `json:"format,omitempty"` // "JSON" for pretty view, "Raw" for compact view
+}
+
+type SetJSONParam struct {
+	Server string `json:"server"`
+	DB     int    `json:"db"`
+	Key    any    `json:"key"`
+	Path   string `json:"path,omitempty"`
+	Value  string `json:"value"`          // value in JSON format
+	Mode   string `json:"mode,omitempty"` // "NX": only set if path not exists, "XX": only set if path exists
+}
+
 type GetHashParam struct {
 	Server string `json:"server"`
 	DB     int    `json:"db"`
@@ -110,3 +146,105 @@ type GetHashParam struct {
 	Format string `json:"format,omitempty"`
 	Decode string `json:"decode,omitempty"`
 }
+
+type TransferKeysParam struct {
+	Server       string `json:"server"`
+	DB           int    `json:"db"`
+	Keys         []any  `json:"keys"`
+	TargetServer string `json:"targetServer"` // same as server if empty
+	TargetDB     int    `json:"targetDB"`
+	Move         bool   `json:"move,omitempty"`    // delete source keys after transferred
+	Replace      bool   `json:"replace,omitempty"` // replace existing target keys, or skip them
+	SerialNo     string `json:"serialNo"`
+}
+
+type SearchEntriesParam struct {
+	Server  string `json:"server"`
+	DB      int    `json:"db"`
+	Key     any    `json:"key"`
+	Pattern string `json:"pattern"`          // glob-style pattern of hash field, set/zset member or list element
+	Cursor  uint64 `json:"cursor,omitempty"` // scan cursor for hash, set and zset, or start index for list
+	Count   int    `json:"count,omitempty"`  // max matched entries in one page
+}
+
+type ValueSearchParam struct {
+	Server     string `json:"server"`
+	DB         int    `json:"db"`
+	Pattern    string `json:"pattern"`        // pattern of keys to search in
+	Type       string `json:"type,omitempty"` // only search keys of specified type
+	Keyword    string `json:"keyword"`
+	Regex      bool   `json:"regex,omitempty"` // keyword is a regular expression
+	IgnoreCase bool   `json:"ignoreCase,omitempty"`
+	SerialNo   string `json:"serialNo"`
+}
+
+type ZSetRangeParam struct {
+	Server  string `json:"server"`
+	DB      int    `json:"db"`
+	Key     any    `json:"key"`
+	By      string `json:"by"`            // "score" or "lex"
+	Min     string `json:"min,omitempty"` // like "1", "(1" or "-inf" by score, "[a", "(a" or "-" by lex
+	Max     string `json:"max,omitempty"`
+	Offset  int64  `json:"offset,omitempty"`
+	Count   int64  `json:"count,omitempty"`
+	Reverse bool   `json:"reverse,omitempty"` // from max to min
+	Decode  string `json:"decode,omitempty"`
+	Format  string `json:"format,omitempty"`
+}
+
+type ListRangeParam struct {
+	Server string `json:"server"`
+	DB     int    `json:"db"`
+	Key    any    `json:"key"`
+	Index  int64  `json:"index"`           // index to jump to, negative index counts from the tail
+	Count  int64  `json:"count,omitempty"` // elements in page around index
+	Decode string `json:"decode,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+type ListPositionParam struct {
+	Server  string `json:"server"`
+	DB      int    `json:"db"`
+	Key     any    `json:"key"`
+	Element any    `json:"element"`
+	Rank    int64  `json:"rank,omitempty"`   // start from the rank-th match, negative rank searches from the tail
+	Count   int64  `json:"count,omitempty"`  // max positions to return
+	MaxLen  int64  `json:"maxLen,omitempty"` // only compare this number of elements, 0 for no limit
+}
+
+type SetOperationParam struct {
+	Server    string    `json:"server"`
+	DB        int       `json:"db"`
+	Op        string    `json:"op"`   // "inter", "union" or "diff"
+	ZSet      bool      `json:"zset"` // operate on sorted sets
+	Keys      []any     `json:"keys"`
+	Weights   []float64 `json:"weights,omitempty"`   // multiplication factor of each zset
+	Aggregate string    `json:"aggregate,omitempty"` // score aggregation of zset: "SUM"(default), "MIN" or "MAX"
+	Target    any       `json:"target,omitempty"`    // store result into this key, or preview result if empty
+	Limit     int       `json:"limit,omitempty"`     // max members to preview
+}
+
+type FlushParam struct {
+	Server  string `json:"server"`
+	DB      int    `json:"db"`
+	All     bool   `json:"all,omitempty"` // flush all databases by "FLUSHALL"
+	Token   string `json:"token"`         // one-time token returned by PrepareFlush
+	Confirm string `json:"confirm"`       // text typed by user, should be the same as confirm text returned by PrepareFlush
+}
+
+type ConvertTypeParam struct {
+	Server string  `json:"server"`
+	DB     int     `json:"db"`
+	Key    any     `json:"key"`
+	To     string  `json:"to"`               // target type: "set", "zset", "list", "hash", or "json" for json string
+	Score  float64 `json:"score,omitempty"`  // default score of members converted to zset
+	Target any     `json:"target,omitempty"` // save to another new key, or replace the original key if empty
+}
+
+type KeyListExportParam struct {
+	Server  string `json:"server"`
+	DB      int    `json:"db"`
+	Pattern string `json:"pattern"`
+	Type    string `json:"type,omitempty"` // only export keys of this type
+	Path    string `json:"path,omitempty"` // csv file to save, or copy to clipboard if empty
+}
@@ -0,0 +1,15 @@
+package types
+
+type KeyspaceEventParam struct {
+	Server  string   `json:"server"`
+	DB      int      `json:"db"`
+	Pattern string   `json:"pattern,omitempty"` // glob-style pattern of keys to watch, "*" if empty
+	Classes string   `json:"classes,omitempty"` // classes of "notify-keyspace-events" to enable, like "g$xe"
+	Events  []string `json:"events,omitempty"`  // only push specified events like "set" and "expired", all if empty
+}
+
+type KeyspaceEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	Key       any    `json:"key"`
+	Event     string `json:"event"`
+}
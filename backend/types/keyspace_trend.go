@@ -0,0 +1,19 @@
+package types
+
+// KeyspaceSample size of a database recorded at a moment
+type KeyspaceSample struct {
+	Timestamp int64  `json:"timestamp"` // unix milliseconds
+	Server    string `json:"server"`
+	DB        int    `json:"db"`
+	Keys      int64  `json:"keys"`
+	Expires   int64  `json:"expires"`
+	AvgTTL    int64  `json:"avgTtl"` // milliseconds, from INFO keyspace
+}
+
+type KeyspaceTrendQuery struct {
+	Server    string `json:"server"`
+	DB        *int   `json:"db,omitempty"` // all databases if not set
+	StartTime int64  `json:"startTime,omitempty"`
+	EndTime   int64  `json:"endTime,omitempty"`
+	MaxPoints int    `json:"maxPoints,omitempty"` // downsample each database to at most points, no limit if not set
+}
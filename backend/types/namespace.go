@@ -0,0 +1,23 @@
+package types
+
+type NamespaceStatParam struct {
+	Server     string `json:"server"`
+	DB         int    `json:"db"`
+	Pattern    string `json:"pattern,omitempty"`    // only analyze keys matching pattern
+	Depth      int    `json:"depth,omitempty"`      // levels of namespace to group by, 1 for top-level namespace
+	SampleRate int    `json:"sampleRate,omitempty"` // get memory usage of one in every n keys, 1 for all keys
+	SerialNo   string `json:"serialNo"`
+}
+
+type NamespaceStat struct {
+	Namespace     string           `json:"namespace"` // empty for keys without separator
+	Keys          int64            `json:"keys"`
+	Types         map[string]int64 `json:"types"`         // key count of each type
+	Memory        int64            `json:"memory"`        // estimated total memory usage by sampled keys
+	SampledKeys   int64            `json:"sampledKeys"`   // keys that memory usage was got
+	SampledMemory int64            `json:"sampledMemory"` // memory usage of sampled keys
+	TTL           map[string]int64 `json:"ttl"`           // key count of each ttl range, see NamespaceTTLRanges
+}
+
+// NamespaceTTLRanges ranges of ttl distribution, keys without expiration are counted as "persist"
+var NamespaceTTLRanges = []string{"persist", "<1m", "<1h", "<1d", "<7d", ">=7d"}
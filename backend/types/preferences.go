@@ -3,11 +3,15 @@ package types
 import "tinyrdm/backend/consts"
 
 type Preferences struct {
-	Behavior PreferencesBehavior  `json:"behavior" yaml:"behavior"`
-	General  PreferencesGeneral   `json:"general" yaml:"general"`
-	Editor   PreferencesEditor    `json:"editor" yaml:"editor"`
-	Cli      PreferencesCli       `json:"cli" yaml:"cli"`
-	Decoder  []PreferencesDecoder `json:"decoder" yaml:"decoder,omitempty"`
+	Behavior   PreferencesBehavior    `json:"behavior" yaml:"behavior"`
+	General    PreferencesGeneral     `json:"general" yaml:"general"`
+	Editor     PreferencesEditor      `json:"editor" yaml:"editor"`
+	Cli        PreferencesCli         `json:"cli" yaml:"cli"`
+	Decoder    []PreferencesDecoder   `json:"decoder" yaml:"decoder,omitempty"`
+	KeyActions []PreferencesKeyAction `json:"keyActions" yaml:"key_actions,omitempty"` // user-defined lua scripts as context actions of keys
+	Protobuf   PreferencesProtobuf    `json:"protobuf" yaml:"protobuf,omitempty"`
+	Avro       PreferencesAvro        `json:"avro" yaml:"avro,omitempty"`
+	KeyRules   []PreferencesKeyRule   `json:"keyRules" yaml:"key_rules,omitempty"` // default decode type and format of keys by pattern
 }
 
 func NewPreferences() Preferences {
@@ -18,13 +22,14 @@ func NewPreferences() Preferences {
 			WindowHeight: consts.DEFAULT_WINDOW_HEIGHT,
 		},
 		General: PreferencesGeneral{
-			Theme:        "auto",
-			Language:     "auto",
-			FontSize:     consts.DEFAULT_FONT_SIZE,
-			ScanSize:     consts.DEFAULT_SCAN_SIZE,
-			KeyIconStyle: 0,
-			CheckUpdate:  true,
-			AllowTrack:   true,
+			Theme:          "auto",
+			Language:       "auto",
+			FontSize:       consts.DEFAULT_FONT_SIZE,
+			ScanSize:       consts.DEFAULT_SCAN_SIZE,
+			KeyIconStyle:   0,
+			CheckUpdate:    true,
+			AllowTrack:     true,
+			LargeValueSize: consts.DEFAULT_LARGE_VALUE_SIZE,
 		},
 		Editor: PreferencesEditor{
 			FontSize:       consts.DEFAULT_FONT_SIZE,
@@ -38,7 +43,13 @@ func NewPreferences() Preferences {
 			FontSize:    consts.DEFAULT_FONT_SIZE,
 			CursorStyle: "block",
 		},
-		Decoder: []PreferencesDecoder{},
+		Decoder:    []PreferencesDecoder{},
+		KeyActions: []PreferencesKeyAction{},
+		Protobuf: PreferencesProtobuf{
+			Files:    []string{},
+			Mappings: []PreferencesProtobufMapping{},
+		},
+		KeyRules: []PreferencesKeyRule{},
 	}
 }
 
@@ -53,18 +64,21 @@ type PreferencesBehavior struct {
 }
 
 type PreferencesGeneral struct {
-	Theme           string   `json:"theme" yaml:"theme"`
-	Language        string   `json:"language" yaml:"language"`
-	Font            string   `json:"font" yaml:"font,omitempty"`
-	FontFamily      []string `json:"fontFamily" yaml:"font_family,omitempty"`
-	FontSize        int      `json:"fontSize" yaml:"font_size"`
-	ScanSize        int      `json:"scanSize" yaml:"scan_size"`
-	KeyIconStyle    int      `json:"keyIconStyle" yaml:"key_icon_style"`
-	UseSysProxy     bool     `json:"useSysProxy" yaml:"use_sys_proxy,omitempty"`
-	UseSysProxyHttp bool     `json:"useSysProxyHttp" yaml:"use_sys_proxy_http,omitempty"`
-	CheckUpdate     bool     `json:"checkUpdate" yaml:"check_update"`
-	SkipVersion     string   `json:"skipVersion" yaml:"skip_version,omitempty"`
-	AllowTrack      bool     `json:"allowTrack" yaml:"allow_track"`
+	Theme           string          `json:"theme" yaml:"theme"`
+	Language        string          `json:"language" yaml:"language"`
+	Font            string          `json:"font" yaml:"font,omitempty"`
+	FontFamily      []string        `json:"fontFamily" yaml:"font_family,omitempty"`
+	FontSize        int             `json:"fontSize" yaml:"font_size"`
+	ScanSize        int             `json:"scanSize" yaml:"scan_size"`
+	KeyIconStyle    int             `json:"keyIconStyle" yaml:"key_icon_style"`
+	UseSysProxy     bool            `json:"useSysProxy" yaml:"use_sys_proxy,omitempty"`
+	UseSysProxyHttp bool            `json:"useSysProxyHttp" yaml:"use_sys_proxy_http,omitempty"`
+	CheckUpdate     bool            `json:"checkUpdate" yaml:"check_update"`
+	SkipVersion     string          `json:"skipVersion" yaml:"skip_version,omitempty"`
+	AllowTrack      bool            `json:"allowTrack" yaml:"allow_track"`
+	DefaultProxy    ConnectionProxy `json:"defaultProxy" yaml:"default_proxy,omitempty"` // used by connections without proxy specified
+	ClientName      string          `json:"clientName" yaml:"client_name,omitempty"`     // template of client name, supports {hostname}, {connection} and {session}
+	LargeValueSize  int             `json:"largeValueSize" yaml:"large_value_size"`      // in KB, only load leading part of larger string value
 }
 
 type PreferencesEditor struct {
@@ -92,4 +106,34 @@ type PreferencesDecoder struct {
 	DecodeArgs []string `json:"decodeArgs" yaml:"decode_args,omitempty"`
 	EncodePath string   `json:"encodePath" yaml:"encode_path"`
 	EncodeArgs []string `json:"encodeArgs" yaml:"encode_args,omitempty"`
+	Chain      []string `json:"chain" yaml:"chain,omitempty"` // decode types applied in order like base64, gzip and protobuf, encoded in reverse on save
+}
+
+type PreferencesKeyAction struct {
+	Name   string   `json:"name" yaml:"name"`
+	Script string   `json:"script" yaml:"script"`                   // lua script, name of key is bound to KEYS[1]
+	Types  []string `json:"types,omitempty" yaml:"types,omitempty"` // only available for keys of these types, or all types if empty
+}
+
+type PreferencesProtobuf struct {
+	Files    []string                     `json:"files" yaml:"files,omitempty"` // .proto files or descriptor sets generated by protoc
+	Mappings []PreferencesProtobufMapping `json:"mappings" yaml:"mappings,omitempty"`
+}
+
+type PreferencesProtobufMapping struct {
+	Pattern string `json:"pattern" yaml:"pattern"` // glob pattern of key
+	Message string `json:"message" yaml:"message"` // full name of message type
+}
+
+type PreferencesKeyRule struct {
+	Pattern string `json:"pattern" yaml:"pattern"`         // glob pattern of key, the first matched rule is applied
+	Decode  string `json:"decode" yaml:"decode,omitempty"` // decode type like "GZip+Msgpack" or "Protobuf:pkg.Message", detect automatically if empty
+	Format  string `json:"format" yaml:"format,omitempty"` // view format, detect automatically if empty
+}
+
+type PreferencesAvro struct {
+	RegistryURL string `json:"registryUrl" yaml:"registry_url,omitempty"` // url of confluent schema registry, avro decoding is disabled if empty
+	Username    string `json:"username" yaml:"username,omitempty"`        // for basic auth, or api key of confluent cloud
+	Password    string `json:"password" yaml:"password,omitempty"`
+	SecretStore string `json:"secretStore,omitempty" yaml:"secret_store,omitempty"` // "keychain" if password is saved in os keychain
 }
@@ -0,0 +1,24 @@
+package types
+
+// key types of RedisBloom module
+const (
+	PROB_BLOOM   = "bloom"
+	PROB_CUCKOO  = "cuckoo"
+	PROB_CMS     = "cms"
+	PROB_TOPK    = "topk"
+	PROB_TDIGEST = "tdigest"
+)
+
+type ProbabilisticReserveParam struct {
+	Server      string  `json:"server"`
+	DB          int     `json:"db"`
+	Key         any     `json:"key"`
+	Type        string  `json:"type"`
+	ErrorRate   float64 `json:"errorRate,omitempty"`   // for bloom
+	Capacity    int64   `json:"capacity,omitempty"`    // for bloom and cuckoo
+	Width       int64   `json:"width,omitempty"`       // for cms and topk
+	Depth       int64   `json:"depth,omitempty"`       // for cms and topk
+	TopK        int64   `json:"topk,omitempty"`        // for topk
+	Decay       float64 `json:"decay,omitempty"`       // for topk
+	Compression int64   `json:"compression,omitempty"` // for tdigest
+}
@@ -16,6 +16,7 @@ type HashEntryItem struct {
 	Key          string `json:"k"`
 	Value        any    `json:"v"`
 	DisplayValue string `json:"dv,omitempty"`
+	TTL          int64  `json:"ttl,omitempty"` // remaining seconds of field expiration, only available since Redis 7.4
 }
 
 type HashReplaceItem struct {
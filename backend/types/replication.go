@@ -0,0 +1,20 @@
+package types
+
+type ReplicaNode struct {
+	ID     string `json:"id,omitempty"`
+	Addr   string `json:"addr"`
+	Role   string `json:"role"`
+	State  string `json:"state,omitempty"` // link state or node flags
+	Offset int64  `json:"offset,omitempty"`
+	Lag    int64  `json:"lag,omitempty"`
+}
+
+type ReplicationShard struct {
+	Master   ReplicaNode   `json:"master"`
+	Replicas []ReplicaNode `json:"replicas"`
+}
+
+type ReplicationTopology struct {
+	Mode   string             `json:"mode"` // standalone or cluster
+	Shards []ReplicationShard `json:"shards"`
+}
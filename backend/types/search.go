@@ -0,0 +1,59 @@
+package types
+
+type SearchField struct {
+	Name     string `json:"name"`            // field name, or JSON path for index on JSON
+	Alias    string `json:"alias,omitempty"` // attribute name used in query, required for JSON path
+	Type     string `json:"type"`            // TEXT, NUMERIC, TAG or GEO
+	Sortable bool   `json:"sortable,omitempty"`
+}
+
+type SearchIndexParam struct {
+	Server   string        `json:"server"`
+	DB       int           `json:"db"`
+	Index    string        `json:"index"`
+	On       string        `json:"on,omitempty"`       // HASH(default) or JSON
+	Prefixes []string      `json:"prefixes,omitempty"` // index keys with prefixes only
+	Schema   []SearchField `json:"schema"`
+}
+
+type SearchQueryParam struct {
+	Server   string   `json:"server"`
+	DB       int      `json:"db"`
+	Index    string   `json:"index"`
+	Query    string   `json:"query"`
+	Offset   int      `json:"offset,omitempty"`
+	Limit    int      `json:"limit,omitempty"` // page size, 10 if not set
+	SortBy   string   `json:"sortBy,omitempty"`
+	SortDesc bool     `json:"sortDesc,omitempty"`
+	Return   []string `json:"return,omitempty"` // return specified fields only
+}
+
+type SearchAggregateParam struct {
+	Server string   `json:"server"`
+	DB     int      `json:"db"`
+	Index  string   `json:"index"`
+	Query  string   `json:"query"`
+	Args   []string `json:"args,omitempty"` // pipeline arguments like "GROUPBY 1 @city REDUCE COUNT 0 AS count"
+	Offset int      `json:"offset,omitempty"`
+	Limit  int      `json:"limit,omitempty"`
+}
+
+type SearchDocument struct {
+	ID     string            `json:"id"`
+	Score  float64           `json:"score,omitempty"`
+	Fields map[string]string `json:"fields"`
+}
+
+type SearchResult struct {
+	Total  int64            `json:"total"`
+	Offset int              `json:"offset"`
+	Limit  int              `json:"limit"`
+	Docs   []SearchDocument `json:"docs"`
+}
+
+type SearchAggregateResult struct {
+	Total  int64               `json:"total"`
+	Offset int                 `json:"offset"`
+	Limit  int                 `json:"limit"`
+	Rows   []map[string]string `json:"rows"`
+}
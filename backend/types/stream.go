@@ -0,0 +1,53 @@
+package types
+
+type StreamGroup struct {
+	Name            string `json:"name"`
+	Consumers       int64  `json:"consumers"`
+	Pending         int64  `json:"pending"`
+	LastDeliveredID string `json:"lastDeliveredId"`
+	EntriesRead     int64  `json:"entriesRead"`
+	Lag             int64  `json:"lag"`
+}
+
+type StreamConsumer struct {
+	Name     string `json:"name"`
+	Pending  int64  `json:"pending"`
+	Idle     int64  `json:"idle"`     // milliseconds since last attempted interaction
+	Inactive int64  `json:"inactive"` // milliseconds since last successful interaction, -1 if never
+}
+
+type StreamPendingEntry struct {
+	ID         string `json:"id"`
+	Consumer   string `json:"consumer"`
+	Idle       int64  `json:"idle"` // milliseconds since last delivered
+	RetryCount int64  `json:"retryCount"`
+}
+
+type StreamPendingSummary struct {
+	Count     int64                `json:"count"`
+	Lower     string               `json:"lower"`
+	Higher    string               `json:"higher"`
+	Consumers map[string]int64     `json:"consumers"`
+	Entries   []StreamPendingEntry `json:"entries"`
+}
+
+type StreamClaimParam struct {
+	Server   string   `json:"server"`
+	DB       int      `json:"db"`
+	Key      any      `json:"key"`
+	Group    string   `json:"group"`
+	Consumer string   `json:"consumer"`        // new owner of claimed entries
+	MinIdle  int64    `json:"minIdle"`         // only claim entries idle longer than this, in milliseconds
+	IDs      []string `json:"ids,omitempty"`   // claim specified entries by XCLAIM, or use XAUTOCLAIM if empty
+	Start    string   `json:"start,omitempty"` // start id of XAUTOCLAIM, "0-0" if empty
+	Count    int64    `json:"count,omitempty"` // max entries to claim by XAUTOCLAIM
+}
+
+type StreamTailParam struct {
+	Server string `json:"server"`
+	DB     int    `json:"db"`
+	Key    any    `json:"key"`
+	Start  string `json:"start,omitempty"`  // follow entries after this id, "$" for only new entries if empty
+	Decode string `json:"decode,omitempty"` // decode type applied to each field value
+	Format string `json:"format,omitempty"` // view format applied to each field value
+}
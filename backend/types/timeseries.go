@@ -0,0 +1,43 @@
+package types
+
+type TimeSeriesSample struct {
+	Timestamp int64   `json:"t"` // unix milliseconds
+	Value     float64 `json:"v"`
+}
+
+type TimeSeriesInfo struct {
+	TotalSamples    int64             `json:"totalSamples"`
+	MemoryUsage     int64             `json:"memoryUsage"`
+	FirstTimestamp  int64             `json:"firstTimestamp"`
+	LastTimestamp   int64             `json:"lastTimestamp"`
+	RetentionTime   int64             `json:"retentionTime"` // in milliseconds, 0 means never expire
+	ChunkCount      int64             `json:"chunkCount"`
+	ChunkSize       int64             `json:"chunkSize"`
+	ChunkType       string            `json:"chunkType,omitempty"`
+	DuplicatePolicy string            `json:"duplicatePolicy,omitempty"`
+	SourceKey       string            `json:"sourceKey,omitempty"`
+	Labels          map[string]string `json:"labels"`
+	Raw             map[string]any    `json:"raw"` // all fields returned by TS.INFO
+}
+
+type TimeSeriesRangeParam struct {
+	Server         string   `json:"server"`
+	DB             int      `json:"db"`
+	Key            any      `json:"key,omitempty"`     // for TS.RANGE
+	Filters        []string `json:"filters,omitempty"` // for TS.MRANGE, like "sensor=temp"
+	GroupBy        string   `json:"groupBy,omitempty"` // label to group series by in TS.MRANGE
+	Reduce         string   `json:"reduce,omitempty"`  // reducer of grouped series, like "sum", "max"
+	From           int64    `json:"from,omitempty"`    // start timestamp, earliest if not set
+	To             int64    `json:"to,omitempty"`      // end timestamp, latest if not set
+	Aggregation    string   `json:"aggregation,omitempty"`
+	BucketDuration int64    `json:"bucketDuration,omitempty"` // in milliseconds, required for aggregation
+	Count          int64    `json:"count,omitempty"`          // max samples to return, no limit if not set
+	Reverse        bool     `json:"reverse,omitempty"`
+	SerialNo       string   `json:"serialNo,omitempty"` // stream samples in batches by event "tsrange:<serialNo>" if set
+}
+
+type TimeSeriesSeries struct {
+	Key     string             `json:"key"`
+	Labels  map[string]string  `json:"labels"`
+	Samples []TimeSeriesSample `json:"samples"`
+}
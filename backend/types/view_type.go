@@ -7,14 +7,30 @@ const FORMAT_YAML = "YAML"
 const FORMAT_XML = "XML"
 const FORMAT_HEX = "Hex"
 const FORMAT_BINARY = "Binary"
+const FORMAT_TIMESTAMP = "Timestamp"
 
 const DECODE_NONE = "None"
 const DECODE_BASE64 = "Base64"
 const DECODE_GZIP = "GZip"
 const DECODE_DEFLATE = "Deflate"
+const DECODE_ZLIB = "Zlib"
 const DECODE_ZSTD = "ZStd"
 const DECODE_LZ4 = "LZ4"
 const DECODE_BROTLI = "Brotli"
+const DECODE_SNAPPY = "Snappy"
 const DECODE_MSGPACK = "Msgpack"
+const DECODE_BSON = "BSON"
+const DECODE_CBOR = "CBOR"
 const DECODE_PHP = "PHP"
+const DECODE_IGBINARY = "Igbinary"
 const DECODE_PICKLE = "Pickle"
+const DECODE_JAVA = "Java"
+const DECODE_PROTOBUF = "Protobuf"
+const DECODE_AVRO = "Avro"
+const DECODE_THRIFT = "Thrift"
+const DECODE_KRYO = "Kryo"
+const DECODE_GBK = "GBK"
+const DECODE_SHIFT_JIS = "Shift-JIS"
+const DECODE_BIG5 = "Big5"
+const DECODE_LATIN1 = "Latin-1"
+const DECODE_JWT = "JWT"
@@ -0,0 +1,280 @@
+package avroutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errMalformed = errors.New("malformed avro data")
+
+// max nested level accepted
+const maxDepth = 512
+
+type decoder struct {
+	buf   []byte
+	pos   int
+	depth int
+	out   bytes.Buffer
+}
+
+// Decode render avro binary data as json by schema, the whole content must be consumed.
+// union is rendered as value of the selected branch, bytes and fixed in base64,
+// and logical types of date, time and timestamp are rendered in iso format
+func (s *Schema) Decode(buf []byte) (string, error) {
+	d := &decoder{buf: buf}
+	if err := d.readValue(s); err != nil {
+		return "", err
+	}
+	if d.pos != len(d.buf) {
+		return "", errMalformed
+	}
+	return d.out.String(), nil
+}
+
+// read zigzag encoded variable-length integer
+func (d *decoder) readLong() (int64, error) {
+	n, size := binary.Uvarint(d.buf[d.pos:])
+	if size <= 0 {
+		return 0, errMalformed
+	}
+	d.pos += size
+	return int64(n>>1) ^ -int64(n&1), nil
+}
+
+func (d *decoder) read(n int64) ([]byte, error) {
+	if n < 0 || n > int64(len(d.buf)-d.pos) {
+		return nil, errMalformed
+	}
+	d.pos += int(n)
+	return d.buf[d.pos-int(n) : d.pos], nil
+}
+
+func (d *decoder) readBytes() ([]byte, error) {
+	length, err := d.readLong()
+	if err != nil {
+		return nil, err
+	}
+	return d.read(length)
+}
+
+// write string in json without escaping html characters
+func (d *decoder) writeString(s string) {
+	encoder := json.NewEncoder(&d.out)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	// remove the trailing newline
+	d.out.Truncate(d.out.Len() - 1)
+}
+
+func (d *decoder) writeFloat(f float64, bitSize int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		// not allowed in json
+		d.writeString(strconv.FormatFloat(f, 'g', -1, bitSize))
+		return
+	}
+	d.out.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
+
+// read count of items in block, the size in bytes follows if count is negative
+func (d *decoder) readBlockCount() (int64, error) {
+	count, err := d.readLong()
+	if err != nil {
+		return 0, err
+	}
+	if count < 0 {
+		if _, err = d.readLong(); err != nil {
+			return 0, err
+		}
+		count = -count
+	}
+	if count > int64(len(d.buf)-d.pos) {
+		return 0, errMalformed
+	}
+	return count, nil
+}
+
+func (d *decoder) readValue(s *Schema) error {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > maxDepth {
+		return errors.New("avro data nested too deep")
+	}
+
+	switch s.Type {
+	case "null":
+		d.out.WriteString("null")
+	case "boolean":
+		b, err := d.read(1)
+		if err != nil {
+			return err
+		}
+		d.out.WriteString(strconv.FormatBool(b[0] != 0))
+	case "int", "long":
+		n, err := d.readLong()
+		if err != nil {
+			return err
+		}
+		d.writeLogicalInt(s, n)
+	case "float":
+		b, err := d.read(4)
+		if err != nil {
+			return err
+		}
+		d.writeFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), 32)
+	case "double":
+		b, err := d.read(8)
+		if err != nil {
+			return err
+		}
+		d.writeFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)), 64)
+	case "bytes", "fixed":
+		var b []byte
+		var err error
+		if s.Type == "fixed" {
+			b, err = d.read(int64(s.Size))
+		} else {
+			b, err = d.readBytes()
+		}
+		if err != nil {
+			return err
+		}
+		if s.Logical == "decimal" {
+			d.out.WriteString(formatDecimal(b, s.Scale))
+		} else {
+			d.writeString(base64.StdEncoding.EncodeToString(b))
+		}
+	case "string":
+		b, err := d.readBytes()
+		if err != nil {
+			return err
+		}
+		d.writeString(string(b))
+	case "record":
+		d.out.WriteByte('{')
+		for i, field := range s.Fields {
+			if i > 0 {
+				d.out.WriteByte(',')
+			}
+			d.writeString(field.Name)
+			d.out.WriteByte(':')
+			if err := d.readValue(field.Type); err != nil {
+				return err
+			}
+		}
+		d.out.WriteByte('}')
+	case "enum":
+		idx, err := d.readLong()
+		if err != nil {
+			return err
+		}
+		if idx < 0 || idx >= int64(len(s.Symbols)) {
+			return errMalformed
+		}
+		d.writeString(s.Symbols[idx])
+	case "array", "map":
+		if s.Type == "array" {
+			d.out.WriteByte('[')
+		} else {
+			d.out.WriteByte('{')
+		}
+		for i := 0; ; {
+			count, err := d.readBlockCount()
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				break
+			}
+			for ; count > 0; count-- {
+				if i > 0 {
+					d.out.WriteByte(',')
+				}
+				i++
+				if s.Type == "array" {
+					err = d.readValue(s.Items)
+				} else {
+					var key []byte
+					if key, err = d.readBytes(); err != nil {
+						return err
+					}
+					d.writeString(string(key))
+					d.out.WriteByte(':')
+					err = d.readValue(s.Values)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if s.Type == "array" {
+			d.out.WriteByte(']')
+		} else {
+			d.out.WriteByte('}')
+		}
+	case "union":
+		idx, err := d.readLong()
+		if err != nil {
+			return err
+		}
+		if idx < 0 || idx >= int64(len(s.Branches)) {
+			return errMalformed
+		}
+		return d.readValue(s.Branches[idx])
+	default:
+		return errors.New("unsupported avro type " + s.Type)
+	}
+	return nil
+}
+
+func (d *decoder) writeLogicalInt(s *Schema, n int64) {
+	switch s.Logical {
+	case "date":
+		d.writeString(time.Unix(n*86400, 0).UTC().Format(time.DateOnly))
+	case "time-millis":
+		d.writeString(formatTimeOfDay(time.Duration(n) * time.Millisecond))
+	case "time-micros":
+		d.writeString(formatTimeOfDay(time.Duration(n) * time.Microsecond))
+	case "timestamp-millis":
+		d.writeString(time.UnixMilli(n).UTC().Format(time.RFC3339Nano))
+	case "timestamp-micros":
+		d.writeString(time.UnixMicro(n).UTC().Format(time.RFC3339Nano))
+	case "local-timestamp-millis":
+		d.writeString(time.UnixMilli(n).UTC().Format("2006-01-02T15:04:05.999999999"))
+	case "local-timestamp-micros":
+		d.writeString(time.UnixMicro(n).UTC().Format("2006-01-02T15:04:05.999999999"))
+	default:
+		d.out.WriteString(strconv.FormatInt(n, 10))
+	}
+}
+
+func formatTimeOfDay(dur time.Duration) string {
+	return time.Unix(0, 0).UTC().Add(dur).Format("15:04:05.999999")
+}
+
+// format decimal of big endian two's complement unscaled value
+func formatDecimal(b []byte, scale int) string {
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+	}
+	digits := new(big.Int).Abs(n).String()
+	var sign string
+	if n.Sign() < 0 {
+		sign = "-"
+	}
+	if scale <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
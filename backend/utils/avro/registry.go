@@ -0,0 +1,169 @@
+package avroutil
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MagicByte leading byte of payload framed by confluent serializers, followed by 4 bytes schema id
+const MagicByte = 0x00
+
+// UnwrapFrame split confluent framed payload into schema id and avro data
+func UnwrapFrame(buf []byte) (id int, data []byte, ok bool) {
+	if len(buf) < 5 || buf[0] != MagicByte {
+		return 0, nil, false
+	}
+	return int(binary.BigEndian.Uint32(buf[1:5])), buf[5:], true
+}
+
+// time to wait before retrying to fetch a schema failed
+const failureTTL = time.Minute
+
+// Registry client of confluent schema registry, fetched schemas are cached in memory and on disk,
+// schemas are immutable once registered, so the cache never expires
+type Registry struct {
+	url      string
+	username string
+	password string
+	cacheDir string
+	client   *http.Client
+
+	mutex    sync.Mutex
+	schemas  map[int]*Schema
+	failures map[int]time.Time
+}
+
+// NewRegistry create client of schema registry, schemas are cached in a subdirectory of cacheDir for each registry
+func NewRegistry(url, username, password, cacheDir string) *Registry {
+	url = strings.TrimRight(url, "/")
+	var dir string
+	if len(cacheDir) > 0 {
+		sum := sha256.Sum256([]byte(url))
+		dir = path.Join(cacheDir, hex.EncodeToString(sum[:8]))
+	}
+	return &Registry{
+		url:      url,
+		username: username,
+		password: password,
+		cacheDir: dir,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		schemas:  map[int]*Schema{},
+		failures: map[int]time.Time{},
+	}
+}
+
+// CachedSchema get schema by id from memory or disk cache, without requesting schema registry
+func (r *Registry) CachedSchema(id int) (*Schema, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if s, ok := r.schemas[id]; ok {
+		return s, true
+	}
+	schemaJSON, err := r.loadCache(id)
+	if err != nil {
+		return nil, false
+	}
+	s, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, false
+	}
+	r.schemas[id] = s
+	return s, true
+}
+
+// Schema get schema by id, from cache or schema registry
+func (r *Registry) Schema(id int) (*Schema, error) {
+	if s, ok := r.CachedSchema(id); ok {
+		return s, nil
+	}
+	r.mutex.Lock()
+	t, failed := r.failures[id]
+	r.mutex.Unlock()
+	if failed && time.Since(t) < failureTTL {
+		return nil, fmt.Errorf("schema %d is not available", id)
+	}
+
+	// request without holding the lock, so that decoding by cached schemas is not blocked
+	schemaJSON, err := r.fetch(id)
+	var s *Schema
+	if err == nil {
+		s, err = ParseSchema(schemaJSON)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err != nil {
+		r.failures[id] = time.Now()
+		return nil, err
+	}
+	r.saveCache(id, schemaJSON)
+	delete(r.failures, id)
+	r.schemas[id] = s
+	return s, nil
+}
+
+func (r *Registry) cacheFile(id int) string {
+	return path.Join(r.cacheDir, strconv.Itoa(id)+".avsc")
+}
+
+func (r *Registry) loadCache(id int) (string, error) {
+	if len(r.cacheDir) <= 0 {
+		return "", os.ErrNotExist
+	}
+	b, err := os.ReadFile(r.cacheFile(id))
+	return string(b), err
+}
+
+func (r *Registry) saveCache(id int, schemaJSON string) {
+	if len(r.cacheDir) <= 0 {
+		return
+	}
+	_ = os.MkdirAll(r.cacheDir, 0777)
+	_ = os.WriteFile(r.cacheFile(id), []byte(schemaJSON), 0644)
+}
+
+func (r *Registry) fetch(id int) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, r.url+"/schemas/ids/"+strconv.Itoa(id), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if len(r.username) > 0 || len(r.password) > 0 {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	res, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 16<<20))
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch schema %d fail: %s %s", id, res.Status, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err = json.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+	if len(result.SchemaType) > 0 && result.SchemaType != "AVRO" {
+		return "", fmt.Errorf("schema %d is %s, not avro", id, result.SchemaType)
+	}
+	return result.Schema, nil
+}
@@ -0,0 +1,154 @@
+package avroutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Schema parsed avro schema
+type Schema struct {
+	Type     string // primitive type name, or record, enum, array, map, union and fixed
+	Name     string // full name of named type
+	Logical  string // logical type if any
+	Fields   []Field
+	Symbols  []string
+	Items    *Schema // element of array
+	Values   *Schema // value of map
+	Branches []*Schema
+	Size     int // size of fixed
+	Scale    int // scale of decimal
+}
+
+type Field struct {
+	Name string
+	Type *Schema
+}
+
+var primitiveTypes = map[string]bool{
+	"null": true, "boolean": true, "int": true, "long": true,
+	"float": true, "double": true, "bytes": true, "string": true,
+}
+
+type schemaParser struct {
+	named map[string]*Schema
+}
+
+// ParseSchema parse avro schema in json
+func ParseSchema(schemaJSON string) (*Schema, error) {
+	var raw any
+	if err := json.Unmarshal([]byte(schemaJSON), &raw); err != nil {
+		return nil, err
+	}
+	p := &schemaParser{named: map[string]*Schema{}}
+	return p.parse(raw, "")
+}
+
+// full name of named type in namespace
+func fullName(name, namespace string) string {
+	if strings.ContainsRune(name, '.') || len(namespace) <= 0 {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func (p *schemaParser) parse(raw any, namespace string) (*Schema, error) {
+	switch v := raw.(type) {
+	case string:
+		if primitiveTypes[v] {
+			return &Schema{Type: v}, nil
+		}
+		// reference to named type
+		if s, ok := p.named[fullName(v, namespace)]; ok {
+			return s, nil
+		}
+		if s, ok := p.named[v]; ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("unknown avro type \"%s\"", v)
+	case []any:
+		s := &Schema{Type: "union"}
+		for _, item := range v {
+			branch, err := p.parse(item, namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.Branches = append(s.Branches, branch)
+		}
+		return s, nil
+	case map[string]any:
+		return p.parseComplex(v, namespace)
+	}
+	return nil, errors.New("invalid avro schema")
+}
+
+func (p *schemaParser) parseComplex(obj map[string]any, namespace string) (*Schema, error) {
+	tp, _ := obj["type"].(string)
+	logical, _ := obj["logicalType"].(string)
+	switch tp {
+	case "record", "error", "enum", "fixed":
+		name, _ := obj["name"].(string)
+		if ns, ok := obj["namespace"].(string); ok {
+			namespace = ns
+		}
+		name = fullName(name, namespace)
+		if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+			namespace = name[:idx]
+		}
+		s := &Schema{Type: tp, Name: name, Logical: logical}
+		if tp == "error" {
+			s.Type = "record"
+		}
+		// register before parsing fields for recursive reference
+		p.named[name] = s
+		switch s.Type {
+		case "record":
+			fields, _ := obj["fields"].([]any)
+			for _, f := range fields {
+				field, _ := f.(map[string]any)
+				fieldName, _ := field["name"].(string)
+				fieldType, err := p.parse(field["type"], namespace)
+				if err != nil {
+					return nil, err
+				}
+				s.Fields = append(s.Fields, Field{Name: fieldName, Type: fieldType})
+			}
+		case "enum":
+			symbols, _ := obj["symbols"].([]any)
+			for _, symbol := range symbols {
+				str, _ := symbol.(string)
+				s.Symbols = append(s.Symbols, str)
+			}
+		case "fixed":
+			size, _ := obj["size"].(float64)
+			s.Size = int(size)
+			scale, _ := obj["scale"].(float64)
+			s.Scale = int(scale)
+		}
+		return s, nil
+	case "array":
+		items, err := p.parse(obj["items"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: tp, Items: items}, nil
+	case "map":
+		values, err := p.parse(obj["values"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: tp, Values: values}, nil
+	}
+
+	// primitive type with attributes, e.g. {"type": "long", "logicalType": "timestamp-millis"}
+	s, err := p.parse(obj["type"], namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(logical) > 0 && primitiveTypes[s.Type] {
+		scale, _ := obj["scale"].(float64)
+		return &Schema{Type: s.Type, Logical: logical, Scale: int(scale)}, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,113 @@
+package bsonutil
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	decimalExponentBias = 6176
+	decimalExponentMax  = 6111
+	decimalExponentMin  = -6176
+)
+
+var decimalCoefficientMax, _ = new(big.Int).SetString(strings.Repeat("9", 34), 10)
+
+// format decimal128 in the way of the bson specification
+func formatDecimal128(high, low uint64) string {
+	var sign string
+	if high>>63 != 0 {
+		sign = "-"
+	}
+	var exponent int
+	coefficient := new(big.Int)
+	if (high>>61)&3 == 3 {
+		switch (high >> 58) & 0x1f {
+		case 0x1f:
+			return "NaN"
+		case 0x1e:
+			return sign + "Infinity"
+		}
+		// coefficient in this form always exceeds the maximum and is treated as zero
+		exponent = int((high>>47)&0x3fff) - decimalExponentBias
+	} else {
+		exponent = int((high>>49)&0x3fff) - decimalExponentBias
+		coefficient.SetUint64(high & (1<<49 - 1))
+		coefficient.Lsh(coefficient, 64)
+		coefficient.Or(coefficient, new(big.Int).SetUint64(low))
+		if coefficient.Cmp(decimalCoefficientMax) > 0 {
+			coefficient.SetInt64(0)
+		}
+	}
+
+	digits := coefficient.String()
+	adjusted := exponent + len(digits) - 1
+	if exponent > 0 || adjusted < -6 {
+		// scientific notation
+		s := digits[:1]
+		if len(digits) > 1 {
+			s += "." + digits[1:]
+		}
+		if adjusted >= 0 {
+			return sign + s + "E+" + strconv.Itoa(adjusted)
+		}
+		return sign + s + "E" + strconv.Itoa(adjusted)
+	}
+	if exponent == 0 {
+		return sign + digits
+	}
+	point := len(digits) + exponent
+	if point <= 0 {
+		return sign + "0." + strings.Repeat("0", -point) + digits
+	}
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// parse decimal string into decimal128 without rounding
+func parseDecimal128(s string) (high, low uint64, err error) {
+	var negative bool
+	str := s
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+	switch strings.ToLower(str) {
+	case "nan":
+		return 0x1f << 58, 0, nil
+	case "inf", "infinity":
+		high = 0x1e << 58
+		if negative {
+			high |= 1 << 63
+		}
+		return high, 0, nil
+	}
+
+	var exponent int
+	if idx := strings.IndexAny(str, "eE"); idx >= 0 {
+		if exponent, err = strconv.Atoi(str[idx+1:]); err != nil {
+			return 0, 0, errors.New("invalid decimal: " + s)
+		}
+		str = str[:idx]
+	}
+	if idx := strings.IndexByte(str, '.'); idx >= 0 {
+		exponent -= len(str) - idx - 1
+		str = str[:idx] + str[idx+1:]
+	}
+	coefficient, ok := new(big.Int).SetString(str, 10)
+	if !ok || str == "" || strings.ContainsAny(str, "+-") {
+		return 0, 0, errors.New("invalid decimal: " + s)
+	}
+	if coefficient.Cmp(decimalCoefficientMax) > 0 || exponent > decimalExponentMax || exponent < decimalExponentMin {
+		return 0, 0, errors.New("decimal out of range: " + s)
+	}
+
+	low = new(big.Int).And(coefficient, new(big.Int).SetUint64(^uint64(0))).Uint64()
+	high = new(big.Int).Rsh(coefficient, 64).Uint64()
+	high |= uint64(exponent+decimalExponentBias) << 49
+	if negative {
+		high |= 1 << 63
+	}
+	return high, low, nil
+}
@@ -0,0 +1,376 @@
+package bsonutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errMalformed = errors.New("malformed bson document")
+
+const (
+	typeDouble     = 0x01
+	typeString     = 0x02
+	typeDocument   = 0x03
+	typeArray      = 0x04
+	typeBinary     = 0x05
+	typeUndefined  = 0x06
+	typeObjectID   = 0x07
+	typeBoolean    = 0x08
+	typeDatetime   = 0x09
+	typeNull       = 0x0a
+	typeRegex      = 0x0b
+	typeDBPointer  = 0x0c
+	typeCode       = 0x0d
+	typeSymbol     = 0x0e
+	typeCodeScope  = 0x0f
+	typeInt32      = 0x10
+	typeTimestamp  = 0x11
+	typeInt64      = 0x12
+	typeDecimal128 = 0x13
+	typeMinKey     = 0xff
+	typeMaxKey     = 0x7f
+)
+
+const binaryOld = 0x02
+
+type decoder struct {
+	buf []byte
+	pos int
+	out bytes.Buffer
+}
+
+// IsDocument check if content looks like a bson document by the leading length and trailing null byte
+func IsDocument(buf []byte) bool {
+	return len(buf) >= 5 && int(binary.LittleEndian.Uint32(buf)) == len(buf) && buf[len(buf)-1] == 0
+}
+
+// ToJSON render bson document as relaxed extended json of mongodb,
+// types which are not native in json are wrapped like {"$oid": "..."},
+// int64 in the range of int32 is wrapped in $numberLong and integral double keeps the decimal point
+// to be encoded back as the same type
+func ToJSON(buf []byte) (string, error) {
+	if !IsDocument(buf) {
+		return "", errMalformed
+	}
+	d := &decoder{buf: buf}
+	if err := d.readDocument(false); err != nil {
+		return "", err
+	}
+	if d.pos != len(d.buf) {
+		return "", errMalformed
+	}
+	return d.out.String(), nil
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.buf) {
+		return nil, errMalformed
+	}
+	d.pos += n
+	return d.buf[d.pos-n : d.pos], nil
+}
+
+func (d *decoder) readInt32() (int32, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b)), nil
+}
+
+func (d *decoder) readInt64() (int64, error) {
+	b, err := d.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint64(b)), nil
+}
+
+func (d *decoder) readCString() (string, error) {
+	idx := bytes.IndexByte(d.buf[d.pos:], 0)
+	if idx < 0 {
+		return "", errMalformed
+	}
+	s := string(d.buf[d.pos : d.pos+idx])
+	d.pos += idx + 1
+	return s, nil
+}
+
+// read string with int32 length including the trailing null byte
+func (d *decoder) readString() (string, error) {
+	length, err := d.readInt32()
+	if err != nil {
+		return "", err
+	}
+	if length <= 0 {
+		return "", errMalformed
+	}
+	b, err := d.read(int(length))
+	if err != nil {
+		return "", err
+	}
+	if b[length-1] != 0 {
+		return "", errMalformed
+	}
+	return string(b[:length-1]), nil
+}
+
+// quote string in json without escaping html characters
+func quote(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (d *decoder) writeString(s string) {
+	d.out.WriteString(quote(s))
+}
+
+// write object with single key and raw json value
+func (d *decoder) writeWrapped(key string, raw string) {
+	d.out.WriteByte('{')
+	d.writeString(key)
+	d.out.WriteByte(':')
+	d.out.WriteString(raw)
+	d.out.WriteByte('}')
+}
+
+func (d *decoder) readDocument(array bool) error {
+	start := d.pos
+	length, err := d.readInt32()
+	if err != nil {
+		return err
+	}
+	end := start + int(length)
+	if length < 5 || end > len(d.buf) || d.buf[end-1] != 0 {
+		return errMalformed
+	}
+	if array {
+		d.out.WriteByte('[')
+	} else {
+		d.out.WriteByte('{')
+	}
+	for i := 0; d.pos < end-1; i++ {
+		tp := d.buf[d.pos]
+		d.pos++
+		name, err := d.readCString()
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			d.out.WriteByte(',')
+		}
+		if !array {
+			d.writeString(name)
+			d.out.WriteByte(':')
+		}
+		if err = d.readValue(tp); err != nil {
+			return err
+		}
+	}
+	if d.pos != end-1 {
+		return errMalformed
+	}
+	d.pos = end
+	if array {
+		d.out.WriteByte(']')
+	} else {
+		d.out.WriteByte('}')
+	}
+	return nil
+}
+
+func (d *decoder) readObjectID() (string, error) {
+	b, err := d.read(12)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (d *decoder) readValue(tp byte) error {
+	switch tp {
+	case typeDouble:
+		b, err := d.read(8)
+		if err != nil {
+			return err
+		}
+		d.out.WriteString(formatDouble(math.Float64frombits(binary.LittleEndian.Uint64(b))))
+	case typeString:
+		s, err := d.readString()
+		if err != nil {
+			return err
+		}
+		d.writeString(s)
+	case typeDocument:
+		return d.readDocument(false)
+	case typeArray:
+		return d.readDocument(true)
+	case typeBinary:
+		length, err := d.readInt32()
+		if err != nil {
+			return err
+		}
+		subtype, err := d.read(1)
+		if err != nil {
+			return err
+		}
+		data, err := d.read(int(length))
+		if err != nil {
+			return err
+		}
+		if subtype[0] == binaryOld {
+			// old binary subtype contains another length
+			if len(data) < 4 || int(binary.LittleEndian.Uint32(data)) != len(data)-4 {
+				return errMalformed
+			}
+			data = data[4:]
+		}
+		d.writeWrapped("$binary", `{"base64":"`+base64.StdEncoding.EncodeToString(data)+`","subType":"`+hex.EncodeToString(subtype)+`"}`)
+	case typeUndefined:
+		d.writeWrapped("$undefined", "true")
+	case typeObjectID:
+		oid, err := d.readObjectID()
+		if err != nil {
+			return err
+		}
+		d.writeWrapped("$oid", strconv.Quote(oid))
+	case typeBoolean:
+		b, err := d.read(1)
+		if err != nil {
+			return err
+		}
+		switch b[0] {
+		case 0:
+			d.out.WriteString("false")
+		case 1:
+			d.out.WriteString("true")
+		default:
+			return errMalformed
+		}
+	case typeDatetime:
+		ms, err := d.readInt64()
+		if err != nil {
+			return err
+		}
+		t := time.UnixMilli(ms).UTC()
+		if t.Year() >= 1970 && t.Year() <= 9999 {
+			d.writeWrapped("$date", strconv.Quote(t.Format(dateLayout)))
+		} else {
+			d.writeWrapped("$date", `{"$numberLong":"`+strconv.FormatInt(ms, 10)+`"}`)
+		}
+	case typeNull:
+		d.out.WriteString("null")
+	case typeRegex:
+		pattern, err := d.readCString()
+		if err != nil {
+			return err
+		}
+		options, err := d.readCString()
+		if err != nil {
+			return err
+		}
+		d.writeWrapped("$regularExpression", `{"pattern":`+quote(pattern)+`,"options":`+quote(options)+`}`)
+	case typeDBPointer:
+		ref, err := d.readString()
+		if err != nil {
+			return err
+		}
+		oid, err := d.readObjectID()
+		if err != nil {
+			return err
+		}
+		d.writeWrapped("$dbPointer", `{"$ref":`+quote(ref)+`,"$id":{"$oid":"`+oid+`"}}`)
+	case typeCode, typeSymbol:
+		s, err := d.readString()
+		if err != nil {
+			return err
+		}
+		if tp == typeCode {
+			d.writeWrapped("$code", quote(s))
+		} else {
+			d.writeWrapped("$symbol", quote(s))
+		}
+	case typeCodeScope:
+		if _, err := d.readInt32(); err != nil {
+			return err
+		}
+		code, err := d.readString()
+		if err != nil {
+			return err
+		}
+		d.out.WriteString(`{"$code":`)
+		d.writeString(code)
+		d.out.WriteString(`,"$scope":`)
+		if err = d.readDocument(false); err != nil {
+			return err
+		}
+		d.out.WriteByte('}')
+	case typeInt32:
+		n, err := d.readInt32()
+		if err != nil {
+			return err
+		}
+		d.out.WriteString(strconv.FormatInt(int64(n), 10))
+	case typeTimestamp:
+		b, err := d.read(8)
+		if err != nil {
+			return err
+		}
+		// increment in the lower 4 bytes and time in the higher 4 bytes
+		inc, sec := binary.LittleEndian.Uint32(b), binary.LittleEndian.Uint32(b[4:])
+		d.writeWrapped("$timestamp", `{"t":`+strconv.FormatUint(uint64(sec), 10)+`,"i":`+strconv.FormatUint(uint64(inc), 10)+`}`)
+	case typeInt64:
+		n, err := d.readInt64()
+		if err != nil {
+			return err
+		}
+		if n >= math.MinInt32 && n <= math.MaxInt32 {
+			d.writeWrapped("$numberLong", strconv.Quote(strconv.FormatInt(n, 10)))
+		} else {
+			d.out.WriteString(strconv.FormatInt(n, 10))
+		}
+	case typeDecimal128:
+		b, err := d.read(16)
+		if err != nil {
+			return err
+		}
+		low, high := binary.LittleEndian.Uint64(b), binary.LittleEndian.Uint64(b[8:])
+		d.writeWrapped("$numberDecimal", strconv.Quote(formatDecimal128(high, low)))
+	case typeMinKey:
+		d.writeWrapped("$minKey", "1")
+	case typeMaxKey:
+		d.writeWrapped("$maxKey", "1")
+	default:
+		return errors.New("unknown bson type 0x" + strconv.FormatUint(uint64(tp), 16))
+	}
+	return nil
+}
+
+const dateLayout = "2006-01-02T15:04:05.000Z"
+
+func formatDouble(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return `{"$numberDouble":"NaN"}`
+	case math.IsInf(f, 1):
+		return `{"$numberDouble":"Infinity"}`
+	case math.IsInf(f, -1):
+		return `{"$numberDouble":"-Infinity"}`
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,351 @@
+package bsonutil
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jsonObject json object with keys in order
+type jsonObject struct {
+	keys   []string
+	values []any
+}
+
+func (o *jsonObject) get(key string) (any, bool) {
+	for i, k := range o.keys {
+		if k == key {
+			return o.values[i], true
+		}
+	}
+	return nil, false
+}
+
+// read json value keeping key order of objects
+func readJSON(decoder *json.Decoder) (any, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			arr := []any{}
+			for decoder.More() {
+				val, err := readJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, val)
+			}
+			_, err = decoder.Token()
+			return arr, err
+		case '{':
+			obj := &jsonObject{}
+			for decoder.More() {
+				keyTok, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				val, err := readJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				obj.keys = append(obj.keys, keyTok.(string))
+				obj.values = append(obj.values, val)
+			}
+			_, err = decoder.Token()
+			return obj, err
+		}
+		return nil, errors.New("invalid json")
+	}
+	return tok, nil
+}
+
+// FromJSON encode extended json of mongodb into bson document, the top level value must be an object
+func FromJSON(str string) ([]byte, error) {
+	decoder := json.NewDecoder(strings.NewReader(str))
+	decoder.UseNumber()
+	val, err := readJSON(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid json")
+	}
+	obj, ok := val.(*jsonObject)
+	if !ok {
+		return nil, errors.New("bson document must be an object")
+	}
+	return appendDocument(nil, obj.keys, obj.values)
+}
+
+func appendInt32(buf []byte, n int32) []byte {
+	return binary.LittleEndian.AppendUint32(buf, uint32(n))
+}
+
+func appendInt64(buf []byte, n int64) []byte {
+	return binary.LittleEndian.AppendUint64(buf, uint64(n))
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = appendInt32(buf, int32(len(s)+1))
+	buf = append(buf, s...)
+	return append(buf, 0)
+}
+
+func appendCString(buf []byte, s string) ([]byte, error) {
+	if strings.IndexByte(s, 0) >= 0 {
+		return nil, errors.New("key contains null byte")
+	}
+	buf = append(buf, s...)
+	return append(buf, 0), nil
+}
+
+func appendDocument(buf []byte, keys []string, values []any) ([]byte, error) {
+	start := len(buf)
+	buf = appendInt32(buf, 0)
+	for i, key := range keys {
+		// reserve type byte
+		typePos := len(buf)
+		buf = append(buf, 0)
+		var err error
+		if buf, err = appendCString(buf, key); err != nil {
+			return nil, err
+		}
+		var tp byte
+		if buf, tp, err = appendValue(buf, values[i]); err != nil {
+			return nil, err
+		}
+		buf[typePos] = tp
+	}
+	buf = append(buf, 0)
+	binary.LittleEndian.PutUint32(buf[start:], uint32(len(buf)-start))
+	return buf, nil
+}
+
+func appendArray(buf []byte, items []any) ([]byte, error) {
+	keys := make([]string, len(items))
+	for i := range items {
+		keys[i] = strconv.Itoa(i)
+	}
+	return appendDocument(buf, keys, items)
+}
+
+func appendObjectID(buf []byte, val any) ([]byte, error) {
+	s, _ := val.(string)
+	oid, err := hex.DecodeString(s)
+	if err != nil || len(oid) != 12 {
+		return nil, fmt.Errorf("invalid object id %v", val)
+	}
+	return append(buf, oid...), nil
+}
+
+// string value of json number or string
+func numberString(val any) string {
+	switch v := val.(type) {
+	case json.Number:
+		return v.String()
+	case string:
+		return v
+	}
+	return ""
+}
+
+func appendValue(buf []byte, val any) ([]byte, byte, error) {
+	switch v := val.(type) {
+	case nil:
+		return buf, typeNull, nil
+	case bool:
+		if v {
+			return append(buf, 1), typeBoolean, nil
+		}
+		return append(buf, 0), typeBoolean, nil
+	case string:
+		return appendString(buf, v), typeString, nil
+	case json.Number:
+		s := v.String()
+		if !strings.ContainsAny(s, ".eE") {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				if n >= math.MinInt32 && n <= math.MaxInt32 {
+					return appendInt32(buf, int32(n)), typeInt32, nil
+				}
+				return appendInt64(buf, n), typeInt64, nil
+			}
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, 0, err
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f)), typeDouble, nil
+	case []any:
+		buf, err := appendArray(buf, v)
+		return buf, typeArray, err
+	case *jsonObject:
+		if len(v.keys) > 0 && strings.HasPrefix(v.keys[0], "$") {
+			if buf, tp, ok, err := appendExtended(buf, v); ok || err != nil {
+				return buf, tp, err
+			}
+		}
+		buf, err := appendDocument(buf, v.keys, v.values)
+		return buf, typeDocument, err
+	}
+	return nil, 0, fmt.Errorf("unsupported value %v", val)
+}
+
+// encode value in form of extended json, false is returned if it's a normal document
+func appendExtended(buf []byte, obj *jsonObject) ([]byte, byte, bool, error) {
+	key, val := obj.keys[0], obj.values[0]
+	if len(obj.keys) == 2 && key == "$code" && obj.keys[1] == "$scope" {
+		code, _ := val.(string)
+		scope, ok := obj.values[1].(*jsonObject)
+		if !ok {
+			return nil, 0, true, errors.New("invalid $scope")
+		}
+		start := len(buf)
+		buf = appendInt32(buf, 0)
+		buf = appendString(buf, code)
+		buf, err := appendDocument(buf, scope.keys, scope.values)
+		if err != nil {
+			return nil, 0, true, err
+		}
+		binary.LittleEndian.PutUint32(buf[start:], uint32(len(buf)-start))
+		return buf, typeCodeScope, true, nil
+	}
+	if len(obj.keys) != 1 {
+		return buf, 0, false, nil
+	}
+
+	var err error
+	switch key {
+	case "$oid":
+		buf, err = appendObjectID(buf, val)
+		return buf, typeObjectID, true, err
+	case "$numberInt":
+		n, e := strconv.ParseInt(numberString(val), 10, 32)
+		return appendInt32(buf, int32(n)), typeInt32, true, e
+	case "$numberLong":
+		n, e := strconv.ParseInt(numberString(val), 10, 64)
+		return appendInt64(buf, n), typeInt64, true, e
+	case "$numberDouble":
+		var f float64
+		switch s := numberString(val); s {
+		case "Infinity":
+			f = math.Inf(1)
+		case "-Infinity":
+			f = math.Inf(-1)
+		case "NaN":
+			f = math.NaN()
+		default:
+			f, err = strconv.ParseFloat(s, 64)
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f)), typeDouble, true, err
+	case "$numberDecimal":
+		high, low, e := parseDecimal128(numberString(val))
+		buf = binary.LittleEndian.AppendUint64(buf, low)
+		return binary.LittleEndian.AppendUint64(buf, high), typeDecimal128, true, e
+	case "$date":
+		var ms int64
+		switch d := val.(type) {
+		case string:
+			t, e := time.Parse(time.RFC3339Nano, d)
+			if e != nil {
+				return nil, 0, true, e
+			}
+			ms = t.UnixMilli()
+		case json.Number:
+			ms, err = d.Int64()
+		case *jsonObject:
+			if n, ok := d.get("$numberLong"); ok {
+				ms, err = strconv.ParseInt(numberString(n), 10, 64)
+			} else {
+				err = errors.New("invalid $date")
+			}
+		default:
+			err = errors.New("invalid $date")
+		}
+		return appendInt64(buf, ms), typeDatetime, true, err
+	case "$binary":
+		bin, ok := val.(*jsonObject)
+		if !ok {
+			return nil, 0, true, errors.New("invalid $binary")
+		}
+		b64, _ := bin.get("base64")
+		subType, _ := bin.get("subType")
+		data, e := base64.StdEncoding.DecodeString(numberString(b64))
+		if e != nil {
+			return nil, 0, true, e
+		}
+		st, e := strconv.ParseUint(numberString(subType), 16, 8)
+		if e != nil {
+			return nil, 0, true, e
+		}
+		if st == binaryOld {
+			data = append(appendInt32(nil, int32(len(data))), data...)
+		}
+		buf = appendInt32(buf, int32(len(data)))
+		buf = append(buf, byte(st))
+		return append(buf, data...), typeBinary, true, nil
+	case "$regularExpression":
+		re, ok := val.(*jsonObject)
+		if !ok {
+			return nil, 0, true, errors.New("invalid $regularExpression")
+		}
+		pattern, _ := re.get("pattern")
+		options, _ := re.get("options")
+		if buf, err = appendCString(buf, numberString(pattern)); err != nil {
+			return nil, 0, true, err
+		}
+		buf, err = appendCString(buf, numberString(options))
+		return buf, typeRegex, true, err
+	case "$timestamp":
+		ts, ok := val.(*jsonObject)
+		if !ok {
+			return nil, 0, true, errors.New("invalid $timestamp")
+		}
+		t, _ := ts.get("t")
+		i, _ := ts.get("i")
+		sec, e1 := strconv.ParseUint(numberString(t), 10, 32)
+		inc, e2 := strconv.ParseUint(numberString(i), 10, 32)
+		if e1 != nil || e2 != nil {
+			return nil, 0, true, errors.New("invalid $timestamp")
+		}
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(inc))
+		return binary.LittleEndian.AppendUint32(buf, uint32(sec)), typeTimestamp, true, nil
+	case "$dbPointer":
+		ptr, ok := val.(*jsonObject)
+		if !ok {
+			return nil, 0, true, errors.New("invalid $dbPointer")
+		}
+		ref, _ := ptr.get("$ref")
+		id, _ := ptr.get("$id")
+		idObj, ok := id.(*jsonObject)
+		if !ok {
+			return nil, 0, true, errors.New("invalid $dbPointer")
+		}
+		oid, _ := idObj.get("$oid")
+		buf = appendString(buf, numberString(ref))
+		buf, err = appendObjectID(buf, oid)
+		return buf, typeDBPointer, true, err
+	case "$code":
+		return appendString(buf, numberString(val)), typeCode, true, nil
+	case "$symbol":
+		return appendString(buf, numberString(val)), typeSymbol, true, nil
+	case "$undefined":
+		return buf, typeUndefined, true, nil
+	case "$minKey":
+		return buf, typeMinKey, true, nil
+	case "$maxKey":
+		return buf, typeMaxKey, true, nil
+	}
+	return buf, 0, false, nil
+}
@@ -0,0 +1,347 @@
+package cborutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var errMalformed = errors.New("malformed cbor data")
+
+const (
+	majorUint   = 0
+	majorNegint = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+const (
+	tagPositiveBignum = 2
+	tagNegativeBignum = 3
+)
+
+// SelfDescribedPrefix prefix of self-described cbor data (tag 55799)
+const SelfDescribedPrefix = "\xd9\xd9\xf7"
+
+// max nested level accepted
+const maxDepth = 512
+
+type decoder struct {
+	buf   []byte
+	pos   int
+	depth int
+	out   bytes.Buffer
+}
+
+// ToJSON render cbor data item as json, the whole content must be consumed.
+// values which are not native in json are wrapped to be encoded back as the same type:
+// byte strings as {"@bytes": "<base64>"}, tags as {"@tag": n, "@value": v},
+// maps with non-string keys as {"@map": [[k, v], ...]}, undefined and other simple values as {"@simple": n},
+// and non-finite floats as {"@float": "NaN"}; integral floats keep the decimal point
+func ToJSON(buf []byte) (string, error) {
+	d := &decoder{buf: buf}
+	if err := d.readItem(); err != nil {
+		return "", err
+	}
+	if d.pos != len(d.buf) {
+		return "", errMalformed
+	}
+	return d.out.String(), nil
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.buf)-d.pos) {
+		return nil, errMalformed
+	}
+	d.pos += int(n)
+	return d.buf[d.pos-int(n) : d.pos], nil
+}
+
+// read initial byte and argument, additional info 31 indicates indefinite length
+func (d *decoder) readHead() (major byte, info byte, arg uint64, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		var data []byte
+		if data, err = d.read(1 << (info - 24)); err != nil {
+			return
+		}
+		for _, c := range data {
+			arg = arg<<8 | uint64(c)
+		}
+	case info == 31:
+		if major == majorUint || major == majorNegint || major == majorTag {
+			err = errMalformed
+		}
+	default:
+		err = errMalformed
+	}
+	return
+}
+
+func (d *decoder) isBreak() bool {
+	if d.pos < len(d.buf) && d.buf[d.pos] == 0xff {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// write string in json without escaping html characters
+func (d *decoder) writeString(s string) {
+	encoder := json.NewEncoder(&d.out)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	// remove the trailing newline
+	d.out.Truncate(d.out.Len() - 1)
+}
+
+// read content of byte or text string, chunks of indefinite length are concatenated
+func (d *decoder) readChunks(major, info byte, arg uint64) ([]byte, error) {
+	if info != 31 {
+		b, err := d.read(arg)
+		return append([]byte{}, b...), err
+	}
+	var content []byte
+	for !d.isBreak() {
+		chunkMajor, chunkInfo, chunkArg, err := d.readHead()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == 31 {
+			return nil, errMalformed
+		}
+		b, err := d.read(chunkArg)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, b...)
+	}
+	return content, nil
+}
+
+func (d *decoder) readItem() error {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > maxDepth {
+		return errors.New("cbor data nested too deep")
+	}
+
+	major, info, arg, err := d.readHead()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case majorUint:
+		d.out.WriteString(strconv.FormatUint(arg, 10))
+	case majorNegint:
+		// -1 - arg
+		n := new(big.Int).SetUint64(arg)
+		d.out.WriteString(n.Neg(n).Sub(n, big.NewInt(1)).String())
+	case majorBytes:
+		b, err := d.readChunks(major, info, arg)
+		if err != nil {
+			return err
+		}
+		d.out.WriteString(`{"@bytes":"` + base64.StdEncoding.EncodeToString(b) + `"}`)
+	case majorText:
+		b, err := d.readChunks(major, info, arg)
+		if err != nil {
+			return err
+		}
+		if !utf8.Valid(b) {
+			return errMalformed
+		}
+		d.writeString(string(b))
+	case majorArray:
+		d.out.WriteByte('[')
+		for i := uint64(0); info == 31 || i < arg; i++ {
+			if info == 31 && d.isBreak() {
+				break
+			}
+			if i > 0 {
+				d.out.WriteByte(',')
+			}
+			if err = d.readItem(); err != nil {
+				return err
+			}
+		}
+		d.out.WriteByte(']')
+	case majorMap:
+		return d.readMap(info, arg)
+	case majorTag:
+		return d.readTag(arg)
+	case majorSimple:
+		switch {
+		case info == 20:
+			d.out.WriteString("false")
+		case info == 21:
+			d.out.WriteString("true")
+		case info == 22:
+			d.out.WriteString("null")
+		case info == 25:
+			d.out.WriteString(formatFloat(halfToFloat(uint16(arg))))
+		case info == 26:
+			d.out.WriteString(formatFloat(float64(math.Float32frombits(uint32(arg)))))
+		case info == 27:
+			d.out.WriteString(formatFloat(math.Float64frombits(arg)))
+		case info == 31:
+			// break outside of indefinite length item
+			return errMalformed
+		default:
+			d.out.WriteString(`{"@simple":` + strconv.FormatUint(arg, 10) + `}`)
+		}
+	}
+	return nil
+}
+
+func (d *decoder) readMap(info byte, arg uint64) error {
+	// render into object if all keys are text strings, otherwise into list of pairs
+	outLen := d.out.Len()
+	var keys []string
+	var values [][]byte
+	textKeys := true
+	for i := uint64(0); info == 31 || i < arg; i++ {
+		if info == 31 && d.isBreak() {
+			break
+		}
+		keyStart := d.out.Len()
+		if err := d.readItem(); err != nil {
+			return err
+		}
+		key := append([]byte{}, d.out.Bytes()[keyStart:]...)
+		d.out.Truncate(keyStart)
+		if len(key) <= 0 || key[0] != '"' {
+			textKeys = false
+		}
+		if err := d.readItem(); err != nil {
+			return err
+		}
+		value := append([]byte{}, d.out.Bytes()[keyStart:]...)
+		d.out.Truncate(keyStart)
+		keys = append(keys, string(key))
+		values = append(values, value)
+	}
+	d.out.Truncate(outLen)
+
+	if textKeys {
+		d.out.WriteByte('{')
+		for i := range keys {
+			if i > 0 {
+				d.out.WriteByte(',')
+			}
+			d.out.WriteString(keys[i])
+			d.out.WriteByte(':')
+			d.out.Write(values[i])
+		}
+		d.out.WriteByte('}')
+	} else {
+		d.out.WriteString(`{"@map":[`)
+		for i := range keys {
+			if i > 0 {
+				d.out.WriteByte(',')
+			}
+			d.out.WriteString("[" + keys[i] + ",")
+			d.out.Write(values[i])
+			d.out.WriteByte(']')
+		}
+		d.out.WriteString("]}")
+	}
+	return nil
+}
+
+func (d *decoder) readTag(tag uint64) error {
+	switch tag {
+	case tagPositiveBignum, tagNegativeBignum:
+		major, info, arg, err := d.readHead()
+		if err != nil {
+			return err
+		}
+		if major != majorBytes {
+			return errMalformed
+		}
+		b, err := d.readChunks(major, info, arg)
+		if err != nil {
+			return err
+		}
+		n := new(big.Int).SetBytes(b)
+		if tag == tagNegativeBignum {
+			n.Neg(n).Sub(n, big.NewInt(1))
+		}
+		// bignum in range of normal integer is kept as tag
+		if n.IsInt64() || n.IsUint64() || (n.Sign() < 0 && n.BitLen() <= 64) {
+			d.out.WriteString(`{"@tag":` + strconv.FormatUint(tag, 10) + `,"@value":{"@bytes":"` +
+				base64.StdEncoding.EncodeToString(b) + `"}}`)
+		} else {
+			d.out.WriteString(n.String())
+		}
+		return nil
+	}
+	d.out.WriteString(`{"@tag":` + strconv.FormatUint(tag, 10) + `,"@value":`)
+	if err := d.readItem(); err != nil {
+		return err
+	}
+	d.out.WriteByte('}')
+	return nil
+}
+
+// convert IEEE 754 half precision into float64
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var val float64
+	switch exp {
+	case 0:
+		val = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			val = math.Inf(1)
+		} else {
+			val = math.NaN()
+		}
+	default:
+		val = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -val
+	}
+	return val
+}
+
+// format float in full precision of float64, it will be encoded back in the shortest lossless precision
+func formatFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return `{"@float":"NaN"}`
+	case math.IsInf(f, 1):
+		return `{"@float":"Infinity"}`
+	case math.IsInf(f, -1):
+		return `{"@float":"-Infinity"}`
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// IsSelfDescribed check if content starts with the self-described cbor tag
+func IsSelfDescribed(buf []byte) bool {
+	return bytes.HasPrefix(buf, []byte(SelfDescribedPrefix))
+}
@@ -0,0 +1,275 @@
+package cborutil
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// jsonObject json object with keys in order
+type jsonObject struct {
+	keys   []string
+	values []any
+}
+
+// check if keys of object are exactly the given keys
+func (o *jsonObject) is(keys ...string) bool {
+	if len(o.keys) != len(keys) {
+		return false
+	}
+	for i, key := range keys {
+		if o.keys[i] != key {
+			return false
+		}
+	}
+	return true
+}
+
+// read json value keeping key order of objects
+func readJSON(decoder *json.Decoder) (any, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			arr := []any{}
+			for decoder.More() {
+				val, err := readJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, val)
+			}
+			_, err = decoder.Token()
+			return arr, err
+		case '{':
+			obj := &jsonObject{}
+			for decoder.More() {
+				keyTok, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				val, err := readJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				obj.keys = append(obj.keys, keyTok.(string))
+				obj.values = append(obj.values, val)
+			}
+			_, err = decoder.Token()
+			return obj, err
+		}
+		return nil, errors.New("invalid json")
+	}
+	return tok, nil
+}
+
+// FromJSON encode json into cbor data item, wrapped values rendered by ToJSON are encoded back as their origin types
+func FromJSON(str string) ([]byte, error) {
+	decoder := json.NewDecoder(strings.NewReader(str))
+	decoder.UseNumber()
+	val, err := readJSON(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid json")
+	}
+	return appendItem(nil, val)
+}
+
+// append initial byte with argument in the shortest form
+func appendHead(buf []byte, major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(buf, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(buf, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, major|27), arg)
+}
+
+func appendInteger(buf []byte, n *big.Int) []byte {
+	if n.Sign() >= 0 {
+		if n.IsUint64() {
+			return appendHead(buf, majorUint, n.Uint64())
+		}
+		b := n.Bytes()
+		buf = appendHead(buf, majorTag, tagPositiveBignum)
+		return append(appendHead(buf, majorBytes, uint64(len(b))), b...)
+	}
+	// -1 - n
+	m := new(big.Int).Neg(n)
+	m.Sub(m, big.NewInt(1))
+	if m.IsUint64() {
+		return appendHead(buf, majorNegint, m.Uint64())
+	}
+	b := m.Bytes()
+	buf = appendHead(buf, majorTag, tagNegativeBignum)
+	return append(appendHead(buf, majorBytes, uint64(len(b))), b...)
+}
+
+// append float in the shortest precision without losing accuracy
+func appendFloat(buf []byte, f float64) []byte {
+	if math.IsNaN(f) {
+		return append(buf, 0xf9, 0x7e, 0x00)
+	}
+	if f32 := float32(f); float64(f32) == f {
+		if h, ok := floatToHalf(f32); ok {
+			return binary.BigEndian.AppendUint16(append(buf, 0xf9), h)
+		}
+		return binary.BigEndian.AppendUint32(append(buf, 0xfa), math.Float32bits(f32))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xfb), math.Float64bits(f))
+}
+
+// convert float32 into half precision, false is returned if it's not exactly representable
+func floatToHalf(f float32) (uint16, bool) {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127
+	mant := bits & 0x7fffff
+	var h uint16
+	switch {
+	case f == 0:
+		h = sign
+	case math.IsInf(float64(f), 0):
+		h = sign | 0x7c00
+	case exp >= -14 && exp <= 15:
+		h = sign | uint16(exp+15)<<10 | uint16(mant>>13)
+	case exp >= -24 && exp < -14:
+		// subnormal
+		h = sign | uint16((mant|1<<23)>>(-exp-1))
+	default:
+		return 0, false
+	}
+	return h, halfToFloat(h) == float64(f)
+}
+
+func numberString(val any) string {
+	switch v := val.(type) {
+	case json.Number:
+		return v.String()
+	case string:
+		return v
+	}
+	return ""
+}
+
+func appendItem(buf []byte, val any) ([]byte, error) {
+	switch v := val.(type) {
+	case nil:
+		return append(buf, 0xf6), nil
+	case bool:
+		if v {
+			return append(buf, 0xf5), nil
+		}
+		return append(buf, 0xf4), nil
+	case string:
+		return append(appendHead(buf, majorText, uint64(len(v))), v...), nil
+	case json.Number:
+		s := v.String()
+		if !strings.ContainsAny(s, ".eE") {
+			if n, ok := new(big.Int).SetString(s, 10); ok {
+				return appendInteger(buf, n), nil
+			}
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return appendFloat(buf, f), nil
+	case []any:
+		buf = appendHead(buf, majorArray, uint64(len(v)))
+		for _, item := range v {
+			var err error
+			if buf, err = appendItem(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case *jsonObject:
+		return appendObject(buf, v)
+	}
+	return nil, fmt.Errorf("unsupported value %v", val)
+}
+
+func appendObject(buf []byte, obj *jsonObject) ([]byte, error) {
+	var err error
+	switch {
+	case obj.is("@bytes"):
+		b, err := base64.StdEncoding.DecodeString(numberString(obj.values[0]))
+		if err != nil {
+			return nil, err
+		}
+		return append(appendHead(buf, majorBytes, uint64(len(b))), b...), nil
+	case obj.is("@tag", "@value"):
+		tag, err := strconv.ParseUint(numberString(obj.values[0]), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return appendItem(appendHead(buf, majorTag, tag), obj.values[1])
+	case obj.is("@simple"):
+		simple, err := strconv.ParseUint(numberString(obj.values[0]), 10, 8)
+		if err != nil {
+			return nil, err
+		}
+		if simple < 24 {
+			return append(buf, 0xe0|byte(simple)), nil
+		}
+		return append(buf, 0xf8, byte(simple)), nil
+	case obj.is("@float"):
+		switch numberString(obj.values[0]) {
+		case "NaN":
+			return appendFloat(buf, math.NaN()), nil
+		case "Infinity":
+			return appendFloat(buf, math.Inf(1)), nil
+		case "-Infinity":
+			return appendFloat(buf, math.Inf(-1)), nil
+		}
+		return nil, errors.New("invalid @float")
+	case obj.is("@map"):
+		pairs, ok := obj.values[0].([]any)
+		if !ok {
+			return nil, errors.New("invalid @map")
+		}
+		buf = appendHead(buf, majorMap, uint64(len(pairs)))
+		for _, p := range pairs {
+			pair, ok := p.([]any)
+			if !ok || len(pair) != 2 {
+				return nil, errors.New("invalid @map")
+			}
+			if buf, err = appendItem(buf, pair[0]); err != nil {
+				return nil, err
+			}
+			if buf, err = appendItem(buf, pair[1]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+
+	buf = appendHead(buf, majorMap, uint64(len(obj.keys)))
+	for i, key := range obj.keys {
+		buf = append(appendHead(buf, majorText, uint64(len(key))), key...)
+		if buf, err = appendItem(buf, obj.values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
@@ -0,0 +1,59 @@
+package convutil
+
+import (
+	"sync/atomic"
+	avroutil "tinyrdm/backend/utils/avro"
+)
+
+// AvroConvert decode confluent framed avro data into json by schema fetched from schema registry, read-only
+type AvroConvert struct{}
+
+var avroRegistry atomic.Pointer[avroutil.Registry]
+
+// SetAvroRegistry update schema registry configured in preferences, nil to disable
+func SetAvroRegistry(registry *avroutil.Registry) {
+	avroRegistry.Store(registry)
+}
+
+func (AvroConvert) Enable() bool {
+	return true
+}
+
+func (AvroConvert) Encode(str string) (string, bool) {
+	// encoding from json is not supported, prevent overwriting binary value with json
+	return str, false
+}
+
+func (c AvroConvert) Decode(str string) (string, bool) {
+	return c.decode(str, true)
+}
+
+// decode by cached schemas only, for automatic detection which should not be blocked by
+// requesting schema registry, as any content starting with 0x00 looks like framed avro
+func (c AvroConvert) decodeCached(str string) (string, bool) {
+	return c.decode(str, false)
+}
+
+func (AvroConvert) decode(str string, fetch bool) (string, bool) {
+	registry := avroRegistry.Load()
+	if registry == nil {
+		return str, false
+	}
+	id, data, ok := avroutil.UnwrapFrame([]byte(str))
+	if !ok {
+		return str, false
+	}
+	var schema *avroutil.Schema
+	if fetch {
+		var err error
+		if schema, err = registry.Schema(id); err != nil {
+			return str, false
+		}
+	} else if schema, ok = registry.CachedSchema(id); !ok {
+		return str, false
+	}
+	if decodedStr, err := schema.Decode(data); err == nil {
+		return decodedStr, true
+	}
+	return str, false
+}
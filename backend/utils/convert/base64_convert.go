@@ -23,3 +23,11 @@ func (Base64Convert) Decode(str string) (string, bool) {
 	}
 	return str, false
 }
+
+// DecodeBinary decode base64 content which may be binary, for content decoded further in chain
+func (Base64Convert) DecodeBinary(str string) (string, bool) {
+	if decodedStr, err := base64.StdEncoding.DecodeString(str); err == nil {
+		return string(decodedStr), true
+	}
+	return str, false
+}
@@ -3,7 +3,6 @@ package convutil
 import (
 	"bytes"
 	"github.com/andybalholm/brotli"
-	"io"
 	"strings"
 )
 
@@ -32,7 +31,7 @@ func (BrotliConvert) Encode(str string) (string, bool) {
 
 func (BrotliConvert) Decode(str string) (string, bool) {
 	reader := brotli.NewReader(strings.NewReader(str))
-	if decompressed, err := io.ReadAll(reader); err == nil {
+	if decompressed, err := readDecompressed(reader, len(str)); err == nil {
 		return string(decompressed), true
 	}
 	return str, false
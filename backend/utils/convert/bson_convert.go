@@ -0,0 +1,29 @@
+package convutil
+
+import (
+	bsonutil "tinyrdm/backend/utils/bson"
+)
+
+// BsonConvert convert between bson document and extended json of mongodb
+type BsonConvert struct{}
+
+func (BsonConvert) Enable() bool {
+	return true
+}
+
+func (BsonConvert) Encode(str string) (string, bool) {
+	if b, err := bsonutil.FromJSON(str); err == nil {
+		return string(b), true
+	}
+	return str, false
+}
+
+func (BsonConvert) Decode(str string) (string, bool) {
+	if !bsonutil.IsDocument([]byte(str)) {
+		return str, false
+	}
+	if value, err := bsonutil.ToJSON([]byte(str)); err == nil {
+		return value, true
+	}
+	return str, false
+}
@@ -0,0 +1,40 @@
+package convutil
+
+import (
+	"strings"
+	cborutil "tinyrdm/backend/utils/cbor"
+)
+
+// CborConvert convert between cbor and json
+type CborConvert struct{}
+
+func (CborConvert) Enable() bool {
+	return true
+}
+
+func (CborConvert) Encode(str string) (string, bool) {
+	if b, err := cborutil.FromJSON(str); err == nil {
+		return string(b), true
+	}
+	return str, false
+}
+
+func (CborConvert) Decode(str string) (string, bool) {
+	if value, err := cborutil.ToJSON([]byte(str)); err == nil {
+		return value, true
+	}
+	return str, false
+}
+
+// check if content may be cbor for automatic detection, only self-described data,
+// arrays and maps are accepted because most of plain text are also valid cbor
+func maybeCbor(str string) bool {
+	if strings.HasPrefix(str, cborutil.SelfDescribedPrefix) {
+		return true
+	}
+	if len(str) < 2 {
+		return false
+	}
+	major := str[0] >> 5
+	return major == 4 || major == 5
+}
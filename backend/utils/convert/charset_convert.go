@@ -0,0 +1,35 @@
+package convutil
+
+import (
+	"golang.org/x/text/encoding"
+	"strings"
+	"unicode/utf8"
+)
+
+// CharsetConvert reinterpret bytes in legacy charset as utf8, and convert back on save
+type CharsetConvert struct {
+	charset encoding.Encoding
+}
+
+func (c CharsetConvert) Enable() bool {
+	return true
+}
+
+// Encode fails if any char can not be represented in charset
+func (c CharsetConvert) Encode(str string) (string, bool) {
+	if !utf8.ValidString(str) {
+		return str, false
+	}
+	if encoded, err := c.charset.NewEncoder().String(str); err == nil {
+		return encoded, true
+	}
+	return str, false
+}
+
+// Decode fails on invalid byte sequence, which is replaced with U+FFFD by decoder
+func (c CharsetConvert) Decode(str string) (string, bool) {
+	if decoded, err := c.charset.NewDecoder().String(str); err == nil && !strings.ContainsRune(decoded, utf8.RuneError) {
+		return decoded, true
+	}
+	return str, false
+}
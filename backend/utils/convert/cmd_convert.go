@@ -13,6 +13,7 @@ type CmdConvert struct {
 	DecodeArgs []string
 	EncodePath string
 	EncodeArgs []string
+	Chain      []string // decode types applied from the outermost instead of running command
 }
 
 const replaceholder = "{VALUE}"
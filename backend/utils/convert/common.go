@@ -1,11 +1,39 @@
 package convutil
 
 import (
+	"errors"
 	"github.com/vrischmann/userdir"
+	"io"
 	"os"
 	"path"
+	"sync/atomic"
+	"tinyrdm/backend/consts"
 )
 
+// max bytes of decompressed content, same as the large value size in preferences
+var maxDecodedSize atomic.Int64
+
+func init() {
+	maxDecodedSize.Store(consts.DEFAULT_LARGE_VALUE_SIZE * 1024)
+}
+
+// SetMaxDecodedSize update max bytes of decompressed content
+func SetMaxDecodedSize(size int64) {
+	if size > 0 {
+		maxDecodedSize.Store(size)
+	}
+}
+
+// read all decompressed content but no more than max decoded size, in case of decompression bomb
+func readDecompressed(reader io.Reader, inputSize int) ([]byte, error) {
+	limit := max(maxDecodedSize.Load(), int64(inputSize))
+	buf, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err == nil && int64(len(buf)) > limit {
+		err = errors.New("decompressed content is too large")
+	}
+	return buf, err
+}
+
 func writeExecuteFile(content []byte, filename string) (string, error) {
 	filepath := path.Join(userdir.GetConfigHome(), "TinyRDM", "decoder", filename)
 	_ = os.Mkdir(path.Dir(filepath), 0777)
@@ -0,0 +1,29 @@
+package convutil
+
+import "bytes"
+
+var contentMagics = []struct {
+	offset      int
+	magic       []byte
+	contentType string
+}{
+	{0, []byte("\x89PNG\r\n\x1a\n"), "image/png"},
+	{0, []byte("\xff\xd8\xff"), "image/jpeg"},
+	{0, []byte("GIF87a"), "image/gif"},
+	{0, []byte("GIF89a"), "image/gif"},
+	{8, []byte("WEBP"), "image/webp"}, // after "RIFF" and 4 bytes length
+	{0, []byte("%PDF-"), "application/pdf"},
+}
+
+// DetectContentType detect content type of previewable file by magic bytes, return empty if unknown
+func DetectContentType(data []byte) string {
+	for _, m := range contentMagics {
+		if len(data) >= m.offset+len(m.magic) && bytes.Equal(data[m.offset:m.offset+len(m.magic)], m.magic) {
+			if m.contentType == "image/webp" && !bytes.HasPrefix(data, []byte("RIFF")) {
+				continue
+			}
+			return m.contentType
+		}
+	}
+	return ""
+}
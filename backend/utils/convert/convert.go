@@ -2,7 +2,13 @@ package convutil
 
 import (
 	"errors"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
 	"regexp"
+	"slices"
+	"strings"
 	"tinyrdm/backend/types"
 	strutil "tinyrdm/backend/utils/string"
 )
@@ -21,14 +27,30 @@ var (
 	base64Conv  Base64Convert
 	binaryConv  BinaryConvert
 	hexConv     HexConvert
+	timeConv    TimestampConvert
 	gzipConv    GZipConvert
 	deflateConv DeflateConvert
+	zlibConv    ZlibConvert
 	zstdConv    ZStdConvert
 	lz4Conv     LZ4Convert
 	brotliConv  BrotliConvert
+	snappyConv  SnappyConvert
 	msgpackConv MsgpackConvert
-	phpConv     = NewPhpConvert()
-	pickleConv  = NewPickleConvert()
+	bsonConv    BsonConvert
+	cborConv    CborConvert
+	javaConv    JavaConvert
+	avroConv    AvroConvert
+	thriftConv  ThriftConvert
+	kryoConv    KryoConvert
+	protoConv   ProtobufConvert
+	phpConv     PhpConvert
+	igbinConv   IgbinaryConvert
+	pickleConv  PickleConvert
+	jwtConv     JwtConvert
+	gbkConv     = CharsetConvert{simplifiedchinese.GBK}
+	sjisConv    = CharsetConvert{japanese.ShiftJIS}
+	big5Conv    = CharsetConvert{traditionalchinese.Big5}
+	latin1Conv  = CharsetConvert{charmap.ISO8859_1}
 )
 
 var BuildInFormatters = map[string]DataConvert{
@@ -38,18 +60,34 @@ var BuildInFormatters = map[string]DataConvert{
 	types.FORMAT_XML:          xmlConv,
 	types.FORMAT_HEX:          hexConv,
 	types.FORMAT_BINARY:       binaryConv,
+	types.FORMAT_TIMESTAMP:    timeConv,
 }
 
 var BuildInDecoders = map[string]DataConvert{
-	types.DECODE_BASE64:  base64Conv,
-	types.DECODE_GZIP:    gzipConv,
-	types.DECODE_DEFLATE: deflateConv,
-	types.DECODE_ZSTD:    zstdConv,
-	types.DECODE_LZ4:     lz4Conv,
-	types.DECODE_BROTLI:  brotliConv,
-	types.DECODE_MSGPACK: msgpackConv,
-	types.DECODE_PHP:     phpConv,
-	types.DECODE_PICKLE:  pickleConv,
+	types.DECODE_BASE64:    base64Conv,
+	types.DECODE_GZIP:      gzipConv,
+	types.DECODE_DEFLATE:   deflateConv,
+	types.DECODE_ZLIB:      zlibConv,
+	types.DECODE_ZSTD:      zstdConv,
+	types.DECODE_LZ4:       lz4Conv,
+	types.DECODE_BROTLI:    brotliConv,
+	types.DECODE_SNAPPY:    snappyConv,
+	types.DECODE_MSGPACK:   msgpackConv,
+	types.DECODE_BSON:      bsonConv,
+	types.DECODE_CBOR:      cborConv,
+	types.DECODE_PROTOBUF:  protoConv,
+	types.DECODE_PHP:       phpConv,
+	types.DECODE_IGBINARY:  igbinConv,
+	types.DECODE_PICKLE:    pickleConv,
+	types.DECODE_JAVA:      javaConv,
+	types.DECODE_AVRO:      avroConv,
+	types.DECODE_THRIFT:    thriftConv,
+	types.DECODE_KRYO:      kryoConv,
+	types.DECODE_GBK:       gbkConv,
+	types.DECODE_SHIFT_JIS: sjisConv,
+	types.DECODE_BIG5:      big5Conv,
+	types.DECODE_LATIN1:    latin1Conv,
+	types.DECODE_JWT:       jwtConv,
 }
 
 // ConvertTo convert string to specified type
@@ -83,25 +121,99 @@ func ConvertTo(str, decodeType, formatType string, customDecoder []CmdConvert) (
 	return
 }
 
+// separator of chained decode types, like "GZip+Msgpack" for msgpack compressed with gzip,
+// the outermost decode type comes first
+const decodeChainSep = "+"
+
+// NormalizeDecode match decode types in chain to build-in decoders case-insensitively,
+// like "gzip+protobuf:pkg.Message" to "GZip+Protobuf:pkg.Message"
+func NormalizeDecode(decode string) string {
+	parts := strings.Split(decode, decodeChainSep)
+	for i, part := range parts {
+		name, arg, hasArg := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		for buildin := range BuildInDecoders {
+			if strings.EqualFold(name, buildin) {
+				name = buildin
+				break
+			}
+		}
+		if hasArg {
+			name += ":" + arg
+		}
+		parts[i] = name
+	}
+	return strings.Join(parts, decodeChainSep)
+}
+
+// decompress value by magic bytes of gzip, zstd, lz4 frame, snappy stream or zlib header,
+// brotli and snappy block have no magic bytes and can only be selected manually
+func decompressByMagic(str string) (value, resultDecode string, ok bool) {
+	var conv DataConvert
+	switch {
+	case strings.HasPrefix(str, "\x1f\x8b"):
+		conv, resultDecode = gzipConv, types.DECODE_GZIP
+	case strings.HasPrefix(str, "\x28\xb5\x2f\xfd"):
+		conv, resultDecode = zstdConv, types.DECODE_ZSTD
+	case strings.HasPrefix(str, "\x04\x22\x4d\x18"):
+		conv, resultDecode = lz4Conv, types.DECODE_LZ4
+	case strings.HasPrefix(str, snappyStreamMagic):
+		conv, resultDecode = snappyConv, types.DECODE_SNAPPY
+	}
+	if conv != nil {
+		if value, ok = conv.Decode(str); ok {
+			return value, resultDecode, true
+		}
+	}
+	if value, ok = zlibConv.Decode(str); ok {
+		return value, types.DECODE_ZLIB, true
+	}
+	return str, "", false
+}
+
+func isCompressDecode(decodeType string) bool {
+	switch decodeType {
+	case types.DECODE_GZIP, types.DECODE_ZLIB, types.DECODE_DEFLATE, types.DECODE_ZSTD, types.DECODE_LZ4, types.DECODE_BROTLI, types.DECODE_SNAPPY:
+		return true
+	}
+	return false
+}
+
 func decodeWith(str, decodeType string, customDecoder []CmdConvert) (value, resultDecode string) {
 	if len(decodeType) > 0 {
 		value = str
 
-		if buildinDecoder, ok := BuildInDecoders[decodeType]; ok {
-			if decodedStr, ok := buildinDecoder.Decode(str); ok {
-				value = decodedStr
-			}
-		} else if decodeType != types.DECODE_NONE {
-			for _, decoder := range customDecoder {
-				if decoder.Name == decodeType {
-					if decodedStr, ok := decoder.Decode(str); ok {
-						value = decodedStr
-					}
-					break
+		if strings.Contains(decodeType, decodeChainSep) {
+			// decode from the outermost, the whole chain fails if any step fails,
+			// otherwise the value would be encoded through all steps when saving
+			parts := strings.Split(decodeType, decodeChainSep)
+			for i, part := range parts {
+				var ok bool
+				if part == types.DECODE_BASE64 && i < len(parts)-1 {
+					value, ok = base64Conv.DecodeBinary(value)
+				} else {
+					value, ok = decodeOne(value, part, customDecoder)
+				}
+				if !ok {
+					return str, types.DECODE_NONE
 				}
 			}
+			resultDecode = decodeType
+			return
 		}
 
+		if decodeType != types.DECODE_NONE && !isCompressDecode(decodeType) {
+			// decompress transparently before applying specified decoder
+			if decompressed, compressDecode, ok := decompressByMagic(str); ok {
+				value, _ = decodeWith(decompressed, decodeType, customDecoder)
+				resultDecode = compressDecode + decodeChainSep + decodeType
+				return
+			}
+		}
+
+		if decodedStr, ok := decodeOne(str, decodeType, customDecoder); ok {
+			value = decodedStr
+		}
 		resultDecode = decodeType
 		return
 	}
@@ -110,6 +222,52 @@ func decodeWith(str, decodeType string, customDecoder []CmdConvert) (value, resu
 	return
 }
 
+// decode with single decode type
+func decodeOne(str, decodeType string, customDecoder []CmdConvert) (string, bool) {
+	if msgType, ok := strings.CutPrefix(decodeType, types.DECODE_PROTOBUF+":"); ok {
+		// protobuf with specified message type
+		return protoConv.DecodeAs(str, msgType)
+	}
+	if buildinDecoder, ok := BuildInDecoders[decodeType]; ok {
+		return buildinDecoder.Decode(str)
+	}
+	if decodeType == types.DECODE_NONE {
+		return str, false
+	}
+	for i, decoder := range customDecoder {
+		if decoder.Name == decodeType {
+			return decodeCustom(str, customDecoder, i)
+		}
+	}
+	if plugin, ok := findWasmPlugin(decodeType); ok {
+		return plugin.Decode(str)
+	}
+	return str, false
+}
+
+// decode with custom decoder, which decodes through all steps if configured as chain
+func decodeCustom(str string, customDecoder []CmdConvert, idx int) (string, bool) {
+	chain := customDecoder[idx].Chain
+	if len(chain) <= 0 {
+		return customDecoder[idx].Decode(str)
+	}
+	// the chain can not refer to itself
+	others := slices.Delete(slices.Clone(customDecoder), idx, idx+1)
+	value := str
+	for i, step := range chain {
+		var ok bool
+		if step == types.DECODE_BASE64 && i < len(chain)-1 {
+			value, ok = base64Conv.DecodeBinary(value)
+		} else {
+			value, ok = decodeOne(value, step, others)
+		}
+		if !ok {
+			return str, false
+		}
+	}
+	return value, true
+}
+
 // attempt try possible decode method
 // if no decode is possible, it will return the origin string value and "none" decode type
 func autoDecode(str string, customDecoder []CmdConvert) (value, resultDecode string) {
@@ -124,8 +282,16 @@ func autoDecode(str string, customDecoder []CmdConvert) (value, resultDecode str
 				}
 			}
 
-			if value, ok = gzipConv.Decode(str); ok {
-				resultDecode = types.DECODE_GZIP
+			if value, ok = jwtConv.Decode(str); ok {
+				resultDecode = types.DECODE_JWT
+				return
+			}
+
+			if value, resultDecode, ok = decompressByMagic(str); ok {
+				// then decode the decompressed content
+				if innerValue, innerDecode := autoDecode(value, customDecoder); innerDecode != types.DECODE_NONE {
+					value, resultDecode = innerValue, resultDecode+decodeChainSep+innerDecode
+				}
 				return
 			}
 
@@ -135,21 +301,26 @@ func autoDecode(str string, customDecoder []CmdConvert) (value, resultDecode str
 			//	return
 			//}
 
-			if value, ok = zstdConv.Decode(str); ok {
-				resultDecode = types.DECODE_ZSTD
+			// FIXME: skip decompress with brotli due to incorrect format checking
+			//if value, ok = decodeBrotli(str); ok {
+			//	resultDecode = types.DECODE_BROTLI
+			//	return
+			//}
+
+			if value, ok = javaConv.Decode(str); ok {
+				resultDecode = types.DECODE_JAVA
 				return
 			}
 
-			if value, ok = lz4Conv.Decode(str); ok {
-				resultDecode = types.DECODE_LZ4
+			if value, ok = avroConv.decodeCached(str); ok {
+				resultDecode = types.DECODE_AVRO
 				return
 			}
 
-			// FIXME: skip decompress with brotli due to incorrect format checking
-			//if value, ok = decodeBrotli(str); ok {
-			//	resultDecode = types.DECODE_BROTLI
-			//	return
-			//}
+			if value, ok = bsonConv.Decode(str); ok {
+				resultDecode = types.DECODE_BSON
+				return
+			}
 
 			if value, ok = msgpackConv.Decode(str); ok {
 				resultDecode = types.DECODE_MSGPACK
@@ -161,20 +332,42 @@ func autoDecode(str string, customDecoder []CmdConvert) (value, resultDecode str
 				return
 			}
 
+			if value, ok = igbinConv.Decode(str); ok {
+				resultDecode = types.DECODE_IGBINARY
+				return
+			}
+
 			if value, ok = pickleConv.Decode(str); ok {
 				resultDecode = types.DECODE_PICKLE
 				return
 			}
 
+			if maybeCbor(str) {
+				if value, ok = cborConv.Decode(str); ok {
+					resultDecode = types.DECODE_CBOR
+					return
+				}
+			}
+
 			// try decode with custom decoder
-			for _, decoder := range customDecoder {
+			for i, decoder := range customDecoder {
 				if decoder.Auto {
-					if value, ok = decoder.Decode(str); ok {
+					if value, ok = decodeCustom(str, customDecoder, i); ok {
 						resultDecode = decoder.Name
 						return
 					}
 				}
 			}
+
+			// try decode with wasm plugins
+			for _, plugin := range WasmPlugins() {
+				if plugin.Auto {
+					if value, ok = plugin.Decode(str); ok {
+						resultDecode = plugin.Name
+						return
+					}
+				}
+			}
 		}
 	}
 
@@ -241,6 +434,21 @@ func SaveAs(str, format, decode string, customDecoder []CmdConvert) (value strin
 		}
 	}
 
+	// encode the formatted value, chained decode types are encoded from the innermost
+	parts := strings.Split(decode, decodeChainSep)
+	for i := len(parts) - 1; i >= 0; i-- {
+		if value, err = encodeWith(value, parts[i], customDecoder); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func encodeWith(str, decode string, customDecoder []CmdConvert) (value string, err error) {
+	value = str
+	if strings.HasPrefix(decode, types.DECODE_PROTOBUF+":") {
+		decode = types.DECODE_PROTOBUF
+	}
 	if buildinDecoder, ok := BuildInDecoders[decode]; ok {
 		if encodedValue, ok := buildinDecoder.Encode(str); ok {
 			value = encodedValue
@@ -249,8 +457,18 @@ func SaveAs(str, format, decode string, customDecoder []CmdConvert) (value strin
 		}
 		return
 	} else if decode != types.DECODE_NONE {
-		for _, decoder := range customDecoder {
+		for i, decoder := range customDecoder {
 			if decoder.Name == decode {
+				if len(decoder.Chain) > 0 {
+					// encode steps of chain in reverse
+					others := slices.Delete(slices.Clone(customDecoder), i, i+1)
+					for j := len(decoder.Chain) - 1; j >= 0; j-- {
+						if value, err = encodeWith(value, decoder.Chain[j], others); err != nil {
+							return
+						}
+					}
+					return
+				}
 				if encodedStr, ok := decoder.Encode(str); ok {
 					value = encodedStr
 				} else {
@@ -259,6 +477,13 @@ func SaveAs(str, format, decode string, customDecoder []CmdConvert) (value strin
 				return
 			}
 		}
+		if plugin, ok := findWasmPlugin(decode); ok {
+			if encodedStr, ok := plugin.Encode(str); ok {
+				value = encodedStr
+			} else {
+				err = errors.New("fail to build " + decode)
+			}
+		}
 	}
 	return
 }
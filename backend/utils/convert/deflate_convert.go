@@ -3,7 +3,6 @@ package convutil
 import (
 	"bytes"
 	"github.com/klauspost/compress/flate"
-	"io"
 	"strings"
 )
 
@@ -36,7 +35,7 @@ func (d DeflateConvert) Encode(str string) (string, bool) {
 func (d DeflateConvert) Decode(str string) (string, bool) {
 	reader := flate.NewReader(strings.NewReader(str))
 	defer reader.Close()
-	if decompressed, err := io.ReadAll(reader); err == nil {
+	if decompressed, err := readDecompressed(reader, len(str)); err == nil {
 		return string(decompressed), true
 	}
 	return str, false
@@ -0,0 +1,151 @@
+package convutil
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"tinyrdm/backend/types"
+	cborutil "tinyrdm/backend/utils/cbor"
+	strutil "tinyrdm/backend/utils/string"
+	"unicode/utf8"
+)
+
+// Candidate possible decode type and format of value, with confidence score from 0 to 100
+type Candidate struct {
+	Decode string `json:"decode"`
+	Format string `json:"format"`
+	Score  int    `json:"score"`
+}
+
+// MaxDetectSize max bytes of value for detecting candidates, as all decoders are attempted
+const MaxDetectSize = 1 << 20
+
+type scored struct {
+	name  string // decode type or format
+	value string
+	score int
+}
+
+var digitsPattern = regexp.MustCompile(`^\d+$`)
+
+// DetectCandidates score build-in decoders and formats of value, and rank them in descending order of score.
+// unlike automatic decoding which takes the first matched decoder, all decoders except custom ones are attempted
+func DetectCandidates(str string) []Candidate {
+	if len(str) <= 0 || len(str) > MaxDetectSize {
+		return nil
+	}
+	var candidates []Candidate
+	for _, f := range scoreFormats(str) {
+		candidates = append(candidates, Candidate{Decode: types.DECODE_NONE, Format: f.name, Score: f.score})
+	}
+	for _, d := range scoreDecoders(str) {
+		// decoded content with its best format, more structured content is more confident
+		f := scoreFormats(d.value)[0]
+		candidates = append(candidates, Candidate{Decode: d.name, Format: f.name, Score: d.score * (50 + f.score/2) / 100})
+	}
+	slices.SortStableFunc(candidates, func(a, b Candidate) int {
+		return b.Score - a.Score
+	})
+	return candidates
+}
+
+// score build-in decoders which can decode the value
+func scoreDecoders(str string) []scored {
+	if digitsPattern.MatchString(str) {
+		// pure digit content may incorrect regard as some encoded type
+		return nil
+	}
+	var result []scored
+	try := func(name string, conv DataConvert, score int) {
+		if value, ok := conv.Decode(str); ok {
+			result = append(result, scored{name, value, score})
+		}
+	}
+
+	if len(str)%4 == 0 && len(str) >= 12 && !strutil.IsSameChar(str) {
+		if value, ok := base64Conv.Decode(str); ok {
+			result = append(result, scored{types.DECODE_BASE64, value, 70})
+		} else if value, ok = base64Conv.DecodeBinary(str); ok {
+			if inner, innerDecode, ok := decompressByMagic(value); ok {
+				result = append(result, scored{types.DECODE_BASE64 + decodeChainSep + innerDecode, inner, 85})
+			} else {
+				result = append(result, scored{types.DECODE_BASE64, value, 40})
+			}
+		}
+	}
+
+	// token shaped content with valid JSON header and payload
+	try(types.DECODE_JWT, jwtConv, 95)
+	// magic bytes are the most confident
+	if value, decode, ok := decompressByMagic(str); ok {
+		result = append(result, scored{decode, value, 95})
+	}
+	try(types.DECODE_JAVA, javaConv, 95)
+	if value, ok := avroConv.decodeCached(str); ok {
+		result = append(result, scored{types.DECODE_AVRO, value, 90})
+	}
+	try(types.DECODE_PHP, phpConv, 90)
+	try(types.DECODE_IGBINARY, igbinConv, 90)
+	if strings.HasPrefix(str, "\x80") {
+		try(types.DECODE_PICKLE, pickleConv, 90)
+	} else {
+		try(types.DECODE_PICKLE, pickleConv, 60)
+	}
+	try(types.DECODE_BSON, bsonConv, 85)
+	if cborutil.IsSelfDescribed([]byte(str)) {
+		try(types.DECODE_CBOR, cborConv, 95)
+	} else if maybeCbor(str) {
+		try(types.DECODE_CBOR, cborConv, 50)
+	}
+
+	// msgpack and protobuf can decode most binary content
+	if b := str[0]; b&0xf0 == 0x80 || b&0xf0 == 0x90 || (b >= 0xdc && b <= 0xdf) {
+		// map or array
+		try(types.DECODE_MSGPACK, msgpackConv, 65)
+	} else {
+		try(types.DECODE_MSGPACK, msgpackConv, 25)
+	}
+	if strutil.ContainsBinary(str) {
+		try(types.DECODE_PROTOBUF, protoConv, 40)
+	}
+
+	// legacy charsets are ambiguous with each other
+	if !utf8.ValidString(str) {
+		try(types.DECODE_GBK, gbkConv, 40)
+		try(types.DECODE_BIG5, big5Conv, 35)
+		try(types.DECODE_SHIFT_JIS, sjisConv, 35)
+	}
+	return result
+}
+
+// score formats of value in descending order, raw format is always included
+func scoreFormats(str string) []scored {
+	var result []scored
+	trimmed := strings.TrimSpace(str)
+	if _, ok := jsonConv.Decode(str); ok {
+		if json.Valid([]byte(trimmed)) {
+			result = append(result, scored{types.FORMAT_JSON, "", 95})
+		} else {
+			result = append(result, scored{types.FORMAT_JSON, "", 50})
+		}
+	}
+	if _, ok := xmlConv.Decode(str); ok {
+		result = append(result, scored{types.FORMAT_XML, "", 85})
+	}
+	if _, _, ok := parseTimestamp(trimmed); ok {
+		result = append(result, scored{types.FORMAT_TIMESTAMP, "", 80})
+	}
+	if _, ok := yamlConv.Decode(str); ok && strings.Contains(trimmed, ":") {
+		result = append(result, scored{types.FORMAT_YAML, "", 60})
+	}
+	if strutil.ContainsBinary(str) {
+		result = append(result, scored{types.FORMAT_HEX, "", 60}, scored{types.FORMAT_RAW, "", 10})
+	} else {
+		result = append(result, scored{types.FORMAT_RAW, "", 50})
+	}
+	slices.SortStableFunc(result, func(a, b scored) int {
+		return b.score - a.score
+	})
+	return result
+}
@@ -3,7 +3,6 @@ package convutil
 import (
 	"bytes"
 	"github.com/klauspost/compress/gzip"
-	"io"
 	"strings"
 )
 
@@ -35,7 +34,7 @@ func (GZipConvert) Decode(str string) (string, bool) {
 	if reader, err := gzip.NewReader(strings.NewReader(str)); err == nil {
 		defer reader.Close()
 		var decompressed []byte
-		if decompressed, err = io.ReadAll(reader); err == nil {
+		if decompressed, err = readDecompressed(reader, len(str)); err == nil {
 			return string(decompressed), true
 		}
 	}
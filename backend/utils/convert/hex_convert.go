@@ -31,3 +31,15 @@ func (HexConvert) Decode(str string) (string, bool) {
 	}
 	return resultStr.String(), true
 }
+
+// DecodeHexBytes decode hex string to bytes, whitespaces and prefix like "\x" or "0x" of each byte are ignored
+func DecodeHexBytes(str string) ([]byte, error) {
+	var builder strings.Builder
+	for _, field := range strings.Fields(str) {
+		if len(field) > 2 && (field[:2] == "0x" || field[:2] == "0X") {
+			field = field[2:]
+		}
+		builder.WriteString(strings.ReplaceAll(field, "\\x", ""))
+	}
+	return hex.DecodeString(builder.String())
+}
@@ -0,0 +1,37 @@
+package convutil
+
+import (
+	"encoding/json"
+	"strings"
+	javautil "tinyrdm/backend/utils/java"
+)
+
+// JavaConvert render java serialized object as json, read-only
+type JavaConvert struct{}
+
+func (JavaConvert) Enable() bool {
+	return true
+}
+
+func (JavaConvert) Encode(str string) (string, bool) {
+	// serializing back is not supported
+	return str, false
+}
+
+func (JavaConvert) Decode(str string) (string, bool) {
+	if !strings.HasPrefix(str, javautil.Magic) {
+		return str, false
+	}
+	contents, err := javautil.Parse([]byte(str))
+	if err != nil || len(contents) <= 0 {
+		return str, false
+	}
+	var obj any = contents
+	if len(contents) == 1 {
+		obj = contents[0]
+	}
+	if b, err := json.Marshal(obj); err == nil {
+		return string(b), true
+	}
+	return str, false
+}
@@ -0,0 +1,114 @@
+package convutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// JwtConvert decode JSON web token into header, payload and validity of claims,
+// the signature is never verified as the key is unknown
+type JwtConvert struct{}
+
+type jwtContent struct {
+	Header            json.RawMessage `json:"header"`
+	Payload           json.RawMessage `json:"payload"`
+	Signature         string          `json:"signature"`
+	SignatureVerified bool            `json:"signatureVerified"`
+	IssuedAt          string          `json:"issuedAt,omitempty"`
+	NotBefore         string          `json:"notBefore,omitempty"`
+	ExpiresAt         string          `json:"expiresAt,omitempty"`
+	Expired           *bool           `json:"expired,omitempty"`
+	NotYetValid       *bool           `json:"notYetValid,omitempty"`
+}
+
+// header.payload.signature in base64url, the signature is empty for unsecured token
+var jwtPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{2,}\.[A-Za-z0-9_-]{2,}\.[A-Za-z0-9_-]*$`)
+
+func (JwtConvert) Enable() bool {
+	return true
+}
+
+func (JwtConvert) Encode(str string) (string, bool) {
+	// re-encoding would produce token with mismatched signature
+	return str, false
+}
+
+func (JwtConvert) Decode(str string) (string, bool) {
+	token := strings.TrimSpace(str)
+	if !jwtPattern.MatchString(token) {
+		return str, false
+	}
+	parts := strings.Split(token, ".")
+	header, ok := decodeJwtPart(parts[0])
+	if !ok {
+		return str, false
+	}
+	var h map[string]any
+	if err := json.Unmarshal(header, &h); err != nil || h["alg"] == nil {
+		return str, false
+	}
+	payload, ok := decodeJwtPart(parts[1])
+	if !ok {
+		return str, false
+	}
+	var claims map[string]any
+	d := json.NewDecoder(bytes.NewReader(payload))
+	d.UseNumber()
+	if err := d.Decode(&claims); err != nil {
+		return str, false
+	}
+
+	content := jwtContent{
+		Header:    header,
+		Payload:   payload,
+		Signature: parts[2],
+	}
+	now := time.Now()
+	if t, ok := jwtTime(claims["iat"]); ok {
+		content.IssuedAt = t.Local().Format(time.RFC3339)
+	}
+	if t, ok := jwtTime(claims["nbf"]); ok {
+		notYetValid := now.Before(t)
+		content.NotBefore, content.NotYetValid = t.Local().Format(time.RFC3339), &notYetValid
+	}
+	if t, ok := jwtTime(claims["exp"]); ok {
+		expired := !now.Before(t)
+		content.ExpiresAt, content.Expired = t.Local().Format(time.RFC3339), &expired
+	}
+	if b, err := json.Marshal(content); err == nil {
+		return string(b), true
+	}
+	return str, false
+}
+
+// decode base64url part of token which should be a JSON object
+func decodeJwtPart(part string) (json.RawMessage, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return nil, false
+	}
+	b = bytes.TrimSpace(b)
+	if len(b) <= 0 || b[0] != '{' || !json.Valid(b) {
+		return nil, false
+	}
+	return b, true
+}
+
+// parse NumericDate claim in seconds, which may contain fraction
+func jwtTime(claim any) (time.Time, bool) {
+	num, ok := claim.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec, err := num.Float64()
+	if err != nil || math.IsInf(sec, 0) || math.Abs(sec) > 1e12 {
+		return time.Time{}, false
+	}
+	whole, frac := math.Modf(sec)
+	return time.Unix(int64(whole), int64(frac*1e9)), true
+}
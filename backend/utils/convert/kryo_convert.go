@@ -0,0 +1,33 @@
+package convutil
+
+import (
+	"encoding/json"
+	kryoutil "tinyrdm/backend/utils/kryo"
+)
+
+// KryoConvert render values serialized by kryo as json in best effort, read-only
+type KryoConvert struct{}
+
+func (KryoConvert) Enable() bool {
+	return true
+}
+
+func (KryoConvert) Encode(str string) (string, bool) {
+	// encoding without serializers is not supported
+	return str, false
+}
+
+func (KryoConvert) Decode(str string) (string, bool) {
+	values, err := kryoutil.Parse([]byte(str))
+	if err != nil {
+		return str, false
+	}
+	var obj any = values
+	if len(values) == 1 {
+		obj = values[0]
+	}
+	if b, err := json.Marshal(obj); err == nil {
+		return string(b), true
+	}
+	return str, false
+}
@@ -3,7 +3,6 @@ package convutil
 import (
 	"bytes"
 	"github.com/pierrec/lz4/v4"
-	"io"
 )
 
 type LZ4Convert struct{}
@@ -32,7 +31,7 @@ func (LZ4Convert) Encode(str string) (string, bool) {
 
 func (LZ4Convert) Decode(str string) (string, bool) {
 	reader := lz4.NewReader(bytes.NewReader([]byte(str)))
-	if decompressed, err := io.ReadAll(reader); err == nil {
+	if decompressed, err := readDecompressed(reader, len(str)); err == nil {
 		return string(decompressed), true
 	}
 	return str, false
@@ -1,70 +1,260 @@
 package convutil
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"github.com/vmihailenco/msgpack/v5"
+	"strconv"
+	"strings"
 )
 
 type MsgpackConvert struct{}
 
+// max nesting depth of msgpack arrays and maps to decode
+const msgpackMaxDepth = 512
+
 func (MsgpackConvert) Enable() bool {
 	return true
 }
 
+// Encode encode json into msgpack, integers in json are kept as integers,
+// content which is not json is encoded as msgpack string
 func (c MsgpackConvert) Encode(str string) (string, bool) {
-	var obj map[string]any
-	if err := json.Unmarshal([]byte(str), &obj); err == nil {
-		for k, v := range obj {
-			obj[k] = c.TryFloatToInt(v)
-		}
-		if b, err := msgpack.Marshal(obj); err == nil {
+	var obj any
+	decoder := json.NewDecoder(strings.NewReader(str))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err == nil && !decoder.More() {
+		if b, err := msgpack.Marshal(c.fromJSON(obj)); err == nil {
 			return string(b), true
 		}
 	}
 
-	if b, err := msgpack.Marshal(str); err != nil {
+	if b, err := msgpack.Marshal(str); err == nil {
 		return string(b), true
 	}
 
 	return str, false
 }
 
-func (MsgpackConvert) Decode(str string) (string, bool) {
-	var decodedStr string
-	if err := msgpack.Unmarshal([]byte(str), &decodedStr); err == nil {
-		return decodedStr, true
+// Decode decode msgpack into json, or into plain text if it's a msgpack string,
+// other scalar values are not accepted because they are hard to distinguish from plain text
+func (c MsgpackConvert) Decode(str string) (string, bool) {
+	if !validMsgpack([]byte(str)) {
+		return str, false
+	}
+	reader := bytes.NewReader([]byte(str))
+	decoder := msgpack.NewDecoder(reader)
+	decoder.SetMapDecoder(c.decodeMap)
+	obj, err := decoder.DecodeInterface()
+	if err != nil || reader.Len() > 0 {
+		// trailing bytes indicates not a msgpack value
+		return str, false
 	}
 
-	var obj map[string]any
-	if err := msgpack.Unmarshal([]byte(str), &obj); err == nil {
-		if b, err := json.Marshal(obj); err == nil {
-			if len(b) >= 10 {
-				return string(b), true
-			}
+	switch val := obj.(type) {
+	case string:
+		return val, true
+	case map[any]any, []any:
+		if b, err := json.Marshal(c.toJSON(val)); err == nil {
+			return string(b), true
 		}
 	}
-
 	return str, false
 }
 
-func (c MsgpackConvert) TryFloatToInt(input any) any {
+// decode map with keys of any type, keys of array or map which are not hashable are stringified
+func (c MsgpackConvert) decodeMap(d *msgpack.Decoder) (any, error) {
+	n, err := d.DecodeMapLen()
+	if err != nil || n < 0 {
+		return nil, err
+	}
+	obj := make(map[any]any, min(n, 1024))
+	for i := 0; i < n; i++ {
+		k, err := d.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+		switch k.(type) {
+		case map[any]any, []any:
+			b, _ := json.Marshal(c.toJSON(k))
+			k = string(b)
+		}
+		if obj[k], err = d.DecodeInterface(); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// convert json numbers to integer if possible
+func (c MsgpackConvert) fromJSON(input any) any {
 	switch val := input.(type) {
 	case map[string]any:
 		for k, v := range val {
-			val[k] = c.TryFloatToInt(v)
+			val[k] = c.fromJSON(v)
 		}
 		return val
 	case []any:
 		for i, v := range val {
-			val[i] = c.TryFloatToInt(v)
+			val[i] = c.fromJSON(v)
 		}
 		return val
-	case float64:
-		if val == float64(int(val)) {
-			return int(val)
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(val.String(), 10, 64); err == nil {
+			return u
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	default:
+		return val
+	}
+}
+
+// convert keys of map to string which is required by json
+func (c MsgpackConvert) toJSON(input any) any {
+	switch val := input.(type) {
+	case map[any]any:
+		obj := make(map[string]any, len(val))
+		for k, v := range val {
+			if ks, ok := k.(string); ok {
+				obj[ks] = c.toJSON(v)
+			} else {
+				obj[fmt.Sprint(k)] = c.toJSON(v)
+			}
+		}
+		return obj
+	case []any:
+		for i, v := range val {
+			val[i] = c.toJSON(v)
 		}
 		return val
 	default:
 		return val
 	}
 }
+
+// walk headers of a single msgpack value without decoding, lengths larger than the bytes left are rejected,
+// which prevents the decoder from allocating by length in header of arbitrary content
+func validMsgpack(buf []byte) bool {
+	pos := 0
+	// read unsigned integer of size bytes in big endian
+	readN := func(size int) (int, bool) {
+		if pos+size > len(buf) {
+			return 0, false
+		}
+		var n uint64
+		switch size {
+		case 1:
+			n = uint64(buf[pos])
+		case 2:
+			n = uint64(binary.BigEndian.Uint16(buf[pos:]))
+		case 4:
+			n = uint64(binary.BigEndian.Uint32(buf[pos:]))
+		}
+		pos += size
+		if n > uint64(len(buf)-pos) {
+			return 0, false
+		}
+		return int(n), true
+	}
+	skip := func(size int) bool {
+		if size > len(buf)-pos {
+			return false
+		}
+		pos += size
+		return true
+	}
+
+	// count of values left in each level of nested arrays and maps
+	stack := []int{1}
+	for len(stack) > 0 {
+		top := len(stack) - 1
+		if stack[top] <= 0 {
+			stack = stack[:top]
+			continue
+		}
+		stack[top]--
+		if pos >= len(buf) {
+			return false
+		}
+		c := buf[pos]
+		pos++
+
+		var items int
+		ok := true
+		switch {
+		case c <= 0x7f || c >= 0xe0 || c == 0xc0 || c == 0xc2 || c == 0xc3:
+			// fixint, nil and bool
+		case c <= 0x8f:
+			items = int(c&0x0f) * 2
+		case c <= 0x9f:
+			items = int(c & 0x0f)
+		case c <= 0xbf:
+			ok = skip(int(c & 0x1f))
+		case c == 0xc4 || c == 0xd9:
+			var n int
+			if n, ok = readN(1); ok {
+				ok = skip(n)
+			}
+		case c == 0xc5 || c == 0xda:
+			var n int
+			if n, ok = readN(2); ok {
+				ok = skip(n)
+			}
+		case c == 0xc6 || c == 0xdb:
+			var n int
+			if n, ok = readN(4); ok {
+				ok = skip(n)
+			}
+		case c == 0xc7 || c == 0xc8 || c == 0xc9:
+			// ext with length and type
+			var n int
+			if n, ok = readN(1 << (c - 0xc7)); ok {
+				ok = skip(n + 1)
+			}
+		case c == 0xcc || c == 0xd0:
+			ok = skip(1)
+		case c == 0xcd || c == 0xd1:
+			ok = skip(2)
+		case c == 0xca || c == 0xce || c == 0xd2:
+			ok = skip(4)
+		case c == 0xcb || c == 0xcf || c == 0xd3:
+			ok = skip(8)
+		case c >= 0xd4 && c <= 0xd8:
+			// fixext with type
+			ok = skip(1 + 1<<(c-0xd4))
+		case c == 0xdc:
+			items, ok = readN(2)
+		case c == 0xdd:
+			items, ok = readN(4)
+		case c == 0xde:
+			if items, ok = readN(2); ok {
+				items *= 2
+			}
+		case c == 0xdf:
+			if items, ok = readN(4); ok {
+				items *= 2
+			}
+		default:
+			// 0xc1 is never used
+			ok = false
+		}
+		if !ok || items > len(buf)-pos {
+			return false
+		}
+		if items > 0 {
+			if len(stack) > msgpackMaxDepth {
+				return false
+			}
+			stack = append(stack, items)
+		}
+	}
+	return pos == len(buf)
+}
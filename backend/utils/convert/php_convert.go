@@ -1,89 +1,57 @@
 package convutil
 
 import (
-	"os/exec"
+	"encoding/json"
+	"strings"
+	phputil "tinyrdm/backend/utils/php"
 )
 
-type PhpConvert struct {
-	CmdConvert
-}
-
-const phpDecodeCode = `
-<?php
+// PhpConvert convert between json and format of php serialize()
+type PhpConvert struct{}
 
-$action = strtolower($argv[1]);
-$content = $argv[2];
-
-if ($action === 'decode') {
-    $decoded = base64_decode($content);
-    if ($decoded !== false) {
-        $obj = unserialize($decoded);
-        if ($obj !== false) {
-            $unserialized = json_encode($obj, JSON_UNESCAPED_UNICODE | JSON_UNESCAPED_SLASHES);
-            if ($unserialized !== false) {
-                echo base64_encode($unserialized);
-                return;
-            }
-        }
-    }
-} elseif ($action === 'encode') {
-    $decoded = base64_decode($content);
-    if ($decoded !== false) {
-        $json = json_decode($decoded, true);
-        if ($json !== false) {
-            $serialized = serialize($json);
-            if ($serialized !== false) {
-                echo base64_encode($serialized);
-                return;
-            }
-        }
-    }
+func (PhpConvert) Enable() bool {
+	return true
 }
-echo '[RDM-ERROR]';
-`
 
-func NewPhpConvert() *PhpConvert {
-	c := CmdConvert{
-		Name:       "PHP",
-		Auto:       true,
-		DecodePath: "php",
-		EncodePath: "php",
-	}
-
-	var err error
-	if _, err = exec.LookPath(c.DecodePath); err != nil {
-		return nil
+func (PhpConvert) Encode(str string) (string, bool) {
+	if serialized, err := phputil.Serialize(str); err == nil {
+		return serialized, true
 	}
+	return str, false
+}
 
-	var filepath string
-	if filepath, err = writeExecuteFile([]byte(phpDecodeCode), "php_decoder.php"); err != nil {
-		return nil
+func (PhpConvert) Decode(str string) (string, bool) {
+	obj, err := phputil.Unserialize(str)
+	if err != nil {
+		return str, false
 	}
-	c.DecodeArgs = []string{filepath, "decode"}
-	c.EncodeArgs = []string{filepath, "encode"}
-
-	return &PhpConvert{
-		CmdConvert: c,
+	if b, err := json.Marshal(obj); err == nil {
+		return string(b), true
 	}
+	return str, false
 }
 
-func (p *PhpConvert) Enable() bool {
-	if p == nil {
-		return false
-	}
+// IgbinaryConvert render content of php igbinary extension as json, read-only
+type IgbinaryConvert struct{}
+
+func (IgbinaryConvert) Enable() bool {
 	return true
 }
 
-func (p *PhpConvert) Encode(str string) (string, bool) {
-	if !p.Enable() {
-		return str, false
-	}
-	return p.CmdConvert.Encode(str)
+func (IgbinaryConvert) Encode(str string) (string, bool) {
+	return str, false
 }
 
-func (p *PhpConvert) Decode(str string) (string, bool) {
-	if !p.Enable() {
+func (IgbinaryConvert) Decode(str string) (string, bool) {
+	if !strings.HasPrefix(str, phputil.IgbinaryHeader) {
+		return str, false
+	}
+	obj, err := phputil.UnserializeIgbinary(str)
+	if err != nil {
 		return str, false
 	}
-	return p.CmdConvert.Decode(str)
+	if b, err := json.Marshal(obj); err == nil {
+		return string(b), true
+	}
+	return str, false
 }
@@ -0,0 +1,50 @@
+package convutil
+
+import (
+	"sync/atomic"
+	protoutil "tinyrdm/backend/utils/proto"
+)
+
+// ProtobufConvert decode protobuf message into json, by message type if specified,
+// or without schema like "protoc --decode_raw"
+type ProtobufConvert struct{}
+
+var protobufSchema atomic.Pointer[protoutil.Schema]
+
+// SetProtobufSchema update schema loaded from user-supplied proto files
+func SetProtobufSchema(schema *protoutil.Schema) {
+	protobufSchema.Store(schema)
+}
+
+// GetProtobufSchema get current schema, nil if not loaded
+func GetProtobufSchema() *protoutil.Schema {
+	return protobufSchema.Load()
+}
+
+func (ProtobufConvert) Enable() bool {
+	return true
+}
+
+func (ProtobufConvert) Encode(str string) (string, bool) {
+	// encoding from json is not supported, prevent overwriting binary value with json
+	return str, false
+}
+
+func (ProtobufConvert) Decode(str string) (string, bool) {
+	if decodedStr, err := protoutil.DecodeRaw([]byte(str)); err == nil {
+		return decodedStr, true
+	}
+	return str, false
+}
+
+// DecodeAs decode protobuf message by message type in schema
+func (c ProtobufConvert) DecodeAs(str, msgType string) (string, bool) {
+	schema := protobufSchema.Load()
+	if schema == nil || len(msgType) <= 0 {
+		return c.Decode(str)
+	}
+	if decodedStr, err := schema.Decode([]byte(str), msgType); err == nil {
+		return decodedStr, true
+	}
+	return str, false
+}
@@ -0,0 +1,44 @@
+package convutil
+
+import (
+	"github.com/klauspost/compress/snappy"
+	"strings"
+)
+
+const (
+	// magic chunk of snappy framing format
+	snappyStreamMagic = "\xff\x06\x00\x00sNaPpY"
+	// a copy element of 3 bytes expands to 64 bytes at most
+	snappyMaxRatio = 22
+)
+
+type SnappyConvert struct{}
+
+func (SnappyConvert) Enable() bool {
+	return true
+}
+
+// Encode compress into snappy block format, which is more common than the framing format for cache values
+func (SnappyConvert) Encode(str string) (string, bool) {
+	return string(snappy.Encode(nil, []byte(str))), true
+}
+
+// Decode decompress content in snappy framing format or block format
+func (SnappyConvert) Decode(str string) (string, bool) {
+	limit := max(maxDecodedSize.Load(), int64(len(str)))
+	if strings.HasPrefix(str, snappyStreamMagic) {
+		reader := snappy.NewReader(strings.NewReader(str))
+		if decompressed, err := readDecompressed(reader, len(str)); err == nil {
+			return string(decompressed), true
+		}
+		return str, false
+	}
+	// check length in header before allocating, which is not trusted for arbitrary content
+	if n, err := snappy.DecodedLen([]byte(str)); err != nil || n > len(str)*snappyMaxRatio || int64(n) > limit {
+		return str, false
+	}
+	if decompressed, err := snappy.Decode(nil, []byte(str)); err == nil {
+		return string(decompressed), true
+	}
+	return str, false
+}
@@ -0,0 +1,29 @@
+package convutil
+
+import (
+	"encoding/json"
+	thriftutil "tinyrdm/backend/utils/thrift"
+)
+
+// ThriftConvert render thrift struct or message in binary or compact protocol as json without schema, read-only
+type ThriftConvert struct{}
+
+func (ThriftConvert) Enable() bool {
+	return true
+}
+
+func (ThriftConvert) Encode(str string) (string, bool) {
+	// encoding without schema is not supported
+	return str, false
+}
+
+func (ThriftConvert) Decode(str string) (string, bool) {
+	val, err := thriftutil.Decode([]byte(str))
+	if err != nil {
+		return str, false
+	}
+	if b, err := json.Marshal(val); err == nil {
+		return string(b), true
+	}
+	return str, false
+}
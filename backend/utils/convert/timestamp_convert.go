@@ -0,0 +1,66 @@
+package convutil
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampConvert view unix timestamp in seconds or milliseconds as local time,
+// milliseconds are kept in fraction and converted back on save
+type TimestampConvert struct{}
+
+const (
+	timestampLayout      = time.RFC3339
+	timestampMilliLayout = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// range of timestamps regarded as time, from 2000 to 2100
+var (
+	minTimestamp = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	maxTimestamp = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+)
+
+func (TimestampConvert) Enable() bool {
+	return true
+}
+
+func (TimestampConvert) Encode(str string) (string, bool) {
+	str = strings.TrimSpace(str)
+	if strings.Contains(str, ".") {
+		if t, err := time.Parse(timestampMilliLayout, str); err == nil {
+			return strconv.FormatInt(t.UnixMilli(), 10), true
+		}
+	} else if t, err := time.Parse(timestampLayout, str); err == nil {
+		return strconv.FormatInt(t.Unix(), 10), true
+	}
+	return str, false
+}
+
+func (TimestampConvert) Decode(str string) (string, bool) {
+	if t, milli, ok := parseTimestamp(str); ok {
+		if milli {
+			return t.Local().Format(timestampMilliLayout), true
+		}
+		return t.Local().Format(timestampLayout), true
+	}
+	return str, false
+}
+
+// parse timestamp of 10 digits in seconds or 13 digits in milliseconds within supported range
+func parseTimestamp(str string) (t time.Time, milli, ok bool) {
+	if len(str) != 10 && len(str) != 13 {
+		return
+	}
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || n < 0 {
+		return
+	}
+	if milli = len(str) == 13; milli {
+		t = time.UnixMilli(n)
+	} else {
+		t = time.Unix(n, 0)
+	}
+	ok = t.Unix() >= minTimestamp && t.Unix() < maxTimestamp
+	return
+}
@@ -0,0 +1,142 @@
+package convutil
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	wasmutil "tinyrdm/backend/utils/wasm"
+)
+
+// WasmConvert decoder implemented by wasm module in plugins directory, the module should export:
+//
+//	memory
+//	alloc(size i32) i32          allocate buffer in memory for input
+//	decode(ptr i32, len i32) i64 decode input, return (ptr << 32 | len) of output in memory, or -1 if failed
+//	encode(ptr i32, len i32) i64 optional, encode edited value back in the same way as decode
+//	auto() i32                   optional, return non-zero to try decoding automatically
+//
+// the module is executed in a sandbox without any imports, a new instance is created for each call
+type WasmConvert struct {
+	Name   string
+	Auto   bool
+	module *wasmutil.Module
+}
+
+const wasmFailed = math.MaxUint64
+
+var wasmPlugins atomic.Pointer[[]WasmConvert]
+
+// NewWasmConvert compile wasm module as decoder
+func NewWasmConvert(name string, bin []byte) (WasmConvert, error) {
+	module, err := wasmutil.Compile(bin)
+	if err != nil {
+		return WasmConvert{}, err
+	}
+	c := WasmConvert{Name: name, module: module}
+	for _, fn := range []string{"alloc", "decode"} {
+		if !module.ExportedFunc(fn) {
+			return WasmConvert{}, fmt.Errorf("function %s is not exported", fn)
+		}
+	}
+	if module.ExportedFunc("auto") {
+		if inst, err := module.Instantiate(); err == nil {
+			if res, err := inst.Call("auto"); err == nil && len(res) == 1 {
+				c.Auto = uint32(res[0]) != 0
+			}
+		}
+	}
+	return c, nil
+}
+
+// LoadWasmPlugins load all *.wasm in directory as decoders named by file name,
+// invalid modules are skipped and their errors are joined
+func LoadWasmPlugins(dir string) ([]WasmConvert, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// no plugins directory
+		return nil, nil
+	}
+	var plugins []WasmConvert
+	var errs []error
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".wasm")
+		if !ok || entry.IsDir() || len(name) <= 0 {
+			continue
+		}
+		bin, err := os.ReadFile(path.Join(dir, entry.Name()))
+		if err == nil {
+			var plugin WasmConvert
+			if plugin, err = NewWasmConvert(name, bin); err == nil {
+				plugins = append(plugins, plugin)
+				continue
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+	}
+	return plugins, errors.Join(errs...)
+}
+
+// SetWasmPlugins update decoders loaded from plugins directory
+func SetWasmPlugins(plugins []WasmConvert) {
+	wasmPlugins.Store(&plugins)
+}
+
+// WasmPlugins get all decoders loaded from plugins directory
+func WasmPlugins() []WasmConvert {
+	if plugins := wasmPlugins.Load(); plugins != nil {
+		return *plugins
+	}
+	return nil
+}
+
+func findWasmPlugin(name string) (WasmConvert, bool) {
+	for _, plugin := range WasmPlugins() {
+		if plugin.Name == name {
+			return plugin, true
+		}
+	}
+	return WasmConvert{}, false
+}
+
+func (c WasmConvert) Enable() bool {
+	return c.module != nil
+}
+
+// call function of module with input copied into its memory
+func (c WasmConvert) call(fn string, str string) (string, bool) {
+	if len(str) > math.MaxInt32 || !c.module.ExportedFunc(fn) {
+		return str, false
+	}
+	inst, err := c.module.Instantiate()
+	if err != nil {
+		return str, false
+	}
+	res, err := inst.Call("alloc", uint64(len(str)))
+	if err != nil || len(res) != 1 {
+		return str, false
+	}
+	ptr := uint32(res[0])
+	if err = inst.Write(ptr, []byte(str)); err != nil {
+		return str, false
+	}
+	if res, err = inst.Call(fn, uint64(ptr), uint64(len(str))); err != nil || len(res) != 1 || res[0] == wasmFailed {
+		return str, false
+	}
+	output, err := inst.Read(uint32(res[0]>>32), uint32(res[0]))
+	if err != nil {
+		return str, false
+	}
+	return string(output), true
+}
+
+func (c WasmConvert) Encode(str string) (string, bool) {
+	return c.call("encode", str)
+}
+
+func (c WasmConvert) Decode(str string) (string, bool) {
+	return c.call("decode", str)
+}
@@ -0,0 +1,53 @@
+package convutil
+
+import (
+	"bytes"
+	"github.com/klauspost/compress/zlib"
+	"strings"
+)
+
+type ZlibConvert struct{}
+
+func (ZlibConvert) Enable() bool {
+	return true
+}
+
+func (ZlibConvert) Encode(str string) (string, bool) {
+	var compress = func(b []byte) (string, error) {
+		var buf bytes.Buffer
+		writer := zlib.NewWriter(&buf)
+		if _, err := writer.Write(b); err != nil {
+			writer.Close()
+			return "", err
+		}
+		writer.Close()
+		return string(buf.Bytes()), nil
+	}
+	if zlibStr, err := compress([]byte(str)); err == nil {
+		return zlibStr, true
+	}
+	return str, false
+}
+
+func (ZlibConvert) Decode(str string) (string, bool) {
+	if !isZlibHeader(str) {
+		return str, false
+	}
+	if reader, err := zlib.NewReader(strings.NewReader(str)); err == nil {
+		defer reader.Close()
+		if decompressed, err := readDecompressed(reader, len(str)); err == nil {
+			return string(decompressed), true
+		}
+	}
+	return str, false
+}
+
+// check the two bytes header of zlib, deflate method with window size no more than 32K,
+// and the header is a multiple of 31 as checksum
+func isZlibHeader(str string) bool {
+	if len(str) < 2 {
+		return false
+	}
+	cmf, flg := str[0], str[1]
+	return cmf&0x0f == 8 && cmf>>4 <= 7 && (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
@@ -3,7 +3,6 @@ package convutil
 import (
 	"bytes"
 	"github.com/klauspost/compress/zstd"
-	"io"
 	"strings"
 )
 
@@ -36,7 +35,7 @@ func (ZStdConvert) Encode(str string) (string, bool) {
 func (ZStdConvert) Decode(str string) (string, bool) {
 	if reader, err := zstd.NewReader(strings.NewReader(str)); err == nil {
 		defer reader.Close()
-		if decompressed, err := io.ReadAll(reader); err == nil {
+		if decompressed, err := readDecompressed(reader, len(str)); err == nil {
 			return string(decompressed), true
 		}
 	}
@@ -0,0 +1,102 @@
+package cryptoutil
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const saltSize = 16
+
+// derive 256-bit key from passphrase
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+// NewSalt generate random salt for key derivation
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// DeriveKeyArgon2 derive 256-bit key from password by argon2id
+func DeriveKeyArgon2(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, 3, 64*1024, 4, 32)
+}
+
+// EncryptWithKey encrypt data with AES-256-GCM
+// output format: nonce | ciphertext
+func EncryptWithKey(data, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(nonce)+len(data)+gcm.Overhead())
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, data, nil), nil
+}
+
+// DecryptWithKey decrypt data encrypted by EncryptWithKey
+func DecryptWithKey(data, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted content")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("incorrect passphrase or corrupted content")
+	}
+	return plain, nil
+}
+
+// EncryptWithPassphrase encrypt data with AES-256-GCM, the key is derived from passphrase by scrypt
+// output format: salt | nonce | ciphertext
+func EncryptWithPassphrase(data []byte, passphrase string) ([]byte, error) {
+	salt, err := NewSalt()
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := EncryptWithKey(data, key)
+	if err != nil {
+		return nil, err
+	}
+	return append(salt, encrypted...), nil
+}
+
+// DecryptWithPassphrase decrypt data encrypted by EncryptWithPassphrase
+func DecryptWithPassphrase(data []byte, passphrase string) ([]byte, error) {
+	if len(data) < saltSize {
+		return nil, errors.New("invalid encrypted content")
+	}
+	key, err := deriveKey(passphrase, data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	return DecryptWithKey(data[saltSize:], key)
+}
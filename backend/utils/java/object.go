@@ -0,0 +1,215 @@
+package javautil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Object serialized java object, fields are kept in the order of declaration from super class
+type Object struct {
+	Class       string
+	Fields      []string
+	Values      map[string]any
+	Annotations []any // contents written by writeObject or writeExternal
+}
+
+func (o *Object) set(name string, val any) {
+	if o.Values == nil {
+		o.Values = map[string]any{}
+	}
+	if _, exists := o.Values[name]; !exists {
+		o.Fields = append(o.Fields, name)
+	}
+	o.Values[name] = val
+}
+
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"@class":`)
+	className, _ := json.Marshal(o.Class)
+	buf.Write(className)
+	for _, name := range o.Fields {
+		buf.WriteByte(',')
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		val, err := json.Marshal(o.Values[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	if len(o.Annotations) > 0 {
+		buf.WriteString(`,"@annotations":`)
+		val, err := json.Marshal(o.Annotations)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (b blockData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"@block": base64.StdEncoding.EncodeToString(b)})
+}
+
+// objects in annotations except block data
+func (o *Object) annotationObjects() []any {
+	objs := make([]any, 0, len(o.Annotations))
+	for _, a := range o.Annotations {
+		if _, ok := a.(blockData); !ok {
+			objs = append(objs, a)
+		}
+	}
+	return objs
+}
+
+// simplify common classes of jdk into plain values
+func simplify(o *Object) any {
+	switch o.Class {
+	case "java.lang.Boolean", "java.lang.Byte", "java.lang.Character", "java.lang.Short",
+		"java.lang.Integer", "java.lang.Long", "java.lang.Float", "java.lang.Double",
+		"java.util.concurrent.atomic.AtomicInteger", "java.util.concurrent.atomic.AtomicLong":
+		if val, ok := o.Values["value"]; ok {
+			return val
+		}
+
+	case "java.math.BigInteger":
+		if num := bigInteger(o); num != nil {
+			return json.Number(num.String())
+		}
+
+	case "java.math.BigDecimal":
+		// unscaled value has been simplified from BigInteger
+		intVal, ok := o.Values["intVal"].(json.Number)
+		scale, ok2 := o.Values["scale"].(int32)
+		if ok && ok2 {
+			return json.Number(bigDecimal(intVal.String(), int(scale)))
+		}
+
+	case "java.util.Date", "java.sql.Timestamp", "java.sql.Date":
+		// time in milliseconds written as block data
+		for _, a := range o.Annotations {
+			if b, ok := a.(blockData); ok && len(b) >= 8 {
+				ms := int64(binary.BigEndian.Uint64(b))
+				return time.UnixMilli(ms).Format(time.RFC3339Nano)
+			}
+		}
+
+	case "java.util.ArrayList", "java.util.LinkedList", "java.util.ArrayDeque", "java.util.Vector",
+		"java.util.HashSet", "java.util.LinkedHashSet", "java.util.concurrent.CopyOnWriteArrayList":
+		if o.Class == "java.util.Vector" {
+			if data, ok := o.Values["elementData"].([]any); ok {
+				count, _ := o.Values["elementCount"].(int32)
+				return data[:min(int(count), len(data))]
+			}
+		}
+		return o.annotationObjects()
+
+	case "java.util.TreeSet":
+		// comparator comes first
+		if objs := o.annotationObjects(); len(objs) > 0 {
+			return objs[1:]
+		}
+		return []any{}
+
+	case "java.util.HashMap", "java.util.LinkedHashMap", "java.util.TreeMap", "java.util.Hashtable",
+		"java.util.Properties", "java.util.concurrent.ConcurrentHashMap":
+		objs := o.annotationObjects()
+		if o.Class == "java.util.concurrent.ConcurrentHashMap" && len(objs) >= 2 {
+			// terminated with null key and value
+			objs = objs[:len(objs)-2]
+		}
+		if len(objs)%2 != 0 {
+			break
+		}
+		m := &Object{Class: o.Class}
+		for i := 0; i < len(objs); i += 2 {
+			m.set(mapKey(objs[i]), objs[i+1])
+		}
+		return m.ordered()
+	}
+
+	return o
+}
+
+func bigInteger(o *Object) *big.Int {
+	magnitude, ok := o.Values["magnitude"].([]byte)
+	signum, ok2 := o.Values["signum"].(int32)
+	if !ok || !ok2 {
+		return nil
+	}
+	num := new(big.Int).SetBytes(magnitude)
+	if signum < 0 {
+		num.Neg(num)
+	}
+	return num
+}
+
+// format unscaled value with scale as decimal
+func bigDecimal(unscaled string, scale int) string {
+	sign := ""
+	if strings.HasPrefix(unscaled, "-") {
+		sign, unscaled = "-", unscaled[1:]
+	}
+	if scale <= 0 {
+		if unscaled == "0" {
+			return unscaled
+		}
+		return sign + unscaled + strings.Repeat("0", -scale)
+	}
+	if len(unscaled) <= scale {
+		unscaled = strings.Repeat("0", scale-len(unscaled)+1) + unscaled
+	}
+	return sign + unscaled[:len(unscaled)-scale] + "." + unscaled[len(unscaled)-scale:]
+}
+
+func mapKey(key any) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case nil:
+		return "null"
+	case json.Number:
+		return k.String()
+	}
+	if b, err := json.Marshal(key); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(key)
+}
+
+// orderedMap map with keys in order of insertion
+type orderedMap Object
+
+func (o *Object) ordered() *orderedMap {
+	return (*orderedMap)(o)
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range m.Fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		val, err := json.Marshal(m.Values[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
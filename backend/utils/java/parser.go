@@ -0,0 +1,534 @@
+package javautil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf16"
+)
+
+// Magic header of java object serialization stream, with stream version 5
+const Magic = "\xac\xed\x00\x05"
+
+const (
+	tcNull           = 0x70
+	tcReference      = 0x71
+	tcClassDesc      = 0x72
+	tcObject         = 0x73
+	tcString         = 0x74
+	tcArray          = 0x75
+	tcClass          = 0x76
+	tcBlockData      = 0x77
+	tcEndBlockData   = 0x78
+	tcReset          = 0x79
+	tcBlockDataLong  = 0x7a
+	tcException      = 0x7b
+	tcLongString     = 0x7c
+	tcProxyClassDesc = 0x7d
+	tcEnum           = 0x7e
+
+	baseWireHandle = 0x7e0000
+)
+
+// flags of class description
+const (
+	scWriteMethod    = 0x01
+	scSerializable   = 0x02
+	scExternalizable = 0x04
+	scBlockData      = 0x08
+)
+
+var errMalformed = errors.New("malformed java serialization data")
+
+type classDesc struct {
+	name   string
+	flags  byte
+	fields []fieldDesc
+	super  *classDesc
+}
+
+type fieldDesc struct {
+	typeCode  byte
+	name      string
+	className string // for object and array fields
+}
+
+// blockData raw bytes written by writeObject or writeExternal
+type blockData []byte
+
+type handleEntry struct {
+	value   any
+	pending bool // object is being read, references to it are rendered as placeholder
+}
+
+type parser struct {
+	buf     []byte
+	pos     int
+	handles []*handleEntry
+}
+
+// Parse parse java serialization stream into values made up of map, slice and primitive types,
+// objects are rendered as *Object with class name and fields
+func Parse(buf []byte) ([]any, error) {
+	if len(buf) < len(Magic) || string(buf[:len(Magic)]) != Magic {
+		return nil, errors.New("not java serialization data")
+	}
+	p := &parser{buf: buf, pos: len(Magic)}
+	var contents []any
+	for p.pos < len(p.buf) {
+		val, err := p.readContent()
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, val)
+	}
+	return contents, nil
+}
+
+func (p *parser) readByte() (byte, error) {
+	if p.pos >= len(p.buf) {
+		return 0, errMalformed
+	}
+	p.pos++
+	return p.buf[p.pos-1], nil
+}
+
+func (p *parser) readBytes(n int) ([]byte, error) {
+	if n < 0 || p.pos+n > len(p.buf) {
+		return nil, errMalformed
+	}
+	p.pos += n
+	return p.buf[p.pos-n : p.pos], nil
+}
+
+func (p *parser) readUint16() (uint16, error) {
+	b, err := p.readBytes(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b), nil
+}
+
+func (p *parser) readInt32() (int32, error) {
+	b, err := p.readBytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (p *parser) readInt64() (int64, error) {
+	b, err := p.readBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+// read string in modified utf-8
+func (p *parser) readUTF() (string, error) {
+	length, err := p.readUint16()
+	if err != nil {
+		return "", err
+	}
+	b, err := p.readBytes(int(length))
+	if err != nil {
+		return "", err
+	}
+	return decodeModifiedUTF8(b)
+}
+
+func (p *parser) readLongUTF() (string, error) {
+	length, err := p.readInt64()
+	if err != nil {
+		return "", err
+	}
+	if length < 0 || length > int64(len(p.buf)-p.pos) {
+		return "", errMalformed
+	}
+	b, _ := p.readBytes(int(length))
+	return decodeModifiedUTF8(b)
+}
+
+// decode modified utf-8, which encodes null as two bytes and supplementary characters as surrogate pairs
+func decodeModifiedUTF8(b []byte) (string, error) {
+	units := make([]uint16, 0, len(b))
+	for i := 0; i < len(b); {
+		c := b[i]
+		switch {
+		case c < 0x80:
+			units = append(units, uint16(c))
+			i++
+		case c&0xe0 == 0xc0 && i+1 < len(b):
+			units = append(units, uint16(c&0x1f)<<6|uint16(b[i+1]&0x3f))
+			i += 2
+		case c&0xf0 == 0xe0 && i+2 < len(b):
+			units = append(units, uint16(c&0x0f)<<12|uint16(b[i+1]&0x3f)<<6|uint16(b[i+2]&0x3f))
+			i += 3
+		default:
+			return "", errMalformed
+		}
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+func (p *parser) newHandle(value any) *handleEntry {
+	entry := &handleEntry{value: value}
+	p.handles = append(p.handles, entry)
+	return entry
+}
+
+func (p *parser) readContent() (any, error) {
+	tc, err := p.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch tc {
+	case tcBlockData:
+		var length byte
+		if length, err = p.readByte(); err != nil {
+			return nil, err
+		}
+		b, err := p.readBytes(int(length))
+		return blockData(b), err
+	case tcBlockDataLong:
+		var length int32
+		if length, err = p.readInt32(); err != nil {
+			return nil, err
+		}
+		b, err := p.readBytes(int(length))
+		return blockData(b), err
+	}
+	return p.readObjectTC(tc)
+}
+
+func (p *parser) readObject() (any, error) {
+	tc, err := p.readByte()
+	if err != nil {
+		return nil, err
+	}
+	return p.readObjectTC(tc)
+}
+
+func (p *parser) readObjectTC(tc byte) (any, error) {
+	switch tc {
+	case tcNull:
+		return nil, nil
+	case tcReference:
+		return p.readReference()
+	case tcObject:
+		return p.readNewObject()
+	case tcString:
+		entry := p.newHandle(nil)
+		str, err := p.readUTF()
+		entry.value = str
+		return str, err
+	case tcLongString:
+		entry := p.newHandle(nil)
+		str, err := p.readLongUTF()
+		entry.value = str
+		return str, err
+	case tcArray:
+		return p.readNewArray()
+	case tcEnum:
+		// enum constant is rendered as its name
+		if _, err := p.readClassDesc(); err != nil {
+			return nil, err
+		}
+		entry := p.newHandle(nil)
+		constant, err := p.readObject()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := constant.(string)
+		entry.value = name
+		return name, nil
+	case tcClass:
+		desc, err := p.readClassDesc()
+		if err != nil {
+			return nil, err
+		}
+		val := "class " + desc.className()
+		p.newHandle(val)
+		return val, nil
+	case tcClassDesc, tcProxyClassDesc:
+		p.pos--
+		desc, err := p.readClassDesc()
+		if err != nil {
+			return nil, err
+		}
+		return "class " + desc.className(), nil
+	case tcReset:
+		p.handles = p.handles[:0]
+		return p.readObject()
+	case tcException:
+		p.handles = p.handles[:0]
+		val, err := p.readObject()
+		p.handles = p.handles[:0]
+		return val, err
+	}
+	return nil, fmt.Errorf("unknown type code 0x%02x at %d", tc, p.pos-1)
+}
+
+func (p *parser) readReference() (any, error) {
+	handle, err := p.readInt32()
+	if err != nil {
+		return nil, err
+	}
+	idx := int(handle) - baseWireHandle
+	if idx < 0 || idx >= len(p.handles) {
+		return nil, errMalformed
+	}
+	entry := p.handles[idx]
+	if entry.pending {
+		// circular reference
+		return map[string]any{"@ref": idx}, nil
+	}
+	return entry.value, nil
+}
+
+func (c *classDesc) className() string {
+	if c == nil {
+		return ""
+	}
+	return c.name
+}
+
+func (p *parser) readClassDesc() (*classDesc, error) {
+	tc, err := p.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch tc {
+	case tcNull:
+		return nil, nil
+	case tcReference:
+		val, err := p.readReference()
+		if err != nil {
+			return nil, err
+		}
+		desc, ok := val.(*classDesc)
+		if !ok {
+			return nil, errMalformed
+		}
+		return desc, nil
+	case tcProxyClassDesc:
+		desc := &classDesc{name: "$Proxy"}
+		p.newHandle(desc)
+		var count int32
+		if count, err = p.readInt32(); err != nil {
+			return nil, err
+		}
+		for i := int32(0); i < count; i++ {
+			if _, err = p.readUTF(); err != nil {
+				return nil, err
+			}
+		}
+		if _, err = p.readAnnotation(); err != nil {
+			return nil, err
+		}
+		desc.super, err = p.readClassDesc()
+		return desc, err
+	case tcClassDesc:
+		desc := &classDesc{}
+		if desc.name, err = p.readUTF(); err != nil {
+			return nil, err
+		}
+		// serialVersionUID
+		if _, err = p.readInt64(); err != nil {
+			return nil, err
+		}
+		p.newHandle(desc)
+		if desc.flags, err = p.readByte(); err != nil {
+			return nil, err
+		}
+		var count uint16
+		if count, err = p.readUint16(); err != nil {
+			return nil, err
+		}
+		desc.fields = make([]fieldDesc, count)
+		for i := range desc.fields {
+			field := &desc.fields[i]
+			if field.typeCode, err = p.readByte(); err != nil {
+				return nil, err
+			}
+			if field.name, err = p.readUTF(); err != nil {
+				return nil, err
+			}
+			if field.typeCode == 'L' || field.typeCode == '[' {
+				var className any
+				if className, err = p.readObject(); err != nil {
+					return nil, err
+				}
+				field.className, _ = className.(string)
+			}
+		}
+		if _, err = p.readAnnotation(); err != nil {
+			return nil, err
+		}
+		desc.super, err = p.readClassDesc()
+		return desc, err
+	}
+	return nil, fmt.Errorf("unexpected type code 0x%02x of class description at %d", tc, p.pos-1)
+}
+
+// read contents until end of block data
+func (p *parser) readAnnotation() ([]any, error) {
+	var contents []any
+	for {
+		if p.pos >= len(p.buf) {
+			return nil, errMalformed
+		}
+		if p.buf[p.pos] == tcEndBlockData {
+			p.pos++
+			return contents, nil
+		}
+		val, err := p.readContent()
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, val)
+	}
+}
+
+func (p *parser) readNewObject() (any, error) {
+	desc, err := p.readClassDesc()
+	if err != nil {
+		return nil, err
+	}
+	obj := &Object{Class: desc.className()}
+	entry := p.newHandle(obj)
+	entry.pending = true
+
+	// class data from the topmost super class
+	var hierarchy []*classDesc
+	for d := desc; d != nil; d = d.super {
+		hierarchy = append([]*classDesc{d}, hierarchy...)
+	}
+	for _, d := range hierarchy {
+		switch {
+		case d.flags&scExternalizable != 0:
+			if d.flags&scBlockData == 0 {
+				return nil, errors.New("externalizable class \"" + d.name + "\" in stream protocol 1 is not supported")
+			}
+			annotation, err := p.readAnnotation()
+			if err != nil {
+				return nil, err
+			}
+			obj.Annotations = append(obj.Annotations, annotation...)
+		case d.flags&scSerializable != 0:
+			for _, field := range d.fields {
+				val, err := p.readFieldValue(field.typeCode)
+				if err != nil {
+					return nil, err
+				}
+				obj.set(field.name, val)
+			}
+			if d.flags&scWriteMethod != 0 {
+				annotation, err := p.readAnnotation()
+				if err != nil {
+					return nil, err
+				}
+				obj.Annotations = append(obj.Annotations, annotation...)
+			}
+		}
+	}
+	entry.pending = false
+	entry.value = simplify(obj)
+	return entry.value, nil
+}
+
+func (p *parser) readNewArray() (any, error) {
+	desc, err := p.readClassDesc()
+	if err != nil {
+		return nil, err
+	}
+	entry := p.newHandle(nil)
+	entry.pending = true
+	size, err := p.readInt32()
+	if err != nil {
+		return nil, err
+	}
+	if size < 0 || int(size) > len(p.buf)-p.pos {
+		return nil, errMalformed
+	}
+	name := desc.className()
+	var typeCode byte = 'L'
+	if len(name) >= 2 && name[0] == '[' {
+		typeCode = name[1]
+	}
+
+	var val any
+	switch typeCode {
+	case 'B':
+		// byte array as raw bytes
+		b, err := p.readBytes(int(size))
+		if err != nil {
+			return nil, err
+		}
+		val = []byte(b)
+	case 'C':
+		units := make([]uint16, size)
+		for i := range units {
+			if units[i], err = p.readUint16(); err != nil {
+				return nil, err
+			}
+		}
+		val = string(utf16.Decode(units))
+	default:
+		arr := make([]any, size)
+		for i := range arr {
+			if arr[i], err = p.readFieldValue(typeCode); err != nil {
+				return nil, err
+			}
+		}
+		val = arr
+	}
+	entry.pending = false
+	entry.value = val
+	return val, nil
+}
+
+func (p *parser) readFieldValue(typeCode byte) (any, error) {
+	switch typeCode {
+	case 'B':
+		b, err := p.readByte()
+		return int8(b), err
+	case 'C':
+		c, err := p.readUint16()
+		return string(utf16.Decode([]uint16{c})), err
+	case 'D':
+		v, err := p.readInt64()
+		return formatFloat(math.Float64frombits(uint64(v))), err
+	case 'F':
+		v, err := p.readInt32()
+		return formatFloat(float64(math.Float32frombits(uint32(v)))), err
+	case 'I':
+		return p.readInt32()
+	case 'J':
+		return p.readInt64()
+	case 'S':
+		v, err := p.readUint16()
+		return int16(v), err
+	case 'Z':
+		b, err := p.readByte()
+		return b != 0, err
+	case 'L', '[':
+		return p.readObject()
+	}
+	return nil, fmt.Errorf("unknown field type '%c'", typeCode)
+}
+
+// NaN and infinity are not allowed in json
+func formatFloat(f float64) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return f
+}
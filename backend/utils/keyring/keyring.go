@@ -0,0 +1,24 @@
+package keyringutil
+
+import "errors"
+
+// ErrNotFound secret not found in credential store
+var ErrNotFound = errors.New("secret not found in keychain")
+
+// ErrUnsupported no credential store available on current platform
+var ErrUnsupported = errors.New("keychain is not supported on current platform")
+
+// Set save secret of account into os credential store
+func Set(service, account, secret string) error {
+	return set(service, account, secret)
+}
+
+// Get read secret of account from os credential store, return ErrNotFound if not exists
+func Get(service, account string) (string, error) {
+	return get(service, account)
+}
+
+// Delete remove secret of account from os credential store
+func Delete(service, account string) error {
+	return del(service, account)
+}
@@ -0,0 +1,50 @@
+//go:build darwin
+
+package keyringutil
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const securityCmd = "/usr/bin/security"
+
+// exit code of "security" when item could not be found
+const errSecItemNotFound = 44
+
+func set(service, account, secret string) error {
+	// pass secret by stdin in interactive mode to avoid exposing it in process arguments
+	cmd := exec.Command(securityCmd, "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %q -a %q -X %q\n",
+		service, account, hex.EncodeToString([]byte(secret))))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("save secret to keychain fail: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func get(service, account string) (string, error) {
+	out, err := exec.Command(securityCmd, "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func del(service, account string) error {
+	if err := exec.Command(securityCmd, "delete-generic-password", "-s", service, "-a", account).Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+//go:build linux
+
+package keyringutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// use "secret-tool" of libsecret to access secret service
+const secretToolCmd = "secret-tool"
+
+func lookPath() (string, error) {
+	path, err := exec.LookPath(secretToolCmd)
+	if err != nil {
+		return "", ErrUnsupported
+	}
+	return path, nil
+}
+
+func set(service, account, secret string) error {
+	path, err := lookPath()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, "store", "--label="+service+": "+account, "service", service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("save secret to keychain fail: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func get(service, account string) (string, error) {
+	path, err := lookPath()
+	if err != nil {
+		return "", err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, "lookup", "service", service, "account", account)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err = cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() <= 0 {
+			// exit without any message if no matched item
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("read secret from keychain fail: %s", strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func del(service, account string) error {
+	path, err := lookPath()
+	if err != nil {
+		return err
+	}
+	if out, err := exec.Command(path, "clear", "service", service, "account", account).CombinedOutput(); err != nil {
+		return fmt.Errorf("delete secret from keychain fail: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package kryoutil
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+var errMalformed = errors.New("malformed kryo data")
+
+// ids of classes registered by default, written with offset 2 as 0 is null and 1 is class by name
+const (
+	classInt     = 0
+	classString  = 1
+	classFloat   = 2
+	classBoolean = 3
+	classByte    = 4
+	classChar    = 5
+	classShort   = 6
+	classLong    = 7
+	classDouble  = 8
+	classVoid    = 9
+
+	classNull = -2
+	className = -1
+)
+
+// min length of printable strings collected from data of unknown class
+const minStringLength = 3
+
+type parser struct {
+	buf   []byte
+	pos   int
+	names map[uint64]string // class names already written
+}
+
+// Parse decode values written by kryo.writeClassAndObject without class registrations.
+// values of default registered classes are decoded, and decoding stops at the first object
+// of other classes which can not be decoded without its serializer, the object is rendered as
+// {"@class": name or "@classId": id, "@strings": [...], "@remaining": base64} with readable strings
+// collected from the remaining bytes
+func Parse(buf []byte) ([]any, error) {
+	p := &parser{buf: buf, names: map[uint64]string{}}
+	var values []any
+	for p.pos < len(p.buf) {
+		val, ok, err := p.readClassAndObject()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, val)
+		if !ok {
+			break
+		}
+	}
+	if len(values) <= 0 {
+		return nil, errMalformed
+	}
+	return values, nil
+}
+
+func (p *parser) read(n int) ([]byte, error) {
+	if n < 0 || n > len(p.buf)-p.pos {
+		return nil, errMalformed
+	}
+	p.pos += n
+	return p.buf[p.pos-n : p.pos], nil
+}
+
+func (p *parser) readVarint() (uint64, error) {
+	var n uint64
+	// kryo writes at most 5 bytes for int and 9 bytes for long, the 9th byte uses all 8 bits
+	for i := 0; i < 9; i++ {
+		b, err := p.read(1)
+		if err != nil {
+			return 0, err
+		}
+		if i == 8 {
+			return n | uint64(b[0])<<56, nil
+		}
+		n |= uint64(b[0]&0x7f) << (7 * i)
+		if b[0]&0x80 == 0 {
+			return n, nil
+		}
+	}
+	return n, nil
+}
+
+func (p *parser) readZigzag() (int64, error) {
+	n, err := p.readVarint()
+	return int64(n>>1) ^ -int64(n&1), err
+}
+
+// read string in format of Output.writeString, null string is returned as false
+func (p *parser) readString() (string, bool, error) {
+	b, err := p.read(1)
+	if err != nil {
+		return "", false, err
+	}
+	if b[0]&0x80 == 0 {
+		// ascii, the last char is marked with high bit
+		start := p.pos - 1
+		for {
+			if b[0]&0x80 != 0 {
+				s := []byte(string(p.buf[start:p.pos]))
+				s[len(s)-1] &= 0x7f
+				return string(s), true, nil
+			}
+			if b, err = p.read(1); err != nil {
+				return "", false, err
+			}
+		}
+	}
+
+	// length of utf8 string in chars plus one
+	length := uint64(b[0] & 0x3f)
+	if b[0]&0x40 != 0 {
+		for shift := 6; ; shift += 7 {
+			if b, err = p.read(1); err != nil {
+				return "", false, err
+			}
+			length |= uint64(b[0]&0x7f) << shift
+			if b[0]&0x80 == 0 || shift > 27 {
+				break
+			}
+		}
+	}
+	switch length {
+	case 0:
+		return "", false, nil
+	case 1:
+		return "", true, nil
+	}
+	chars := make([]rune, 0, min(length-1, uint64(len(p.buf)-p.pos)))
+	for i := uint64(0); i < length-1; i++ {
+		// chars are encoded in 1 to 3 bytes like utf8
+		if b, err = p.read(1); err != nil {
+			return "", false, err
+		}
+		c := rune(b[0])
+		switch c >> 4 {
+		case 12, 13:
+			next, err := p.read(1)
+			if err != nil {
+				return "", false, err
+			}
+			c = (c&0x1f)<<6 | rune(next[0]&0x3f)
+		case 14:
+			next, err := p.read(2)
+			if err != nil {
+				return "", false, err
+			}
+			c = (c&0x0f)<<12 | rune(next[0]&0x3f)<<6 | rune(next[1]&0x3f)
+		}
+		chars = append(chars, c)
+	}
+	return string(chars), true, nil
+}
+
+// read class and object, false is returned if the object can not be decoded
+func (p *parser) readClassAndObject() (any, bool, error) {
+	id, err := p.readVarint()
+	if err != nil {
+		return nil, false, err
+	}
+	classID := int64(id) - 2
+	switch classID {
+	case classNull:
+		return nil, true, nil
+	case className:
+		nameID, err := p.readVarint()
+		if err != nil {
+			return nil, false, err
+		}
+		name, exists := p.names[nameID]
+		if !exists {
+			var ok bool
+			if name, ok, err = p.readString(); err != nil || !ok {
+				return nil, false, errMalformed
+			}
+			p.names[nameID] = name
+		}
+		return p.unknownObject("@class", name), false, nil
+	}
+
+	var val any
+	switch classID {
+	case classInt, classLong:
+		val, err = p.readZigzag()
+	case classString:
+		var s string
+		var ok bool
+		if s, ok, err = p.readString(); ok {
+			val = s
+		}
+	case classFloat:
+		var b []byte
+		if b, err = p.read(4); err == nil {
+			val = floatValue(float64(math.Float32frombits(binary.BigEndian.Uint32(b))), 32)
+		}
+	case classDouble:
+		var b []byte
+		if b, err = p.read(8); err == nil {
+			val = floatValue(math.Float64frombits(binary.BigEndian.Uint64(b)), 64)
+		}
+	case classBoolean:
+		var b []byte
+		if b, err = p.read(1); err == nil {
+			val = b[0] != 0
+		}
+	case classByte:
+		var b []byte
+		if b, err = p.read(1); err == nil {
+			val = int64(int8(b[0]))
+		}
+	case classChar:
+		var b []byte
+		if b, err = p.read(2); err == nil {
+			val = string(rune(binary.BigEndian.Uint16(b)))
+		}
+	case classShort:
+		var b []byte
+		if b, err = p.read(2); err == nil {
+			val = int64(int16(binary.BigEndian.Uint16(b)))
+		}
+	case classVoid:
+		val = nil
+	default:
+		return p.unknownObject("@classId", classID), false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return val, true, nil
+}
+
+func floatValue(f float64, bitSize int) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, bitSize)
+	}
+	return f
+}
+
+// render object of unknown class with readable strings in the remaining bytes
+func (p *parser) unknownObject(key string, class any) *object {
+	remaining := p.buf[p.pos:]
+	p.pos = len(p.buf)
+	obj := &object{}
+	obj.set(key, class)
+	obj.set("@strings", collectStrings(remaining))
+	obj.set("@remaining", base64.StdEncoding.EncodeToString(remaining))
+	return obj
+}
+
+// collect printable ascii runs, the high bit of last char marked by kryo is removed
+func collectStrings(buf []byte) []string {
+	strs := []string{}
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end-start >= minStringLength && utf8.Valid(buf[start:end]) {
+			strs = append(strs, string(buf[start:end]))
+		}
+		start = -1
+	}
+	for i, b := range buf {
+		switch {
+		case b >= 0x20 && b < 0x7f:
+			if start < 0 {
+				start = i
+			}
+		case b >= 0xa0 && b < 0xff && start >= 0:
+			// last char of ascii string
+			if i+1-start >= minStringLength {
+				s := append([]byte{}, buf[start:i+1]...)
+				s[len(s)-1] &= 0x7f
+				strs = append(strs, string(s))
+			}
+			start = -1
+		default:
+			flush(i)
+		}
+	}
+	flush(len(buf))
+	return strs
+}
@@ -0,0 +1,37 @@
+package kryoutil
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// object json object with keys in order
+type object struct {
+	keys   []string
+	values []any
+}
+
+func (o *object) set(key string, val any) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, val)
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b, _ := json.Marshal(key)
+		buf.Write(b)
+		buf.WriteByte(':')
+		b, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
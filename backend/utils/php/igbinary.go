@@ -0,0 +1,265 @@
+package phputil
+
+import (
+	"errors"
+	"math"
+)
+
+// IgbinaryHeader header of igbinary format version 2
+const IgbinaryHeader = "\x00\x00\x00\x02"
+
+const (
+	igNull        = 0x00
+	igRef8        = 0x01
+	igRef16       = 0x02
+	igRef32       = 0x03
+	igFalse       = 0x04
+	igTrue        = 0x05
+	igLong8P      = 0x06
+	igLong8N      = 0x07
+	igLong16P     = 0x08
+	igLong16N     = 0x09
+	igLong32P     = 0x0a
+	igLong32N     = 0x0b
+	igDouble      = 0x0c
+	igStringEmpty = 0x0d
+	igStringID8   = 0x0e
+	igStringID16  = 0x0f
+	igStringID32  = 0x10
+	igString8     = 0x11
+	igString16    = 0x12
+	igString32    = 0x13
+	igArray8      = 0x14
+	igArray16     = 0x15
+	igArray32     = 0x16
+	igObject8     = 0x17
+	igObject16    = 0x18
+	igObject32    = 0x19
+	igObjectID8   = 0x1a
+	igObjectID16  = 0x1b
+	igObjectID32  = 0x1c
+	igObjectSer8  = 0x1d
+	igObjectSer16 = 0x1e
+	igObjectSer32 = 0x1f
+	igLong64P     = 0x20
+	igLong64N     = 0x21
+	igObjRef8     = 0x22
+	igObjRef16    = 0x23
+	igObjRef32    = 0x24
+	igRef         = 0x25
+)
+
+type igbinaryReader struct {
+	buf     string
+	pos     int
+	strings []string // table of strings for back reference
+}
+
+// UnserializeIgbinary parse content in format of igbinary_serialize(), references are not resolved
+func UnserializeIgbinary(str string) (any, error) {
+	if len(str) < len(IgbinaryHeader) || str[:len(IgbinaryHeader)] != IgbinaryHeader {
+		return nil, errors.New("not igbinary data")
+	}
+	r := &igbinaryReader{buf: str, pos: len(IgbinaryHeader)}
+	val, err := r.readValue()
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(r.buf) {
+		return nil, errMalformed
+	}
+	return val, nil
+}
+
+func (r *igbinaryReader) readByte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errMalformed
+	}
+	r.pos++
+	return r.buf[r.pos-1], nil
+}
+
+// read big endian unsigned integer in size bytes
+func (r *igbinaryReader) readUint(size int) (uint64, error) {
+	if r.pos+size > len(r.buf) {
+		return 0, errMalformed
+	}
+	var n uint64
+	for i := 0; i < size; i++ {
+		n = n<<8 | uint64(r.buf[r.pos+i])
+	}
+	r.pos += size
+	return n, nil
+}
+
+func (r *igbinaryReader) readBytes(n uint64) (string, error) {
+	if n > uint64(len(r.buf)-r.pos) {
+		return "", errMalformed
+	}
+	s := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return s, nil
+}
+
+// size in bytes of length field by type, types are grouped in 8, 16 and 32 bits
+func sizeOf(tp, base8 byte) int {
+	return 1 << (tp - base8)
+}
+
+func (r *igbinaryReader) readString(tp byte) (string, error) {
+	switch tp {
+	case igStringEmpty:
+		return "", nil
+	case igString8, igString16, igString32:
+		length, err := r.readUint(sizeOf(tp, igString8))
+		if err != nil {
+			return "", err
+		}
+		s, err := r.readBytes(length)
+		if err != nil {
+			return "", err
+		}
+		r.strings = append(r.strings, s)
+		return s, nil
+	case igStringID8, igStringID16, igStringID32:
+		id, err := r.readUint(sizeOf(tp, igStringID8))
+		if err != nil {
+			return "", err
+		}
+		if id >= uint64(len(r.strings)) {
+			return "", errMalformed
+		}
+		return r.strings[id], nil
+	}
+	return "", errMalformed
+}
+
+func (r *igbinaryReader) readLong(tp byte) (int64, error) {
+	var size int
+	switch tp {
+	case igLong8P, igLong8N:
+		size = 1
+	case igLong16P, igLong16N:
+		size = 2
+	case igLong32P, igLong32N:
+		size = 4
+	case igLong64P, igLong64N:
+		size = 8
+	default:
+		return 0, errMalformed
+	}
+	n, err := r.readUint(size)
+	if err != nil {
+		return 0, err
+	}
+	switch tp {
+	case igLong8N, igLong16N, igLong32N, igLong64N:
+		return -int64(n), nil
+	}
+	return int64(n), nil
+}
+
+func (r *igbinaryReader) readArray(tp byte) (*Array, error) {
+	count, err := r.readUint(sizeOf(tp, igArray8))
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(r.buf)-r.pos) {
+		return nil, errMalformed
+	}
+	arr := &Array{}
+	for i := uint64(0); i < count; i++ {
+		keyType, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		var key any
+		switch keyType {
+		case igLong8P, igLong8N, igLong16P, igLong16N, igLong32P, igLong32N, igLong64P, igLong64N:
+			key, err = r.readLong(keyType)
+		default:
+			key, err = r.readString(keyType)
+		}
+		if err != nil {
+			return nil, err
+		}
+		val, err := r.readValue()
+		if err != nil {
+			return nil, err
+		}
+		arr.Set(key, val)
+	}
+	return arr, nil
+}
+
+func (r *igbinaryReader) readValue() (any, error) {
+	tp, err := r.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch tp {
+	case igNull:
+		return nil, nil
+	case igFalse:
+		return false, nil
+	case igTrue:
+		return true, nil
+	case igLong8P, igLong8N, igLong16P, igLong16N, igLong32P, igLong32N, igLong64P, igLong64N:
+		return r.readLong(tp)
+	case igDouble:
+		bits, err := r.readUint(8)
+		if err != nil {
+			return nil, err
+		}
+		return floatNumber(math.Float64frombits(bits)), nil
+	case igStringEmpty, igString8, igString16, igString32, igStringID8, igStringID16, igStringID32:
+		return r.readString(tp)
+	case igArray8, igArray16, igArray32:
+		return r.readArray(tp)
+	case igObject8, igObject16, igObject32, igObjectID8, igObjectID16, igObjectID32:
+		// class name in string, or back reference of string
+		var class string
+		if tp <= igObject32 {
+			class, err = r.readString(igString8 + tp - igObject8)
+		} else {
+			class, err = r.readString(igStringID8 + tp - igObjectID8)
+		}
+		if err != nil {
+			return nil, err
+		}
+		dataType, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		switch dataType {
+		case igArray8, igArray16, igArray32:
+			props, err := r.readArray(dataType)
+			if err != nil {
+				return nil, err
+			}
+			return &Object{Class: class, Props: *props}, nil
+		case igObjectSer8, igObjectSer16, igObjectSer32:
+			length, err := r.readUint(sizeOf(dataType, igObjectSer8))
+			if err != nil {
+				return nil, err
+			}
+			data, err := r.readBytes(length)
+			if err != nil {
+				return nil, err
+			}
+			return &CustomObject{Class: class, Data: data}, nil
+		}
+		return nil, errMalformed
+	case igRef8, igRef16, igRef32, igObjRef8, igObjRef16, igObjRef32:
+		base := byte(igRef8)
+		if tp >= igObjRef8 {
+			base = igObjRef8
+		}
+		idx, err := r.readUint(sizeOf(tp, base))
+		return Reference{Index: int(idx)}, err
+	case igRef:
+		// value referenced by others
+		return r.readValue()
+	}
+	return nil, errors.New("unknown igbinary type")
+}
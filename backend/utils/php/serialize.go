@@ -0,0 +1,381 @@
+package phputil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var errMalformed = errors.New("malformed php serialized data")
+
+type unserializer struct {
+	buf string
+	pos int
+}
+
+// Unserialize parse content in format of php serialize(), the whole content must be consumed
+func Unserialize(str string) (any, error) {
+	u := &unserializer{buf: str}
+	val, err := u.readValue()
+	if err != nil {
+		return nil, err
+	}
+	if u.pos != len(u.buf) {
+		return nil, errMalformed
+	}
+	return val, nil
+}
+
+func (u *unserializer) expect(c byte) error {
+	if u.pos >= len(u.buf) || u.buf[u.pos] != c {
+		return errMalformed
+	}
+	u.pos++
+	return nil
+}
+
+// read until delimiter, the delimiter is consumed
+func (u *unserializer) readUntil(delim byte) (string, error) {
+	idx := strings.IndexByte(u.buf[u.pos:], delim)
+	if idx < 0 {
+		return "", errMalformed
+	}
+	s := u.buf[u.pos : u.pos+idx]
+	u.pos += idx + 1
+	return s, nil
+}
+
+func (u *unserializer) readInt(delim byte) (int64, error) {
+	s, err := u.readUntil(delim)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errMalformed
+	}
+	return n, nil
+}
+
+// read "LEN:"content"" with the given quotes
+func (u *unserializer) readString(open, close byte) (string, error) {
+	length, err := u.readInt(':')
+	if err != nil {
+		return "", err
+	}
+	if err = u.expect(open); err != nil {
+		return "", err
+	}
+	if length < 0 || u.pos+int(length) > len(u.buf) {
+		return "", errMalformed
+	}
+	s := u.buf[u.pos : u.pos+int(length)]
+	u.pos += int(length)
+	if err = u.expect(close); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// read "N:{key;value;...}"
+func (u *unserializer) readPairs() (*Array, error) {
+	count, err := u.readInt(':')
+	if err != nil {
+		return nil, err
+	}
+	if err = u.expect('{'); err != nil {
+		return nil, err
+	}
+	if count < 0 || count > int64(len(u.buf)-u.pos) {
+		return nil, errMalformed
+	}
+	arr := &Array{}
+	for i := int64(0); i < count; i++ {
+		key, err := u.readValue()
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case int64, string:
+		default:
+			return nil, errMalformed
+		}
+		val, err := u.readValue()
+		if err != nil {
+			return nil, err
+		}
+		arr.Set(key, val)
+	}
+	return arr, u.expect('}')
+}
+
+func (u *unserializer) readValue() (any, error) {
+	if u.pos+1 >= len(u.buf) {
+		return nil, errMalformed
+	}
+	tp := u.buf[u.pos]
+	if tp == 'N' {
+		u.pos++
+		return nil, u.expect(';')
+	}
+	u.pos++
+	if err := u.expect(':'); err != nil {
+		return nil, err
+	}
+	switch tp {
+	case 'b':
+		n, err := u.readInt(';')
+		if err != nil || (n != 0 && n != 1) {
+			return nil, errMalformed
+		}
+		return n == 1, nil
+	case 'i':
+		return u.readInt(';')
+	case 'd':
+		s, err := u.readUntil(';')
+		if err != nil {
+			return nil, err
+		}
+		switch s {
+		case "INF", "-INF", "NAN":
+			// not allowed in json
+			return s, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errMalformed
+		}
+		return floatNumber(f), nil
+	case 's':
+		s, err := u.readString('"', '"')
+		if err != nil {
+			return nil, err
+		}
+		return s, u.expect(';')
+	case 'a':
+		return u.readPairs()
+	case 'O':
+		class, err := u.readString('"', '"')
+		if err != nil {
+			return nil, err
+		}
+		if err = u.expect(':'); err != nil {
+			return nil, err
+		}
+		props, err := u.readPairs()
+		if err != nil {
+			return nil, err
+		}
+		return &Object{Class: class, Props: *props}, nil
+	case 'C':
+		class, err := u.readString('"', '"')
+		if err != nil {
+			return nil, err
+		}
+		if err = u.expect(':'); err != nil {
+			return nil, err
+		}
+		data, err := u.readString('{', '}')
+		if err != nil {
+			return nil, err
+		}
+		return &CustomObject{Class: class, Data: data}, nil
+	case 'E':
+		// enum case as "Class:Case"
+		s, err := u.readString('"', '"')
+		if err != nil {
+			return nil, err
+		}
+		return s, u.expect(';')
+	case 'r', 'R':
+		n, err := u.readInt(';')
+		return Reference{Index: int(n)}, err
+	}
+	return nil, errMalformed
+}
+
+// Serialize encode json into format of php serialize(),
+// json object with "@class" is encoded as object, or as custom object if "@data" is present
+func Serialize(jsonStr string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(jsonStr))
+	decoder.UseNumber()
+	val, err := readJSON(decoder)
+	if err != nil {
+		return "", err
+	}
+	if _, err = decoder.Token(); err != io.EOF {
+		return "", errors.New("invalid json")
+	}
+	var sb strings.Builder
+	if err = writeValue(&sb, val); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// jsonObject json object with keys in order
+type jsonObject struct {
+	keys   []string
+	values []any
+}
+
+func (o *jsonObject) get(key string) (any, bool) {
+	for i, k := range o.keys {
+		if k == key {
+			return o.values[i], true
+		}
+	}
+	return nil, false
+}
+
+// read json value keeping key order of objects
+func readJSON(decoder *json.Decoder) (any, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			arr := []any{}
+			for decoder.More() {
+				val, err := readJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, val)
+			}
+			_, err = decoder.Token()
+			return arr, err
+		case '{':
+			obj := &jsonObject{}
+			for decoder.More() {
+				keyTok, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				val, err := readJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				obj.keys = append(obj.keys, keyTok.(string))
+				obj.values = append(obj.values, val)
+			}
+			_, err = decoder.Token()
+			return obj, err
+		}
+		return nil, errors.New("invalid json")
+	}
+	return tok, nil
+}
+
+func writeString(sb *strings.Builder, s string) {
+	fmt.Fprintf(sb, "s:%d:\"%s\";", len(s), s)
+}
+
+// write key of array, keys in canonical decimal integer are converted to integer like php does
+func writeKey(sb *strings.Builder, key string) {
+	if n, err := strconv.ParseInt(key, 10, 64); err == nil && strconv.FormatInt(n, 10) == key {
+		fmt.Fprintf(sb, "i:%d;", n)
+	} else {
+		writeString(sb, key)
+	}
+}
+
+func writeValue(sb *strings.Builder, val any) error {
+	switch v := val.(type) {
+	case nil:
+		sb.WriteString("N;")
+	case bool:
+		if v {
+			sb.WriteString("b:1;")
+		} else {
+			sb.WriteString("b:0;")
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			fmt.Fprintf(sb, "i:%d;", n)
+		} else if f, err := v.Float64(); err == nil {
+			sb.WriteString("d:" + formatFloat(f) + ";")
+		} else {
+			return err
+		}
+	case string:
+		writeString(sb, v)
+	case []any:
+		fmt.Fprintf(sb, "a:%d:{", len(v))
+		for i, item := range v {
+			fmt.Fprintf(sb, "i:%d;", i)
+			if err := writeValue(sb, item); err != nil {
+				return err
+			}
+		}
+		sb.WriteByte('}')
+	case *jsonObject:
+		class, hasClass := v.get("@class")
+		className, _ := class.(string)
+		if hasClass {
+			if data, ok := v.get("@data"); ok && len(v.keys) == 2 {
+				dataStr, _ := data.(string)
+				fmt.Fprintf(sb, "C:%d:\"%s\":%d:{%s}", len(className), className, len(dataStr), dataStr)
+				return nil
+			}
+			fmt.Fprintf(sb, "O:%d:\"%s\":%d:{", len(className), className, len(v.keys)-1)
+		} else {
+			fmt.Fprintf(sb, "a:%d:{", len(v.keys))
+		}
+		for i, key := range v.keys {
+			if hasClass && key == "@class" {
+				continue
+			}
+			if hasClass {
+				// names of property are always string
+				writeString(sb, key)
+			} else {
+				writeKey(sb, key)
+			}
+			if err := writeValue(sb, v.values[i]); err != nil {
+				return err
+			}
+		}
+		sb.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported value %v", val)
+	}
+	return nil
+}
+
+// format float like php with serialize_precision -1
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "INF"
+	case math.IsInf(f, -1):
+		return "-INF"
+	case math.IsNaN(f):
+		return "NAN"
+	}
+	s := strconv.FormatFloat(f, 'G', -1, 64)
+	if strings.ContainsRune(s, 'E') && !strings.ContainsRune(s, '.') {
+		// 1E+25 -> 1.0E+25
+		s = strings.Replace(s, "E", ".0E", 1)
+	}
+	return s
+}
+
+// float in json number, integral value keeps the decimal point to be encoded back as float
+func floatNumber(f float64) any {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return formatFloat(f)
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return json.Number(s)
+}
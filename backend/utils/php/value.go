@@ -0,0 +1,109 @@
+package phputil
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// Array php array with keys in order, keys are int64 or string
+type Array struct {
+	Keys   []any
+	Values []any
+}
+
+func (a *Array) Set(key, val any) {
+	a.Keys = append(a.Keys, key)
+	a.Values = append(a.Values, val)
+}
+
+// is list with sequential keys start from 0
+func (a *Array) isList() bool {
+	for i, key := range a.Keys {
+		if k, ok := key.(int64); !ok || k != int64(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalJSON marshal list as json array, others as json object
+func (a *Array) MarshalJSON() ([]byte, error) {
+	if a.isList() {
+		if len(a.Values) <= 0 {
+			return []byte("[]"), nil
+		}
+		return json.Marshal(a.Values)
+	}
+	return marshalPairs(nil, a)
+}
+
+// Object php object with class name and properties,
+// names of protected and private property are kept with the null bytes prefix
+type Object struct {
+	Class string
+	Props Array
+}
+
+func (o *Object) MarshalJSON() ([]byte, error) {
+	return marshalPairs([]any{"@class", o.Class}, &o.Props)
+}
+
+// CustomObject object implements Serializable interface with custom data
+type CustomObject struct {
+	Class string
+	Data  string
+}
+
+func (o *CustomObject) MarshalJSON() ([]byte, error) {
+	return marshalPairs([]any{"@class", o.Class, "@data", o.Data}, nil)
+}
+
+// Reference reference to previous value which is not resolved
+type Reference struct {
+	Index int
+}
+
+func (r Reference) MarshalJSON() ([]byte, error) {
+	return []byte(`{"@ref":` + strconv.Itoa(r.Index) + `}`), nil
+}
+
+func marshalPairs(leading []any, a *Array) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	write := func(key, val any) error {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		var name string
+		switch k := key.(type) {
+		case int64:
+			name = strconv.FormatInt(k, 10)
+		case string:
+			name = k
+		}
+		b, _ := json.Marshal(name)
+		buf.Write(b)
+		buf.WriteByte(':')
+		b, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		return nil
+	}
+	for i := 0; i+1 < len(leading); i += 2 {
+		if err := write(leading[i], leading[i+1]); err != nil {
+			return nil, err
+		}
+	}
+	if a != nil {
+		for i, key := range a.Keys {
+			if err := write(key, a.Values[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
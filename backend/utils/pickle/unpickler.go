@@ -0,0 +1,739 @@
+package pickleutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var errMalformed = errors.New("malformed pickle data")
+
+// mark object in stack
+type mark struct{}
+
+type unpickler struct {
+	buf   []byte
+	pos   int
+	stack []any
+	memo  map[int64]any
+}
+
+// Unpickle reconstruct value from pickle data of protocol 0 to 5 without executing any code,
+// callables of REDUCE and constructors of classes are never invoked, common types like datetime
+// and OrderedDict are reconstructed by their arguments, other objects are kept with class and state
+func Unpickle(buf []byte) (any, error) {
+	u := &unpickler{buf: buf, memo: map[int64]any{}}
+	for {
+		if u.pos >= len(u.buf) {
+			return nil, errMalformed
+		}
+		op := u.buf[u.pos]
+		u.pos++
+		if op == '.' {
+			// STOP
+			if u.pos != len(u.buf) {
+				return nil, errMalformed
+			}
+			return u.pop()
+		}
+		if err := u.execute(op); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (u *unpickler) push(val any) {
+	u.stack = append(u.stack, val)
+}
+
+func (u *unpickler) pop() (any, error) {
+	if len(u.stack) <= 0 {
+		return nil, errMalformed
+	}
+	val := u.stack[len(u.stack)-1]
+	u.stack = u.stack[:len(u.stack)-1]
+	if _, ok := val.(mark); ok {
+		return nil, errMalformed
+	}
+	return val, nil
+}
+
+func (u *unpickler) top() (any, error) {
+	if len(u.stack) <= 0 {
+		return nil, errMalformed
+	}
+	return u.stack[len(u.stack)-1], nil
+}
+
+// pop items until the latest mark
+func (u *unpickler) popMark() ([]any, error) {
+	for i := len(u.stack) - 1; i >= 0; i-- {
+		if _, ok := u.stack[i].(mark); ok {
+			items := append([]any{}, u.stack[i+1:]...)
+			u.stack = u.stack[:i]
+			return items, nil
+		}
+	}
+	return nil, errMalformed
+}
+
+func (u *unpickler) read(n int) ([]byte, error) {
+	if n < 0 || u.pos+n > len(u.buf) {
+		return nil, errMalformed
+	}
+	u.pos += n
+	return u.buf[u.pos-n : u.pos], nil
+}
+
+func (u *unpickler) readUint(size int) (uint64, error) {
+	b, err := u.read(size)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for i := size - 1; i >= 0; i-- {
+		n = n<<8 | uint64(b[i])
+	}
+	return n, nil
+}
+
+func (u *unpickler) readLine() (string, error) {
+	idx := strings.IndexByte(string(u.buf[u.pos:]), '\n')
+	if idx < 0 {
+		return "", errMalformed
+	}
+	line := string(u.buf[u.pos : u.pos+idx])
+	u.pos += idx + 1
+	return line, nil
+}
+
+// read length in size bytes then content
+func (u *unpickler) readSized(size int) ([]byte, error) {
+	length, err := u.readUint(size)
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(u.buf)-u.pos) {
+		return nil, errMalformed
+	}
+	return u.read(int(length))
+}
+
+// decode little endian two's complement integer
+func decodeLong(b []byte) any {
+	if len(b) <= 8 {
+		var n uint64
+		for i := len(b) - 1; i >= 0; i-- {
+			n = n<<8 | uint64(b[i])
+		}
+		if len(b) > 0 && len(b) < 8 && b[len(b)-1]&0x80 != 0 {
+			n |= math.MaxUint64 << (uint(len(b)) * 8)
+		}
+		return int64(n)
+	}
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	num := new(big.Int).SetBytes(be)
+	if b[len(b)-1]&0x80 != 0 {
+		num.Sub(num, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+	}
+	return num
+}
+
+func parseInt(s string) (any, error) {
+	s = strings.TrimSuffix(s, "L")
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	if num, ok := new(big.Int).SetString(s, 10); ok {
+		return num, nil
+	}
+	return nil, errMalformed
+}
+
+func (u *unpickler) memoGet(idx int64) error {
+	val, ok := u.memo[idx]
+	if !ok {
+		return errMalformed
+	}
+	u.push(val)
+	return nil
+}
+
+func (u *unpickler) memoPut(idx int64) error {
+	val, err := u.top()
+	if err != nil {
+		return err
+	}
+	u.memo[idx] = val
+	return nil
+}
+
+func (u *unpickler) execute(op byte) error {
+	switch op {
+	case 0x80:
+		// PROTO
+		_, err := u.read(1)
+		return err
+	case 0x95:
+		// FRAME
+		_, err := u.read(8)
+		return err
+	case '(':
+		u.push(mark{})
+	case '0':
+		_, err := u.pop()
+		return err
+	case '1':
+		_, err := u.popMark()
+		return err
+	case '2':
+		val, err := u.top()
+		if err != nil {
+			return err
+		}
+		u.push(val)
+
+	// constants and numbers
+	case 'N':
+		u.push(nil)
+	case 0x88:
+		u.push(true)
+	case 0x89:
+		u.push(false)
+	case 'I':
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		switch line {
+		case "00":
+			u.push(false)
+		case "01":
+			u.push(true)
+		default:
+			val, err := parseInt(line)
+			if err != nil {
+				return err
+			}
+			u.push(val)
+		}
+	case 'L':
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		val, err := parseInt(line)
+		if err != nil {
+			return err
+		}
+		u.push(val)
+	case 'J':
+		n, err := u.readUint(4)
+		if err != nil {
+			return err
+		}
+		u.push(int64(int32(uint32(n))))
+	case 'K':
+		n, err := u.readUint(1)
+		if err != nil {
+			return err
+		}
+		u.push(int64(n))
+	case 'M':
+		n, err := u.readUint(2)
+		if err != nil {
+			return err
+		}
+		u.push(int64(n))
+	case 0x8a, 0x8b:
+		// LONG1, LONG4
+		b, err := u.readSized(map[byte]int{0x8a: 1, 0x8b: 4}[op])
+		if err != nil {
+			return err
+		}
+		u.push(decodeLong(b))
+	case 'F':
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(line, 64)
+		if err != nil {
+			return errMalformed
+		}
+		u.push(f)
+	case 'G':
+		b, err := u.read(8)
+		if err != nil {
+			return err
+		}
+		u.push(math.Float64frombits(binary.BigEndian.Uint64(b)))
+
+	// strings and bytes
+	case 'S':
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		s, err := strconv.Unquote(pyQuoteToGo(line))
+		if err != nil {
+			return errMalformed
+		}
+		u.push(Bytes(s))
+	case 'T', 'U', 'B', 'C', 0x8e, 0x96:
+		// BINSTRING, SHORT_BINSTRING, BINBYTES, SHORT_BINBYTES, BINBYTES8, BYTEARRAY8
+		size := map[byte]int{'T': 4, 'U': 1, 'B': 4, 'C': 1, 0x8e: 8, 0x96: 8}[op]
+		b, err := u.readSized(size)
+		if err != nil {
+			return err
+		}
+		u.push(Bytes(append([]byte{}, b...)))
+	case 'V':
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		u.push(decodeRawUnicodeEscape(line))
+	case 'X', 0x8c, 0x8d:
+		// BINUNICODE, SHORT_BINUNICODE, BINUNICODE8
+		size := map[byte]int{'X': 4, 0x8c: 1, 0x8d: 8}[op]
+		b, err := u.readSized(size)
+		if err != nil {
+			return err
+		}
+		if !utf8.Valid(b) {
+			return errMalformed
+		}
+		u.push(string(b))
+
+	// containers
+	case ')':
+		u.push(Tuple{})
+	case 't':
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(Tuple(items))
+	case 0x85, 0x86, 0x87:
+		// TUPLE1, TUPLE2, TUPLE3
+		n := int(op-0x85) + 1
+		items := make(Tuple, n)
+		for i := n - 1; i >= 0; i-- {
+			val, err := u.pop()
+			if err != nil {
+				return err
+			}
+			items[i] = val
+		}
+		u.push(items)
+	case ']':
+		u.push(&List{})
+	case 'l':
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(&List{Items: items})
+	case 'a':
+		val, err := u.pop()
+		if err != nil {
+			return err
+		}
+		return u.appendItems([]any{val})
+	case 'e':
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		return u.appendItems(items)
+	case '}':
+		u.push(&Dict{})
+	case 'd':
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		dict := &Dict{}
+		if err = setItems(dict, items); err != nil {
+			return err
+		}
+		u.push(dict)
+	case 's':
+		val, err := u.pop()
+		if err != nil {
+			return err
+		}
+		key, err := u.pop()
+		if err != nil {
+			return err
+		}
+		return u.setItems([]any{key, val})
+	case 'u':
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		return u.setItems(items)
+	case 0x8f:
+		// EMPTY_SET, rendered as list
+		u.push(&List{})
+	case 0x90:
+		// ADDITEMS
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		return u.appendItems(items)
+	case 0x91:
+		// FROZENSET
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(&List{Items: items})
+
+	// memo
+	case 'g':
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		idx, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			return errMalformed
+		}
+		return u.memoGet(idx)
+	case 'h', 'j':
+		idx, err := u.readUint(map[byte]int{'h': 1, 'j': 4}[op])
+		if err != nil {
+			return err
+		}
+		return u.memoGet(int64(idx))
+	case 'p':
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		idx, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			return errMalformed
+		}
+		return u.memoPut(idx)
+	case 'q', 'r':
+		idx, err := u.readUint(map[byte]int{'q': 1, 'r': 4}[op])
+		if err != nil {
+			return err
+		}
+		return u.memoPut(int64(idx))
+	case 0x94:
+		// MEMOIZE
+		return u.memoPut(int64(len(u.memo)))
+
+	// objects
+	case 'c':
+		module, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		name, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		u.push(&Global{Module: module, Name: name})
+	case 0x93:
+		// STACK_GLOBAL
+		name, err := u.pop()
+		if err != nil {
+			return err
+		}
+		module, err := u.pop()
+		if err != nil {
+			return err
+		}
+		moduleStr, ok1 := module.(string)
+		nameStr, ok2 := name.(string)
+		if !ok1 || !ok2 {
+			return errMalformed
+		}
+		u.push(&Global{Module: moduleStr, Name: nameStr})
+	case 'i':
+		// INST
+		module, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		name, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		args, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(&Object{Class: &Global{Module: module, Name: name}, Args: args})
+	case 'o':
+		// OBJ
+		items, err := u.popMark()
+		if err != nil || len(items) <= 0 {
+			return errMalformed
+		}
+		u.push(reduce(items[0], items[1:]))
+	case 'R', 0x81:
+		// REDUCE, NEWOBJ
+		args, err := u.pop()
+		if err != nil {
+			return err
+		}
+		callable, err := u.pop()
+		if err != nil {
+			return err
+		}
+		argList, _ := args.(Tuple)
+		u.push(reduce(callable, argList))
+	case 0x92:
+		// NEWOBJ_EX
+		if _, err := u.pop(); err != nil {
+			return err
+		}
+		args, err := u.pop()
+		if err != nil {
+			return err
+		}
+		cls, err := u.pop()
+		if err != nil {
+			return err
+		}
+		argList, _ := args.(Tuple)
+		u.push(reduce(cls, argList))
+	case 'b':
+		// BUILD
+		state, err := u.pop()
+		if err != nil {
+			return err
+		}
+		val, err := u.top()
+		if err != nil {
+			return err
+		}
+		switch obj := val.(type) {
+		case *Object:
+			obj.State = state
+		case *Dict:
+			if d, ok := state.(*Dict); ok {
+				for i, key := range d.Keys {
+					obj.Set(key, d.Values[i])
+				}
+			}
+		}
+	case 'P':
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		u.push(map[string]any{"@persistent_id": line})
+	case 'Q':
+		pid, err := u.pop()
+		if err != nil {
+			return err
+		}
+		u.push(&Object{Class: &Global{Module: "pickle", Name: "persistent_id"}, Args: []any{pid}})
+	case 0x82, 0x83, 0x84:
+		// EXT1, EXT2, EXT4
+		code, err := u.readUint(map[byte]int{0x82: 1, 0x83: 2, 0x84: 4}[op])
+		if err != nil {
+			return err
+		}
+		u.push(&Global{Module: "copyreg", Name: "extension_" + strconv.FormatUint(code, 10)})
+	case 0x97, 0x98:
+		// out-of-band buffers are not available
+		return errors.New("out-of-band buffer is not supported")
+	default:
+		return fmt.Errorf("unknown opcode 0x%02x at %d", op, u.pos-1)
+	}
+	return nil
+}
+
+func (u *unpickler) appendItems(items []any) error {
+	val, err := u.top()
+	if err != nil {
+		return err
+	}
+	switch target := val.(type) {
+	case *List:
+		target.Items = append(target.Items, items...)
+	case *Object:
+		target.Items = append(target.Items, items...)
+	default:
+		return errMalformed
+	}
+	return nil
+}
+
+func (u *unpickler) setItems(items []any) error {
+	val, err := u.top()
+	if err != nil {
+		return err
+	}
+	switch target := val.(type) {
+	case *Dict:
+		return setItems(target, items)
+	case *Object:
+		// instance of dict subclass
+		dict, ok := target.State.(*Dict)
+		if !ok {
+			dict = &Dict{}
+			target.State = dict
+		}
+		return setItems(dict, items)
+	}
+	return errMalformed
+}
+
+func setItems(dict *Dict, items []any) error {
+	if len(items)%2 != 0 {
+		return errMalformed
+	}
+	for i := 0; i < len(items); i += 2 {
+		dict.Set(items[i], items[i+1])
+	}
+	return nil
+}
+
+// reconstruct value of well-known callables by arguments, without calling anything
+func reduce(callable any, args []any) any {
+	global, ok := callable.(*Global)
+	if !ok {
+		return &Object{Class: &Global{Module: "builtins", Name: "object"}, Args: args}
+	}
+
+	switch global.String() {
+	case "collections.OrderedDict", "builtins.dict", "__builtin__.dict", "collections.defaultdict":
+		return &Dict{}
+	case "builtins.set", "builtins.frozenset", "__builtin__.set", "__builtin__.frozenset",
+		"builtins.list", "__builtin__.list":
+		if len(args) > 0 {
+			if list, ok := args[0].(*List); ok {
+				return &List{Items: list.Items}
+			}
+		}
+		return &List{}
+	case "builtins.bytearray", "__builtin__.bytearray", "_codecs.encode":
+		// bytes pickled in protocol 2 as _codecs.encode(str, "latin1")
+		if len(args) > 0 {
+			switch v := args[0].(type) {
+			case string:
+				b := make([]byte, 0, len(v))
+				for _, r := range v {
+					b = append(b, byte(r))
+				}
+				return Bytes(b)
+			case Bytes:
+				return v
+			}
+		}
+		return Bytes{}
+	case "decimal.Decimal":
+		if len(args) > 0 {
+			if s, ok := args[0].(string); ok {
+				return s
+			}
+		}
+	case "datetime.datetime", "datetime.date", "datetime.time":
+		if len(args) > 0 {
+			var b []byte
+			switch v := args[0].(type) {
+			case Bytes:
+				b = v
+			case string:
+				// encoded in latin1 by python 2
+				for _, r := range v {
+					b = append(b, byte(r))
+				}
+			}
+			if s, ok := formatDatetime(global.Name, b, args[1:]); ok {
+				return s
+			}
+		}
+	case "copy_reg._reconstructor", "copyreg._reconstructor":
+		// _reconstructor(cls, base, state)
+		if len(args) > 0 {
+			if cls, ok := args[0].(*Global); ok {
+				return &Object{Class: cls}
+			}
+		}
+	case "copyreg.__newobj__", "copy_reg.__newobj__":
+		if len(args) > 0 {
+			if cls, ok := args[0].(*Global); ok {
+				return &Object{Class: cls, Args: args[1:]}
+			}
+		}
+	}
+	return &Object{Class: global, Args: args}
+}
+
+// format datetime pickled as bytes in the layout of datetime module
+func formatDatetime(kind string, b []byte, rest []any) (string, bool) {
+	var tzSuffix string
+	if len(rest) > 0 && rest[0] != nil {
+		// timezone is kept as class name only
+		if obj, ok := rest[0].(*Object); ok {
+			tzSuffix = " " + obj.Class.String()
+		}
+	}
+	switch {
+	case kind == "date" && len(b) == 4:
+		return fmt.Sprintf("%04d-%02d-%02d", int(b[0])<<8|int(b[1]), b[2], b[3]), true
+	case kind == "time" && len(b) == 6:
+		t := time.Date(0, 1, 1, int(b[0]), int(b[1]), int(b[2]), (int(b[3])<<16|int(b[4])<<8|int(b[5]))*1000, time.UTC)
+		return t.Format("15:04:05.999999") + tzSuffix, true
+	case kind == "datetime" && len(b) == 10:
+		t := time.Date(int(b[0])<<8|int(b[1]), time.Month(b[2]), int(b[3]), int(b[4]), int(b[5]), int(b[6]),
+			(int(b[7])<<16|int(b[8])<<8|int(b[9]))*1000, time.UTC)
+		return t.Format("2006-01-02T15:04:05.999999") + tzSuffix, true
+	}
+	return "", false
+}
+
+// convert python string literal of repr() into go quoted string
+func pyQuoteToGo(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		inner := s[1 : len(s)-1]
+		inner = strings.ReplaceAll(inner, `\'`, `'`)
+		inner = strings.ReplaceAll(inner, `"`, `\"`)
+		return `"` + inner + `"`
+	}
+	return s
+}
+
+// decode raw-unicode-escape of protocol 0 string, only \uXXXX and \UXXXXXXXX are escaped
+func decodeRawUnicodeEscape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && (s[i+1] == 'u' || s[i+1] == 'U') {
+			size := 4
+			if s[i+1] == 'U' {
+				size = 8
+			}
+			if i+2+size <= len(s) {
+				if code, err := strconv.ParseUint(s[i+2:i+2+size], 16, 32); err == nil {
+					sb.WriteRune(rune(code))
+					i += 1 + size
+					continue
+				}
+			}
+		}
+		// other bytes are latin1
+		sb.WriteRune(rune(s[i]))
+	}
+	return sb.String()
+}
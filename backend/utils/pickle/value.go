@@ -0,0 +1,195 @@
+package pickleutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Global reference to class or function by module and name, never imported
+type Global struct {
+	Module string
+	Name   string
+}
+
+func (g *Global) String() string {
+	return g.Module + "." + g.Name
+}
+
+// Object instance of class, created without calling any constructor
+type Object struct {
+	Class *Global
+	Args  []any
+	State any
+	Items []any // items appended to instance of list subclass
+}
+
+type List struct {
+	Items []any
+}
+
+type Dict struct {
+	Keys   []any
+	Values []any
+}
+
+func (d *Dict) Set(key, val any) {
+	switch key.(type) {
+	case nil, bool, int64, float64, string:
+		// replace value of existed key, other types are not comparable
+		for i, k := range d.Keys {
+			if k == key {
+				d.Values[i] = val
+				return
+			}
+		}
+	}
+	d.Keys = append(d.Keys, key)
+	d.Values = append(d.Values, val)
+}
+
+type Tuple []any
+
+// Bytes python bytes and bytearray
+type Bytes []byte
+
+// orderedMap rendered json object with keys in order
+type orderedMap struct {
+	keys   []string
+	values []any
+}
+
+func (m *orderedMap) set(key string, val any) {
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, val)
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b, _ := json.Marshal(key)
+		buf.Write(b)
+		buf.WriteByte(':')
+		b, err := json.Marshal(m.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ToJSON render unpickled value as json, recursive references are rendered as placeholder
+func ToJSON(val any) ([]byte, error) {
+	return json.Marshal(render(val, map[any]bool{}))
+}
+
+func render(val any, visiting map[any]bool) any {
+	switch v := val.(type) {
+	case *List, *Dict, *Object:
+		if visiting[v] {
+			return map[string]string{"@ref": "recursive"}
+		}
+		visiting[v] = true
+		defer delete(visiting, v)
+	}
+
+	switch v := val.(type) {
+	case nil, bool, int64, string:
+		return v
+	case *big.Int:
+		return json.Number(v.String())
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		return v
+	case Bytes:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return base64.StdEncoding.EncodeToString(v)
+	case Tuple:
+		return renderItems(v, visiting)
+	case *List:
+		return renderItems(v.Items, visiting)
+	case *Dict:
+		m := &orderedMap{}
+		for i, key := range v.Keys {
+			m.set(renderKey(key, visiting), render(v.Values[i], visiting))
+		}
+		return m
+	case *Global:
+		return v.String()
+	case *Object:
+		m := &orderedMap{}
+		m.set("@class", v.Class.String())
+		if len(v.Args) > 0 {
+			m.set("@args", renderItems(v.Args, visiting))
+		}
+		switch state := v.State.(type) {
+		case nil:
+		case *Dict:
+			for i, key := range state.Keys {
+				m.set(renderKey(key, visiting), render(state.Values[i], visiting))
+			}
+		case Tuple:
+			// state of object with __slots__ is (dict, slots)
+			if len(state) == 2 {
+				for _, part := range state {
+					if d, ok := part.(*Dict); ok {
+						for i, key := range d.Keys {
+							m.set(renderKey(key, visiting), render(d.Values[i], visiting))
+						}
+					}
+				}
+			} else {
+				m.set("@state", render(state, visiting))
+			}
+		default:
+			m.set("@state", render(state, visiting))
+		}
+		if len(v.Items) > 0 {
+			m.set("@items", renderItems(v.Items, visiting))
+		}
+		return m
+	}
+	return fmt.Sprint(val)
+}
+
+func renderItems(items []any, visiting map[any]bool) []any {
+	list := make([]any, len(items))
+	for i, item := range items {
+		list[i] = render(item, visiting)
+	}
+	return list
+}
+
+func renderKey(key any, visiting map[any]bool) string {
+	switch k := render(key, visiting).(type) {
+	case string:
+		return k
+	case nil:
+		return "None"
+	case bool:
+		if k {
+			return "True"
+		}
+		return "False"
+	default:
+		if b, err := json.Marshal(k); err == nil {
+			return string(b)
+		}
+		return fmt.Sprint(k)
+	}
+}
@@ -0,0 +1,388 @@
+package protoutil
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errMalformed = errors.New("malformed protobuf data")
+
+// walk through all fields of encoded message, val holds the bits of varint and fixed types,
+// data holds the content of length-delimited fields
+func walkFields(buf []byte, fn func(num int32, wireType int, val uint64, data []byte) error) error {
+	pos := 0
+	for pos < len(buf) {
+		tag, n := binary.Uvarint(buf[pos:])
+		if n <= 0 {
+			return errMalformed
+		}
+		pos += n
+		num, wireType := int32(tag>>3), int(tag&0x07)
+		if num <= 0 {
+			return errMalformed
+		}
+		var val uint64
+		var data []byte
+		switch wireType {
+		case wireVarint:
+			if val, n = binary.Uvarint(buf[pos:]); n <= 0 {
+				return errMalformed
+			}
+			pos += n
+		case wireFixed64:
+			if pos+8 > len(buf) {
+				return errMalformed
+			}
+			val = binary.LittleEndian.Uint64(buf[pos:])
+			pos += 8
+		case wireFixed32:
+			if pos+4 > len(buf) {
+				return errMalformed
+			}
+			val = uint64(binary.LittleEndian.Uint32(buf[pos:]))
+			pos += 4
+		case wireBytes:
+			length, n := binary.Uvarint(buf[pos:])
+			if n <= 0 || length > uint64(len(buf)-pos-n) {
+				return errMalformed
+			}
+			pos += n
+			data = buf[pos : pos+int(length)]
+			pos += int(length)
+		default:
+			// deprecated groups are not supported
+			return errMalformed
+		}
+		if err := fn(num, wireType, val, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode decode binary message into json in the canonical form of protojson,
+// 64-bit integers are quoted, bytes are base64 encoded, enums are named, unknown fields are keyed by number
+func (s *Schema) Decode(buf []byte, msgName string) (string, error) {
+	msg, ok := s.Message(msgName)
+	if !ok {
+		return "", errors.New("unknown message type \"" + msgName + "\"")
+	}
+	return s.decodeMessage(buf, msg)
+}
+
+// DecodeRaw decode binary message without schema, nested messages are guessed
+func DecodeRaw(buf []byte) (string, error) {
+	return (&Schema{}).decodeMessage(buf, nil)
+}
+
+type fieldValues struct {
+	field  *Field
+	number int32
+	values []string
+}
+
+func (s *Schema) decodeMessage(buf []byte, msg *Message) (string, error) {
+	var fields []*fieldValues
+	byNumber := map[int32]*fieldValues{}
+	err := walkFields(buf, func(num int32, wireType int, val uint64, data []byte) error {
+		fv, ok := byNumber[num]
+		if !ok {
+			fv = &fieldValues{number: num}
+			if msg != nil {
+				fv.field = msg.byNumber[num]
+			}
+			byNumber[num] = fv
+			fields = append(fields, fv)
+		}
+		if fv.field != nil {
+			if vals, ok, err := s.decodeField(fv.field, wireType, val, data); err != nil {
+				return err
+			} else if ok {
+				if fv.field.Repeated {
+					fv.values = append(fv.values, vals...)
+				} else {
+					// the last one wins for singular field
+					fv.values = vals[len(vals)-1:]
+				}
+				return nil
+			}
+		}
+		fv.values = append(fv.values, decodeUnknown(wireType, val, data))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// known fields in the declared order, then unknown fields
+	if msg != nil {
+		ordered := make([]*fieldValues, 0, len(fields))
+		for _, field := range msg.Fields {
+			if fv, ok := byNumber[field.Number]; ok && fv.field != nil {
+				ordered = append(ordered, fv)
+			}
+		}
+		for _, fv := range fields {
+			if fv.field == nil {
+				ordered = append(ordered, fv)
+			}
+		}
+		fields = ordered
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, fv := range fields {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		name := strconv.Itoa(int(fv.number))
+		if fv.field != nil {
+			name = fv.field.Name
+		}
+		sb.WriteString(quote(name))
+		sb.WriteByte(':')
+		switch {
+		case fv.field != nil && fv.field.Repeated && s.isMapEntry(fv.field):
+			sb.WriteByte('{')
+			for j, entry := range fv.values {
+				if j > 0 {
+					sb.WriteByte(',')
+				}
+				sb.WriteString(entry)
+			}
+			sb.WriteByte('}')
+		case fv.field != nil && !fv.field.Repeated:
+			sb.WriteString(fv.values[0])
+		case fv.field == nil && len(fv.values) == 1:
+			sb.WriteString(fv.values[0])
+		default:
+			sb.WriteByte('[')
+			sb.WriteString(strings.Join(fv.values, ","))
+			sb.WriteByte(']')
+		}
+	}
+	sb.WriteByte('}')
+	return sb.String(), nil
+}
+
+func (s *Schema) isMapEntry(field *Field) bool {
+	if field.Type != typeMessage {
+		return false
+	}
+	msg, ok := s.messages[field.TypeName]
+	return ok && msg.MapEntry
+}
+
+// decode value of known field, return false if wire type mismatched
+func (s *Schema) decodeField(field *Field, wireType int, val uint64, data []byte) ([]string, bool, error) {
+	switch field.Type {
+	case typeString:
+		if wireType != wireBytes {
+			return nil, false, nil
+		}
+		return []string{quote(string(data))}, true, nil
+	case typeBytes:
+		if wireType != wireBytes {
+			return nil, false, nil
+		}
+		return []string{quote(base64.StdEncoding.EncodeToString(data))}, true, nil
+	case typeMessage, typeGroup:
+		if wireType != wireBytes {
+			return nil, false, nil
+		}
+		msg, ok := s.messages[field.TypeName]
+		if !ok {
+			// message type not loaded, such as well-known types
+			val, err := (&Schema{}).decodeMessage(data, nil)
+			return []string{val}, err == nil, nil
+		}
+		if msg.MapEntry {
+			val, err := s.decodeMapEntry(data, msg)
+			return []string{val}, err == nil, err
+		}
+		val, err := s.decodeMessage(data, msg)
+		return []string{val}, err == nil, err
+	}
+
+	expectWireType := wireVarint
+	switch field.Type {
+	case typeDouble, typeFixed64, typeSfixed64:
+		expectWireType = wireFixed64
+	case typeFloat, typeFixed32, typeSfixed32:
+		expectWireType = wireFixed32
+	}
+	if wireType == expectWireType {
+		return []string{s.formatScalar(field, val)}, true, nil
+	}
+	if wireType != wireBytes || !field.Repeated {
+		return nil, false, nil
+	}
+
+	// packed repeated scalars
+	var vals []string
+	for pos := 0; pos < len(data); {
+		switch expectWireType {
+		case wireFixed64:
+			if pos+8 > len(data) {
+				return nil, false, errMalformed
+			}
+			val = binary.LittleEndian.Uint64(data[pos:])
+			pos += 8
+		case wireFixed32:
+			if pos+4 > len(data) {
+				return nil, false, errMalformed
+			}
+			val = uint64(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+		default:
+			var n int
+			if val, n = binary.Uvarint(data[pos:]); n <= 0 {
+				return nil, false, errMalformed
+			}
+			pos += n
+		}
+		vals = append(vals, s.formatScalar(field, val))
+	}
+	if len(vals) <= 0 {
+		return nil, false, nil
+	}
+	return vals, true, nil
+}
+
+// decode map entry into "key":value
+func (s *Schema) decodeMapEntry(buf []byte, msg *Message) (string, error) {
+	obj, err := s.decodeMessage(buf, msg)
+	if err != nil {
+		return "", err
+	}
+	var entry struct {
+		Key   json.RawMessage `json:"key"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err = json.Unmarshal([]byte(obj), &entry); err != nil {
+		return "", err
+	}
+	key := string(entry.Key)
+	if len(key) <= 0 {
+		key = zeroValue(msg.byNumber[1])
+	}
+	if !strings.HasPrefix(key, "\"") {
+		// keys of map are always string in json
+		key = quote(key)
+	}
+	value := string(entry.Value)
+	if len(value) <= 0 {
+		value = zeroValue(msg.byNumber[2])
+	}
+	return key + ":" + value, nil
+}
+
+func zeroValue(field *Field) string {
+	if field == nil {
+		return "null"
+	}
+	switch field.Type {
+	case typeString, typeBytes:
+		return `""`
+	case typeBool:
+		return "false"
+	case typeMessage, typeGroup:
+		return "{}"
+	case typeInt64, typeUint64, typeFixed64, typeSfixed64, typeSint64:
+		return `"0"`
+	}
+	return "0"
+}
+
+func (s *Schema) formatScalar(field *Field, val uint64) string {
+	switch field.Type {
+	case typeDouble:
+		return formatFloat(math.Float64frombits(val), 64)
+	case typeFloat:
+		return formatFloat(float64(math.Float32frombits(uint32(val))), 32)
+	case typeInt64, typeSfixed64:
+		return quote(strconv.FormatInt(int64(val), 10))
+	case typeUint64, typeFixed64:
+		return quote(strconv.FormatUint(val, 10))
+	case typeInt32, typeSfixed32:
+		return strconv.FormatInt(int64(int32(val)), 10)
+	case typeUint32, typeFixed32:
+		return strconv.FormatUint(uint64(uint32(val)), 10)
+	case typeSint32:
+		return strconv.FormatInt(int64(int32(uint32(val)>>1)^-int32(val&1)), 10)
+	case typeSint64:
+		return quote(strconv.FormatInt(int64(val>>1)^-int64(val&1), 10))
+	case typeBool:
+		return strconv.FormatBool(val != 0)
+	case typeEnum:
+		if enum, ok := s.enums[field.TypeName]; ok {
+			if name, ok := enum.Values[int32(val)]; ok {
+				return quote(name)
+			}
+		}
+		return strconv.FormatInt(int64(int32(val)), 10)
+	}
+	return strconv.FormatUint(val, 10)
+}
+
+func formatFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return `"NaN"`
+	case math.IsInf(f, 1):
+		return `"Infinity"`
+	case math.IsInf(f, -1):
+		return `"-Infinity"`
+	}
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
+
+// decode field without schema, length-delimited value is decoded as string if printable,
+// or as nested message if possible, otherwise as base64
+func decodeUnknown(wireType int, val uint64, data []byte) string {
+	switch wireType {
+	case wireBytes:
+		if isPrintable(data) {
+			return quote(string(data))
+		}
+		if nested, err := DecodeRaw(data); err == nil {
+			return nested
+		}
+		return quote(base64.StdEncoding.EncodeToString(data))
+	case wireFixed64:
+		return quote(strconv.FormatUint(val, 10))
+	}
+	return strconv.FormatUint(val, 10)
+}
+
+func isPrintable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func quote(str string) string {
+	b, _ := json.Marshal(str)
+	return string(b)
+}
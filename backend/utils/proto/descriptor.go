@@ -0,0 +1,155 @@
+package protoutil
+
+import (
+	"strings"
+)
+
+// parse binary FileDescriptorSet into schema
+func parseDescriptorSet(buf []byte, schema *Schema, unresolved map[*Field]*Message) error {
+	return walkFields(buf, func(num int32, wireType int, val uint64, data []byte) error {
+		if num == 1 && wireType == wireBytes {
+			return parseFileDescriptor(data, schema, unresolved)
+		}
+		return nil
+	})
+}
+
+func parseFileDescriptor(buf []byte, schema *Schema, unresolved map[*Field]*Message) error {
+	// package may come after message types
+	var pkg string
+	var messages, enums [][]byte
+	err := walkFields(buf, func(num int32, wireType int, val uint64, data []byte) error {
+		if wireType != wireBytes {
+			return nil
+		}
+		switch num {
+		case 2:
+			pkg = string(data)
+		case 4:
+			messages = append(messages, data)
+		case 5:
+			enums = append(enums, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, data := range messages {
+		if err = parseMessageDescriptor(data, pkg, schema, unresolved); err != nil {
+			return err
+		}
+	}
+	for _, data := range enums {
+		if err = parseEnumDescriptor(data, pkg, schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseMessageDescriptor(buf []byte, scope string, schema *Schema, unresolved map[*Field]*Message) error {
+	msg := &Message{}
+	var fields, nested, enums [][]byte
+	err := walkFields(buf, func(num int32, wireType int, val uint64, data []byte) error {
+		if wireType != wireBytes {
+			return nil
+		}
+		switch num {
+		case 1:
+			msg.Name = joinName(scope, string(data))
+		case 2:
+			fields = append(fields, data)
+		case 3:
+			nested = append(nested, data)
+		case 4:
+			enums = append(enums, data)
+		case 7:
+			// MessageOptions.map_entry
+			return walkFields(data, func(num int32, wireType int, val uint64, data []byte) error {
+				if num == 7 && wireType == wireVarint {
+					msg.MapEntry = val != 0
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, data := range fields {
+		field := &Field{}
+		err = walkFields(data, func(num int32, wireType int, val uint64, data []byte) error {
+			switch num {
+			case 1:
+				field.Name = string(data)
+			case 3:
+				field.Number = int32(val)
+			case 4:
+				field.Repeated = val == 3
+			case 5:
+				field.Type = int(val)
+			case 6:
+				field.TypeName = string(data)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(field.TypeName, ".") {
+			field.TypeName = field.TypeName[1:]
+		} else if len(field.TypeName) > 0 {
+			unresolved[field] = msg
+		}
+		msg.Fields = append(msg.Fields, field)
+	}
+	schema.addMessage(msg)
+
+	for _, data := range nested {
+		if err = parseMessageDescriptor(data, msg.Name, schema, unresolved); err != nil {
+			return err
+		}
+	}
+	for _, data := range enums {
+		if err = parseEnumDescriptor(data, msg.Name, schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseEnumDescriptor(buf []byte, scope string, schema *Schema) error {
+	enum := &Enum{Values: map[int32]string{}}
+	err := walkFields(buf, func(num int32, wireType int, val uint64, data []byte) error {
+		switch num {
+		case 1:
+			enum.Name = joinName(scope, string(data))
+		case 2:
+			var name string
+			var number int32
+			if err := walkFields(data, func(num int32, wireType int, val uint64, data []byte) error {
+				switch num {
+				case 1:
+					name = string(data)
+				case 2:
+					number = int32(val)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			if _, exists := enum.Values[number]; !exists {
+				enum.Values[number] = name
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	schema.enums[enum.Name] = enum
+	return nil
+}
@@ -0,0 +1,349 @@
+package protoutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenizer of proto file, comments are skipped
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	peek *string
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			if idx := strings.IndexByte(l.src[l.pos:], '\n'); idx >= 0 {
+				l.pos += idx
+			} else {
+				l.pos = len(l.src)
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				l.pos = len(l.src)
+			} else {
+				l.line += strings.Count(l.src[l.pos:l.pos+2+end], "\n")
+				l.pos += end + 4
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next token, empty at the end of file
+func (l *lexer) next() string {
+	if l.peek != nil {
+		tok := *l.peek
+		l.peek = nil
+		return tok
+	}
+	l.skipSpace()
+	if l.pos >= len(l.src) {
+		return ""
+	}
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case c == '"' || c == '\'':
+		// string literal, keep quotes
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != c {
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		l.pos = min(l.pos+1, len(l.src))
+	case c == '_' || c == '.' || c == '-' || c == '+' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)):
+		l.pos++
+		for l.pos < len(l.src) {
+			c = l.src[l.pos]
+			if c == '_' || c == '.' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) {
+				l.pos++
+			} else {
+				break
+			}
+		}
+	default:
+		l.pos++
+	}
+	return l.src[start:l.pos]
+}
+
+func (l *lexer) lookahead() string {
+	if l.peek == nil {
+		tok := l.next()
+		l.peek = &tok
+	}
+	return *l.peek
+}
+
+func (l *lexer) expect(tok string) error {
+	if t := l.next(); t != tok {
+		return l.errorf("expect \"%s\" but got \"%s\"", tok, t)
+	}
+	return nil
+}
+
+func (l *lexer) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", l.line+1, fmt.Sprintf(format, args...))
+}
+
+// skip to the end of statement, including nested blocks
+func (l *lexer) skipStatement() error {
+	depth := 0
+	for {
+		tok := l.next()
+		switch tok {
+		case "":
+			return l.errorf("unexpected end of file")
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth <= 0 {
+				if l.lookahead() == ";" {
+					l.next()
+				}
+				return nil
+			}
+		case ";":
+			if depth <= 0 {
+				return nil
+			}
+		}
+	}
+}
+
+type protoParser struct {
+	lex        *lexer
+	schema     *Schema
+	unresolved map[*Field]*Message
+	pkg        string
+}
+
+// parse content of proto file into schema, return the imported files
+func parseProto(content string, schema *Schema, unresolved map[*Field]*Message) ([]string, error) {
+	p := &protoParser{
+		lex:        &lexer{src: content},
+		schema:     schema,
+		unresolved: unresolved,
+	}
+	var imports []string
+	for {
+		tok := p.lex.next()
+		var err error
+		switch tok {
+		case "":
+			return imports, nil
+		case ";":
+		case "package":
+			p.pkg = p.lex.next()
+			err = p.lex.expect(";")
+		case "import":
+			path := p.lex.next()
+			if path == "public" || path == "weak" {
+				path = p.lex.next()
+			}
+			imports = append(imports, strings.Trim(path, "\"'"))
+			err = p.lex.expect(";")
+		case "message":
+			err = p.parseMessage(p.pkg)
+		case "enum":
+			err = p.parseEnum(p.pkg)
+		case "syntax", "edition", "option", "service", "extend":
+			err = p.lex.skipStatement()
+		default:
+			err = p.lex.errorf("unexpected \"%s\"", tok)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func joinName(scope, name string) string {
+	if len(scope) > 0 {
+		return scope + "." + name
+	}
+	return name
+}
+
+func (p *protoParser) parseMessage(scope string) error {
+	msg := &Message{Name: joinName(scope, p.lex.next())}
+	if err := p.lex.expect("{"); err != nil {
+		return err
+	}
+	if err := p.parseMessageBody(msg); err != nil {
+		return err
+	}
+	p.schema.addMessage(msg)
+	return nil
+}
+
+// parse body of message or oneof until closing brace
+func (p *protoParser) parseMessageBody(msg *Message) error {
+	for {
+		tok := p.lex.next()
+		var err error
+		switch tok {
+		case "":
+			return p.lex.errorf("unexpected end of file")
+		case "}":
+			return nil
+		case ";":
+		case "message":
+			err = p.parseMessage(msg.Name)
+		case "enum":
+			err = p.parseEnum(msg.Name)
+		case "oneof":
+			p.lex.next()
+			// fields of oneof are treated as optional fields of message
+			if err = p.lex.expect("{"); err == nil {
+				err = p.parseMessageBody(msg)
+			}
+		case "option", "reserved", "extensions", "extend":
+			err = p.lex.skipStatement()
+		case "map":
+			err = p.parseMapField(msg)
+		default:
+			field := &Field{}
+			switch tok {
+			case "repeated":
+				field.Repeated = true
+				tok = p.lex.next()
+			case "optional", "required":
+				tok = p.lex.next()
+			}
+			if tok == "group" {
+				// deprecated group is skipped
+				err = p.lex.skipStatement()
+				break
+			}
+			err = p.parseField(msg, field, tok)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parse "type name = number [options];"
+func (p *protoParser) parseField(msg *Message, field *Field, typeName string) error {
+	field.Name = p.lex.next()
+	if err := p.lex.expect("="); err != nil {
+		return err
+	}
+	number, err := strconv.ParseInt(p.lex.next(), 0, 32)
+	if err != nil {
+		return p.lex.errorf("invalid number of field \"%s\"", field.Name)
+	}
+	field.Number = int32(number)
+	if t, ok := scalarTypes[typeName]; ok {
+		field.Type = t
+	} else {
+		field.TypeName = typeName
+		p.unresolved[field] = msg
+	}
+	if p.lex.lookahead() == "[" {
+		for tok := p.lex.next(); tok != "]"; tok = p.lex.next() {
+			if len(tok) <= 0 {
+				return p.lex.errorf("unexpected end of file")
+			}
+		}
+	}
+	msg.Fields = append(msg.Fields, field)
+	return p.lex.expect(";")
+}
+
+// parse "map<key, value> name = number;" as repeated field of synthetic entry message
+func (p *protoParser) parseMapField(msg *Message) error {
+	if err := p.lex.expect("<"); err != nil {
+		return err
+	}
+	keyType := p.lex.next()
+	if err := p.lex.expect(","); err != nil {
+		return err
+	}
+	valueType := p.lex.next()
+	if err := p.lex.expect(">"); err != nil {
+		return err
+	}
+
+	field := &Field{Repeated: true, Type: typeMessage}
+	if err := p.parseField(msg, field, ""); err != nil {
+		return err
+	}
+	delete(p.unresolved, field)
+	entry := &Message{
+		Name:     msg.Name + "." + strings.ToUpper(field.Name[:1]) + field.Name[1:] + "Entry",
+		MapEntry: true,
+	}
+	key := &Field{Name: "key", Number: 1, Type: scalarTypes[keyType]}
+	value := &Field{Name: "value", Number: 2}
+	if t, ok := scalarTypes[valueType]; ok {
+		value.Type = t
+	} else {
+		value.TypeName = valueType
+		// resolve in scope of the message containing map field
+		p.unresolved[value] = msg
+	}
+	entry.Fields = []*Field{key, value}
+	p.schema.addMessage(entry)
+	field.TypeName = entry.Name
+	return nil
+}
+
+func (p *protoParser) parseEnum(scope string) error {
+	enum := &Enum{
+		Name:   joinName(scope, p.lex.next()),
+		Values: map[int32]string{},
+	}
+	if err := p.lex.expect("{"); err != nil {
+		return err
+	}
+	for {
+		tok := p.lex.next()
+		switch tok {
+		case "":
+			return p.lex.errorf("unexpected end of file")
+		case "}":
+			p.schema.enums[enum.Name] = enum
+			return nil
+		case ";":
+		case "option", "reserved":
+			if err := p.lex.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			if err := p.lex.expect("="); err != nil {
+				return err
+			}
+			number, err := strconv.ParseInt(p.lex.next(), 0, 32)
+			if err != nil {
+				return p.lex.errorf("invalid value of enum \"%s\"", tok)
+			}
+			if _, exists := enum.Values[int32(number)]; !exists {
+				// the first one of aliases is used
+				enum.Values[int32(number)] = tok
+			}
+			if err = p.lex.skipStatement(); err != nil {
+				return err
+			}
+		}
+	}
+}
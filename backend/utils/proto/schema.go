@@ -0,0 +1,229 @@
+package protoutil
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// field types, same as FieldDescriptorProto.Type
+const (
+	typeDouble   = 1
+	typeFloat    = 2
+	typeInt64    = 3
+	typeUint64   = 4
+	typeInt32    = 5
+	typeFixed64  = 6
+	typeFixed32  = 7
+	typeBool     = 8
+	typeString   = 9
+	typeGroup    = 10
+	typeMessage  = 11
+	typeBytes    = 12
+	typeUint32   = 13
+	typeEnum     = 14
+	typeSfixed32 = 15
+	typeSfixed64 = 16
+	typeSint32   = 17
+	typeSint64   = 18
+)
+
+var scalarTypes = map[string]int{
+	"double":   typeDouble,
+	"float":    typeFloat,
+	"int64":    typeInt64,
+	"uint64":   typeUint64,
+	"int32":    typeInt32,
+	"fixed64":  typeFixed64,
+	"fixed32":  typeFixed32,
+	"bool":     typeBool,
+	"string":   typeString,
+	"bytes":    typeBytes,
+	"uint32":   typeUint32,
+	"sfixed32": typeSfixed32,
+	"sfixed64": typeSfixed64,
+	"sint32":   typeSint32,
+	"sint64":   typeSint64,
+}
+
+type Field struct {
+	Name     string
+	Number   int32
+	Type     int
+	TypeName string // full name of message or enum type, without leading dot
+	Repeated bool
+}
+
+type Message struct {
+	Name     string // full name including package
+	Fields   []*Field
+	MapEntry bool // synthetic entry type of map field, with key as field 1 and value as field 2
+	byNumber map[int32]*Field
+}
+
+type Enum struct {
+	Name   string
+	Values map[int32]string
+}
+
+// Schema message and enum types loaded from proto files or descriptor sets
+type Schema struct {
+	messages map[string]*Message
+	enums    map[string]*Enum
+}
+
+func newSchema() *Schema {
+	return &Schema{
+		messages: map[string]*Message{},
+		enums:    map[string]*Enum{},
+	}
+}
+
+// Messages get names of all message types, except map entries
+func (s *Schema) Messages() []string {
+	names := make([]string, 0, len(s.messages))
+	for name, msg := range s.messages {
+		if !msg.MapEntry {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Message find message type by full name
+func (s *Schema) Message(name string) (*Message, bool) {
+	msg, ok := s.messages[strings.TrimPrefix(name, ".")]
+	return msg, ok
+}
+
+func (s *Schema) addMessage(msg *Message) {
+	msg.byNumber = make(map[int32]*Field, len(msg.Fields))
+	for _, field := range msg.Fields {
+		msg.byNumber[field.Number] = field
+	}
+	s.messages[msg.Name] = msg
+}
+
+// resolve type name of fields referring to message or enum
+func (s *Schema) resolve(msg *Message, field *Field) {
+	name := field.TypeName
+	if strings.HasPrefix(name, ".") {
+		name = name[1:]
+	} else {
+		// search from the innermost scope to outer
+		scope := msg.Name
+		for {
+			candidate := name
+			if len(scope) > 0 {
+				candidate = scope + "." + name
+			}
+			if _, ok := s.messages[candidate]; ok {
+				name = candidate
+				break
+			}
+			if _, ok := s.enums[candidate]; ok {
+				name = candidate
+				break
+			}
+			if len(scope) <= 0 {
+				break
+			}
+			if idx := strings.LastIndexByte(scope, '.'); idx >= 0 {
+				scope = scope[:idx]
+			} else {
+				scope = ""
+			}
+		}
+	}
+	field.TypeName = name
+	if _, ok := s.enums[name]; ok {
+		field.Type = typeEnum
+	} else if field.Type != typeGroup {
+		// unresolved types like well-known types are decoded as unknown message
+		field.Type = typeMessage
+	}
+}
+
+func (s *Schema) resolveAll(unresolved map[*Field]*Message) {
+	for field, msg := range unresolved {
+		s.resolve(msg, field)
+	}
+}
+
+// LoadFiles load schema from .proto files or binary descriptor sets generated by "protoc --descriptor_set_out",
+// imported proto files are searched in directory of the importing file and all given files
+func LoadFiles(paths []string) (*Schema, error) {
+	schema := newSchema()
+	unresolved := map[*Field]*Message{}
+	loaded := map[string]bool{}
+	var dirs []string
+	for _, path := range paths {
+		dirs = append(dirs, filepath.Dir(path))
+	}
+
+	var loadProto func(path string) error
+	loadProto = func(path string) error {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		if loaded[path] {
+			return nil
+		}
+		loaded[path] = true
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		imports, err := parseProto(string(content), schema, unresolved)
+		if err != nil {
+			return &ParseError{File: filepath.Base(path), Err: err}
+		}
+		for _, imp := range imports {
+			// missing imports like google/protobuf/*.proto are ignored
+			searchDirs := append([]string{filepath.Dir(path)}, dirs...)
+			for _, dir := range searchDirs {
+				impPath := filepath.Join(dir, filepath.FromSlash(imp))
+				if _, statErr := os.Stat(impPath); statErr == nil {
+					if err = loadProto(impPath); err != nil {
+						return err
+					}
+					break
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, path := range paths {
+		if strings.EqualFold(filepath.Ext(path), ".proto") {
+			if err := loadProto(path); err != nil {
+				return nil, err
+			}
+		} else {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			if err = parseDescriptorSet(content, schema, unresolved); err != nil {
+				return nil, &ParseError{File: filepath.Base(path), Err: err}
+			}
+		}
+	}
+	schema.resolveAll(unresolved)
+	return schema, nil
+}
+
+type ParseError struct {
+	File string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return e.File + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -57,7 +57,6 @@ func (p *HttpProxy) Dial(network, addr string) (net.Conn, error) {
 
 	res, err := http.ReadResponse(bufio.NewReader(c), req)
 	if err != nil {
-		res.Body.Close()
 		c.Close()
 		return nil, err
 	}
@@ -68,6 +67,12 @@ func (p *HttpProxy) Dial(network, addr string) (net.Conn, error) {
 		return nil, fmt.Errorf("proxy connection error: StatusCode[%d]", res.StatusCode)
 	}
 
+	// the deadline only applies to proxy handshake
+	if err = c.SetDeadline(time.Time{}); err != nil {
+		c.Close()
+		return nil, err
+	}
+
 	return c, nil
 }
 
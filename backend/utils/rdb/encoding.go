@@ -0,0 +1,246 @@
+package rdbutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+var errMalformed = errors.New("malformed encoded data")
+
+// parse ziplist into entries, integers are converted to string
+func parseZiplist(buf []byte) ([]string, error) {
+	if len(buf) < 11 {
+		return nil, errMalformed
+	}
+	entries := make([]string, 0, binary.LittleEndian.Uint16(buf[8:10]))
+	pos := 10
+	for pos < len(buf) && buf[pos] != 0xff {
+		// skip previous entry length
+		if buf[pos] == 0xfe {
+			pos += 5
+		} else {
+			pos += 1
+		}
+		if pos >= len(buf) {
+			return nil, errMalformed
+		}
+
+		enc := buf[pos]
+		var val string
+		var err error
+		switch enc >> 6 {
+		case 0:
+			val, pos, err = readSlice(buf, pos+1, int(enc&0x3f))
+		case 1:
+			if pos+1 >= len(buf) {
+				return nil, errMalformed
+			}
+			val, pos, err = readSlice(buf, pos+2, int(enc&0x3f)<<8|int(buf[pos+1]))
+		case 2:
+			if pos+5 > len(buf) {
+				return nil, errMalformed
+			}
+			val, pos, err = readSlice(buf, pos+5, int(binary.BigEndian.Uint32(buf[pos+1:pos+5])))
+		default:
+			var num int64
+			pos++
+			switch enc {
+			case 0xc0:
+				num, pos, err = readInt(buf, pos, 2)
+			case 0xd0:
+				num, pos, err = readInt(buf, pos, 4)
+			case 0xe0:
+				num, pos, err = readInt(buf, pos, 8)
+			case 0xf0:
+				num, pos, err = readInt(buf, pos, 3)
+			case 0xfe:
+				num, pos, err = readInt(buf, pos, 1)
+			default:
+				// immediate 4 bit integer 0~12
+				num = int64(enc&0x0f) - 1
+				if num < 0 || num > 12 {
+					err = errMalformed
+				}
+			}
+			val = strconv.FormatInt(num, 10)
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, val)
+	}
+	return entries, nil
+}
+
+// parse listpack into entries, integers are converted to string
+func parseListpack(buf []byte) ([]string, error) {
+	if len(buf) < 7 {
+		return nil, errMalformed
+	}
+	entries := make([]string, 0, binary.LittleEndian.Uint16(buf[4:6]))
+	pos := 6
+	for pos < len(buf) && buf[pos] != 0xff {
+		start := pos
+		enc := buf[pos]
+		var val string
+		var num int64
+		var isInt bool
+		var err error
+		switch {
+		case enc&0x80 == 0:
+			// 7 bit unsigned integer
+			num, isInt, pos = int64(enc&0x7f), true, pos+1
+		case enc&0xc0 == 0x80:
+			val, pos, err = readSlice(buf, pos+1, int(enc&0x3f))
+		case enc&0xe0 == 0xc0:
+			// 13 bit signed integer
+			if pos+1 >= len(buf) {
+				return nil, errMalformed
+			}
+			num = int64(enc&0x1f)<<8 | int64(buf[pos+1])
+			if num >= 1<<12 {
+				num -= 1 << 13
+			}
+			isInt, pos = true, pos+2
+		case enc&0xf0 == 0xe0:
+			if pos+1 >= len(buf) {
+				return nil, errMalformed
+			}
+			val, pos, err = readSlice(buf, pos+2, int(enc&0x0f)<<8|int(buf[pos+1]))
+		case enc == 0xf0:
+			if pos+5 > len(buf) {
+				return nil, errMalformed
+			}
+			val, pos, err = readSlice(buf, pos+5, int(binary.LittleEndian.Uint32(buf[pos+1:pos+5])))
+		case enc == 0xf1:
+			num, pos, err = readInt(buf, pos+1, 2)
+			isInt = true
+		case enc == 0xf2:
+			num, pos, err = readInt(buf, pos+1, 3)
+			isInt = true
+		case enc == 0xf3:
+			num, pos, err = readInt(buf, pos+1, 4)
+			isInt = true
+		case enc == 0xf4:
+			num, pos, err = readInt(buf, pos+1, 8)
+			isInt = true
+		default:
+			err = errMalformed
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isInt {
+			val = strconv.FormatInt(num, 10)
+		}
+		entries = append(entries, val)
+
+		// skip backlen
+		size := pos - start
+		switch {
+		case size <= 127:
+			pos += 1
+		case size < 16383:
+			pos += 2
+		case size < 2097151:
+			pos += 3
+		case size < 268435455:
+			pos += 4
+		default:
+			pos += 5
+		}
+	}
+	return entries, nil
+}
+
+// parse intset into integers in string
+func parseIntset(buf []byte) ([]string, error) {
+	if len(buf) < 8 {
+		return nil, errMalformed
+	}
+	size := int(binary.LittleEndian.Uint32(buf[0:4]))
+	count := int(binary.LittleEndian.Uint32(buf[4:8]))
+	if size != 2 && size != 4 && size != 8 || len(buf) < 8+size*count {
+		return nil, errMalformed
+	}
+	entries := make([]string, 0, count)
+	pos := 8
+	for i := 0; i < count; i++ {
+		num, next, err := readInt(buf, pos, size)
+		if err != nil {
+			return nil, err
+		}
+		pos = next
+		entries = append(entries, strconv.FormatInt(num, 10))
+	}
+	return entries, nil
+}
+
+// parse zipmap into field and value pairs
+func parseZipmap(buf []byte) ([]string, error) {
+	if len(buf) < 2 {
+		return nil, errMalformed
+	}
+	entries := make([]string, 0)
+	pos := 1
+	readLen := func() (int, error) {
+		if pos >= len(buf) {
+			return 0, errMalformed
+		}
+		if buf[pos] < 254 {
+			pos++
+			return int(buf[pos-1]), nil
+		}
+		if pos+5 > len(buf) {
+			return 0, errMalformed
+		}
+		length := int(binary.LittleEndian.Uint32(buf[pos+1 : pos+5]))
+		pos += 5
+		return length, nil
+	}
+	for pos < len(buf) && buf[pos] != 0xff {
+		length, err := readLen()
+		if err != nil {
+			return nil, err
+		}
+		var field, value string
+		if field, pos, err = readSlice(buf, pos, length); err != nil {
+			return nil, err
+		}
+		if length, err = readLen(); err != nil {
+			return nil, err
+		}
+		if pos >= len(buf) {
+			return nil, errMalformed
+		}
+		free := int(buf[pos])
+		if value, pos, err = readSlice(buf, pos+1, length); err != nil {
+			return nil, err
+		}
+		pos += free
+		entries = append(entries, field, value)
+	}
+	return entries, nil
+}
+
+func readSlice(buf []byte, pos, length int) (string, int, error) {
+	if length < 0 || pos+length > len(buf) {
+		return "", pos, errMalformed
+	}
+	return string(buf[pos : pos+length]), pos + length, nil
+}
+
+// read little endian signed integer in size bytes
+func readInt(buf []byte, pos, size int) (int64, int, error) {
+	if pos+size > len(buf) {
+		return 0, pos, errMalformed
+	}
+	var num uint64
+	for i := size - 1; i >= 0; i-- {
+		num = num<<8 | uint64(buf[pos+i])
+	}
+	// sign extension
+	shift := 64 - size*8
+	return int64(num<<shift) >> shift, pos + size, nil
+}
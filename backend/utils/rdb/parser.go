@@ -0,0 +1,640 @@
+package rdbutil
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// value types
+const (
+	typeString            = 0
+	typeList              = 1
+	typeSet               = 2
+	typeZSet              = 3
+	typeHash              = 4
+	typeZSet2             = 5
+	typeModule2           = 7
+	typeHashZipmap        = 9
+	typeListZiplist       = 10
+	typeSetIntset         = 11
+	typeZSetZiplist       = 12
+	typeHashZiplist       = 13
+	typeListQuicklist     = 14
+	typeStreamListpacks   = 15
+	typeHashListpack      = 16
+	typeZSetListpack      = 17
+	typeListQuicklist2    = 18
+	typeStreamListpacks2  = 19
+	typeSetListpack       = 20
+	typeStreamListpacks3  = 21
+	typeHashMetadata      = 24
+	typeHashListpackEx    = 25
+	quicklistNodePlain    = 1
+	streamFlagDeleted     = 1
+	streamFlagSameFields  = 2
+	moduleOpcodeEOF       = 0
+	moduleOpcodeSInt      = 1
+	moduleOpcodeUInt      = 2
+	moduleOpcodeFloat     = 3
+	moduleOpcodeDouble    = 4
+	moduleOpcodeString    = 5
+	moduleNameCharset     = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	streamListpackVersion = 2 // version of stream type which has extra metadata
+)
+
+// opcodes
+const (
+	opSlotInfo     = 0xf4
+	opFunction2    = 0xf5
+	opModuleAux    = 0xf7
+	opIdle         = 0xf8
+	opFreq         = 0xf9
+	opAux          = 0xfa
+	opResizeDB     = 0xfb
+	opExpireTimeMs = 0xfc
+	opExpireTime   = 0xfd
+	opSelectDB     = 0xfe
+	opEOF          = 0xff
+)
+
+type ZSetMember struct {
+	Member string
+	Score  float64
+}
+
+type StreamEntry struct {
+	ID     string
+	Fields []string // field and value pairs
+}
+
+// Entry a key parsed from rdb file
+type Entry struct {
+	DB       int
+	Key      string
+	Type     string // "string", "list", "set", "zset", "hash", "stream", or module name for module types
+	ExpireAt int64  // unix milliseconds, 0 for persistent key
+	// string for string, []string for list and set, field and value pairs []string for hash,
+	// []ZSetMember for zset, []StreamEntry for stream, and nil for module types
+	Value any
+}
+
+// ParseFile parse rdb file and call fn for each key in order,
+// auxiliary fields are passed to aux if not nil
+func ParseFile(path string, fn func(entry *Entry) error, aux func(key, val string)) (err error) {
+	defer func() {
+		// malformed file should never crash the app
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parse rdb file fail: %v", r)
+		}
+	}()
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	r := &reader{r: bufio.NewReaderSize(file, 64*1024), remain: stat.Size()}
+	magic, err := r.readBytes(9)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(string(magic), "REDIS") {
+		return errors.New("not a valid rdb file")
+	}
+	version, err := strconv.Atoi(string(magic[5:]))
+	if err != nil {
+		return errors.New("not a valid rdb file")
+	}
+
+	var db int
+	var expireAt int64
+	for {
+		op, err := r.readByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				// missing EOF opcode for old version
+				return nil
+			}
+			return err
+		}
+
+		switch op {
+		case opEOF:
+			return nil
+		case opSelectDB:
+			if db, err = r.readLen(); err != nil {
+				return err
+			}
+			continue
+		case opResizeDB:
+			if _, err = r.readLen(); err == nil {
+				_, err = r.readLen()
+			}
+		case opAux:
+			var key, val string
+			if key, err = r.readString(); err == nil {
+				if val, err = r.readString(); err == nil && aux != nil {
+					aux(key, val)
+				}
+			}
+		case opExpireTimeMs:
+			var ms uint64
+			ms, err = r.readUint64()
+			expireAt = int64(ms)
+		case opExpireTime:
+			var sec uint32
+			sec, err = r.readUint32()
+			expireAt = int64(sec) * 1000
+		case opIdle:
+			_, err = r.readLen()
+		case opFreq:
+			_, err = r.readByte()
+		case opSlotInfo:
+			for i := 0; i < 3 && err == nil; i++ {
+				_, err = r.readLen()
+			}
+		case opFunction2:
+			_, err = r.readString()
+		case opModuleAux:
+			if _, _, err = r.readLength(); err == nil {
+				// when opcode and when
+				if _, _, err = r.readLength(); err == nil {
+					if _, _, err = r.readLength(); err == nil {
+						err = r.skipModuleValue()
+					}
+				}
+			}
+		default:
+			// key and value
+			entry := &Entry{
+				DB:       db,
+				ExpireAt: expireAt,
+			}
+			expireAt = 0
+			if entry.Key, err = r.readString(); err != nil {
+				return err
+			}
+			if err = r.readValue(op, version, entry); err != nil {
+				return fmt.Errorf("parse key \"%s\" fail: %w", entry.Key, err)
+			}
+			if err = fn(entry); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (r *reader) readValue(valType byte, version int, entry *Entry) error {
+	var err error
+	switch valType {
+	case typeString:
+		entry.Type = "string"
+		entry.Value, err = r.readString()
+
+	case typeList, typeSet:
+		entry.Type = "list"
+		if valType == typeSet {
+			entry.Type = "set"
+		}
+		entry.Value, err = r.readStrings(1)
+
+	case typeHash:
+		entry.Type = "hash"
+		entry.Value, err = r.readStrings(2)
+
+	case typeZSet, typeZSet2:
+		entry.Type = "zset"
+		var length int
+		if length, err = r.readCount(2); err != nil {
+			return err
+		}
+		members := make([]ZSetMember, 0, prealloc(length))
+		for i := 0; i < length; i++ {
+			var m ZSetMember
+			if m.Member, err = r.readString(); err != nil {
+				return err
+			}
+			if valType == typeZSet2 {
+				m.Score, err = r.readDouble()
+			} else {
+				m.Score, err = r.readFloat()
+			}
+			if err != nil {
+				return err
+			}
+			members = append(members, m)
+		}
+		entry.Value = members
+
+	case typeHashZipmap:
+		entry.Type = "hash"
+		entry.Value, err = r.readEncoded(parseZipmap)
+
+	case typeListZiplist:
+		entry.Type = "list"
+		entry.Value, err = r.readEncoded(parseZiplist)
+
+	case typeSetIntset:
+		entry.Type = "set"
+		entry.Value, err = r.readEncoded(parseIntset)
+
+	case typeSetListpack:
+		entry.Type = "set"
+		entry.Value, err = r.readEncoded(parseListpack)
+
+	case typeHashZiplist, typeHashListpack:
+		entry.Type = "hash"
+		if valType == typeHashZiplist {
+			entry.Value, err = r.readEncoded(parseZiplist)
+		} else {
+			entry.Value, err = r.readEncoded(parseListpack)
+		}
+
+	case typeZSetZiplist, typeZSetListpack:
+		entry.Type = "zset"
+		var vals []string
+		if valType == typeZSetZiplist {
+			vals, err = r.readEncoded(parseZiplist)
+		} else {
+			vals, err = r.readEncoded(parseListpack)
+		}
+		if err != nil {
+			return err
+		}
+		members := make([]ZSetMember, 0, len(vals)/2)
+		for i := 0; i+1 < len(vals); i += 2 {
+			score, _ := strconv.ParseFloat(vals[i+1], 64)
+			members = append(members, ZSetMember{Member: vals[i], Score: score})
+		}
+		entry.Value = members
+
+	case typeListQuicklist, typeListQuicklist2:
+		entry.Type = "list"
+		var nodes int
+		if nodes, err = r.readCount(1); err != nil {
+			return err
+		}
+		vals := make([]string, 0)
+		for i := 0; i < nodes; i++ {
+			container := 0
+			if valType == typeListQuicklist2 {
+				if container, err = r.readLen(); err != nil {
+					return err
+				}
+			}
+			var node []string
+			if container == quicklistNodePlain {
+				var val string
+				val, err = r.readString()
+				node = []string{val}
+			} else if valType == typeListQuicklist2 {
+				node, err = r.readEncoded(parseListpack)
+			} else {
+				node, err = r.readEncoded(parseZiplist)
+			}
+			if err != nil {
+				return err
+			}
+			vals = append(vals, node...)
+		}
+		entry.Value = vals
+
+	case typeHashMetadata:
+		// hash with field expiration, expiration of fields are ignored
+		entry.Type = "hash"
+		if _, err = r.readUint64(); err != nil {
+			return err
+		}
+		var length int
+		if length, err = r.readCount(3); err != nil {
+			return err
+		}
+		vals := make([]string, 0, prealloc(length)*2)
+		for i := 0; i < length; i++ {
+			var field, val string
+			if _, err = r.readLen(); err != nil {
+				return err
+			}
+			if field, err = r.readString(); err != nil {
+				return err
+			}
+			if val, err = r.readString(); err != nil {
+				return err
+			}
+			vals = append(vals, field, val)
+		}
+		entry.Value = vals
+
+	case typeHashListpackEx:
+		entry.Type = "hash"
+		if _, err = r.readUint64(); err != nil {
+			return err
+		}
+		var triples []string
+		if triples, err = r.readEncoded(parseListpack); err != nil {
+			return err
+		}
+		vals := make([]string, 0, len(triples)/3*2)
+		for i := 0; i+2 < len(triples); i += 3 {
+			vals = append(vals, triples[i], triples[i+1])
+		}
+		entry.Value = vals
+
+	case typeStreamListpacks, typeStreamListpacks2, typeStreamListpacks3:
+		entry.Type = "stream"
+		version := 1
+		if valType == typeStreamListpacks2 {
+			version = 2
+		} else if valType == typeStreamListpacks3 {
+			version = 3
+		}
+		entry.Value, err = r.readStream(version)
+
+	case typeModule2:
+		var moduleID uint64
+		if moduleID, _, err = r.readLength(); err != nil {
+			return err
+		}
+		entry.Type = moduleName(moduleID)
+		err = r.skipModuleValue()
+
+	default:
+		err = fmt.Errorf("unsupported value type %d of rdb version %d", valType, version)
+	}
+	return err
+}
+
+// read length and then length*step strings
+func (r *reader) readStrings(step int) ([]string, error) {
+	length, err := r.readCount(step)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]string, 0, prealloc(length)*step)
+	for i := 0; i < length*step; i++ {
+		val, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
+
+// read string and decode it by parse function
+func (r *reader) readEncoded(parse func([]byte) ([]string, error)) ([]string, error) {
+	buf, err := r.readString()
+	if err != nil {
+		return nil, err
+	}
+	return parse([]byte(buf))
+}
+
+func (r *reader) readStream(version int) ([]StreamEntry, error) {
+	nodes, err := r.readCount(2)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]StreamEntry, 0)
+	for i := 0; i < nodes; i++ {
+		var nodeKey string
+		if nodeKey, err = r.readString(); err != nil {
+			return nil, err
+		}
+		if len(nodeKey) != 16 {
+			return nil, errMalformed
+		}
+		var lp []string
+		if lp, err = r.readEncoded(parseListpack); err != nil {
+			return nil, err
+		}
+		masterMs := binary.BigEndian.Uint64([]byte(nodeKey[:8]))
+		masterSeq := binary.BigEndian.Uint64([]byte(nodeKey[8:]))
+		var nodeEntries []StreamEntry
+		if nodeEntries, err = parseStreamListpack(lp, masterMs, masterSeq); err != nil {
+			return nil, err
+		}
+		entries = append(entries, nodeEntries...)
+	}
+
+	// length and last id
+	metaCount := 3
+	if version >= streamListpackVersion {
+		// first id, max deleted id and entries added
+		metaCount += 5
+	}
+	for i := 0; i < metaCount; i++ {
+		if _, err = r.readLen(); err != nil {
+			return nil, err
+		}
+	}
+
+	// skip consumer groups
+	var groups int
+	if groups, err = r.readCount(1); err != nil {
+		return nil, err
+	}
+	for i := 0; i < groups; i++ {
+		if _, err = r.readString(); err != nil {
+			return nil, err
+		}
+		// last id and entries read
+		count := 2
+		if version >= streamListpackVersion {
+			count += 1
+		}
+		for j := 0; j < count; j++ {
+			if _, err = r.readLen(); err != nil {
+				return nil, err
+			}
+		}
+		var pending int
+		if pending, err = r.readCount(16 + 8 + 1); err != nil {
+			return nil, err
+		}
+		for j := 0; j < pending; j++ {
+			// raw id and delivery time
+			if _, err = r.readBytes(16 + 8); err != nil {
+				return nil, err
+			}
+			// delivery count
+			if _, err = r.readLen(); err != nil {
+				return nil, err
+			}
+		}
+		var consumers int
+		if consumers, err = r.readCount(1); err != nil {
+			return nil, err
+		}
+		for j := 0; j < consumers; j++ {
+			if _, err = r.readString(); err != nil {
+				return nil, err
+			}
+			// seen time, and active time since version 3
+			timeSize := 8
+			if version >= 3 {
+				timeSize += 8
+			}
+			if _, err = r.readBytes(timeSize); err != nil {
+				return nil, err
+			}
+			if pending, err = r.readCount(16); err != nil {
+				return nil, err
+			}
+			if _, err = r.readBytes(16 * pending); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return entries, nil
+}
+
+// parse entries in one listpack node of stream, deleted entries are skipped
+func parseStreamListpack(lp []string, masterMs, masterSeq uint64) ([]StreamEntry, error) {
+	pos := 0
+	next := func() (string, error) {
+		if pos >= len(lp) {
+			return "", errMalformed
+		}
+		pos++
+		return lp[pos-1], nil
+	}
+	nextInt := func() (int64, error) {
+		val, err := next()
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseInt(val, 10, 64)
+	}
+
+	// master entry: count, deleted count, master fields and terminator
+	var count, deleted, masterCount int64
+	var err error
+	if count, err = nextInt(); err != nil {
+		return nil, err
+	}
+	if deleted, err = nextInt(); err != nil {
+		return nil, err
+	}
+	if masterCount, err = nextInt(); err != nil {
+		return nil, err
+	}
+	// every entry and master field takes at least one element in listpack
+	limit := int64(len(lp))
+	if count < 0 || count > limit || deleted < 0 || deleted > limit || masterCount < 0 || masterCount > limit {
+		return nil, errMalformed
+	}
+	masterFields := make([]string, masterCount)
+	for i := range masterFields {
+		if masterFields[i], err = next(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err = next(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]StreamEntry, 0, count)
+	for i := int64(0); i < count+deleted; i++ {
+		var flags, msDiff, seqDiff int64
+		if flags, err = nextInt(); err != nil {
+			return nil, err
+		}
+		if msDiff, err = nextInt(); err != nil {
+			return nil, err
+		}
+		if seqDiff, err = nextInt(); err != nil {
+			return nil, err
+		}
+		var fields []string
+		if flags&streamFlagSameFields != 0 {
+			fields = make([]string, 0, masterCount*2)
+			for _, field := range masterFields {
+				var val string
+				if val, err = next(); err != nil {
+					return nil, err
+				}
+				fields = append(fields, field, val)
+			}
+		} else {
+			var fieldCount int64
+			if fieldCount, err = nextInt(); err != nil {
+				return nil, err
+			}
+			if fieldCount < 0 || fieldCount*2 > int64(len(lp)-pos) {
+				return nil, errMalformed
+			}
+			fields = make([]string, 0, fieldCount*2)
+			for j := int64(0); j < fieldCount*2; j++ {
+				var val string
+				if val, err = next(); err != nil {
+					return nil, err
+				}
+				fields = append(fields, val)
+			}
+		}
+		// lp-count
+		if _, err = next(); err != nil {
+			return nil, err
+		}
+		if flags&streamFlagDeleted != 0 {
+			continue
+		}
+		entries = append(entries, StreamEntry{
+			ID:     fmt.Sprintf("%d-%d", masterMs+uint64(msDiff), masterSeq+uint64(seqDiff)),
+			Fields: fields,
+		})
+	}
+	return entries, nil
+}
+
+// skip serialized value of module type until EOF opcode
+func (r *reader) skipModuleValue() error {
+	for {
+		opcode, _, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case moduleOpcodeEOF:
+			return nil
+		case moduleOpcodeSInt, moduleOpcodeUInt:
+			_, _, err = r.readLength()
+		case moduleOpcodeFloat:
+			_, err = r.readBytes(4)
+		case moduleOpcodeDouble:
+			_, err = r.readBytes(8)
+		case moduleOpcodeString:
+			_, err = r.readString()
+		default:
+			err = fmt.Errorf("unknown module opcode %d", opcode)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// decode module type name from module id, 9 characters are encoded in the high 54 bits
+func moduleName(moduleID uint64) string {
+	name := make([]byte, 9)
+	id := moduleID >> 10
+	for i := 8; i >= 0; i-- {
+		name[i] = moduleNameCharset[id&63]
+		id >>= 6
+	}
+	return string(name)
+}
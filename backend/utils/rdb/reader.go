@@ -0,0 +1,254 @@
+package rdbutil
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+const (
+	lenEnc6Bit  = 0
+	lenEnc14Bit = 1
+	lenEnc32Bit = 0x80
+	lenEnc64Bit = 0x81
+	lenEncSpec  = 3
+
+	encInt8  = 0
+	encInt16 = 1
+	encInt32 = 2
+	encLZF   = 3
+
+	// max capacity preallocated from lengths read in file
+	maxPrealloc = 1024
+)
+
+type reader struct {
+	r      *bufio.Reader
+	remain int64 // bytes not read yet in file
+}
+
+func (r *reader) readByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil {
+		r.remain--
+	}
+	return b, err
+}
+
+func (r *reader) readBytes(n int) ([]byte, error) {
+	if n < 0 || int64(n) > r.remain {
+		return nil, errMalformed
+	}
+	r.remain -= int64(n)
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (r *reader) readUint32() (uint32, error) {
+	buf, err := r.readBytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf), nil
+}
+
+func (r *reader) readUint64() (uint64, error) {
+	buf, err := r.readBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+// read length encoding, encoded is true if the following is a special encoded string
+func (r *reader) readLength() (length uint64, encoded bool, err error) {
+	var b byte
+	if b, err = r.readByte(); err != nil {
+		return
+	}
+	switch b >> 6 {
+	case lenEnc6Bit:
+		length = uint64(b & 0x3f)
+	case lenEnc14Bit:
+		var next byte
+		if next, err = r.readByte(); err != nil {
+			return
+		}
+		length = uint64(b&0x3f)<<8 | uint64(next)
+	case lenEncSpec:
+		length, encoded = uint64(b&0x3f), true
+	default:
+		var buf []byte
+		switch b {
+		case lenEnc32Bit:
+			if buf, err = r.readBytes(4); err == nil {
+				length = uint64(binary.BigEndian.Uint32(buf))
+			}
+		case lenEnc64Bit:
+			if buf, err = r.readBytes(8); err == nil {
+				length = binary.BigEndian.Uint64(buf)
+			}
+		default:
+			err = fmt.Errorf("invalid length encoding 0x%x", b)
+		}
+	}
+	return
+}
+
+func (r *reader) readLen() (int, error) {
+	length, encoded, err := r.readLength()
+	if err != nil {
+		return 0, err
+	}
+	if encoded {
+		return 0, errors.New("unexpected encoded length")
+	}
+	if length > math.MaxInt {
+		return 0, errMalformed
+	}
+	return int(length), nil
+}
+
+// read count of elements, each element takes at least size bytes in the rest of file
+func (r *reader) readCount(size int) (int, error) {
+	count, err := r.readLen()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(count) > uint64(r.remain)/uint64(size) {
+		return 0, errMalformed
+	}
+	return count, nil
+}
+
+// capacity hint for slices of count elements, never trust lengths read from file
+func prealloc(count int) int {
+	return max(min(count, maxPrealloc), 0)
+}
+
+// read string encoding, including integer and LZF compressed string
+func (r *reader) readString() (string, error) {
+	length, encoded, err := r.readLength()
+	if err != nil {
+		return "", err
+	}
+	if !encoded {
+		buf, err := r.readBytes(int(length))
+		return string(buf), err
+	}
+
+	switch length {
+	case encInt8:
+		b, err := r.readByte()
+		return strconv.Itoa(int(int8(b))), err
+	case encInt16:
+		buf, err := r.readBytes(2)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(int16(binary.LittleEndian.Uint16(buf)))), nil
+	case encInt32:
+		buf, err := r.readBytes(4)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(int32(binary.LittleEndian.Uint32(buf)))), nil
+	case encLZF:
+		compressedLen, err := r.readLen()
+		if err != nil {
+			return "", err
+		}
+		rawLen, err := r.readLen()
+		if err != nil {
+			return "", err
+		}
+		compressed, err := r.readBytes(compressedLen)
+		if err != nil {
+			return "", err
+		}
+		// each back reference of at most 3 bytes expands to 264 bytes at most
+		if int64(rawLen) > int64(compressedLen)*88+1 {
+			return "", errMalformed
+		}
+		raw, err := lzfDecompress(compressed, rawLen)
+		return string(raw), err
+	}
+	return "", fmt.Errorf("invalid string encoding %d", length)
+}
+
+// read score of zset stored as string, used by old zset type
+func (r *reader) readFloat() (float64, error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, err
+	}
+	switch b {
+	case 253:
+		return math.NaN(), nil
+	case 254:
+		return math.Inf(1), nil
+	case 255:
+		return math.Inf(-1), nil
+	}
+	buf, err := r.readBytes(int(b))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(buf), 64)
+}
+
+// read score of zset stored as binary double
+func (r *reader) readDouble() (float64, error) {
+	bits, err := r.readUint64()
+	return math.Float64frombits(bits), err
+}
+
+func lzfDecompress(in []byte, outLen int) ([]byte, error) {
+	out := make([]byte, 0, outLen)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+		if ctrl < 32 {
+			// literal run
+			ctrl++
+			if i+ctrl > len(in) {
+				return nil, errors.New("invalid lzf data")
+			}
+			out = append(out, in[i:i+ctrl]...)
+			i += ctrl
+			continue
+		}
+
+		// back reference
+		length := ctrl >> 5
+		if length == 7 {
+			if i >= len(in) {
+				return nil, errors.New("invalid lzf data")
+			}
+			length += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, errors.New("invalid lzf data")
+		}
+		ref := len(out) - ((ctrl & 0x1f) << 8) - int(in[i]) - 1
+		i++
+		if ref < 0 {
+			return nil, errors.New("invalid lzf data")
+		}
+		for j := 0; j < length+2; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != outLen {
+		return nil, errors.New("invalid lzf data length")
+	}
+	return out, nil
+}
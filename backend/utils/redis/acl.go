@@ -0,0 +1,125 @@
+package redis
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"tinyrdm/backend/types"
+	"tinyrdm/backend/utils/coll"
+)
+
+// BuildACLRules build rules of "ACL SETUSER" from user info, all previous rules of user will be reset
+// @param categories all command categories supported by server, get by "ACL CAT"
+func BuildACLRules(user types.ACLUser, categories []string) ([]string, error) {
+	if len(user.Name) <= 0 || strings.ContainsAny(user.Name, " \t\r\n") {
+		return nil, fmt.Errorf("invalid user name \"%s\"", user.Name)
+	}
+
+	rules := []string{"reset"}
+	if user.Enabled {
+		rules = append(rules, "on")
+	} else {
+		rules = append(rules, "off")
+	}
+
+	if user.NoPass {
+		rules = append(rules, "nopass")
+	} else {
+		for _, pwd := range user.Passwords {
+			if len(pwd) <= 0 {
+				return nil, fmt.Errorf("password can not be empty")
+			}
+			rules = append(rules, ">"+pwd)
+		}
+		for _, hash := range user.Hashes {
+			if len(hash) != 64 || strings.Trim(strings.ToLower(hash), "0123456789abcdef") != "" {
+				return nil, fmt.Errorf("invalid password hash \"%s\"", hash)
+			}
+			rules = append(rules, "#"+strings.ToLower(hash))
+		}
+	}
+
+	categorySet := coll.NewSet(categories...)
+	categorySet.Add("all")
+	for _, cmd := range user.Commands {
+		if err := validateACLCommand(cmd, categorySet); err != nil {
+			return nil, err
+		}
+		rules = append(rules, cmd)
+	}
+
+	for _, key := range user.Keys {
+		if err := validateACLKey(key); err != nil {
+			return nil, err
+		}
+		rules = append(rules, key)
+	}
+
+	for _, channel := range user.Channels {
+		if err := validateACLChannel(channel); err != nil {
+			return nil, err
+		}
+		rules = append(rules, channel)
+	}
+	return rules, nil
+}
+
+// command rule like "+get", "-config|set", "+@read", "allcommands" or "nocommands"
+func validateACLCommand(rule string, categories coll.Set[string]) error {
+	switch rule {
+	case "allcommands", "nocommands":
+		return nil
+	}
+	if len(rule) < 2 || (rule[0] != '+' && rule[0] != '-') || strings.ContainsAny(rule, " \t\r\n") {
+		return fmt.Errorf("invalid command rule \"%s\"", rule)
+	}
+	if rule[1] == '@' {
+		if category := strings.ToLower(rule[2:]); !categories.Contains(category) {
+			return fmt.Errorf("unknown command category \"%s\"", category)
+		}
+	}
+	return nil
+}
+
+// key rule like "~pattern", "%R~pattern", "%W~pattern", "%RW~pattern", "allkeys" or "resetkeys"
+func validateACLKey(rule string) error {
+	switch rule {
+	case "allkeys", "resetkeys":
+		return nil
+	}
+	var pattern string
+	if strings.HasPrefix(rule, "~") {
+		pattern = rule[1:]
+	} else if strings.HasPrefix(rule, "%") {
+		perm, p, found := strings.Cut(rule[1:], "~")
+		if !found || len(perm) <= 0 || strings.Trim(strings.ToUpper(perm), "RW") != "" {
+			return fmt.Errorf("invalid key permission \"%s\"", rule)
+		}
+		pattern = p
+	} else {
+		return fmt.Errorf("invalid key rule \"%s\"", rule)
+	}
+	return validatePattern(rule, pattern)
+}
+
+// channel rule like "&pattern", "allchannels" or "resetchannels"
+func validateACLChannel(rule string) error {
+	switch rule {
+	case "allchannels", "resetchannels":
+		return nil
+	}
+	if !strings.HasPrefix(rule, "&") {
+		return fmt.Errorf("invalid channel rule \"%s\"", rule)
+	}
+	return validatePattern(rule, rule[1:])
+}
+
+func validatePattern(rule, pattern string) error {
+	if len(pattern) <= 0 || strings.ContainsAny(pattern, " \t\r\n") {
+		return fmt.Errorf("invalid pattern in rule \"%s\"", rule)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern in rule \"%s\": %s", rule, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+type auditCallback func(db int, args []string, err error)
+
+// AuditHook report all executed write commands with current database index
+type AuditHook struct {
+	db     atomic.Int64
+	record auditCallback
+}
+
+func NewAuditHook(db int, record auditCallback) *AuditHook {
+	h := &AuditHook{
+		record: record,
+	}
+	h.db.Store(int64(db))
+	return h
+}
+
+// mask secrets in arguments, like passwords of acl user and server
+func maskArgs(args []any) []string {
+	ret := make([]string, len(args))
+	for i, arg := range args {
+		ret[i] = fmt.Sprint(arg)
+	}
+	if len(ret) < 2 {
+		return ret
+	}
+	switch strings.ToLower(ret[0]) {
+	case "acl":
+		if strings.EqualFold(ret[1], "setuser") {
+			for i := 3; i < len(ret); i++ {
+				if strings.HasPrefix(ret[i], ">") || strings.HasPrefix(ret[i], "<") {
+					ret[i] = ret[i][:1] + "***"
+				}
+			}
+		}
+	case "config":
+		if strings.EqualFold(ret[1], "set") {
+			for i := 2; i+1 < len(ret); i += 2 {
+				switch strings.ToLower(ret[i]) {
+				case "requirepass", "masterauth":
+					ret[i+1] = "***"
+				}
+			}
+		}
+	}
+	return ret
+}
+
+func (h *AuditHook) handle(cmd redis.Cmder) {
+	args := cmd.Args()
+	err := cmd.Err()
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+	if err == nil && len(args) > 1 && strings.EqualFold(cmd.Name(), "select") {
+		if db, convErr := strconv.Atoi(fmt.Sprint(args[1])); convErr == nil {
+			h.db.Store(int64(db))
+		}
+		return
+	}
+	if h.record != nil && IsWriteCommand(args) {
+		h.record(int(h.db.Load()), maskArgs(args), err)
+	}
+}
+
+func (h *AuditHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *AuditHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		h.handle(cmd)
+		return err
+	}
+}
+
+func (h *AuditHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		for _, cmd := range cmds {
+			h.handle(cmd)
+		}
+		return err
+	}
+}
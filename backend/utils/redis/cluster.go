@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"strconv"
+	"strings"
+	"tinyrdm/backend/types"
+)
+
+const ClusterSlotCount = 16384
+
+// ParseClusterNodes parse response of "cluster nodes", each line in format below
+// <id> <ip:port@cport[,hostname]> <flags> <master> <ping-sent> <pong-recv> <config-epoch> <link-state> <slot> ...
+func ParseClusterNodes(content string) []types.ClusterNode {
+	var nodes []types.ClusterNode
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+		addr, _, _ := strings.Cut(fields[1], "@")
+		node := types.ClusterNode{
+			ID:        fields[0],
+			Addr:      addr,
+			Flags:     fields[2],
+			LinkState: fields[7],
+			Slots:     []types.ClusterSlotRange{},
+		}
+		if strings.Contains(node.Flags, "master") {
+			node.Role = "master"
+		} else {
+			node.Role = "replica"
+			node.MasterID = fields[3]
+		}
+		for _, slot := range fields[8:] {
+			if strings.HasPrefix(slot, "[") {
+				// importing or migrating slot
+				continue
+			}
+			startStr, endStr, found := strings.Cut(slot, "-")
+			start, err := strconv.Atoi(startStr)
+			if err != nil {
+				continue
+			}
+			end := start
+			if found {
+				if end, err = strconv.Atoi(endStr); err != nil {
+					continue
+				}
+			}
+			node.Slots = append(node.Slots, types.ClusterSlotRange{Start: start, End: end})
+			node.SlotCount += end - start + 1
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// PlanRebalance calculate slot moves to distribute slots evenly among masters
+func PlanRebalance(nodes []types.ClusterNode) []types.ClusterSlotMove {
+	var masters []types.ClusterNode
+	for _, node := range nodes {
+		if node.Role == "master" && !strings.Contains(node.Flags, "fail") {
+			masters = append(masters, node)
+		}
+	}
+	if len(masters) <= 1 {
+		return nil
+	}
+
+	// expected slot count of each master, the remainder is assigned to the first masters
+	expected := make([]int, len(masters))
+	for i := range masters {
+		expected[i] = ClusterSlotCount / len(masters)
+		if i < ClusterSlotCount%len(masters) {
+			expected[i] += 1
+		}
+	}
+
+	// collect surplus slots from masters which hold more than expected
+	type surplus struct {
+		sourceID string
+		slots    []int
+	}
+	var surpluses []surplus
+	for i, master := range masters {
+		over := master.SlotCount - expected[i]
+		if over <= 0 {
+			continue
+		}
+		var slots []int
+		for j := len(master.Slots) - 1; j >= 0 && len(slots) < over; j-- {
+			for slot := master.Slots[j].End; slot >= master.Slots[j].Start && len(slots) < over; slot-- {
+				slots = append(slots, slot)
+			}
+		}
+		surpluses = append(surpluses, surplus{sourceID: master.ID, slots: slots})
+	}
+
+	// assign surplus slots to masters which hold less than expected
+	var moves []types.ClusterSlotMove
+	for i, master := range masters {
+		lack := expected[i] - master.SlotCount
+		for lack > 0 && len(surpluses) > 0 {
+			src := &surpluses[0]
+			n := min(lack, len(src.slots))
+			moves = append(moves, types.ClusterSlotMove{
+				SourceID: src.sourceID,
+				TargetID: master.ID,
+				Slots:    src.slots[:n],
+			})
+			src.slots = src.slots[n:]
+			lack -= n
+			if len(src.slots) <= 0 {
+				surpluses = surpluses[1:]
+			}
+		}
+	}
+	return moves
+}
+
+// KeyHashTag return hash tag of key, it's the content between first "{" and the first "}" after it,
+// empty tag like "{}" is ignored, and the whole key is hashed in that case
+func KeyHashTag(key string) (string, bool) {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key[start+1 : start+1+end], true
+		}
+	}
+	return key, false
+}
+
+// KeySlot calculate slot of key with crc16 like cluster does
+func KeySlot(key string) int {
+	tag, _ := KeyHashTag(key)
+	var crc uint16
+	for i := 0; i < len(tag); i++ {
+		crc ^= uint16(tag[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return int(crc) % ClusterSlotCount
+}
@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"net"
+	"strings"
+)
+
+// NoFlushHook reject commands which flush databases before sending to server
+type NoFlushHook struct{}
+
+func NewNoFlushHook() *NoFlushHook {
+	return &NoFlushHook{}
+}
+
+func (h *NoFlushHook) check(cmd redis.Cmder) error {
+	switch name := strings.ToLower(cmd.Name()); name {
+	case "flushdb", "flushall":
+		err := fmt.Errorf("command \"%s\" is disabled for this connection", strings.ToUpper(name))
+		cmd.SetErr(err)
+		return err
+	}
+	return nil
+}
+
+func (h *NoFlushHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *NoFlushHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.check(cmd); err != nil {
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *NoFlushHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		// reject the whole pipeline if any command would flush
+		for _, cmd := range cmds {
+			if err := h.check(cmd); err != nil {
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
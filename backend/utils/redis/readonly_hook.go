@@ -0,0 +1,124 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"net"
+	"strings"
+)
+
+// commands which modify data or server state
+var writeCommands = map[string]struct{}{
+	"append": {}, "bitfield": {}, "bitop": {}, "blmove": {}, "blmpop": {}, "blpop": {}, "brpop": {},
+	"brpoplpush": {}, "bzmpop": {}, "bzpopmax": {}, "bzpopmin": {}, "copy": {}, "decr": {}, "decrby": {},
+	"del": {}, "eval": {}, "evalsha": {}, "expire": {}, "expireat": {}, "fcall": {}, "flushall": {},
+	"flushdb": {}, "geoadd": {}, "georadius": {}, "georadiusbymember": {}, "geosearchstore": {},
+	"getdel": {}, "getex": {}, "getset": {}, "hdel": {}, "hexpire": {}, "hexpireat": {}, "hgetdel": {},
+	"hgetex": {}, "hincrby": {}, "hincrbyfloat": {}, "hmset": {}, "hpersist": {}, "hpexpire": {},
+	"hpexpireat": {}, "hset": {}, "hsetex": {}, "hsetnx": {}, "incr": {}, "incrby": {}, "incrbyfloat": {},
+	"linsert": {}, "lmove": {}, "lmpop": {}, "lpop": {}, "lpush": {}, "lpushx": {}, "lrem": {}, "lset": {},
+	"ltrim": {}, "migrate": {}, "move": {}, "mset": {}, "msetnx": {}, "persist": {}, "pexpire": {},
+	"pexpireat": {}, "pfadd": {}, "pfmerge": {}, "psetex": {}, "rename": {}, "renamenx": {}, "restore": {},
+	"rpop": {}, "rpoplpush": {}, "rpush": {}, "rpushx": {}, "sadd": {}, "sdiffstore": {}, "set": {},
+	"setbit": {}, "setex": {}, "setnx": {}, "setrange": {}, "sinterstore": {}, "smove": {}, "sort": {},
+	"spop": {}, "srem": {}, "sunionstore": {}, "swapdb": {}, "unlink": {}, "xack": {}, "xackdel": {},
+	"xadd": {}, "xautoclaim": {}, "xclaim": {}, "xdel": {}, "xdelex": {}, "xgroup": {}, "xreadgroup": {},
+	"xsetid": {}, "xtrim": {}, "zadd": {}, "zdiffstore": {}, "zincrby": {}, "zinterstore": {}, "zmpop": {},
+	"zpopmax": {}, "zpopmin": {}, "zrangestore": {}, "zrem": {}, "zremrangebylex": {},
+	"zremrangebyrank": {}, "zremrangebyscore": {}, "zunionstore": {},
+	"json.set": {}, "json.del": {}, "json.forget": {}, "json.merge": {}, "json.mset": {},
+	"json.arrappend": {}, "json.arrinsert": {}, "json.arrpop": {}, "json.arrtrim": {}, "json.clear": {},
+	"json.numincrby": {}, "json.nummultby": {}, "json.strappend": {}, "json.toggle": {},
+	"ft.aliasadd": {}, "ft.aliasdel": {}, "ft.aliasupdate": {}, "ft.alter": {}, "ft.create": {},
+	"ft.dictadd": {}, "ft.dictdel": {}, "ft.dropindex": {}, "ft.synupdate": {},
+	"ts.add": {}, "ts.alter": {}, "ts.create": {}, "ts.createrule": {}, "ts.decrby": {}, "ts.del": {},
+	"ts.deleterule": {}, "ts.incrby": {}, "ts.madd": {},
+	"bf.add": {}, "bf.insert": {}, "bf.loadchunk": {}, "bf.madd": {}, "bf.reserve": {},
+	"cf.add": {}, "cf.addnx": {}, "cf.del": {}, "cf.insert": {}, "cf.insertnx": {}, "cf.loadchunk": {}, "cf.reserve": {},
+	"cms.incrby": {}, "cms.initbydim": {}, "cms.initbyprob": {}, "cms.merge": {},
+	"topk.add": {}, "topk.incrby": {}, "topk.reserve": {},
+	"tdigest.add": {}, "tdigest.create": {}, "tdigest.merge": {}, "tdigest.reset": {},
+	"bgrewriteaof": {}, "bgsave": {}, "debug": {}, "failover": {}, "replicaof": {}, "save": {},
+	"shutdown": {}, "slaveof": {},
+}
+
+// subcommands which modify server state, the key is main command
+var writeSubCommands = map[string][]string{
+	"acl":      {"deluser", "load", "log", "save", "setuser"},
+	"client":   {"kill", "pause", "unblock"},
+	"cluster":  {"addslots", "addslotsrange", "delslots", "delslotsrange", "failover", "flushslots", "forget", "meet", "reset", "setslot"},
+	"config":   {"resetstat", "rewrite", "set"},
+	"function": {"delete", "flush", "load", "restore"},
+	"memory":   {"purge"},
+	"module":   {"load", "loadex", "unload"},
+	"script":   {"flush", "kill", "load"},
+	"slowlog":  {"reset"},
+}
+
+// IsWriteCommand check if command will modify data or server state
+func IsWriteCommand(args []any) bool {
+	if len(args) <= 0 {
+		return false
+	}
+	cmd := strings.ToLower(fmt.Sprint(args[0]))
+	if _, ok := writeCommands[cmd]; ok {
+		return true
+	}
+	if subCmds, ok := writeSubCommands[cmd]; ok && len(args) > 1 {
+		subCmd := strings.ToLower(fmt.Sprint(args[1]))
+		for _, c := range subCmds {
+			if c == subCmd {
+				// "acl log" is read-only unless reset
+				if cmd == "acl" && c == "log" {
+					return len(args) > 2 && strings.EqualFold(fmt.Sprint(args[2]), "reset")
+				}
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadOnlyHook reject all write commands before sending to server
+type ReadOnlyHook struct{}
+
+func NewReadOnlyHook() *ReadOnlyHook {
+	return &ReadOnlyHook{}
+}
+
+func (h *ReadOnlyHook) check(cmd redis.Cmder) error {
+	if IsWriteCommand(cmd.Args()) {
+		err := fmt.Errorf("command \"%s\" is not allowed in read-only mode", strings.ToUpper(cmd.Name()))
+		cmd.SetErr(err)
+		return err
+	}
+	return nil
+}
+
+func (h *ReadOnlyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *ReadOnlyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.check(cmd); err != nil {
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *ReadOnlyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		// reject the whole pipeline if any command would write
+		for _, cmd := range cmds {
+			if err := h.check(cmd); err != nil {
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
@@ -0,0 +1,23 @@
+package redis
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// EncodeRESP encode command arguments into RESP array of bulk strings,
+// which can be replayed by "redis-cli --pipe"
+func EncodeRESP(args ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('*')
+	buf.WriteString(strconv.Itoa(len(args)))
+	buf.WriteString("\r\n")
+	for _, arg := range args {
+		buf.WriteByte('$')
+		buf.WriteString(strconv.Itoa(len(arg)))
+		buf.WriteString("\r\n")
+		buf.WriteString(arg)
+		buf.WriteString("\r\n")
+	}
+	return buf.Bytes()
+}
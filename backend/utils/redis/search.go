@@ -0,0 +1,151 @@
+package redis
+
+import (
+	"fmt"
+	"strconv"
+	"tinyrdm/backend/types"
+)
+
+// NormalizeReply convert reply to JSON compatible value, maps in RESP3 are keyed by string
+func NormalizeReply(reply any) any {
+	switch val := reply.(type) {
+	case map[any]any:
+		ret := make(map[string]any, len(val))
+		for k, v := range val {
+			ret[fmt.Sprint(k)] = NormalizeReply(v)
+		}
+		return ret
+	case []any:
+		ret := make([]any, len(val))
+		for i, v := range val {
+			ret[i] = NormalizeReply(v)
+		}
+		return ret
+	default:
+		return val
+	}
+}
+
+// ReplyToMap convert map reply in RESP3 or flat key-value array in RESP2 to map
+func ReplyToMap(reply any) map[string]any {
+	ret := map[string]any{}
+	switch val := reply.(type) {
+	case map[any]any:
+		for k, v := range val {
+			ret[fmt.Sprint(k)] = NormalizeReply(v)
+		}
+	case []any:
+		for i := 0; i+1 < len(val); i += 2 {
+			ret[fmt.Sprint(val[i])] = NormalizeReply(val[i+1])
+		}
+	}
+	return ret
+}
+
+func replyToStringMap(reply any) map[string]string {
+	ret := map[string]string{}
+	for k, v := range ReplyToMap(reply) {
+		if v == nil {
+			ret[k] = ""
+		} else {
+			ret[k] = fmt.Sprint(v)
+		}
+	}
+	return ret
+}
+
+func replyToInt(reply any) int64 {
+	switch val := reply.(type) {
+	case int64:
+		return val
+	case string:
+		n, _ := strconv.ParseInt(val, 10, 64)
+		return n
+	}
+	return 0
+}
+
+func replyToFloat(reply any) float64 {
+	switch val := reply.(type) {
+	case float64:
+		return val
+	case int64:
+		return float64(val)
+	case string:
+		n, _ := strconv.ParseFloat(val, 64)
+		return n
+	}
+	return 0
+}
+
+// ParseSearchReply parse reply of "FT.SEARCH" in both RESP2 and RESP3
+// @param withScores "WITHSCORES" is specified
+func ParseSearchReply(reply any, withScores bool) (total int64, docs []types.SearchDocument) {
+	docs = []types.SearchDocument{}
+	switch val := reply.(type) {
+	case map[any]any:
+		// RESP3: {total_results, results: [{id, score, extra_attributes}]}
+		total = replyToInt(val["total_results"])
+		results, _ := val["results"].([]any)
+		for _, r := range results {
+			if m, ok := r.(map[any]any); ok {
+				docs = append(docs, types.SearchDocument{
+					ID:     fmt.Sprint(m["id"]),
+					Score:  replyToFloat(m["score"]),
+					Fields: replyToStringMap(m["extra_attributes"]),
+				})
+			}
+		}
+	case []any:
+		// RESP2: [total, id, (score), [field, value...], ...]
+		if len(val) <= 0 {
+			return
+		}
+		total = replyToInt(val[0])
+		for i := 1; i < len(val); {
+			doc := types.SearchDocument{
+				ID:     fmt.Sprint(val[i]),
+				Fields: map[string]string{},
+			}
+			i++
+			if withScores && i < len(val) {
+				doc.Score = replyToFloat(val[i])
+				i++
+			}
+			if i < len(val) {
+				if fields, ok := val[i].([]any); ok {
+					doc.Fields = replyToStringMap(fields)
+					i++
+				}
+			}
+			docs = append(docs, doc)
+		}
+	}
+	return
+}
+
+// ParseAggregateReply parse reply of "FT.AGGREGATE" in both RESP2 and RESP3
+func ParseAggregateReply(reply any) (total int64, rows []map[string]string) {
+	rows = []map[string]string{}
+	switch val := reply.(type) {
+	case map[any]any:
+		// RESP3: {total_results, results: [{extra_attributes}]}
+		total = replyToInt(val["total_results"])
+		results, _ := val["results"].([]any)
+		for _, r := range results {
+			if m, ok := r.(map[any]any); ok {
+				rows = append(rows, replyToStringMap(m["extra_attributes"]))
+			}
+		}
+	case []any:
+		// RESP2: [total, [field, value...], ...]
+		if len(val) <= 0 {
+			return
+		}
+		total = replyToInt(val[0])
+		for _, r := range val[1:] {
+			rows = append(rows, replyToStringMap(r))
+		}
+	}
+	return
+}
@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"fmt"
+	"tinyrdm/backend/types"
+)
+
+// ParseTimeSeriesSamples parse samples reply like [[timestamp, value]...]
+// the value is a string in RESP2, or a double in RESP3
+func ParseTimeSeriesSamples(reply any) []types.TimeSeriesSample {
+	items, _ := reply.([]any)
+	samples := make([]types.TimeSeriesSample, 0, len(items))
+	for _, it := range items {
+		if pair, ok := it.([]any); ok && len(pair) >= 2 {
+			samples = append(samples, types.TimeSeriesSample{
+				Timestamp: replyToInt(pair[0]),
+				Value:     replyToFloat(pair[1]),
+			})
+		}
+	}
+	return samples
+}
+
+// parse labels reply, which is [[name, value]...] in RESP2, or a map in RESP3
+func parseTimeSeriesLabels(reply any) map[string]string {
+	labels := map[string]string{}
+	switch val := reply.(type) {
+	case map[any]any:
+		for k, v := range val {
+			labels[fmt.Sprint(k)] = fmt.Sprint(v)
+		}
+	case []any:
+		for _, it := range val {
+			if pair, ok := it.([]any); ok && len(pair) >= 2 {
+				labels[fmt.Sprint(pair[0])] = fmt.Sprint(pair[1])
+			}
+		}
+	}
+	return labels
+}
+
+// ParseTimeSeriesInfo parse reply of "TS.INFO"
+func ParseTimeSeriesInfo(reply any) types.TimeSeriesInfo {
+	var rawLabels any
+	switch val := reply.(type) {
+	case map[any]any:
+		rawLabels = val["labels"]
+	case []any:
+		for i := 0; i+1 < len(val); i += 2 {
+			if fmt.Sprint(val[i]) == "labels" {
+				rawLabels = val[i+1]
+			}
+		}
+	}
+
+	raw := ReplyToMap(reply)
+	str := func(name string) string {
+		if v, ok := raw[name]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	return types.TimeSeriesInfo{
+		TotalSamples:    replyToInt(raw["totalSamples"]),
+		MemoryUsage:     replyToInt(raw["memoryUsage"]),
+		FirstTimestamp:  replyToInt(raw["firstTimestamp"]),
+		LastTimestamp:   replyToInt(raw["lastTimestamp"]),
+		RetentionTime:   replyToInt(raw["retentionTime"]),
+		ChunkCount:      replyToInt(raw["chunkCount"]),
+		ChunkSize:       replyToInt(raw["chunkSize"]),
+		ChunkType:       str("chunkType"),
+		DuplicatePolicy: str("duplicatePolicy"),
+		SourceKey:       str("sourceKey"),
+		Labels:          parseTimeSeriesLabels(rawLabels),
+		Raw:             raw,
+	}
+}
+
+// ParseTimeSeriesMRange parse reply of "TS.MRANGE"
+// RESP2: [[key, labels, samples]...]
+// RESP3: {key: [labels, metadata..., samples]}
+func ParseTimeSeriesMRange(reply any) []types.TimeSeriesSeries {
+	parseEntry := func(key string, parts []any) types.TimeSeriesSeries {
+		series := types.TimeSeriesSeries{
+			Key:     key,
+			Labels:  map[string]string{},
+			Samples: []types.TimeSeriesSample{},
+		}
+		if len(parts) > 0 {
+			series.Labels = parseTimeSeriesLabels(parts[0])
+			series.Samples = ParseTimeSeriesSamples(parts[len(parts)-1])
+		}
+		return series
+	}
+
+	var ret []types.TimeSeriesSeries
+	switch val := reply.(type) {
+	case map[any]any:
+		for k, v := range val {
+			parts, _ := v.([]any)
+			ret = append(ret, parseEntry(fmt.Sprint(k), parts))
+		}
+	case []any:
+		for _, it := range val {
+			if parts, ok := it.([]any); ok && len(parts) > 0 {
+				ret = append(ret, parseEntry(fmt.Sprint(parts[0]), parts[1:]))
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,45 @@
+package sshutil
+
+import (
+	"errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"net"
+	"os"
+	"sync"
+)
+
+var agentClient agent.ExtendedAgent
+var agentConn net.Conn
+var agentMutex sync.Mutex
+
+// AgentSigners get all signers provided by ssh-agent, which socket is specified by "SSH_AUTH_SOCK"
+func AgentSigners() ([]ssh.Signer, error) {
+	agentMutex.Lock()
+	defer agentMutex.Unlock()
+
+	if agentClient != nil {
+		if signers, err := agentClient.Signers(); err == nil {
+			return signers, nil
+		}
+		// agent connection may be broken, try reconnecting
+		agentConn.Close()
+		agentClient, agentConn = nil, nil
+	}
+
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if len(sock) <= 0 {
+		return nil, errors.New("ssh-agent is not running or SSH_AUTH_SOCK is not set")
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, err
+	}
+	agentConn, agentClient = conn, agent.NewClient(conn)
+	return agentClient.Signers()
+}
+
+// AgentAuth authenticate with keys provided by ssh-agent
+func AgentAuth() ssh.AuthMethod {
+	return ssh.PublicKeysCallback(AgentSigners)
+}
@@ -0,0 +1,143 @@
+package sshutil
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// HostConfig options of a host read from OpenSSH config file
+type HostConfig struct {
+	HostName      string
+	Port          int
+	User          string
+	IdentityFiles []string
+	ProxyJump     []string // jump hosts in "[user@]host[:port]" format
+}
+
+// LookupHostConfig read options of host alias from ~/.ssh/config
+// only "Host", "HostName", "Port", "User", "IdentityFile" and "ProxyJump" are supported,
+// "Match" blocks are ignored. the first obtained value of each option takes effect as OpenSSH does
+func LookupHostConfig(alias string) (cfg HostConfig, found bool, err error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	file, err := os.Open(path.Join(home, ".ssh", "config"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	defer file.Close()
+
+	matched := true // options before first "Host" apply to all hosts
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, val := splitConfigLine(scanner.Text())
+		if len(key) <= 0 {
+			continue
+		}
+		switch key {
+		case "host":
+			matched = matchHost(alias, strings.Fields(val))
+			found = found || matched
+			continue
+		case "match":
+			matched = false
+			continue
+		}
+		if !matched || len(val) <= 0 {
+			continue
+		}
+
+		switch key {
+		case "hostname":
+			if len(cfg.HostName) <= 0 {
+				cfg.HostName = strings.ReplaceAll(val, "%h", alias)
+			}
+		case "port":
+			if cfg.Port <= 0 {
+				cfg.Port, _ = strconv.Atoi(val)
+			}
+		case "user":
+			if len(cfg.User) <= 0 {
+				cfg.User = val
+			}
+		case "identityfile":
+			// multiple identity files are allowed
+			cfg.IdentityFiles = append(cfg.IdentityFiles, expandHome(strings.Trim(val, "\""), home))
+		case "proxyjump":
+			if cfg.ProxyJump == nil && !strings.EqualFold(val, "none") {
+				cfg.ProxyJump = strings.Split(val, ",")
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if len(cfg.HostName) <= 0 {
+		cfg.HostName = alias
+	}
+	return
+}
+
+// split config line into lowercase keyword and argument, supports "key value" and "key=value"
+func splitConfigLine(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if len(line) <= 0 || strings.HasPrefix(line, "#") {
+		return "", ""
+	}
+	idx := strings.IndexAny(line, " \t=")
+	if idx < 0 {
+		return strings.ToLower(line), ""
+	}
+	key := strings.ToLower(line[:idx])
+	val := strings.TrimSpace(line[idx:])
+	val = strings.TrimSpace(strings.TrimPrefix(val, "="))
+	return key, val
+}
+
+// check if host alias matches any of patterns, negated patterns("!pattern") take precedence
+func matchHost(alias string, patterns []string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		if negate := strings.HasPrefix(pattern, "!"); negate {
+			if ok, _ := path.Match(pattern[1:], alias); ok {
+				return false
+			}
+		} else if ok, _ := path.Match(pattern, alias); ok {
+			matched = true
+		}
+	}
+	return matched
+}
+
+func expandHome(p, home string) string {
+	if p == "~" {
+		return home
+	}
+	if strings.HasPrefix(p, "~/") {
+		return path.Join(home, p[2:])
+	}
+	return p
+}
+
+// ParseJumpHost parse jump host in "[user@]host[:port]" format
+func ParseJumpHost(spec string) (user, host string, port int) {
+	spec = strings.TrimSpace(strings.TrimPrefix(spec, "ssh://"))
+	if idx := strings.LastIndex(spec, "@"); idx >= 0 {
+		user, spec = spec[:idx], spec[idx+1:]
+	}
+	host = spec
+	if idx := strings.LastIndex(spec, ":"); idx >= 0 && !strings.HasSuffix(spec, "]") {
+		if p, err := strconv.Atoi(spec[idx+1:]); err == nil {
+			host, port = spec[:idx], p
+		}
+	}
+	host = strings.Trim(host, "[]")
+	return
+}
@@ -0,0 +1,39 @@
+package sshutil
+
+import (
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"net"
+	"os"
+	"path"
+	"strings"
+)
+
+// HostKeyCallback create host key verification callback by policy
+// "known_hosts": verify with ~/.ssh/known_hosts
+// "fingerprint": verify with specified SHA256 fingerprint, like "SHA256:xxxx"
+// others: skip verification
+func HostKeyCallback(policy, fingerprint string) (ssh.HostKeyCallback, error) {
+	switch policy {
+	case "known_hosts":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		return knownhosts.New(path.Join(home, ".ssh", "known_hosts"))
+	case "fingerprint":
+		expected := strings.TrimPrefix(strings.TrimSpace(fingerprint), "SHA256:")
+		if len(expected) <= 0 {
+			return nil, fmt.Errorf("host key fingerprint is required")
+		}
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if actual := ssh.FingerprintSHA256(key); strings.TrimPrefix(actual, "SHA256:") != expected {
+				return fmt.Errorf("host key mismatch for %s: %s", hostname, actual)
+			}
+			return nil
+		}, nil
+	default:
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+}
@@ -0,0 +1,161 @@
+package sshutil
+
+import (
+	"errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+	"net"
+	"sync"
+)
+
+// Tunnel dial target address through ssh server.
+// the ssh session is established on first dial, and will be reestablished
+// automatically if dropped. it's closed after all tunneled connections are closed.
+type Tunnel struct {
+	addr    string            // ssh server address
+	config  *ssh.ClientConfig // ssh client config
+	forward proxy.Dialer      // dialer to reach ssh server
+	client  *ssh.Client
+	refs    int // count of alive tunneled connections
+	mutex   sync.Mutex
+}
+
+// NewTunnel create a ssh tunnel dialer
+// if forward is nil, connect to ssh server directly
+func NewTunnel(addr string, config *ssh.ClientConfig, forward proxy.Dialer) *Tunnel {
+	if forward == nil {
+		forward = proxy.Direct
+	}
+	return &Tunnel{
+		addr:    addr,
+		config:  config,
+		forward: forward,
+	}
+}
+
+// get current ssh client, connect to ssh server if not connected
+func (t *Tunnel) getClient() (*ssh.Client, error) {
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	conn, err := t.forward.Dial("tcp", t.addr)
+	if err != nil {
+		return nil, err
+	}
+	sc, chans, reqs, err := ssh.NewClientConn(conn, t.addr, t.config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	client := ssh.NewClient(sc, chans, reqs)
+	go func() {
+		// session dropped, reset client for reconnecting
+		client.Wait()
+		t.mutex.Lock()
+		if t.client == client {
+			t.client = nil
+		}
+		t.mutex.Unlock()
+	}()
+	t.client = client
+	return client, nil
+}
+
+// Dial connect to target address through ssh server
+func (t *Tunnel) Dial(network, addr string) (net.Conn, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	client, err := t.getClient()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.Dial(network, addr)
+	if err != nil {
+		var openErr *ssh.OpenChannelError
+		if errors.As(err, &openErr) {
+			// rejected by ssh server, no need to reconnect
+			return nil, err
+		}
+		// session may be broken, reconnect and retry once
+		client.Close()
+		t.client = nil
+		if client, err = t.getClient(); err != nil {
+			return nil, err
+		}
+		if conn, err = client.Dial(network, addr); err != nil {
+			return nil, err
+		}
+	}
+	t.refs += 1
+	return &tunnelConn{Conn: conn, tunnel: t}, nil
+}
+
+// release one tunneled connection, close ssh session if no connection alive
+func (t *Tunnel) release() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.refs -= 1
+	if t.refs <= 0 && t.client != nil {
+		t.refs = 0
+		t.client.Close()
+		t.client = nil
+	}
+}
+
+// Close close the ssh session
+func (t *Tunnel) Close() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.client != nil {
+		err := t.client.Close()
+		t.client = nil
+		return err
+	}
+	return nil
+}
+
+type tunnelConn struct {
+	net.Conn
+	tunnel *Tunnel
+	once   sync.Once
+}
+
+func (c *tunnelConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.tunnel.release)
+	return err
+}
+
+// Session open a session on ssh server to execute remote command,
+// the ssh session is kept alive until the returned session is closed
+func (t *Tunnel) Session() (*Session, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	client, err := t.getClient()
+	if err != nil {
+		return nil, err
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	t.refs += 1
+	return &Session{Session: session, tunnel: t}, nil
+}
+
+type Session struct {
+	*ssh.Session
+	tunnel *Tunnel
+	once   sync.Once
+}
+
+func (s *Session) Close() error {
+	err := s.Session.Close()
+	s.once.Do(s.tunnel.release)
+	return err
+}
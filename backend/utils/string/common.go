@@ -1,6 +1,8 @@
 package strutil
 
 import (
+	"slices"
+	"strings"
 	"unicode"
 )
 
@@ -40,3 +42,113 @@ func IsSameChar(str string) bool {
 
 	return true
 }
+
+// EscapeGlob escape special characters of redis glob-style pattern, so that str is matched literally
+func EscapeGlob(str string) string {
+	var sb strings.Builder
+	for _, r := range str {
+		switch r {
+		case '*', '?', '[', ']', '\\', '^', '-':
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// SplitKey split key into namespace parts by multiple separators, the longer separator is matched first
+func SplitKey(key string, separators []string) []string {
+	seps := slices.Clone(separators)
+	slices.SortStableFunc(seps, func(a, b string) int {
+		return len(b) - len(a)
+	})
+	parts := make([]string, 0, 4)
+	start := 0
+	for i := 0; i < len(key); {
+		matched := false
+		for _, sep := range seps {
+			if len(sep) > 0 && strings.HasPrefix(key[i:], sep) {
+				parts = append(parts, key[start:i])
+				i += len(sep)
+				start, matched = i, true
+				break
+			}
+		}
+		if !matched {
+			i++
+		}
+	}
+	return append(parts, key[start:])
+}
+
+// MatchGlob check if str matches redis glob-style pattern, supports "*", "?", "[...]" and "\" escaping
+func MatchGlob(pattern, str string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(str); i++ {
+				if MatchGlob(pattern[1:], str[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(str) <= 0 {
+				return false
+			}
+			str = str[1:]
+		case '[':
+			if len(str) <= 0 {
+				return false
+			}
+			pattern = pattern[1:]
+			not := len(pattern) > 0 && pattern[0] == '^'
+			if not {
+				pattern = pattern[1:]
+			}
+			matched := false
+			for len(pattern) > 0 && pattern[0] != ']' {
+				if pattern[0] == '\\' && len(pattern) > 1 {
+					pattern = pattern[1:]
+					matched = matched || pattern[0] == str[0]
+				} else if len(pattern) > 2 && pattern[1] == '-' && pattern[2] != ']' {
+					start, end := pattern[0], pattern[2]
+					if start > end {
+						start, end = end, start
+					}
+					matched = matched || (str[0] >= start && str[0] <= end)
+					pattern = pattern[2:]
+				} else {
+					matched = matched || pattern[0] == str[0]
+				}
+				pattern = pattern[1:]
+			}
+			if matched == not {
+				return false
+			}
+			str = str[1:]
+			if len(pattern) <= 0 {
+				// unclosed bracket
+				return len(str) <= 0
+			}
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(str) <= 0 || pattern[0] != str[0] {
+				return false
+			}
+			str = str[1:]
+		}
+		pattern = pattern[1:]
+	}
+	return len(str) <= 0
+}
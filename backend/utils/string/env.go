@@ -0,0 +1,31 @@
+package strutil
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var envPlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)}`)
+
+// ExpandEnv replace ${ENV_VAR} placeholders with value of environment variables
+// only braced form is supported, so that "$" in passwords can be kept as is
+func ExpandEnv(str string) (string, error) {
+	if !strings.Contains(str, "${") {
+		return str, nil
+	}
+	var missing []string
+	ret := envPlaceholder.ReplaceAllStringFunc(str, func(placeholder string) string {
+		name := placeholder[2 : len(placeholder)-1]
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return val
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable not defined: %s", strings.Join(missing, ", "))
+	}
+	return ret, nil
+}
@@ -0,0 +1,221 @@
+package thriftutil
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+const binaryVersion1 = 0x80010000
+
+type binaryReader struct {
+	buf   []byte
+	pos   int
+	depth int
+}
+
+// DecodeBinary decode struct or message in binary protocol
+func DecodeBinary(buf []byte) (any, error) {
+	r := &binaryReader{buf: buf}
+	val, err := r.readMessageOrStruct()
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(r.buf) {
+		return nil, errMalformed
+	}
+	return val, nil
+}
+
+func (r *binaryReader) read(n int) ([]byte, error) {
+	if n < 0 || n > len(r.buf)-r.pos {
+		return nil, errMalformed
+	}
+	r.pos += n
+	return r.buf[r.pos-n : r.pos], nil
+}
+
+func (r *binaryReader) readByte() (byte, error) {
+	b, err := r.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *binaryReader) readI16() (int16, error) {
+	b, err := r.read(2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.BigEndian.Uint16(b)), nil
+}
+
+func (r *binaryReader) readI32() (int32, error) {
+	b, err := r.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (r *binaryReader) readBinary() ([]byte, error) {
+	length, err := r.readI32()
+	if err != nil {
+		return nil, err
+	}
+	return r.read(int(length))
+}
+
+// read count of container, which can not exceed the remaining bytes
+func (r *binaryReader) readSize() (int, error) {
+	size, err := r.readI32()
+	if err != nil {
+		return 0, err
+	}
+	if size < 0 || int(size) > len(r.buf)-r.pos {
+		return 0, errMalformed
+	}
+	return int(size), nil
+}
+
+func (r *binaryReader) readMessageOrStruct() (any, error) {
+	if len(r.buf) < 4 {
+		return r.readStruct()
+	}
+	header := binary.BigEndian.Uint32(r.buf)
+	if header&0xffff0000 != binaryVersion1 {
+		return r.readStruct()
+	}
+	// strict message header
+	r.pos += 4
+	name, err := r.readBinary()
+	if err != nil {
+		return nil, err
+	}
+	seqID, err := r.readI32()
+	if err != nil {
+		return nil, err
+	}
+	body, err := r.readStruct()
+	if err != nil {
+		return nil, err
+	}
+	return messageValue(string(name), byte(header), seqID, body), nil
+}
+
+func (r *binaryReader) readStruct() (any, error) {
+	obj := &Object{}
+	for {
+		tp, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if tp == typeStop {
+			return obj, nil
+		}
+		id, err := r.readI16()
+		if err != nil {
+			return nil, err
+		}
+		val, err := r.readValue(tp)
+		if err != nil {
+			return nil, err
+		}
+		obj.set(strconv.Itoa(int(id)), val)
+	}
+}
+
+func (r *binaryReader) readValue(tp byte) (any, error) {
+	r.depth++
+	defer func() { r.depth-- }()
+	if r.depth > maxDepth {
+		return nil, errMalformed
+	}
+
+	switch tp {
+	case typeBool:
+		b, err := r.readByte()
+		if err != nil || b > 1 {
+			return nil, errMalformed
+		}
+		return b == 1, nil
+	case typeByte:
+		b, err := r.readByte()
+		return int64(int8(b)), err
+	case typeI16:
+		n, err := r.readI16()
+		return int64(n), err
+	case typeI32:
+		n, err := r.readI32()
+		return int64(n), err
+	case typeI64:
+		b, err := r.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return int64(binary.BigEndian.Uint64(b)), nil
+	case typeDouble:
+		b, err := r.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return doubleValue(math.Float64frombits(binary.BigEndian.Uint64(b))), nil
+	case typeString:
+		b, err := r.readBinary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryValue(b), nil
+	case typeUUID:
+		b, err := r.read(16)
+		if err != nil {
+			return nil, err
+		}
+		return uuidValue(b), nil
+	case typeStruct:
+		return r.readStruct()
+	case typeList, typeSet:
+		elemType, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]any, 0, size)
+		for i := 0; i < size; i++ {
+			val, err := r.readValue(elemType)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, val)
+		}
+		return list, nil
+	case typeMap:
+		keyType, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		valType, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		keys, values := make([]any, size), make([]any, size)
+		for i := 0; i < size; i++ {
+			if keys[i], err = r.readValue(keyType); err != nil {
+				return nil, err
+			}
+			if values[i], err = r.readValue(valType); err != nil {
+				return nil, err
+			}
+		}
+		return buildMap(keys, values), nil
+	}
+	return nil, errMalformed
+}
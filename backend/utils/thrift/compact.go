@@ -0,0 +1,247 @@
+package thriftutil
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+const (
+	compactProtocolID  = 0x82
+	compactVersion     = 1
+	compactVersionMask = 0x1f
+)
+
+// types of compact protocol
+const (
+	compactTrue   = 1
+	compactFalse  = 2
+	compactByte   = 3
+	compactI16    = 4
+	compactI32    = 5
+	compactI64    = 6
+	compactDouble = 7
+	compactBinary = 8
+	compactList   = 9
+	compactSet    = 10
+	compactMap    = 11
+	compactStruct = 12
+	compactUUID   = 13
+)
+
+type compactReader struct {
+	buf   []byte
+	pos   int
+	depth int
+}
+
+// DecodeCompact decode struct or message in compact protocol
+func DecodeCompact(buf []byte) (any, error) {
+	r := &compactReader{buf: buf}
+	val, err := r.readMessageOrStruct()
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(r.buf) {
+		return nil, errMalformed
+	}
+	return val, nil
+}
+
+func (r *compactReader) read(n int) ([]byte, error) {
+	if n < 0 || n > len(r.buf)-r.pos {
+		return nil, errMalformed
+	}
+	r.pos += n
+	return r.buf[r.pos-n : r.pos], nil
+}
+
+func (r *compactReader) readByte() (byte, error) {
+	b, err := r.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *compactReader) readVarint() (uint64, error) {
+	n, size := binary.Uvarint(r.buf[r.pos:])
+	if size <= 0 {
+		return 0, errMalformed
+	}
+	r.pos += size
+	return n, nil
+}
+
+// read zigzag encoded integer
+func (r *compactReader) readZigzag() (int64, error) {
+	n, err := r.readVarint()
+	return int64(n>>1) ^ -int64(n&1), err
+}
+
+func (r *compactReader) readBinary() ([]byte, error) {
+	length, err := r.readVarint()
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(r.buf)-r.pos) {
+		return nil, errMalformed
+	}
+	return r.read(int(length))
+}
+
+func (r *compactReader) checkSize(size uint64) (int, error) {
+	if size > uint64(len(r.buf)-r.pos) {
+		return 0, errMalformed
+	}
+	return int(size), nil
+}
+
+func (r *compactReader) readMessageOrStruct() (any, error) {
+	if len(r.buf) < 2 || r.buf[0] != compactProtocolID || r.buf[1]&compactVersionMask != compactVersion {
+		return r.readStruct()
+	}
+	msgType := r.buf[1] >> 5
+	r.pos += 2
+	seqID, err := r.readVarint()
+	if err != nil {
+		return nil, err
+	}
+	name, err := r.readBinary()
+	if err != nil {
+		return nil, err
+	}
+	body, err := r.readStruct()
+	if err != nil {
+		return nil, err
+	}
+	return messageValue(string(name), msgType, int32(seqID), body), nil
+}
+
+func (r *compactReader) readStruct() (any, error) {
+	obj := &Object{}
+	var lastID int64
+	for {
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if header == typeStop {
+			return obj, nil
+		}
+		tp := header & 0x0f
+		if delta := header >> 4; delta != 0 {
+			lastID += int64(delta)
+		} else if lastID, err = r.readZigzag(); err != nil {
+			return nil, err
+		}
+
+		var val any
+		switch tp {
+		case compactTrue:
+			// value of boolean field is in type
+			val = true
+		case compactFalse:
+			val = false
+		default:
+			if val, err = r.readValue(tp); err != nil {
+				return nil, err
+			}
+		}
+		obj.set(strconv.FormatInt(lastID, 10), val)
+	}
+}
+
+func (r *compactReader) readValue(tp byte) (any, error) {
+	r.depth++
+	defer func() { r.depth-- }()
+	if r.depth > maxDepth {
+		return nil, errMalformed
+	}
+
+	switch tp {
+	case compactTrue, compactFalse:
+		// boolean in container is in a byte
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		return b == compactTrue, nil
+	case compactByte:
+		b, err := r.readByte()
+		return int64(int8(b)), err
+	case compactI16, compactI32, compactI64:
+		return r.readZigzag()
+	case compactDouble:
+		b, err := r.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return doubleValue(math.Float64frombits(binary.LittleEndian.Uint64(b))), nil
+	case compactBinary:
+		b, err := r.readBinary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryValue(b), nil
+	case compactUUID:
+		b, err := r.read(16)
+		if err != nil {
+			return nil, err
+		}
+		return uuidValue(b), nil
+	case compactStruct:
+		return r.readStruct()
+	case compactList, compactSet:
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			if size, err = r.readVarint(); err != nil {
+				return nil, err
+			}
+		}
+		count, err := r.checkSize(size)
+		if err != nil {
+			return nil, err
+		}
+		list := make([]any, 0, count)
+		for i := 0; i < count; i++ {
+			val, err := r.readValue(header & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, val)
+		}
+		return list, nil
+	case compactMap:
+		size, err := r.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		count, err := r.checkSize(size)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return &Object{}, nil
+		}
+		kv, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		keys, values := make([]any, count), make([]any, count)
+		for i := 0; i < count; i++ {
+			if keys[i], err = r.readValue(kv >> 4); err != nil {
+				return nil, err
+			}
+			if values[i], err = r.readValue(kv & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return buildMap(keys, values), nil
+	}
+	return nil, errMalformed
+}
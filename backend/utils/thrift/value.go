@@ -0,0 +1,147 @@
+package thriftutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+var errMalformed = errors.New("malformed thrift data")
+
+// max nested level accepted
+const maxDepth = 64
+
+// field types, shared by binary protocol and converted from compact protocol
+const (
+	typeStop   = 0
+	typeBool   = 2
+	typeByte   = 3
+	typeDouble = 4
+	typeI16    = 6
+	typeI32    = 8
+	typeI64    = 10
+	typeString = 11
+	typeStruct = 12
+	typeMap    = 13
+	typeSet    = 14
+	typeList   = 15
+	typeUUID   = 16
+)
+
+// Object json object with keys in order
+type Object struct {
+	Keys   []string
+	Values []any
+}
+
+func (o *Object) set(key string, val any) {
+	o.Keys = append(o.Keys, key)
+	o.Values = append(o.Values, val)
+}
+
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b, _ := json.Marshal(key)
+		buf.Write(b)
+		buf.WriteByte(':')
+		b, err := json.Marshal(o.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Pairs entries of map with keys not in string or number
+type Pairs [][2]any
+
+func (p Pairs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"@map": [][2]any(p)})
+}
+
+// render binary as text if it's printable, otherwise in base64
+func binaryValue(b []byte) any {
+	if utf8.Valid(b) {
+		printable := true
+		for _, r := range string(b) {
+			if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return string(b)
+		}
+	}
+	return map[string]string{"@base64": base64.StdEncoding.EncodeToString(b)}
+}
+
+func doubleValue(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return f
+}
+
+func uuidValue(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// build map as object if all keys are string or number, otherwise as pairs
+func buildMap(keys, values []any) any {
+	obj := &Object{}
+	for i, key := range keys {
+		switch k := key.(type) {
+		case string:
+			obj.set(k, values[i])
+		case int64:
+			obj.set(strconv.FormatInt(k, 10), values[i])
+		case bool:
+			obj.set(strconv.FormatBool(k), values[i])
+		default:
+			pairs := make(Pairs, len(keys))
+			for j := range keys {
+				pairs[j] = [2]any{keys[j], values[j]}
+			}
+			return pairs
+		}
+	}
+	return obj
+}
+
+// Decode decode thrift struct or message without schema, in binary protocol or compact protocol
+// whichever consumes the whole content. fields are keyed by field id, and message is rendered as
+// {"@method": name, "@type": type, "@seqid": id, "@body": struct}
+func Decode(buf []byte) (any, error) {
+	if val, err := DecodeBinary(buf); err == nil {
+		return val, nil
+	}
+	return DecodeCompact(buf)
+}
+
+func messageValue(name string, tp byte, seqID int32, body any) any {
+	types := map[byte]string{1: "call", 2: "reply", 3: "exception", 4: "oneway"}
+	msgType, ok := types[tp]
+	if !ok {
+		msgType = strconv.Itoa(int(tp))
+	}
+	obj := &Object{}
+	obj.set("@method", name)
+	obj.set("@type", msgType)
+	obj.set("@seqid", int64(seqID))
+	obj.set("@body", body)
+	return obj
+}
@@ -0,0 +1,249 @@
+package wasmutil
+
+import (
+	"errors"
+	"fmt"
+)
+
+// instr compiled instruction
+type instr struct {
+	op  uint16   // opcode, or 0xfc00 + sub opcode for prefixed instructions
+	a   uint64   // first immediate: constant, index, memory offset or label depth
+	b   uint32   // second immediate: position of end or table index
+	c   uint32   // arity of branch to the block
+	d   uint32   // count of params of the block
+	tbl []uint32 // label depths of br_table
+}
+
+const opPrefix = 0xfc
+
+// resolve block type into count of params and results
+func (m *Module) blockType(r *reader) (params, results uint32, err error) {
+	bt, err := r.signed(33)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch {
+	case bt == -64:
+		// empty
+		return 0, 0, nil
+	case bt < 0:
+		// single value type
+		return 0, 1, nil
+	case bt < int64(len(m.types)):
+		return uint32(len(m.types[bt].params)), uint32(len(m.types[bt].results)), nil
+	}
+	return 0, 0, errMalformed
+}
+
+func (m *Module) compile(f *function) ([]instr, error) {
+	r := &reader{buf: f.body}
+	code := make([]instr, 0, len(f.body)/2)
+	var ctrls []int // positions of opening block, loop or if
+	var closed bool // end of function body reached
+	for !r.eof() {
+		op, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		in := instr{op: uint16(op)}
+		switch op {
+		case 0x02, 0x03, 0x04:
+			// block, loop, if
+			params, results, err := m.blockType(r)
+			if err != nil {
+				return nil, err
+			}
+			in.d = params
+			if op == 0x03 {
+				in.c = params
+			} else {
+				in.c = results
+			}
+			ctrls = append(ctrls, len(code))
+		case 0x05:
+			// else
+			if len(ctrls) <= 0 || code[ctrls[len(ctrls)-1]].op != 0x04 {
+				return nil, errMalformed
+			}
+			code[ctrls[len(ctrls)-1]].a = uint64(len(code))
+		case 0x0b:
+			// end
+			if len(ctrls) > 0 {
+				open := &code[ctrls[len(ctrls)-1]]
+				ctrls = ctrls[:len(ctrls)-1]
+				open.b = uint32(len(code))
+				if open.op == 0x04 {
+					if open.a > 0 {
+						// jump from else to end
+						code[open.a].b = uint32(len(code))
+					} else {
+						// if without else
+						open.a = uint64(len(code))
+					}
+				}
+			} else if !r.eof() {
+				return nil, errMalformed
+			} else {
+				closed = true
+			}
+		case 0x0c, 0x0d:
+			// br, br_if
+			depth, err := r.u32()
+			if err != nil {
+				return nil, err
+			}
+			in.a = uint64(depth)
+		case 0x0e:
+			// br_table
+			if in.tbl, err = readVec(r, r.u32); err != nil {
+				return nil, err
+			}
+			depth, err := r.u32()
+			if err != nil {
+				return nil, err
+			}
+			in.a = uint64(depth)
+		case 0x10, 0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0xd2:
+			// call, local.*, global.*, table.get, table.set, ref.func
+			idx, err := r.u32()
+			if err != nil {
+				return nil, err
+			}
+			in.a = uint64(idx)
+			if op == 0x10 {
+				if _, ok := m.funcType(idx); !ok {
+					return nil, errMalformed
+				}
+			}
+		case 0x11:
+			// call_indirect
+			typeIdx, err := r.u32()
+			if err != nil {
+				return nil, err
+			}
+			tableIdx, err := r.u32()
+			if err != nil {
+				return nil, err
+			}
+			if int(typeIdx) >= len(m.types) {
+				return nil, errMalformed
+			}
+			in.a, in.b = uint64(typeIdx), tableIdx
+		case 0x1c:
+			// select with types
+			if _, err = readValueTypes(r); err != nil {
+				return nil, err
+			}
+		case 0x3f, 0x40:
+			// memory.size, memory.grow
+			if _, err = r.byte(); err != nil {
+				return nil, err
+			}
+		case 0x41:
+			n, err := r.signed(32)
+			if err != nil {
+				return nil, err
+			}
+			in.a = uint64(uint32(n))
+		case 0x42:
+			n, err := r.signed(64)
+			if err != nil {
+				return nil, err
+			}
+			in.a = uint64(n)
+		case 0x43:
+			b, err := r.bytes(4)
+			if err != nil {
+				return nil, err
+			}
+			in.a = uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16 | uint64(b[3])<<24
+		case 0x44:
+			b, err := r.bytes(8)
+			if err != nil {
+				return nil, err
+			}
+			for i := 7; i >= 0; i-- {
+				in.a = in.a<<8 | uint64(b[i])
+			}
+		case 0xd0:
+			// ref.null
+			if _, err = r.byte(); err != nil {
+				return nil, err
+			}
+		case opPrefix:
+			sub, err := r.u32()
+			if err != nil {
+				return nil, err
+			}
+			in.op = opPrefix<<8 | uint16(sub)
+			switch sub {
+			case 0, 1, 2, 3, 4, 5, 6, 7:
+				// saturating truncation
+			case 8:
+				// memory.init
+				idx, err := r.u32()
+				if err != nil {
+					return nil, err
+				}
+				in.a = uint64(idx)
+				_, err = r.byte()
+				if err != nil {
+					return nil, err
+				}
+			case 9, 13, 15, 16, 17:
+				// data.drop, elem.drop, table.grow, table.size, table.fill
+				idx, err := r.u32()
+				if err != nil {
+					return nil, err
+				}
+				in.a = uint64(idx)
+			case 10:
+				// memory.copy
+				if _, err = r.bytes(2); err != nil {
+					return nil, err
+				}
+			case 11:
+				// memory.fill
+				if _, err = r.byte(); err != nil {
+					return nil, err
+				}
+			case 12, 14:
+				// table.init, table.copy
+				a, err := r.u32()
+				if err != nil {
+					return nil, err
+				}
+				b, err := r.u32()
+				if err != nil {
+					return nil, err
+				}
+				in.a, in.b = uint64(a), b
+			default:
+				return nil, fmt.Errorf("unsupported instruction 0xfc %d", sub)
+			}
+		default:
+			switch {
+			case op >= 0x28 && op <= 0x3e:
+				// load and store with alignment and offset
+				if _, err = r.u32(); err != nil {
+					return nil, err
+				}
+				offset, err := r.u32()
+				if err != nil {
+					return nil, err
+				}
+				in.a = uint64(offset)
+			case op <= 0x01, op == 0x0f, op == 0x1a, op == 0x1b, op == 0xd1, op >= 0x45 && op <= 0xc4:
+				// instructions without immediate
+			default:
+				return nil, fmt.Errorf("unsupported instruction 0x%02x", op)
+			}
+		}
+		code = append(code, in)
+	}
+	if !closed {
+		return nil, errors.New("unterminated function body")
+	}
+	return code, nil
+}
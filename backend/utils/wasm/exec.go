@@ -0,0 +1,811 @@
+package wasmutil
+
+import (
+	"math"
+	"math/bits"
+)
+
+// trap error raised in executing, which aborts the call
+type trap string
+
+func (t trap) Error() string {
+	return "wasm trap: " + string(t)
+}
+
+const maxCallDepth = 1000
+
+type label struct {
+	target int // position to continue after branch
+	height int // height of value stack at entry of block
+	arity  int // count of values carried by branch
+}
+
+func (in *Instance) push(v uint64) {
+	in.stack = append(in.stack, v)
+}
+
+func (in *Instance) pop() uint64 {
+	v := in.stack[len(in.stack)-1]
+	in.stack = in.stack[:len(in.stack)-1]
+	return v
+}
+
+func (in *Instance) pushBool(b bool) {
+	if b {
+		in.push(1)
+	} else {
+		in.push(0)
+	}
+}
+
+func (in *Instance) popF32() float32 {
+	return math.Float32frombits(uint32(in.pop()))
+}
+
+func (in *Instance) pushF32(f float32) {
+	in.push(uint64(math.Float32bits(f)))
+}
+
+func (in *Instance) popF64() float64 {
+	return math.Float64frombits(in.pop())
+}
+
+func (in *Instance) pushF64(f float64) {
+	in.push(math.Float64bits(f))
+}
+
+// keep top arity values at height of stack
+func (in *Instance) unwind(height, arity int) {
+	if len(in.stack)-arity != height {
+		copy(in.stack[height:], in.stack[len(in.stack)-arity:])
+		in.stack = in.stack[:height+arity]
+	}
+}
+
+// effective address of memory access
+func (in *Instance) addr(base uint64, offset uint64, size uint64) uint64 {
+	ea := uint64(uint32(base)) + offset
+	if ea+size > uint64(len(in.memory)) {
+		panic(trap("out of bounds memory access"))
+	}
+	return ea
+}
+
+func (in *Instance) table(idx uint32) []uint64 {
+	if int(idx) >= len(in.tables) {
+		panic(trap("unknown table"))
+	}
+	return in.tables[idx]
+}
+
+// check range of bulk memory or table operation
+func checkRange(start, count uint64, size int, msg string) {
+	if start+count > uint64(size) {
+		panic(trap(msg))
+	}
+}
+
+// invoke function by index with arguments on stack
+func (in *Instance) invoke(idx uint32) {
+	m := in.module
+	if int(idx) < m.numImport {
+		im := m.imports[idx]
+		panic(trap("imported function " + im.module + "." + im.name + " is not available"))
+	}
+	f := m.funcs[idx-uint32(m.numImport)]
+	ft := &m.types[f.typ]
+	if in.depth++; in.depth > maxCallDepth {
+		panic(trap("call stack exhausted"))
+	}
+	locals := make([]uint64, len(ft.params)+len(f.locals))
+	sp := len(in.stack) - len(ft.params)
+	copy(locals, in.stack[sp:])
+	in.stack = in.stack[:sp]
+	for i, tp := range f.locals {
+		if tp == valueFuncRef || tp == valueExternRef {
+			locals[len(ft.params)+i] = nullRef
+		}
+	}
+	in.exec(f.code, locals, len(ft.results))
+	in.depth--
+}
+
+func sameType(a, b *funcType) bool {
+	return string(a.params) == string(b.params) && string(a.results) == string(b.results)
+}
+
+func (in *Instance) exec(code []instr, locals []uint64, arity int) {
+	base := len(in.stack)
+	var labels []label
+	// branch to label by depth, false is returned if branch out of function
+	branch := func(depth uint64, pc *int) bool {
+		if depth >= uint64(len(labels)) {
+			in.unwind(base, arity)
+			return false
+		}
+		l := labels[len(labels)-1-int(depth)]
+		labels = labels[:len(labels)-1-int(depth)]
+		in.unwind(l.height, l.arity)
+		*pc = l.target
+		return true
+	}
+
+	for pc := 0; pc < len(code); pc++ {
+		if in.fuel--; in.fuel < 0 {
+			panic(trap("instructions exceed limit"))
+		}
+		ins := &code[pc]
+		switch ins.op {
+		case 0x00:
+			panic(trap("unreachable"))
+		case 0x01:
+			// nop
+		case 0x02:
+			// branch to block jumps to its end with label removed
+			labels = append(labels, label{target: int(ins.b), height: len(in.stack) - int(ins.d), arity: int(ins.c)})
+		case 0x03:
+			// branch to loop executes the loop instruction again
+			labels = append(labels, label{target: pc - 1, height: len(in.stack) - int(ins.d), arity: int(ins.c)})
+		case 0x04:
+			cond := uint32(in.pop())
+			if cond == 0 && ins.a == uint64(ins.b) {
+				// skip if without else
+				pc = int(ins.b)
+				break
+			}
+			labels = append(labels, label{target: int(ins.b), height: len(in.stack) - int(ins.d), arity: int(ins.c)})
+			if cond == 0 {
+				pc = int(ins.a)
+			}
+		case 0x05:
+			// reach else from then block
+			labels = labels[:len(labels)-1]
+			pc = int(ins.b)
+		case 0x0b:
+			if len(labels) <= 0 {
+				return
+			}
+			labels = labels[:len(labels)-1]
+		case 0x0c:
+			if !branch(ins.a, &pc) {
+				return
+			}
+		case 0x0d:
+			if uint32(in.pop()) != 0 && !branch(ins.a, &pc) {
+				return
+			}
+		case 0x0e:
+			depth := ins.a
+			if i := uint32(in.pop()); int(i) < len(ins.tbl) {
+				depth = uint64(ins.tbl[i])
+			}
+			if !branch(depth, &pc) {
+				return
+			}
+		case 0x0f:
+			in.unwind(base, arity)
+			return
+		case 0x10:
+			in.invoke(uint32(ins.a))
+		case 0x11:
+			tbl := in.table(ins.b)
+			i := uint32(in.pop())
+			if int(i) >= len(tbl) {
+				panic(trap("undefined element"))
+			}
+			ref := tbl[i]
+			if ref == nullRef {
+				panic(trap("uninitialized element"))
+			}
+			ft, ok := in.module.funcType(uint32(ref))
+			if !ok || !sameType(ft, &in.module.types[ins.a]) {
+				panic(trap("indirect call type mismatch"))
+			}
+			in.invoke(uint32(ref))
+		case 0x1a:
+			in.pop()
+		case 0x1b, 0x1c:
+			cond := uint32(in.pop())
+			v2 := in.pop()
+			if cond == 0 {
+				in.stack[len(in.stack)-1] = v2
+			}
+		case 0x20:
+			in.push(locals[ins.a])
+		case 0x21:
+			locals[ins.a] = in.pop()
+		case 0x22:
+			locals[ins.a] = in.stack[len(in.stack)-1]
+		case 0x23:
+			in.push(in.globals[ins.a])
+		case 0x24:
+			in.globals[ins.a] = in.pop()
+		case 0x25:
+			tbl := in.table(uint32(ins.a))
+			i := uint32(in.pop())
+			checkRange(uint64(i), 1, len(tbl), "out of bounds table access")
+			in.push(tbl[i])
+		case 0x26:
+			tbl := in.table(uint32(ins.a))
+			v := in.pop()
+			i := uint32(in.pop())
+			checkRange(uint64(i), 1, len(tbl), "out of bounds table access")
+			tbl[i] = v
+
+		// load and store in little endian
+		case 0x28, 0x2a, 0x35:
+			ea := in.addr(in.pop(), ins.a, 4)
+			in.push(uint64(uint32(in.memory[ea]) | uint32(in.memory[ea+1])<<8 | uint32(in.memory[ea+2])<<16 | uint32(in.memory[ea+3])<<24))
+		case 0x29, 0x2b:
+			ea := in.addr(in.pop(), ins.a, 8)
+			var v uint64
+			for i := uint64(8); i > 0; i-- {
+				v = v<<8 | uint64(in.memory[ea+i-1])
+			}
+			in.push(v)
+		case 0x2c:
+			ea := in.addr(in.pop(), ins.a, 1)
+			in.push(uint64(uint32(int32(int8(in.memory[ea])))))
+		case 0x2d, 0x31:
+			ea := in.addr(in.pop(), ins.a, 1)
+			in.push(uint64(in.memory[ea]))
+		case 0x2e:
+			ea := in.addr(in.pop(), ins.a, 2)
+			in.push(uint64(uint32(int32(int16(uint16(in.memory[ea]) | uint16(in.memory[ea+1])<<8)))))
+		case 0x2f, 0x33:
+			ea := in.addr(in.pop(), ins.a, 2)
+			in.push(uint64(in.memory[ea]) | uint64(in.memory[ea+1])<<8)
+		case 0x30:
+			ea := in.addr(in.pop(), ins.a, 1)
+			in.push(uint64(int64(int8(in.memory[ea]))))
+		case 0x32:
+			ea := in.addr(in.pop(), ins.a, 2)
+			in.push(uint64(int64(int16(uint16(in.memory[ea]) | uint16(in.memory[ea+1])<<8))))
+		case 0x34:
+			ea := in.addr(in.pop(), ins.a, 4)
+			in.push(uint64(int64(int32(uint32(in.memory[ea]) | uint32(in.memory[ea+1])<<8 | uint32(in.memory[ea+2])<<16 | uint32(in.memory[ea+3])<<24))))
+		case 0x36, 0x38, 0x3e:
+			in.store(ins.a, 4)
+		case 0x37, 0x39:
+			in.store(ins.a, 8)
+		case 0x3a, 0x3c:
+			in.store(ins.a, 1)
+		case 0x3b, 0x3d:
+			in.store(ins.a, 2)
+		case 0x3f:
+			in.push(uint64(len(in.memory) / pageSize))
+		case 0x40:
+			in.push(in.grow(uint32(in.pop())))
+
+		// constants are compiled in bits
+		case 0x41, 0x42, 0x43, 0x44:
+			in.push(ins.a)
+
+		// i32 comparisons
+		case 0x45:
+			in.pushBool(uint32(in.pop()) == 0)
+		case 0x46, 0x47, 0x48, 0x49, 0x4a, 0x4b, 0x4c, 0x4d, 0x4e, 0x4f:
+			b, a := uint32(in.pop()), uint32(in.pop())
+			in.pushBool(compareI32(ins.op, a, b))
+
+		// i64 comparisons
+		case 0x50:
+			in.pushBool(in.pop() == 0)
+		case 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, 0x59, 0x5a:
+			b, a := in.pop(), in.pop()
+			in.pushBool(compareI64(ins.op, a, b))
+
+		// float comparisons
+		case 0x5b, 0x5c, 0x5d, 0x5e, 0x5f, 0x60:
+			b, a := in.popF32(), in.popF32()
+			in.pushBool(compareFloat(ins.op-0x5b, float64(a), float64(b)))
+		case 0x61, 0x62, 0x63, 0x64, 0x65, 0x66:
+			b, a := in.popF64(), in.popF64()
+			in.pushBool(compareFloat(ins.op-0x61, a, b))
+
+		// i32 arithmetic
+		case 0x67:
+			in.push(uint64(bits.LeadingZeros32(uint32(in.pop()))))
+		case 0x68:
+			in.push(uint64(bits.TrailingZeros32(uint32(in.pop()))))
+		case 0x69:
+			in.push(uint64(bits.OnesCount32(uint32(in.pop()))))
+		case 0x6a, 0x6b, 0x6c, 0x6d, 0x6e, 0x6f, 0x70, 0x71, 0x72, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78:
+			b, a := uint32(in.pop()), uint32(in.pop())
+			in.push(uint64(arithI32(ins.op, a, b)))
+
+		// i64 arithmetic
+		case 0x79:
+			in.push(uint64(bits.LeadingZeros64(in.pop())))
+		case 0x7a:
+			in.push(uint64(bits.TrailingZeros64(in.pop())))
+		case 0x7b:
+			in.push(uint64(bits.OnesCount64(in.pop())))
+		case 0x7c, 0x7d, 0x7e, 0x7f, 0x80, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89, 0x8a:
+			b, a := in.pop(), in.pop()
+			in.push(arithI64(ins.op, a, b))
+
+		// f32 arithmetic
+		case 0x8b:
+			in.push(in.pop() &^ (1 << 31))
+		case 0x8c:
+			in.push(in.pop() ^ (1 << 31))
+		case 0x8d, 0x8e, 0x8f, 0x90, 0x91:
+			in.pushF32(float32(unaryFloat(ins.op-0x8d, float64(in.popF32()))))
+		case 0x92, 0x93, 0x94, 0x95, 0x96, 0x97, 0x98:
+			b, a := in.popF32(), in.popF32()
+			switch ins.op {
+			case 0x92:
+				in.pushF32(a + b)
+			case 0x93:
+				in.pushF32(a - b)
+			case 0x94:
+				in.pushF32(a * b)
+			case 0x95:
+				in.pushF32(a / b)
+			default:
+				in.pushF32(float32(binaryFloat(ins.op-0x96, float64(a), float64(b))))
+			}
+
+		// f64 arithmetic
+		case 0x99:
+			in.push(in.pop() &^ (1 << 63))
+		case 0x9a:
+			in.push(in.pop() ^ (1 << 63))
+		case 0x9b, 0x9c, 0x9d, 0x9e, 0x9f:
+			in.pushF64(unaryFloat(ins.op-0x9b, in.popF64()))
+		case 0xa0, 0xa1, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6:
+			b, a := in.popF64(), in.popF64()
+			switch ins.op {
+			case 0xa0:
+				in.pushF64(a + b)
+			case 0xa1:
+				in.pushF64(a - b)
+			case 0xa2:
+				in.pushF64(a * b)
+			case 0xa3:
+				in.pushF64(a / b)
+			default:
+				in.pushF64(binaryFloat(ins.op-0xa4, a, b))
+			}
+
+		// conversions
+		case 0xa7:
+			in.push(uint64(uint32(in.pop())))
+		case 0xa8:
+			in.push(truncI32(float64(in.popF32()), true, false))
+		case 0xa9:
+			in.push(truncI32(float64(in.popF32()), false, false))
+		case 0xaa:
+			in.push(truncI32(in.popF64(), true, false))
+		case 0xab:
+			in.push(truncI32(in.popF64(), false, false))
+		case 0xac:
+			in.push(uint64(int64(int32(uint32(in.pop())))))
+		case 0xad:
+			in.push(uint64(uint32(in.pop())))
+		case 0xae:
+			in.push(truncI64(float64(in.popF32()), true, false))
+		case 0xaf:
+			in.push(truncI64(float64(in.popF32()), false, false))
+		case 0xb0:
+			in.push(truncI64(in.popF64(), true, false))
+		case 0xb1:
+			in.push(truncI64(in.popF64(), false, false))
+		case 0xb2:
+			in.pushF32(float32(int32(uint32(in.pop()))))
+		case 0xb3:
+			in.pushF32(float32(uint32(in.pop())))
+		case 0xb4:
+			in.pushF32(float32(int64(in.pop())))
+		case 0xb5:
+			in.pushF32(float32(in.pop()))
+		case 0xb6:
+			in.pushF32(float32(in.popF64()))
+		case 0xb7:
+			in.pushF64(float64(int32(uint32(in.pop()))))
+		case 0xb8:
+			in.pushF64(float64(uint32(in.pop())))
+		case 0xb9:
+			in.pushF64(float64(int64(in.pop())))
+		case 0xba:
+			in.pushF64(float64(in.pop()))
+		case 0xbb:
+			in.pushF64(float64(in.popF32()))
+		case 0xbc, 0xbd, 0xbe, 0xbf:
+			// reinterpret keeps bits
+		case 0xc0:
+			in.push(uint64(uint32(int32(int8(in.pop())))))
+		case 0xc1:
+			in.push(uint64(uint32(int32(int16(in.pop())))))
+		case 0xc2:
+			in.push(uint64(int64(int8(in.pop()))))
+		case 0xc3:
+			in.push(uint64(int64(int16(in.pop()))))
+		case 0xc4:
+			in.push(uint64(int64(int32(in.pop()))))
+
+		// references
+		case 0xd0:
+			in.push(nullRef)
+		case 0xd1:
+			in.pushBool(in.pop() == nullRef)
+		case 0xd2:
+			in.push(ins.a)
+
+		// saturating truncation
+		case opPrefix<<8 | 0:
+			in.push(truncI32(float64(in.popF32()), true, true))
+		case opPrefix<<8 | 1:
+			in.push(truncI32(float64(in.popF32()), false, true))
+		case opPrefix<<8 | 2:
+			in.push(truncI32(in.popF64(), true, true))
+		case opPrefix<<8 | 3:
+			in.push(truncI32(in.popF64(), false, true))
+		case opPrefix<<8 | 4:
+			in.push(truncI64(float64(in.popF32()), true, true))
+		case opPrefix<<8 | 5:
+			in.push(truncI64(float64(in.popF32()), false, true))
+		case opPrefix<<8 | 6:
+			in.push(truncI64(in.popF64(), true, true))
+		case opPrefix<<8 | 7:
+			in.push(truncI64(in.popF64(), false, true))
+
+		// bulk memory and table operations
+		case opPrefix<<8 | 8:
+			n, s, d := uint64(uint32(in.pop())), uint64(uint32(in.pop())), uint64(uint32(in.pop()))
+			data := in.datas[ins.a]
+			checkRange(s, n, len(data), "out of bounds memory access")
+			checkRange(d, n, len(in.memory), "out of bounds memory access")
+			copy(in.memory[d:], data[s:s+n])
+		case opPrefix<<8 | 9:
+			in.datas[ins.a] = nil
+		case opPrefix<<8 | 10:
+			n, s, d := uint64(uint32(in.pop())), uint64(uint32(in.pop())), uint64(uint32(in.pop()))
+			checkRange(s, n, len(in.memory), "out of bounds memory access")
+			checkRange(d, n, len(in.memory), "out of bounds memory access")
+			copy(in.memory[d:], in.memory[s:s+n])
+		case opPrefix<<8 | 11:
+			n, v, d := uint64(uint32(in.pop())), byte(in.pop()), uint64(uint32(in.pop()))
+			checkRange(d, n, len(in.memory), "out of bounds memory access")
+			fill := in.memory[d : d+n]
+			for i := range fill {
+				fill[i] = v
+			}
+		case opPrefix<<8 | 12:
+			tbl := in.table(ins.b)
+			n, s, d := uint64(uint32(in.pop())), uint64(uint32(in.pop())), uint64(uint32(in.pop()))
+			elem := in.elems[ins.a]
+			checkRange(s, n, len(elem), "out of bounds table access")
+			checkRange(d, n, len(tbl), "out of bounds table access")
+			copy(tbl[d:], elem[s:s+n])
+		case opPrefix<<8 | 13:
+			in.elems[ins.a] = nil
+		case opPrefix<<8 | 14:
+			dst, src := in.table(uint32(ins.a)), in.table(ins.b)
+			n, s, d := uint64(uint32(in.pop())), uint64(uint32(in.pop())), uint64(uint32(in.pop()))
+			checkRange(s, n, len(src), "out of bounds table access")
+			checkRange(d, n, len(dst), "out of bounds table access")
+			copy(dst[d:], src[s:s+n])
+		case opPrefix<<8 | 15:
+			n := uint32(in.pop())
+			v := in.pop()
+			in.push(in.growTable(uint32(ins.a), n, v))
+		case opPrefix<<8 | 16:
+			in.push(uint64(len(in.table(uint32(ins.a)))))
+		case opPrefix<<8 | 17:
+			tbl := in.table(uint32(ins.a))
+			n, v, d := uint64(uint32(in.pop())), in.pop(), uint64(uint32(in.pop()))
+			checkRange(d, n, len(tbl), "out of bounds table access")
+			for i := d; i < d+n; i++ {
+				tbl[i] = v
+			}
+		default:
+			panic(trap("unsupported instruction"))
+		}
+	}
+}
+
+// store value of size bytes in little endian
+func (in *Instance) store(offset uint64, size uint64) {
+	v := in.pop()
+	ea := in.addr(in.pop(), offset, size)
+	for i := uint64(0); i < size; i++ {
+		in.memory[ea+i] = byte(v >> (8 * i))
+	}
+}
+
+// grow memory in pages, previous size is returned or -1 if failed
+func (in *Instance) grow(delta uint32) uint64 {
+	pages := uint32(len(in.memory) / pageSize)
+	if uint64(pages)+uint64(delta) > uint64(in.maxPages) {
+		return math.MaxUint32
+	}
+	in.memory = append(in.memory, make([]byte, int(delta)*pageSize)...)
+	return uint64(pages)
+}
+
+// grow table with initial value, previous size is returned or -1 if failed
+func (in *Instance) growTable(idx uint32, delta uint32, v uint64) uint64 {
+	tbl := in.table(idx)
+	limit := uint64(maxTableSize)
+	if l := in.module.tables[idx]; l.hasMax && uint64(l.max) < limit {
+		limit = uint64(l.max)
+	}
+	size := uint64(len(tbl))
+	if size+uint64(delta) > limit {
+		return math.MaxUint32
+	}
+	for i := uint32(0); i < delta; i++ {
+		tbl = append(tbl, v)
+	}
+	in.tables[idx] = tbl
+	return size
+}
+
+func compareI32(op uint16, a, b uint32) bool {
+	switch op {
+	case 0x46:
+		return a == b
+	case 0x47:
+		return a != b
+	case 0x48:
+		return int32(a) < int32(b)
+	case 0x49:
+		return a < b
+	case 0x4a:
+		return int32(a) > int32(b)
+	case 0x4b:
+		return a > b
+	case 0x4c:
+		return int32(a) <= int32(b)
+	case 0x4d:
+		return a <= b
+	case 0x4e:
+		return int32(a) >= int32(b)
+	default:
+		return a >= b
+	}
+}
+
+func compareI64(op uint16, a, b uint64) bool {
+	switch op {
+	case 0x51:
+		return a == b
+	case 0x52:
+		return a != b
+	case 0x53:
+		return int64(a) < int64(b)
+	case 0x54:
+		return a < b
+	case 0x55:
+		return int64(a) > int64(b)
+	case 0x56:
+		return a > b
+	case 0x57:
+		return int64(a) <= int64(b)
+	case 0x58:
+		return a <= b
+	case 0x59:
+		return int64(a) >= int64(b)
+	default:
+		return a >= b
+	}
+}
+
+// compare floats by offset of eq, ne, lt, gt, le and ge
+func compareFloat(op uint16, a, b float64) bool {
+	switch op {
+	case 0:
+		return a == b
+	case 1:
+		return a != b
+	case 2:
+		return a < b
+	case 3:
+		return a > b
+	case 4:
+		return a <= b
+	default:
+		return a >= b
+	}
+}
+
+func arithI32(op uint16, a, b uint32) uint32 {
+	switch op {
+	case 0x6a:
+		return a + b
+	case 0x6b:
+		return a - b
+	case 0x6c:
+		return a * b
+	case 0x6d:
+		if b == 0 {
+			panic(trap("integer divide by zero"))
+		}
+		if int32(a) == math.MinInt32 && int32(b) == -1 {
+			panic(trap("integer overflow"))
+		}
+		return uint32(int32(a) / int32(b))
+	case 0x6e:
+		if b == 0 {
+			panic(trap("integer divide by zero"))
+		}
+		return a / b
+	case 0x6f:
+		if b == 0 {
+			panic(trap("integer divide by zero"))
+		}
+		if int32(b) == -1 {
+			return 0
+		}
+		return uint32(int32(a) % int32(b))
+	case 0x70:
+		if b == 0 {
+			panic(trap("integer divide by zero"))
+		}
+		return a % b
+	case 0x71:
+		return a & b
+	case 0x72:
+		return a | b
+	case 0x73:
+		return a ^ b
+	case 0x74:
+		return a << (b & 31)
+	case 0x75:
+		return uint32(int32(a) >> (b & 31))
+	case 0x76:
+		return a >> (b & 31)
+	case 0x77:
+		return bits.RotateLeft32(a, int(b&31))
+	default:
+		return bits.RotateLeft32(a, -int(b&31))
+	}
+}
+
+func arithI64(op uint16, a, b uint64) uint64 {
+	switch op {
+	case 0x7c:
+		return a + b
+	case 0x7d:
+		return a - b
+	case 0x7e:
+		return a * b
+	case 0x7f:
+		if b == 0 {
+			panic(trap("integer divide by zero"))
+		}
+		if int64(a) == math.MinInt64 && int64(b) == -1 {
+			panic(trap("integer overflow"))
+		}
+		return uint64(int64(a) / int64(b))
+	case 0x80:
+		if b == 0 {
+			panic(trap("integer divide by zero"))
+		}
+		return a / b
+	case 0x81:
+		if b == 0 {
+			panic(trap("integer divide by zero"))
+		}
+		if int64(b) == -1 {
+			return 0
+		}
+		return uint64(int64(a) % int64(b))
+	case 0x82:
+		if b == 0 {
+			panic(trap("integer divide by zero"))
+		}
+		return a % b
+	case 0x83:
+		return a & b
+	case 0x84:
+		return a | b
+	case 0x85:
+		return a ^ b
+	case 0x86:
+		return a << (b & 63)
+	case 0x87:
+		return uint64(int64(a) >> (b & 63))
+	case 0x88:
+		return a >> (b & 63)
+	case 0x89:
+		return bits.RotateLeft64(a, int(b&63))
+	default:
+		return bits.RotateLeft64(a, -int(b&63))
+	}
+}
+
+// unary float operation by offset of ceil, floor, trunc, nearest and sqrt
+func unaryFloat(op uint16, f float64) float64 {
+	switch op {
+	case 0:
+		return math.Ceil(f)
+	case 1:
+		return math.Floor(f)
+	case 2:
+		return math.Trunc(f)
+	case 3:
+		return math.RoundToEven(f)
+	default:
+		return math.Sqrt(f)
+	}
+}
+
+// binary float operation by offset of min, max and copysign
+func binaryFloat(op uint16, a, b float64) float64 {
+	switch op {
+	case 0:
+		return math.Min(a, b)
+	case 1:
+		return math.Max(a, b)
+	default:
+		return math.Copysign(a, b)
+	}
+}
+
+// truncate float to i32, out of range values are saturated or trapped
+func truncI32(f float64, signed, saturate bool) uint64 {
+	lower, upper := float64(0), float64(math.MaxUint32)
+	if signed {
+		lower, upper = math.MinInt32, math.MaxInt32
+	}
+	t := math.Trunc(f)
+	switch {
+	case math.IsNaN(f):
+		if !saturate {
+			panic(trap("invalid conversion to integer"))
+		}
+		return 0
+	case t < lower || t > upper:
+		if !saturate {
+			panic(trap("integer overflow"))
+		}
+		t = math.Max(lower, math.Min(upper, t))
+	}
+	if signed {
+		return uint64(uint32(int32(t)))
+	}
+	return uint64(uint32(t))
+}
+
+// truncate float to i64, out of range values are saturated or trapped
+func truncI64(f float64, signed, saturate bool) uint64 {
+	t := math.Trunc(f)
+	if math.IsNaN(f) {
+		if !saturate {
+			panic(trap("invalid conversion to integer"))
+		}
+		return 0
+	}
+	if signed {
+		// bounds are exactly -2^63 and 2^63
+		switch {
+		case t < math.MinInt64:
+			if !saturate {
+				panic(trap("integer overflow"))
+			}
+			return 1 << 63
+		case t >= 1<<63:
+			if !saturate {
+				panic(trap("integer overflow"))
+			}
+			return math.MaxInt64
+		}
+		return uint64(int64(t))
+	}
+	switch {
+	case t < 0:
+		if !saturate {
+			panic(trap("integer overflow"))
+		}
+		return 0
+	case t >= 1<<64:
+		if !saturate {
+			panic(trap("integer overflow"))
+		}
+		return math.MaxUint64
+	}
+	return uint64(t)
+}
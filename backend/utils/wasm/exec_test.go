@@ -0,0 +1,397 @@
+package wasmutil
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func f32(f float32) uint64 {
+	return uint64(math.Float32bits(f))
+}
+
+func f64(f float64) uint64 {
+	return math.Float64bits(f)
+}
+
+func i32(n int32) uint64 {
+	return uint64(uint32(n))
+}
+
+func i64(n int64) uint64 {
+	return uint64(n)
+}
+
+// call exported "f" of module built from functions, with memory of one page
+func callFunc(t *testing.T, args []uint64, funcs ...testFunc) ([]uint64, error) {
+	t.Helper()
+	m, err := Compile(buildModule(1, 2, funcs...))
+	if err != nil {
+		t.Fatalf("compile fail: %s", err)
+	}
+	in, err := m.Instantiate()
+	if err != nil {
+		t.Fatalf("instantiate fail: %s", err)
+	}
+	return in.Call("f", args...)
+}
+
+// binary operation on two params
+func binaryOp(param, result byte, op ...byte) testFunc {
+	return testFunc{
+		params:  []byte{param, param},
+		results: []byte{result},
+		body:    append(append([]byte{0x20, 0, 0x20, 1}, op...), 0x0b),
+	}
+}
+
+// unary operation on one param
+func unaryOp(param, result byte, op ...byte) testFunc {
+	return testFunc{
+		params:  []byte{param},
+		results: []byte{result},
+		body:    append(append([]byte{0x20, 0}, op...), 0x0b),
+	}
+}
+
+func TestOpcodes(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   testFunc
+		args []uint64
+		want uint64
+	}{
+		// i32
+		{"i32.add", binaryOp(valueI32, valueI32, 0x6a), []uint64{1, 2}, 3},
+		{"i32.add wraps", binaryOp(valueI32, valueI32, 0x6a), []uint64{math.MaxUint32, 2}, 1},
+		{"i32.sub wraps", binaryOp(valueI32, valueI32, 0x6b), []uint64{0, 1}, math.MaxUint32},
+		{"i32.mul", binaryOp(valueI32, valueI32, 0x6c), []uint64{i32(-3), 7}, i32(-21)},
+		{"i32.div_s", binaryOp(valueI32, valueI32, 0x6d), []uint64{i32(-7), 2}, i32(-3)},
+		{"i32.div_u", binaryOp(valueI32, valueI32, 0x6e), []uint64{i32(-7), 2}, 0x7ffffffc},
+		{"i32.rem_s", binaryOp(valueI32, valueI32, 0x6f), []uint64{i32(-7), 2}, i32(-1)},
+		{"i32.rem_s overflow", binaryOp(valueI32, valueI32, 0x6f), []uint64{i32(math.MinInt32), i32(-1)}, 0},
+		{"i32.rem_u", binaryOp(valueI32, valueI32, 0x70), []uint64{7, 3}, 1},
+		{"i32.and", binaryOp(valueI32, valueI32, 0x71), []uint64{0b1100, 0b1010}, 0b1000},
+		{"i32.or", binaryOp(valueI32, valueI32, 0x72), []uint64{0b1100, 0b1010}, 0b1110},
+		{"i32.xor", binaryOp(valueI32, valueI32, 0x73), []uint64{0b1100, 0b1010}, 0b0110},
+		{"i32.shl masks count", binaryOp(valueI32, valueI32, 0x74), []uint64{1, 33}, 2},
+		{"i32.shr_s", binaryOp(valueI32, valueI32, 0x75), []uint64{i32(-8), 1}, i32(-4)},
+		{"i32.shr_u", binaryOp(valueI32, valueI32, 0x76), []uint64{i32(-8), 1}, 0x7ffffffc},
+		{"i32.rotl", binaryOp(valueI32, valueI32, 0x77), []uint64{0x80000001, 1}, 3},
+		{"i32.rotr", binaryOp(valueI32, valueI32, 0x78), []uint64{3, 1}, 0x80000001},
+		{"i32.clz of zero", unaryOp(valueI32, valueI32, 0x67), []uint64{0}, 32},
+		{"i32.ctz", unaryOp(valueI32, valueI32, 0x68), []uint64{8}, 3},
+		{"i32.popcnt", unaryOp(valueI32, valueI32, 0x69), []uint64{0xff}, 8},
+		{"i32.eqz", unaryOp(valueI32, valueI32, 0x45), []uint64{0}, 1},
+		{"i32.lt_s", binaryOp(valueI32, valueI32, 0x48), []uint64{i32(-1), 1}, 1},
+		{"i32.lt_u", binaryOp(valueI32, valueI32, 0x49), []uint64{i32(-1), 1}, 0},
+		{"i32.ge_s", binaryOp(valueI32, valueI32, 0x4e), []uint64{1, 1}, 1},
+		{"i32.extend8_s", unaryOp(valueI32, valueI32, 0xc0), []uint64{0x80}, i32(-128)},
+		{"i32.extend16_s", unaryOp(valueI32, valueI32, 0xc1), []uint64{0x8000}, i32(-32768)},
+
+		// i64
+		{"i64.add", binaryOp(valueI64, valueI64, 0x7c), []uint64{math.MaxUint64, 2}, 1},
+		{"i64.div_s", binaryOp(valueI64, valueI64, 0x7f), []uint64{i64(-9), 2}, i64(-4)},
+		{"i64.rem_s overflow", binaryOp(valueI64, valueI64, 0x81), []uint64{i64(math.MinInt64), i64(-1)}, 0},
+		{"i64.shr_s", binaryOp(valueI64, valueI64, 0x87), []uint64{i64(-16), 66}, i64(-4)},
+		{"i64.rotr", binaryOp(valueI64, valueI64, 0x8a), []uint64{1, 1}, 1 << 63},
+		{"i64.clz", unaryOp(valueI64, valueI64, 0x79), []uint64{1}, 63},
+		{"i64.eqz", unaryOp(valueI64, valueI32, 0x50), []uint64{0}, 1},
+		{"i64.gt_u", binaryOp(valueI64, valueI32, 0x56), []uint64{i64(-1), 1}, 1},
+		{"i64.extend_i32_s", unaryOp(valueI32, valueI64, 0xac), []uint64{i32(-1)}, math.MaxUint64},
+		{"i64.extend_i32_u", unaryOp(valueI32, valueI64, 0xad), []uint64{i32(-1)}, math.MaxUint32},
+		{"i32.wrap_i64", unaryOp(valueI64, valueI32, 0xa7), []uint64{0x1_0000_0005}, 5},
+
+		// floats
+		{"f32.add", binaryOp(valueF32, valueF32, 0x92), []uint64{f32(1.5), f32(2.25)}, f32(3.75)},
+		{"f32.div", binaryOp(valueF32, valueF32, 0x95), []uint64{f32(1), f32(4)}, f32(0.25)},
+		{"f32.min", binaryOp(valueF32, valueF32, 0x96), []uint64{f32(-1), f32(2)}, f32(-1)},
+		{"f32.neg", unaryOp(valueF32, valueF32, 0x8c), []uint64{f32(2)}, f32(-2)},
+		{"f32.abs", unaryOp(valueF32, valueF32, 0x8b), []uint64{f32(-2)}, f32(2)},
+		{"f64.sub", binaryOp(valueF64, valueF64, 0xa1), []uint64{f64(1), f64(0.25)}, f64(0.75)},
+		{"f64.max", binaryOp(valueF64, valueF64, 0xa5), []uint64{f64(-1), f64(2)}, f64(2)},
+		{"f64.copysign", binaryOp(valueF64, valueF64, 0xa6), []uint64{f64(3), f64(math.Copysign(0, -1))}, f64(-3)},
+		{"f64.nearest rounds to even", unaryOp(valueF64, valueF64, 0x9e), []uint64{f64(2.5)}, f64(2)},
+		{"f64.sqrt", unaryOp(valueF64, valueF64, 0x9f), []uint64{f64(16)}, f64(4)},
+		{"f64.lt", binaryOp(valueF64, valueI32, 0x63), []uint64{f64(1), f64(2)}, 1},
+		{"f64.ne with nan", binaryOp(valueF64, valueI32, 0x62), []uint64{f64(math.NaN()), f64(math.NaN())}, 1},
+		{"f64.convert_i32_s", unaryOp(valueI32, valueF64, 0xb7), []uint64{i32(-2)}, f64(-2)},
+		{"f64.convert_i64_u", unaryOp(valueI64, valueF64, 0xba), []uint64{1 << 63}, f64(1 << 63)},
+		{"f64.promote_f32", unaryOp(valueF32, valueF64, 0xbb), []uint64{f32(0.5)}, f64(0.5)},
+		{"i32.trunc_f64_s", unaryOp(valueF64, valueI32, 0xaa), []uint64{f64(-3.9)}, i32(-3)},
+		{"i64.trunc_f32_u", unaryOp(valueF32, valueI64, 0xaf), []uint64{f32(3.9)}, 3},
+		{"i32.reinterpret_f32", unaryOp(valueF32, valueI32, 0xbc), []uint64{f32(1)}, 0x3f800000},
+
+		// saturating truncation
+		{"i32.trunc_sat_f64_s overflow", unaryOp(valueF64, valueI32, opPrefix, 2), []uint64{f64(3e9)}, math.MaxInt32},
+		{"i32.trunc_sat_f64_s underflow", unaryOp(valueF64, valueI32, opPrefix, 2), []uint64{f64(-3e9)}, i32(math.MinInt32)},
+		{"i32.trunc_sat_f64_u negative", unaryOp(valueF64, valueI32, opPrefix, 3), []uint64{f64(-1)}, 0},
+		{"i32.trunc_sat_f32_s nan", unaryOp(valueF32, valueI32, opPrefix, 0), []uint64{f32(float32(math.NaN()))}, 0},
+		{"i64.trunc_sat_f64_s overflow", unaryOp(valueF64, valueI64, opPrefix, 6), []uint64{f64(1e19)}, math.MaxInt64},
+		{"i64.trunc_sat_f64_u overflow", unaryOp(valueF64, valueI64, opPrefix, 7), []uint64{f64(1e20)}, math.MaxUint64},
+
+		// parametric
+		{"select first", testFunc{
+			params:  []byte{valueI32, valueI32, valueI32},
+			results: []byte{valueI32},
+			body:    []byte{0x20, 0, 0x20, 1, 0x20, 2, 0x1b, 0x0b},
+		}, []uint64{10, 20, 1}, 10},
+		{"select second", testFunc{
+			params:  []byte{valueI32, valueI32, valueI32},
+			results: []byte{valueI32},
+			body:    []byte{0x20, 0, 0x20, 1, 0x20, 2, 0x1b, 0x0b},
+		}, []uint64{10, 20, 0}, 20},
+		{"local.tee", testFunc{
+			params:  []byte{valueI32},
+			results: []byte{valueI32},
+			locals:  []byte{valueI32},
+			body:    []byte{0x20, 0, 0x22, 1, 0x20, 1, 0x6a, 0x0b},
+		}, []uint64{21}, 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := callFunc(t, tt.args, tt.fn)
+			if err != nil {
+				t.Fatalf("call fail: %s", err)
+			}
+			if len(results) != 1 || results[0] != tt.want {
+				t.Errorf("expect 0x%x, got %x", tt.want, results)
+			}
+		})
+	}
+}
+
+func TestControlFlow(t *testing.T) {
+	// sum from 1 to n by loop
+	sum := testFunc{
+		params:  []byte{valueI32},
+		results: []byte{valueI32},
+		locals:  []byte{valueI32},
+		body: []byte{
+			0x02, 0x40, // block
+			0x03, 0x40, // loop
+			0x20, 0, 0x45, 0x0d, 1, // br_if 1 if n == 0
+			0x20, 1, 0x20, 0, 0x6a, 0x21, 1, // acc += n
+			0x20, 0, 0x41, 1, 0x6b, 0x21, 0, // n -= 1
+			0x0c, 0, // br 0
+			0x0b, 0x0b,
+			0x20, 1,
+			0x0b,
+		},
+	}
+	// if with else carrying result
+	choose := testFunc{
+		params:  []byte{valueI32},
+		results: []byte{valueI32},
+		body:    []byte{0x20, 0, 0x04, valueI32, 0x41, 1, 0x05, 0x41, 2, 0x0b, 0x0b},
+	}
+	// if without else
+	skip := testFunc{
+		params:  []byte{valueI32},
+		results: []byte{valueI32},
+		locals:  []byte{valueI32},
+		body:    []byte{0x41, 7, 0x21, 1, 0x20, 0, 0x04, 0x40, 0x41, 9, 0x21, 1, 0x0b, 0x20, 1, 0x0b},
+	}
+	// br_table selects among nested blocks, out of range index goes to default
+	table := testFunc{
+		params:  []byte{valueI32},
+		results: []byte{valueI32},
+		body: []byte{
+			0x02, 0x40, 0x02, 0x40, 0x02, 0x40,
+			0x20, 0, 0x0e, 2, 0, 1, 2, // br_table [0 1] default 2
+			0x0b, 0x41, 10, 0x0f,
+			0x0b, 0x41, 11, 0x0f,
+			0x0b, 0x41, 12,
+			0x0b,
+		},
+	}
+	// branch out of block carries value and drops others on stack
+	carry := testFunc{
+		results: []byte{valueI32},
+		body:    []byte{0x02, valueI32, 0x41, 1, 0x41, 2, 0x41, 3, 0x0c, 0, 0x0b, 0x0b},
+	}
+	// return from nested block
+	early := testFunc{
+		results: []byte{valueI32},
+		body:    []byte{0x02, 0x40, 0x03, 0x40, 0x41, 5, 0x0f, 0x0b, 0x0b, 0x41, 6, 0x0b},
+	}
+	// call another function
+	caller := testFunc{
+		params:  []byte{valueI32},
+		results: []byte{valueI32},
+		body:    []byte{0x20, 0, 0x10, 1, 0x41, 1, 0x6a, 0x0b},
+	}
+	double := testFunc{
+		params:  []byte{valueI32},
+		results: []byte{valueI32},
+		body:    []byte{0x20, 0, 0x20, 0, 0x6a, 0x0b},
+	}
+
+	tests := []struct {
+		name  string
+		funcs []testFunc
+		args  []uint64
+		want  uint64
+	}{
+		{"loop sum", []testFunc{sum}, []uint64{10}, 55},
+		{"loop sum of zero", []testFunc{sum}, []uint64{0}, 0},
+		{"if then", []testFunc{choose}, []uint64{1}, 1},
+		{"if else", []testFunc{choose}, []uint64{0}, 2},
+		{"if without else taken", []testFunc{skip}, []uint64{1}, 9},
+		{"if without else skipped", []testFunc{skip}, []uint64{0}, 7},
+		{"br_table first", []testFunc{table}, []uint64{0}, 10},
+		{"br_table second", []testFunc{table}, []uint64{1}, 11},
+		{"br_table default", []testFunc{table}, []uint64{100}, 12},
+		{"br carries value", []testFunc{carry}, nil, 3},
+		{"return in loop", []testFunc{early}, nil, 5},
+		{"call", []testFunc{caller, double}, []uint64{4}, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := callFunc(t, tt.args, tt.funcs...)
+			if err != nil {
+				t.Fatalf("call fail: %s", err)
+			}
+			if len(results) != 1 || results[0] != tt.want {
+				t.Errorf("expect %d, got %v", tt.want, results)
+			}
+		})
+	}
+}
+
+func TestMemory(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   testFunc
+		args []uint64
+		want uint64
+	}{
+		{"store and load i32", testFunc{
+			params:  []byte{valueI32, valueI32},
+			results: []byte{valueI32},
+			body:    []byte{0x20, 0, 0x20, 1, 0x36, 2, 0, 0x20, 0, 0x28, 2, 0, 0x0b},
+		}, []uint64{8, 0xdeadbeef}, 0xdeadbeef},
+		{"store i32 in little endian", testFunc{
+			params:  []byte{valueI32},
+			results: []byte{valueI32},
+			body:    []byte{0x41, 0, 0x20, 0, 0x36, 2, 0, 0x41, 0, 0x2d, 0, 0, 0x0b},
+		}, []uint64{0x11223344}, 0x44},
+		{"load with offset", testFunc{
+			results: []byte{valueI32},
+			body:    []byte{0x41, 4, 0x41, 7, 0x3a, 0, 0, 0x41, 0, 0x2d, 0, 4, 0x0b},
+		}, nil, 7},
+		{"load8_s", testFunc{
+			results: []byte{valueI32},
+			body:    []byte{0x41, 0, 0x41, 0xff, 0x01, 0x3a, 0, 0, 0x41, 0, 0x2c, 0, 0, 0x0b},
+		}, nil, i32(-1)},
+		{"i64 load32_s", testFunc{
+			results: []byte{valueI64},
+			body:    []byte{0x41, 0, 0x41, 0x7f, 0x36, 2, 0, 0x41, 0, 0x34, 2, 0, 0x0b},
+		}, nil, math.MaxUint64},
+		{"memory.size", testFunc{
+			results: []byte{valueI32},
+			body:    []byte{0x3f, 0, 0x0b},
+		}, nil, 1},
+		{"memory.grow returns previous size", testFunc{
+			results: []byte{valueI32},
+			body:    []byte{0x41, 1, 0x40, 0, 0x1a, 0x3f, 0, 0x0b},
+		}, nil, 2},
+		{"memory.grow fails beyond max", testFunc{
+			results: []byte{valueI32},
+			body:    []byte{0x41, 2, 0x40, 0, 0x0b},
+		}, nil, math.MaxUint32},
+		{"memory.fill and copy", testFunc{
+			results: []byte{valueI32},
+			body: []byte{
+				0x41, 0, 0x41, 0x2a, 0x41, 4, opPrefix, 11, 0, // fill 4 bytes at 0
+				0x41, 0xe4, 0, 0x41, 0, 0x41, 4, opPrefix, 10, 0, 0, // copy to 100
+				0x41, 0xe4, 0, 0x28, 2, 0,
+				0x0b,
+			},
+		}, nil, 0x2a2a2a2a},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := callFunc(t, tt.args, tt.fn)
+			if err != nil {
+				t.Fatalf("call fail: %s", err)
+			}
+			if len(results) != 1 || results[0] != tt.want {
+				t.Errorf("expect 0x%x, got %x", tt.want, results)
+			}
+		})
+	}
+}
+
+func TestTraps(t *testing.T) {
+	recursive := testFunc{
+		body: []byte{0x10, 0, 0x0b},
+	}
+	tests := []struct {
+		name  string
+		funcs []testFunc
+		args  []uint64
+		trap  string
+	}{
+		{"unreachable", []testFunc{{body: []byte{0x00, 0x0b}}}, nil, "unreachable"},
+		{"i32.div_s by zero", []testFunc{binaryOp(valueI32, valueI32, 0x6d)}, []uint64{1, 0}, "integer divide by zero"},
+		{"i32.div_s overflow", []testFunc{binaryOp(valueI32, valueI32, 0x6d)}, []uint64{i32(math.MinInt32), i32(-1)}, "integer overflow"},
+		{"i32.rem_u by zero", []testFunc{binaryOp(valueI32, valueI32, 0x70)}, []uint64{1, 0}, "integer divide by zero"},
+		{"i64.div_u by zero", []testFunc{binaryOp(valueI64, valueI64, 0x80)}, []uint64{1, 0}, "integer divide by zero"},
+		{"i64.div_s overflow", []testFunc{binaryOp(valueI64, valueI64, 0x7f)}, []uint64{i64(math.MinInt64), i64(-1)}, "integer overflow"},
+		{"i32.trunc_f64_s nan", []testFunc{unaryOp(valueF64, valueI32, 0xaa)}, []uint64{f64(math.NaN())}, "invalid conversion to integer"},
+		{"i32.trunc_f64_s overflow", []testFunc{unaryOp(valueF64, valueI32, 0xaa)}, []uint64{f64(3e9)}, "integer overflow"},
+		{"i32.trunc_f64_u negative", []testFunc{unaryOp(valueF64, valueI32, 0xab)}, []uint64{f64(-1)}, "integer overflow"},
+		{"i64.trunc_f64_s overflow", []testFunc{unaryOp(valueF64, valueI64, 0xb0)}, []uint64{f64(1 << 63)}, "integer overflow"},
+		{"load out of bounds", []testFunc{unaryOp(valueI32, valueI32, 0x28, 2, 0)}, []uint64{pageSize - 2}, "out of bounds memory access"},
+		{"load offset out of bounds", []testFunc{unaryOp(valueI32, valueI32, 0x28, 2, 0xff, 0xff, 0x03)}, []uint64{0}, "out of bounds memory access"},
+		{"store out of bounds", []testFunc{{
+			params: []byte{valueI32},
+			body:   []byte{0x20, 0, 0x41, 1, 0x36, 2, 0, 0x0b},
+		}}, []uint64{i32(-1)}, "out of bounds memory access"},
+		{"memory.fill out of bounds", []testFunc{{
+			body: []byte{0x41, 0, 0x41, 0, 0x41, 0x80, 0x80, 0x08, opPrefix, 11, 0, 0x0b},
+		}}, nil, "out of bounds memory access"},
+		{"call stack exhausted", []testFunc{recursive}, nil, "call stack exhausted"},
+		{"call imported function", nil, nil, "is not available"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.funcs == nil {
+				err = callImported(t)
+			} else {
+				_, err = callFunc(t, tt.args, tt.funcs...)
+			}
+			var tr trap
+			if !errors.As(err, &tr) {
+				t.Fatalf("expect trap %q, got %v", tt.trap, err)
+			}
+			if !strings.Contains(string(tr), tt.trap) {
+				t.Errorf("expect trap %q, got %q", tt.trap, string(tr))
+			}
+		})
+	}
+}
+
+// call a function which calls an imported function
+func callImported(t *testing.T) error {
+	t.Helper()
+	bin := []byte("\x00asm\x01\x00\x00\x00")
+	bin = append(bin, section(1, vec([]byte{0x60, 0, 0}))...)
+	bin = append(bin, section(2, vec([]byte{3, 'e', 'n', 'v', 1, 'g', externFunc, 0}))...)
+	bin = append(bin, section(3, vec([]byte{0}))...)
+	bin = append(bin, section(7, vec([]byte{1, 'f', externFunc, 1}))...)
+	bin = append(bin, section(10, vec([]byte{4, 0, 0x10, 0, 0x0b}))...)
+	m, err := Compile(bin)
+	if err != nil {
+		t.Fatalf("compile fail: %s", err)
+	}
+	in, err := m.Instantiate()
+	if err != nil {
+		t.Fatalf("instantiate fail: %s", err)
+	}
+	_, err = in.Call("f")
+	return err
+}
@@ -0,0 +1,189 @@
+package wasmutil
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	pageSize = 65536
+	// MaxMemoryPages max pages of 64KiB of memory of an instance
+	MaxMemoryPages = 1024
+	// MaxInstructions max instructions can be executed in a call
+	MaxInstructions = 200_000_000
+
+	maxTableSize = 1 << 20
+)
+
+// Instance instantiated module with its own memory, globals and tables.
+// an instance is not safe for concurrent use
+type Instance struct {
+	module   *Module
+	memory   []byte
+	maxPages uint32
+	globals  []uint64
+	tables   [][]uint64
+	elems    [][]uint64 // references of element segments, nil if dropped
+	datas    [][]byte   // data segments, nil if dropped
+	stack    []uint64
+	fuel     int64
+	depth    int
+}
+
+// recover trap raised in executing as error
+func recoverTrap(err *error) {
+	if r := recover(); r != nil {
+		if t, ok := r.(trap); ok {
+			*err = t
+		} else {
+			// runtime errors caused by malformed code
+			*err = fmt.Errorf("wasm trap: %v", r)
+		}
+	}
+}
+
+// Instantiate create an instance, initialize memory, tables and globals and run the start function
+func (m *Module) Instantiate() (in *Instance, err error) {
+	in = &Instance{module: m, fuel: MaxInstructions}
+	defer recoverTrap(&err)
+
+	if m.memory != nil {
+		if m.memory.min > MaxMemoryPages {
+			return nil, errors.New("memory of module exceeds limit")
+		}
+		in.memory = make([]byte, int(m.memory.min)*pageSize)
+		in.maxPages = MaxMemoryPages
+		if m.memory.hasMax && m.memory.max < in.maxPages {
+			in.maxPages = m.memory.max
+		}
+	}
+
+	in.globals = make([]uint64, len(m.globals))
+	for i, g := range m.globals {
+		in.globals[i] = in.evalConst(g.init)
+	}
+
+	in.tables = make([][]uint64, len(m.tables))
+	for i, t := range m.tables {
+		if t.min > maxTableSize {
+			return nil, errors.New("table of module exceeds limit")
+		}
+		in.tables[i] = make([]uint64, t.min)
+		for j := range in.tables[i] {
+			in.tables[i][j] = nullRef
+		}
+	}
+
+	in.elems = make([][]uint64, len(m.elems))
+	for i, seg := range m.elems {
+		refs := make([]uint64, len(seg.inits))
+		for j, expr := range seg.inits {
+			refs[j] = in.evalConst(expr)
+		}
+		switch seg.mode {
+		case 0:
+			offset := uint64(uint32(in.evalConst(seg.offset)))
+			tbl := in.table(seg.table)
+			checkRange(offset, uint64(len(refs)), len(tbl), "out of bounds table access")
+			copy(tbl[offset:], refs)
+		case 1:
+			in.elems[i] = refs
+		}
+	}
+
+	in.datas = make([][]byte, len(m.datas))
+	for i, seg := range m.datas {
+		if seg.active {
+			offset := uint64(uint32(in.evalConst(seg.offset)))
+			checkRange(offset, uint64(len(seg.data)), len(in.memory), "out of bounds memory access")
+			copy(in.memory[offset:], seg.data)
+		} else {
+			in.datas[i] = seg.data
+		}
+	}
+
+	if m.start != nil {
+		if ft, ok := m.funcType(*m.start); !ok || len(ft.params) > 0 || len(ft.results) > 0 {
+			return nil, errMalformed
+		}
+		in.invoke(*m.start)
+	}
+	return in, nil
+}
+
+// evaluate constant expression
+func (in *Instance) evalConst(expr []byte) uint64 {
+	r := &reader{buf: expr}
+	op, _ := r.byte()
+	switch op {
+	case 0x41:
+		n, _ := r.signed(32)
+		return uint64(uint32(n))
+	case 0x42:
+		n, _ := r.signed(64)
+		return uint64(n)
+	case 0x43:
+		b, _ := r.bytes(4)
+		return uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16 | uint64(b[3])<<24
+	case 0x44:
+		b, _ := r.bytes(8)
+		var v uint64
+		for i := 7; i >= 0; i-- {
+			v = v<<8 | uint64(b[i])
+		}
+		return v
+	case 0x23:
+		idx, _ := r.u32()
+		if int(idx) >= len(in.globals) {
+			panic(trap("unknown global"))
+		}
+		return in.globals[idx]
+	case 0xd0:
+		return nullRef
+	case 0xd2:
+		idx, _ := r.u32()
+		return uint64(idx)
+	}
+	panic(trap("invalid constant expression"))
+}
+
+// Call call exported function, arguments and results are in raw bits,
+// i32 and f32 are in the low 32 bits
+func (in *Instance) Call(name string, args ...uint64) (results []uint64, err error) {
+	e, ok := in.module.exports[name]
+	if !ok || e.kind != externFunc {
+		return nil, fmt.Errorf("function %s is not exported", name)
+	}
+	ft, ok := in.module.funcType(e.index)
+	if !ok {
+		return nil, errMalformed
+	}
+	if len(args) != len(ft.params) {
+		return nil, fmt.Errorf("function %s requires %d arguments", name, len(ft.params))
+	}
+	defer recoverTrap(&err)
+	in.stack = append(in.stack[:0], args...)
+	in.fuel, in.depth = MaxInstructions, 0
+	in.invoke(e.index)
+	results = append([]uint64{}, in.stack[len(in.stack)-len(ft.results):]...)
+	in.stack = in.stack[:0]
+	return results, nil
+}
+
+// Read copy bytes from memory
+func (in *Instance) Read(ptr, length uint32) ([]byte, error) {
+	if uint64(ptr)+uint64(length) > uint64(len(in.memory)) {
+		return nil, errors.New("out of bounds memory access")
+	}
+	return append([]byte{}, in.memory[ptr:ptr+length]...), nil
+}
+
+// Write copy bytes into memory
+func (in *Instance) Write(ptr uint32, data []byte) error {
+	if uint64(ptr)+uint64(len(data)) > uint64(len(in.memory)) || len(data) > math.MaxUint32 {
+		return errors.New("out of bounds memory access")
+	}
+	copy(in.memory[ptr:], data)
+	return nil
+}
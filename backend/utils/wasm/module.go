@@ -0,0 +1,556 @@
+package wasmutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+var errMalformed = errors.New("malformed wasm module")
+
+const (
+	valueI32       = 0x7f
+	valueI64       = 0x7e
+	valueF32       = 0x7d
+	valueF64       = 0x7c
+	valueFuncRef   = 0x70
+	valueExternRef = 0x6f
+)
+
+const (
+	externFunc   = 0
+	externTable  = 1
+	externMemory = 2
+	externGlobal = 3
+)
+
+// null reference in table and stack
+const nullRef = math.MaxUint64
+
+type funcType struct {
+	params  []byte
+	results []byte
+}
+
+type limits struct {
+	min    uint32
+	max    uint32
+	hasMax bool
+}
+
+type global struct {
+	valType byte
+	mutable bool
+	init    []byte // constant expression
+}
+
+type export struct {
+	kind  byte
+	index uint32
+}
+
+type imported struct {
+	module string
+	name   string
+	kind   byte
+	typ    uint32
+}
+
+type elemSegment struct {
+	mode   byte // 0 active, 1 passive, 2 declarative
+	table  uint32
+	offset []byte
+	inits  [][]byte // constant expression of each element
+}
+
+type dataSegment struct {
+	active bool
+	offset []byte
+	data   []byte
+}
+
+type function struct {
+	typ    uint32
+	locals []byte // types of locals except params
+	body   []byte
+	code   []instr
+}
+
+// Module parsed wasm module
+type Module struct {
+	types     []funcType
+	imports   []imported
+	funcs     []*function // functions defined in module, indexed after imported functions
+	tables    []limits
+	memory    *limits
+	globals   []global
+	exports   map[string]export
+	start     *uint32
+	elems     []elemSegment
+	datas     []dataSegment
+	numImport int // count of imported functions
+}
+
+type reader struct {
+	buf []byte
+	pos int
+}
+
+func (r *reader) eof() bool {
+	return r.pos >= len(r.buf)
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errMalformed
+	}
+	r.pos++
+	return r.buf[r.pos-1], nil
+}
+
+func (r *reader) bytes(n uint32) ([]byte, error) {
+	if uint64(n) > uint64(len(r.buf)-r.pos) {
+		return nil, errMalformed
+	}
+	r.pos += int(n)
+	return r.buf[r.pos-int(n) : r.pos], nil
+}
+
+func (r *reader) u32() (uint32, error) {
+	var n uint64
+	for shift := 0; shift < 35; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		n |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			if n > math.MaxUint32 {
+				return 0, errMalformed
+			}
+			return uint32(n), nil
+		}
+	}
+	return 0, errMalformed
+}
+
+// read signed leb128 in size bits
+func (r *reader) signed(size int) (int64, error) {
+	var n int64
+	var shift int
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		n |= int64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				n |= -1 << shift
+			}
+			return n, nil
+		}
+		if shift >= size+7 {
+			return 0, errMalformed
+		}
+	}
+}
+
+func (r *reader) name() (string, error) {
+	length, err := r.u32()
+	if err != nil {
+		return "", err
+	}
+	b, err := r.bytes(length)
+	if err != nil || !utf8.Valid(b) {
+		return "", errMalformed
+	}
+	return string(b), nil
+}
+
+func (r *reader) limits() (limits, error) {
+	flag, err := r.byte()
+	if err != nil {
+		return limits{}, err
+	}
+	var l limits
+	if l.min, err = r.u32(); err != nil {
+		return l, err
+	}
+	if flag&1 != 0 {
+		l.hasMax = true
+		if l.max, err = r.u32(); err != nil {
+			return l, err
+		}
+	}
+	return l, nil
+}
+
+// read constant expression until end
+func (r *reader) constExpr() ([]byte, error) {
+	start := r.pos
+	for {
+		op, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case 0x0b:
+			return r.buf[start:r.pos], nil
+		case 0x41:
+			_, err = r.signed(32)
+		case 0x42:
+			_, err = r.signed(64)
+		case 0x43:
+			_, err = r.bytes(4)
+		case 0x44:
+			_, err = r.bytes(8)
+		case 0x23, 0xd2:
+			_, err = r.u32()
+		case 0xd0:
+			_, err = r.byte()
+		default:
+			return nil, fmt.Errorf("unsupported constant expression 0x%02x", op)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Compile parse binary wasm module and compile function bodies into instructions for interpreting
+func Compile(bin []byte) (*Module, error) {
+	if len(bin) < 8 || string(bin[:4]) != "\x00asm" || binary.LittleEndian.Uint32(bin[4:8]) != 1 {
+		return nil, errors.New("not a wasm module of version 1")
+	}
+	m := &Module{exports: map[string]export{}}
+	r := &reader{buf: bin, pos: 8}
+	var funcTypes []uint32
+	for !r.eof() {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		content, err := r.bytes(size)
+		if err != nil {
+			return nil, err
+		}
+		sr := &reader{buf: content}
+		switch id {
+		case 0:
+			// custom section
+		case 1:
+			err = m.readTypes(sr)
+		case 2:
+			err = m.readImports(sr)
+		case 3:
+			funcTypes, err = readVec(sr, func() (uint32, error) { return sr.u32() })
+		case 4:
+			m.tables, err = readVec(sr, func() (limits, error) {
+				if tp, err := sr.byte(); err != nil || (tp != valueFuncRef && tp != valueExternRef) {
+					return limits{}, errMalformed
+				}
+				return sr.limits()
+			})
+		case 5:
+			var mems []limits
+			if mems, err = readVec(sr, sr.limits); err == nil && len(mems) > 0 {
+				if len(mems) > 1 {
+					return nil, errors.New("multiple memories are not supported")
+				}
+				m.memory = &mems[0]
+			}
+		case 6:
+			m.globals, err = readVec(sr, func() (global, error) {
+				var g global
+				var err error
+				if g.valType, err = sr.byte(); err != nil {
+					return g, err
+				}
+				mut, err := sr.byte()
+				if err != nil {
+					return g, err
+				}
+				g.mutable = mut == 1
+				g.init, err = sr.constExpr()
+				return g, err
+			})
+		case 7:
+			err = m.readExports(sr)
+		case 8:
+			var start uint32
+			if start, err = sr.u32(); err == nil {
+				m.start = &start
+			}
+		case 9:
+			m.elems, err = readVec(sr, func() (elemSegment, error) { return readElem(sr) })
+		case 10:
+			err = m.readCode(sr, funcTypes)
+		case 11:
+			m.datas, err = readVec(sr, func() (dataSegment, error) { return readData(sr) })
+		case 12:
+			// data count
+		default:
+			return nil, fmt.Errorf("unknown section %d", id)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(funcTypes) != len(m.funcs) {
+		return nil, errMalformed
+	}
+	for _, f := range m.funcs {
+		if int(f.typ) >= len(m.types) {
+			return nil, errMalformed
+		}
+		var err error
+		if f.code, err = m.compile(f); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func readVec[T any](r *reader, read func() (T, error)) ([]T, error) {
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	if int(count) > len(r.buf)-r.pos {
+		return nil, errMalformed
+	}
+	items := make([]T, 0, count)
+	for i := uint32(0); i < count; i++ {
+		item, err := read()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func readValueTypes(r *reader) ([]byte, error) {
+	return readVec(r, r.byte)
+}
+
+func (m *Module) readTypes(r *reader) (err error) {
+	m.types, err = readVec(r, func() (funcType, error) {
+		var ft funcType
+		if form, err := r.byte(); err != nil || form != 0x60 {
+			return ft, errMalformed
+		}
+		var err error
+		if ft.params, err = readValueTypes(r); err != nil {
+			return ft, err
+		}
+		ft.results, err = readValueTypes(r)
+		return ft, err
+	})
+	return
+}
+
+func (m *Module) readImports(r *reader) (err error) {
+	m.imports, err = readVec(r, func() (imported, error) {
+		var im imported
+		var err error
+		if im.module, err = r.name(); err != nil {
+			return im, err
+		}
+		if im.name, err = r.name(); err != nil {
+			return im, err
+		}
+		if im.kind, err = r.byte(); err != nil {
+			return im, err
+		}
+		if im.kind != externFunc {
+			return im, fmt.Errorf("import of %s.%s is not supported", im.module, im.name)
+		}
+		im.typ, err = r.u32()
+		if err == nil && int(im.typ) >= len(m.types) {
+			err = errMalformed
+		}
+		return im, err
+	})
+	m.numImport = len(m.imports)
+	return
+}
+
+func (m *Module) readExports(r *reader) error {
+	exports, err := readVec(r, func() (struct {
+		name string
+		export
+	}, error) {
+		var e struct {
+			name string
+			export
+		}
+		var err error
+		if e.name, err = r.name(); err != nil {
+			return e, err
+		}
+		if e.kind, err = r.byte(); err != nil {
+			return e, err
+		}
+		e.index, err = r.u32()
+		return e, err
+	})
+	for _, e := range exports {
+		m.exports[e.name] = e.export
+	}
+	return err
+}
+
+func readFuncIndexExprs(r *reader) ([][]byte, error) {
+	return readVec(r, func() ([]byte, error) {
+		idx, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		// convert to expression of ref.func
+		expr := binary.AppendUvarint([]byte{0xd2}, uint64(idx))
+		return append(expr, 0x0b), nil
+	})
+}
+
+func readElem(r *reader) (seg elemSegment, err error) {
+	flag, err := r.u32()
+	if err != nil {
+		return
+	}
+	if flag > 7 {
+		return seg, errMalformed
+	}
+	switch {
+	case flag&1 == 0:
+		seg.mode = 0
+	case flag&2 == 0:
+		seg.mode = 1
+	default:
+		seg.mode = 2
+	}
+	if flag&1 == 0 {
+		if flag&2 != 0 {
+			if seg.table, err = r.u32(); err != nil {
+				return
+			}
+		}
+		if seg.offset, err = r.constExpr(); err != nil {
+			return
+		}
+	}
+	if flag&3 != 0 {
+		// element kind or reference type
+		if _, err = r.byte(); err != nil {
+			return
+		}
+	}
+	if flag&4 == 0 {
+		seg.inits, err = readFuncIndexExprs(r)
+	} else {
+		seg.inits, err = readVec(r, r.constExpr)
+	}
+	return
+}
+
+func readData(r *reader) (seg dataSegment, err error) {
+	flag, err := r.u32()
+	if err != nil {
+		return
+	}
+	switch flag {
+	case 0, 2:
+		if flag == 2 {
+			if _, err = r.u32(); err != nil {
+				return
+			}
+		}
+		seg.active = true
+		if seg.offset, err = r.constExpr(); err != nil {
+			return
+		}
+	case 1:
+	default:
+		return seg, errMalformed
+	}
+	length, err := r.u32()
+	if err != nil {
+		return
+	}
+	seg.data, err = r.bytes(length)
+	return
+}
+
+func (m *Module) readCode(r *reader, funcTypes []uint32) error {
+	bodies, err := readVec(r, func() (*function, error) {
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		content, err := r.bytes(size)
+		if err != nil {
+			return nil, err
+		}
+		br := &reader{buf: content}
+		groups, err := br.u32()
+		if err != nil {
+			return nil, err
+		}
+		f := &function{}
+		for i := uint32(0); i < groups; i++ {
+			count, err := br.u32()
+			if err != nil {
+				return nil, err
+			}
+			tp, err := br.byte()
+			if err != nil {
+				return nil, err
+			}
+			if uint64(len(f.locals))+uint64(count) > 50000 {
+				return nil, errors.New("too many locals")
+			}
+			for j := uint32(0); j < count; j++ {
+				f.locals = append(f.locals, tp)
+			}
+		}
+		f.body = content[br.pos:]
+		return f, nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(bodies) != len(funcTypes) {
+		return errMalformed
+	}
+	for i, f := range bodies {
+		f.typ = funcTypes[i]
+	}
+	m.funcs = bodies
+	return nil
+}
+
+// funcType type of function by index in function space including imported functions
+func (m *Module) funcType(idx uint32) (*funcType, bool) {
+	if int(idx) < m.numImport {
+		return &m.types[m.imports[idx].typ], true
+	}
+	idx -= uint32(m.numImport)
+	if int(idx) >= len(m.funcs) {
+		return nil, false
+	}
+	return &m.types[m.funcs[idx].typ], true
+}
+
+// ExportedFunc check if a function is exported by name
+func (m *Module) ExportedFunc(name string) bool {
+	e, ok := m.exports[name]
+	return ok && e.kind == externFunc
+}
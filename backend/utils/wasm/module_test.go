@@ -0,0 +1,185 @@
+package wasmutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// function of test module, the first one is exported as "f"
+type testFunc struct {
+	params  []byte
+	results []byte
+	locals  []byte
+	body    []byte // instructions including the final end
+}
+
+func section(id byte, content []byte) []byte {
+	return append(binary.AppendUvarint([]byte{id}, uint64(len(content))), content...)
+}
+
+func vec(items ...[]byte) []byte {
+	buf := binary.AppendUvarint(nil, uint64(len(items)))
+	for _, item := range items {
+		buf = append(buf, item...)
+	}
+	return buf
+}
+
+func valueTypes(types []byte) []byte {
+	return append(binary.AppendUvarint(nil, uint64(len(types))), types...)
+}
+
+// signed leb128 for immediates of const instructions
+func sleb(n int64) []byte {
+	var buf []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if (n == 0 && b&0x40 == 0) || (n == -1 && b&0x40 != 0) {
+			return append(buf, b)
+		}
+		buf = append(buf, b|0x80)
+	}
+}
+
+// build binary module with functions, and a memory of pages if pages >= 0
+func buildModule(pages, maxPages int, funcs ...testFunc) []byte {
+	bin := []byte("\x00asm\x01\x00\x00\x00")
+	var types, indexes, bodies [][]byte
+	for i, f := range funcs {
+		types = append(types, append(append([]byte{0x60}, valueTypes(f.params)...), valueTypes(f.results)...))
+		indexes = append(indexes, binary.AppendUvarint(nil, uint64(i)))
+		var locals [][]byte
+		for _, tp := range f.locals {
+			locals = append(locals, []byte{1, tp})
+		}
+		content := append(vec(locals...), f.body...)
+		bodies = append(bodies, append(binary.AppendUvarint(nil, uint64(len(content))), content...))
+	}
+	bin = append(bin, section(1, vec(types...))...)
+	bin = append(bin, section(3, vec(indexes...))...)
+	if pages >= 0 {
+		mem := binary.AppendUvarint([]byte{0}, uint64(pages))
+		if maxPages >= 0 {
+			mem = binary.AppendUvarint(binary.AppendUvarint([]byte{1}, uint64(pages)), uint64(maxPages))
+		}
+		bin = append(bin, section(5, vec(mem))...)
+	}
+	if len(funcs) > 0 {
+		bin = append(bin, section(7, vec([]byte{1, 'f', externFunc, 0}))...)
+	}
+	bin = append(bin, section(10, vec(bodies...))...)
+	return bin
+}
+
+func TestCompile(t *testing.T) {
+	valid := buildModule(1, -1, testFunc{
+		params:  []byte{valueI32},
+		results: []byte{valueI32},
+		body:    []byte{0x20, 0, 0x0b},
+	})
+	m, err := Compile(valid)
+	if err != nil {
+		t.Fatalf("compile valid module fail: %s", err)
+	}
+	if !m.ExportedFunc("f") || m.ExportedFunc("g") {
+		t.Errorf("unexpected exports %v", m.exports)
+	}
+
+	tests := []struct {
+		name string
+		bin  []byte
+		err  string
+	}{
+		{"empty", nil, "not a wasm module"},
+		{"bad magic", []byte("\x00wasm\x01\x00\x00\x00"), "not a wasm module"},
+		{"bad version", []byte("\x00asm\x02\x00\x00\x00"), "not a wasm module"},
+		{"truncated", valid[:len(valid)-2], errMalformed.Error()},
+		{"section exceeds", append([]byte("\x00asm\x01\x00\x00\x00"), 1, 0x7f, 0), errMalformed.Error()},
+		{"unknown section", append([]byte("\x00asm\x01\x00\x00\x00"), 13, 0), "unknown section"},
+		{"huge vector", append([]byte("\x00asm\x01\x00\x00\x00"), section(1, []byte{0xff, 0xff, 0xff, 0xff, 0x0f})...), errMalformed.Error()},
+		{"function without body", append([]byte("\x00asm\x01\x00\x00\x00"),
+			append(section(1, vec([]byte{0x60, 0, 0})), section(3, vec([]byte{0}))...)...), errMalformed.Error()},
+		{"unterminated body", buildModule(-1, -1, testFunc{body: []byte{0x02, 0x40, 0x0b}}), "unterminated function body"},
+		{"else without if", buildModule(-1, -1, testFunc{body: []byte{0x05, 0x0b}}), errMalformed.Error()},
+		{"call unknown function", buildModule(-1, -1, testFunc{body: []byte{0x10, 5, 0x0b}}), errMalformed.Error()},
+		{"unsupported instruction", buildModule(-1, -1, testFunc{body: []byte{0xfd, 0, 0x0b}}), "unsupported instruction"},
+		{"code after end", buildModule(-1, -1, testFunc{body: []byte{0x0b, 0x01, 0x0b}}), errMalformed.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.bin)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expect error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	m, err := Compile(buildModule(MaxMemoryPages+1, -1, testFunc{body: []byte{0x0b}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Instantiate(); err == nil {
+		t.Error("expect error for memory exceeding limit")
+	}
+
+	m, err = Compile(buildModule(1, -1, testFunc{body: []byte{0x0b}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in, err := m.Instantiate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = in.Write(pageSize-2, []byte{1, 2}); err != nil {
+		t.Errorf("write at end of memory fail: %s", err)
+	}
+	if b, err := in.Read(pageSize-2, 2); err != nil || string(b) != "\x01\x02" {
+		t.Errorf("read back %v, %v", b, err)
+	}
+	if err = in.Write(pageSize-1, []byte{1, 2}); err == nil {
+		t.Error("expect error for writing out of memory")
+	}
+	if _, err = in.Read(0xffffffff, 2); err == nil {
+		t.Error("expect error for reading out of memory")
+	}
+	if _, err = in.Call("g"); err == nil {
+		t.Error("expect error for calling function not exported")
+	}
+	if _, err = in.Call("f", 1); err == nil {
+		t.Error("expect error for calling with wrong count of arguments")
+	}
+	var tr trap
+	if _, err = in.Call("f"); errors.As(err, &tr) {
+		t.Errorf("unexpected trap %s", err)
+	}
+}
+
+// FuzzCompile parse arbitrary modules, which should fail with error instead of panic or exhausting memory
+func FuzzCompile(f *testing.F) {
+	f.Add(buildModule(1, 2, testFunc{
+		params:  []byte{valueI32, valueI32},
+		results: []byte{valueI32},
+		locals:  []byte{valueI64},
+		body:    []byte{0x20, 0, 0x20, 1, 0x6a, 0x0b},
+	}))
+	f.Add(buildModule(-1, -1, testFunc{
+		results: []byte{valueI32},
+		body:    []byte{0x02, 0x7f, 0x41, 1, 0x04, 0x7f, 0x41, 2, 0x05, 0x41, 3, 0x0b, 0x0b, 0x0b},
+	}))
+	f.Add([]byte("\x00asm\x01\x00\x00\x00"))
+	f.Fuzz(func(t *testing.T, bin []byte) {
+		m, err := Compile(bin)
+		if err != nil {
+			return
+		}
+		// instantiating runs start function which could loop until fuel exhausted, skip it
+		if m.start == nil {
+			_, _ = m.Instantiate()
+		}
+	})
+}
@@ -10,6 +10,7 @@ export const formatTypes = {
     XML: 'XML',
     HEX: 'Hex',
     BINARY: 'Binary',
+    TIMESTAMP: 'Timestamp',
 }
 
 /**
@@ -21,11 +22,25 @@ export const decodeTypes = {
     BASE64: 'Base64',
     GZIP: 'GZip',
     DEFLATE: 'Deflate',
+    ZLIB: 'Zlib',
     ZSTD: 'ZStd',
     LZ4: 'LZ4',
     BROTLI: 'Brotli',
+    SNAPPY: 'Snappy',
     MSGPACK: 'Msgpack',
+    BSON: 'BSON',
+    CBOR: 'CBOR',
     PHP: 'PHP',
+    IGBINARY: 'Igbinary',
     PICKLE: 'Pickle',
-    // Java: 'Java',
+    PROTOBUF: 'Protobuf',
+    AVRO: 'Avro',
+    THRIFT: 'Thrift',
+    KRYO: 'Kryo',
+    JAVA: 'Java',
+    GBK: 'GBK',
+    SHIFT_JIS: 'Shift-JIS',
+    BIG5: 'Big5',
+    LATIN1: 'Latin-1',
+    JWT: 'JWT',
 }
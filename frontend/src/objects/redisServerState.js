@@ -1,10 +1,10 @@
-import { get, initial, isEmpty, join, last, mapValues, size, slice, sortBy, split, toUpper } from 'lodash'
+import { get, isEmpty, last, mapValues, size, sortBy, toUpper } from 'lodash'
 import useConnectionStore from 'stores/connections.js'
 import { ConnectionType } from '@/consts/connection_type.js'
 import { RedisDatabaseItem } from '@/objects/redisDatabaseItem.js'
 import { KeyViewType } from '@/consts/key_view_type.js'
 import { RedisNodeItem } from '@/objects/redisNodeItem.js'
-import { decodeRedisKey, nativeRedisKey } from '@/utils/key_convert.js'
+import { decodeRedisKey, nativeRedisKey, parentKeyPath, splitKeyPath } from '@/utils/key_convert.js'
 
 /**
  * server connection state
@@ -29,6 +29,7 @@ export class RedisServerState {
      * @param {KeyViewType} viewType view type selection for all opened connections group by 'server'
      * @param {Map<string, RedisNodeItem>} nodeMap map nodes by "type#key"
      * @param {string} version redis server version
+     * @param {string[]} separators all separators of key namespaces
      */
     constructor({
         name,
@@ -42,6 +43,7 @@ export class RedisServerState {
         viewType = KeyViewType.Tree,
         nodeMap = new Map(),
         version = '',
+        separators = [],
     }) {
         this.name = name
         this.db = db
@@ -62,6 +64,7 @@ export class RedisServerState {
         const connStore = useConnectionStore()
         const keySeparator = connStore.getDefaultSeparator(name)
         this.separator = isEmpty(keySeparator) ? ':' : keySeparator
+        this.separators = isEmpty(separators) ? [this.separator] : separators
     }
 
     dispose() {
@@ -196,13 +199,14 @@ export class RedisServerState {
             for (const key of keys) {
                 const k = decodeRedisKey(key)
                 const isBinaryKey = k !== key
-                const keyParts = isBinaryKey ? [nativeRedisKey(key)] : split(k, this.separator)
+                const keyParts = isBinaryKey
+                    ? [{ label: k, path: nativeRedisKey(key) }]
+                    : splitKeyPath(k, this.separators)
                 const len = size(keyParts)
                 const lastIdx = len - 1
-                let handlePath = ''
                 let node = root
                 for (let i = 0; i < len; i++) {
-                    handlePath += keyParts[i]
+                    const handlePath = keyParts[i].path
                     if (i !== lastIdx) {
                         // layer
                         const nodeKey = `${ConnectionType.RedisKey}/${handlePath}`
@@ -210,7 +214,7 @@ export class RedisServerState {
                         if (selectedNode == null) {
                             selectedNode = new RedisNodeItem({
                                 key: `${this.name}/db${this.db}#${nodeKey}`,
-                                label: keyParts[i],
+                                label: keyParts[i].label,
                                 db: this.db,
                                 keyCount: 0,
                                 redisKey: handlePath,
@@ -223,14 +227,13 @@ export class RedisServerState {
                             result.newLayer += 1
                         }
                         node = selectedNode
-                        handlePath += this.separator
                     } else {
                         // key
                         const nodeKey = `${ConnectionType.RedisValue}/${handlePath}`
                         const replaceKey = this.nodeMap.has(nodeKey)
                         const selectedNode = new RedisNodeItem({
                             key: `${this.name}/db${this.db}#${nodeKey}`,
-                            label: isBinaryKey ? k : keyParts[i],
+                            label: keyParts[i].label,
                             db: this.db,
                             keyCount: 0,
                             redisKey: handlePath,
@@ -259,8 +262,8 @@ export class RedisServerState {
      * @param {string} newKey
      */
     renameKey(key, newKey) {
-        const oldLayer = initial(key.split(this.separator)).join(this.separator)
-        const newLayer = initial(newKey.split(this.separator)).join(this.separator)
+        const oldLayer = parentKeyPath(key, this.separators)
+        const newLayer = parentKeyPath(newKey, this.separators)
         if (oldLayer !== newLayer) {
             // also change layer
             this.removeKeyNode(key, false)
@@ -275,7 +278,7 @@ export class RedisServerState {
             const keyNode = this.nodeMap.get(oldNodeKeyName)
             keyNode.key = `${this.name}/db${this.db}#${newNodeKeyName}`
             if (this.viewType === KeyViewType.Tree) {
-                keyNode.label = last(split(newKey, this.separator))
+                keyNode.label = last(splitKeyPath(newKey, this.separators)).label
             } else {
                 keyNode.label = newKey
             }
@@ -312,15 +315,13 @@ export class RedisServerState {
                 dbInst.keyCount = 0
             }
         } else {
-            const keyParts = split(key, this.separator)
-            const totalParts = size(keyParts)
             // remove from parent in tree node
-            const parentKey = slice(keyParts, 0, totalParts - 1)
+            const parentKey = parentKeyPath(key, this.separators)
             let parentNode
             if (isEmpty(parentKey)) {
                 parentNode = dbRoot
             } else {
-                parentNode = this.nodeMap.get(`${ConnectionType.RedisKey}/${join(parentKey, this.separator)}`)
+                parentNode = this.nodeMap.get(`${ConnectionType.RedisKey}/${parentKey}`)
             }
 
             // not found parent node
@@ -365,13 +366,13 @@ export class RedisServerState {
      */
     tidyNode(key, skipResort) {
         const rootNode = this.getRoot()
-        const keyParts = split(key, this.separator)
+        const keyParts = splitKeyPath(key, this.separators)
         const totalParts = size(keyParts)
         let node
         // find last exists ancestor key
         let i = totalParts - 1
         for (; i > 0; i--) {
-            const parentKey = join(slice(keyParts, 0, i), this.separator)
+            const parentKey = keyParts[i - 1].path
             node = this.nodeMap.get(`${ConnectionType.RedisKey}/${parentKey}`)
             if (node != null) {
                 break
@@ -384,7 +385,7 @@ export class RedisServerState {
         if (keyCountUpdated) {
             // update key count of parent and above
             for (; i > 0; i--) {
-                const parentKey = join(slice(keyParts, 0, i), this.separator)
+                const parentKey = keyParts[i - 1].path
                 const parentNode = this.nodeMap.get(`${ConnectionType.RedisKey}/${parentKey}`)
                 if (parentNode == null) {
                     break
@@ -394,7 +395,7 @@ export class RedisServerState {
                     let anceKeyNode = rootNode
                     // remove from ancestor node
                     if (i > 1) {
-                        const anceKey = join(slice(keyParts, 0, i - 1), this.separator)
+                        const anceKey = keyParts[i - 2].path
                         anceKeyNode = this.nodeMap.get(`${ConnectionType.RedisKey}/${anceKey}`)
                     }
                     if (anceKeyNode != null) {
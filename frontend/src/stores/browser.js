@@ -7,9 +7,9 @@ import { RedisNodeItem } from '@/objects/redisNodeItem.js'
 import { RedisServerState } from '@/objects/redisServerState.js'
 import { isRedisGlob } from '@/utils/glob_pattern.js'
 import { i18nGlobal } from '@/utils/i18n.js'
-import { nativeRedisKey } from '@/utils/key_convert.js'
+import { nativeRedisKey, parentKeyPath } from '@/utils/key_convert.js'
 import { timeout } from '@/utils/promise.js'
-import { endsWith, get, isEmpty, join, map, now, size, slice, split, startsWith } from 'lodash'
+import { endsWith, get, isEmpty, map, now, size, split, startsWith } from 'lodash'
 import { defineStore } from 'pinia'
 import useConnectionStore from 'stores/connections.js'
 import useTabStore from 'stores/tab.js'
@@ -40,8 +40,10 @@ import {
     LoadNextKeys,
     OpenConnection,
     OpenDatabase,
+    PrepareFlush,
     RemoveStreamValues,
     RenameKey,
+    RunKeyAction,
     ServerInfo,
     SetHashValue,
     SetKeyTTL,
@@ -252,13 +254,14 @@ const useBrowserStore = defineStore('browser', {
             // if (connNode == null) {
             //     throw new Error('no such connection')
             // }
-            const { db, view = KeyViewType.Tree, lastDB, version } = data
+            const { db, view = KeyViewType.Tree, lastDB, version, separators } = data
             if (isEmpty(db)) {
                 throw new Error('no db loaded')
             }
             const serverInst = new RedisServerState({
                 name,
                 separator: this.getSeparator(name),
+                separators,
                 db: -1,
                 viewType: view,
                 version,
@@ -582,17 +585,17 @@ const useBrowserStore = defineStore('browser', {
          * @param {string|number[]} value
          * @param {string} [decode]
          * @param {string} [format]
-         * @return {Promise<{[format]: string, [decode]: string, value: string}>}
+         * @return {Promise<{[format]: string, [decode]: string, value: string, candidates: {decode: string, format: string, score: number}[]}>}
          */
         async convertValue({ value, decode, format }) {
             try {
                 const { data, success } = await ConvertValue(value, decode, format)
                 if (success) {
-                    const { value: retVal, decode: retDecode, format: retFormat } = data
-                    return { value: retVal, decode: retDecode, format: retFormat }
+                    const { value: retVal, decode: retDecode, format: retFormat, candidates = [] } = data
+                    return { value: retVal, decode: retDecode, format: retFormat, candidates: candidates || [] }
                 }
             } catch (e) {}
-            return { value, decode, format }
+            return { value, decode, format, candidates: [] }
         },
 
         /**
@@ -721,8 +724,12 @@ const useBrowserStore = defineStore('browser', {
             }
             let match = prefix
             const separator = this.getSeparator(server)
+            const { separators = [] } = this.servers[server] || {}
             if (!isEmpty(match)) {
-                if (!endsWith(match, separator)) {
+                if (size(separators) > 1) {
+                    // child keys may follow any of separators
+                    match += '*'
+                } else if (!endsWith(match, separator)) {
                     match += separator + '*'
                 } else {
                     match += '*'
@@ -823,16 +830,14 @@ const useBrowserStore = defineStore('browser', {
             if (serverInst == null) {
                 return null
             }
-            const separator = this.getSeparator(server)
             const keyPart = key.substring(i)
             const keyStartIdx = keyPart.indexOf('/')
             const redisKey = keyPart.substring(keyStartIdx + 1)
-            const redisKeyParts = split(redisKey, separator)
-            const parentKey = slice(redisKeyParts, 0, size(redisKeyParts) - 1)
+            const parentKey = parentKeyPath(redisKey, serverInst.separators)
             if (isEmpty(parentKey)) {
                 return serverInst.getRoot()
             }
-            return serverInst.nodeMap.get(`${ConnectionType.RedisKey}/${join(parentKey, separator)}`)
+            return serverInst.nodeMap.get(`${ConnectionType.RedisKey}/${parentKey}`)
         },
 
         /**
@@ -845,9 +850,20 @@ const useBrowserStore = defineStore('browser', {
          * @param {number} ttl
          * @param {string} [format]
          * @param {string} [decode]
+         * @param {string|number[]} [expected] value when loaded, saving fails if modified by others since then
          * @returns {Promise<{[msg]: string, success: boolean, [nodeKey]: {string}}>}
          */
-        async setKey({ server, db, key, keyType, value, ttl, format = formatTypes.RAW, decode = decodeTypes.NONE }) {
+        async setKey({
+            server,
+            db,
+            key,
+            keyType,
+            value,
+            ttl,
+            format = formatTypes.RAW,
+            decode = decodeTypes.NONE,
+            expected,
+        }) {
             try {
                 const { data, success, msg } = await SetKeyValue({
                     server,
@@ -858,6 +874,7 @@ const useBrowserStore = defineStore('browser', {
                     ttl,
                     format,
                     decode,
+                    expected,
                 })
                 if (success) {
                     /** @type RedisServerState **/
@@ -1702,6 +1719,20 @@ const useBrowserStore = defineStore('browser', {
             // }
         },
 
+        /**
+         * run user-defined key action on key
+         * @param {string} server
+         * @param {number} db
+         * @param {string|number[]} key
+         * @param {string} name name of key action
+         * @return {Promise<{success: boolean, msg: string, result: string}>}
+         */
+        async runKeyAction(server, db, key, name) {
+            const { success, msg, data } = await RunKeyAction(server, db, key, name, [])
+            const { result = '' } = data || {}
+            return { success, msg, result }
+        },
+
         /**
          * delete redis key
          * @param {string} server
@@ -1972,16 +2003,34 @@ const useBrowserStore = defineStore('browser', {
             }
         },
 
+        /**
+         * prepare to flush database, get size and sample keys of database with confirmation
+         * @param {string} server
+         * @param {number} db
+         * @return {Promise<{dbSize: number, samples: string[], confirm: string, token: string}>}
+         */
+        async prepareFlush(server, db) {
+            const { data, success, msg } = await PrepareFlush(server, db, false)
+            if (!success) {
+                throw new Error(msg)
+            }
+            return data
+        },
+
         /**
          * flush database
          * @param {string} server
          * @param {number} db
-         * @param {boolean} async
+         * @param {string} token token returned by prepareFlush
+         * @param {string} confirm confirm text typed by user
          * @return {Promise<boolean>}
          */
-        async flushDatabase(server, db, async) {
+        async flushDatabase(server, db, token, confirm) {
             try {
-                const { success = false } = await FlushDB(server, db, async)
+                const { success = false, msg } = await FlushDB({ server, db, token, confirm })
+                if (!success) {
+                    throw new Error(msg)
+                }
 
                 if (success === true) {
                     /** @type RedisServerState **/
@@ -167,6 +167,8 @@ const useConnectionStore = defineStore('connections', {
                 dbFilterList: [],
                 keyView: KeyViewType.Tree,
                 loadSize: 10000,
+                disableFlush: false,
+                readFromReplica: false,
                 markColor: '',
                 alias: {},
                 ssl: {
@@ -313,10 +315,11 @@ const useConnectionStore = defineStore('connections', {
         /**
          * create a connection group
          * @param name
+         * @param {string} [parent] parent group name
          * @returns {Promise<{success: boolean, [msg]: string}>}
          */
-        async createGroup(name) {
-            const { success, msg } = await CreateGroup(name)
+        async createGroup(name, parent = '') {
+            const { success, msg } = await CreateGroup(name, parent)
             if (!success) {
                 return { success: false, msg }
             }
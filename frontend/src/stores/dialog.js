@@ -99,6 +99,7 @@ const useDialogStore = defineStore('dialog', {
             decodeArgs: [],
             encodePath: '',
             encodeArgs: [],
+            chain: [],
         },
 
         preferencesDialogVisible: false,
@@ -326,6 +327,7 @@ const useDialogStore = defineStore('dialog', {
          * @param {string[]} decodeArgs
          * @param {string} encodePath
          * @param {string[]} encodeArgs
+         * @param {string[]} chain
          */
         openDecoderDialog({
             name = '',
@@ -334,6 +336,7 @@ const useDialogStore = defineStore('dialog', {
             decodeArgs = [],
             encodePath = '',
             encodeArgs = [],
+            chain = [],
         } = {}) {
             this.decodeDialogVisible = true
             this.decodeParam.name = name
@@ -342,6 +345,7 @@ const useDialogStore = defineStore('dialog', {
             this.decodeParam.decodeArgs = decodeArgs || []
             this.decodeParam.encodePath = encodePath
             this.decodeParam.encodeArgs = encodeArgs || []
+            this.decodeParam.chain = chain || []
         },
 
         closeDecoderDialog() {
@@ -3,10 +3,13 @@ import { lang } from '@/langs/index.js'
 import { cloneDeep, findIndex, get, isEmpty, join, map, pick, set, some, split } from 'lodash'
 import {
     CheckForUpdate,
+    DeleteKeyAction,
     GetBuildInDecoder,
     GetFontList,
+    GetKeyActions,
     GetPreferences,
     RestorePreferences,
+    SaveKeyAction,
     SetPreferences,
 } from 'wailsjs/go/services/preferencesService.js'
 import { BrowserOpenURL } from 'wailsjs/runtime/runtime.js'
@@ -72,6 +75,7 @@ const usePreferencesStore = defineStore('preferences', {
             cursorStyle: 'block',
         },
         buildInDecoder: [],
+        pluginDecoder: [],
         decoder: [],
         lastPref: {},
         fontList: [],
@@ -331,19 +335,56 @@ const usePreferencesStore = defineStore('preferences', {
         },
 
         /**
-         * get all available build-in decoder
+         * get all available build-in decoder and wasm plugin decoder
          * @return {Promise<void>}
          */
         async loadBuildInDecoder() {
             const { success, data } = await GetBuildInDecoder()
             if (success) {
-                const { decoder = [] } = data
+                const { decoder = [], plugin = [] } = data
                 this.buildInDecoder = decoder
+                this.pluginDecoder = plugin
             } else {
                 this.buildInDecoder = []
+                this.pluginDecoder = []
             }
         },
 
+        /**
+         * get user-defined key actions available for key type
+         * @param {string} [keyType] all actions if empty
+         * @return {Promise<{name: string, script: string, types: string[]}[]>}
+         */
+        async getKeyActions(keyType = '') {
+            const { success, data } = await GetKeyActions(keyType)
+            if (success) {
+                const { actions = [] } = data
+                return actions || []
+            }
+            return []
+        },
+
+        /**
+         * add key action, or replace the action named origName
+         * @param {string} origName empty to add new action
+         * @param {{name: string, script: string, types: string[]}} action
+         * @return {Promise<{success: boolean, msg: string}>}
+         */
+        async saveKeyAction(origName, action) {
+            const { success, msg } = await SaveKeyAction(origName, action)
+            return { success, msg }
+        },
+
+        /**
+         * remove key action by name
+         * @param {string} name
+         * @return {Promise<{success: boolean, msg: string}>}
+         */
+        async deleteKeyAction(name) {
+            const { success, msg } = await DeleteKeyAction(name)
+            return { success, msg }
+        },
+
         /**
          * save preferences to local
          * @returns {Promise<boolean>}
@@ -387,13 +428,23 @@ const usePreferencesStore = defineStore('preferences', {
          * @param {string[]} encodeArgs
          * @param {string} decodePath
          * @param {string[]} decodeArgs
+         * @param {string[]} chain decode types applied in order instead of command
          */
-        addCustomDecoder({ name, enable = true, auto = true, encodePath, encodeArgs, decodePath, decodeArgs }) {
+        addCustomDecoder({
+            name,
+            enable = true,
+            auto = true,
+            encodePath,
+            encodeArgs,
+            decodePath,
+            decodeArgs,
+            chain = [],
+        }) {
             if (some(this.decoder, { name })) {
                 return false
             }
             this.decoder = this.decoder || []
-            this.decoder.push({ name, enable, auto, encodePath, encodeArgs, decodePath, decodeArgs })
+            this.decoder.push({ name, enable, auto, encodePath, encodeArgs, decodePath, decodeArgs, chain })
             return true
         },
 
@@ -407,6 +458,7 @@ const usePreferencesStore = defineStore('preferences', {
          * @param {string[]} encodeArgs
          * @param {string} decodePath
          * @param {string[]} decodeArgs
+         * @param {string[]} chain decode types applied in order instead of command
          */
         updateCustomDecoder({
             newName,
@@ -417,6 +469,7 @@ const usePreferencesStore = defineStore('preferences', {
             encodeArgs,
             decodePath,
             decodeArgs,
+            chain = [],
         }) {
             const idx = findIndex(this.decoder, { name })
             if (idx === -1) {
@@ -435,6 +488,7 @@ const usePreferencesStore = defineStore('preferences', {
             selDecoder.encodeArgs = encodeArgs
             selDecoder.decodePath = decodePath
             selDecoder.decodeArgs = decodeArgs
+            selDecoder.chain = chain
             this.decoder[idx] = selDecoder
             return true
         },
@@ -1,4 +1,4 @@
-import { join, map, take } from 'lodash'
+import { filter, find, initial, isEmpty, join, last, map, sortBy, take } from 'lodash'
 
 /**
  * converted binary data in strings to hex format
@@ -38,3 +38,41 @@ export function nativeRedisKey(key, truncate) {
     }
     return key
 }
+
+/**
+ * split key into namespace parts by separators, longer separator is matched first
+ * @param {string} key
+ * @param {string[]} separators
+ * @return {{label: string, path: string}[]} path is the leading part of key till the end of label
+ */
+export function splitKeyPath(key, separators) {
+    const seps = sortBy(
+        filter(separators, (s) => !isEmpty(s)),
+        (s) => -s.length,
+    )
+    const parts = []
+    let start = 0
+    for (let i = 0; i < key.length; ) {
+        const sep = find(seps, (s) => key.startsWith(s, i))
+        if (sep == null) {
+            i++
+            continue
+        }
+        parts.push({ label: key.substring(start, i), path: key.substring(0, i) })
+        i += sep.length
+        start = i
+    }
+    parts.push({ label: key.substring(start), path: key })
+    return parts
+}
+
+/**
+ * get path of parent namespace of key
+ * @param {string} key
+ * @param {string[]} separators
+ * @return {string} empty if key is in top level
+ */
+export function parentKeyPath(key, separators) {
+    const parent = last(initial(splitKeyPath(key, separators)))
+    return parent != null ? parent.path : ''
+}
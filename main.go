@@ -36,6 +36,18 @@ func main() {
 	cliSvc := services.Cli()
 	monitorSvc := services.Monitor()
 	pubsubSvc := services.Pubsub()
+	pushSvc := services.Push()
+	aclSvc := services.ACL()
+	healthSvc := services.Health()
+	trendSvc := services.Trend()
+	clusterSvc := services.Cluster()
+	auditSvc := services.Audit()
+	searchSvc := services.Search()
+	tailSvc := services.Tail()
+	rdbSvc := services.RDB()
+	keyspaceSvc := services.Keyspace()
+	watchSvc := services.Watch()
+	jobSvc := services.Jobs()
 	prefSvc := services.Preferences()
 	prefSvc.SetAppVersion(version)
 	prefSvc.UpdateEnv()
@@ -77,6 +89,18 @@ func main() {
 			cliSvc.Start(ctx)
 			monitorSvc.Start(ctx)
 			pubsubSvc.Start(ctx)
+			pushSvc.Start(ctx)
+			aclSvc.Start(ctx)
+			healthSvc.Start(ctx)
+			trendSvc.Start(ctx)
+			clusterSvc.Start(ctx)
+			auditSvc.Start(ctx)
+			searchSvc.Start(ctx)
+			tailSvc.Start(ctx)
+			rdbSvc.Start(ctx)
+			keyspaceSvc.Start(ctx)
+			watchSvc.Start(ctx)
+			jobSvc.Start(ctx)
 
 			services.GA().SetSecretKey(gaMeasurementID, gaSecretKey)
 			services.GA().Startup(version)
@@ -96,6 +120,12 @@ func main() {
 			cliSvc.CloseAll()
 			monitorSvc.StopAll()
 			pubsubSvc.StopAll()
+			pushSvc.StopAll()
+			healthSvc.StopAll()
+			trendSvc.StopAll()
+			tailSvc.StopAll()
+			keyspaceSvc.StopAll()
+			watchSvc.StopAll()
 		},
 		Bind: []interface{}{
 			sysSvc,
@@ -104,6 +134,18 @@ func main() {
 			cliSvc,
 			monitorSvc,
 			pubsubSvc,
+			pushSvc,
+			aclSvc,
+			healthSvc,
+			trendSvc,
+			clusterSvc,
+			auditSvc,
+			searchSvc,
+			tailSvc,
+			rdbSvc,
+			keyspaceSvc,
+			watchSvc,
+			jobSvc,
 			prefSvc,
 		},
 		Mac: &mac.Options{